@@ -51,7 +51,7 @@ var createInstanceCmd = &cobra.Command{
 				return err
 			}
 
-			return printJSON(response)
+			return printResponse(response)
 		}
 
 		variableNames := []string{}
@@ -66,7 +66,7 @@ var createInstanceCmd = &cobra.Command{
 			return err
 		}
 
-		return printJSON(response)
+		return printResponse(response)
 	},
 }
 
@@ -55,7 +55,7 @@ var deployWorkflowCmd = &cobra.Command{
 			return err
 		}
 
-		return printJSON(response)
+		return printResponse(response)
 	},
 }
 
@@ -48,7 +48,7 @@ var publishMessageCmd = &cobra.Command{
 		defer cancel()
 
         response, err := request.Send(ctx)
-		return printJSON(response)
+		return printResponse(response)
 	},
 }
 
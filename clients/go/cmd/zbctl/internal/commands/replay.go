@@ -0,0 +1,65 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/replay"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+	"log"
+	"os"
+)
+
+var replayContinueOnErrorFlag bool
+
+var replayCmd = &cobra.Command{
+	Use:     "replay <recordingPath>",
+	Short:   "Replay the commands of a recording of a client",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: initClient,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		recorded, err := zbc.ReadRecordedCommands(file)
+		if err != nil {
+			return err
+		}
+
+		replayer := replay.New(client, replay.Options{ContinueOnError: replayContinueOnErrorFlag})
+		results, err := replayer.ReplayAll(context.Background(), recorded)
+		for _, result := range results {
+			if result.Diverged() {
+				log.Printf("Replayed %s command recorded at %s with another outcome: expected %s, but got %v", result.Command.Command, result.Command.Time, result.Command.Code, result.Err)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Replayed %d of %d recorded commands\n", len(results), len(recorded))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().BoolVar(&replayContinueOnErrorFlag, "continueOnError", false, "Specify if the remaining commands are replayed after a command failed which succeeded when it was recorded")
+}
@@ -15,21 +15,28 @@
 package commands
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
 	"os"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
 )
 
 const (
 	DefaultAddressHost = "127.0.0.1"
 	DefaultAddressPort = "26500"
 	defaultTimeout     = 10 * time.Second
+
+	outputJSON     = "json"
+	outputYAML     = "yaml"
+	outputTemplate = "template"
 )
 
 var client zbc.Client
@@ -42,6 +49,9 @@ var audienceFlag string
 var authzURLFlag string
 var insecureFlag bool
 var clientCacheFlag string
+var outputFlag string
+var templateFlag string
+var outputTmpl *template.Template
 
 var rootCmd = &cobra.Command{
 	Use:   "zbctl",
@@ -53,10 +63,25 @@ It is designed for regular maintenance jobs such as:
 	* activating, completing or failing jobs
 	* update variables and retries
 	* view cluster status`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if outputFlag != outputJSON && outputFlag != outputYAML && outputFlag != outputTemplate {
+			return fmt.Errorf("invalid output format %q, expected %q, %q or %q", outputFlag, outputJSON, outputYAML, outputTemplate)
+		}
+		if outputFlag == outputTemplate {
+			if templateFlag == "" {
+				return fmt.Errorf("expected --template for output format %q", outputTemplate)
+			}
+			tmpl, err := template.New("output").Option("missingkey=error").Parse(templateFlag)
+			if err != nil {
+				return fmt.Errorf("invalid output template: %w", err)
+			}
+			outputTmpl = tmpl
+		}
+
 		// silence help here instead of as a parameter because we only want to suppress it on a 'Zeebe' error and not if
 		// parsing args fails
 		cmd.SilenceUsage = true
+		return nil
 	},
 	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
 		if client != nil {
@@ -83,6 +108,8 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&audienceFlag, "audience", "", "Specify the resource that the access token should be valid for. If omitted, will read from the environment variable '"+zbc.OAuthTokenAudienceEnvVar+"'")
 	rootCmd.PersistentFlags().StringVar(&authzURLFlag, "authzUrl", zbc.OAuthDefaultAuthzURL, "Specify an authorization server URL from which to request an access token. If omitted, will read from the environment variable '"+zbc.OAuthAuthorizationUrlEnvVar+"'")
 	rootCmd.PersistentFlags().BoolVar(&insecureFlag, "insecure", false, "Specify if zbctl should use an unsecured connection. If omitted, will read from the environment variable '"+zbc.InsecureEnvVar+"'")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", outputJSON, "Specify the output format of responses, either '"+outputJSON+"', '"+outputYAML+"' or '"+outputTemplate+"'")
+	rootCmd.PersistentFlags().StringVar(&templateFlag, "template", "", "Specify the Go template which formats responses if the output format is '"+outputTemplate+"', e.g. '{{.workflowInstanceKey}}'. Fields have the names of the JSON output")
 	rootCmd.PersistentFlags().StringVar(&clientCacheFlag, "clientCache", zbc.DefaultOauthYamlCachePath, "Specify the path to use for the OAuth credentials cache. If omitted, will read from the environment variable '"+zbc.OAuthCachePathEnvVar+"'")
 }
 
@@ -201,10 +228,48 @@ func keyArg(key *int64) cobra.PositionalArgs {
 	}
 }
 
-func printJSON(value interface{}) error {
+// printResponse prints the value in the format of the output flag. YAML and the data of the template are converted
+// from the JSON representation, so all formats use the same field names and omit the same empty fields.
+func printResponse(value interface{}) error {
 	valueJSON, err := json.MarshalIndent(value, "", "  ")
-	if err == nil {
+	if err != nil {
+		return err
+	}
+
+	if outputFlag == outputTemplate {
+		return printTemplate(valueJSON)
+	}
+	if outputFlag != outputYAML {
 		fmt.Println(string(valueJSON))
+		return nil
+	}
+
+	var document yaml.MapSlice
+	if err := yaml.Unmarshal(valueJSON, &document); err != nil {
+		return err
+	}
+
+	valueYAML, err := yaml.Marshal(document)
+	if err == nil {
+		fmt.Printf("---\n%s", valueYAML)
 	}
 	return err
 }
+
+// printTemplate executes the output template with the JSON document. Numbers are kept as written, so keys are not
+// printed in exponent notation.
+func printTemplate(valueJSON []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(valueJSON))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return err
+	}
+
+	var output bytes.Buffer
+	if err := outputTmpl.Execute(&output, document); err != nil {
+		return err
+	}
+	fmt.Println(output.String())
+	return nil
+}
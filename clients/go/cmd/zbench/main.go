@@ -0,0 +1,106 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command zbench generates load on a Zeebe cluster and reports the command latencies, see package bench.
+//
+//	zbench --address localhost:26500 --insecure --bpmn benchmark.bpmn --process benchmark \
+//		--rate 200 --duration 5m --job-types benchmark-task --job-latency 20ms
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/bench"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+)
+
+func main() {
+	var config bench.Config
+	address := flag.String("address", "127.0.0.1:26500", "address of the gateway")
+	insecure := flag.Bool("insecure", false, "use a plaintext connection to the gateway")
+	resource := flag.String("bpmn", "", "path of the BPMN model to deploy before the run")
+	jobTypes := flag.String("job-types", "", "comma separated types of the jobs completed by synthetic workers")
+	variables := flag.String("variables", "", "JSON variables of the created instances")
+	flag.StringVar(&config.BPMNProcessID, "process", "", "BPMN process id of the created instances")
+	flag.Float64Var(&config.Rate, "rate", 100, "instances created per second")
+	flag.DurationVar(&config.Duration, "duration", time.Minute, "duration of the run")
+	flag.IntVar(&config.MaxInFlight, "max-in-flight", bench.DefaultMaxInFlight, "maximum instance creations awaiting their response")
+	flag.DurationVar(&config.JobLatency, "job-latency", 0, "simulated latency of the synthetic workers")
+	flag.IntVar(&config.WorkerConcurrency, "worker-concurrency", bench.DefaultWorkerConcurrency, "jobs handled at the same time per job type")
+	flag.Parse()
+
+	if config.BPMNProcessID == "" {
+		fail(fmt.Errorf("expected a BPMN process id, see --process"))
+	}
+	if *resource != "" {
+		data, err := ioutil.ReadFile(*resource)
+		if err != nil {
+			fail(err)
+		}
+		config.Resource = data
+		config.ResourceName = filepath.Base(*resource)
+	}
+	if *jobTypes != "" {
+		config.JobTypes = strings.Split(*jobTypes, ",")
+	}
+	if *variables != "" {
+		config.Variables = rawJSON(*variables)
+	}
+
+	client, err := zbc.NewClient(&zbc.ClientConfig{
+		GatewayAddress:         *address,
+		UsePlaintextConnection: *insecure,
+	})
+	if err != nil {
+		fail(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		<-interrupts
+		cancel()
+	}()
+
+	report, err := bench.Run(ctx, client, config)
+	if err != nil {
+		fail(err)
+	}
+	if err := report.Print(os.Stdout); err != nil {
+		fail(err)
+	}
+}
+
+// rawJSON is serialized as the JSON document it contains
+type rawJSON string
+
+func (r rawJSON) MarshalJSON() ([]byte, error) {
+	return []byte(r), nil
+}
+
+func fail(err error) {
+	_, _ = fmt.Fprintln(os.Stderr, "zbench:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,82 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command zeebe-gen generates Go types and job workers for the service tasks of BPMN models, see package codegen.
+//
+//	zeebe-gen --package orders --out workers_gen.go order.bpmn
+//
+// To regenerate the code whenever the models change, add a go:generate directive to the package:
+//
+//	//go:generate go run github.com/zeebe-io/zeebe/clients/go/cmd/zeebe-gen --package orders --out workers_gen.go order.bpmn
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/codegen"
+)
+
+func main() {
+	packageName := flag.String("package", "", "name of the generated package, by default the name of the directory of the output file")
+	out := flag.String("out", "", "path of the generated file, by default the code is written to stdout")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintln(flag.CommandLine.Output(), "Usage: zeebe-gen [flags] model.bpmn...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *packageName == "" {
+		dir, err := filepath.Abs(filepath.Dir(*out))
+		if err != nil {
+			fail(err)
+		}
+		*packageName = filepath.Base(dir)
+	}
+
+	models := make([]codegen.Model, flag.NArg())
+	for i, path := range flag.Args() {
+		definition, err := ioutil.ReadFile(path)
+		if err != nil {
+			fail(err)
+		}
+		models[i] = codegen.Model{Name: filepath.Base(path), Definition: definition}
+	}
+
+	source, err := codegen.Generate(*packageName, models...)
+	if err != nil {
+		fail(err)
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(source)
+	} else {
+		err = ioutil.WriteFile(*out, source, 0644)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	_, _ = fmt.Fprintln(os.Stderr, "zeebe-gen:", err)
+	os.Exit(1)
+}
@@ -170,37 +170,54 @@ func (s *ContainerSuite) AfterTest(suiteName, testName string) {
 
 func (s *ContainerSuite) SetupSuite() {
 	var err error
+	s.container, s.GatewayAddress, err = StartContainer(context.Background(), s.ContainerImage, s.WaitTime)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+}
+
+// StartContainer starts a container running the given Zeebe image and waits until its gateway is ready. It returns
+// the container and the contact point of its gateway in the format 'host:port'.
+func StartContainer(ctx context.Context, image string, waitTime time.Duration) (testcontainers.Container, string, error) {
+	if err := validateImageExists(ctx, image); err != nil {
+		return nil, "", err
+	}
+
+	return StartContainerWithEnv(ctx, image, waitTime, nil)
+}
+
+// StartContainerWithEnv starts a container like StartContainer, with additional environment variables, e.g. to
+// configure the broker, and additional exposed ports. Unlike StartContainer, the image is pulled if it doesn't exist
+// locally.
+func StartContainerWithEnv(ctx context.Context, image string, waitTime time.Duration, env map[string]string, exposedPorts ...string) (testcontainers.Container, string, error) {
 	req := testcontainers.GenericContainerRequest{
 		ContainerRequest: testcontainers.ContainerRequest{
-			Image:        s.ContainerImage,
-			ExposedPorts: []string{"26500"},
-			WaitingFor:   zeebeWaitStrategy{waitTime: s.WaitTime},
+			Image:        image,
+			Env:          env,
+			ExposedPorts: append([]string{"26500"}, exposedPorts...),
+			WaitingFor:   zeebeWaitStrategy{waitTime: waitTime},
 		},
 		Started: true,
 	}
 
-	ctx := context.Background()
-	err = validateImageExists(ctx, s.ContainerImage)
-	if err != nil {
-		s.T().Fatal(err)
-	}
-
-	s.container, err = testcontainers.GenericContainer(ctx, req)
+	container, err := testcontainers.GenericContainer(ctx, req)
 	if err != nil {
-		s.T().Fatal(err)
+		return nil, "", err
 	}
 
-	host, err := s.container.Host(ctx)
+	host, err := container.Host(ctx)
 	if err != nil {
-		s.T().Fatal(err)
+		_ = container.Terminate(ctx)
+		return nil, "", err
 	}
 
-	port, err := s.container.MappedPort(ctx, "26500")
+	port, err := container.MappedPort(ctx, "26500")
 	if err != nil {
-		s.T().Fatal(err)
+		_ = container.Terminate(ctx)
+		return nil, "", err
 	}
 
-	s.GatewayAddress = fmt.Sprintf("%s:%d", host, port.Int())
+	return container, fmt.Sprintf("%s:%d", host, port.Int()), nil
 }
 
 func (s *ContainerSuite) TearDownSuite() {
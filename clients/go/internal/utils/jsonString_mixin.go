@@ -15,8 +15,9 @@
 package utils
 
 import (
-	"encoding/json"
 	"fmt"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
 )
 
 type SerializerMixin interface {
@@ -26,10 +27,11 @@ type SerializerMixin interface {
 
 type JSONStringSerializer struct {
 	valueMap map[string]interface{}
+	codec    entities.VariableCodec
 }
 
 func (validator *JSONStringSerializer) Validate(name string, value string) error {
-	err := json.Unmarshal([]byte(value), &validator.valueMap)
+	err := validator.codec.Unmarshal([]byte(value), &validator.valueMap)
 	if err != nil {
 		return fmt.Errorf("parameter %q requires a JSON object, got %q: %s", name, value, err)
 	}
@@ -40,7 +42,7 @@ func (validator *JSONStringSerializer) AsJSON(name string, value interface{}, ig
 	if ignoreOmitempty {
 		value = MapMarshal(value, "json", false, true)
 	}
-	b, err := json.Marshal(value)
+	b, err := validator.codec.Marshal(value)
 	if err != nil {
 		return "", fmt.Errorf("parameter %q requires a JSON object, got %q: %s", name, value, err)
 	}
@@ -48,7 +50,12 @@ func (validator *JSONStringSerializer) AsJSON(name string, value interface{}, ig
 }
 
 func NewJSONStringSerializer() SerializerMixin {
+	return NewJSONStringSerializerWithCodec(entities.JSONCodec)
+}
+
+func NewJSONStringSerializerWithCodec(codec entities.VariableCodec) SerializerMixin {
 	return &JSONStringSerializer{
 		valueMap: make(map[string]interface{}),
+		codec:    codec,
 	}
 }
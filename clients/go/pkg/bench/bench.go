@@ -0,0 +1,174 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bench generates load on a Zeebe cluster for capacity planning. It creates workflow instances at a target
+// rate, completes their jobs with synthetic workers which simulate the latency of real work, and reports the latency
+// percentiles and backpressure of the commands.
+//
+//	report, err := bench.Run(ctx, client, bench.Config{
+//		Resource:      model,
+//		ResourceName:  "benchmark.bpmn",
+//		BPMNProcessID: "benchmark",
+//		Rate:          200,
+//		Duration:      5 * time.Minute,
+//		JobTypes:      []string{"benchmark-task"},
+//		JobLatency:    20 * time.Millisecond,
+//	})
+//	_ = report.Print(os.Stdout)
+package bench
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+)
+
+const (
+	// DefaultMaxInFlight is the default maximum number of instance creations which wait for their response.
+	DefaultMaxInFlight = 1000
+	// DefaultWorkerConcurrency is the default number of jobs a synthetic worker handles at the same time.
+	DefaultWorkerConcurrency = 32
+)
+
+// Config configures a benchmark run.
+type Config struct {
+	// Resource, if set, is a BPMN model which is deployed with the ResourceName before the run
+	Resource     []byte
+	ResourceName string
+	// BPMNProcessID is the workflow of which instances are created, in its latest version
+	BPMNProcessID string
+	// Variables, if not nil, are the JSON serializable variables of every created instance
+	Variables interface{}
+	// Rate is the target number of instances created per second, for the Duration of the run
+	Rate     float64
+	Duration time.Duration
+	// MaxInFlight limits the instance creations which wait for their response; if reached, the achieved rate drops
+	// below the target rate
+	MaxInFlight int
+
+	// JobTypes are the types of the jobs which are completed by synthetic workers, after JobLatency
+	JobTypes          []string
+	JobLatency        time.Duration
+	WorkerConcurrency int
+}
+
+// Run deploys the resource, if any, and creates instances at the target rate while the synthetic workers complete
+// their jobs. It returns the stats of the CreateWorkflowInstance and CompleteJob commands once the duration elapsed,
+// all instance creations returned and the workers are closed.
+func Run(ctx context.Context, client zbc.Client, config Config) (*Report, error) {
+	if config.Rate <= 0 || config.Duration <= 0 {
+		return nil, errors.New("expected a positive rate and duration")
+	}
+	if config.MaxInFlight <= 0 {
+		config.MaxInFlight = DefaultMaxInFlight
+	}
+	if config.WorkerConcurrency <= 0 {
+		config.WorkerConcurrency = DefaultWorkerConcurrency
+	}
+
+	if config.Resource != nil {
+		_, err := client.NewDeployWorkflowCommand().
+			AddResource(config.Resource, config.ResourceName, pb.WorkflowRequestObject_BPMN).
+			Send(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	recorder := newRecorder()
+	workers := make([]worker.JobWorker, len(config.JobTypes))
+	for i, jobType := range config.JobTypes {
+		workers[i] = client.NewJobWorker().
+			JobType(jobType).
+			Handler(completeAfter(recorder, config.JobLatency)).
+			MaxJobsActive(config.WorkerConcurrency).
+			Concurrency(config.WorkerConcurrency).
+			Open()
+	}
+
+	start := time.Now()
+	createInstances(ctx, client, config, recorder)
+	duration := time.Since(start)
+
+	for _, jobWorker := range workers {
+		jobWorker.Close()
+	}
+	return recorder.report(duration), nil
+}
+
+func createInstances(ctx context.Context, client zbc.Client, config Config, recorder *recorder) {
+	runCtx, cancel := context.WithTimeout(ctx, config.Duration)
+	defer cancel()
+
+	burst := int(config.Rate / 10)
+	if burst < 1 {
+		burst = 1
+	}
+	limiter := zbc.NewTokenBucketRateLimiter(config.Rate, burst)
+	inFlight := make(chan struct{}, config.MaxInFlight)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		if limiter.Wait(runCtx) != nil {
+			return
+		}
+		select {
+		case inFlight <- struct{}{}:
+		case <-runCtx.Done():
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-inFlight
+				wg.Done()
+			}()
+
+			sent := time.Now()
+			err := createInstance(ctx, client, config)
+			recorder.record("CreateWorkflowInstance", time.Since(sent), err)
+		}()
+	}
+}
+
+func createInstance(ctx context.Context, client zbc.Client, config Config) error {
+	command := client.NewCreateInstanceCommand().BPMNProcessId(config.BPMNProcessID).LatestVersion()
+	if config.Variables != nil {
+		var err error
+		if command, err = command.VariablesFromObject(config.Variables); err != nil {
+			return err
+		}
+	}
+
+	_, err := command.Send(ctx)
+	return err
+}
+
+func completeAfter(recorder *recorder, latency time.Duration) worker.JobHandler {
+	return func(client worker.JobClient, job entities.Job) {
+		time.Sleep(latency)
+
+		sent := time.Now()
+		_, err := client.NewCompleteJobCommand().JobKey(job.Key).Send(context.Background())
+		recorder.record("CompleteJob", time.Since(sent), err)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbtest/mockgateway"
+)
+
+func TestRun(t *testing.T) {
+	gateway, err := mockgateway.Start()
+	require.NoError(t, err)
+	defer gateway.Close()
+	client, err := gateway.NewClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	gateway.AddJobs(&pb.ActivatedJob{Type: "task"}, &pb.ActivatedJob{Type: "task"})
+	gateway.Respond("CreateWorkflowInstance", mockgateway.Response{Err: status.Error(codes.ResourceExhausted, "backpressure")})
+
+	report, err := Run(context.Background(), client, Config{
+		Resource:      []byte("<bpmn/>"),
+		ResourceName:  "benchmark.bpmn",
+		BPMNProcessID: "benchmark",
+		Rate:          100,
+		Duration:      500 * time.Millisecond,
+		JobTypes:      []string{"task"},
+		JobLatency:    time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	create := report.Commands["CreateWorkflowInstance"]
+	assert.InDelta(t, 50, create.Count, 15)
+	assert.Equal(t, 1, create.Errors)
+	assert.Equal(t, 1, create.Backpressure)
+	assert.Equal(t, 2, report.Commands["CompleteJob"].Count)
+
+	var output bytes.Buffer
+	require.NoError(t, report.Print(&output))
+	assert.True(t, strings.HasPrefix(output.String(), "COMMAND"))
+	assert.Contains(t, output.String(), "CreateWorkflowInstance")
+}
+
+func TestRunRejectsInvalidRate(t *testing.T) {
+	_, err := Run(context.Background(), nil, Config{Duration: time.Second})
+	assert.Error(t, err)
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	assert.Equal(t, 50*time.Millisecond, percentile(latencies, 0.5))
+	assert.Equal(t, 95*time.Millisecond, percentile(latencies, 0.95))
+	assert.Equal(t, 99*time.Millisecond, percentile(latencies, 0.99))
+	assert.Equal(t, time.Duration(0), percentile(nil, 0.5))
+}
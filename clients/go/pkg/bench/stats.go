@@ -0,0 +1,129 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Stats summarizes the outcomes of a command during a run. Backpressure counts the commands rejected with
+// RESOURCE_EXHAUSTED; they are included in Errors. The percentiles are over all sent commands.
+type Stats struct {
+	Count        int
+	Errors       int
+	Backpressure int
+	P50          time.Duration
+	P95          time.Duration
+	P99          time.Duration
+}
+
+// BackpressureRate is the fraction of commands which were rejected because of backpressure.
+func (s Stats) BackpressureRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Backpressure) / float64(s.Count)
+}
+
+// Report contains the Stats of a run per command name, e.g. "CreateWorkflowInstance".
+type Report struct {
+	Duration time.Duration
+	Commands map[string]Stats
+}
+
+// Print writes the report as table.
+func (r *Report) Print(w io.Writer) error {
+	names := make([]string, 0, len(r.Commands))
+	for name := range r.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(table, "COMMAND\tCOUNT\tRATE/S\tERRORS\tBACKPRESSURE\tP50\tP95\tP99")
+	for _, name := range names {
+		stats := r.Commands[name]
+		rate := float64(stats.Count) / r.Duration.Seconds()
+		_, _ = fmt.Fprintf(table, "%s\t%d\t%.1f\t%d\t%.2f%%\t%s\t%s\t%s\n", name, stats.Count, rate, stats.Errors,
+			100*stats.BackpressureRate(), stats.P50, stats.P95, stats.P99)
+	}
+	return table.Flush()
+}
+
+// recorder collects the latencies and errors of the commands sent during a run
+type recorder struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	stats     map[string]Stats
+}
+
+func newRecorder() *recorder {
+	return &recorder{latencies: make(map[string][]time.Duration), stats: make(map[string]Stats)}
+}
+
+func (r *recorder) record(command string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := r.stats[command]
+	stats.Count++
+	if err != nil {
+		stats.Errors++
+		if status.Code(err) == codes.ResourceExhausted {
+			stats.Backpressure++
+		}
+	}
+	r.stats[command] = stats
+	r.latencies[command] = append(r.latencies[command], latency)
+}
+
+func (r *recorder) report(duration time.Duration) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &Report{Duration: duration, Commands: make(map[string]Stats, len(r.stats))}
+	for command, stats := range r.stats {
+		latencies := append([]time.Duration(nil), r.latencies[command]...)
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		stats.P50 = percentile(latencies, 0.5)
+		stats.P95 = percentile(latencies, 0.95)
+		stats.P99 = percentile(latencies, 0.99)
+		report.Commands[command] = stats
+	}
+	return report
+}
+
+// percentile returns the nearest-rank percentile of the sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,197 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bpmn builds simple executable BPMN workflows in code, e.g. for tests:
+//
+//	definition, err := bpmn.NewProcess("order-process").
+//		StartEvent().
+//		ServiceTask("charge-card", "payment-service", bpmn.Retries(5)).
+//		EndEvent().
+//		Done()
+//
+// The resulting XML can be deployed with the DeployCommand. Validate checks existing BPMN resources for problems the
+// broker would reject.
+package bpmn
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+)
+
+// ServiceTaskOption configures a service task.
+type ServiceTaskOption func(*extensionElement)
+
+// Retries sets the number of retries of the jobs created for the service task.
+func Retries(retries int) ServiceTaskOption {
+	return func(extensions *extensionElement) {
+		extensions.TaskDefinition.Retries = strconv.Itoa(retries)
+	}
+}
+
+// TaskHeader adds a custom header to the jobs created for the service task.
+func TaskHeader(key, value string) ServiceTaskOption {
+	return func(extensions *extensionElement) {
+		if extensions.TaskHeaders == nil {
+			extensions.TaskHeaders = &taskHeaders{}
+		}
+		extensions.TaskHeaders.Headers = append(extensions.TaskHeaders.Headers, taskHeader{Key: key, Value: value})
+	}
+}
+
+type flowNodeKind int
+
+const (
+	startEvent flowNodeKind = iota
+	serviceTask
+	endEvent
+)
+
+type builtFlowNode struct {
+	kind flowNodeKind
+	node *flowNode
+}
+
+// ProcessBuilder builds a workflow as a sequence of flow nodes, each connected to the previous one. The first error is
+// kept and returned by Done.
+type ProcessBuilder struct {
+	definitions definitions
+	nodes       []builtFlowNode
+	ids         map[string]bool
+	startEvents int
+	endEvents   int
+	err         error
+}
+
+// NewProcess starts to build an executable workflow with the given BPMN process id.
+func NewProcess(bpmnProcessID string) *ProcessBuilder {
+	builder := &ProcessBuilder{
+		definitions: definitions{
+			BPMNNamespace:   bpmnNamespace,
+			ZeebeNamespace:  zeebeNamespace,
+			ID:              "Definitions_" + bpmnProcessID,
+			TargetNamespace: targetNamespace,
+			Process:         process{ID: bpmnProcessID, IsExecutable: true},
+		},
+		ids: map[string]bool{},
+	}
+
+	if bpmnProcessID == "" {
+		builder.err = fmt.Errorf("expected BPMN process id to be non-empty")
+	}
+
+	return builder
+}
+
+// Name sets the name of the workflow.
+func (b *ProcessBuilder) Name(name string) *ProcessBuilder {
+	b.definitions.Process.Name = name
+	return b
+}
+
+// StartEvent adds the none start event of the workflow. It must be the first flow node.
+func (b *ProcessBuilder) StartEvent() *ProcessBuilder {
+	b.startEvents++
+	b.add(startEvent, &flowNode{ID: fmt.Sprintf("StartEvent_%d", b.startEvents)})
+	return b
+}
+
+// ServiceTask adds a service task which creates jobs of the given type.
+func (b *ProcessBuilder) ServiceTask(id, jobType string, options ...ServiceTaskOption) *ProcessBuilder {
+	if b.err == nil && jobType == "" {
+		b.err = fmt.Errorf("expected job type of service task '%s' to be non-empty", id)
+	}
+
+	extensions := &extensionElement{TaskDefinition: &taskDefinition{Type: jobType}}
+	for _, option := range options {
+		option(extensions)
+	}
+
+	b.add(serviceTask, &flowNode{ID: id, Extensions: extensions})
+	return b
+}
+
+// EndEvent adds the none end event of the workflow. It must be the last flow node.
+func (b *ProcessBuilder) EndEvent() *ProcessBuilder {
+	b.endEvents++
+	b.add(endEvent, &flowNode{ID: fmt.Sprintf("EndEvent_%d", b.endEvents)})
+	return b
+}
+
+// Done validates the workflow and returns it as BPMN XML.
+func (b *ProcessBuilder) Done() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.nodes) == 0 || b.nodes[len(b.nodes)-1].kind != endEvent {
+		return nil, fmt.Errorf("expected process '%s' to end with an end event", b.definitions.Process.ID)
+	}
+
+	definitions := b.definitions
+	for _, built := range b.nodes {
+		switch built.kind {
+		case startEvent:
+			definitions.Process.StartEvents = append(definitions.Process.StartEvents, *built.node)
+		case serviceTask:
+			definitions.Process.ServiceTasks = append(definitions.Process.ServiceTasks, *built.node)
+		case endEvent:
+			definitions.Process.EndEvents = append(definitions.Process.EndEvents, *built.node)
+		}
+	}
+
+	definition, err := xml.MarshalIndent(definitions, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), definition...), nil
+}
+
+func (b *ProcessBuilder) add(kind flowNodeKind, node *flowNode) {
+	if b.err != nil {
+		return
+	}
+
+	processID := b.definitions.Process.ID
+	switch {
+	case node.ID == "":
+		b.err = fmt.Errorf("expected id of flow node in process '%s' to be non-empty", processID)
+	case b.ids[node.ID]:
+		b.err = fmt.Errorf("expected id '%s' to be unique in process '%s'", node.ID, processID)
+	case len(b.nodes) == 0 && kind != startEvent:
+		b.err = fmt.Errorf("expected process '%s' to begin with a start event", processID)
+	case len(b.nodes) > 0 && kind == startEvent:
+		b.err = fmt.Errorf("expected start event to be the first flow node of process '%s'", processID)
+	case len(b.nodes) > 0 && b.nodes[len(b.nodes)-1].kind == endEvent:
+		b.err = fmt.Errorf("expected no flow node after the end event of process '%s'", processID)
+	}
+	if b.err != nil {
+		return
+	}
+
+	if len(b.nodes) > 0 {
+		previous := b.nodes[len(b.nodes)-1].node
+		flow := sequenceFlow{
+			ID:        fmt.Sprintf("SequenceFlow_%d", len(b.definitions.Process.SequenceFlows)+1),
+			SourceRef: previous.ID,
+			TargetRef: node.ID,
+		}
+		b.definitions.Process.SequenceFlows = append(b.definitions.Process.SequenceFlows, flow)
+		previous.Outgoing = append(previous.Outgoing, flow.ID)
+		node.Incoming = append(node.Incoming, flow.ID)
+	}
+
+	b.ids[node.ID] = true
+	b.nodes = append(b.nodes, builtFlowNode{kind: kind, node: node})
+}
@@ -0,0 +1,63 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bpmn
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildProcess(t *testing.T) {
+	definition, err := NewProcess("order-process").
+		Name("Order").
+		StartEvent().
+		ServiceTask("charge-card", "payment-service", Retries(5), TaskHeader("currency", "EUR")).
+		ServiceTask("ship-order", "shipping-service").
+		EndEvent().
+		Done()
+	if err != nil {
+		t.Fatalf("Done() = %v", err)
+	}
+
+	golden, err := ioutil.ReadFile("testdata/order_process.bpmn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, string(golden), string(definition))
+}
+
+func TestRejectInvalidProcess(t *testing.T) {
+	cases := map[string]*ProcessBuilder{
+		"empty process id":        NewProcess("").StartEvent().EndEvent(),
+		"missing start event":     NewProcess("foo").ServiceTask("task", "bar").EndEvent(),
+		"second start event":      NewProcess("foo").StartEvent().StartEvent().EndEvent(),
+		"missing end event":       NewProcess("foo").StartEvent().ServiceTask("task", "bar"),
+		"flow node after end":     NewProcess("foo").StartEvent().EndEvent().ServiceTask("task", "bar"),
+		"empty job type":          NewProcess("foo").StartEvent().ServiceTask("task", "").EndEvent(),
+		"empty service task id":   NewProcess("foo").StartEvent().ServiceTask("", "bar").EndEvent(),
+		"duplicate flow node ids": NewProcess("foo").StartEvent().ServiceTask("task", "bar").ServiceTask("task", "baz").EndEvent(),
+	}
+
+	for name, builder := range cases {
+		t.Run(name, func(t *testing.T) {
+			definition, err := builder.Done()
+			assert.Error(t, err)
+			assert.Nil(t, definition)
+		})
+	}
+}
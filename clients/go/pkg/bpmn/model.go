@@ -0,0 +1,75 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bpmn
+
+import "encoding/xml"
+
+const (
+	bpmnNamespace   = "http://www.omg.org/spec/BPMN/20100524/MODEL"
+	zeebeNamespace  = "http://camunda.org/schema/zeebe/1.0"
+	targetNamespace = "http://bpmn.io/schema/bpmn"
+)
+
+type definitions struct {
+	XMLName         xml.Name `xml:"bpmn:definitions"`
+	BPMNNamespace   string   `xml:"xmlns:bpmn,attr"`
+	ZeebeNamespace  string   `xml:"xmlns:zeebe,attr"`
+	ID              string   `xml:"id,attr"`
+	TargetNamespace string   `xml:"targetNamespace,attr"`
+	Process         process  `xml:"bpmn:process"`
+}
+
+type process struct {
+	ID            string         `xml:"id,attr"`
+	Name          string         `xml:"name,attr,omitempty"`
+	IsExecutable  bool           `xml:"isExecutable,attr"`
+	StartEvents   []flowNode     `xml:"bpmn:startEvent"`
+	ServiceTasks  []flowNode     `xml:"bpmn:serviceTask"`
+	EndEvents     []flowNode     `xml:"bpmn:endEvent"`
+	SequenceFlows []sequenceFlow `xml:"bpmn:sequenceFlow"`
+}
+
+type flowNode struct {
+	ID         string            `xml:"id,attr"`
+	Name       string            `xml:"name,attr,omitempty"`
+	Extensions *extensionElement `xml:"bpmn:extensionElements,omitempty"`
+	Incoming   []string          `xml:"bpmn:incoming"`
+	Outgoing   []string          `xml:"bpmn:outgoing"`
+}
+
+type extensionElement struct {
+	TaskDefinition *taskDefinition `xml:"zeebe:taskDefinition,omitempty"`
+	TaskHeaders    *taskHeaders    `xml:"zeebe:taskHeaders,omitempty"`
+}
+
+type taskDefinition struct {
+	Type    string `xml:"type,attr"`
+	Retries string `xml:"retries,attr,omitempty"`
+}
+
+type taskHeaders struct {
+	Headers []taskHeader `xml:"zeebe:header"`
+}
+
+type taskHeader struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type sequenceFlow struct {
+	ID        string `xml:"id,attr"`
+	SourceRef string `xml:"sourceRef,attr"`
+	TargetRef string `xml:"targetRef,attr"`
+}
@@ -0,0 +1,92 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bpmn
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// ServiceTask is a service task of a BPMN model with the zeebe extension elements which define its jobs.
+type ServiceTask struct {
+	ProcessID string
+	ID        string
+	Name      string
+	JobType   string
+	Retries   string
+	Headers   map[string]string
+	Inputs    []Mapping
+	Outputs   []Mapping
+}
+
+// Mapping is an input or output mapping of a service task, which sets the target variable to the value of the source
+// expression.
+type Mapping struct {
+	Source string
+	Target string
+}
+
+// ServiceTasks returns the service tasks of all processes of the BPMN model, including the service tasks of sub
+// processes, in the order they are defined.
+func ServiceTasks(definition []byte) ([]ServiceTask, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(definition))
+	var tasks []ServiceTask
+	var processID string
+	var task *ServiceTask
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse BPMN model: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case isBPMN(t.Name, "process"):
+				processID = attribute(t, "id")
+			case isBPMN(t.Name, "serviceTask"):
+				task = &ServiceTask{ProcessID: processID, ID: attribute(t, "id"), Name: attribute(t, "name"), Headers: map[string]string{}}
+			case task != nil && t.Name.Space == zeebeNamespace:
+				addExtension(task, t)
+			}
+		case xml.EndElement:
+			if task != nil && isBPMN(t.Name, "serviceTask") {
+				tasks = append(tasks, *task)
+				task = nil
+			}
+		}
+	}
+
+	return tasks, nil
+}
+
+func addExtension(task *ServiceTask, element xml.StartElement) {
+	switch element.Name.Local {
+	case "taskDefinition":
+		task.JobType = attribute(element, "type")
+		task.Retries = attribute(element, "retries")
+	case "header":
+		task.Headers[attribute(element, "key")] = attribute(element, "value")
+	case "input":
+		task.Inputs = append(task.Inputs, Mapping{Source: attribute(element, "source"), Target: attribute(element, "target")})
+	case "output":
+		task.Outputs = append(task.Outputs, Mapping{Source: attribute(element, "source"), Target: attribute(element, "target")})
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bpmn
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mappedProcess = `<?xml version="1.0" encoding="UTF-8"?>
+<definitions xmlns="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:zeebe="http://camunda.org/schema/zeebe/1.0" id="definitions">
+  <process id="refund-process" isExecutable="true">
+    <subProcess id="refund">
+      <serviceTask id="refund-payment" name="Refund payment">
+        <extensionElements>
+          <zeebe:taskDefinition type="refund-service" />
+          <zeebe:ioMapping>
+            <zeebe:input source="= order.paymentId" target="paymentId" />
+            <zeebe:output source="= refundId" target="refund.id" />
+          </zeebe:ioMapping>
+        </extensionElements>
+      </serviceTask>
+    </subProcess>
+  </process>
+</definitions>`
+
+func TestServiceTasks(t *testing.T) {
+	definition, err := ioutil.ReadFile("testdata/order_process.bpmn")
+	require.NoError(t, err)
+
+	tasks, err := ServiceTasks(definition)
+
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceTask{
+		{ProcessID: "order-process", ID: "charge-card", JobType: "payment-service", Retries: "5", Headers: map[string]string{"currency": "EUR"}},
+		{ProcessID: "order-process", ID: "ship-order", JobType: "shipping-service", Headers: map[string]string{}},
+	}, tasks)
+}
+
+func TestServiceTasksWithMappings(t *testing.T) {
+	tasks, err := ServiceTasks([]byte(mappedProcess))
+
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceTask{{
+		ProcessID: "refund-process",
+		ID:        "refund-payment",
+		Name:      "Refund payment",
+		JobType:   "refund-service",
+		Headers:   map[string]string{},
+		Inputs:    []Mapping{{Source: "= order.paymentId", Target: "paymentId"}},
+		Outputs:   []Mapping{{Source: "= refundId", Target: "refund.id"}},
+	}}, tasks)
+}
+
+func TestServiceTasksOfMalformedModel(t *testing.T) {
+	_, err := ServiceTasks([]byte(`<definitions><process>`))
+
+	assert.Error(t, err)
+}
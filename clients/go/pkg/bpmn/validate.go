@@ -0,0 +1,204 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bpmn
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// flowElementTypes are the BPMN elements which can be contained in a process. Only the supported ones can be deployed.
+var flowElementTypes = map[string]bool{
+	"boundaryEvent":          true,
+	"callActivity":           true,
+	"endEvent":               true,
+	"eventBasedGateway":      true,
+	"exclusiveGateway":       true,
+	"intermediateCatchEvent": true,
+	"parallelGateway":        true,
+	"receiveTask":            true,
+	"sequenceFlow":           true,
+	"serviceTask":            true,
+	"startEvent":             true,
+	"subProcess":             true,
+	"dataObject":             true,
+	"dataObjectReference":    true,
+	"dataStoreReference":     true,
+
+	"adHocSubProcess":        false,
+	"businessRuleTask":       false,
+	"callChoreography":       false,
+	"choreographyTask":       false,
+	"complexGateway":         false,
+	"inclusiveGateway":       false,
+	"intermediateThrowEvent": false,
+	"manualTask":             false,
+	"scriptTask":             false,
+	"sendTask":               false,
+	"subChoreography":        false,
+	"task":                   false,
+	"transaction":            false,
+	"userTask":               false,
+}
+
+// Diagnostic is a problem found in a BPMN resource by Validate.
+type Diagnostic struct {
+	Resource  string
+	Line      int
+	ElementID string
+	Message   string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s", d.Resource, d.Line, d.Message)
+}
+
+// ValidationError is returned if a resource cannot be deployed because of the diagnostics.
+type ValidationError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Diagnostics))
+	for i, diagnostic := range e.Diagnostics {
+		lines[i] = diagnostic.String()
+	}
+	return "invalid BPMN resources:\n" + strings.Join(lines, "\n")
+}
+
+type parsedElement struct {
+	name    xml.Name
+	id      string
+	line    int
+	jobType bool
+}
+
+type reference struct {
+	flowID string
+	ref    string
+	line   int
+}
+
+// Validate checks a BPMN resource for problems which the broker would reject on deployment: malformed XML, elements
+// which are not supported, service tasks without a job type and sequence flows which refer to unknown flow nodes. The
+// diagnostics are ordered by line.
+func Validate(resource string, definition []byte) []Diagnostic {
+	v := validator{resource: resource, ids: map[string]bool{}}
+	v.validate(definition)
+
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		return v.diagnostics[i].Line < v.diagnostics[j].Line
+	})
+	return v.diagnostics
+}
+
+type validator struct {
+	resource    string
+	diagnostics []Diagnostic
+	ids         map[string]bool
+	references  []reference
+}
+
+func (v *validator) validate(definition []byte) {
+	decoder := xml.NewDecoder(bytes.NewReader(definition))
+	var stack []*parsedElement
+	line, offset := 1, int64(0)
+
+	for {
+		token, err := decoder.Token()
+		current := decoder.InputOffset()
+		line += bytes.Count(definition[offset:current], []byte("\n"))
+		offset = current
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			v.report(line, "", fmt.Sprintf("malformed XML: %s", err))
+			return
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := &parsedElement{name: t.Name, id: attribute(t, "id"), line: line}
+			if len(stack) > 0 {
+				v.checkChild(stack[len(stack)-1], element, t)
+			}
+			// the task definition is nested in the extension elements of the service task
+			if len(stack) > 1 && t.Name.Space == zeebeNamespace && t.Name.Local == "taskDefinition" && attribute(t, "type") != "" {
+				stack[len(stack)-2].jobType = true
+			}
+			stack = append(stack, element)
+		case xml.EndElement:
+			element := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if isBPMN(element.name, "serviceTask") && !element.jobType {
+				v.report(element.line, element.id, fmt.Sprintf("service task '%s' has no job type", element.id))
+			}
+		}
+	}
+
+	for _, ref := range v.references {
+		if !v.ids[ref.ref] {
+			v.report(ref.line, ref.flowID, fmt.Sprintf("sequence flow '%s' refers to unknown flow node '%s'", ref.flowID, ref.ref))
+		}
+	}
+}
+
+func (v *validator) checkChild(parent, element *parsedElement, start xml.StartElement) {
+	if !isBPMN(parent.name, "process") && !isBPMN(parent.name, "subProcess") {
+		return
+	}
+	if element.name.Space != bpmnNamespace {
+		return
+	}
+
+	supported, isFlowElement := flowElementTypes[element.name.Local]
+	if !isFlowElement {
+		return
+	}
+
+	if element.id != "" {
+		v.ids[element.id] = true
+	}
+	if !supported {
+		v.report(element.line, element.id, fmt.Sprintf("element '%s' of type %s is not supported", element.id, element.name.Local))
+	}
+	if element.name.Local == "sequenceFlow" {
+		for _, ref := range []string{attribute(start, "sourceRef"), attribute(start, "targetRef")} {
+			v.references = append(v.references, reference{flowID: element.id, ref: ref, line: element.line})
+		}
+	}
+}
+
+func (v *validator) report(line int, elementID, message string) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{Resource: v.resource, Line: line, ElementID: elementID, Message: message})
+}
+
+func isBPMN(name xml.Name, local string) bool {
+	return name.Space == bpmnNamespace && name.Local == local
+}
+
+func attribute(start xml.StartElement, name string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bpmn
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const invalidProcess = `<?xml version="1.0" encoding="UTF-8"?>
+<bpmn:definitions xmlns:bpmn="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:zeebe="http://camunda.org/schema/zeebe/1.0" id="definitions">
+  <bpmn:process id="invalid-process" isExecutable="true">
+    <bpmn:startEvent id="start" />
+    <bpmn:userTask id="approve" />
+    <bpmn:serviceTask id="charge">
+      <bpmn:extensionElements>
+        <zeebe:taskHeaders />
+      </bpmn:extensionElements>
+    </bpmn:serviceTask>
+    <bpmn:sequenceFlow id="flow" sourceRef="start" targetRef="missing" />
+  </bpmn:process>
+</bpmn:definitions>`
+
+func TestValidateBuiltProcess(t *testing.T) {
+	definition, err := NewProcess("order-process").
+		StartEvent().
+		ServiceTask("charge-card", "payment-service").
+		EndEvent().
+		Done()
+	require.NoError(t, err)
+
+	assert.Empty(t, Validate("order-process.bpmn", definition))
+}
+
+func TestValidateDemoProcess(t *testing.T) {
+	definition, err := ioutil.ReadFile("../../../java/src/test/resources/workflows/demo-process.bpmn")
+	require.NoError(t, err)
+
+	assert.Empty(t, Validate("demo-process.bpmn", definition))
+}
+
+func TestValidateReportsDiagnosticsWithLines(t *testing.T) {
+	diagnostics := Validate("invalid.bpmn", []byte(invalidProcess))
+
+	assert.Equal(t, []Diagnostic{
+		{Resource: "invalid.bpmn", Line: 5, ElementID: "approve", Message: "element 'approve' of type userTask is not supported"},
+		{Resource: "invalid.bpmn", Line: 6, ElementID: "charge", Message: "service task 'charge' has no job type"},
+		{Resource: "invalid.bpmn", Line: 11, ElementID: "flow", Message: "sequence flow 'flow' refers to unknown flow node 'missing'"},
+	}, diagnostics)
+}
+
+func TestValidateMalformedXML(t *testing.T) {
+	diagnostics := Validate("malformed.bpmn", []byte("<bpmn:definitions>\n<bpmn:process>\n</bpmn:definitions>"))
+
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, 3, diagnostics[0].Line)
+	assert.Contains(t, diagnostics[0].Message, "malformed XML")
+}
+
+func TestValidationError(t *testing.T) {
+	err := &ValidationError{Diagnostics: Validate("invalid.bpmn", []byte(invalidProcess))}
+
+	assert.Contains(t, err.Error(), "invalid.bpmn:6: service task 'charge' has no job type")
+}
@@ -0,0 +1,351 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codegen generates Go types and job workers for the service tasks of BPMN models, see command zeebe-gen.
+//
+// For every job type, it generates the constant of the job type, an input struct with the variables which are set
+// by the input mappings of its service tasks, an output struct with the variables which are read by their output
+// mappings, and a handler type which receives the input and returns the output. The generated Register function opens
+// a job worker for every handler, so regenerating the code after a model changed breaks the build where the handlers
+// no longer match the model.
+//
+// The models don't define the types of variables, so the fields of the structs are interface{} values.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/bpmn"
+)
+
+// Model is a BPMN model which the code is generated for.
+type Model struct {
+	// Name of the model, e.g. its file name, which is mentioned in the generated code
+	Name       string
+	Definition []byte
+}
+
+type jobType struct {
+	Name    string
+	JobType string
+	Tasks   []string
+	Inputs  []field
+	Outputs []field
+	// FetchAll is set if a service task of the job type has no input mappings, so all variables are visible to it
+	FetchAll bool
+}
+
+type field struct {
+	Name     string
+	Variable string
+}
+
+// Generate returns the formatted Go source of the given package for the service tasks of the models.
+func Generate(packageName string, models ...Model) ([]byte, error) {
+	jobTypes, err := collectJobTypes(models)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(models))
+	for i, model := range models {
+		names[i] = model.Name
+	}
+
+	var source bytes.Buffer
+	err = sourceTemplate.Execute(&source, struct {
+		Package  string
+		Models   string
+		JobTypes []*jobType
+	}{packageName, strings.Join(names, ", "), jobTypes})
+	if err != nil {
+		return nil, err
+	}
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+func collectJobTypes(models []Model) ([]*jobType, error) {
+	byJobType := make(map[string]*jobType)
+	byName := make(map[string]string)
+	inputs := make(map[string]map[string]bool)
+	outputs := make(map[string]map[string]bool)
+
+	for _, model := range models {
+		tasks, err := bpmn.ServiceTasks(model.Definition)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", model.Name, err)
+		}
+
+		for _, task := range tasks {
+			if task.JobType == "" {
+				return nil, fmt.Errorf("%s: service task '%s' has no job type", model.Name, task.ID)
+			}
+
+			spec, ok := byJobType[task.JobType]
+			if !ok {
+				name, err := identifier(task.JobType)
+				if err != nil {
+					return nil, fmt.Errorf("%s: service task '%s': %w", model.Name, task.ID, err)
+				}
+				if other, exists := byName[name]; exists {
+					return nil, fmt.Errorf("job types '%s' and '%s' have the same Go name %s", other, task.JobType, name)
+				}
+				byName[name] = task.JobType
+
+				spec = &jobType{Name: name, JobType: task.JobType}
+				byJobType[task.JobType] = spec
+				inputs[task.JobType] = make(map[string]bool)
+				outputs[task.JobType] = make(map[string]bool)
+			}
+
+			spec.Tasks = append(spec.Tasks, task.ProcessID+"/"+task.ID)
+			if len(task.Inputs) == 0 {
+				spec.FetchAll = true
+			}
+			for _, input := range task.Inputs {
+				if variable := rootVariable(input.Target); variable != "" {
+					inputs[task.JobType][variable] = true
+				}
+			}
+			for _, output := range task.Outputs {
+				if variable := rootVariable(output.Source); variable != "" {
+					outputs[task.JobType][variable] = true
+				}
+			}
+		}
+	}
+
+	if len(byJobType) == 0 {
+		return nil, fmt.Errorf("expected the models to contain service tasks, but they contain none")
+	}
+
+	jobTypes := make([]*jobType, 0, len(byJobType))
+	for _, spec := range byJobType {
+		var err error
+		if spec.Inputs, err = fields(spec.JobType, inputs[spec.JobType]); err != nil {
+			return nil, err
+		}
+		if spec.Outputs, err = fields(spec.JobType, outputs[spec.JobType]); err != nil {
+			return nil, err
+		}
+		jobTypes = append(jobTypes, spec)
+	}
+	sort.Slice(jobTypes, func(i, j int) bool {
+		return jobTypes[i].JobType < jobTypes[j].JobType
+	})
+	return jobTypes, nil
+}
+
+func fields(jobType string, variables map[string]bool) ([]field, error) {
+	fields := make([]field, 0, len(variables))
+	names := make(map[string]string)
+	for variable := range variables {
+		name, err := identifier(variable)
+		if err != nil {
+			return nil, fmt.Errorf("job type '%s': %w", jobType, err)
+		}
+		if other, exists := names[name]; exists {
+			return nil, fmt.Errorf("variables '%s' and '%s' of job type '%s' have the same Go name %s", other, variable, jobType, name)
+		}
+		names[name] = variable
+		fields = append(fields, field{Name: name, Variable: variable})
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Variable < fields[j].Variable
+	})
+	return fields, nil
+}
+
+var (
+	variablePattern = regexp.MustCompile(`^\s*=?\s*(?:\$\.)?([A-Za-z_][A-Za-z0-9_]*)\s*(\(?)`)
+	feelKeywords    = map[string]bool{"true": true, "false": true, "null": true, "if": true, "for": true, "some": true, "every": true, "not": true}
+)
+
+// rootVariable returns the variable which is referenced by the start of a mapping, e.g. 'order' for '= order.id' or
+// '$.order.id', or an empty string if the mapping does not start with a variable, e.g. a function call.
+func rootVariable(mapping string) string {
+	match := variablePattern.FindStringSubmatch(mapping)
+	if match == nil || feelKeywords[match[1]] || match[2] != "" {
+		return ""
+	}
+	return match[1]
+}
+
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "CSS": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "SQL": true, "TCP": true, "TTL": true, "UI": true, "URI": true, "URL": true,
+	"UUID": true, "XML": true,
+}
+
+// identifier converts a job type or variable name to an exported Go identifier, e.g. 'payment-service' to
+// PaymentService and 'orderId' to OrderID.
+func identifier(name string) (string, error) {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	if len(words) == 0 {
+		return "", fmt.Errorf("expected '%s' to contain letters or digits", name)
+	}
+
+	var result strings.Builder
+	for _, word := range words {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			result.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		result.WriteRune(unicode.ToUpper(runes[0]))
+		result.WriteString(string(runes[1:]))
+	}
+
+	identifier := result.String()
+	if !unicode.IsLetter([]rune(identifier)[0]) {
+		identifier = "X" + identifier
+	}
+	return identifier, nil
+}
+
+func quoteAll(values []field) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value.Variable)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+var sourceTemplate = template.Must(template.New("source").Funcs(template.FuncMap{
+	"join":     strings.Join,
+	"quoteAll": quoteAll,
+}).Parse(`// Code generated by zeebe-gen from {{.Models}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
+)
+{{range .JobTypes}}
+// {{.Name}}JobType is the job type of the service tasks {{join .Tasks ", "}}.
+const {{.Name}}JobType = {{printf "%q" .JobType}}
+
+// {{.Name}}Input are the input variables of the jobs of type {{.JobType}}.
+{{- if .Inputs}}
+type {{.Name}}Input struct {
+{{- range .Inputs}}
+	{{.Name}} interface{} ` + "`" + `json:"{{.Variable}}"` + "`" + `
+{{- end}}
+}
+{{- else}}
+type {{.Name}}Input struct{}
+{{- end}}
+
+// {{.Name}}Output are the output variables of the jobs of type {{.JobType}}. Variables which are nil are not set.
+{{- if .Outputs}}
+type {{.Name}}Output struct {
+{{- range .Outputs}}
+	{{.Name}} interface{} ` + "`" + `json:"{{.Variable}},omitempty"` + "`" + `
+{{- end}}
+}
+{{- else}}
+type {{.Name}}Output struct{}
+{{- end}}
+
+// {{.Name}}Handler handles the jobs of type {{.JobType}}. The job is completed with the output, or passed to the
+// failure handler of the worker if an error is returned.
+type {{.Name}}Handler func(client worker.JobClient, job entities.Job, input {{.Name}}Input) ({{.Name}}Output, error)
+
+func (handler {{.Name}}Handler) handle(client worker.JobClient, job entities.Job) error {
+	var input {{.Name}}Input
+	if err := job.GetVariablesAs(&input); err != nil {
+		return err
+	}
+
+	output, err := handler(client, job, input)
+	if err != nil {
+		return err
+	}
+	return completeJob(client, job.Key, output)
+}
+{{end}}
+// Handlers are the handlers of the job types of {{.Models}}.
+type Handlers struct {
+{{- range .JobTypes}}
+	{{.Name}} {{.Name}}Handler
+{{- end}}
+}
+
+// Register opens a job worker for the handler of every job type, which fetches only the variables of its input. If a
+// handler is nil, no worker is opened and an error is returned.
+func Register(client worker.WorkerClient, handlers Handlers) ([]worker.JobWorker, error) {
+{{- range .JobTypes}}
+	if handlers.{{.Name}} == nil {
+		return nil, fmt.Errorf("expected a handler for job type %q", {{.Name}}JobType)
+	}
+{{- end}}
+
+	return []worker.JobWorker{
+{{- range .JobTypes}}
+		client.NewJobWorker().JobType({{.Name}}JobType).FallibleHandler(handlers.{{.Name}}.handle){{if not .FetchAll}}.FetchVariables({{quoteAll .Inputs}}){{end}}.Open(),
+{{- end}}
+	}, nil
+}
+
+func completeJob(client worker.JobClient, jobKey int64, output interface{}) error {
+	command, err := client.NewCompleteJobCommand().JobKey(jobKey).VariablesFromObject(output)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), worker.DefaultRequestTimeout)
+	defer cancel()
+	_, err = command.Send(ctx)
+	return err
+}
+`))
@@ -0,0 +1,102 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const process = `<?xml version="1.0" encoding="UTF-8"?>
+<bpmn:definitions xmlns:bpmn="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:zeebe="http://camunda.org/schema/zeebe/1.0" id="definitions">
+  <bpmn:process id="process" isExecutable="true">
+    %s
+  </bpmn:process>
+</bpmn:definitions>`
+
+func serviceTask(id, jobType string) string {
+	return `<bpmn:serviceTask id="` + id + `"><bpmn:extensionElements><zeebe:taskDefinition type="` + jobType + `" /></bpmn:extensionElements></bpmn:serviceTask>`
+}
+
+func model(tasks ...string) Model {
+	return Model{Name: "process.bpmn", Definition: []byte(fmt.Sprintf(process, strings.Join(tasks, "")))}
+}
+
+func TestGenerate(t *testing.T) {
+	definition, err := ioutil.ReadFile("testdata/order.bpmn")
+	require.NoError(t, err)
+	golden, err := ioutil.ReadFile("testdata/order.golden")
+	require.NoError(t, err)
+
+	source, err := Generate("orders", Model{Name: "order.bpmn", Definition: definition})
+
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), string(source))
+}
+
+func TestGenerateWithoutServiceTasks(t *testing.T) {
+	_, err := Generate("orders", model())
+
+	assert.Error(t, err)
+}
+
+func TestGenerateWithoutJobType(t *testing.T) {
+	_, err := Generate("orders", model(serviceTask("task", "")))
+
+	assert.EqualError(t, err, "process.bpmn: service task 'task' has no job type")
+}
+
+func TestGenerateWithConflictingJobTypes(t *testing.T) {
+	_, err := Generate("orders", model(serviceTask("a", "payment-service"), serviceTask("b", "payment_service")))
+
+	assert.EqualError(t, err, "job types 'payment-service' and 'payment_service' have the same Go name PaymentService")
+}
+
+func TestIdentifier(t *testing.T) {
+	for name, expected := range map[string]string{
+		"payment-service": "PaymentService",
+		"orderId":         "OrderID",
+		"callbackUrl":     "CallbackURL",
+		"order_total":     "OrderTotal",
+		"3ds-check":       "X3dsCheck",
+	} {
+		actual, err := identifier(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, name)
+	}
+
+	_, err := identifier("--")
+	assert.Error(t, err)
+}
+
+func TestRootVariable(t *testing.T) {
+	for mapping, expected := range map[string]string{
+		"= order.id":     "order",
+		"=paymentId":     "paymentId",
+		"$.order.items":  "order",
+		"total":          "total",
+		"= true":         "",
+		`= "constant"`:   "",
+		"= if a then b":  "",
+		"= count(items)": "",
+	} {
+		assert.Equal(t, expected, rootVariable(mapping), mapping)
+	}
+}
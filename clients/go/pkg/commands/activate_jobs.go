@@ -29,6 +29,7 @@ const (
 )
 
 type DispatchActivateJobsCommand interface {
+	RequestTimeout(time.Duration) DispatchActivateJobsCommand
 	Send(ctx context.Context) ([]entities.Job, error)
 }
 
@@ -51,6 +52,7 @@ type ActivateJobsCommandStep3 interface {
 type ActivateJobsCommand struct {
 	Command
 	request pb.ActivateJobsRequest
+	codec   entities.VariableCodec
 }
 
 func (cmd *ActivateJobsCommand) JobType(jobType string) ActivateJobsCommandStep2 {
@@ -78,13 +80,21 @@ func (cmd *ActivateJobsCommand) FetchVariables(fetchVariables ...string) Activat
 	return cmd
 }
 
+func (cmd *ActivateJobsCommand) RequestTimeout(timeout time.Duration) DispatchActivateJobsCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
 func (cmd *ActivateJobsCommand) Send(ctx context.Context) ([]entities.Job, error) {
+	ctx, cancel := cmd.withRequestTimeout(ctx)
+	defer cancel()
+
 	cmd.request.RequestTimeout = getLongPollingMillis(ctx)
 
 	stream, err := cmd.gateway.ActivateJobs(ctx, &cmd.request)
 	if err != nil {
 		if cmd.shouldRetry(ctx, err) {
-			return cmd.Send(ctx)
+			return cmd.Send(WithNextAttempt(ctx))
 		}
 		return nil, err
 	}
@@ -100,7 +110,7 @@ func (cmd *ActivateJobsCommand) Send(ctx context.Context) ([]entities.Job, error
 			return activatedJobs, err
 		}
 		for _, activatedJob := range response.Jobs {
-			activatedJobs = append(activatedJobs, entities.Job{ActivatedJob: *activatedJob})
+			activatedJobs = append(activatedJobs, entities.NewJob(activatedJob, cmd.codec))
 		}
 	}
 
@@ -108,7 +118,12 @@ func (cmd *ActivateJobsCommand) Send(ctx context.Context) ([]entities.Job, error
 }
 
 func NewActivateJobsCommand(gateway pb.GatewayClient, pred retryPredicate) ActivateJobsCommandStep1 {
+	return NewActivateJobsCommandWithCodec(gateway, pred, entities.JSONCodec)
+}
+
+func NewActivateJobsCommandWithCodec(gateway pb.GatewayClient, pred retryPredicate, codec entities.VariableCodec) ActivateJobsCommandStep1 {
 	return &ActivateJobsCommand{
+		codec: codec,
 		request: pb.ActivateJobsRequest{
 			Timeout: DefaultJobTimeoutInMs,
 			Worker:  DefaultJobWorkerName,
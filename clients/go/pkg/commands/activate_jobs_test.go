@@ -99,13 +99,13 @@ func TestActivateJobsCommand(t *testing.T) {
 
 	var expectedJobs []entities.Job
 	for _, job := range response1.Jobs {
-		expectedJobs = append(expectedJobs, entities.Job{ActivatedJob: *job})
+		expectedJobs = append(expectedJobs, entities.NewJob(job, entities.JSONCodec))
 	}
 	for _, job := range response2.Jobs {
-		expectedJobs = append(expectedJobs, entities.Job{ActivatedJob: *job})
+		expectedJobs = append(expectedJobs, entities.NewJob(job, entities.JSONCodec))
 	}
 	for _, job := range response3.Jobs {
-		expectedJobs = append(expectedJobs, entities.Job{ActivatedJob: *job})
+		expectedJobs = append(expectedJobs, entities.NewJob(job, entities.JSONCodec))
 	}
 
 	gomock.InOrder(
@@ -243,3 +243,35 @@ func TestActivateJobsCommandWithFetchVariables(t *testing.T) {
 		t.Errorf("Failed to receive response")
 	}
 }
+
+func TestActivateJobsCommandWithRequestTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	stream := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+	stream.EXPECT().Recv().Return(nil, io.EOF)
+
+	var requestTimeout int64
+	client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *pb.ActivateJobsRequest, _ ...interface{}) (pb.Gateway_ActivateJobsClient, error) {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Error("expected context to have a deadline")
+			}
+			requestTimeout = request.RequestTimeout
+			return stream, nil
+		})
+
+	_, err := NewActivateJobsCommand(client, func(context.Context, error) bool { return false }).
+		JobType("foo").
+		MaxJobsToActivate(5).
+		RequestTimeout(2 * time.Second).
+		Send(context.Background())
+	if err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	if requestTimeout <= 0 || requestTimeout > 2000 {
+		t.Errorf("Expected request timeout of the gateway to be derived from the command timeout, but got %d", requestTimeout)
+	}
+}
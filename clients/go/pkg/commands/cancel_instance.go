@@ -17,6 +17,7 @@ package commands
 import (
 	"context"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"time"
 )
 
 type CancelInstanceStep1 interface {
@@ -24,6 +25,7 @@ type CancelInstanceStep1 interface {
 }
 
 type DispatchCancelWorkflowInstanceCommand interface {
+	RequestTimeout(time.Duration) DispatchCancelWorkflowInstanceCommand
 	Send(context.Context) (*pb.CancelWorkflowInstanceResponse, error)
 }
 
@@ -32,10 +34,18 @@ type CancelWorkflowInstanceCommand struct {
 	request pb.CancelWorkflowInstanceRequest
 }
 
+func (cmd CancelWorkflowInstanceCommand) RequestTimeout(timeout time.Duration) DispatchCancelWorkflowInstanceCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
 func (cmd CancelWorkflowInstanceCommand) Send(ctx context.Context) (*pb.CancelWorkflowInstanceResponse, error) {
+	ctx, cancel := cmd.withRequestTimeout(ctx)
+	defer cancel()
+
 	response, err := cmd.gateway.CancelWorkflowInstance(ctx, &cmd.request)
 	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+		return cmd.Send(WithNextAttempt(ctx))
 	}
 
 	return response, err
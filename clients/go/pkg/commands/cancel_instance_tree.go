@@ -0,0 +1,203 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+const (
+	// DefaultCancelInstanceTreeMaxInstances is the default number of instances of a tree which are cancelled at most.
+	DefaultCancelInstanceTreeMaxInstances = 1000
+	// DefaultCancelInstanceTreeMaxAttempts is the default number of times an instance of a tree is tried to be cancelled.
+	DefaultCancelInstanceTreeMaxAttempts = 3
+	// DefaultCancelInstanceTreeBackoff is the default time which is waited before an instance is tried again, multiplied
+	// by the number of attempts so far.
+	DefaultCancelInstanceTreeBackoff = 100 * time.Millisecond
+)
+
+// ChildInstanceLookup returns the keys of the workflow instances which were created by the call activities of a
+// workflow instance, e.g. records.InstanceTree, or a lookup in the exported data of Operate or Elasticsearch.
+type ChildInstanceLookup interface {
+	ChildInstances(ctx context.Context, workflowInstanceKey int64) ([]int64, error)
+}
+
+// ChildInstanceLookupFunc adapts a function to the ChildInstanceLookup interface.
+type ChildInstanceLookupFunc func(ctx context.Context, workflowInstanceKey int64) ([]int64, error)
+
+func (f ChildInstanceLookupFunc) ChildInstances(ctx context.Context, workflowInstanceKey int64) ([]int64, error) {
+	return f(ctx, workflowInstanceKey)
+}
+
+// CancelInstanceTreeResult lists the instances of a tree, in the order in which they are cancelled. Instances which
+// did not exist anymore, e.g. because they were terminated with their parent, are not found.
+type CancelInstanceTreeResult struct {
+	Instances []int64
+	Cancelled []int64
+	NotFound  []int64
+	DryRun    bool
+}
+
+type CancelInstanceTreeCommand struct {
+	Command
+	rootKey      int64
+	lookup       ChildInstanceLookup
+	dryRun       bool
+	maxInstances int
+	maxAttempts  int
+	backoff      time.Duration
+}
+
+func (cmd *CancelInstanceTreeCommand) WorkflowInstanceKey(key int64) *CancelInstanceTreeCommand {
+	cmd.rootKey = key
+	return cmd
+}
+
+// ChildInstances sets the lookup of the child instances; without it, only the root instance is cancelled.
+func (cmd *CancelInstanceTreeCommand) ChildInstances(lookup ChildInstanceLookup) *CancelInstanceTreeCommand {
+	cmd.lookup = lookup
+	return cmd
+}
+
+// DryRun only discovers the instances of the tree, which are returned in the result, without cancelling them.
+func (cmd *CancelInstanceTreeCommand) DryRun() *CancelInstanceTreeCommand {
+	cmd.dryRun = true
+	return cmd
+}
+
+// MaxInstances sets the number of instances of the tree which are cancelled at most. If the tree contains more
+// instances, none is cancelled, so a wrong lookup can't cancel unrelated instances.
+func (cmd *CancelInstanceTreeCommand) MaxInstances(maxInstances int) *CancelInstanceTreeCommand {
+	if maxInstances > 0 {
+		cmd.maxInstances = maxInstances
+	}
+	return cmd
+}
+
+// MaxAttempts sets how many times an instance is tried to be cancelled if the gateway is unavailable or overloaded.
+func (cmd *CancelInstanceTreeCommand) MaxAttempts(maxAttempts int) *CancelInstanceTreeCommand {
+	if maxAttempts > 0 {
+		cmd.maxAttempts = maxAttempts
+	}
+	return cmd
+}
+
+func (cmd *CancelInstanceTreeCommand) RequestTimeout(timeout time.Duration) *CancelInstanceTreeCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
+// Send discovers the child instances of the root instance, transitively, and cancels the tree from the root to the
+// leaves, so a parent can't create child instances which were not discovered. Instances which don't exist anymore are
+// skipped, so the command can be sent again after it failed. If an instance can't be cancelled, the instances after
+// it are not cancelled and the result so far is returned with the error.
+func (cmd *CancelInstanceTreeCommand) Send(ctx context.Context) (*CancelInstanceTreeResult, error) {
+	instances, err := cmd.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CancelInstanceTreeResult{Instances: instances, DryRun: cmd.dryRun}
+	if cmd.dryRun {
+		return result, nil
+	}
+
+	for _, key := range instances {
+		err := cmd.cancel(ctx, key)
+		switch {
+		case err == nil:
+			result.Cancelled = append(result.Cancelled, key)
+		case status.Code(err) == codes.NotFound:
+			result.NotFound = append(result.NotFound, key)
+		default:
+			return result, fmt.Errorf("failed to cancel workflow instance %d of tree %d: %w", key, cmd.rootKey, err)
+		}
+	}
+	return result, nil
+}
+
+// discover returns the keys of the tree in breadth first order, starting with the root.
+func (cmd *CancelInstanceTreeCommand) discover(ctx context.Context) ([]int64, error) {
+	instances := []int64{cmd.rootKey}
+	if cmd.lookup == nil {
+		return instances, nil
+	}
+
+	visited := map[int64]bool{cmd.rootKey: true}
+	for i := 0; i < len(instances); i++ {
+		children, err := cmd.lookup.ChildInstances(ctx, instances[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up child instances of workflow instance %d: %w", instances[i], err)
+		}
+
+		for _, child := range children {
+			if visited[child] {
+				continue
+			}
+			visited[child] = true
+			instances = append(instances, child)
+		}
+
+		if len(instances) > cmd.maxInstances {
+			return nil, fmt.Errorf("expected tree of workflow instance %d to contain at most %d instances, but it contains more", cmd.rootKey, cmd.maxInstances)
+		}
+	}
+	return instances, nil
+}
+
+func (cmd *CancelInstanceTreeCommand) cancel(ctx context.Context, key int64) error {
+	single := &CancelWorkflowInstanceCommand{
+		Command: cmd.Command,
+		request: pb.CancelWorkflowInstanceRequest{WorkflowInstanceKey: key},
+	}
+
+	for attempt := 1; ; attempt++ {
+		_, err := single.Send(ctx)
+		if err == nil || attempt >= cmd.maxAttempts || !isTransient(err) {
+			return err
+		}
+
+		select {
+		case <-time.After(time.Duration(attempt) * cmd.backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func isTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+func NewCancelInstanceTreeCommand(gateway pb.GatewayClient, pred retryPredicate) *CancelInstanceTreeCommand {
+	return &CancelInstanceTreeCommand{
+		Command: Command{
+			gateway:     gateway,
+			shouldRetry: pred,
+		},
+		maxInstances: DefaultCancelInstanceTreeMaxInstances,
+		maxAttempts:  DefaultCancelInstanceTreeMaxAttempts,
+		backoff:      DefaultCancelInstanceTreeBackoff,
+	}
+}
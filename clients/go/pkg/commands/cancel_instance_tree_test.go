@@ -0,0 +1,125 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"reflect"
+	"testing"
+)
+
+var instanceTree = ChildInstanceLookupFunc(func(_ context.Context, key int64) ([]int64, error) {
+	switch key {
+	case 1:
+		return []int64{2, 3}, nil
+	case 2:
+		return []int64{4, 1}, nil
+	}
+	return nil, nil
+})
+
+func expectCancel(client *mock_pb.MockGatewayClient, key int64) *gomock.Call {
+	request := &pb.CancelWorkflowInstanceRequest{WorkflowInstanceKey: key}
+	return client.EXPECT().CancelWorkflowInstance(gomock.Any(), &utils.RPCTestMsg{Msg: request})
+}
+
+func TestCancelInstanceTreeCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	gomock.InOrder(
+		expectCancel(client, 1).Return(&pb.CancelWorkflowInstanceResponse{}, nil),
+		expectCancel(client, 2).Return(nil, status.Error(codes.NotFound, "terminated with its parent")),
+		expectCancel(client, 3).Return(nil, status.Error(codes.Unavailable, "leader change")),
+		expectCancel(client, 3).Return(&pb.CancelWorkflowInstanceResponse{}, nil),
+		expectCancel(client, 4).Return(&pb.CancelWorkflowInstanceResponse{}, nil),
+	)
+
+	command := NewCancelInstanceTreeCommand(client, func(context.Context, error) bool { return false })
+	command.backoff = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	result, err := command.WorkflowInstanceKey(1).ChildInstances(instanceTree).Send(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to cancel tree: %v", err)
+	}
+	if !reflect.DeepEqual(result.Instances, []int64{1, 2, 3, 4}) {
+		t.Errorf("Expected instances of tree in breadth first order, but got %v", result.Instances)
+	}
+	if !reflect.DeepEqual(result.Cancelled, []int64{1, 3, 4}) || !reflect.DeepEqual(result.NotFound, []int64{2}) {
+		t.Errorf("Expected instances 1, 3 and 4 to be cancelled and 2 not to be found, but got %v and %v", result.Cancelled, result.NotFound)
+	}
+}
+
+func TestCancelInstanceTreeCommandDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	command := NewCancelInstanceTreeCommand(client, func(context.Context, error) bool { return false })
+
+	result, err := command.WorkflowInstanceKey(1).ChildInstances(instanceTree).DryRun().Send(context.Background())
+
+	if err != nil {
+		t.Fatalf("Failed to discover tree: %v", err)
+	}
+	if !result.DryRun || !reflect.DeepEqual(result.Instances, []int64{1, 2, 3, 4}) || len(result.Cancelled) > 0 {
+		t.Errorf("Expected tree to be discovered without cancelling it, but got %+v", result)
+	}
+}
+
+func TestCancelInstanceTreeCommandWithTooManyInstances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	command := NewCancelInstanceTreeCommand(client, func(context.Context, error) bool { return false })
+
+	if _, err := command.WorkflowInstanceKey(1).ChildInstances(instanceTree).MaxInstances(3).Send(context.Background()); err == nil {
+		t.Error("Expected tree with more than the maximum instances to be rejected")
+	}
+}
+
+func TestCancelInstanceTreeCommandStopsOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	gomock.InOrder(
+		expectCancel(client, 1).Return(&pb.CancelWorkflowInstanceResponse{}, nil),
+		expectCancel(client, 2).Return(nil, status.Error(codes.PermissionDenied, "denied")),
+	)
+
+	command := NewCancelInstanceTreeCommand(client, func(context.Context, error) bool { return false })
+
+	result, err := command.WorkflowInstanceKey(1).ChildInstances(instanceTree).Send(context.Background())
+
+	if err == nil {
+		t.Fatal("Expected error of instance which could not be cancelled")
+	}
+	if !reflect.DeepEqual(result.Cancelled, []int64{1}) {
+		t.Errorf("Expected only the root to be cancelled, but got %v", result.Cancelled)
+	}
+}
@@ -28,11 +28,26 @@ const (
 
 type retryPredicate func(context.Context, error) bool
 
+type attemptKey struct{}
+
 type Command struct {
 	mixin utils.SerializerMixin
 
 	gateway     pb.GatewayClient
 	shouldRetry retryPredicate
+
+	requestTimeout time.Duration
+}
+
+// withRequestTimeout applies the request timeout of the command, if set, to the context. As retries are sent with the
+// derived context, the timeout covers all attempts. Commands which long poll, like ActivateJobs, pass the deadline to
+// the gateway as request timeout.
+func (cmd *Command) withRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if cmd.requestTimeout > 0 {
+		return context.WithTimeout(ctx, cmd.requestTimeout)
+	}
+
+	return context.WithCancel(ctx)
 }
 
 func getLongPollingMillis(ctx context.Context) int64 {
@@ -51,3 +66,19 @@ func getLongPollingMillis(ctx context.Context) int64 {
 
 	return longPollMillis
 }
+
+// Attempt returns how many times the command sent with the given context has been tried so far, including the current
+// try. Commands which are retried, e.g. after the credentials were refreshed, are sent with an incremented attempt.
+func Attempt(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+
+	return 1
+}
+
+// WithNextAttempt returns a context for the next try of the command which is sent with the given context, e.g. for
+// interceptors which retry commands.
+func WithNextAttempt(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attemptKey{}, Attempt(ctx)+1)
+}
@@ -18,10 +18,16 @@ import (
 	"context"
 	"fmt"
 	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/jsonschema"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"time"
 )
 
 type DispatchCompleteJobCommand interface {
+	RequestTimeout(time.Duration) DispatchCompleteJobCommand
+	// Set the JSON Schema which the variables are validated against before the command is sent
+	OutputSchema(*jsonschema.Schema) DispatchCompleteJobCommand
 	Send(context.Context) (*pb.CompleteJobResponse, error)
 }
 
@@ -34,14 +40,22 @@ type CompleteJobCommandStep2 interface {
 
 	VariablesFromString(string) (DispatchCompleteJobCommand, error)
 	VariablesFromStringer(fmt.Stringer) (DispatchCompleteJobCommand, error)
-	VariablesFromMap(map[string]interface{}) (DispatchCompleteJobCommand, error)
+	// Set the variables of the map which are merged into the workflow instance; if keys are given, only the variables
+	// with these names are merged, so other variables of the map cannot overwrite the workflow instance variables
+	VariablesFromMap(map[string]interface{}, ...string) (DispatchCompleteJobCommand, error)
 	VariablesFromObject(interface{}) (DispatchCompleteJobCommand, error)
 	VariablesFromObjectIgnoreOmitempty(interface{}) (DispatchCompleteJobCommand, error)
+	// Set the variables of the map on the element instance of the job only, before the job is completed
+	LocalVariables(elementInstanceKey int64, variables map[string]interface{}) (CompleteJobCommandStep2, error)
+	// Set the variables of the map which are merged into the workflow instance, like VariablesFromMap
+	ProcessVariables(map[string]interface{}) (DispatchCompleteJobCommand, error)
 }
 
 type CompleteJobCommand struct {
 	Command
 	request pb.CompleteJobRequest
+	local   *pb.SetVariablesRequest
+	schema  *jsonschema.Schema
 }
 
 func (cmd *CompleteJobCommand) JobKey(jobKey int64) CompleteJobCommandStep2 {
@@ -83,23 +97,107 @@ func (cmd *CompleteJobCommand) VariablesFromObjectIgnoreOmitempty(variables inte
 	return cmd, nil
 }
 
-func (cmd *CompleteJobCommand) VariablesFromMap(variables map[string]interface{}) (DispatchCompleteJobCommand, error) {
+func (cmd *CompleteJobCommand) VariablesFromMap(variables map[string]interface{}, onlyKeys ...string) (DispatchCompleteJobCommand, error) {
+	if len(onlyKeys) == 0 {
+		return cmd.VariablesFromObject(variables)
+	}
+
+	filtered := make(map[string]interface{}, len(onlyKeys))
+	for _, key := range onlyKeys {
+		if value, ok := variables[key]; ok {
+			filtered[key] = value
+		}
+	}
+	return cmd.VariablesFromObject(filtered)
+}
+
+// LocalVariables sets the variables on the element instance of the job, which is the ElementInstanceKey of the activated
+// job, before the job is completed. The gateway only supports local variables on SetVariables commands, so they are set
+// by a separate command when the command is sent. Local variables are visible to the output mappings of the task, but
+// are not propagated to the workflow instance, e.g. temporary variables of the handler.
+func (cmd *CompleteJobCommand) LocalVariables(elementInstanceKey int64, variables map[string]interface{}) (CompleteJobCommandStep2, error) {
+	value, err := cmd.mixin.AsJSON("local variables", variables, false)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd.local = &pb.SetVariablesRequest{ElementInstanceKey: elementInstanceKey, Variables: value, Local: true}
+	return cmd, nil
+}
+
+// ProcessVariables sets the variables which are merged into the workflow instance when the job is completed, unless
+// the output mappings of the task define otherwise.
+func (cmd *CompleteJobCommand) ProcessVariables(variables map[string]interface{}) (DispatchCompleteJobCommand, error) {
 	return cmd.VariablesFromObject(variables)
 }
 
+func (cmd *CompleteJobCommand) RequestTimeout(timeout time.Duration) DispatchCompleteJobCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
+// OutputSchema sets the JSON Schema which the variables are validated against when the command is sent. If they do not
+// match, the command is not sent and Send returns a *jsonschema.ValidationError which lists the violations, e.g. to
+// enforce the output variables which the workflow expects from the handler. Completing the job without variables is
+// validated like an empty object.
+func (cmd *CompleteJobCommand) OutputSchema(schema *jsonschema.Schema) DispatchCompleteJobCommand {
+	cmd.schema = schema
+	return cmd
+}
+
 func (cmd *CompleteJobCommand) Send(ctx context.Context) (*pb.CompleteJobResponse, error) {
+	if cmd.schema != nil {
+		if err := cmd.validateOutput(); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := cmd.withRequestTimeout(ctx)
+	defer cancel()
+
+	if cmd.local != nil {
+		if err := cmd.setLocalVariables(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	response, err := cmd.gateway.CompleteJob(ctx, &cmd.request)
 	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+		return cmd.Send(WithNextAttempt(ctx))
 	}
 
 	return response, err
 }
 
+func (cmd *CompleteJobCommand) validateOutput() error {
+	variables := cmd.request.Variables
+	if variables == "" {
+		variables = "{}"
+	}
+	return cmd.schema.ValidateJSON(variables)
+}
+
+func (cmd *CompleteJobCommand) setLocalVariables(ctx context.Context) error {
+	_, err := cmd.gateway.SetVariables(ctx, cmd.local)
+	if cmd.shouldRetry(ctx, err) {
+		return cmd.setLocalVariables(WithNextAttempt(ctx))
+	}
+	if err == nil {
+		// the variables are set once, also if completing the job is retried
+		cmd.local = nil
+	}
+
+	return err
+}
+
 func NewCompleteJobCommand(gateway pb.GatewayClient, pred retryPredicate) CompleteJobCommandStep1 {
+	return NewCompleteJobCommandWithCodec(gateway, pred, entities.JSONCodec)
+}
+
+func NewCompleteJobCommandWithCodec(gateway pb.GatewayClient, pred retryPredicate, codec entities.VariableCodec) CompleteJobCommandStep1 {
 	return &CompleteJobCommand{
 		Command: Command{
-			mixin:       utils.NewJSONStringSerializer(),
+			mixin:       utils.NewJSONStringSerializerWithCodec(codec),
 			gateway:     gateway,
 			shouldRetry: pred,
 		},
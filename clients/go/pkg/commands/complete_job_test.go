@@ -19,6 +19,8 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
 	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/jsonschema"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
 	"testing"
 )
@@ -277,3 +279,209 @@ func TestCompleteJobCommandWithVariablesFromMap(t *testing.T) {
 		t.Errorf("Failed to receive response")
 	}
 }
+
+func TestCompleteJobCommandWithFilteredVariablesFromMap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	variableMaps := map[string]interface{}{"foo": "bar", "document": "large payload", "baz": 1}
+
+	request := &pb.CompleteJobRequest{
+		JobKey:    123,
+		Variables: "{\"baz\":1,\"foo\":\"bar\"}",
+	}
+	stub := &pb.CompleteJobResponse{}
+
+	client.EXPECT().CompleteJob(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil)
+
+	command := NewCompleteJobCommand(client, func(context.Context, error) bool { return false })
+
+	variablesCommand, err := command.JobKey(123).VariablesFromMap(variableMaps, "foo", "baz", "unknown")
+	if err != nil {
+		t.Error("Failed to set variables: ", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	response, err := variablesCommand.Send(ctx)
+
+	if err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	if response != stub {
+		t.Errorf("Failed to receive response")
+	}
+}
+
+func TestCompleteJobCommandWithCodec(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	request := &pb.CompleteJobRequest{
+		JobKey:    123,
+		Variables: "{\"foo\":\"bar\"}",
+	}
+	stub := &pb.CompleteJobResponse{}
+
+	client.EXPECT().CompleteJob(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil)
+
+	codec := &recordingCodec{}
+	command := NewCompleteJobCommandWithCodec(client, func(context.Context, error) bool {
+		return false
+	}, codec)
+
+	variablesCommand, err := command.JobKey(123).VariablesFromMap(map[string]interface{}{"foo": "bar"})
+	if err != nil {
+		t.Error("Failed to set variables: ", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	_, err = variablesCommand.Send(ctx)
+	if err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	if codec.marshalled != 1 {
+		t.Errorf("Expected variables to be marshalled by the codec once, but was %d times", codec.marshalled)
+	}
+}
+
+type recordingCodec struct {
+	marshalled int
+}
+
+func (c *recordingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshalled++
+	return entities.JSONCodec.Marshal(v)
+}
+
+func (c *recordingCodec) Unmarshal(data []byte, v interface{}) error {
+	return entities.JSONCodec.Unmarshal(data, v)
+}
+
+func TestCompleteJobCommandWithLocalVariables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	localRequest := &pb.SetVariablesRequest{
+		ElementInstanceKey: 456,
+		Variables:          `{"tmp":1}`,
+		Local:              true,
+	}
+	request := &pb.CompleteJobRequest{
+		JobKey:    123,
+		Variables: `{"result":"ok"}`,
+	}
+	stub := &pb.CompleteJobResponse{}
+
+	gomock.InOrder(
+		client.EXPECT().SetVariables(gomock.Any(), &utils.RPCTestMsg{Msg: localRequest}).Return(&pb.SetVariablesResponse{}, nil),
+		client.EXPECT().CompleteJob(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil),
+	)
+
+	command, err := NewCompleteJobCommand(client, func(context.Context, error) bool {
+		return false
+	}).JobKey(123).LocalVariables(456, map[string]interface{}{"tmp": 1})
+	if err != nil {
+		t.Fatal("Failed to set local variables: ", err)
+	}
+	variablesCommand, err := command.ProcessVariables(map[string]interface{}{"result": "ok"})
+	if err != nil {
+		t.Fatal("Failed to set process variables: ", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	response, err := variablesCommand.Send(ctx)
+
+	if err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	if response != stub {
+		t.Errorf("Failed to receive response")
+	}
+}
+
+func TestCompleteJobCommandNotCompletedIfLocalVariablesFail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().SetVariables(gomock.Any(), gomock.Any()).Return(nil, context.DeadlineExceeded)
+
+	command, err := NewCompleteJobCommand(client, func(context.Context, error) bool {
+		return false
+	}).JobKey(123).LocalVariables(456, map[string]interface{}{"tmp": 1})
+	if err != nil {
+		t.Fatal("Failed to set local variables: ", err)
+	}
+
+	if _, err := command.Send(context.Background()); err != context.DeadlineExceeded {
+		t.Errorf("Expected error of local variables to be returned, got %v", err)
+	}
+}
+
+func TestCompleteJobCommandWithOutputSchema(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	request := &pb.CompleteJobRequest{
+		JobKey:    123,
+		Variables: `{"approved":true}`,
+	}
+	stub := &pb.CompleteJobResponse{}
+
+	client.EXPECT().CompleteJob(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil)
+
+	schema := jsonschema.MustCompile(`{"type":"object","required":["approved"],"properties":{"approved":{"type":"boolean"}}}`)
+	command, err := NewCompleteJobCommand(client, func(context.Context, error) bool { return false }).JobKey(123).VariablesFromString(`{"approved":true}`)
+	if err != nil {
+		t.Fatal("Failed to set variables: ", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	response, err := command.OutputSchema(schema).Send(ctx)
+
+	if err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	if response != stub {
+		t.Errorf("Failed to receive response")
+	}
+}
+
+func TestCompleteJobCommandWithInvalidOutput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	schema := jsonschema.MustCompile(`{"type":"object","required":["approved"]}`)
+	command := NewCompleteJobCommand(client, func(context.Context, error) bool { return false }).JobKey(123)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	_, err := command.OutputSchema(schema).Send(ctx)
+
+	if _, ok := err.(*jsonschema.ValidationError); !ok {
+		t.Errorf("Expected command without required variable to be rejected, got %v", err)
+	}
+}
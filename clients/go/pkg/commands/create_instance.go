@@ -18,16 +18,20 @@ import (
 	"context"
 	"fmt"
 	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"time"
 )
 
 const LatestVersion = -1
 
 type DispatchCreateInstanceCommand interface {
+	RequestTimeout(time.Duration) DispatchCreateInstanceCommand
 	Send(context.Context) (*pb.CreateWorkflowInstanceResponse, error)
 }
 
 type DispatchCreateInstanceWithResultCommand interface {
+	RequestTimeout(time.Duration) DispatchCreateInstanceWithResultCommand
 	Send(context.Context) (*pb.CreateWorkflowInstanceWithResultResponse, error)
 }
 
@@ -150,30 +154,50 @@ func (cmd *CreateInstanceWithResultCommand) FetchVariables(variableNames ...stri
 	return cmd
 }
 
+func (cmd *CreateInstanceCommand) RequestTimeout(timeout time.Duration) DispatchCreateInstanceCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
 func (cmd *CreateInstanceCommand) Send(ctx context.Context) (*pb.CreateWorkflowInstanceResponse, error) {
+	ctx, cancel := cmd.withRequestTimeout(ctx)
+	defer cancel()
+
 	response, err := cmd.gateway.CreateWorkflowInstance(ctx, &cmd.request)
 	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+		return cmd.Send(WithNextAttempt(ctx))
 	}
 
 	return response, err
 }
 
+func (cmd *CreateInstanceWithResultCommand) RequestTimeout(timeout time.Duration) DispatchCreateInstanceWithResultCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
 func (cmd *CreateInstanceWithResultCommand) Send(ctx context.Context) (*pb.CreateWorkflowInstanceWithResultResponse, error) {
+	ctx, cancel := cmd.withRequestTimeout(ctx)
+	defer cancel()
+
 	cmd.request.RequestTimeout = getLongPollingMillis(ctx)
 
 	response, err := cmd.gateway.CreateWorkflowInstanceWithResult(ctx, &cmd.request)
 	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+		return cmd.Send(WithNextAttempt(ctx))
 	}
 
 	return response, err
 }
 
 func NewCreateInstanceCommand(gateway pb.GatewayClient, pred retryPredicate) CreateInstanceCommandStep1 {
+	return NewCreateInstanceCommandWithCodec(gateway, pred, entities.JSONCodec)
+}
+
+func NewCreateInstanceCommandWithCodec(gateway pb.GatewayClient, pred retryPredicate, codec entities.VariableCodec) CreateInstanceCommandStep1 {
 	return &CreateInstanceCommand{
 		Command: Command{
-			mixin:       utils.NewJSONStringSerializer(),
+			mixin:       utils.NewJSONStringSerializerWithCodec(codec),
 			gateway:     gateway,
 			shouldRetry: pred,
 		},
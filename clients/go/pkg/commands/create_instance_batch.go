@@ -0,0 +1,193 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"sync"
+)
+
+// DefaultCreateInstanceBatchConcurrency is the default number of instances of a batch which are created at the same time.
+const DefaultCreateInstanceBatchConcurrency = 32
+
+// Instance is a workflow instance to create with a CreateInstanceBatchCommand, either of the workflow with the
+// WorkflowKey, or of the BPMNProcessID in the Version, which defaults to the latest version. Variables, if not nil,
+// are expected to be JSON serializable.
+type Instance struct {
+	BPMNProcessID string
+	Version       int32
+	WorkflowKey   int64
+	Variables     interface{}
+}
+
+// CreateInstanceResult is the outcome of creating an instance of a batch. Index is the position of the instance in
+// the batch, or in the stream of instances.
+type CreateInstanceResult struct {
+	Index    int
+	Instance Instance
+	Response *pb.CreateWorkflowInstanceResponse
+	Err      error
+}
+
+// CreateInstanceBatchCommand creates many workflow instances with parallel requests, e.g. to generate load or to
+// migrate instances. Requests are retried and rate limited like the single commands of the client.
+type CreateInstanceBatchCommand struct {
+	Command
+	instances   []Instance
+	concurrency int
+}
+
+func (cmd *CreateInstanceBatchCommand) AddInstances(instances ...Instance) *CreateInstanceBatchCommand {
+	cmd.instances = append(cmd.instances, instances...)
+	return cmd
+}
+
+// Concurrency sets the maximum number of instances which are created at the same time.
+func (cmd *CreateInstanceBatchCommand) Concurrency(concurrency int) *CreateInstanceBatchCommand {
+	if concurrency > 0 {
+		cmd.concurrency = concurrency
+	}
+	return cmd
+}
+
+// Send creates the added instances and returns a result for each instance, in the order they were added. If any
+// instance could not be created, an error is returned in addition to the results.
+func (cmd *CreateInstanceBatchCommand) Send(ctx context.Context) ([]CreateInstanceResult, error) {
+	instances := make(chan Instance)
+	go func() {
+		defer close(instances)
+		for _, instance := range cmd.instances {
+			select {
+			case instances <- instance:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make([]CreateInstanceResult, len(cmd.instances))
+	for i, instance := range cmd.instances {
+		results[i] = CreateInstanceResult{Index: i, Instance: instance}
+	}
+	for result := range cmd.Stream(ctx, instances) {
+		results[result.Index] = result
+	}
+
+	failed := 0
+	for i := range results {
+		if results[i].Err == nil && results[i].Response == nil {
+			// not created, because the context was done before
+			results[i].Err = ctx.Err()
+		}
+		if results[i].Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("failed to create %d of %d instances", failed, len(results))
+	}
+
+	return results, nil
+}
+
+// Stream creates the instances received from the channel until it is closed or the context is done, and streams the
+// results as soon as they are available, so the order of the results can differ from the order of the instances. The
+// returned channel is closed after the last result and must be drained. The added instances of the command are
+// ignored.
+func (cmd *CreateInstanceBatchCommand) Stream(ctx context.Context, instances <-chan Instance) <-chan CreateInstanceResult {
+	results := make(chan CreateInstanceResult, cmd.concurrency)
+
+	go func() {
+		defer close(results)
+
+		tokens := make(chan struct{}, cmd.concurrency)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+
+		index := 0
+		for {
+			var instance Instance
+			var ok bool
+			select {
+			case instance, ok = <-instances:
+			case <-ctx.Done():
+				return
+			}
+			if !ok {
+				return
+			}
+
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				results <- CreateInstanceResult{Index: index, Instance: instance, Err: ctx.Err()}
+				return
+			}
+
+			wg.Add(1)
+			go func(result CreateInstanceResult) {
+				defer func() {
+					<-tokens
+					wg.Done()
+				}()
+				result.Response, result.Err = cmd.create(ctx, result.Instance)
+				results <- result
+			}(CreateInstanceResult{Index: index, Instance: instance})
+			index++
+		}
+	}()
+
+	return results
+}
+
+func (cmd *CreateInstanceBatchCommand) create(ctx context.Context, instance Instance) (*pb.CreateWorkflowInstanceResponse, error) {
+	single := &CreateInstanceCommand{Command: cmd.Command}
+	if instance.WorkflowKey != 0 {
+		single.request.WorkflowKey = instance.WorkflowKey
+	} else {
+		single.request.BpmnProcessId = instance.BPMNProcessID
+		single.request.Version = instance.Version
+		if single.request.Version == 0 {
+			single.request.Version = LatestVersion
+		}
+	}
+
+	if instance.Variables != nil {
+		if _, err := single.VariablesFromObject(instance.Variables); err != nil {
+			return nil, err
+		}
+	}
+
+	return single.Send(ctx)
+}
+
+func NewCreateInstanceBatchCommand(gateway pb.GatewayClient, pred retryPredicate) *CreateInstanceBatchCommand {
+	return NewCreateInstanceBatchCommandWithCodec(gateway, pred, entities.JSONCodec)
+}
+
+func NewCreateInstanceBatchCommandWithCodec(gateway pb.GatewayClient, pred retryPredicate, codec entities.VariableCodec) *CreateInstanceBatchCommand {
+	return &CreateInstanceBatchCommand{
+		Command: Command{
+			mixin:       utils.NewJSONStringSerializerWithCodec(codec),
+			gateway:     gateway,
+			shouldRetry: pred,
+		},
+		concurrency: DefaultCreateInstanceBatchConcurrency,
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"testing"
+)
+
+func TestCreateInstanceBatchCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	first := &pb.CreateWorkflowInstanceRequest{
+		BpmnProcessId: "foo",
+		Version:       LatestVersion,
+		Variables:     `{"foo":"bar"}`,
+	}
+	second := &pb.CreateWorkflowInstanceRequest{
+		WorkflowKey: 123,
+	}
+	firstStub := &pb.CreateWorkflowInstanceResponse{WorkflowInstanceKey: 1}
+	secondStub := &pb.CreateWorkflowInstanceResponse{WorkflowInstanceKey: 2}
+
+	client.EXPECT().CreateWorkflowInstance(gomock.Any(), &utils.RPCTestMsg{Msg: first}).Return(firstStub, nil)
+	client.EXPECT().CreateWorkflowInstance(gomock.Any(), &utils.RPCTestMsg{Msg: second}).Return(secondStub, nil)
+
+	command := NewCreateInstanceBatchCommand(client, func(context.Context, error) bool { return false })
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	results, err := command.
+		AddInstances(
+			Instance{BPMNProcessID: "foo", Variables: map[string]string{"foo": "bar"}},
+			Instance{WorkflowKey: 123},
+		).
+		Concurrency(2).
+		Send(ctx)
+
+	if err != nil {
+		t.Errorf("Failed to send requests: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected a result per instance, but got %d", len(results))
+	}
+
+	if results[0].Response != firstStub || results[1].Response != secondStub {
+		t.Errorf("Failed to receive responses")
+	}
+}
+
+func TestCreateInstanceBatchCommandWithFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	failure := errors.New("create failed")
+	client.EXPECT().CreateWorkflowInstance(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.CreateWorkflowInstanceRequest{BpmnProcessId: "foo", Version: 1}}).Return(nil, failure)
+	client.EXPECT().CreateWorkflowInstance(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.CreateWorkflowInstanceRequest{BpmnProcessId: "foo", Version: 2}}).Return(&pb.CreateWorkflowInstanceResponse{}, nil)
+
+	command := NewCreateInstanceBatchCommand(client, func(context.Context, error) bool { return false })
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	results, err := command.
+		AddInstances(Instance{BPMNProcessID: "foo", Version: 1}, Instance{BPMNProcessID: "foo", Version: 2}).
+		Send(ctx)
+
+	if err == nil {
+		t.Errorf("Expected batch to fail")
+	}
+
+	if results[0].Err != failure || results[1].Err != nil {
+		t.Errorf("Expected only the first instance to fail, but got %v and %v", results[0].Err, results[1].Err)
+	}
+}
+
+func TestCreateInstanceBatchCommandStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().CreateWorkflowInstance(gomock.Any(), gomock.Any()).Return(&pb.CreateWorkflowInstanceResponse{}, nil).Times(10)
+
+	command := NewCreateInstanceBatchCommand(client, func(context.Context, error) bool { return false })
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	instances := make(chan Instance)
+	go func() {
+		defer close(instances)
+		for i := 0; i < 10; i++ {
+			instances <- Instance{BPMNProcessID: "foo"}
+		}
+	}()
+
+	seen := make(map[int]bool)
+	for result := range command.Concurrency(3).Stream(ctx, instances) {
+		if result.Err != nil {
+			t.Errorf("Failed to create instance %d: %v", result.Index, result.Err)
+		}
+		seen[result.Index] = true
+	}
+
+	if len(seen) != 10 {
+		t.Errorf("Expected a result per instance, but got %d", len(seen))
+	}
+}
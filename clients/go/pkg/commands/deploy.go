@@ -16,14 +16,21 @@ package commands
 
 import (
 	"context"
+	"fmt"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/bpmn"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"io"
 	"io/ioutil"
 	"log"
+	"strings"
+	"time"
 )
 
 type DeployCommand struct {
 	Command
-	request pb.DeployWorkflowRequest
+	request  pb.DeployWorkflowRequest
+	err      error
+	validate bool
 }
 
 func (cmd *DeployCommand) AddResourceFile(path string) *DeployCommand {
@@ -39,10 +46,42 @@ func (cmd *DeployCommand) AddResource(definition []byte, name string, resourceTy
 	return cmd
 }
 
+// AddResourceReader adds a resource with the given name, whose definition is read from the reader. If reading fails,
+// the error is returned by Send.
+func (cmd *DeployCommand) AddResourceReader(name string, r io.Reader) *DeployCommand {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		cmd.setError(fmt.Errorf("failed to read resource '%s': %w", name, err))
+		return cmd
+	}
+	return cmd.AddResource(b, name, pb.WorkflowRequestObject_FILE)
+}
+
+// WithValidation validates the BPMN resources before sending them, so problems which the broker would reject are
+// returned by Send as *bpmn.ValidationError, with the line numbers of the offending elements.
+func (cmd *DeployCommand) WithValidation() *DeployCommand {
+	cmd.validate = true
+	return cmd
+}
+
+func (cmd *DeployCommand) RequestTimeout(timeout time.Duration) *DeployCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
+// Send deploys the resources. It fails without sending a request if a resource could not be read, if two resources
+// have the same name or, with WithValidation, if a BPMN resource is invalid.
 func (cmd *DeployCommand) Send(ctx context.Context) (*pb.DeployWorkflowResponse, error) {
+	if err := cmd.check(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := cmd.withRequestTimeout(ctx)
+	defer cancel()
+
 	response, err := cmd.gateway.DeployWorkflow(ctx, &cmd.request)
 	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+		return cmd.Send(WithNextAttempt(ctx))
 	}
 
 	return response, err
@@ -56,3 +95,45 @@ func NewDeployCommand(gateway pb.GatewayClient, pred retryPredicate) *DeployComm
 		},
 	}
 }
+
+func (cmd *DeployCommand) setError(err error) {
+	if cmd.err == nil {
+		cmd.err = err
+	}
+}
+
+func (cmd *DeployCommand) check() error {
+	if cmd.err != nil {
+		return cmd.err
+	}
+
+	names := make(map[string]bool, len(cmd.request.Workflows))
+	for _, workflow := range cmd.request.Workflows {
+		if names[workflow.Name] {
+			return fmt.Errorf("expected resource names to be unique, but '%s' was added more than once", workflow.Name)
+		}
+		names[workflow.Name] = true
+	}
+
+	if cmd.validate {
+		return validateResources(cmd.request.Workflows)
+	}
+
+	return nil
+}
+
+func validateResources(workflows []*pb.WorkflowRequestObject) error {
+	var diagnostics []bpmn.Diagnostic
+	for _, workflow := range workflows {
+		isBPMN := workflow.Type == pb.WorkflowRequestObject_BPMN ||
+			(workflow.Type == pb.WorkflowRequestObject_FILE && strings.HasSuffix(workflow.Name, ".bpmn"))
+		if isBPMN {
+			diagnostics = append(diagnostics, bpmn.Validate(workflow.Name, workflow.Definition)...)
+		}
+	}
+
+	if len(diagnostics) > 0 {
+		return &bpmn.ValidationError{Diagnostics: diagnostics}
+	}
+	return nil
+}
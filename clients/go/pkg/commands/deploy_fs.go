@@ -0,0 +1,50 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.16
+// +build go1.16
+
+package commands
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+// AddResourceFromFS adds all files of the file system which match the glob pattern, e.g. models embedded with
+// go:embed. The path of each file in the file system is used as its resource name. If no file matches or a file cannot
+// be read, the error is returned by Send.
+func (cmd *DeployCommand) AddResourceFromFS(fsys fs.FS, pattern string) *DeployCommand {
+	paths, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		cmd.setError(fmt.Errorf("failed to match resources with '%s': %w", pattern, err))
+		return cmd
+	} else if len(paths) == 0 {
+		cmd.setError(fmt.Errorf("expected to find resources matching '%s', but found none", pattern))
+		return cmd
+	}
+
+	for _, path := range paths {
+		b, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			cmd.setError(fmt.Errorf("failed to read resource '%s': %w", path, err))
+			return cmd
+		}
+		cmd.AddResource(b, path, pb.WorkflowRequestObject_FILE)
+	}
+
+	return cmd
+}
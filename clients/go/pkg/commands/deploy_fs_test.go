@@ -0,0 +1,93 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.16
+// +build go1.16
+
+package commands
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func TestDeployCommand_AddResourceFromFS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	fsys := fstest.MapFS{
+		"models/order.bpmn":   {Data: []byte("order")},
+		"models/payment.bpmn": {Data: []byte("payment")},
+		"models/README.md":    {Data: []byte("readme")},
+	}
+
+	request := &pb.DeployWorkflowRequest{
+		Workflows: []*pb.WorkflowRequestObject{
+			{
+				Name:       "models/order.bpmn",
+				Type:       pb.WorkflowRequestObject_FILE,
+				Definition: []byte("order"),
+			},
+			{
+				Name:       "models/payment.bpmn",
+				Type:       pb.WorkflowRequestObject_FILE,
+				Definition: []byte("payment"),
+			},
+		},
+	}
+	stub := &pb.DeployWorkflowResponse{}
+
+	client.EXPECT().DeployWorkflow(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil)
+
+	command := NewDeployCommand(client, func(context.Context, error) bool { return false })
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	response, err := command.
+		AddResourceFromFS(fsys, "models/*.bpmn").
+		Send(ctx)
+
+	if err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	if response != stub {
+		t.Errorf("Failed to receive response")
+	}
+}
+
+func TestDeployCommand_AddResourceFromFSWithoutMatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	command := NewDeployCommand(client, func(context.Context, error) bool { return false })
+
+	_, err := command.
+		AddResourceFromFS(fstest.MapFS{}, "*.bpmn").
+		Send(context.Background())
+
+	if err == nil {
+		t.Errorf("Expected error if no resource matches")
+	}
+}
@@ -15,10 +15,13 @@
 package commands
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"github.com/golang/mock/gomock"
 	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
 	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/bpmn"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
 	"io/ioutil"
 	"testing"
@@ -117,6 +120,110 @@ func TestDeployCommand_AddResource(t *testing.T) {
 	}
 }
 
+func TestDeployCommand_AddResourceReader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	demoName := "../../../java/src/test/resources/workflows/demo-process.bpmn"
+	demoBytes := readBytes(t, demoName)
+
+	request := &pb.DeployWorkflowRequest{
+		Workflows: []*pb.WorkflowRequestObject{
+			{
+				Name:       "demo-process.bpmn",
+				Type:       pb.WorkflowRequestObject_FILE,
+				Definition: demoBytes,
+			},
+		},
+	}
+	stub := &pb.DeployWorkflowResponse{}
+
+	client.EXPECT().DeployWorkflow(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil)
+
+	command := NewDeployCommand(client, func(context.Context, error) bool { return false })
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	response, err := command.
+		AddResourceReader("demo-process.bpmn", bytes.NewReader(demoBytes)).
+		Send(ctx)
+
+	if err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	if response != stub {
+		t.Errorf("Failed to receive response")
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestDeployCommand_AddResourceReaderFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	command := NewDeployCommand(client, func(context.Context, error) bool { return false })
+
+	_, err := command.
+		AddResourceReader("demo-process.bpmn", failingReader{}).
+		Send(context.Background())
+
+	if err == nil {
+		t.Errorf("Expected read error to be returned")
+	}
+}
+
+func TestDeployCommand_RejectDuplicateResourceNames(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	command := NewDeployCommand(client, func(context.Context, error) bool { return false })
+
+	_, err := command.
+		AddResource([]byte("first"), "process.bpmn", pb.WorkflowRequestObject_BPMN).
+		AddResource([]byte("second"), "process.bpmn", pb.WorkflowRequestObject_BPMN).
+		Send(context.Background())
+
+	if err == nil {
+		t.Errorf("Expected duplicate resource names to be rejected")
+	}
+}
+
+func TestDeployCommand_WithValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	command := NewDeployCommand(client, func(context.Context, error) bool { return false })
+
+	definition := []byte(`<bpmn:definitions xmlns:bpmn="http://www.omg.org/spec/BPMN/20100524/MODEL">
+  <bpmn:process id="process"><bpmn:serviceTask id="task" /></bpmn:process>
+</bpmn:definitions>`)
+	_, err := command.
+		AddResource(definition, "process.bpmn", pb.WorkflowRequestObject_BPMN).
+		WithValidation().
+		Send(context.Background())
+
+	var validationErr *bpmn.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected validation error, but got %v", err)
+	}
+
+	if len(validationErr.Diagnostics) != 1 || validationErr.Diagnostics[0].Line != 2 {
+		t.Errorf("Expected one diagnostic on line 2, but got %v", validationErr.Diagnostics)
+	}
+}
+
 func readBytes(t *testing.T, filename string) []byte {
 	bytes, err := ioutil.ReadFile(filename)
 	if err != nil {
@@ -18,9 +18,11 @@ package commands
 import (
 	"context"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"time"
 )
 
 type DispatchFailJobCommand interface {
+	RequestTimeout(time.Duration) DispatchFailJobCommand
 	Send(context.Context) (*pb.FailJobResponse, error)
 }
 
@@ -57,10 +59,18 @@ func (cmd *FailJobCommand) ErrorMessage(errorMessage string) FailJobCommandStep3
 	return cmd
 }
 
+func (cmd *FailJobCommand) RequestTimeout(timeout time.Duration) DispatchFailJobCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
 func (cmd *FailJobCommand) Send(ctx context.Context) (*pb.FailJobResponse, error) {
+	ctx, cancel := cmd.withRequestTimeout(ctx)
+	defer cancel()
+
 	response, err := cmd.gateway.FailJob(ctx, &cmd.request)
 	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+		return cmd.Send(WithNextAttempt(ctx))
 	}
 
 	return response, err
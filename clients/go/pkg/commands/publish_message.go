@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
 	"time"
 )
@@ -50,6 +51,7 @@ type PublishMessageCommandStep3 interface {
 }
 
 type DispatchPublishMessageCommand interface {
+	RequestTimeout(time.Duration) DispatchPublishMessageCommand
 	Send(context.Context) (*pb.PublishMessageResponse, error)
 }
 
@@ -116,18 +118,30 @@ func (cmd *PublishMessageCommand) MessageName(name string) PublishMessageCommand
 	return cmd
 }
 
+func (cmd *PublishMessageCommand) RequestTimeout(timeout time.Duration) DispatchPublishMessageCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
 func (cmd *PublishMessageCommand) Send(ctx context.Context) (*pb.PublishMessageResponse, error) {
+	ctx, cancel := cmd.withRequestTimeout(ctx)
+	defer cancel()
+
 	response, err := cmd.gateway.PublishMessage(ctx, &cmd.request)
 	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+		return cmd.Send(WithNextAttempt(ctx))
 	}
 	return response, err
 }
 
 func NewPublishMessageCommand(gateway pb.GatewayClient, pred retryPredicate) PublishMessageCommandStep1 {
+	return NewPublishMessageCommandWithCodec(gateway, pred, entities.JSONCodec)
+}
+
+func NewPublishMessageCommandWithCodec(gateway pb.GatewayClient, pred retryPredicate, codec entities.VariableCodec) PublishMessageCommandStep1 {
 	return &PublishMessageCommand{
 		Command: Command{
-			mixin:       utils.NewJSONStringSerializer(),
+			mixin:       utils.NewJSONStringSerializerWithCodec(codec),
 			gateway:     gateway,
 			shouldRetry: pred,
 		},
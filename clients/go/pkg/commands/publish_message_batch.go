@@ -0,0 +1,150 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"sync"
+	"time"
+)
+
+// DefaultPublishMessageBatchConcurrency is the default number of messages of a batch which are published at the same time.
+const DefaultPublishMessageBatchConcurrency = 8
+
+// Message is a message of a PublishMessageBatchCommand. Variables, if not nil, are expected to be JSON serializable.
+type Message struct {
+	Name           string
+	CorrelationKey string
+	MessageID      string
+	TimeToLive     time.Duration
+	Variables      interface{}
+}
+
+// PublishMessageResult is the outcome of publishing a message of a batch. Duplicate is true if the message was not
+// published, because an earlier message of the batch has the same message id.
+type PublishMessageResult struct {
+	Message   Message
+	Response  *pb.PublishMessageResponse
+	Err       error
+	Duplicate bool
+}
+
+type PublishMessageBatchCommand struct {
+	Command
+	messages    []Message
+	concurrency int
+}
+
+func (cmd *PublishMessageBatchCommand) AddMessages(messages ...Message) *PublishMessageBatchCommand {
+	cmd.messages = append(cmd.messages, messages...)
+	return cmd
+}
+
+// Concurrency sets the maximum number of messages which are published at the same time.
+func (cmd *PublishMessageBatchCommand) Concurrency(concurrency int) *PublishMessageBatchCommand {
+	if concurrency > 0 {
+		cmd.concurrency = concurrency
+	}
+	return cmd
+}
+
+// Send publishes the messages and returns a result for each message, in the order they were added. Messages with a
+// message id which was already added to the batch are skipped. If any message could not be published, an error is
+// returned in addition to the results.
+func (cmd *PublishMessageBatchCommand) Send(ctx context.Context) ([]PublishMessageResult, error) {
+	results := make([]PublishMessageResult, len(cmd.messages))
+	messageIDs := make(map[string]bool, len(cmd.messages))
+	tokens := make(chan struct{}, cmd.concurrency)
+	var wg sync.WaitGroup
+
+	for i, message := range cmd.messages {
+		results[i].Message = message
+		if message.MessageID != "" {
+			if messageIDs[message.MessageID] {
+				results[i].Duplicate = true
+				continue
+			}
+			messageIDs[message.MessageID] = true
+		}
+
+		select {
+		case tokens <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *PublishMessageResult) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			result.Response, result.Err = cmd.publish(ctx, result.Message)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("failed to publish %d of %d messages", failed, len(results))
+	}
+
+	return results, nil
+}
+
+func (cmd *PublishMessageBatchCommand) publish(ctx context.Context, message Message) (*pb.PublishMessageResponse, error) {
+	single := &PublishMessageCommand{
+		Command: cmd.Command,
+		request: pb.PublishMessageRequest{
+			Name:           message.Name,
+			CorrelationKey: message.CorrelationKey,
+			MessageId:      message.MessageID,
+			TimeToLive:     int64(message.TimeToLive / time.Millisecond),
+		},
+	}
+
+	if message.Variables != nil {
+		if _, err := single.VariablesFromObject(message.Variables); err != nil {
+			return nil, err
+		}
+	}
+
+	return single.Send(ctx)
+}
+
+func NewPublishMessageBatchCommand(gateway pb.GatewayClient, pred retryPredicate) *PublishMessageBatchCommand {
+	return NewPublishMessageBatchCommandWithCodec(gateway, pred, entities.JSONCodec)
+}
+
+func NewPublishMessageBatchCommandWithCodec(gateway pb.GatewayClient, pred retryPredicate, codec entities.VariableCodec) *PublishMessageBatchCommand {
+	return &PublishMessageBatchCommand{
+		Command: Command{
+			mixin:       utils.NewJSONStringSerializerWithCodec(codec),
+			gateway:     gateway,
+			shouldRetry: pred,
+		},
+		concurrency: DefaultPublishMessageBatchConcurrency,
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"testing"
+	"time"
+)
+
+func TestPublishMessageBatchCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	first := &pb.PublishMessageRequest{
+		Name:           "foo",
+		CorrelationKey: "1",
+		MessageId:      "a",
+		TimeToLive:     60000,
+		Variables:      `{"foo":"bar"}`,
+	}
+	second := &pb.PublishMessageRequest{
+		Name:           "foo",
+		CorrelationKey: "2",
+	}
+	firstStub := &pb.PublishMessageResponse{Key: 1}
+	secondStub := &pb.PublishMessageResponse{Key: 2}
+
+	client.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: first}).Return(firstStub, nil)
+	client.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: second}).Return(secondStub, nil)
+
+	command := NewPublishMessageBatchCommand(client, func(context.Context, error) bool { return false })
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	results, err := command.
+		AddMessages(
+			Message{Name: "foo", CorrelationKey: "1", MessageID: "a", TimeToLive: time.Minute, Variables: map[string]string{"foo": "bar"}},
+			Message{Name: "foo", CorrelationKey: "2"},
+			Message{Name: "foo", CorrelationKey: "1", MessageID: "a"},
+		).
+		Concurrency(2).
+		Send(ctx)
+
+	if err != nil {
+		t.Errorf("Failed to send requests: %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("Expected a result per message, but got %d", len(results))
+	}
+
+	if results[0].Response != firstStub || results[1].Response != secondStub {
+		t.Errorf("Failed to receive responses")
+	}
+
+	if !results[2].Duplicate || results[2].Response != nil {
+		t.Errorf("Expected message with duplicate id to be skipped")
+	}
+}
+
+func TestPublishMessageBatchCommandWithFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	failure := errors.New("publish failed")
+	client.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.PublishMessageRequest{Name: "foo", CorrelationKey: "1"}}).Return(nil, failure)
+	client.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.PublishMessageRequest{Name: "foo", CorrelationKey: "2"}}).Return(&pb.PublishMessageResponse{}, nil)
+
+	command := NewPublishMessageBatchCommand(client, func(context.Context, error) bool { return false })
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	results, err := command.
+		AddMessages(Message{Name: "foo", CorrelationKey: "1"}, Message{Name: "foo", CorrelationKey: "2"}).
+		Send(ctx)
+
+	if err == nil {
+		t.Errorf("Expected batch to fail")
+	}
+
+	if results[0].Err != failure || results[1].Err != nil {
+		t.Errorf("Expected only the first message to fail, but got %v and %v", results[0].Err, results[1].Err)
+	}
+}
@@ -18,9 +18,11 @@ package commands
 import (
 	"context"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"time"
 )
 
 type DispatchResolveIncidentCommand interface {
+	RequestTimeout(time.Duration) DispatchResolveIncidentCommand
 	Send(context.Context) (*pb.ResolveIncidentResponse, error)
 }
 
@@ -42,10 +44,18 @@ func (cmd *ResolveIncidentCommand) IncidentKey(incidentKey int64) ResolveInciden
 	return cmd
 }
 
+func (cmd *ResolveIncidentCommand) RequestTimeout(timeout time.Duration) DispatchResolveIncidentCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
 func (cmd *ResolveIncidentCommand) Send(ctx context.Context) (*pb.ResolveIncidentResponse, error) {
+	ctx, cancel := cmd.withRequestTimeout(ctx)
+	defer cancel()
+
 	response, err := cmd.gateway.ResolveIncident(ctx, &cmd.request)
 	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+		return cmd.Send(WithNextAttempt(ctx))
 	}
 
 	return response, err
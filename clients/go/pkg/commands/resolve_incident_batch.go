@@ -0,0 +1,143 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"sync"
+)
+
+// DefaultResolveIncidentBatchConcurrency is the default number of incidents of a batch which are resolved at the same time.
+const DefaultResolveIncidentBatchConcurrency = 8
+
+// Incident is an incident of a ResolveIncidentBatchCommand. If JobKey is set, the retries of the job are updated to
+// JobRetries, or DefaultJobRetries if not set, before the incident is resolved. The keys can be taken from the
+// exported incident records, see records.IncidentRecord.
+type Incident struct {
+	Key        int64
+	JobKey     int64
+	JobRetries int32
+}
+
+// ResolveIncidentResult is the outcome of resolving an incident of a batch. Err is the error of updating the job
+// retries or of resolving the incident.
+type ResolveIncidentResult struct {
+	Incident Incident
+	Response *pb.ResolveIncidentResponse
+	Err      error
+}
+
+type ResolveIncidentBatchCommand struct {
+	Command
+	incidents   []Incident
+	concurrency int
+}
+
+func (cmd *ResolveIncidentBatchCommand) AddIncidents(incidents ...Incident) *ResolveIncidentBatchCommand {
+	cmd.incidents = append(cmd.incidents, incidents...)
+	return cmd
+}
+
+// AddIncidentKeys adds incidents which are resolved without updating job retries first.
+func (cmd *ResolveIncidentBatchCommand) AddIncidentKeys(keys ...int64) *ResolveIncidentBatchCommand {
+	for _, key := range keys {
+		cmd.incidents = append(cmd.incidents, Incident{Key: key})
+	}
+	return cmd
+}
+
+// Concurrency sets the maximum number of incidents which are resolved at the same time.
+func (cmd *ResolveIncidentBatchCommand) Concurrency(concurrency int) *ResolveIncidentBatchCommand {
+	if concurrency > 0 {
+		cmd.concurrency = concurrency
+	}
+	return cmd
+}
+
+// Send resolves the incidents and returns a result for each incident, in the order they were added. If any incident
+// could not be resolved, an error is returned in addition to the results.
+func (cmd *ResolveIncidentBatchCommand) Send(ctx context.Context) ([]ResolveIncidentResult, error) {
+	results := make([]ResolveIncidentResult, len(cmd.incidents))
+	tokens := make(chan struct{}, cmd.concurrency)
+	var wg sync.WaitGroup
+
+	for i, incident := range cmd.incidents {
+		results[i].Incident = incident
+
+		select {
+		case tokens <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *ResolveIncidentResult) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			result.Response, result.Err = cmd.resolve(ctx, result.Incident)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("failed to resolve %d of %d incidents", failed, len(results))
+	}
+
+	return results, nil
+}
+
+func (cmd *ResolveIncidentBatchCommand) resolve(ctx context.Context, incident Incident) (*pb.ResolveIncidentResponse, error) {
+	if incident.JobKey > 0 {
+		retries := incident.JobRetries
+		if retries <= 0 {
+			retries = DefaultJobRetries
+		}
+
+		update := &UpdateJobRetriesCommand{
+			Command: cmd.Command,
+			request: pb.UpdateJobRetriesRequest{JobKey: incident.JobKey, Retries: retries},
+		}
+		if _, err := update.Send(ctx); err != nil {
+			return nil, fmt.Errorf("failed to update retries of job %d: %w", incident.JobKey, err)
+		}
+	}
+
+	single := &ResolveIncidentCommand{
+		Command: cmd.Command,
+		request: pb.ResolveIncidentRequest{IncidentKey: incident.Key},
+	}
+	return single.Send(ctx)
+}
+
+func NewResolveIncidentBatchCommand(gateway pb.GatewayClient, pred retryPredicate) *ResolveIncidentBatchCommand {
+	return &ResolveIncidentBatchCommand{
+		Command: Command{
+			gateway:     gateway,
+			shouldRetry: pred,
+		},
+		concurrency: DefaultResolveIncidentBatchConcurrency,
+	}
+}
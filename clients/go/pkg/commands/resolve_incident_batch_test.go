@@ -0,0 +1,92 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"testing"
+)
+
+func TestResolveIncidentBatchCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	firstStub := &pb.ResolveIncidentResponse{}
+	secondStub := &pb.ResolveIncidentResponse{}
+
+	client.EXPECT().ResolveIncident(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.ResolveIncidentRequest{IncidentKey: 1}}).Return(firstStub, nil)
+	gomock.InOrder(
+		client.EXPECT().UpdateJobRetries(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.UpdateJobRetriesRequest{JobKey: 20, Retries: DefaultJobRetries}}).Return(&pb.UpdateJobRetriesResponse{}, nil),
+		client.EXPECT().ResolveIncident(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.ResolveIncidentRequest{IncidentKey: 2}}).Return(secondStub, nil),
+	)
+
+	command := NewResolveIncidentBatchCommand(client, func(context.Context, error) bool { return false })
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	results, err := command.
+		AddIncidentKeys(1).
+		AddIncidents(Incident{Key: 2, JobKey: 20}).
+		Concurrency(2).
+		Send(ctx)
+
+	if err != nil {
+		t.Errorf("Failed to send requests: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected a result per incident, but got %d", len(results))
+	}
+
+	if results[0].Response != firstStub || results[1].Response != secondStub {
+		t.Errorf("Failed to receive responses")
+	}
+}
+
+func TestResolveIncidentBatchCommandWithFailedRetriesUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	failure := errors.New("update failed")
+	client.EXPECT().UpdateJobRetries(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.UpdateJobRetriesRequest{JobKey: 10, Retries: 5}}).Return(nil, failure)
+	client.EXPECT().ResolveIncident(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.ResolveIncidentRequest{IncidentKey: 2}}).Return(&pb.ResolveIncidentResponse{}, nil)
+
+	command := NewResolveIncidentBatchCommand(client, func(context.Context, error) bool { return false })
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	results, err := command.
+		AddIncidents(Incident{Key: 1, JobKey: 10, JobRetries: 5}, Incident{Key: 2}).
+		Send(ctx)
+
+	if err == nil {
+		t.Errorf("Expected batch to fail")
+	}
+
+	if !errors.Is(results[0].Err, failure) || results[0].Response != nil || results[1].Err != nil {
+		t.Errorf("Expected only the first incident to fail, but got %v and %v", results[0].Err, results[1].Err)
+	}
+}
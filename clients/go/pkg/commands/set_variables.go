@@ -16,13 +16,19 @@
 package commands
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"reflect"
+	"time"
 )
 
 type DispatchSetVariablesCommand interface {
+	RequestTimeout(time.Duration) DispatchSetVariablesCommand
 	Local(bool) DispatchSetVariablesCommand
 	Send(context.Context) (*pb.SetVariablesResponse, error)
 }
@@ -37,6 +43,11 @@ type SetVariablesCommandStep2 interface {
 	VariablesFromMap(map[string]interface{}) (DispatchSetVariablesCommand, error)
 	VariablesFromObject(interface{}) (DispatchSetVariablesCommand, error)
 	VariablesFromObjectIgnoreOmitempty(interface{}) (DispatchSetVariablesCommand, error)
+	MergePatch(current, patch interface{}) (DispatchSetVariablesCommand, error)
+	// Set the variables of the map on the element instance only, like Local(true)
+	LocalVariables(map[string]interface{}) (DispatchSetVariablesCommand, error)
+	// Set the variables of the map on the scope which defines them, or the workflow instance, like Local(false)
+	ProcessVariables(map[string]interface{}) (DispatchSetVariablesCommand, error)
 }
 
 type SetVariablesCommand struct {
@@ -87,24 +98,133 @@ func (cmd *SetVariablesCommand) VariablesFromMap(variables map[string]interface{
 	return cmd.VariablesFromObject(variables)
 }
 
+// LocalVariables sets the variables on the element instance only, so they are not propagated to the scopes above it,
+// e.g. temporary variables which should not be visible in the workflow instance.
+func (cmd *SetVariablesCommand) LocalVariables(variables map[string]interface{}) (DispatchSetVariablesCommand, error) {
+	if _, err := cmd.VariablesFromObject(variables); err != nil {
+		return nil, err
+	}
+	return cmd.Local(true), nil
+}
+
+// ProcessVariables sets the variables on the scope which defines them, or on the workflow instance if no scope does.
+func (cmd *SetVariablesCommand) ProcessVariables(variables map[string]interface{}) (DispatchSetVariablesCommand, error) {
+	if _, err := cmd.VariablesFromObject(variables); err != nil {
+		return nil, err
+	}
+	return cmd.Local(false), nil
+}
+
+// MergePatch applies the JSON merge patch (RFC 7386) to the current variables and sets only the top-level variables
+// which are changed by it, with local set to false. The current variables are a snapshot which was read before, e.g.
+// the variables of the activated job; both are expected to be JSON objects. Variables can't be deleted, so variables
+// which are removed by the patch are set to null.
+func (cmd *SetVariablesCommand) MergePatch(current, patch interface{}) (DispatchSetVariablesCommand, error) {
+	currentDocument, err := cmd.asDocument("current variables", current)
+	if err != nil {
+		return nil, err
+	}
+	patchDocument, err := cmd.asDocument("patch", patch)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]interface{})
+	for name, value := range patchDocument {
+		previous, exists := currentDocument[name]
+		merged := mergePatch(previous, value)
+		if merged == nil && !exists {
+			continue
+		}
+		if !exists || !reflect.DeepEqual(previous, merged) {
+			changed[name] = merged
+		}
+	}
+
+	variables, err := cmd.mixin.AsJSON("variables", changed, false)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd.request.Variables = variables
+	cmd.request.Local = false
+	return cmd, nil
+}
+
+func (cmd *SetVariablesCommand) asDocument(name string, value interface{}) (map[string]interface{}, error) {
+	if value == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize %s: %w", name, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document map[string]interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("expected %s to be a JSON object: %w", name, err)
+	}
+	return document, nil
+}
+
+// mergePatch merges the patch into the target as specified by RFC 7386.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	merged := make(map[string]interface{}, len(targetObject))
+	if ok {
+		for name, value := range targetObject {
+			merged[name] = value
+		}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(merged, name)
+		} else {
+			merged[name] = mergePatch(merged[name], value)
+		}
+	}
+	return merged
+}
+
 func (cmd *SetVariablesCommand) Local(local bool) DispatchSetVariablesCommand {
 	cmd.request.Local = local
 	return cmd
 }
 
+func (cmd *SetVariablesCommand) RequestTimeout(timeout time.Duration) DispatchSetVariablesCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
 func (cmd *SetVariablesCommand) Send(ctx context.Context) (*pb.SetVariablesResponse, error) {
+	ctx, cancel := cmd.withRequestTimeout(ctx)
+	defer cancel()
+
 	response, err := cmd.gateway.SetVariables(ctx, &cmd.request)
 	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+		return cmd.Send(WithNextAttempt(ctx))
 	}
 
 	return response, err
 }
 
 func NewSetVariablesCommand(gateway pb.GatewayClient, pred retryPredicate) SetVariablesCommandStep1 {
+	return NewSetVariablesCommandWithCodec(gateway, pred, entities.JSONCodec)
+}
+
+func NewSetVariablesCommandWithCodec(gateway pb.GatewayClient, pred retryPredicate, codec entities.VariableCodec) SetVariablesCommandStep1 {
 	return &SetVariablesCommand{
 		Command: Command{
-			mixin:       utils.NewJSONStringSerializer(),
+			mixin:       utils.NewJSONStringSerializerWithCodec(codec),
 			gateway:     gateway,
 			shouldRetry: pred,
 		},
@@ -16,6 +16,7 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/golang/mock/gomock"
 	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
 	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
@@ -258,3 +259,95 @@ func TestSetVariablesCommandWithVariablesFromMap(t *testing.T) {
 		t.Errorf("Failed to receive response")
 	}
 }
+
+func TestSetVariablesCommandWithMergePatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	request := &pb.SetVariablesRequest{
+		ElementInstanceKey: 123,
+		Variables:          `{"b":{"x":1,"z":3},"c":null,"e":"new"}`,
+		Local:              false,
+	}
+	stub := &pb.SetVariablesResponse{
+		Key: 523,
+	}
+
+	client.EXPECT().SetVariables(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil)
+
+	command := NewSetVariablesCommand(client, func(context.Context, error) bool { return false })
+
+	current := map[string]interface{}{"a": 1, "b": map[string]int{"x": 1, "y": 2}, "c": "keep"}
+	patch := `{"a":1,"b":{"y":null,"z":3},"c":null,"d":null,"e":"new"}`
+	variablesCommand, err := command.ElementInstanceKey(123).MergePatch(current, json.RawMessage(patch))
+	if err != nil {
+		t.Fatal("Failed to apply merge patch: ", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	response, err := variablesCommand.Send(ctx)
+
+	if err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	if response != stub {
+		t.Errorf("Failed to receive response")
+	}
+}
+
+func TestSetVariablesCommandWithInvalidMergePatch(t *testing.T) {
+	command := NewSetVariablesCommand(nil, func(context.Context, error) bool { return false })
+
+	if _, err := command.ElementInstanceKey(123).MergePatch(nil, []string{"foo"}); err == nil {
+		t.Error("Expected patch which is not an object to be rejected")
+	}
+}
+
+func TestSetVariablesCommandWithLocalAndProcessVariables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	localRequest := &pb.SetVariablesRequest{
+		ElementInstanceKey: 123,
+		Variables:          `{"tmp":1}`,
+		Local:              true,
+	}
+	processRequest := &pb.SetVariablesRequest{
+		ElementInstanceKey: 123,
+		Variables:          `{"result":"ok"}`,
+		Local:              false,
+	}
+	stub := &pb.SetVariablesResponse{
+		Key: 523,
+	}
+
+	client.EXPECT().SetVariables(gomock.Any(), &utils.RPCTestMsg{Msg: localRequest}).Return(stub, nil)
+	client.EXPECT().SetVariables(gomock.Any(), &utils.RPCTestMsg{Msg: processRequest}).Return(stub, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	pred := func(context.Context, error) bool { return false }
+	localCommand, err := NewSetVariablesCommand(client, pred).ElementInstanceKey(123).LocalVariables(map[string]interface{}{"tmp": 1})
+	if err != nil {
+		t.Fatal("Failed to set local variables: ", err)
+	}
+	if _, err := localCommand.Send(ctx); err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	processCommand, err := NewSetVariablesCommand(client, pred).ElementInstanceKey(123).ProcessVariables(map[string]interface{}{"result": "ok"})
+	if err != nil {
+		t.Fatal("Failed to set process variables: ", err)
+	}
+	if _, err := processCommand.Send(ctx); err != nil {
+		t.Errorf("Failed to send request")
+	}
+}
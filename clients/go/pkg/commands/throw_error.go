@@ -17,7 +17,10 @@ package commands
 
 import (
 	"context"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"time"
 )
 
 type ThrowErrorCommandStep1 interface {
@@ -29,13 +32,17 @@ type ThrowErrorCommandStep2 interface {
 }
 
 type DispatchThrowErrorCommand interface {
+	RequestTimeout(time.Duration) DispatchThrowErrorCommand
 	ErrorMessage(string) DispatchThrowErrorCommand
+	// Set the variables of the object on the element instance of the job before the error is thrown
+	Variables(elementInstanceKey int64, variables interface{}) (DispatchThrowErrorCommand, error)
 	Send(context.Context) (*pb.ThrowErrorResponse, error)
 }
 
 type ThrowErrorCommand struct {
 	Command
-	request pb.ThrowErrorRequest
+	request   pb.ThrowErrorRequest
+	variables *pb.SetVariablesRequest
 }
 
 func (c *ThrowErrorCommand) JobKey(jobKey int64) ThrowErrorCommandStep2 {
@@ -53,21 +60,66 @@ func (c *ThrowErrorCommand) ErrorMessage(errorMsg string) DispatchThrowErrorComm
 	return c
 }
 
+// Variables sets the variables on the element instance of the job, which is the ElementInstanceKey of the activated
+// job, before the error is thrown. The gateway does not support variables on ThrowError commands, so they are set by a
+// separate command when the command is sent; as they are not local, they are propagated to the scope which defines
+// them, so the error event which catches the error can map them, e.g. diagnostic data of the handler.
+func (c *ThrowErrorCommand) Variables(elementInstanceKey int64, variables interface{}) (DispatchThrowErrorCommand, error) {
+	value, err := c.mixin.AsJSON("variables", variables, false)
+	if err != nil {
+		return nil, err
+	}
+
+	c.variables = &pb.SetVariablesRequest{ElementInstanceKey: elementInstanceKey, Variables: value}
+	return c, nil
+}
+
+func (c *ThrowErrorCommand) RequestTimeout(timeout time.Duration) DispatchThrowErrorCommand {
+	c.requestTimeout = timeout
+	return c
+}
+
 func (c *ThrowErrorCommand) Send(ctx context.Context) (*pb.ThrowErrorResponse, error) {
+	ctx, cancel := c.withRequestTimeout(ctx)
+	defer cancel()
+
+	if c.variables != nil {
+		if err := c.setVariables(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	response, err := c.gateway.ThrowError(ctx, &c.request)
 	if c.shouldRetry(ctx, err) {
-		return c.Send(ctx)
+		return c.Send(WithNextAttempt(ctx))
 	}
 
 	return response, err
 }
 
+func (c *ThrowErrorCommand) setVariables(ctx context.Context) error {
+	_, err := c.gateway.SetVariables(ctx, c.variables)
+	if c.shouldRetry(ctx, err) {
+		return c.setVariables(WithNextAttempt(ctx))
+	}
+	if err == nil {
+		// the variables are set once, also if throwing the error is retried
+		c.variables = nil
+	}
+
+	return err
+}
+
 func NewThrowErrorCommand(gateway pb.GatewayClient, pred retryPredicate) ThrowErrorCommandStep1 {
+	return NewThrowErrorCommandWithCodec(gateway, pred, entities.JSONCodec)
+}
+
+func NewThrowErrorCommandWithCodec(gateway pb.GatewayClient, pred retryPredicate, codec entities.VariableCodec) ThrowErrorCommandStep1 {
 	return &ThrowErrorCommand{
 		Command: Command{
+			mixin:       utils.NewJSONStringSerializerWithCodec(codec),
 			gateway:     gateway,
 			shouldRetry: pred,
 		},
 	}
-
 }
@@ -54,3 +54,44 @@ func TestThrowErrorCommand(t *testing.T) {
 		t.Errorf("Failed to receive response")
 	}
 }
+
+func TestThrowErrorCommandWithVariables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	variablesRequest := &pb.SetVariablesRequest{
+		ElementInstanceKey: 456,
+		Variables:          `{"reason":"timeout"}`,
+	}
+	request := &pb.ThrowErrorRequest{
+		JobKey:    123,
+		ErrorCode: "someErrorCode",
+	}
+	stub := &pb.ThrowErrorResponse{}
+
+	gomock.InOrder(
+		client.EXPECT().SetVariables(gomock.Any(), &utils.RPCTestMsg{Msg: variablesRequest}).Return(&pb.SetVariablesResponse{}, nil),
+		client.EXPECT().ThrowError(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil),
+	)
+
+	command, err := NewThrowErrorCommand(client, func(context.Context, error) bool { return false }).
+		JobKey(123).ErrorCode("someErrorCode").Variables(456, map[string]interface{}{"reason": "timeout"})
+	if err != nil {
+		t.Fatal("Failed to set variables: ", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	response, err := command.Send(ctx)
+
+	if err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	if response != stub {
+		t.Errorf("Failed to receive response")
+	}
+}
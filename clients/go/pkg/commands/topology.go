@@ -18,16 +18,25 @@ package commands
 import (
 	"context"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"time"
 )
 
 type TopologyCommand struct {
 	Command
 }
 
+func (cmd *TopologyCommand) RequestTimeout(timeout time.Duration) *TopologyCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
 func (cmd *TopologyCommand) Send(ctx context.Context) (*pb.TopologyResponse, error) {
+	ctx, cancel := cmd.withRequestTimeout(ctx)
+	defer cancel()
+
 	response, err := cmd.gateway.Topology(ctx, &pb.TopologyRequest{})
 	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+		return cmd.Send(WithNextAttempt(ctx))
 	}
 
 	return response, err
@@ -21,6 +21,7 @@ import (
 	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
 	"testing"
+	"time"
 )
 
 func TestTopologyCommand(t *testing.T) {
@@ -88,3 +89,30 @@ func TestTopologyCommand(t *testing.T) {
 		t.Errorf("Failed to receive response")
 	}
 }
+
+func TestTopologyCommandWithRequestTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	var deadline time.Time
+	client.EXPECT().Topology(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ *pb.TopologyRequest, _ ...interface{}) (*pb.TopologyResponse, error) {
+			deadline, _ = ctx.Deadline()
+			return &pb.TopologyResponse{}, nil
+		})
+
+	start := time.Now()
+	command := NewTopologyCommand(client, func(context.Context, error) bool { return false })
+
+	_, err := command.RequestTimeout(time.Minute).Send(context.Background())
+
+	if err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	if deadline.Before(start.Add(time.Minute)) || deadline.After(time.Now().Add(time.Minute)) {
+		t.Errorf("Expected deadline in one minute, but got %v", deadline)
+	}
+}
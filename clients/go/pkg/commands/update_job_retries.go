@@ -18,6 +18,7 @@ package commands
 import (
 	"context"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"time"
 )
 
 const (
@@ -25,6 +26,7 @@ const (
 )
 
 type DispatchUpdateJobRetriesCommand interface {
+	RequestTimeout(time.Duration) DispatchUpdateJobRetriesCommand
 	Send(context.Context) (*pb.UpdateJobRetriesResponse, error)
 }
 
@@ -53,10 +55,18 @@ func (cmd *UpdateJobRetriesCommand) Retries(retries int32) DispatchUpdateJobRetr
 	return cmd
 }
 
+func (cmd *UpdateJobRetriesCommand) RequestTimeout(timeout time.Duration) DispatchUpdateJobRetriesCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
 func (cmd *UpdateJobRetriesCommand) Send(ctx context.Context) (*pb.UpdateJobRetriesResponse, error) {
+	ctx, cancel := cmd.withRequestTimeout(ctx)
+	defer cancel()
+
 	response, err := cmd.gateway.UpdateJobRetries(ctx, &cmd.request)
 	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+		return cmd.Send(WithNextAttempt(ctx))
 	}
 
 	return response, err
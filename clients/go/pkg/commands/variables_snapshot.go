@@ -0,0 +1,196 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"io"
+	"sort"
+	"time"
+)
+
+// VariableLookup returns the variables of a workflow instance by the key of their scope, which is the workflow instance
+// or one of its element instances, e.g. records.VariableStore, or a lookup in the exported data of Operate or
+// Elasticsearch.
+type VariableLookup interface {
+	Variables(ctx context.Context, workflowInstanceKey int64) (map[int64]map[string]json.RawMessage, error)
+}
+
+// VariableLookupFunc adapts a function to the VariableLookup interface.
+type VariableLookupFunc func(ctx context.Context, workflowInstanceKey int64) (map[int64]map[string]json.RawMessage, error)
+
+func (f VariableLookupFunc) Variables(ctx context.Context, workflowInstanceKey int64) (map[int64]map[string]json.RawMessage, error) {
+	return f(ctx, workflowInstanceKey)
+}
+
+// ScopeVariables are the variables of a scope, which are written as one JSON line by ExportVariablesCommand and read by
+// ImportVariablesCommand.
+type ScopeVariables struct {
+	WorkflowInstanceKey int64                      `json:"workflowInstanceKey"`
+	ScopeKey            int64                      `json:"scopeKey"`
+	Variables           map[string]json.RawMessage `json:"variables"`
+}
+
+// VariablesSnapshotResult counts the scopes and variables which were exported or imported.
+type VariablesSnapshotResult struct {
+	Scopes    int
+	Variables int
+}
+
+type ExportVariablesCommand struct {
+	workflowInstanceKey int64
+	lookup              VariableLookup
+	writer              io.Writer
+}
+
+func (cmd *ExportVariablesCommand) WorkflowInstanceKey(key int64) *ExportVariablesCommand {
+	cmd.workflowInstanceKey = key
+	return cmd
+}
+
+// Variables sets the lookup of the variables of the workflow instance, which is required.
+func (cmd *ExportVariablesCommand) Variables(lookup VariableLookup) *ExportVariablesCommand {
+	cmd.lookup = lookup
+	return cmd
+}
+
+// To sets the writer of the snapshot, which is required.
+func (cmd *ExportVariablesCommand) To(writer io.Writer) *ExportVariablesCommand {
+	cmd.writer = writer
+	return cmd
+}
+
+// Send writes the variables of the workflow instance as JSON lines, one per scope, starting with the workflow instance
+// and followed by the element instances in ascending order of their keys, so they can be imported in order.
+func (cmd *ExportVariablesCommand) Send(ctx context.Context) (*VariablesSnapshotResult, error) {
+	if cmd.lookup == nil || cmd.writer == nil {
+		return nil, errors.New("expected a variable lookup and a writer to export variables, but got none")
+	}
+
+	scopes, err := cmd.lookup.Variables(ctx, cmd.workflowInstanceKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up variables of workflow instance %d: %w", cmd.workflowInstanceKey, err)
+	}
+
+	scopeKeys := make([]int64, 0, len(scopes))
+	for scopeKey := range scopes {
+		scopeKeys = append(scopeKeys, scopeKey)
+	}
+	sort.Slice(scopeKeys, func(i, j int) bool {
+		if scopeKeys[i] == cmd.workflowInstanceKey || scopeKeys[j] == cmd.workflowInstanceKey {
+			return scopeKeys[i] == cmd.workflowInstanceKey
+		}
+		return scopeKeys[i] < scopeKeys[j]
+	})
+
+	result := &VariablesSnapshotResult{}
+	encoder := json.NewEncoder(cmd.writer)
+	for _, scopeKey := range scopeKeys {
+		line := ScopeVariables{WorkflowInstanceKey: cmd.workflowInstanceKey, ScopeKey: scopeKey, Variables: scopes[scopeKey]}
+		if err := encoder.Encode(line); err != nil {
+			return result, fmt.Errorf("failed to export variables of scope %d: %w", scopeKey, err)
+		}
+		result.Scopes++
+		result.Variables += len(line.Variables)
+	}
+	return result, nil
+}
+
+func NewExportVariablesCommand() *ExportVariablesCommand {
+	return &ExportVariablesCommand{}
+}
+
+type ImportVariablesCommand struct {
+	Command
+	reader io.Reader
+}
+
+// From sets the reader of the snapshot which was written by ExportVariablesCommand, which is required.
+func (cmd *ImportVariablesCommand) From(reader io.Reader) *ImportVariablesCommand {
+	cmd.reader = reader
+	return cmd
+}
+
+func (cmd *ImportVariablesCommand) RequestTimeout(timeout time.Duration) *ImportVariablesCommand {
+	cmd.requestTimeout = timeout
+	return cmd
+}
+
+// Send sets the variables of each line of the snapshot on its scope, in order, with local set to true, so they are
+// restored on the scope they were exported from. If the variables of a scope can't be set, the lines after it are not
+// imported and the result so far is returned with the error.
+func (cmd *ImportVariablesCommand) Send(ctx context.Context) (*VariablesSnapshotResult, error) {
+	if cmd.reader == nil {
+		return nil, errors.New("expected a reader to import variables, but got none")
+	}
+
+	result := &VariablesSnapshotResult{}
+	scanner := bufio.NewScanner(cmd.reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var scope ScopeVariables
+		if err := json.Unmarshal(scanner.Bytes(), &scope); err != nil {
+			return result, fmt.Errorf("failed to read variables in line %d: %w", line, err)
+		}
+		if scope.ScopeKey <= 0 {
+			return result, fmt.Errorf("expected variables in line %d to have a scope key, but got %d", line, scope.ScopeKey)
+		}
+		if len(scope.Variables) == 0 {
+			continue
+		}
+
+		if err := cmd.setVariables(ctx, scope); err != nil {
+			return result, fmt.Errorf("failed to import variables of scope %d of workflow instance %d: %w", scope.ScopeKey, scope.WorkflowInstanceKey, err)
+		}
+		result.Scopes++
+		result.Variables += len(scope.Variables)
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("failed to read variables: %w", err)
+	}
+	return result, nil
+}
+
+func (cmd *ImportVariablesCommand) setVariables(ctx context.Context, scope ScopeVariables) error {
+	variables, err := json.Marshal(scope.Variables)
+	if err != nil {
+		return err
+	}
+
+	single := &SetVariablesCommand{
+		Command: cmd.Command,
+		request: pb.SetVariablesRequest{ElementInstanceKey: scope.ScopeKey, Variables: string(variables), Local: true},
+	}
+	_, err = single.Send(ctx)
+	return err
+}
+
+func NewImportVariablesCommand(gateway pb.GatewayClient, pred retryPredicate) *ImportVariablesCommand {
+	return &ImportVariablesCommand{
+		Command: Command{
+			gateway:     gateway,
+			shouldRetry: pred,
+		},
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"testing"
+)
+
+func TestExportVariablesCommand(t *testing.T) {
+	lookup := VariableLookupFunc(func(_ context.Context, workflowInstanceKey int64) (map[int64]map[string]json.RawMessage, error) {
+		if workflowInstanceKey != 10 {
+			t.Errorf("Expected variables of workflow instance 10 to be looked up, but got %d", workflowInstanceKey)
+		}
+		return map[int64]map[string]json.RawMessage{
+			30: {"attempt": json.RawMessage("1")},
+			20: {"item": json.RawMessage(`{"sku":"A-1"}`)},
+			10: {"orderId": json.RawMessage(`"DE-42"`), "total": json.RawMessage("12.5")},
+		}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	result, err := NewExportVariablesCommand().WorkflowInstanceKey(10).Variables(lookup).To(&out).Send(ctx)
+	if err != nil {
+		t.Fatal("Failed to export variables: ", err)
+	}
+
+	expected := `{"workflowInstanceKey":10,"scopeKey":10,"variables":{"orderId":"DE-42","total":12.5}}
+{"workflowInstanceKey":10,"scopeKey":20,"variables":{"item":{"sku":"A-1"}}}
+{"workflowInstanceKey":10,"scopeKey":30,"variables":{"attempt":1}}
+`
+	if out.String() != expected {
+		t.Errorf("Expected snapshot %s, but got %s", expected, out.String())
+	}
+	if *result != (VariablesSnapshotResult{Scopes: 3, Variables: 4}) {
+		t.Errorf("Expected 3 scopes with 4 variables to be exported, but got %+v", result)
+	}
+}
+
+func TestImportVariablesCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	gomock.InOrder(
+		client.EXPECT().SetVariables(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.SetVariablesRequest{
+			ElementInstanceKey: 10,
+			Variables:          `{"orderId":"DE-42","total":12.5}`,
+			Local:              true,
+		}}).Return(&pb.SetVariablesResponse{Key: 1}, nil),
+		client.EXPECT().SetVariables(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.SetVariablesRequest{
+			ElementInstanceKey: 30,
+			Variables:          `{"attempt":1}`,
+			Local:              true,
+		}}).Return(&pb.SetVariablesResponse{Key: 2}, nil),
+	)
+
+	snapshot := `{"workflowInstanceKey":10,"scopeKey":10,"variables":{"orderId":"DE-42","total":12.5}}
+
+{"workflowInstanceKey":10,"scopeKey":20,"variables":{}}
+{"workflowInstanceKey":10,"scopeKey":30,"variables":{"attempt":1}}
+`
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	result, err := NewImportVariablesCommand(client, func(context.Context, error) bool { return false }).From(strings.NewReader(snapshot)).Send(ctx)
+	if err != nil {
+		t.Fatal("Failed to import variables: ", err)
+	}
+	if *result != (VariablesSnapshotResult{Scopes: 2, Variables: 3}) {
+		t.Errorf("Expected 2 scopes with 3 variables to be imported, but got %+v", result)
+	}
+}
+
+func TestImportVariablesCommandStopsAtError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().SetVariables(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.NotFound, "scope not found"))
+
+	snapshot := `{"workflowInstanceKey":10,"scopeKey":10,"variables":{"orderId":"DE-42"}}
+{"workflowInstanceKey":10,"scopeKey":30,"variables":{"attempt":1}}
+`
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	result, err := NewImportVariablesCommand(client, func(context.Context, error) bool { return false }).From(strings.NewReader(snapshot)).Send(ctx)
+	if status.Code(errors.Unwrap(err)) != codes.NotFound {
+		t.Errorf("Expected import to fail with the error of the gateway, but got %v", err)
+	}
+	if result.Scopes != 0 {
+		t.Errorf("Expected no scope to be imported, but got %d", result.Scopes)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connector runs outbound connectors as job workers: a connector is a Definition of a job type, optionally
+// with a JSON Schema of its input variables, and a Function which calls the external system with the variables of the
+// job and returns the response.
+//
+// The runtime resolves the secret placeholders in the variables, validates them against the input schema and maps the
+// response to the variables which complete the job, as configured by the custom headers of the service task:
+//
+//	resultVariable   - the name of the variable which is set to the whole response
+//	resultExpression - a FEEL expression with the variable 'response' whose result must be a context, i.e. a map;
+//	                   its entries are set as variables, e.g. {"orderId": response.body.id}
+//
+// Errors of a function are retried by the worker with decremented retries. Errors which are wrapped by NonRetriable
+// fail the job without retries, and a worker.BPMNError, also wrapped, is thrown as BPMN error, so it can be caught by
+// an error event.
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/feel"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/jsonschema"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/secrets"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
+)
+
+const (
+	// ResultVariableHeader is the custom header which contains the name of the variable that is set to the response
+	ResultVariableHeader = "resultVariable"
+	// ResultExpressionHeader is the custom header which contains the FEEL expression that maps the response
+	ResultExpressionHeader = "resultExpression"
+	// ResponseVariable is the name of the response in the result expression
+	ResponseVariable = "response"
+)
+
+// Definition describes a connector. It can be loaded from a JSON document with LoadDefinitions.
+type Definition struct {
+	// Name of the connector, which is also the name of its job worker
+	Name string `json:"name"`
+	// Type is the job type of the service tasks which use the connector
+	Type string `json:"type"`
+	// InputSchema is a JSON Schema of the input variables, which are not validated if it is empty
+	InputSchema json.RawMessage `json:"inputSchema,omitempty"`
+}
+
+// LoadDefinitions reads a JSON array of connector definitions.
+func LoadDefinitions(reader io.Reader) ([]Definition, error) {
+	var definitions []Definition
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&definitions); err != nil {
+		return nil, fmt.Errorf("failed to read connector definitions: %w", err)
+	}
+	return definitions, nil
+}
+
+// Function calls the external system of a connector with the variables of the job, whose secrets are resolved, and
+// returns the response, which must be serializable as JSON. The context is done when the job deadline passes or the
+// runtime is closed.
+type Function func(ctx context.Context, job *entities.Job) (interface{}, error)
+
+type nonRetriableError struct {
+	err error
+}
+
+// NonRetriable wraps the error of a function, so the job is failed without retries, e.g. if the request was rejected
+// by the external system and would be rejected again.
+func NonRetriable(err error) error {
+	return &nonRetriableError{err: err}
+}
+
+func (e *nonRetriableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *nonRetriableError) Unwrap() error {
+	return e.err
+}
+
+// IsNonRetriable returns true if the error, or an error it wraps, was created by NonRetriable.
+func IsNonRetriable(err error) bool {
+	var nonRetriable *nonRetriableError
+	return errors.As(err, &nonRetriable)
+}
+
+// Options configure a Runtime.
+type Options struct {
+	// Secrets resolves the secret placeholders in the variables of the jobs; they are not resolved if nil
+	Secrets secrets.Provider
+	// MaxJobsActive of the workers, the default of the workers if zero
+	MaxJobsActive int
+	// Logger, logging.Default if nil
+	Logger logging.Logger
+}
+
+// Runtime opens a job worker for every registered connector.
+type Runtime struct {
+	client  worker.WorkerClient
+	options Options
+
+	lock    sync.Mutex
+	workers map[string]worker.JobWorker
+}
+
+// New creates a runtime which opens the workers of the connectors with the client, e.g. a zbc.Client.
+func New(client worker.WorkerClient, options Options) *Runtime {
+	if options.Logger == nil {
+		options.Logger = logging.Default
+	}
+
+	return &Runtime{client: client, options: options, workers: make(map[string]worker.JobWorker)}
+}
+
+// Register opens the job worker of the connector. It fails if the definition is invalid or a connector with the same
+// job type is already registered.
+func (r *Runtime) Register(definition Definition, function Function) error {
+	if definition.Type == "" {
+		return fmt.Errorf("expected connector '%s' to have a job type, but it has none", definition.Name)
+	}
+	if function == nil {
+		return fmt.Errorf("expected connector '%s' to have a function, but it has none", definition.Name)
+	}
+
+	var schema *jsonschema.Schema
+	if len(bytes.TrimSpace(definition.InputSchema)) > 0 {
+		var err error
+		if schema, err = jsonschema.Compile(string(definition.InputSchema)); err != nil {
+			return fmt.Errorf("invalid input schema of connector '%s': %w", definition.Name, err)
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, exists := r.workers[definition.Type]; exists {
+		return fmt.Errorf("expected to register connector '%s' for job type '%s', but the type is already registered", definition.Name, definition.Type)
+	}
+
+	builder := r.client.NewJobWorker().JobType(definition.Type).ContextHandler(r.handler(function)).
+		FailureHandler(failureHandler).Logger(r.options.Logger)
+	if definition.Name != "" {
+		builder = builder.Name(definition.Name)
+	}
+	if r.options.MaxJobsActive > 0 {
+		builder = builder.MaxJobsActive(r.options.MaxJobsActive)
+	}
+	if r.options.Secrets != nil {
+		builder = builder.SecretResolution(r.options.Secrets)
+	}
+	if schema != nil {
+		builder = builder.InputSchema(schema)
+	}
+
+	r.workers[definition.Type] = builder.Open()
+	return nil
+}
+
+// RegisterDefinitions registers the connectors of the definitions with the functions of the same name. If a function
+// is missing or a definition is invalid, no connector is registered.
+func (r *Runtime) RegisterDefinitions(definitions []Definition, functions map[string]Function) error {
+	for _, definition := range definitions {
+		if functions[definition.Name] == nil {
+			return fmt.Errorf("expected a function for connector '%s', but there is none", definition.Name)
+		}
+		if len(bytes.TrimSpace(definition.InputSchema)) > 0 {
+			if _, err := jsonschema.Compile(string(definition.InputSchema)); err != nil {
+				return fmt.Errorf("invalid input schema of connector '%s': %w", definition.Name, err)
+			}
+		}
+	}
+
+	for _, definition := range definitions {
+		if err := r.Register(definition, functions[definition.Name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the workers of all connectors and waits until their handlers returned.
+func (r *Runtime) Close() {
+	r.lock.Lock()
+	workers := r.workers
+	r.workers = make(map[string]worker.JobWorker)
+	r.lock.Unlock()
+
+	for _, jobWorker := range workers {
+		jobWorker.Close()
+	}
+}
+
+func (r *Runtime) handler(function Function) worker.ContextJobHandler {
+	return func(ctx context.Context, client worker.JobClient, job entities.Job) error {
+		response, err := function(ctx, &job)
+		if err != nil {
+			return err
+		}
+
+		variables, err := mapResult(&job, response)
+		if err != nil {
+			return NonRetriable(err)
+		}
+
+		command, err := client.NewCompleteJobCommand().JobKey(job.Key).VariablesFromMap(variables)
+		if err != nil {
+			return NonRetriable(err)
+		}
+		if _, err := command.Send(ctx); err != nil {
+			r.options.Logger.Warn("Failed to complete job of connector", "jobKey", job.Key, "jobType", job.Type, "error", err)
+		}
+		return nil
+	}
+}
+
+// mapResult returns the variables of the response, as configured by the custom headers of the job.
+func mapResult(job *entities.Job, response interface{}) (map[string]interface{}, error) {
+	headers, err := job.GetCustomHeadersAsMap()
+	if err != nil {
+		return nil, err
+	}
+
+	variables := make(map[string]interface{})
+	if name := headers[ResultVariableHeader]; name != "" {
+		variables[name] = response
+	}
+
+	if expression := headers[ResultExpressionHeader]; expression != "" {
+		result, err := feel.Evaluate(expression, map[string]interface{}{ResponseVariable: response})
+		if err != nil {
+			return nil, fmt.Errorf("failed to map response: %w", err)
+		}
+		mapped, ok := result.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected result expression '%s' to return a context, but it returned %v", expression, result)
+		}
+		for name, value := range mapped {
+			variables[name] = value
+		}
+	}
+	return variables, nil
+}
+
+func failureHandler(_ entities.Job, err error) worker.FailureDecision {
+	if IsNonRetriable(err) {
+		return worker.FailJobWithoutRetries()
+	}
+	return worker.RetryJob(0)
+}
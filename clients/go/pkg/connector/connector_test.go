@@ -0,0 +1,200 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/secrets"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbtest/mockgateway"
+)
+
+func startRuntime(t *testing.T, options Options) (*mockgateway.Gateway, *Runtime, func()) {
+	gateway, err := mockgateway.Start()
+	require.NoError(t, err)
+	client, err := gateway.NewClient()
+	require.NoError(t, err)
+
+	runtime := New(client, options)
+	return gateway, runtime, func() {
+		runtime.Close()
+		_ = client.Close()
+		gateway.Close()
+	}
+}
+
+func TestLoadDefinitions(t *testing.T) {
+	definitions, err := LoadDefinitions(strings.NewReader(`[
+		{"name": "http", "type": "io.example:http:1", "inputSchema": {"type": "object", "required": ["url"]}},
+		{"name": "mail", "type": "io.example:mail:1"}
+	]`))
+
+	require.NoError(t, err)
+	require.Len(t, definitions, 2)
+	require.Equal(t, "io.example:http:1", definitions[0].Type)
+	require.JSONEq(t, `{"type": "object", "required": ["url"]}`, string(definitions[0].InputSchema))
+	require.Empty(t, definitions[1].InputSchema)
+}
+
+func TestLoadDefinitionsWithUnknownField(t *testing.T) {
+	_, err := LoadDefinitions(strings.NewReader(`[{"name": "http", "jobType": "http"}]`))
+
+	require.Error(t, err)
+}
+
+func TestCompleteJobWithResultVariable(t *testing.T) {
+	gateway, runtime, closeRuntime := startRuntime(t, Options{})
+	defer closeRuntime()
+
+	err := runtime.Register(Definition{Name: "http", Type: "http"}, func(_ context.Context, job *entities.Job) (interface{}, error) {
+		return map[string]interface{}{"status": 200}, nil
+	})
+	require.NoError(t, err)
+
+	gateway.AddJobs(&pb.ActivatedJob{Key: 1, Type: "http", Retries: 3, CustomHeaders: `{"resultVariable":"httpResponse"}`})
+
+	gateway.Recorder(t).AssertCompletedJob(1).WithVariables(map[string]interface{}{"httpResponse": map[string]interface{}{"status": 200}})
+}
+
+func TestCompleteJobWithResultExpression(t *testing.T) {
+	gateway, runtime, closeRuntime := startRuntime(t, Options{})
+	defer closeRuntime()
+
+	err := runtime.Register(Definition{Name: "http", Type: "http"}, func(_ context.Context, job *entities.Job) (interface{}, error) {
+		return map[string]interface{}{"status": 201, "body": map[string]interface{}{"id": "order-1"}}, nil
+	})
+	require.NoError(t, err)
+
+	gateway.AddJobs(&pb.ActivatedJob{Key: 1, Type: "http", Retries: 3, CustomHeaders: `{"resultExpression":"{orderId: response.body.id}"}`})
+
+	gateway.Recorder(t).AssertCompletedJob(1).WithVariables(map[string]interface{}{"orderId": "order-1"})
+}
+
+func TestFailJobIfResultExpressionIsNoContext(t *testing.T) {
+	gateway, runtime, closeRuntime := startRuntime(t, Options{})
+	defer closeRuntime()
+
+	err := runtime.Register(Definition{Name: "http", Type: "http"}, func(_ context.Context, job *entities.Job) (interface{}, error) {
+		return map[string]interface{}{"status": 200}, nil
+	})
+	require.NoError(t, err)
+
+	gateway.AddJobs(&pb.ActivatedJob{Key: 1, Type: "http", Retries: 3, CustomHeaders: `{"resultExpression":"response.status"}`})
+
+	gateway.Recorder(t).AssertFailedJob(1).WithRetries(0)
+}
+
+func TestRetryJobOnError(t *testing.T) {
+	gateway, runtime, closeRuntime := startRuntime(t, Options{})
+	defer closeRuntime()
+
+	err := runtime.Register(Definition{Name: "http", Type: "http"}, func(context.Context, *entities.Job) (interface{}, error) {
+		return nil, errors.New("connection refused")
+	})
+	require.NoError(t, err)
+
+	gateway.AddJobs(&pb.ActivatedJob{Key: 1, Type: "http", Retries: 3})
+
+	gateway.Recorder(t).AssertFailedJob(1).WithRetries(2).WithErrorMessage("connection refused")
+}
+
+func TestFailJobWithoutRetriesOnNonRetriableError(t *testing.T) {
+	gateway, runtime, closeRuntime := startRuntime(t, Options{})
+	defer closeRuntime()
+
+	err := runtime.Register(Definition{Name: "http", Type: "http"}, func(context.Context, *entities.Job) (interface{}, error) {
+		return nil, NonRetriable(errors.New("bad request"))
+	})
+	require.NoError(t, err)
+
+	gateway.AddJobs(&pb.ActivatedJob{Key: 1, Type: "http", Retries: 3})
+
+	gateway.Recorder(t).AssertFailedJob(1).WithRetries(0).WithErrorMessage("bad request")
+}
+
+func TestThrowBPMNError(t *testing.T) {
+	gateway, runtime, closeRuntime := startRuntime(t, Options{})
+	defer closeRuntime()
+
+	err := runtime.Register(Definition{Name: "http", Type: "http"}, func(context.Context, *entities.Job) (interface{}, error) {
+		return nil, worker.NewBPMNError("NOT_FOUND", "order does not exist", nil)
+	})
+	require.NoError(t, err)
+
+	gateway.AddJobs(&pb.ActivatedJob{Key: 1, Type: "http", Retries: 3})
+
+	gateway.Recorder(t).AssertThrownError(1, "NOT_FOUND").WithErrorMessage("order does not exist")
+}
+
+func TestResolveSecretsAndValidateInput(t *testing.T) {
+	provider := secrets.ProviderFunc(func(_ context.Context, name string) (string, error) {
+		if name == "TOKEN" {
+			return "s3cr3t", nil
+		}
+		return "", secrets.ErrNotFound
+	})
+	gateway, runtime, closeRuntime := startRuntime(t, Options{Secrets: provider})
+	defer closeRuntime()
+
+	definition := Definition{Name: "http", Type: "http", InputSchema: []byte(`{"type": "object", "required": ["token"]}`)}
+	err := runtime.Register(definition, func(_ context.Context, job *entities.Job) (interface{}, error) {
+		variables, err := job.GetVariablesAsMap()
+		if err != nil {
+			return nil, err
+		}
+		return variables["token"], nil
+	})
+	require.NoError(t, err)
+
+	gateway.AddJobs(
+		&pb.ActivatedJob{Key: 1, Type: "http", Retries: 3, Variables: `{"token":"{{secrets.TOKEN}}"}`, CustomHeaders: `{"resultVariable":"token"}`},
+		&pb.ActivatedJob{Key: 2, Type: "http", Retries: 3, Variables: `{}`},
+	)
+
+	recorder := gateway.Recorder(t)
+	recorder.AssertCompletedJob(1).WithVariables(map[string]interface{}{"token": "s3cr3t"})
+	recorder.AssertFailedJob(2).WithRetries(0)
+}
+
+func TestRegisterInvalidDefinitions(t *testing.T) {
+	_, runtime, closeRuntime := startRuntime(t, Options{})
+	defer closeRuntime()
+
+	function := func(context.Context, *entities.Job) (interface{}, error) { return nil, nil }
+
+	require.Error(t, runtime.Register(Definition{Name: "http"}, function))
+	require.Error(t, runtime.Register(Definition{Name: "http", Type: "http", InputSchema: []byte(`{"type": 1}`)}, function))
+	require.NoError(t, runtime.Register(Definition{Name: "http", Type: "http"}, function))
+	require.Error(t, runtime.Register(Definition{Name: "other", Type: "http"}, function))
+}
+
+func TestRegisterDefinitionsWithMissingFunction(t *testing.T) {
+	_, runtime, closeRuntime := startRuntime(t, Options{})
+	defer closeRuntime()
+
+	definitions := []Definition{{Name: "http", Type: "http"}, {Name: "mail", Type: "mail"}}
+	functions := map[string]Function{"http": func(context.Context, *entities.Job) (interface{}, error) { return nil, nil }}
+
+	require.Error(t, runtime.RegisterDefinitions(definitions, functions))
+	require.Empty(t, runtime.workers)
+}
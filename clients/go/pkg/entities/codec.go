@@ -0,0 +1,56 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"encoding/json"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+// VariableCodec serializes and deserializes variables. The gateway expects
+// variables as JSON documents, so a codec can replace encoding/json with a
+// faster implementation, but must still produce JSON.
+type VariableCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// VariablesResolver can additionally be implemented by a VariableCodec which
+// encodes variables in a way the gateway doesn't know about, e.g. by compressing
+// them. It rewrites the variables to plain JSON, so they can also be decoded with
+// encoding/json when decoder options are given.
+type VariablesResolver interface {
+	ResolveVariables(data []byte) ([]byte, error)
+}
+
+// JSONCodec is the default VariableCodec, based on encoding/json.
+var JSONCodec VariableCodec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// NewJob creates a job from an activated job, whose variables and custom
+// headers are decoded with the given codec.
+func NewJob(job *pb.ActivatedJob, codec VariableCodec) Job {
+	return Job{ActivatedJob: *job, codec: codec}
+}
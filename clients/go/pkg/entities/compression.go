@@ -0,0 +1,146 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// CompressedVariablePrefix starts the string value of a variable which was compressed by a compressing codec. The
+// rest of the value is the base64 encoded gzip of the JSON value of the variable.
+const CompressedVariablePrefix = "zeebe:gzip+base64:"
+
+// NewCompressingCodec creates a VariableCodec which compresses the top-level variables whose JSON value is larger
+// than threshold bytes, e.g. to stay below the maximum message size of the broker with large documents. Compressed
+// variables are decompressed again when they are decoded with the codec, but they are opaque strings to the
+// workflow, so they must not be used in expressions or mappings. Variables which are set as JSON string, e.g. with
+// VariablesFromString, are sent unchanged.
+func NewCompressingCodec(codec VariableCodec, threshold int) VariableCodec {
+	return compressingCodec{codec: codec, threshold: threshold}
+}
+
+type compressingCodec struct {
+	codec     VariableCodec
+	threshold int
+}
+
+func (c compressingCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := c.codec.Marshal(v)
+	if err != nil || len(data) <= c.threshold {
+		return data, err
+	}
+
+	var variables map[string]json.RawMessage
+	if json.Unmarshal(data, &variables) != nil {
+		// not a document of variables
+		return data, nil
+	}
+
+	compressed := false
+	for name, value := range variables {
+		if len(value) <= c.threshold {
+			continue
+		}
+
+		encoded, err := compress(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress variable '%s': %w", name, err)
+		}
+		variables[name] = encoded
+		compressed = true
+	}
+
+	if !compressed {
+		return data, nil
+	}
+	return json.Marshal(variables)
+}
+
+func (c compressingCodec) Unmarshal(data []byte, v interface{}) error {
+	data, err := c.ResolveVariables(data)
+	if err != nil {
+		return err
+	}
+
+	return c.codec.Unmarshal(data, v)
+}
+
+// ResolveVariables decompresses the compressed variables of the document.
+func (c compressingCodec) ResolveVariables(data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte(CompressedVariablePrefix)) {
+		return data, nil
+	}
+
+	var variables map[string]json.RawMessage
+	if json.Unmarshal(data, &variables) != nil {
+		return data, nil
+	}
+
+	for name, value := range variables {
+		var encoded string
+		if json.Unmarshal(value, &encoded) != nil || !strings.HasPrefix(encoded, CompressedVariablePrefix) {
+			continue
+		}
+
+		decompressed, err := decompress(strings.TrimPrefix(encoded, CompressedVariablePrefix))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress variable '%s': %w", name, err)
+		}
+		variables[name] = decompressed
+	}
+
+	return json.Marshal(variables)
+}
+
+func compress(value []byte) (json.RawMessage, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(value); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(CompressedVariablePrefix + base64.StdEncoding.EncodeToString(buffer.Bytes()))
+}
+
+func decompress(encoded string) (json.RawMessage, error) {
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	value, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(value) {
+		return nil, fmt.Errorf("expected compressed value to be JSON")
+	}
+	return value, nil
+}
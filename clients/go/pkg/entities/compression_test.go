@@ -0,0 +1,98 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type document struct {
+	ID      string
+	Content string
+}
+
+func TestCompressingCodec_Marshal(t *testing.T) {
+	codec := NewCompressingCodec(JSONCodec, 100)
+	variables := map[string]interface{}{
+		"id":       "small",
+		"document": document{ID: "1", Content: strings.Repeat("a", 1000)},
+	}
+
+	data, err := codec.Marshal(variables)
+	if err != nil {
+		t.Fatalf("codec.Marshal() = %v", err)
+	}
+
+	var encoded map[string]string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		t.Fatalf("expected compressed variables to be JSON strings: %v", err)
+	}
+	if encoded["id"] != "small" {
+		t.Errorf("expected small variable to be unchanged, got %q", encoded["id"])
+	}
+	if !strings.HasPrefix(encoded["document"], CompressedVariablePrefix) || len(encoded["document"]) >= 1000 {
+		t.Errorf("expected large variable to be compressed, got %q", encoded["document"])
+	}
+}
+
+func TestCompressingCodec_MarshalSmallDocument(t *testing.T) {
+	codec := NewCompressingCodec(JSONCodec, 100)
+
+	data, err := codec.Marshal(map[string]string{"foo": "bar"})
+	if err != nil {
+		t.Fatalf("codec.Marshal() = %v", err)
+	}
+
+	if string(data) != `{"foo":"bar"}` {
+		t.Errorf("expected small document to be unchanged, got %s", data)
+	}
+}
+
+func TestCompressingCodec_GetVariablesAs(t *testing.T) {
+	codec := NewCompressingCodec(JSONCodec, 100)
+	want := document{ID: "1", Content: strings.Repeat("a", 1000)}
+	data, err := codec.Marshal(map[string]interface{}{"document": want, "key": 2251799813685249})
+	if err != nil {
+		t.Fatalf("codec.Marshal() = %v", err)
+	}
+	job := NewJob(&pb.ActivatedJob{Variables: string(data)}, codec)
+
+	var got struct {
+		Document document
+		Key      json.Number
+	}
+	if err := job.GetVariablesAs(&got); err != nil {
+		t.Fatalf("job.GetVariablesAs() = %v", err)
+	}
+	if diff := cmp.Diff(want, got.Document); diff != "" {
+		t.Errorf("job.GetVariablesAs() differs (-want +got):\n%s", diff)
+	}
+
+	var withOptions map[string]interface{}
+	if err := job.GetVariablesAs(&withOptions, UseNumber()); err != nil {
+		t.Fatalf("job.GetVariablesAs(UseNumber()) = %v", err)
+	}
+	if withOptions["key"] != json.Number("2251799813685249") {
+		t.Errorf("expected key to be decoded as number, got %v", withOptions["key"])
+	}
+	if _, ok := withOptions["document"].(map[string]interface{}); !ok {
+		t.Errorf("expected document to be decompressed, got %v", withOptions["document"])
+	}
+}
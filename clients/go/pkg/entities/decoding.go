@@ -0,0 +1,76 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DecoderOption configures how the JSON representation of variables or custom
+// headers is decoded.
+type DecoderOption func(decoder *json.Decoder)
+
+// DisallowUnknownFields causes decoding to fail if the JSON object contains
+// keys which do not match any exported field of the target struct.
+func DisallowUnknownFields() DecoderOption {
+	return func(decoder *json.Decoder) {
+		decoder.DisallowUnknownFields()
+	}
+}
+
+// UseNumber causes numbers to be decoded as json.Number instead of float64
+// when the target is an interface{}, e.g. in a map[string]interface{}. This
+// preserves the precision of large numbers such as keys.
+func UseNumber() DecoderOption {
+	return func(decoder *json.Decoder) {
+		decoder.UseNumber()
+	}
+}
+
+func unmarshal(codec VariableCodec, data string, t interface{}, opts []DecoderOption) error {
+	if len(opts) == 0 {
+		if codec == nil {
+			codec = JSONCodec
+		}
+		return codec.Unmarshal([]byte(data), t)
+	}
+
+	if resolver, ok := codec.(VariablesResolver); ok {
+		resolved, err := resolver.ResolveVariables([]byte(data))
+		if err != nil {
+			return err
+		}
+		data = string(resolved)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(data))
+	for _, opt := range opts {
+		opt(decoder)
+	}
+
+	if err := decoder.Decode(t); err != nil {
+		return err
+	}
+
+	// decoder.More() would accept a trailing '}' or ']', so the rest of the data has to be empty
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("invalid data after top-level value in %q", data)
+	}
+
+	return nil
+}
@@ -16,6 +16,7 @@ package entities
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
 )
@@ -26,40 +27,82 @@ import (
 // on jobs.
 type Job struct {
 	pb.ActivatedJob
+	codec       VariableCodec
+	projections map[string]interface{}
 }
 
 // GetVariablesAsMap returns a map of a workflow instance's variables.
 //
 // See https://docs.zeebe.io/reference/variables.html for details on workflow
 // variables.
-func (j *Job) GetVariablesAsMap() (map[string]interface{}, error) {
+func (j *Job) GetVariablesAsMap(opts ...DecoderOption) (map[string]interface{}, error) {
 	var m map[string]interface{}
-	return m, j.GetVariablesAs(&m)
+	return m, j.GetVariablesAs(&m, opts...)
 }
 
 // GetVariablesAs unmarshals the JSON representation of a workflow instance's
-// variables into type t.
+// variables into type t. The decoding can be customized with options such as
+// DisallowUnknownFields and UseNumber, in which case encoding/json is used
+// instead of the codec of the client.
 //
 // See https://docs.zeebe.io/reference/variables.html for details on workflow
 // variables.
-func (j *Job) GetVariablesAs(t interface{}) error {
-	return json.Unmarshal([]byte(j.Variables), t)
+func (j *Job) GetVariablesAs(t interface{}, opts ...DecoderOption) error {
+	return unmarshal(j.codec, j.Variables, t, opts)
 }
 
 // GetCustomHeadersAsMap returns a map of a workflow's custom headers.
 //
 // Unlike variables, custom headers are specific to a workflow, as opposed to a
 // workflow instance.
-func (j *Job) GetCustomHeadersAsMap() (map[string]string, error) {
+func (j *Job) GetCustomHeadersAsMap(opts ...DecoderOption) (map[string]string, error) {
 	var m map[string]string
-	return m, j.GetCustomHeadersAs(&m)
+	return m, j.GetCustomHeadersAs(&m, opts...)
 }
 
 // GetCustomHeadersAs unmarshals the JSON representation of a workflow's
-// custom headers into type t.
+// custom headers into type t. The decoding can be customized with options such
+// as DisallowUnknownFields, in which case encoding/json is used instead of the
+// codec of the client.
 //
 // Unlike variables, custom headers are specific to a workflow, as opposed to a
 // workflow instance.
-func (j *Job) GetCustomHeadersAs(t interface{}) error {
-	return json.Unmarshal([]byte(j.CustomHeaders), t)
+func (j *Job) GetCustomHeadersAs(t interface{}, opts ...DecoderOption) error {
+	return unmarshal(j.codec, j.CustomHeaders, t, opts)
+}
+
+// GetProjection returns the value of the projection with the given name, which
+// was extracted from the variables when the job was activated, and whether it
+// exists. Numbers are json.Number values.
+//
+// See worker.JobWorkerBuilderStep3.VariableProjection for details on
+// projections.
+func (j *Job) GetProjection(name string) (interface{}, bool) {
+	value, ok := j.projections[name]
+	return value, ok
+}
+
+// GetProjectionAs unmarshals the value of the projection with the given name
+// into type t. It fails if the projection doesn't exist.
+func (j *Job) GetProjectionAs(name string, t interface{}) error {
+	value, ok := j.projections[name]
+	if !ok {
+		return fmt.Errorf("job %d has no projection '%s'", j.Key, name)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, t)
+}
+
+// SetProjection sets the value of the projection with the given name. It is
+// used by job workers, which evaluate the projections before the handler is
+// invoked.
+func (j *Job) SetProjection(name string, value interface{}) {
+	if j.projections == nil {
+		j.projections = make(map[string]interface{})
+	}
+	j.projections[name] = value
 }
@@ -0,0 +1,68 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"context"
+	"time"
+)
+
+// JobInfo identifies the job on whose behalf a context is used, e.g. to correlate the commands sent while handling
+// the job with the job.
+type JobInfo struct {
+	Key                 int64
+	WorkflowInstanceKey int64
+	BpmnProcessId       string
+	ElementId           string
+	// Deadline is when the broker may activate the job for another worker, or zero if the job has no deadline
+	Deadline time.Time
+}
+
+type jobInfoKey struct{}
+
+// NewJobContext derives a context from the parent which carries the JobInfo of the job and is done when the job
+// deadline passes. The cancel function must be called once the job is handled.
+func NewJobContext(parent context.Context, job *Job) (context.Context, context.CancelFunc) {
+	info := JobInfo{
+		Key:                 job.Key,
+		WorkflowInstanceKey: job.WorkflowInstanceKey,
+		BpmnProcessId:       job.BpmnProcessId,
+		ElementId:           job.ElementId,
+	}
+	if job.Deadline > 0 {
+		info.Deadline = time.Unix(0, job.Deadline*int64(time.Millisecond))
+	}
+
+	ctx := context.WithValue(parent, jobInfoKey{}, info)
+	if info.Deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, info.Deadline)
+}
+
+// JobInfoFromContext returns the JobInfo of the job whose context this is, if any.
+func JobInfoFromContext(ctx context.Context) (JobInfo, bool) {
+	info, ok := ctx.Value(jobInfoKey{}).(JobInfo)
+	return info, ok
+}
+
+// Context returns a context which carries the JobInfo of the job and is done when the job deadline passes. Commands
+// sent with it, or a context derived from it, fail once the deadline passed and send the job and workflow instance
+// key to the gateway as metadata. Its resources are released when the deadline passes; use NewJobContext to release
+// them earlier.
+func (j *Job) Context() context.Context {
+	ctx, _ := NewJobContext(context.Background(), j)
+	return ctx
+}
@@ -0,0 +1,72 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func TestJob_Context(t *testing.T) {
+	deadline := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+	job := Job{ActivatedJob: pb.ActivatedJob{
+		Key:                 123,
+		WorkflowInstanceKey: 456,
+		BpmnProcessId:       "order-process",
+		ElementId:           "ship",
+		Deadline:            deadline.UnixNano() / int64(time.Millisecond),
+	}}
+
+	ctx := job.Context()
+
+	got, ok := JobInfoFromContext(ctx)
+	if !ok {
+		t.Fatal("JobInfoFromContext(job.Context()) found no job")
+	}
+	want := JobInfo{Key: 123, WorkflowInstanceKey: 456, BpmnProcessId: "order-process", ElementId: "ship", Deadline: deadline}
+	if !got.Deadline.Equal(want.Deadline) {
+		t.Errorf("JobInfoFromContext(job.Context()).Deadline = %v, want %v", got.Deadline, want.Deadline)
+	}
+	got.Deadline = want.Deadline
+	if got != want {
+		t.Errorf("JobInfoFromContext(job.Context()) = %+v, want %+v", got, want)
+	}
+
+	if ctxDeadline, ok := ctx.Deadline(); !ok || !ctxDeadline.Equal(deadline) {
+		t.Errorf("job.Context().Deadline() = %v, %v, want %v", ctxDeadline, ok, deadline)
+	}
+}
+
+func TestNewJobContextWithoutDeadline(t *testing.T) {
+	ctx, cancel := NewJobContext(context.Background(), &Job{ActivatedJob: pb.ActivatedJob{Key: 123}})
+
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expected context of job without deadline to have no deadline")
+	}
+
+	cancel()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("ctx.Err() after cancel = %v, want %v", ctx.Err(), context.Canceled)
+	}
+}
+
+func TestJobInfoFromContextWithoutJob(t *testing.T) {
+	if _, ok := JobInfoFromContext(context.Background()); ok {
+		t.Error("expected context without job to have no JobInfo")
+	}
+}
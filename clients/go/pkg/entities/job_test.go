@@ -15,6 +15,8 @@
 package entities
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -27,7 +29,7 @@ type testType struct {
 }
 
 var (
-	job = Job{pb.ActivatedJob{
+	job = Job{ActivatedJob: pb.ActivatedJob{
 		CustomHeaders: `{"foo": "bar", "hello": "world"}`,
 		Variables:     `{"foo": "bar", "hello": "world"}`,
 	}}
@@ -92,3 +94,100 @@ func TestJob_GetCustomHeadersAs(t *testing.T) {
 		t.Errorf("job.GetCustomHeadersAs(%T) differs (-want +got):\n%s", got, diff)
 	}
 }
+
+func TestJob_GetVariablesAsWithDisallowUnknownFields(t *testing.T) {
+	job := Job{ActivatedJob: pb.ActivatedJob{
+		Variables: `{"foo": "bar", "hello": "world", "unknown": 1}`,
+	}}
+
+	var got testType
+	if err := job.GetVariablesAs(&got); err != nil {
+		t.Fatalf("job.GetVariablesAs(&%T) = %v", got, err)
+	}
+
+	if err := job.GetVariablesAs(&got, DisallowUnknownFields()); err == nil {
+		t.Errorf("job.GetVariablesAs(&%T, DisallowUnknownFields()) expected to fail on unknown field", got)
+	}
+}
+
+func TestJob_GetVariablesAsMapWithUseNumber(t *testing.T) {
+	job := Job{ActivatedJob: pb.ActivatedJob{
+		Variables: `{"key": 2251799813685249}`,
+	}}
+
+	got, err := job.GetVariablesAsMap(UseNumber())
+	if err != nil {
+		t.Fatalf("job.GetVariablesAsMap(UseNumber()) = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"key": json.Number("2251799813685249"),
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("job.GetVariablesAsMap(UseNumber()) differs (-want +got):\n%s", diff)
+	}
+}
+
+func TestJob_GetCustomHeadersAsWithTrailingData(t *testing.T) {
+	job := Job{ActivatedJob: pb.ActivatedJob{
+		CustomHeaders: `{"foo": "bar"} {"hello": "world"}`,
+	}}
+
+	var got testType
+	if err := job.GetCustomHeadersAs(&got, DisallowUnknownFields()); err == nil {
+		t.Errorf("job.GetCustomHeadersAs(&%T, DisallowUnknownFields()) expected to fail on trailing data", got)
+	}
+}
+
+func TestJob_GetVariablesAsWithTrailingBrace(t *testing.T) {
+	job := Job{ActivatedJob: pb.ActivatedJob{
+		Variables: `{"foo": "bar"}}`,
+	}}
+
+	var got testType
+	if err := job.GetVariablesAs(&got, UseNumber()); err == nil {
+		t.Errorf("job.GetVariablesAs(&%T, UseNumber()) expected to fail on trailing '}'", got)
+	}
+}
+
+type upperCaseKeysCodec struct{}
+
+func (upperCaseKeysCodec) Marshal(v interface{}) ([]byte, error) {
+	return JSONCodec.Marshal(v)
+}
+
+func (upperCaseKeysCodec) Unmarshal(data []byte, v interface{}) error {
+	return JSONCodec.Unmarshal([]byte(strings.ToUpper(string(data))), v)
+}
+
+func TestJob_GetVariablesAsMapWithCodec(t *testing.T) {
+	job := NewJob(&pb.ActivatedJob{Variables: `{"foo": "bar"}`}, upperCaseKeysCodec{})
+
+	got, err := job.GetVariablesAsMap()
+	if err != nil {
+		t.Fatalf("job.GetVariablesAsMap() = %v", err)
+	}
+
+	want := map[string]interface{}{"FOO": "BAR"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("job.GetVariablesAsMap() differs (-want +got):\n%s", diff)
+	}
+}
+
+func TestJob_GetProjectionAs(t *testing.T) {
+	job := Job{ActivatedJob: pb.ActivatedJob{Key: 123}}
+	job.SetProjection("skus", []interface{}{"a", "b"})
+
+	var got []string
+	if err := job.GetProjectionAs("skus", &got); err != nil {
+		t.Fatalf("job.GetProjectionAs(skus) = %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, got); diff != "" {
+		t.Errorf("job.GetProjectionAs(skus) differs (-want +got):\n%s", diff)
+	}
+
+	if err := job.GetProjectionAs("missing", &got); err == nil {
+		t.Error("expected job.GetProjectionAs(missing) to fail")
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ISO8601Millis is the layout of times serialized by TimeAsISO8601, e.g.
+// '2020-06-01T12:30:00.000Z', which FEEL parses as date and time.
+const ISO8601Millis = "2006-01-02T15:04:05.000Z07:00"
+
+// VariableMarshaler converts a value of a registered type into the value
+// which is serialized instead, e.g. a time.Time into its epoch milliseconds.
+type VariableMarshaler func(value interface{}) (interface{}, error)
+
+// TimeAsISO8601 serializes a time.Time in UTC with millisecond precision,
+// see ISO8601Millis.
+func TimeAsISO8601(value interface{}) (interface{}, error) {
+	t, ok := value.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("expected time.Time, got %T", value)
+	}
+	return t.UTC().Format(ISO8601Millis), nil
+}
+
+// TimeAsEpochMillis serializes a time.Time as the number of milliseconds since
+// the Unix epoch.
+func TimeAsEpochMillis(value interface{}) (interface{}, error) {
+	t, ok := value.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("expected time.Time, got %T", value)
+	}
+	return t.UnixNano() / int64(time.Millisecond), nil
+}
+
+// StringAsNumber serializes a value whose string representation is a number,
+// e.g. a decimal type, as JSON number instead of string, so expressions can
+// compute with it.
+func StringAsNumber(value interface{}) (interface{}, error) {
+	number := json.Number(fmt.Sprint(value))
+	if _, err := strconv.ParseFloat(number.String(), 64); err != nil {
+		return nil, fmt.Errorf("expected %T to be a number, got '%s'", value, number)
+	}
+	return number, nil
+}
+
+// MarshalingCodec is a VariableCodec which converts the values of registered
+// types before they are serialized by the wrapped codec, so all services
+// serialize e.g. times in the same format. Unmarshal is passed to the wrapped
+// codec unchanged.
+type MarshalingCodec struct {
+	codec VariableCodec
+
+	lock       sync.RWMutex
+	marshalers map[reflect.Type]VariableMarshaler
+	// needs caches whether a type needs conversion, see needsConversion
+	needs *sync.Map
+}
+
+// NewMarshalingCodec creates a MarshalingCodec which serializes the converted
+// values with the codec.
+func NewMarshalingCodec(codec VariableCodec) *MarshalingCodec {
+	return &MarshalingCodec{
+		codec:      codec,
+		marshalers: make(map[reflect.Type]VariableMarshaler),
+		needs:      &sync.Map{},
+	}
+}
+
+// RegisterVariableMarshaler converts all values of the type of example, e.g.
+// time.Time{}, with the marshaler, wherever they appear in the variables: in
+// structs, maps, slices and behind pointers. Register pointer types with a
+// typed nil, e.g. (*Money)(nil). Structs which contain a value of a registered
+// type are serialized like encoding/json does, except that the fields of
+// unexported embedded structs are omitted.
+func (c *MarshalingCodec) RegisterVariableMarshaler(example interface{}, marshaler VariableMarshaler) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.marshalers[reflect.TypeOf(example)] = marshaler
+	c.needs = &sync.Map{}
+}
+
+func (c *MarshalingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.lock.RLock()
+	converted, err := c.convert(reflect.ValueOf(v))
+	c.lock.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.codec.Marshal(converted)
+}
+
+func (c *MarshalingCodec) Unmarshal(data []byte, v interface{}) error {
+	return c.codec.Unmarshal(data, v)
+}
+
+// ResolveVariables passes the variables to the wrapped codec, if it resolves
+// variables.
+func (c *MarshalingCodec) ResolveVariables(data []byte) ([]byte, error) {
+	if resolver, ok := c.codec.(VariablesResolver); ok {
+		return resolver.ResolveVariables(data)
+	}
+	return data, nil
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// convert returns the value with all values of registered types converted. It
+// is called with the read lock held.
+func (c *MarshalingCodec) convert(value reflect.Value) (interface{}, error) {
+	if !value.IsValid() {
+		return nil, nil
+	}
+
+	if marshaler, ok := c.marshalers[value.Type()]; ok {
+		return marshaler(value.Interface())
+	}
+	if !c.needsConversion(value.Type()) {
+		return value.Interface(), nil
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil, nil
+		}
+		return c.convert(value.Elem())
+	case reflect.Struct:
+		return c.convertStruct(value)
+	case reflect.Map:
+		if value.IsNil() {
+			return nil, nil
+		}
+		converted := make(map[string]interface{}, value.Len())
+		iterator := value.MapRange()
+		for iterator.Next() {
+			key, err := mapKey(iterator.Key())
+			if err != nil {
+				return nil, err
+			}
+			if converted[key], err = c.convert(iterator.Value()); err != nil {
+				return nil, err
+			}
+		}
+		return converted, nil
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil, nil
+		}
+		converted := make([]interface{}, value.Len())
+		for i := range converted {
+			var err error
+			if converted[i], err = c.convert(value.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		return converted, nil
+	default:
+		return value.Interface(), nil
+	}
+}
+
+// convertStruct converts the exported fields of the struct into a map, named
+// and omitted by their json tags like encoding/json does.
+func (c *MarshalingCodec) convertStruct(value reflect.Value) (map[string]interface{}, error) {
+	converted := make(map[string]interface{})
+	embedded := make(map[string]interface{})
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, omitempty, skip := jsonField(field)
+		if skip || field.PkgPath != "" {
+			// values of unexported fields can't be read with reflection
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		if field.Anonymous && name == "" {
+			inner := fieldValue
+			if inner.Kind() == reflect.Ptr {
+				if inner.IsNil() {
+					continue
+				}
+				inner = inner.Elem()
+			}
+			if inner.Kind() == reflect.Struct && c.marshalers[inner.Type()] == nil {
+				fields, err := c.convertStruct(inner)
+				if err != nil {
+					return nil, err
+				}
+				for fieldName, fieldValue := range fields {
+					embedded[fieldName] = fieldValue
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if omitempty && isEmptyValue(fieldValue) {
+			continue
+		}
+
+		var err error
+		if converted[name], err = c.convert(fieldValue); err != nil {
+			return nil, fmt.Errorf("failed to convert field '%s': %w", field.Name, err)
+		}
+	}
+
+	// fields of the struct take precedence over the fields of embedded structs
+	for name, value := range embedded {
+		if _, ok := converted[name]; !ok {
+			converted[name] = value
+		}
+	}
+	return converted, nil
+}
+
+// needsConversion returns whether values of the type may contain a value of a
+// registered type. Types which serialize themselves are not converted. It is
+// called with the read lock held.
+func (c *MarshalingCodec) needsConversion(t reflect.Type) bool {
+	if needs, ok := c.needs.Load(t); ok {
+		return needs.(bool)
+	}
+
+	// only the result for t itself is cached, as the results for the types it
+	// contains are incomplete if they refer back to t
+	needs := c.computeNeeds(t, make(map[reflect.Type]bool))
+	c.needs.Store(t, needs)
+	return needs
+}
+
+func (c *MarshalingCodec) computeNeeds(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if _, ok := c.marshalers[t]; ok {
+		return true
+	}
+	if visiting[t] {
+		// a recursive type needs conversion if any other part of it does
+		return false
+	}
+	visiting[t] = true
+
+	switch {
+	case t.Kind() == reflect.Interface:
+		return len(c.marshalers) > 0
+	case t.Kind() == reflect.Ptr:
+		return c.computeNeeds(t.Elem(), visiting)
+	case t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType):
+		return false
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map:
+		return c.computeNeeds(t.Elem(), visiting)
+	case t.Kind() == reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath == "" && c.computeNeeds(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jsonField(field reflect.StructField) (string, bool, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	omitempty := false
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitempty = true
+		}
+	}
+	return parts[0], omitempty, false
+}
+
+func mapKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if marshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	default:
+		return "", fmt.Errorf("unsupported map key type %s", key.Type())
+	}
+}
+
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool:
+		return !value.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return value.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return value.IsNil()
+	}
+	return false
+}
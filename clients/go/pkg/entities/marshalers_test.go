@@ -0,0 +1,114 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type money struct {
+	amount string
+}
+
+func (m money) String() string {
+	return m.amount
+}
+
+type shipment struct {
+	ID        string     `json:"id"`
+	ShippedAt time.Time  `json:"shippedAt"`
+	Delivered *time.Time `json:"delivered,omitempty"`
+	Price     money      `json:"price"`
+	Ignored   string     `json:"-"`
+	Audit
+	internal string
+}
+
+type Audit struct {
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type node struct {
+	At       time.Time `json:"at"`
+	Children []node    `json:"children,omitempty"`
+}
+
+func TestMarshalingCodecConvertsRegisteredTypes(t *testing.T) {
+	codec := NewMarshalingCodec(JSONCodec)
+	codec.RegisterVariableMarshaler(time.Time{}, TimeAsEpochMillis)
+	codec.RegisterVariableMarshaler(money{}, StringAsNumber)
+
+	shippedAt := time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)
+	data, err := codec.Marshal(map[string]interface{}{
+		"shipment": shipment{ID: "s-1", ShippedAt: shippedAt, Price: money{"12.50"}, Ignored: "x", Audit: Audit{CreatedAt: shippedAt}, internal: "y"},
+		"times":    []interface{}{shippedAt, &shippedAt, nil},
+	})
+	if err != nil {
+		t.Fatalf("codec.Marshal() = %v", err)
+	}
+
+	want := `{"shipment":{"createdAt":1591014600000,"id":"s-1","price":12.50,"shippedAt":1591014600000},"times":[1591014600000,1591014600000,null]}`
+	if string(data) != want {
+		t.Errorf("codec.Marshal() = %s, want %s", data, want)
+	}
+}
+
+func TestMarshalingCodecConvertsRecursiveTypes(t *testing.T) {
+	codec := NewMarshalingCodec(JSONCodec)
+	codec.RegisterVariableMarshaler(time.Time{}, TimeAsISO8601)
+
+	at := time.Date(2020, 6, 1, 14, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	data, err := codec.Marshal(node{At: at, Children: []node{{At: at}}})
+	if err != nil {
+		t.Fatalf("codec.Marshal() = %v", err)
+	}
+
+	want := `{"at":"2020-06-01T12:30:00.000Z","children":[{"at":"2020-06-01T12:30:00.000Z"}]}`
+	if string(data) != want {
+		t.Errorf("codec.Marshal() = %s, want %s", data, want)
+	}
+}
+
+func TestMarshalingCodecLeavesOtherTypesUnchanged(t *testing.T) {
+	codec := NewMarshalingCodec(JSONCodec)
+	codec.RegisterVariableMarshaler(money{}, StringAsNumber)
+
+	type order struct {
+		ID      string    `json:"orderId"`
+		Created time.Time `json:"created"`
+	}
+	data, err := codec.Marshal(order{ID: "o-1", Created: time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("codec.Marshal() = %v", err)
+	}
+
+	want := `{"orderId":"o-1","created":"2020-06-01T12:30:00Z"}`
+	if string(data) != want {
+		t.Errorf("codec.Marshal() = %s, want %s", data, want)
+	}
+}
+
+func TestMarshalingCodecFailsIfMarshalerFails(t *testing.T) {
+	codec := NewMarshalingCodec(JSONCodec)
+	codec.RegisterVariableMarshaler(money{}, func(interface{}) (interface{}, error) {
+		return nil, errors.New("invalid amount")
+	})
+
+	if _, err := codec.Marshal(map[string]interface{}{"price": money{"x"}}); err == nil {
+		t.Error("expected codec.Marshal() to fail")
+	}
+}
@@ -0,0 +1,358 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feel
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type builtin struct {
+	minArgs int
+	// maxArgs is negative if the function takes any number of arguments
+	maxArgs int
+	call    func(args []interface{}) interface{}
+}
+
+var builtins map[string]builtin
+
+func init() {
+	builtins = map[string]builtin{
+		"not": {1, 1, func(args []interface{}) interface{} {
+			if value, ok := args[0].(bool); ok {
+				return !value
+			}
+			return nil
+		}},
+		"string": {1, 1, func(args []interface{}) interface{} {
+			if args[0] == nil {
+				return nil
+			}
+			return toString(args[0])
+		}},
+		"number": {1, 1, func(args []interface{}) interface{} {
+			if value, ok := args[0].(string); ok {
+				if number, ok := new(big.Rat).SetString(value); ok {
+					return number
+				}
+			}
+			return nil
+		}},
+		"string length": {1, 1, stringFunction(func(s string) interface{} {
+			return big.NewRat(int64(utf8.RuneCountInString(s)), 1)
+		})},
+		"upper case": {1, 1, stringFunction(func(s string) interface{} { return strings.ToUpper(s) })},
+		"lower case": {1, 1, stringFunction(func(s string) interface{} { return strings.ToLower(s) })},
+		"substring":  {2, 3, substring},
+		"contains": {2, 2, stringsFunction(func(s, sub string) interface{} {
+			return strings.Contains(s, sub)
+		})},
+		"starts with": {2, 2, stringsFunction(func(s, prefix string) interface{} {
+			return strings.HasPrefix(s, prefix)
+		})},
+		"ends with": {2, 2, stringsFunction(func(s, suffix string) interface{} {
+			return strings.HasSuffix(s, suffix)
+		})},
+		"matches": {2, 2, stringsFunction(func(s, pattern string) interface{} {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil
+			}
+			return re.MatchString(s)
+		})},
+		"count": {1, 1, listFunction(func(items []interface{}) interface{} {
+			return big.NewRat(int64(len(items)), 1)
+		})},
+		"sum": {1, -1, numbersFunction(sum)},
+		"mean": {1, -1, numbersFunction(func(numbers []*big.Rat) interface{} {
+			if len(numbers) == 0 {
+				return nil
+			}
+			return new(big.Rat).Quo(sum(numbers).(*big.Rat), big.NewRat(int64(len(numbers)), 1))
+		})},
+		"min": {1, -1, numbersFunction(func(numbers []*big.Rat) interface{} { return extreme(numbers, -1) })},
+		"max": {1, -1, numbersFunction(func(numbers []*big.Rat) interface{} { return extreme(numbers, 1) })},
+		"list contains": {2, 2, func(args []interface{}) interface{} {
+			if items, ok := args[0].([]interface{}); ok {
+				return contains(items, args[1])
+			}
+			return nil
+		}},
+		"append": {1, -1, func(args []interface{}) interface{} {
+			items, ok := args[0].([]interface{})
+			if !ok {
+				return nil
+			}
+			return append(append([]interface{}{}, items...), args[1:]...)
+		}},
+		"concatenate": {1, -1, func(args []interface{}) interface{} {
+			result := []interface{}{}
+			for _, arg := range args {
+				items, ok := arg.([]interface{})
+				if !ok {
+					return nil
+				}
+				result = append(result, items...)
+			}
+			return result
+		}},
+		"reverse": {1, 1, listFunction(func(items []interface{}) interface{} {
+			reversed := make([]interface{}, len(items))
+			for i, item := range items {
+				reversed[len(items)-1-i] = item
+			}
+			return reversed
+		})},
+		"distinct values": {1, 1, listFunction(func(items []interface{}) interface{} {
+			distinct := []interface{}{}
+			for _, item := range items {
+				if !contains(distinct, item) {
+					distinct = append(distinct, item)
+				}
+			}
+			return distinct
+		})},
+		"flatten": {1, 1, listFunction(func(items []interface{}) interface{} { return flatten(items) })},
+		"index of": {2, 2, func(args []interface{}) interface{} {
+			items, ok := args[0].([]interface{})
+			if !ok {
+				return nil
+			}
+			indices := []interface{}{}
+			for i, item := range items {
+				if equal(item, args[1]) == true {
+					indices = append(indices, big.NewRat(int64(i+1), 1))
+				}
+			}
+			return indices
+		}},
+		"sort": {1, 1, listFunction(func(items []interface{}) interface{} {
+			sorted := append([]interface{}{}, items...)
+			for i := 1; i < len(sorted); i++ {
+				if _, ok := compare(sorted[0], sorted[i]); !ok {
+					return nil
+				}
+			}
+			sort.SliceStable(sorted, func(i, j int) bool {
+				order, _ := compare(sorted[i], sorted[j])
+				return order < 0
+			})
+			return sorted
+		})},
+		"abs": {1, 1, numberFunction(func(n *big.Rat) interface{} { return new(big.Rat).Abs(n) })},
+		"floor": {1, 1, numberFunction(func(n *big.Rat) interface{} {
+			return new(big.Rat).SetInt(floor(n))
+		})},
+		"ceiling": {1, 1, numberFunction(func(n *big.Rat) interface{} {
+			return new(big.Rat).Neg(new(big.Rat).SetInt(floor(new(big.Rat).Neg(n))))
+		})},
+		"decimal": {2, 2, decimal},
+	}
+}
+
+func toString(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case *big.Rat:
+		return formatNumber(value)
+	case nil:
+		return "null"
+	case []interface{}:
+		parts := make([]string, len(value))
+		for i, item := range value {
+			parts[i] = toString(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			parts[i] = key + ":" + toString(value[key])
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// formatNumber formats the number as integer or decimal; numbers with infinite decimals are rounded to 10 digits
+func formatNumber(n *big.Rat) string {
+	if n.IsInt() {
+		return n.Num().String()
+	}
+	s := n.FloatString(10)
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
+
+func stringFunction(f func(string) interface{}) func([]interface{}) interface{} {
+	return func(args []interface{}) interface{} {
+		if s, ok := args[0].(string); ok {
+			return f(s)
+		}
+		return nil
+	}
+}
+
+func stringsFunction(f func(string, string) interface{}) func([]interface{}) interface{} {
+	return func(args []interface{}) interface{} {
+		a, okA := args[0].(string)
+		b, okB := args[1].(string)
+		if okA && okB {
+			return f(a, b)
+		}
+		return nil
+	}
+}
+
+func listFunction(f func([]interface{}) interface{}) func([]interface{}) interface{} {
+	return func(args []interface{}) interface{} {
+		if items, ok := args[0].([]interface{}); ok {
+			return f(items)
+		}
+		return nil
+	}
+}
+
+func numberFunction(f func(*big.Rat) interface{}) func([]interface{}) interface{} {
+	return func(args []interface{}) interface{} {
+		if n, ok := args[0].(*big.Rat); ok {
+			return f(n)
+		}
+		return nil
+	}
+}
+
+// numbersFunction calls the function with the numbers of a list, or the numbers which are passed as arguments
+func numbersFunction(f func([]*big.Rat) interface{}) func([]interface{}) interface{} {
+	return func(args []interface{}) interface{} {
+		values := args
+		if len(args) == 1 {
+			if items, ok := args[0].([]interface{}); ok {
+				values = items
+			}
+		}
+
+		numbers := make([]*big.Rat, len(values))
+		for i, value := range values {
+			n, ok := value.(*big.Rat)
+			if !ok {
+				return nil
+			}
+			numbers[i] = n
+		}
+		return f(numbers)
+	}
+}
+
+func sum(numbers []*big.Rat) interface{} {
+	total := new(big.Rat)
+	for _, n := range numbers {
+		total.Add(total, n)
+	}
+	return total
+}
+
+func extreme(numbers []*big.Rat, sign int) interface{} {
+	if len(numbers) == 0 {
+		return nil
+	}
+	result := numbers[0]
+	for _, n := range numbers[1:] {
+		if n.Cmp(result) == sign {
+			result = n
+		}
+	}
+	return result
+}
+
+func flatten(items []interface{}) []interface{} {
+	flat := []interface{}{}
+	for _, item := range items {
+		if nested, ok := item.([]interface{}); ok {
+			flat = append(flat, flatten(nested)...)
+		} else {
+			flat = append(flat, item)
+		}
+	}
+	return flat
+}
+
+func floor(n *big.Rat) *big.Int {
+	// the Euclidean division of big.Int rounds towards negative infinity, as the denominator is positive
+	return new(big.Int).Div(n.Num(), n.Denom())
+}
+
+func substring(args []interface{}) interface{} {
+	s, ok := args[0].(string)
+	start, okStart := args[1].(*big.Rat)
+	if !ok || !okStart || !start.IsInt() {
+		return nil
+	}
+
+	runes := []rune(s)
+	from := int(start.Num().Int64())
+	if from < 0 {
+		from += len(runes) + 1
+	}
+	if from < 1 {
+		from = 1
+	}
+	if from > len(runes) {
+		return ""
+	}
+
+	to := len(runes)
+	if len(args) == 3 {
+		length, ok := args[2].(*big.Rat)
+		if !ok || !length.IsInt() || length.Sign() < 0 {
+			return nil
+		}
+		if end := from - 1 + int(length.Num().Int64()); end < to {
+			to = end
+		}
+	}
+	return string(runes[from-1 : to])
+}
+
+// decimal rounds the number to the scale with half even rounding
+func decimal(args []interface{}) interface{} {
+	n, ok := args[0].(*big.Rat)
+	scale, okScale := args[1].(*big.Rat)
+	if !ok || !okScale || !scale.IsInt() {
+		return nil
+	}
+
+	factor := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), new(big.Int).Abs(scale.Num()), nil))
+	if scale.Sign() < 0 {
+		factor.Inv(factor)
+	}
+
+	scaled := new(big.Rat).Mul(n, factor)
+	rounded := floor(scaled)
+	remainder := new(big.Rat).Sub(scaled, new(big.Rat).SetInt(rounded))
+	if c := remainder.Cmp(big.NewRat(1, 2)); c > 0 || (c == 0 && rounded.Bit(0) == 1) {
+		rounded.Add(rounded, big.NewInt(1))
+	}
+	return new(big.Rat).Quo(new(big.Rat).SetInt(rounded), factor)
+}
@@ -0,0 +1,548 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feel
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// rangeValue is the value of a range like [1..10], which can be tested by in or iterated by for
+type rangeValue struct {
+	low, high             interface{}
+	lowClosed, highClosed bool
+}
+
+type scope struct {
+	variables map[string]interface{}
+	parent    *scope
+}
+
+func (s *scope) lookup(name string) (interface{}, bool) {
+	for current := s; current != nil; current = current.parent {
+		if value, ok := current.variables[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func (s *scope) with(variables map[string]interface{}) *scope {
+	return &scope{variables: variables, parent: s}
+}
+
+func (n *literal) eval(*scope) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *variable) eval(s *scope) (interface{}, error) {
+	value, ok := s.lookup(n.name)
+	if !ok {
+		return nil, fmt.Errorf("no variable found for name '%s'", n.name)
+	}
+	return value, nil
+}
+
+func (n *path) eval(s *scope) (interface{}, error) {
+	target, err := n.target.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	return member(target, n.name), nil
+}
+
+// member returns the entry of a context, or the entries of all contexts of a list, or null if there is none
+func member(value interface{}, name string) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return value[name]
+	case []interface{}:
+		members := make([]interface{}, len(value))
+		for i, item := range value {
+			members[i] = member(item, name)
+		}
+		return members
+	default:
+		return nil
+	}
+}
+
+func (n *filter) eval(s *scope) (interface{}, error) {
+	target, err := n.target.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := target.([]interface{})
+	if !ok {
+		items = []interface{}{target}
+	}
+
+	// a numeric filter selects the item at the index, starting at 1 or from the end if it is negative
+	if index, err := n.filter.eval(s); err == nil {
+		if index, ok := index.(*big.Rat); ok {
+			return itemAt(items, index), nil
+		}
+	}
+
+	var filtered []interface{}
+	for _, item := range items {
+		variables := map[string]interface{}{"item": item}
+		if entries, ok := item.(map[string]interface{}); ok {
+			for name, value := range entries {
+				variables[name] = value
+			}
+		}
+
+		matches, err := n.filter.eval(s.with(variables))
+		if err != nil {
+			return nil, err
+		}
+		if matches == true {
+			filtered = append(filtered, item)
+		}
+	}
+	if filtered == nil {
+		filtered = []interface{}{}
+	}
+	return filtered, nil
+}
+
+func itemAt(items []interface{}, index *big.Rat) interface{} {
+	if !index.IsInt() || !index.Num().IsInt64() {
+		return nil
+	}
+
+	i := int(index.Num().Int64())
+	if i < 0 {
+		i += len(items) + 1
+	}
+	if i < 1 || i > len(items) {
+		return nil
+	}
+	return items[i-1]
+}
+
+func (n *call) eval(s *scope) (interface{}, error) {
+	function, ok := builtins[n.function]
+	if !ok {
+		return nil, fmt.Errorf("no function found for name '%s'", n.function)
+	}
+
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		var err error
+		if args[i], err = arg.eval(s); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) < function.minArgs || (function.maxArgs >= 0 && len(args) > function.maxArgs) {
+		return nil, fmt.Errorf("invalid number of arguments for function '%s', got %d", n.function, len(args))
+	}
+	return function.call(args), nil
+}
+
+func (n *negation) eval(s *scope) (interface{}, error) {
+	operand, err := n.operand.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if number, ok := operand.(*big.Rat); ok {
+		return new(big.Rat).Neg(number), nil
+	}
+	return nil, nil
+}
+
+func (n *binary) eval(s *scope) (interface{}, error) {
+	left, err := n.left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// and and or are evaluated lazily and with three-valued logic: null is neither true nor false
+	switch n.operator {
+	case "and":
+		if left == false {
+			return false, nil
+		}
+	case "or":
+		if left == true {
+			return true, nil
+		}
+	}
+
+	right, err := n.right.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.operator {
+	case "and":
+		if right == false {
+			return false, nil
+		}
+		if left == true && right == true {
+			return true, nil
+		}
+		return nil, nil
+	case "or":
+		if right == true {
+			return true, nil
+		}
+		if left == false && right == false {
+			return false, nil
+		}
+		return nil, nil
+	case "=":
+		return equal(left, right), nil
+	case "!=":
+		if eq := equal(left, right); eq != nil {
+			return !eq.(bool), nil
+		}
+		return nil, nil
+	case "<", "<=", ">", ">=":
+		return compareWith(n.operator, left, right), nil
+	default:
+		return arithmetic(n.operator, left, right), nil
+	}
+}
+
+func (n *between) eval(s *scope) (interface{}, error) {
+	values, err := evalAll(s, n.value, n.low, n.high)
+	if err != nil {
+		return nil, err
+	}
+	return and(compareWith(">=", values[0], values[1]), compareWith("<=", values[0], values[2])), nil
+}
+
+func (n *in) eval(s *scope) (interface{}, error) {
+	value, err := n.value.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, test := range n.tests {
+		candidate, err := test.eval(s)
+		if err != nil {
+			return nil, err
+		}
+
+		var matches interface{}
+		switch candidate := candidate.(type) {
+		case rangeValue:
+			matches = candidate.contains(value)
+		case []interface{}:
+			matches = contains(candidate, value)
+		default:
+			matches = equal(value, candidate)
+		}
+		if matches == true {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r rangeValue) contains(value interface{}) interface{} {
+	low, high := ">", "<"
+	if r.lowClosed {
+		low = ">="
+	}
+	if r.highClosed {
+		high = "<="
+	}
+	return and(compareWith(low, value, r.low), compareWith(high, value, r.high))
+}
+
+func (n *rangeNode) eval(s *scope) (interface{}, error) {
+	values, err := evalAll(s, n.low, n.high)
+	if err != nil {
+		return nil, err
+	}
+	return rangeValue{low: values[0], high: values[1], lowClosed: n.lowClosed, highClosed: n.highClosed}, nil
+}
+
+func (n *list) eval(s *scope) (interface{}, error) {
+	return evalAll(s, n.items...)
+}
+
+func (n *context) eval(s *scope) (interface{}, error) {
+	entries := make(map[string]interface{}, len(n.keys))
+	// entries can refer to the previous entries of the context
+	inner := s.with(entries)
+	for i, key := range n.keys {
+		value, err := n.values[i].eval(inner)
+		if err != nil {
+			return nil, err
+		}
+		entries[key] = value
+	}
+	return entries, nil
+}
+
+func (n *conditional) eval(s *scope) (interface{}, error) {
+	condition, err := n.condition.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if condition == true {
+		return n.then.eval(s)
+	}
+	return n.otherwise.eval(s)
+}
+
+func (n *forNode) eval(s *scope) (interface{}, error) {
+	results := []interface{}{}
+	err := n.iterate(s, func(inner *scope) (bool, error) {
+		result, err := n.body.eval(inner)
+		results = append(results, result)
+		return true, err
+	})
+	return results, err
+}
+
+func (n *quantified) eval(s *scope) (interface{}, error) {
+	result := n.every
+	err := n.iterate(s, func(inner *scope) (bool, error) {
+		satisfied, err := n.condition.eval(inner)
+		if err != nil {
+			return false, err
+		}
+		if (satisfied == true) != n.every {
+			result = !n.every
+			return false, nil
+		}
+		return true, nil
+	})
+	return result, err
+}
+
+// iterate calls the function for the combinations of the values of the iteration variables, until it returns false
+func (it *iteration) iterate(s *scope, f func(*scope) (bool, error)) error {
+	_, err := it.iterateFrom(0, s, f)
+	return err
+}
+
+func (it *iteration) iterateFrom(index int, s *scope, f func(*scope) (bool, error)) (bool, error) {
+	if index == len(it.names) {
+		return f(s)
+	}
+
+	values, err := it.lists[index].eval(s)
+	if err != nil {
+		return false, err
+	}
+	items, err := iterable(values)
+	if err != nil {
+		return false, err
+	}
+
+	for _, item := range items {
+		next, err := it.iterateFrom(index+1, s.with(map[string]interface{}{it.names[index]: item}), f)
+		if err != nil || !next {
+			return next, err
+		}
+	}
+	return true, nil
+}
+
+func iterable(value interface{}) ([]interface{}, error) {
+	switch value := value.(type) {
+	case []interface{}:
+		return value, nil
+	case rangeValue:
+		low, okLow := value.low.(*big.Rat)
+		high, okHigh := value.high.(*big.Rat)
+		if !okLow || !okHigh || !low.IsInt() || !high.IsInt() {
+			return nil, fmt.Errorf("expected range to iterate over integers")
+		}
+
+		step := big.NewRat(1, 1)
+		if low.Cmp(high) > 0 {
+			step.Neg(step)
+		}
+		var items []interface{}
+		for current := new(big.Rat).Set(low); ; current = new(big.Rat).Add(current, step) {
+			items = append(items, current)
+			if current.Cmp(high) == 0 {
+				return items, nil
+			}
+		}
+	default:
+		return []interface{}{value}, nil
+	}
+}
+
+func evalAll(s *scope, nodes ...node) ([]interface{}, error) {
+	values := make([]interface{}, len(nodes))
+	for i, n := range nodes {
+		var err error
+		if values[i], err = n.eval(s); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func and(a, b interface{}) interface{} {
+	if a == false || b == false {
+		return false
+	}
+	if a == true && b == true {
+		return true
+	}
+	return nil
+}
+
+// equal compares the values, or returns null if they are not comparable
+func equal(a, b interface{}) interface{} {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	switch a := a.(type) {
+	case *big.Rat:
+		if b, ok := b.(*big.Rat); ok {
+			return a.Cmp(b) == 0
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok {
+			if len(a) != len(b) {
+				return false
+			}
+			for i := range a {
+				if equal(a[i], b[i]) != true {
+					return false
+				}
+			}
+			return true
+		}
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			if len(a) != len(b) {
+				return false
+			}
+			for key, value := range a {
+				if other, ok := b[key]; !ok || equal(value, other) != true {
+					return false
+				}
+			}
+			return true
+		}
+	case bool, string:
+		if reflect.TypeOf(a) == reflect.TypeOf(b) {
+			return a == b
+		}
+	}
+	return nil
+}
+
+func contains(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if equal(candidate, value) == true {
+			return true
+		}
+	}
+	return false
+}
+
+// compare returns the order of numbers or strings, or false if they are not comparable
+func compare(a, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case *big.Rat:
+		if b, ok := b.(*big.Rat); ok {
+			return a.Cmp(b), true
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			switch {
+			case a < b:
+				return -1, true
+			case a > b:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func compareWith(operator string, a, b interface{}) interface{} {
+	order, ok := compare(a, b)
+	if !ok {
+		return nil
+	}
+
+	switch operator {
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	default:
+		return order >= 0
+	}
+}
+
+func arithmetic(operator string, a, b interface{}) interface{} {
+	if operator == "+" {
+		if a, ok := a.(string); ok {
+			if b, ok := b.(string); ok {
+				return a + b
+			}
+		}
+	}
+
+	x, okX := a.(*big.Rat)
+	y, okY := b.(*big.Rat)
+	if !okX || !okY {
+		return nil
+	}
+
+	switch operator {
+	case "+":
+		return new(big.Rat).Add(x, y)
+	case "-":
+		return new(big.Rat).Sub(x, y)
+	case "*":
+		return new(big.Rat).Mul(x, y)
+	case "/":
+		if y.Sign() == 0 {
+			return nil
+		}
+		return new(big.Rat).Quo(x, y)
+	default:
+		return power(x, y)
+	}
+}
+
+// power raises the number to an integer exponent, or returns null for other exponents
+func power(base, exponent *big.Rat) interface{} {
+	if !exponent.IsInt() {
+		return nil
+	}
+
+	n := new(big.Int).Abs(exponent.Num())
+	result := new(big.Rat).SetFrac(new(big.Int).Exp(base.Num(), n, nil), new(big.Int).Exp(base.Denom(), n, nil))
+	if exponent.Sign() < 0 {
+		if result.Sign() == 0 {
+			return nil
+		}
+		result.Inv(result)
+	}
+	return result
+}
@@ -0,0 +1,142 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package feel evaluates FEEL expressions, like the input and output mappings or the conditions of sequence flows of
+// BPMN models, e.g. to test them without deploying the models to a broker.
+//
+// It implements a subset of FEEL which covers the expressions of typical mappings and conditions: literals of
+// numbers, strings, booleans, null, lists and contexts, variables and paths, filters, arithmetic, comparisons,
+// between, in with values, lists and ranges, and, or, if, for, some and every, and the built-in functions of strings,
+// lists and numbers like 'string length', 'list contains', count, sum, min and max. Temporal values like dates and
+// durations, unary tests and user-defined functions are not supported.
+//
+// Numbers are evaluated as decimals, so 0.1 + 0.2 = 0.3, but the results are returned as float64, like numbers which
+// are decoded by encoding/json.
+package feel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Expression is a parsed FEEL expression. It is safe for concurrent use.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Parse parses the FEEL expression. A leading '=', which marks expressions in BPMN models, is ignored.
+func Parse(expression string) (*Expression, error) {
+	source := strings.TrimPrefix(strings.TrimSpace(expression), "=")
+	root, err := parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse expression '%s': %w", expression, err)
+	}
+	return &Expression{source: expression, root: root}, nil
+}
+
+// Evaluate evaluates the expression with the variables of the context, which can be any value that is serializable
+// as JSON object, e.g. a map or a struct. The result is null, a bool, float64, string, []interface{} or
+// map[string]interface{}, like values which are decoded by encoding/json. Variables which don't exist are an error,
+// while values which don't match the operation, like adding a string to a number, result in null.
+func (e *Expression) Evaluate(context interface{}) (interface{}, error) {
+	variables, err := toVariables(context)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := e.root.eval(&scope{variables: variables})
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate expression '%s': %w", e.source, err)
+	}
+	return fromValue(result), nil
+}
+
+// Evaluate parses the expression and evaluates it with the variables of the context, see Expression.Evaluate.
+func Evaluate(expression string, context interface{}) (interface{}, error) {
+	parsed, err := Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Evaluate(context)
+}
+
+func toVariables(context interface{}) (map[string]interface{}, error) {
+	if context == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	data, err := json.Marshal(context)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize context: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var variables map[string]interface{}
+	if err := decoder.Decode(&variables); err != nil {
+		return nil, fmt.Errorf("expected context to be a JSON object: %w", err)
+	}
+	return toValue(variables).(map[string]interface{}), nil
+}
+
+// toValue converts the numbers of a decoded JSON value to decimals
+func toValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		if number, ok := new(big.Rat).SetString(value.String()); ok {
+			return number
+		}
+		return nil
+	case []interface{}:
+		for i, item := range value {
+			value[i] = toValue(item)
+		}
+		return value
+	case map[string]interface{}:
+		for key, entry := range value {
+			value[key] = toValue(entry)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+func fromValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case *big.Rat:
+		f, _ := value.Float64()
+		return f
+	case []interface{}:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = fromValue(item)
+		}
+		return items
+	case map[string]interface{}:
+		entries := make(map[string]interface{}, len(value))
+		for key, entry := range value {
+			entries[key] = fromValue(entry)
+		}
+		return entries
+	case rangeValue:
+		return nil
+	default:
+		return value
+	}
+}
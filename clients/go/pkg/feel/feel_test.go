@@ -0,0 +1,185 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/bpmn"
+)
+
+type order struct {
+	ID    string                   `json:"id"`
+	Total float64                  `json:"total"`
+	Items []map[string]interface{} `json:"items"`
+}
+
+var variables = map[string]interface{}{
+	"order": order{
+		ID:    "o-1",
+		Total: 42.5,
+		Items: []map[string]interface{}{
+			{"sku": "a", "quantity": 2, "price": 10},
+			{"sku": "b", "quantity": 1, "price": 22.5},
+		},
+	},
+	"customer": map[string]interface{}{"name": "Jane", "vip": true, "email": nil},
+	"limit":    100,
+}
+
+func TestEvaluate(t *testing.T) {
+	for expression, expected := range map[string]interface{}{
+		// literals and arithmetic
+		`= 1 + 2 * 3`:      float64(7),
+		`(1 + 2) * 3`:      float64(9),
+		`0.1 + 0.2 = 0.3`:  true,
+		`2 ** 10`:          float64(1024),
+		`-limit / 8`:       -12.5,
+		`1 / 0`:            nil,
+		`"a" + "b"`:        "ab",
+		`"a" + 1`:          nil,
+		`null`:             nil,
+		`[1, "a", true]`:   []interface{}{float64(1), "a", true},
+		`{a: 1, b: a + 1}`: map[string]interface{}{"a": float64(1), "b": float64(2)},
+		// variables and paths
+		`order.id`:                      "o-1",
+		`order.items.sku`:               []interface{}{"a", "b"},
+		`order.missing`:                 nil,
+		`customer.email = null`:         true,
+		`order.items[1].sku`:            "a",
+		`order.items[-1].sku`:           "b",
+		`order.items[quantity > 1]`:     []interface{}{map[string]interface{}{"sku": "a", "quantity": float64(2), "price": float64(10)}},
+		`order.items[item.price > 100]`: []interface{}{},
+		`[1, 2, 3, 4][item > 2]`:        []interface{}{float64(3), float64(4)},
+		// comparisons and logic
+		`order.total > 40 and customer.vip`:   true,
+		`order.total > limit or customer.vip`: true,
+		`order.total < "a"`:                   nil,
+		`null and false`:                      false,
+		`null or true`:                        true,
+		`null and true`:                       nil,
+		`order.total between 40 and 50`:       true,
+		`"b" in ["a", "b"]`:                   true,
+		`5 in (5..10]`:                        false,
+		`5 in [5..10]`:                        true,
+		`10 in ]5..10[`:                       false,
+		`3 in (1, 2, 3)`:                      true,
+		`order.id != "o-2"`:                   true,
+		`[1, [2]] = [1, [2]]`:                 true,
+		`{a: 1} = {a: 1.0}`:                   true,
+		// control flow
+		`if customer.vip then "fast" else "normal"`:                 "fast",
+		`if customer.email != null then 1 else 2`:                   float64(2),
+		`for item in order.items return item.quantity * item.price`: []interface{}{float64(20), 22.5},
+		`for i in 1..3 return i * i`:                                []interface{}{float64(1), float64(4), float64(9)},
+		`for x in [1, 2], y in [10, 20] return x + y`:               []interface{}{float64(11), float64(21), float64(12), float64(22)},
+		`some item in order.items satisfies item.price > 20`:        true,
+		`every item in order.items satisfies item.price > 20`:       false,
+		// built-in functions
+		`string length(customer.name)`:            float64(4),
+		`upper case("abc")`:                       "ABC",
+		`substring("foobar", 4)`:                  "bar",
+		`substring("foobar", -3, 2)`:              "ba",
+		`starts with(order.id, "o-")`:             true,
+		`contains("foobar", "oba")`:               true,
+		`matches("o-1", "^o-[0-9]+$")`:            true,
+		`count(order.items)`:                      float64(2),
+		`sum(order.items.price)`:                  32.5,
+		`sum(1, 2, 3)`:                            float64(6),
+		`mean([1, 2])`:                            1.5,
+		`min([3, 1, 2])`:                          float64(1),
+		`max(3, 1, 2)`:                            float64(3),
+		`list contains(order.items.sku, "b")`:     true,
+		`append([1], 2, 3)`:                       []interface{}{float64(1), float64(2), float64(3)},
+		`concatenate([1], [2, 3])`:                []interface{}{float64(1), float64(2), float64(3)},
+		`distinct values([1, 2, 1])`:              []interface{}{float64(1), float64(2)},
+		`flatten([1, [2, [3]]])`:                  []interface{}{float64(1), float64(2), float64(3)},
+		`reverse([1, 2])`:                         []interface{}{float64(2), float64(1)},
+		`index of([1, 2, 1], 1)`:                  []interface{}{float64(1), float64(3)},
+		`sort(["b", "a"])`:                        []interface{}{"a", "b"},
+		`not(customer.vip)`:                       false,
+		`string(1.5) + "/" + string(order.total)`: "1.5/42.5",
+		`number("12.5") * 2`:                      float64(25),
+		`floor(-1.5)`:                             float64(-2),
+		`ceiling(1.2)`:                            float64(2),
+		`abs(-3)`:                                 float64(3),
+		`decimal(2.345, 2)`:                       2.34,
+		`decimal(2.355, 2)`:                       2.36,
+	} {
+		actual, err := Evaluate(expression, variables)
+		if assert.NoError(t, err, expression) {
+			assert.Equal(t, expected, actual, expression)
+		}
+	}
+}
+
+func TestEvaluateUnknownVariable(t *testing.T) {
+	_, err := Evaluate("= missing + 1", variables)
+
+	assert.EqualError(t, err, "failed to evaluate expression '= missing + 1': no variable found for name 'missing'")
+}
+
+func TestEvaluateUnknownFunction(t *testing.T) {
+	_, err := Evaluate("missing(1)", nil)
+
+	assert.Error(t, err)
+}
+
+func TestParseInvalidExpressions(t *testing.T) {
+	for _, expression := range []string{
+		`1 +`,
+		`(1`,
+		`[1, 2`,
+		`{a 1}`,
+		`"unterminated`,
+		`if true then 1`,
+		`for 1 in x return x`,
+		`1 2`,
+		`a # b`,
+		`[1..`,
+	} {
+		_, err := Parse(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestParsedExpressionCanBeEvaluatedAgain(t *testing.T) {
+	expression, err := Parse("= amount > 10")
+	require.NoError(t, err)
+
+	for amount, expected := range map[int]bool{5: false, 15: true} {
+		result, err := expression.Evaluate(map[string]int{"amount": amount})
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	}
+}
+
+func TestEvaluateMappings(t *testing.T) {
+	mappings := []bpmn.Mapping{
+		{Source: "= order.id", Target: "orderId"},
+		{Source: "= sum(order.items.price)", Target: "payment.amount"},
+		{Source: `= "EUR"`, Target: "payment.currency"},
+	}
+
+	result, err := EvaluateMappings(mappings, variables)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"orderId": "o-1",
+		"payment": map[string]interface{}{"amount": 32.5, "currency": "EUR"},
+	}, result)
+}
@@ -0,0 +1,126 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feel
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenName
+	tokenOperator
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+// operators are ordered, so longer operators are matched first
+var operators = []string{"**", "..", "!=", "<=", ">=", "+", "-", "*", "/", "=", "<", ">", "(", ")", "[", "]", "{", "}", ",", ".", ":"}
+
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			// a range like 1..10 is not a decimal
+			if i+1 < len(runes) && runes[i] == '.' && unicode.IsDigit(runes[i+1]) {
+				i++
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), offset: start})
+		case r == '"':
+			text, end, err := readString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, offset: i})
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenName, text: string(runes[start:i]), offset: start})
+		default:
+			operator := matchOperator(runes[i:])
+			if operator == "" {
+				return nil, fmt.Errorf("unexpected character '%c' at offset %d", r, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: operator, offset: i})
+			i += len([]rune(operator))
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, offset: len(runes)}), nil
+}
+
+func matchOperator(runes []rune) string {
+	rest := string(runes)
+	for _, operator := range operators {
+		if strings.HasPrefix(rest, operator) {
+			return operator
+		}
+	}
+	return ""
+}
+
+func readString(runes []rune, start int) (string, int, error) {
+	var text strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '"':
+			return text.String(), i + 1, nil
+		case '\\':
+			if i+1 == len(runes) {
+				break
+			}
+			i++
+			switch runes[i] {
+			case 'n':
+				text.WriteRune('\n')
+			case 't':
+				text.WriteRune('\t')
+			case 'r':
+				text.WriteRune('\r')
+			default:
+				text.WriteRune(runes[i])
+			}
+		default:
+			text.WriteRune(runes[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string at offset %d", start)
+}
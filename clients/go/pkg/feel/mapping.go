@@ -0,0 +1,70 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/bpmn"
+)
+
+// EvaluateMappings evaluates the input or output mappings of a service task, see bpmn.ServiceTasks, with the
+// variables of the context and returns the variables which are set by them. Targets like 'order.id' set the entry of
+// a nested context. Like the broker, the mappings are applied in order, so later mappings can extend the contexts of
+// earlier ones.
+func EvaluateMappings(mappings []bpmn.Mapping, context interface{}) (map[string]interface{}, error) {
+	variables, err := toVariables(context)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, mapping := range mappings {
+		expression, err := Parse(mapping.Source)
+		if err != nil {
+			return nil, err
+		}
+		value, err := expression.root.eval(&scope{variables: variables})
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate source '%s' of target '%s': %w", mapping.Source, mapping.Target, err)
+		}
+		if err := setPath(result, mapping.Target, fromValue(value)); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func setPath(variables map[string]interface{}, target string, value interface{}) error {
+	names := strings.Split(target, ".")
+	for i, name := range names {
+		if name == "" {
+			return fmt.Errorf("invalid target '%s' of mapping", target)
+		}
+		if i == len(names)-1 {
+			variables[name] = value
+			return nil
+		}
+
+		nested, ok := variables[name].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			variables[name] = nested
+		}
+		variables = nested
+	}
+	return nil
+}
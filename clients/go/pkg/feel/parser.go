@@ -0,0 +1,582 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feel
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var keywords = map[string]bool{
+	"and": true, "or": true, "if": true, "then": true, "else": true, "for": true, "in": true, "return": true,
+	"some": true, "every": true, "satisfies": true, "between": true, "true": true, "false": true, "null": true,
+}
+
+type node interface {
+	eval(s *scope) (interface{}, error)
+}
+
+type (
+	literal struct {
+		value interface{}
+	}
+	variable struct {
+		name string
+	}
+	path struct {
+		target node
+		name   string
+	}
+	filter struct {
+		target node
+		filter node
+	}
+	call struct {
+		function string
+		args     []node
+	}
+	negation struct {
+		operand node
+	}
+	binary struct {
+		operator    string
+		left, right node
+	}
+	between struct {
+		value, low, high node
+	}
+	in struct {
+		value node
+		tests []node
+	}
+	rangeNode struct {
+		low, high             node
+		lowClosed, highClosed bool
+	}
+	list struct {
+		items []node
+	}
+	context struct {
+		keys   []string
+		values []node
+	}
+	conditional struct {
+		condition, then, otherwise node
+	}
+	iteration struct {
+		names []string
+		lists []node
+	}
+	forNode struct {
+		iteration
+		body node
+	}
+	quantified struct {
+		iteration
+		every     bool
+		condition node
+	}
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+	// rangeEnd is set while the end of a range is parsed, which can be followed by '[' as in ]1..10[
+	rangeEnd bool
+}
+
+func parse(expression string) (node, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, p.unexpected(next)
+	}
+	return root, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is returns whether the next token is the operator or keyword
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return (t.kind == tokenOperator || t.kind == tokenName) && t.text == text
+}
+
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return fmt.Errorf("expected '%s' at offset %d, but got %s", text, p.peek().offset, describe(p.peek()))
+	}
+	return nil
+}
+
+func (p *parser) unexpected(t token) error {
+	return fmt.Errorf("unexpected %s at offset %d", describe(t), t.offset)
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return fmt.Sprintf("string \"%s\"", t.text)
+	default:
+		return fmt.Sprintf("'%s'", t.text)
+	}
+}
+
+func (p *parser) parseExpression() (node, error) {
+	switch {
+	case p.accept("if"):
+		return p.parseConditional()
+	case p.accept("for"):
+		return p.parseFor()
+	case p.is("some") || p.is("every"):
+		return p.parseQuantified(p.next().text == "every")
+	default:
+		return p.parseDisjunction()
+	}
+}
+
+func (p *parser) parseConditional() (node, error) {
+	condition, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("then"); err != nil {
+		return nil, err
+	}
+	then, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("else"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	return &conditional{condition: condition, then: then, otherwise: otherwise}, nil
+}
+
+func (p *parser) parseIteration(end string) (iteration, error) {
+	var it iteration
+	for {
+		name := p.next()
+		if name.kind != tokenName || keywords[name.text] {
+			return it, fmt.Errorf("expected name of iteration variable at offset %d, but got %s", name.offset, describe(name))
+		}
+		if err := p.expect("in"); err != nil {
+			return it, err
+		}
+		values, err := p.parseDisjunction()
+		if err != nil {
+			return it, err
+		}
+		if p.accept("..") {
+			high, err := p.parseDisjunction()
+			if err != nil {
+				return it, err
+			}
+			values = &rangeNode{low: values, high: high, lowClosed: true, highClosed: true}
+		}
+
+		it.names = append(it.names, name.text)
+		it.lists = append(it.lists, values)
+		if !p.accept(",") {
+			return it, p.expect(end)
+		}
+	}
+}
+
+func (p *parser) parseFor() (node, error) {
+	it, err := p.parseIteration("return")
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	return &forNode{iteration: it, body: body}, nil
+}
+
+func (p *parser) parseQuantified(every bool) (node, error) {
+	it, err := p.parseIteration("satisfies")
+	if err != nil {
+		return nil, err
+	}
+	condition, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	return &quantified{iteration: it, every: every, condition: condition}, nil
+}
+
+func (p *parser) parseDisjunction() (node, error) {
+	left, err := p.parseConjunction()
+	for err == nil && p.accept("or") {
+		var right node
+		if right, err = p.parseConjunction(); err == nil {
+			left = &binary{operator: "or", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseConjunction() (node, error) {
+	left, err := p.parseComparison()
+	for err == nil && p.accept("and") {
+		var right node
+		if right, err = p.parseComparison(); err == nil {
+			left = &binary{operator: "and", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, operator := range []string{"=", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(operator) {
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &binary{operator: operator, left: left, right: right}, nil
+		}
+	}
+
+	switch {
+	case p.accept("between"):
+		low, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("and"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &between{value: left, low: low, high: high}, nil
+	case p.accept("in"):
+		tests, err := p.parseTests()
+		if err != nil {
+			return nil, err
+		}
+		return &in{value: left, tests: tests}, nil
+	}
+	return left, nil
+}
+
+// parseTests parses the tests of an in expression: a single range or value, or a parenthesized list of them
+func (p *parser) parseTests() ([]node, error) {
+	if !p.accept("(") {
+		test, err := p.parseAdditive()
+		return []node{test}, err
+	}
+
+	var tests []node
+	for {
+		test, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if len(tests) == 0 && p.accept("..") {
+			// the parenthesis opened a range which excludes its start
+			rng, err := p.parseRangeEnd(test, false)
+			return []node{rng}, err
+		}
+
+		tests = append(tests, test)
+		if !p.accept(",") {
+			return tests, p.expect(")")
+		}
+	}
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	for err == nil && (p.is("+") || p.is("-")) {
+		operator := p.next().text
+		var right node
+		if right, err = p.parseMultiplicative(); err == nil {
+			left = &binary{operator: operator, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseExponentiation()
+	for err == nil && (p.is("*") || p.is("/")) {
+		operator := p.next().text
+		var right node
+		if right, err = p.parseExponentiation(); err == nil {
+			left = &binary{operator: operator, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseExponentiation() (node, error) {
+	left, err := p.parseUnary()
+	for err == nil && p.accept("**") {
+		var right node
+		if right, err = p.parseUnary(); err == nil {
+			left = &binary{operator: "**", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negation{operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	rangeEnd := p.rangeEnd
+	p.rangeEnd = false
+
+	target, err := p.parsePrimary()
+	for err == nil {
+		switch {
+		case p.accept("."):
+			name := p.next()
+			if name.kind != tokenName {
+				return nil, fmt.Errorf("expected name after '.' at offset %d, but got %s", name.offset, describe(name))
+			}
+			target = &path{target: target, name: name.text}
+		case !rangeEnd && p.accept("["):
+			var condition node
+			if condition, err = p.parseExpression(); err == nil {
+				target = &filter{target: target, filter: condition}
+				err = p.expect("]")
+			}
+		default:
+			return target, nil
+		}
+	}
+	return nil, err
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		value, ok := new(big.Rat).SetString(t.text)
+		if !ok {
+			return nil, fmt.Errorf("invalid number '%s' at offset %d", t.text, t.offset)
+		}
+		return &literal{value: value}, nil
+	case tokenString:
+		return &literal{value: t.text}, nil
+	case tokenName:
+		return p.parseName(t)
+	}
+
+	switch t.text {
+	case "(":
+		expression, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if p.accept("..") {
+			return p.parseRangeEnd(expression, false)
+		}
+		return expression, p.expect(")")
+	case "[":
+		return p.parseList()
+	case "]":
+		low, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(".."); err != nil {
+			return nil, err
+		}
+		return p.parseRangeEnd(low, false)
+	case "{":
+		return p.parseContext()
+	}
+	return nil, p.unexpected(t)
+}
+
+func (p *parser) parseName(t token) (node, error) {
+	switch t.text {
+	case "true", "false":
+		return &literal{value: t.text == "true"}, nil
+	case "null":
+		return &literal{value: nil}, nil
+	}
+	if keywords[t.text] {
+		return nil, p.unexpected(t)
+	}
+
+	// names of built-in functions can contain spaces, like 'string length'
+	for words := 3; words > 1; words-- {
+		if name, ok := p.functionName(t.text, words); ok {
+			p.pos += words - 1
+			return p.parseCall(name)
+		}
+	}
+	if p.is("(") {
+		return p.parseCall(t.text)
+	}
+	return &variable{name: t.text}, nil
+}
+
+func (p *parser) functionName(first string, words int) (string, bool) {
+	parts := []string{first}
+	for i := 0; i < words-1; i++ {
+		t := p.tokens[p.pos+i]
+		if t.kind != tokenName {
+			return "", false
+		}
+		parts = append(parts, t.text)
+	}
+
+	name := strings.Join(parts, " ")
+	next := p.tokens[p.pos+words-1]
+	_, builtin := builtins[name]
+	return name, builtin && next.kind == tokenOperator && next.text == "("
+}
+
+func (p *parser) parseCall(function string) (node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	c := &call{function: function}
+	if p.accept(")") {
+		return c, nil
+	}
+	for {
+		arg, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		c.args = append(c.args, arg)
+		if !p.accept(",") {
+			return c, p.expect(")")
+		}
+	}
+}
+
+func (p *parser) parseList() (node, error) {
+	l := &list{}
+	if p.accept("]") {
+		return l, nil
+	}
+	for {
+		item, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if len(l.items) == 0 && p.accept("..") {
+			// the bracket opened a range which includes its start
+			return p.parseRangeEnd(item, true)
+		}
+
+		l.items = append(l.items, item)
+		if !p.accept(",") {
+			return l, p.expect("]")
+		}
+	}
+}
+
+func (p *parser) parseRangeEnd(low node, lowClosed bool) (node, error) {
+	p.rangeEnd = true
+	high, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	rng := &rangeNode{low: low, high: high, lowClosed: lowClosed}
+	switch {
+	case p.accept("]"):
+		rng.highClosed = true
+	case p.accept(")"), p.accept("["):
+	default:
+		return nil, fmt.Errorf("expected end of range at offset %d, but got %s", p.peek().offset, describe(p.peek()))
+	}
+	return rng, nil
+}
+
+func (p *parser) parseContext() (node, error) {
+	c := &context{}
+	if p.accept("}") {
+		return c, nil
+	}
+	for {
+		key := p.next()
+		if key.kind != tokenName && key.kind != tokenString {
+			return nil, fmt.Errorf("expected key of context entry at offset %d, but got %s", key.offset, describe(key))
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		c.keys = append(c.keys, key.text)
+		c.values = append(c.values, value)
+		if !p.accept(",") {
+			return c, p.expect("}")
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inbound
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/feel"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/jsonpath"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/secrets"
+)
+
+const (
+	// AuthBasic requires the username and password of the route with HTTP basic authentication
+	AuthBasic = "basic"
+	// AuthBearer requires the token of the route as bearer token in the Authorization header
+	AuthBearer = "bearer"
+	// AuthHMAC requires the hex encoded HMAC-SHA256 of the body with the secret of the route in the signature header,
+	// optionally prefixed with 'sha256=', like the signatures of GitHub webhooks
+	AuthHMAC = "hmac"
+
+	// DefaultSignatureHeader is the header which contains the signature of routes with AuthHMAC if they set none
+	DefaultSignatureHeader = "X-Signature"
+)
+
+// Route maps the webhook requests to a path to messages. The correlation key, message id and variables are
+// expressions which select from a document with the decoded JSON body, the headers, whose names are lower case, and
+// the query parameters of the request:
+//
+//	{"body": {...}, "headers": {"x-request-id": "..."}, "query": {"source": "..."}}
+//
+// Expressions which start with '$' are JSONPath expressions, like '$.body.order.id', all others are FEEL
+// expressions, like 'body.order.id' or '"order-" + string(body.order.id)'.
+type Route struct {
+	// Path of the webhook, e.g. /orders/paid
+	Path string `yaml:"path"`
+	// Message is the name of the published message
+	Message string `yaml:"message"`
+	// CorrelationKey is the expression of the correlation key, which must select a string or a number
+	CorrelationKey string `yaml:"correlationKey"`
+	// MessageID is the optional expression of the message id, so redelivered webhooks are published only once
+	MessageID string `yaml:"messageId"`
+	// TimeToLive of the published message
+	TimeToLive time.Duration `yaml:"timeToLive"`
+	// Variables are the expressions of the variables of the message by name; if none are set, the body is the
+	// variables if it is an object
+	Variables map[string]string `yaml:"variables"`
+	// Auth of the webhook requests, none if empty
+	Auth Auth `yaml:"auth"`
+}
+
+// Auth configures the authentication of the webhook requests of a route. The credentials and the secret can contain
+// secret placeholders, like '{{secrets.WEBHOOK_TOKEN}}', which are resolved by the secrets.Provider of the server.
+type Auth struct {
+	// Type is AuthBasic, AuthBearer or AuthHMAC, or empty if the requests are not authenticated
+	Type            string `yaml:"type"`
+	Username        string `yaml:"username"`
+	Password        string `yaml:"password"`
+	Token           string `yaml:"token"`
+	Secret          string `yaml:"secret"`
+	SignatureHeader string `yaml:"signatureHeader"`
+}
+
+type routesFile struct {
+	Routes []Route `yaml:"routes"`
+}
+
+// LoadRoutes reads the routes from the YAML file at the path:
+//
+//	routes:
+//	  - path: /orders/paid
+//	    message: payment-received
+//	    correlationKey: $.body.orderId
+//	    timeToLive: 1h
+//	    variables:
+//	      amount: body.amount
+//	    auth:
+//	      type: bearer
+//	      token: '{{secrets.PAYMENT_WEBHOOK_TOKEN}}'
+func LoadRoutes(path string) ([]Route, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routes file %s: %w", path, err)
+	}
+
+	var file routesFile
+	if err := yaml.UnmarshalStrict(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse routes file %s: %w", path, err)
+	}
+	return file.Routes, nil
+}
+
+type compiledRoute struct {
+	Route
+	correlationKey *expression
+	messageID      *expression
+	variables      map[string]*expression
+}
+
+func compileRoute(route Route) (*compiledRoute, error) {
+	if !strings.HasPrefix(route.Path, "/") {
+		return nil, fmt.Errorf("expected path of route to start with '/', but got '%s'", route.Path)
+	}
+	if route.Message == "" {
+		return nil, fmt.Errorf("expected route %s to have a message name, but it has none", route.Path)
+	}
+	switch auth := route.Auth; auth.Type {
+	case "":
+	case AuthBasic:
+		if auth.Username == "" || auth.Password == "" {
+			return nil, fmt.Errorf("expected route %s with basic auth to have a username and password", route.Path)
+		}
+	case AuthBearer:
+		if auth.Token == "" {
+			return nil, fmt.Errorf("expected route %s with bearer auth to have a token", route.Path)
+		}
+	case AuthHMAC:
+		if auth.Secret == "" {
+			return nil, fmt.Errorf("expected route %s with hmac auth to have a secret", route.Path)
+		}
+	default:
+		return nil, fmt.Errorf("expected auth type of route %s to be one of '%s', '%s' or '%s', but got '%s'", route.Path, AuthBasic, AuthBearer, AuthHMAC, route.Auth.Type)
+	}
+
+	compiled := &compiledRoute{Route: route, variables: make(map[string]*expression, len(route.Variables))}
+	var err error
+	if compiled.correlationKey, err = compileExpression(route.CorrelationKey); err != nil {
+		return nil, fmt.Errorf("invalid correlation key of route %s: %w", route.Path, err)
+	}
+	if route.MessageID != "" {
+		if compiled.messageID, err = compileExpression(route.MessageID); err != nil {
+			return nil, fmt.Errorf("invalid message id of route %s: %w", route.Path, err)
+		}
+	}
+	for name, source := range route.Variables {
+		if compiled.variables[name], err = compileExpression(source); err != nil {
+			return nil, fmt.Errorf("invalid variable '%s' of route %s: %w", name, route.Path, err)
+		}
+	}
+	return compiled, nil
+}
+
+// authenticate returns an error if the request is not authenticated as configured by the auth of the route.
+func (r *compiledRoute) authenticate(ctx context.Context, provider secrets.Provider, request *http.Request, body []byte) error {
+	auth := r.Auth
+	switch auth.Type {
+	case AuthBasic:
+		username, password, ok := request.BasicAuth()
+		if !ok {
+			return fmt.Errorf("expected basic authentication")
+		}
+		expectedUsername, err := resolveSecret(ctx, provider, auth.Username)
+		if err != nil {
+			return err
+		}
+		expectedPassword, err := resolveSecret(ctx, provider, auth.Password)
+		if err != nil {
+			return err
+		}
+		if !equal(username, expectedUsername) || !equal(password, expectedPassword) {
+			return fmt.Errorf("invalid username or password")
+		}
+	case AuthBearer:
+		token := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+		expected, err := resolveSecret(ctx, provider, auth.Token)
+		if err != nil {
+			return err
+		}
+		if !equal(token, expected) {
+			return fmt.Errorf("invalid bearer token")
+		}
+	case AuthHMAC:
+		header := auth.SignatureHeader
+		if header == "" {
+			header = DefaultSignatureHeader
+		}
+		signature, err := hex.DecodeString(strings.TrimPrefix(request.Header.Get(header), "sha256="))
+		if err != nil {
+			return fmt.Errorf("expected hex encoded signature in header %s: %w", header, err)
+		}
+		secret, err := resolveSecret(ctx, provider, auth.Secret)
+		if err != nil {
+			return err
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return fmt.Errorf("invalid signature in header %s", header)
+		}
+	}
+	return nil
+}
+
+func resolveSecret(ctx context.Context, provider secrets.Provider, value string) (string, error) {
+	if provider == nil || !secrets.HasPlaceholders(value) {
+		return value, nil
+	}
+	return secrets.Resolve(ctx, provider, value)
+}
+
+func equal(actual, expected string) bool {
+	return expected != "" && subtle.ConstantTimeCompare([]byte(actual), []byte(expected)) == 1
+}
+
+// expression is a JSONPath expression, if it starts with '$', or a FEEL expression.
+type expression struct {
+	path *jsonpath.Path
+	feel *feel.Expression
+}
+
+func compileExpression(source string) (*expression, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, fmt.Errorf("expected an expression, but it is empty")
+	}
+
+	if strings.HasPrefix(source, "$") {
+		path, err := jsonpath.Compile(source)
+		if err != nil {
+			return nil, err
+		}
+		if !path.Definite() {
+			return nil, fmt.Errorf("expected JSONPath expression '%s' to select a single value, but it contains wildcards", source)
+		}
+		return &expression{path: path}, nil
+	}
+
+	parsed, err := feel.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	return &expression{feel: parsed}, nil
+}
+
+// evaluate returns the value of the expression in the document, which is nil if a JSONPath expression selects none.
+func (e *expression) evaluate(document map[string]interface{}) (interface{}, error) {
+	if e.path != nil {
+		values := e.path.Select(document)
+		if len(values) == 0 {
+			return nil, nil
+		}
+		return values[0], nil
+	}
+	return e.feel.Evaluate(document)
+}
+
+// evaluateKey returns the value of the expression as string, e.g. for the correlation key.
+func (e *expression) evaluateKey(document map[string]interface{}) (string, error) {
+	value, err := e.evaluate(document)
+	if err != nil {
+		return "", err
+	}
+
+	switch key := value.(type) {
+	case string:
+		if key != "" {
+			return key, nil
+		}
+	case json.Number:
+		return key.String(), nil
+	case float64:
+		return strconv.FormatFloat(key, 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("expected a string or a number, but got %v", value)
+}
@@ -0,0 +1,281 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inbound publishes messages for incoming webhooks, e.g. of payment providers: a Server is an http.Handler
+// which maps the requests to the paths of its routes to messages, with correlation key, message id and variables
+// which are selected from the request, see Route.
+//
+//	server, err := inbound.New(client, nil, inbound.Options{Secrets: secrets.EnvProvider{}})
+//	...
+//	go server.WatchFile(ctx, "routes.yaml", inbound.DefaultWatchInterval)
+//	http.ListenAndServe(":8080", server)
+//
+// The server responds with 202 and the key of the published message, or with 409 if a message with the same id was
+// already published. The routes can be replaced while the server is running with Reload or WatchFile. Signals can't
+// be broadcast, as the gateway does not support them.
+package inbound
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/secrets"
+)
+
+const (
+	// DefaultMaxBodySize is the size in bytes of the largest body which is accepted if no maximum is set.
+	DefaultMaxBodySize = 1 << 20
+	// DefaultRequestTimeout of the PublishMessage commands if no request timeout is set.
+	DefaultRequestTimeout = 10 * time.Second
+	// DefaultWatchInterval is the interval in which routes files are checked for changes by WatchFile.
+	DefaultWatchInterval = 5 * time.Second
+)
+
+// Client publishes the messages, e.g. the client of the zbc package.
+type Client interface {
+	NewPublishMessageCommand() commands.PublishMessageCommandStep1
+}
+
+// Options configure a Server. Zero values are replaced by the respective defaults.
+type Options struct {
+	// Secrets resolves the secret placeholders in the auth of the routes; they are not resolved if nil
+	Secrets secrets.Provider
+	// MaxBodySize is the size in bytes of the largest body which is accepted
+	MaxBodySize int64
+	// RequestTimeout of the PublishMessage commands
+	RequestTimeout time.Duration
+	// Logger, logging.Default if nil
+	Logger logging.Logger
+}
+
+// Server publishes the messages of the webhook requests to its routes. It is safe for concurrent use.
+type Server struct {
+	client  Client
+	options Options
+
+	lock   sync.RWMutex
+	routes map[string]*compiledRoute
+}
+
+// New creates a server with the routes. It fails if any route is invalid.
+func New(client Client, routes []Route, options Options) (*Server, error) {
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = DefaultMaxBodySize
+	}
+	if options.RequestTimeout <= 0 {
+		options.RequestTimeout = DefaultRequestTimeout
+	}
+	if options.Logger == nil {
+		options.Logger = logging.Default
+	}
+
+	server := &Server{client: client, options: options}
+	if err := server.Reload(routes); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// Reload replaces the routes of the server. If any route is invalid, the routes are not replaced and an error is
+// returned. Requests which are in flight are completed with the previous routes.
+func (s *Server) Reload(routes []Route) error {
+	compiled := make(map[string]*compiledRoute, len(routes))
+	for _, route := range routes {
+		if _, exists := compiled[route.Path]; exists {
+			return fmt.Errorf("expected paths of routes to be unique, but %s is configured more than once", route.Path)
+		}
+
+		var err error
+		if compiled[route.Path], err = compileRoute(route); err != nil {
+			return err
+		}
+	}
+
+	s.lock.Lock()
+	s.routes = compiled
+	s.lock.Unlock()
+	return nil
+}
+
+// WatchFile loads the routes from the file at the path, see LoadRoutes, and reloads them whenever its modification
+// time or size changes, until the context is done. If the file can't be loaded or contains invalid routes, the error
+// is logged and the server keeps its routes. It returns the error of the context.
+func (s *Server) WatchFile(ctx context.Context, path string, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var modTime time.Time
+	size := int64(-1)
+	for {
+		info, err := os.Stat(path)
+		if err != nil {
+			s.options.Logger.Warn("Failed to check routes file", "path", path, "error", err)
+		} else if !info.ModTime().Equal(modTime) || info.Size() != size {
+			modTime, size = info.ModTime(), info.Size()
+			s.reloadFile(path)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) reloadFile(path string) {
+	routes, err := LoadRoutes(path)
+	if err == nil {
+		err = s.Reload(routes)
+	}
+	if err != nil {
+		s.options.Logger.Warn("Failed to reload routes, keeping the previous routes", "path", path, "error", err)
+		return
+	}
+	s.options.Logger.Info("Reloaded routes", "path", path, "routes", len(routes))
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	s.lock.RLock()
+	route, ok := s.routes[request.URL.Path]
+	s.lock.RUnlock()
+
+	if !ok {
+		http.NotFound(w, request)
+		return
+	}
+	if request.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST requests are accepted", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, request.Body, s.options.MaxBodySize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read body: %v", err), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err := route.authenticate(request.Context(), s.options.Secrets, request, body); err != nil {
+		s.options.Logger.Debug("Rejected unauthenticated webhook request", "path", route.Path, "error", err)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	document, err := newDocument(request, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	command, err := s.newCommand(route, document)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), s.options.RequestTimeout)
+	defer cancel()
+
+	response, err := command.Send(ctx)
+	if status.Code(err) == codes.AlreadyExists {
+		http.Error(w, "message was already published", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		s.options.Logger.Warn("Failed to publish message of webhook", "path", route.Path, "message", route.Message, "error", err)
+		http.Error(w, "failed to publish message", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) newCommand(route *compiledRoute, document map[string]interface{}) (commands.DispatchPublishMessageCommand, error) {
+	correlationKey, err := route.correlationKey.evaluateKey(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select correlation key: %w", err)
+	}
+
+	command := s.client.NewPublishMessageCommand().MessageName(route.Message).CorrelationKey(correlationKey).TimeToLive(route.TimeToLive)
+	if route.messageID != nil {
+		messageID, err := route.messageID.evaluateKey(document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select message id: %w", err)
+		}
+		command = command.MessageId(messageID)
+	}
+
+	var variables interface{}
+	if len(route.variables) > 0 {
+		selected := make(map[string]interface{}, len(route.variables))
+		for name, expression := range route.variables {
+			if selected[name], err = expression.evaluate(document); err != nil {
+				return nil, fmt.Errorf("failed to select variable '%s': %w", name, err)
+			}
+		}
+		variables = selected
+	} else if body, ok := document["body"].(map[string]interface{}); ok {
+		variables = body
+	}
+
+	if variables != nil {
+		if command, err = command.VariablesFromObject(variables); err != nil {
+			return nil, err
+		}
+	}
+	return command.RequestTimeout(s.options.RequestTimeout), nil
+}
+
+// newDocument returns the document of the request which the expressions of the routes select from.
+func newDocument(request *http.Request, body []byte) (map[string]interface{}, error) {
+	var decodedBody interface{}
+	if len(bytes.TrimSpace(body)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&decodedBody); err != nil {
+			return nil, fmt.Errorf("expected body to be JSON: %w", err)
+		}
+	}
+
+	headers := make(map[string]interface{}, len(request.Header))
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = values[0]
+	}
+	query := make(map[string]interface{})
+	for name, values := range request.URL.Query() {
+		query[name] = values[0]
+	}
+
+	return map[string]interface{}{"body": decodedBody, "headers": headers, "query": query}, nil
+}
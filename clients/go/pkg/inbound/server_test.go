@@ -0,0 +1,193 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inbound
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/secrets"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbtest/mockgateway"
+)
+
+var paymentRoute = Route{
+	Path:           "/payments",
+	Message:        "payment-received",
+	CorrelationKey: "$.body.orderId",
+	MessageID:      `"payment-" + body.paymentId`,
+	TimeToLive:     time.Hour,
+	Variables:      map[string]string{"amount": "body.amount", "source": "$.query.source"},
+}
+
+func startServer(t *testing.T, routes []Route, options Options) (*mockgateway.Gateway, *Server, func()) {
+	gateway, err := mockgateway.Start()
+	require.NoError(t, err)
+	client, err := gateway.NewClient()
+	require.NoError(t, err)
+
+	server, err := New(client, routes, options)
+	require.NoError(t, err)
+	return gateway, server, func() {
+		_ = client.Close()
+		gateway.Close()
+	}
+}
+
+func post(server *Server, target, body string, header http.Header) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestPublishMessageOfWebhook(t *testing.T) {
+	gateway, server, stop := startServer(t, []Route{paymentRoute}, Options{})
+	defer stop()
+
+	response := post(server, "/payments?source=psp", `{"orderId": 1234567890123, "paymentId": "p-1", "amount": 42.5}`, nil)
+
+	require.Equal(t, http.StatusAccepted, response.Code)
+	require.Contains(t, response.Body.String(), `"key"`)
+	gateway.Recorder(t).AssertPublishedMessage("payment-received", "1234567890123").
+		WithMessageID("payment-p-1").
+		WithTimeToLive(time.Hour).
+		WithVariables(map[string]interface{}{"amount": 42.5, "source": "psp"})
+}
+
+func TestPublishBodyAsVariables(t *testing.T) {
+	route := Route{Path: "/orders", Message: "order-created", CorrelationKey: "$.headers['x-order-id']"}
+	gateway, server, stop := startServer(t, []Route{route}, Options{})
+	defer stop()
+
+	response := post(server, "/orders", `{"items": 3}`, http.Header{"X-Order-Id": {"order-1"}})
+
+	require.Equal(t, http.StatusAccepted, response.Code)
+	gateway.Recorder(t).AssertPublishedMessage("order-created", "order-1").WithVariables(map[string]interface{}{"items": 3})
+}
+
+func TestRejectInvalidRequests(t *testing.T) {
+	_, server, stop := startServer(t, []Route{paymentRoute}, Options{MaxBodySize: 64})
+	defer stop()
+
+	require.Equal(t, http.StatusNotFound, post(server, "/unknown", `{}`, nil).Code)
+	require.Equal(t, http.StatusBadRequest, post(server, "/payments", `not json`, nil).Code)
+	require.Equal(t, http.StatusBadRequest, post(server, "/payments", `{"paymentId": "p-1", "amount": 1}`, nil).Code)
+	require.Equal(t, http.StatusRequestEntityTooLarge, post(server, "/payments", `{"orderId": "`+strings.Repeat("x", 64)+`"}`, nil).Code)
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/payments", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestRespondConflictIfMessageWasPublished(t *testing.T) {
+	gateway, server, stop := startServer(t, []Route{paymentRoute}, Options{})
+	defer stop()
+	gateway.Respond("PublishMessage", mockgateway.Response{Err: status.Error(codes.AlreadyExists, "message already exists")})
+
+	response := post(server, "/payments", `{"orderId": "order-1", "paymentId": "p-1", "amount": 1}`, nil)
+
+	require.Equal(t, http.StatusConflict, response.Code)
+}
+
+func TestAuthenticateRequests(t *testing.T) {
+	provider := secrets.ProviderFunc(func(_ context.Context, name string) (string, error) {
+		return "s3cr3t", nil
+	})
+	routes := []Route{
+		{Path: "/basic", Message: "m", CorrelationKey: "$.body.id", Auth: Auth{Type: AuthBasic, Username: "hook", Password: "{{secrets.PASSWORD}}"}},
+		{Path: "/bearer", Message: "m", CorrelationKey: "$.body.id", Auth: Auth{Type: AuthBearer, Token: "{{secrets.TOKEN}}"}},
+		{Path: "/hmac", Message: "m", CorrelationKey: "$.body.id", Auth: Auth{Type: AuthHMAC, Secret: "{{secrets.SECRET}}", SignatureHeader: "X-Hub-Signature-256"}},
+	}
+	_, server, stop := startServer(t, routes, Options{Secrets: provider})
+	defer stop()
+
+	body := `{"id": "1"}`
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte(body))
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	basic := httptest.NewRequest(http.MethodPost, "/basic", nil)
+	basic.SetBasicAuth("hook", "s3cr3t")
+
+	require.Equal(t, http.StatusAccepted, post(server, "/basic", body, basic.Header).Code)
+	require.Equal(t, http.StatusAccepted, post(server, "/bearer", body, http.Header{"Authorization": {"Bearer s3cr3t"}}).Code)
+	require.Equal(t, http.StatusAccepted, post(server, "/hmac", body, http.Header{"X-Hub-Signature-256": {signature}}).Code)
+
+	require.Equal(t, http.StatusUnauthorized, post(server, "/basic", body, nil).Code)
+	require.Equal(t, http.StatusUnauthorized, post(server, "/bearer", body, http.Header{"Authorization": {"Bearer wrong"}}).Code)
+	require.Equal(t, http.StatusUnauthorized, post(server, "/hmac", `{"id": "2"}`, http.Header{"X-Hub-Signature-256": {signature}}).Code)
+}
+
+func TestRejectInvalidRoutes(t *testing.T) {
+	_, server, stop := startServer(t, []Route{paymentRoute}, Options{})
+	defer stop()
+
+	require.Error(t, server.Reload([]Route{{Path: "payments", Message: "m", CorrelationKey: "$.body.id"}}))
+	require.Error(t, server.Reload([]Route{{Path: "/payments", CorrelationKey: "$.body.id"}}))
+	require.Error(t, server.Reload([]Route{{Path: "/payments", Message: "m", CorrelationKey: "$.body.items[*]"}}))
+	require.Error(t, server.Reload([]Route{{Path: "/payments", Message: "m", CorrelationKey: "$.body.id", Auth: Auth{Type: AuthBearer}}}))
+	require.Error(t, server.Reload([]Route{paymentRoute, paymentRoute}))
+
+	// the previous routes are kept
+	require.Equal(t, http.StatusAccepted, post(server, "/payments", `{"orderId": "order-1", "paymentId": "p-1", "amount": 1}`, nil).Code)
+}
+
+func TestWatchFileReloadsRoutes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zeebe-inbound")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "routes.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("routes:\n  - path: /old\n    message: m\n    correlationKey: $.body.id\n"), 0600))
+	_, server, stop := startServer(t, nil, Options{})
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.WatchFile(ctx, path, 10*time.Millisecond) }()
+
+	require.Eventually(t, func() bool {
+		return post(server, "/old", `{"id": "1"}`, nil).Code == http.StatusAccepted
+	}, utils.DefaultTestTimeout, 10*time.Millisecond)
+
+	content := "routes:\n  - path: /new\n    message: m\n    correlationKey: $.body.id\n    timeToLive: 1m\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	require.Eventually(t, func() bool {
+		return post(server, "/new", `{"id": "1"}`, nil).Code == http.StatusAccepted
+	}, utils.DefaultTestTimeout, 10*time.Millisecond)
+	require.Equal(t, http.StatusNotFound, post(server, "/old", `{"id": "1"}`, nil).Code)
+
+	cancel()
+	require.Equal(t, context.Canceled, <-done)
+}
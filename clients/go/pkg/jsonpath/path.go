@@ -0,0 +1,297 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonpath selects values from JSON documents, e.g. the variables of a job, with JSONPath expressions like
+// '$.order.items[*].sku'.
+//
+// A path starts with '$', the document, followed by any number of child properties, '.name' or ['name'], array
+// indices, '[0]' or '[-1]' for the last element, and wildcards, '.*' or '[*]', which select all properties of an
+// object or all elements of an array. Filters, slices, unions and recursive descent are not supported.
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Path is a compiled JSONPath expression. It is safe for concurrent use.
+type Path struct {
+	expression string
+	segments   []segment
+	definite   bool
+}
+
+type segmentKind int
+
+const (
+	property segmentKind = iota
+	index
+	wildcard
+)
+
+type segment struct {
+	kind  segmentKind
+	name  string
+	index int
+}
+
+// Compile parses the JSONPath expression.
+func Compile(expression string) (*Path, error) {
+	p := &parser{expression: expression}
+	segments, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	definite := true
+	for _, s := range segments {
+		if s.kind == wildcard {
+			definite = false
+		}
+	}
+	return &Path{expression: expression, segments: segments, definite: definite}, nil
+}
+
+// MustCompile is like Compile, but panics if the expression cannot be compiled, e.g. to initialize global variables.
+func MustCompile(expression string) *Path {
+	path, err := Compile(expression)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *Path) String() string {
+	return p.expression
+}
+
+// Definite returns whether the path selects at most one value, i.e. it contains no wildcards.
+func (p *Path) Definite() bool {
+	return p.definite
+}
+
+// Root returns the name of the top-level property which the path selects from, e.g. 'order' for '$.order.items', or
+// an empty string if it selects from the whole document.
+func (p *Path) Root() string {
+	if len(p.segments) == 0 || p.segments[0].kind != property {
+		return ""
+	}
+	return p.segments[0].name
+}
+
+// Select returns all values selected by the path from the document, which is decoded JSON, i.e. consists of
+// map[string]interface{}, []interface{} and scalar values. Values which don't exist are skipped.
+func (p *Path) Select(document interface{}) []interface{} {
+	values := []interface{}{document}
+	for _, s := range p.segments {
+		var selected []interface{}
+		for _, value := range values {
+			selected = s.selectFrom(value, selected)
+		}
+		values = selected
+	}
+	return values
+}
+
+// Evaluate returns the value selected by a definite path, and whether it exists. Paths with wildcards return all
+// selected values as []interface{}, which always exists, but may be empty.
+func (p *Path) Evaluate(document interface{}) (interface{}, bool) {
+	values := p.Select(document)
+	if !p.definite {
+		return values, true
+	}
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// EvaluateJSON is like Evaluate, but decodes the JSON document first. Numbers are decoded as json.Number, to preserve
+// the precision of large numbers such as keys.
+func (p *Path) EvaluateJSON(document string) (interface{}, bool, error) {
+	decoder := json.NewDecoder(strings.NewReader(document))
+	decoder.UseNumber()
+
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, false, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	value, ok := p.Evaluate(decoded)
+	return value, ok, nil
+}
+
+func (s segment) selectFrom(value interface{}, selected []interface{}) []interface{} {
+	switch s.kind {
+	case property:
+		if object, ok := value.(map[string]interface{}); ok {
+			if child, ok := object[s.name]; ok {
+				selected = append(selected, child)
+			}
+		}
+	case index:
+		if array, ok := value.([]interface{}); ok {
+			i := s.index
+			if i < 0 {
+				i += len(array)
+			}
+			if i >= 0 && i < len(array) {
+				selected = append(selected, array[i])
+			}
+		}
+	case wildcard:
+		switch value := value.(type) {
+		case []interface{}:
+			selected = append(selected, value...)
+		case map[string]interface{}:
+			for _, name := range sortedKeys(value) {
+				selected = append(selected, value[name])
+			}
+		}
+	}
+	return selected
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type parser struct {
+	expression string
+	pos        int
+}
+
+func (p *parser) parse() ([]segment, error) {
+	if !strings.HasPrefix(p.expression, "$") {
+		return nil, p.errorf("expected path to start with '$'")
+	}
+	p.pos = 1
+
+	var segments []segment
+	for p.pos < len(p.expression) {
+		switch p.expression[p.pos] {
+		case '.':
+			p.pos++
+			s, err := p.parseDotSegment()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, s)
+		case '[':
+			p.pos++
+			s, err := p.parseBracketSegment()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, s)
+		default:
+			return nil, p.errorf("expected '.' or '['")
+		}
+	}
+	return segments, nil
+}
+
+func (p *parser) parseDotSegment() (segment, error) {
+	if p.pos < len(p.expression) && p.expression[p.pos] == '.' {
+		return segment{}, p.errorf("recursive descent is not supported")
+	}
+	if p.pos < len(p.expression) && p.expression[p.pos] == '*' {
+		p.pos++
+		return segment{kind: wildcard}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.expression) && isNameChar(p.expression[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return segment{}, p.errorf("expected property name")
+	}
+	return segment{kind: property, name: p.expression[start:p.pos]}, nil
+}
+
+func (p *parser) parseBracketSegment() (segment, error) {
+	if p.pos >= len(p.expression) {
+		return segment{}, p.errorf("expected index, property name or '*'")
+	}
+
+	var s segment
+	switch c := p.expression[p.pos]; {
+	case c == '*':
+		p.pos++
+		s = segment{kind: wildcard}
+	case c == '\'' || c == '"':
+		name, err := p.parseQuoted(c)
+		if err != nil {
+			return segment{}, err
+		}
+		s = segment{kind: property, name: name}
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.expression) && p.expression[p.pos] >= '0' && p.expression[p.pos] <= '9' {
+			p.pos++
+		}
+		i, err := strconv.Atoi(p.expression[start:p.pos])
+		if err != nil {
+			return segment{}, p.errorf("invalid index '%s'", p.expression[start:p.pos])
+		}
+		s = segment{kind: index, index: i}
+	default:
+		return segment{}, p.errorf("expected index, property name or '*'")
+	}
+
+	if p.pos >= len(p.expression) || p.expression[p.pos] != ']' {
+		return segment{}, p.errorf("expected ']'")
+	}
+	p.pos++
+	return s, nil
+}
+
+func (p *parser) parseQuoted(quote byte) (string, error) {
+	p.pos++
+	var name bytes.Buffer
+	for p.pos < len(p.expression) {
+		c := p.expression[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return name.String(), nil
+		case c == '\\' && p.pos+1 < len(p.expression):
+			name.WriteByte(p.expression[p.pos+1])
+			p.pos += 2
+		default:
+			name.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated property name")
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid JSONPath '%s' at position %d: %s", p.expression, p.pos, fmt.Sprintf(format, args...))
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '-' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,96 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const order = `{
+	"order": {
+		"id": "DE-42",
+		"items": [{"sku": "a", "quantity": 1}, {"sku": "b", "quantity": 2}, {"quantity": 3}],
+		"shipping address": {"city": "Berlin"}
+	}
+}`
+
+func TestSelectWithWildcard(t *testing.T) {
+	value, ok, err := MustCompile("$.order.items[*].sku").EvaluateJSON(order)
+
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{"a", "b"}, value)
+}
+
+func TestSelectDefinitePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{"$.order.id", "DE-42"},
+		{"$['order']['shipping address'].city", "Berlin"},
+		{"$.order.items[1].quantity", json.Number("2")},
+		{"$.order.items[-1].quantity", json.Number("3")},
+		{"$.order.items[0]", map[string]interface{}{"sku": "a", "quantity": json.Number("1")}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			value, ok, err := MustCompile(test.path).EvaluateJSON(order)
+
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, test.want, value)
+		})
+	}
+}
+
+func TestSelectMissingValue(t *testing.T) {
+	for _, path := range []string{"$.customer", "$.order.items[3]", "$.order.id.length", "$.order[0]"} {
+		t.Run(path, func(t *testing.T) {
+			_, ok, err := MustCompile(path).EvaluateJSON(order)
+
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestObjectWildcardSelectsPropertiesByName(t *testing.T) {
+	values := MustCompile("$.*").Select(map[string]interface{}{"b": 2, "a": 1, "c": 3})
+
+	assert.Equal(t, []interface{}{1, 2, 3}, values)
+}
+
+func TestRoot(t *testing.T) {
+	assert.Equal(t, "order", MustCompile("$.order.items[*].sku").Root())
+	assert.Equal(t, "order", MustCompile("$['order']").Root())
+	assert.Equal(t, "", MustCompile("$[*].sku").Root())
+	assert.Equal(t, "", MustCompile("$").Root())
+}
+
+func TestCompileInvalidPath(t *testing.T) {
+	for _, path := range []string{"", "order.items", "$.", "$..sku", "$[", "$[1", "$['sku", "$[?(@.sku)]", "$.order items"} {
+		t.Run(path, func(t *testing.T) {
+			_, err := Compile(path)
+
+			assert.Error(t, err)
+		})
+	}
+}
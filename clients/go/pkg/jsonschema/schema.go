@@ -0,0 +1,669 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonschema validates variables against a JSON Schema, e.g. to enforce the contract between the variables of
+// a workflow and the job workers which process them.
+//
+// The keywords which constrain the structure of variables are supported: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minimum, maximum, exclusiveMinimum, exclusiveMaximum, minLength,
+// maxLength, pattern, allOf, anyOf, oneOf, not and references to the schema itself, like '#/definitions/address'.
+// Other keywords, e.g. format, are ignored.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema. It is safe for concurrent use.
+type Schema struct {
+	root *node
+}
+
+// Compile parses the JSON Schema.
+func Compile(schema string) (*Schema, error) {
+	document, err := decode([]byte(schema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	c := &compiler{document: document, refs: make(map[string]*node)}
+	root, err := c.compile(document, "#")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCycles(root); err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+// MustCompile is like Compile, but panics if the schema cannot be compiled, e.g. to initialize global variables.
+func MustCompile(schema string) *Schema {
+	compiled, err := Compile(schema)
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}
+
+// ValidateJSON validates the JSON document against the schema and returns a *ValidationError if it does not match.
+func (s *Schema) ValidateJSON(document string) error {
+	value, err := decode([]byte(document))
+	if err != nil {
+		return fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	var violations []Violation
+	s.root.validate(value, "$", &violations)
+	if len(violations) > 0 {
+		sort.SliceStable(violations, func(i, j int) bool {
+			return violations[i].Path < violations[j].Path
+		})
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// Validate serializes the value as JSON and validates it against the schema, like ValidateJSON.
+func (s *Schema) Validate(value interface{}) error {
+	document, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to serialize document: %w", err)
+	}
+	return s.ValidateJSON(string(document))
+}
+
+// Violation is a constraint of the schema which is violated by the value at the path, e.g. '$.items[0].quantity'.
+type Violation struct {
+	Path    string
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// ValidationError is returned if a document does not match the schema. It lists all violations, ordered by path.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.String()
+	}
+	return "document does not match the schema: " + strings.Join(messages, "; ")
+}
+
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("expected a single JSON value")
+	}
+	return value, nil
+}
+
+type node struct {
+	// a schema which is true or false accepts, respectively rejects every value
+	accept *bool
+	ref    *node
+
+	types    []string
+	enum     []interface{}
+	constant interface{}
+	hasConst bool
+
+	properties           map[string]*node
+	required             []string
+	additionalProperties *node
+
+	items    *node
+	minItems *int
+	maxItems *int
+
+	minimum          *big.Rat
+	maximum          *big.Rat
+	exclusiveMinimum *big.Rat
+	exclusiveMaximum *big.Rat
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	allOf []*node
+	anyOf []*node
+	oneOf []*node
+	not   *node
+}
+
+type compiler struct {
+	document interface{}
+	refs     map[string]*node
+}
+
+func (c *compiler) compile(schema interface{}, location string) (*node, error) {
+	if accept, ok := schema.(bool); ok {
+		return &node{accept: &accept}, nil
+	}
+
+	object, ok := schema.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected schema at %s to be an object or boolean, got %v", location, schema)
+	}
+
+	n := &node{}
+	if ref, ok := object["$ref"].(string); ok {
+		target, err := c.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		n.ref = target
+	}
+
+	for keyword, value := range object {
+		if err := c.compileKeyword(n, keyword, value, location+"/"+keyword); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+func (c *compiler) compileKeyword(n *node, keyword string, value interface{}, location string) error {
+	var err error
+	switch keyword {
+	case "type":
+		n.types, err = stringList(value, location)
+	case "enum":
+		values, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected %s to be an array", location)
+		}
+		n.enum = values
+	case "const":
+		n.constant, n.hasConst = value, true
+	case "properties":
+		properties, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected %s to be an object", location)
+		}
+		n.properties = make(map[string]*node, len(properties))
+		for name, property := range properties {
+			if n.properties[name], err = c.compile(property, location+"/"+name); err != nil {
+				return err
+			}
+		}
+	case "required":
+		n.required, err = stringList(value, location)
+	case "additionalProperties":
+		n.additionalProperties, err = c.compile(value, location)
+	case "items":
+		n.items, err = c.compile(value, location)
+	case "minItems":
+		n.minItems, err = count(value, location)
+	case "maxItems":
+		n.maxItems, err = count(value, location)
+	case "minLength":
+		n.minLength, err = count(value, location)
+	case "maxLength":
+		n.maxLength, err = count(value, location)
+	case "minimum":
+		n.minimum, err = number(value, location)
+	case "maximum":
+		n.maximum, err = number(value, location)
+	case "exclusiveMinimum":
+		n.exclusiveMinimum, err = number(value, location)
+	case "exclusiveMaximum":
+		n.exclusiveMaximum, err = number(value, location)
+	case "pattern":
+		pattern, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected %s to be a string", location)
+		}
+		if n.pattern, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("failed to compile %s: %w", location, err)
+		}
+	case "allOf":
+		n.allOf, err = c.compileList(value, location)
+	case "anyOf":
+		n.anyOf, err = c.compileList(value, location)
+	case "oneOf":
+		n.oneOf, err = c.compileList(value, location)
+	case "not":
+		n.not, err = c.compile(value, location)
+	}
+	return err
+}
+
+func (c *compiler) compileList(value interface{}, location string) ([]*node, error) {
+	schemas, ok := value.([]interface{})
+	if !ok || len(schemas) == 0 {
+		return nil, fmt.Errorf("expected %s to be a non-empty array", location)
+	}
+
+	nodes := make([]*node, len(schemas))
+	for i, schema := range schemas {
+		var err error
+		if nodes[i], err = c.compile(schema, location+"/"+strconv.Itoa(i)); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// resolve compiles the schema which is referenced by the JSON pointer. The node is registered before it is compiled,
+// so recursive schemas refer to the same node.
+func (c *compiler) resolve(ref string) (*node, error) {
+	if target, ok := c.refs[ref]; ok {
+		return target, nil
+	}
+	if ref != "#" && !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("expected reference %q to refer to the schema itself", ref)
+	}
+
+	schema := c.document
+	for _, token := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		switch parent := schema.(type) {
+		case map[string]interface{}:
+			schema = parent[token]
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(parent) {
+				return nil, fmt.Errorf("expected reference %q to exist", ref)
+			}
+			schema = parent[index]
+		default:
+			schema = nil
+		}
+		if schema == nil {
+			return nil, fmt.Errorf("expected reference %q to exist", ref)
+		}
+	}
+
+	target := &node{}
+	c.refs[ref] = target
+	compiled, err := c.compile(schema, ref)
+	if err != nil {
+		return nil, err
+	}
+	*target = *compiled
+	return target, nil
+}
+
+// checkCycles rejects schemas which refer to themselves for the same value, e.g. {"$ref":"#"} or
+// {"allOf":[{"$ref":"#"}]}, since their validation would never end. Recursive schemas have to descend into a property
+// or item before they refer to themselves again.
+func checkCycles(root *node) error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[*node]int)
+
+	var visit func(n *node) error
+	visit = func(n *node) error {
+		switch state[n] {
+		case visiting:
+			return fmt.Errorf("expected schema to descend into a property or item before it refers to itself, but it refers to itself for the same value")
+		case visited:
+			return nil
+		}
+
+		state[n] = visiting
+		for _, next := range n.sameValueSchemas() {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		state[n] = visited
+		return nil
+	}
+
+	// the nodes of nested values start new chains of references, so they are visited once each
+	pending := []*node{root}
+	seen := map[*node]bool{root: true}
+	for len(pending) > 0 {
+		n := pending[0]
+		pending = pending[1:]
+
+		if err := visit(n); err != nil {
+			return err
+		}
+		for _, next := range append(n.sameValueSchemas(), n.nestedValueSchemas()...) {
+			if !seen[next] {
+				seen[next] = true
+				pending = append(pending, next)
+			}
+		}
+	}
+	return nil
+}
+
+// sameValueSchemas are the schemas which validate the same value as the node.
+func (n *node) sameValueSchemas() []*node {
+	var schemas []*node
+	if n.ref != nil {
+		schemas = append(schemas, n.ref)
+	}
+	schemas = append(schemas, n.allOf...)
+	schemas = append(schemas, n.anyOf...)
+	schemas = append(schemas, n.oneOf...)
+	if n.not != nil {
+		schemas = append(schemas, n.not)
+	}
+	return schemas
+}
+
+// nestedValueSchemas are the schemas which validate the properties or items of the value of the node.
+func (n *node) nestedValueSchemas() []*node {
+	var schemas []*node
+	for _, property := range n.properties {
+		schemas = append(schemas, property)
+	}
+	if n.additionalProperties != nil {
+		schemas = append(schemas, n.additionalProperties)
+	}
+	if n.items != nil {
+		schemas = append(schemas, n.items)
+	}
+	return schemas
+}
+
+func stringList(value interface{}, location string) ([]string, error) {
+	if single, ok := value.(string); ok {
+		return []string{single}, nil
+	}
+
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected %s to be a string or an array of strings", location)
+	}
+	list := make([]string, len(values))
+	for i, value := range values {
+		if list[i], ok = value.(string); !ok {
+			return nil, fmt.Errorf("expected %s to be a string or an array of strings", location)
+		}
+	}
+	return list, nil
+}
+
+func count(value interface{}, location string) (*int, error) {
+	n, ok := value.(json.Number)
+	if ok {
+		if i, err := n.Int64(); err == nil && i >= 0 {
+			c := int(i)
+			return &c, nil
+		}
+	}
+	return nil, fmt.Errorf("expected %s to be a non-negative integer", location)
+}
+
+func number(value interface{}, location string) (*big.Rat, error) {
+	if n, ok := value.(json.Number); ok {
+		if r, ok := new(big.Rat).SetString(n.String()); ok {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("expected %s to be a number", location)
+}
+
+func (n *node) validate(value interface{}, path string, violations *[]Violation) {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.accept != nil {
+		if !*n.accept {
+			report("expected no value")
+		}
+		return
+	}
+	if n.ref != nil {
+		n.ref.validate(value, path, violations)
+	}
+
+	if len(n.types) > 0 && !matchesType(value, n.types) {
+		report("expected %s, got %s", strings.Join(n.types, " or "), typeOf(value))
+		return
+	}
+	if n.enum != nil && !containsValue(n.enum, value) {
+		report("expected one of %s, got %s", encode(n.enum), encode(value))
+	}
+	if n.hasConst && !equal(n.constant, value) {
+		report("expected %s, got %s", encode(n.constant), encode(value))
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		n.validateObject(value, path, violations, report)
+	case []interface{}:
+		n.validateArray(value, path, violations, report)
+	case string:
+		n.validateString(value, report)
+	case json.Number:
+		n.validateNumber(value, report)
+	}
+
+	for _, schema := range n.allOf {
+		schema.validate(value, path, violations)
+	}
+	if n.anyOf != nil && countMatches(n.anyOf, value) == 0 {
+		report("expected to match any of the schemas")
+	}
+	if n.oneOf != nil {
+		if matches := countMatches(n.oneOf, value); matches != 1 {
+			report("expected to match exactly one of the schemas, matched %d", matches)
+		}
+	}
+	if n.not != nil && countMatches([]*node{n.not}, value) == 1 {
+		report("expected not to match the schema")
+	}
+}
+
+func (n *node) validateObject(object map[string]interface{}, path string, violations *[]Violation, report func(string, ...interface{})) {
+	for _, name := range n.required {
+		if _, ok := object[name]; !ok {
+			report("missing required property %q", name)
+		}
+	}
+
+	for name, value := range object {
+		if schema, ok := n.properties[name]; ok {
+			schema.validate(value, propertyPath(path, name), violations)
+		} else if n.additionalProperties != nil {
+			if accept := n.additionalProperties.accept; accept != nil && !*accept {
+				report("unexpected property %q", name)
+				continue
+			}
+			n.additionalProperties.validate(value, propertyPath(path, name), violations)
+		}
+	}
+}
+
+func (n *node) validateArray(array []interface{}, path string, violations *[]Violation, report func(string, ...interface{})) {
+	if n.minItems != nil && len(array) < *n.minItems {
+		report("expected at least %d items, got %d", *n.minItems, len(array))
+	}
+	if n.maxItems != nil && len(array) > *n.maxItems {
+		report("expected at most %d items, got %d", *n.maxItems, len(array))
+	}
+
+	if n.items != nil {
+		for i, item := range array {
+			n.items.validate(item, fmt.Sprintf("%s[%d]", path, i), violations)
+		}
+	}
+}
+
+func (n *node) validateString(value string, report func(string, ...interface{})) {
+	length := utf8.RuneCountInString(value)
+	if n.minLength != nil && length < *n.minLength {
+		report("expected at least %d characters, got %d", *n.minLength, length)
+	}
+	if n.maxLength != nil && length > *n.maxLength {
+		report("expected at most %d characters, got %d", *n.maxLength, length)
+	}
+	if n.pattern != nil && !n.pattern.MatchString(value) {
+		report("expected to match pattern %q, got %q", n.pattern.String(), value)
+	}
+}
+
+func (n *node) validateNumber(value json.Number, report func(string, ...interface{})) {
+	r, ok := new(big.Rat).SetString(value.String())
+	if !ok {
+		return
+	}
+
+	if n.minimum != nil && r.Cmp(n.minimum) < 0 {
+		report("expected at least %s, got %s", n.minimum.RatString(), value)
+	}
+	if n.maximum != nil && r.Cmp(n.maximum) > 0 {
+		report("expected at most %s, got %s", n.maximum.RatString(), value)
+	}
+	if n.exclusiveMinimum != nil && r.Cmp(n.exclusiveMinimum) <= 0 {
+		report("expected more than %s, got %s", n.exclusiveMinimum.RatString(), value)
+	}
+	if n.exclusiveMaximum != nil && r.Cmp(n.exclusiveMaximum) >= 0 {
+		report("expected less than %s, got %s", n.exclusiveMaximum.RatString(), value)
+	}
+}
+
+func countMatches(schemas []*node, value interface{}) int {
+	matches := 0
+	for _, schema := range schemas {
+		var violations []Violation
+		schema.validate(value, "", &violations)
+		if len(violations) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func propertyPath(path, name string) string {
+	if identifier.MatchString(name) {
+		return path + "." + name
+	}
+	return path + "[" + strconv.Quote(name) + "]"
+}
+
+func matchesType(value interface{}, types []string) bool {
+	actual := typeOf(value)
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func typeOf(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number:
+		if r, ok := new(big.Rat).SetString(value.String()); ok && r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if equal(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares JSON values, where numbers are equal if they have the same value, e.g. 1 and 1.0.
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okX := new(big.Rat).SetString(a.String())
+		y, okY := new(big.Rat).SetString(b.String())
+		return okX && okY && x.Cmp(y) == 0
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for name, value := range a {
+			other, ok := b[name]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func encode(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
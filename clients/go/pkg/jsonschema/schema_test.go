@@ -0,0 +1,144 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderSchema = `{
+	"type": "object",
+	"required": ["orderId", "items"],
+	"properties": {
+		"orderId": {"type": "string", "pattern": "^[A-Z]{2}-[0-9]+$"},
+		"priority": {"enum": ["low", "high"]},
+		"items": {
+			"type": "array",
+			"minItems": 1,
+			"items": {"$ref": "#/definitions/item"}
+		}
+	},
+	"additionalProperties": false,
+	"definitions": {
+		"item": {
+			"type": "object",
+			"required": ["sku", "quantity"],
+			"properties": {
+				"sku": {"type": "string", "minLength": 1},
+				"quantity": {"type": "integer", "exclusiveMinimum": 0}
+			}
+		}
+	}
+}`
+
+func TestValidDocument(t *testing.T) {
+	schema := MustCompile(orderSchema)
+
+	err := schema.ValidateJSON(`{"orderId":"DE-42","priority":"high","items":[{"sku":"a","quantity":2.0}]}`)
+
+	assert.NoError(t, err)
+}
+
+func TestInvalidDocumentReportsAllViolationsWithPaths(t *testing.T) {
+	schema := MustCompile(orderSchema)
+
+	err := schema.ValidateJSON(`{"orderId":"42","priority":"urgent","items":[{"sku":"a","quantity":1},{"sku":"","quantity":0.5}],"note":"x"}`)
+
+	require.IsType(t, &ValidationError{}, err)
+	assert.Equal(t, []Violation{
+		{Path: "$", Message: `unexpected property "note"`},
+		{Path: "$.items[1].quantity", Message: "expected integer, got number"},
+		{Path: "$.items[1].sku", Message: "expected at least 1 characters, got 0"},
+		{Path: "$.orderId", Message: `expected to match pattern "^[A-Z]{2}-[0-9]+$", got "42"`},
+		{Path: "$.priority", Message: `expected one of ["low","high"], got "urgent"`},
+	}, err.(*ValidationError).Violations)
+}
+
+func TestMissingRequiredProperty(t *testing.T) {
+	schema := MustCompile(orderSchema)
+
+	err := schema.Validate(map[string]interface{}{"orderId": "DE-1"})
+
+	require.IsType(t, &ValidationError{}, err)
+	assert.Equal(t, []Violation{{Path: "$", Message: `missing required property "items"`}}, err.(*ValidationError).Violations)
+	assert.EqualError(t, err, `document does not match the schema: $: missing required property "items"`)
+}
+
+func TestCombinedSchemas(t *testing.T) {
+	schema := MustCompile(`{
+		"oneOf": [{"type": "integer"}, {"type": "number", "maximum": 10}],
+		"not": {"const": 3}
+	}`)
+
+	assert.NoError(t, schema.ValidateJSON(`12`))
+	assert.NoError(t, schema.ValidateJSON(`0.5`))
+	assert.Error(t, schema.ValidateJSON(`4`), "matches both schemas")
+	assert.Error(t, schema.ValidateJSON(`3.0`), "is excluded")
+	assert.Error(t, schema.ValidateJSON(`"a"`))
+}
+
+func TestRecursiveSchema(t *testing.T) {
+	schema := MustCompile(`{
+		"type": "object",
+		"properties": {"name": {"type": "string"}, "children": {"type": "array", "items": {"$ref": "#"}}}
+	}`)
+
+	err := schema.ValidateJSON(`{"name":"a","children":[{"name":"b","children":[{"name":1}]}]}`)
+
+	require.IsType(t, &ValidationError{}, err)
+	assert.Equal(t, "$.children[0].children[0].name", err.(*ValidationError).Violations[0].Path)
+}
+
+func TestInvalidSchema(t *testing.T) {
+	for _, schema := range []string{
+		`{"type": 1}`,
+		`{"minItems": -1}`,
+		`{"pattern": "("}`,
+		`{"$ref": "#/definitions/missing"}`,
+		`{"$ref": "http://example.com/schema"}`,
+		`{"anyOf": []}`,
+		`[]`,
+		`{"type": "string"}]`,
+		`{"type": "string"}}`,
+		`{"type": "string"} {}`,
+	} {
+		_, err := Compile(schema)
+		assert.Error(t, err, schema)
+	}
+}
+
+func TestSchemaReferringToItselfForTheSameValue(t *testing.T) {
+	for _, schema := range []string{
+		`{"$ref": "#"}`,
+		`{"allOf": [{"$ref": "#"}]}`,
+		`{"properties": {"a": {"anyOf": [{"$ref": "#/properties/a"}]}}}`,
+		`{"definitions": {"a": {"$ref": "#/definitions/b"}, "b": {"not": {"$ref": "#/definitions/a"}}}, "$ref": "#/definitions/a"}`,
+	} {
+		_, err := Compile(schema)
+		assert.Error(t, err, schema)
+	}
+}
+
+func TestDocumentWithTrailingData(t *testing.T) {
+	schema := MustCompile(`{"type": "object"}`)
+
+	for _, document := range []string{`{}]`, `{}}`, `{} {}`} {
+		assert.Error(t, schema.ValidateJSON(document), document)
+	}
+	assert.NoError(t, schema.ValidateJSON("{}\n"))
+}
@@ -0,0 +1,111 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging defines the structured logger used by the client and the job workers.
+package logging
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Logger logs messages with alternating keys and values as fields, e.g.
+//
+//	logger.Warn("Failed to activate jobs", "jobType", "payment", "error", err)
+//
+// The method set matches the one of *slog.Logger in Go 1.21, so it can be used as Logger as well.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// Level is the minimum severity of the messages written by a logger created with NewStdLogger.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{LevelDebug: "DEBUG", LevelInfo: "INFO", LevelWarn: "WARN", LevelError: "ERROR"}
+
+// Default writes messages of level info and above to the standard logger of the log package.
+var Default = NewStdLogger(nil, LevelInfo)
+
+// Discard drops all messages.
+var Discard Logger = discardLogger{}
+
+type stdLogger struct {
+	logger *log.Logger
+	level  Level
+}
+
+// NewStdLogger creates a Logger which writes messages of the given level and above as a single line to the logger,
+// e.g. 'WARN Failed to activate jobs jobType=payment error=...'. If the logger is nil, the standard logger of the log
+// package is used.
+func NewStdLogger(logger *log.Logger, level Level) Logger {
+	return &stdLogger{logger: logger, level: level}
+}
+
+func (l *stdLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.log(LevelDebug, msg, keysAndValues)
+}
+
+func (l *stdLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.log(LevelInfo, msg, keysAndValues)
+}
+
+func (l *stdLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log(LevelWarn, msg, keysAndValues)
+}
+
+func (l *stdLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.log(LevelError, msg, keysAndValues)
+}
+
+func (l *stdLogger) log(level Level, msg string, keysAndValues []interface{}) {
+	if level < l.level {
+		return
+	}
+
+	var line strings.Builder
+	line.WriteString(levelNames[level])
+	line.WriteString(" ")
+	line.WriteString(msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 < len(keysAndValues) {
+			_, _ = fmt.Fprintf(&line, " %v=%v", keysAndValues[i], keysAndValues[i+1])
+		} else {
+			_, _ = fmt.Fprintf(&line, " %v", keysAndValues[i])
+		}
+	}
+
+	if l.logger != nil {
+		l.logger.Println(line.String())
+	} else {
+		log.Println(line.String())
+	}
+}
+
+type discardLogger struct{}
+
+func (discardLogger) Debug(string, ...interface{}) {}
+func (discardLogger) Info(string, ...interface{})  {}
+func (discardLogger) Warn(string, ...interface{})  {}
+func (discardLogger) Error(string, ...interface{}) {}
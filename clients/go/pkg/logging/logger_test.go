@@ -0,0 +1,50 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdLoggerWritesFields(t *testing.T) {
+	// given
+	var out bytes.Buffer
+	logger := NewStdLogger(log.New(&out, "", 0), LevelDebug)
+
+	// when
+	logger.Warn("Failed to activate jobs", "jobType", "payment", "error", errors.New("unavailable"))
+
+	// then
+	assert.Equal(t, "WARN Failed to activate jobs jobType=payment error=unavailable\n", out.String())
+}
+
+func TestStdLoggerSkipsMessagesBelowLevel(t *testing.T) {
+	// given
+	var out bytes.Buffer
+	logger := NewStdLogger(log.New(&out, "", 0), LevelWarn)
+
+	// when
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Error("error", "key")
+
+	// then
+	assert.Equal(t, "ERROR error key\n", out.String())
+}
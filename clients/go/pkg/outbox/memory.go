@@ -0,0 +1,89 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryStore is a Store which keeps the entries in memory, e.g. for tests. Entries are lost when the process exits,
+// so it does not provide the guarantees of an outbox.
+type MemoryStore struct {
+	lock    sync.Mutex
+	entries []*memoryEntry
+}
+
+type memoryEntry struct {
+	Entry
+	dispatched bool
+	lastError  error
+}
+
+// Enqueue adds the command to the store and returns the id of the entry.
+func (s *MemoryStore) Enqueue(command Command) (string, error) {
+	entry, err := NewEntry(command)
+	if err != nil {
+		return "", err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.entries = append(s.entries, &memoryEntry{Entry: entry})
+	return entry.ID, nil
+}
+
+func (s *MemoryStore) Pending(_ context.Context, limit, maxAttempts int) ([]Entry, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var entries []Entry
+	for _, entry := range s.entries {
+		if len(entries) == limit {
+			break
+		}
+		if !entry.dispatched && entry.Attempts < maxAttempts {
+			entries = append(entries, entry.Entry)
+		}
+	}
+	return entries, nil
+}
+
+func (s *MemoryStore) Dispatched(_ context.Context, id string) error {
+	return s.update(id, func(entry *memoryEntry) {
+		entry.dispatched = true
+	})
+}
+
+func (s *MemoryStore) Failed(_ context.Context, id string, err error) error {
+	return s.update(id, func(entry *memoryEntry) {
+		entry.Attempts++
+		entry.lastError = err
+	})
+}
+
+func (s *MemoryStore) update(id string, update func(*memoryEntry)) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, entry := range s.entries {
+		if entry.ID == id {
+			update(entry)
+			return nil
+		}
+	}
+	return fmt.Errorf("expected to find outbox entry %s, but it does not exist", id)
+}
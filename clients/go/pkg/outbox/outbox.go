@@ -0,0 +1,128 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package outbox dispatches commands to Zeebe with the transactional outbox pattern: commands are stored with the
+// changes of the application in the same database transaction, and a Relay sends them to the gateway afterwards. If
+// the transaction is rolled back, no command is sent; if it is committed, the command is sent at least once.
+//
+// Published messages use the outbox entry id as message id if none is set, so the broker ignores duplicates while the
+// message is buffered. Completing a job which was already completed fails with NOT_FOUND and is treated as
+// dispatched. Workflow instances have no idempotency key in the gateway protocol, so an instance may be created twice
+// if the relay fails after the gateway accepted the command.
+package outbox
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	KindPublishMessage = "PublishMessage"
+	KindCreateInstance = "CreateInstance"
+	KindCompleteJob    = "CompleteJob"
+)
+
+// Command is a command which can be stored in the outbox: PublishMessage, CreateInstance or CompleteJob.
+type Command interface {
+	kind() string
+}
+
+// PublishMessage publishes a message like zbc.Client.NewPublishMessageCommand.
+type PublishMessage struct {
+	Name           string        `json:"name"`
+	CorrelationKey string        `json:"correlationKey"`
+	MessageID      string        `json:"messageId,omitempty"`
+	TimeToLive     time.Duration `json:"timeToLive,omitempty"`
+	Variables      interface{}   `json:"variables,omitempty"`
+}
+
+func (PublishMessage) kind() string {
+	return KindPublishMessage
+}
+
+// CreateInstance creates a workflow instance of the latest version of the workflow, or of the given version, like
+// zbc.Client.NewCreateInstanceCommand.
+type CreateInstance struct {
+	BpmnProcessID string      `json:"bpmnProcessId"`
+	Version       int32       `json:"version,omitempty"`
+	Variables     interface{} `json:"variables,omitempty"`
+}
+
+func (CreateInstance) kind() string {
+	return KindCreateInstance
+}
+
+// CompleteJob completes a job like zbc.Client.NewCompleteJobCommand.
+type CompleteJob struct {
+	JobKey    int64       `json:"jobKey"`
+	Variables interface{} `json:"variables,omitempty"`
+}
+
+func (CompleteJob) kind() string {
+	return KindCompleteJob
+}
+
+// Entry is a command stored in the outbox.
+type Entry struct {
+	ID string
+	// Kind of the command, e.g. KindPublishMessage
+	Kind string
+	// Payload is the command encoded as JSON
+	Payload []byte
+	// Attempts is the number of times the relay failed to dispatch the command
+	Attempts int
+}
+
+// NewEntry encodes the command as outbox entry with a new random id.
+func NewEntry(command Command) (Entry, error) {
+	payload, err := json.Marshal(command)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to encode %s command: %w", command.kind(), err)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Entry{}, fmt.Errorf("failed to generate outbox entry id: %w", err)
+	}
+
+	return Entry{ID: hex.EncodeToString(id), Kind: command.kind(), Payload: payload}, nil
+}
+
+// Command decodes the command of the entry. Numbers of the variables are decoded as json.Number, so they are sent
+// without losing precision.
+func (e Entry) Command() (Command, error) {
+	var command Command
+	switch e.Kind {
+	case KindPublishMessage:
+		command = &PublishMessage{}
+	case KindCreateInstance:
+		command = &CreateInstance{}
+	case KindCompleteJob:
+		command = &CompleteJob{}
+	default:
+		return nil, fmt.Errorf("unknown kind '%s' of outbox entry %s", e.Kind, e.ID)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(e.Payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(command); err != nil {
+		return nil, fmt.Errorf("failed to decode outbox entry %s: %w", e.ID, err)
+	}
+
+	return command, nil
+}
@@ -0,0 +1,49 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbox
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryCommand(t *testing.T) {
+	// given
+	entry, err := NewEntry(PublishMessage{Name: "foo", CorrelationKey: "bar", TimeToLive: time.Minute, Variables: map[string]int64{"key": 2251799813685249}})
+	require.NoError(t, err)
+
+	// when
+	command, err := entry.Command()
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, &PublishMessage{
+		Name:           "foo",
+		CorrelationKey: "bar",
+		TimeToLive:     time.Minute,
+		Variables:      map[string]interface{}{"key": json.Number("2251799813685249")},
+	}, command)
+}
+
+func TestEntryCommandWithUnknownKind(t *testing.T) {
+	// when
+	_, err := Entry{ID: "1", Kind: "Unknown", Payload: []byte("{}")}.Command()
+
+	// then
+	require.Error(t, err)
+}
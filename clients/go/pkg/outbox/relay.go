@@ -0,0 +1,230 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+)
+
+const (
+	// DefaultInterval is used by the Relay if no interval is set.
+	DefaultInterval = time.Second
+	// DefaultBatchSize is used by the Relay if no batch size is set.
+	DefaultBatchSize = 100
+	// DefaultMaxAttempts is used by the Relay if no maximum number of attempts is set.
+	DefaultMaxAttempts = 10
+	// DefaultRequestTimeout is used by the Relay if no request timeout is set.
+	DefaultRequestTimeout = 10 * time.Second
+)
+
+// Store reads the entries of the outbox for the Relay. Adding entries is specific to the store, as it has to take
+// part in the transaction of the application, see SQLStore.Enqueue.
+type Store interface {
+	// Pending returns up to limit entries which were not dispatched yet and failed less than maxAttempts times, in
+	// the order in which they were added
+	Pending(ctx context.Context, limit, maxAttempts int) ([]Entry, error)
+	// Dispatched marks the entry as dispatched, so it is not returned as pending anymore
+	Dispatched(ctx context.Context, id string) error
+	// Failed increments the attempts of the entry and records the error
+	Failed(ctx context.Context, id string, err error) error
+}
+
+// Client sends the commands of the outbox, e.g. the client of the zbc package.
+type Client interface {
+	NewPublishMessageCommand() commands.PublishMessageCommandStep1
+	NewCreateInstanceCommand() commands.CreateInstanceCommandStep1
+	NewCompleteJobCommand() commands.CompleteJobCommandStep1
+}
+
+// Relay dispatches the pending entries of a Store to the gateway. Only one relay should read a store at a time, as
+// entries are not locked while they are dispatched.
+type Relay struct {
+	Store  Store
+	Client Client
+	// Interval between polling the store for pending entries, DefaultInterval if zero
+	Interval time.Duration
+	// BatchSize is the maximum number of entries read at once, DefaultBatchSize if zero
+	BatchSize int
+	// MaxAttempts is the number of failed attempts after which an entry is not dispatched anymore, DefaultMaxAttempts
+	// if zero. Entries which exceeded it stay in the store to be inspected.
+	MaxAttempts int
+	// RequestTimeout of every command, DefaultRequestTimeout if zero
+	RequestTimeout time.Duration
+	// Logger, logging.Default if nil
+	Logger logging.Logger
+}
+
+// Run dispatches pending entries until the context is done, in which case it returns the error of the context.
+func (r *Relay) Run(ctx context.Context) error {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.DispatchPending(ctx); err != nil && ctx.Err() == nil {
+			r.logger().Warn("Failed to dispatch outbox entries", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchPending dispatches one batch of pending entries, in order, and returns how many were dispatched. An entry
+// which fails is recorded as failed and retried with the next batch; it does not stop the following entries.
+func (r *Relay) DispatchPending(ctx context.Context) (int, error) {
+	entries, err := r.Store.Pending(ctx, r.batchSize(), r.maxAttempts())
+	if err != nil {
+		return 0, fmt.Errorf("failed to read pending outbox entries: %w", err)
+	}
+
+	dispatched := 0
+	for _, entry := range entries {
+		if err := r.dispatch(ctx, entry); err != nil {
+			r.logger().Warn("Failed to dispatch outbox entry", "id", entry.ID, "kind", entry.Kind, "attempts", entry.Attempts+1, "error", err)
+			if err := r.Store.Failed(ctx, entry.ID, err); err != nil {
+				return dispatched, fmt.Errorf("failed to record failure of outbox entry %s: %w", entry.ID, err)
+			}
+			continue
+		}
+
+		if err := r.Store.Dispatched(ctx, entry.ID); err != nil {
+			return dispatched, fmt.Errorf("failed to mark outbox entry %s as dispatched: %w", entry.ID, err)
+		}
+		dispatched++
+	}
+
+	return dispatched, nil
+}
+
+func (r *Relay) dispatch(ctx context.Context, entry Entry) error {
+	command, err := entry.Command()
+	if err != nil {
+		return err
+	}
+
+	timeout := r.RequestTimeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+
+	switch command := command.(type) {
+	case *PublishMessage:
+		return r.publishMessage(ctx, entry, command, timeout)
+	case *CreateInstance:
+		return r.createInstance(ctx, command, timeout)
+	case *CompleteJob:
+		return r.completeJob(ctx, command, timeout)
+	default:
+		return fmt.Errorf("unexpected command %T of outbox entry %s", command, entry.ID)
+	}
+}
+
+func (r *Relay) publishMessage(ctx context.Context, entry Entry, message *PublishMessage, timeout time.Duration) error {
+	messageID := message.MessageID
+	if messageID == "" {
+		messageID = entry.ID
+	}
+
+	command := r.Client.NewPublishMessageCommand().MessageName(message.Name).CorrelationKey(message.CorrelationKey).MessageId(messageID)
+	if message.TimeToLive > 0 {
+		command = command.TimeToLive(message.TimeToLive)
+	}
+	if message.Variables != nil {
+		var err error
+		if command, err = command.VariablesFromObject(message.Variables); err != nil {
+			return err
+		}
+	}
+
+	_, err := command.RequestTimeout(timeout).Send(ctx)
+	if status.Code(err) == codes.AlreadyExists {
+		return nil
+	}
+	return err
+}
+
+func (r *Relay) createInstance(ctx context.Context, instance *CreateInstance, timeout time.Duration) error {
+	step2 := r.Client.NewCreateInstanceCommand().BPMNProcessId(instance.BpmnProcessID)
+	var command commands.CreateInstanceCommandStep3
+	if instance.Version > 0 {
+		command = step2.Version(instance.Version)
+	} else {
+		command = step2.LatestVersion()
+	}
+	if instance.Variables != nil {
+		var err error
+		if command, err = command.VariablesFromObject(instance.Variables); err != nil {
+			return err
+		}
+	}
+
+	_, err := command.RequestTimeout(timeout).Send(ctx)
+	return err
+}
+
+func (r *Relay) completeJob(ctx context.Context, job *CompleteJob, timeout time.Duration) error {
+	step2 := r.Client.NewCompleteJobCommand().JobKey(job.JobKey)
+	var command commands.DispatchCompleteJobCommand = step2
+	if job.Variables != nil {
+		var err error
+		if command, err = step2.VariablesFromObject(job.Variables); err != nil {
+			return err
+		}
+	}
+
+	_, err := command.RequestTimeout(timeout).Send(ctx)
+	if status.Code(err) == codes.NotFound {
+		r.logger().Info("Job of outbox entry was already completed or canceled", "jobKey", job.JobKey)
+		return nil
+	}
+	return err
+}
+
+func (r *Relay) batchSize() int {
+	if r.BatchSize > 0 {
+		return r.BatchSize
+	}
+	return DefaultBatchSize
+}
+
+func (r *Relay) maxAttempts() int {
+	if r.MaxAttempts > 0 {
+		return r.MaxAttempts
+	}
+	return DefaultMaxAttempts
+}
+
+func (r *Relay) logger() logging.Logger {
+	if r.Logger != nil {
+		return r.Logger
+	}
+	return logging.Default
+}
@@ -0,0 +1,139 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type gatewayClient struct {
+	gateway pb.GatewayClient
+}
+
+func (c gatewayClient) NewPublishMessageCommand() commands.PublishMessageCommandStep1 {
+	return commands.NewPublishMessageCommand(c.gateway, noRetry)
+}
+
+func (c gatewayClient) NewCreateInstanceCommand() commands.CreateInstanceCommandStep1 {
+	return commands.NewCreateInstanceCommand(c.gateway, noRetry)
+}
+
+func (c gatewayClient) NewCompleteJobCommand() commands.CompleteJobCommandStep1 {
+	return commands.NewCompleteJobCommand(c.gateway, noRetry)
+}
+
+func noRetry(context.Context, error) bool {
+	return false
+}
+
+func TestRelayDispatchesPendingEntriesInOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := &MemoryStore{}
+	messageID, err := store.Enqueue(PublishMessage{Name: "order-placed", CorrelationKey: "order-1", Variables: map[string]interface{}{"total": 12345678901234}})
+	require.NoError(t, err)
+	_, err = store.Enqueue(CreateInstance{BpmnProcessID: "shipping", Version: 2})
+	require.NoError(t, err)
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	publishMessage := &pb.PublishMessageRequest{Name: "order-placed", CorrelationKey: "order-1", MessageId: messageID, Variables: `{"total":12345678901234}`}
+	createInstance := &pb.CreateWorkflowInstanceRequest{BpmnProcessId: "shipping", Version: 2}
+	gomock.InOrder(
+		gateway.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: publishMessage}).Return(&pb.PublishMessageResponse{}, nil),
+		gateway.EXPECT().CreateWorkflowInstance(gomock.Any(), &utils.RPCTestMsg{Msg: createInstance}).Return(&pb.CreateWorkflowInstanceResponse{}, nil),
+	)
+	relay := &Relay{Store: store, Client: gatewayClient{gateway}}
+
+	// when
+	dispatched, err := relay.DispatchPending(context.Background())
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 2, dispatched)
+	pending, err := store.Pending(context.Background(), DefaultBatchSize, DefaultMaxAttempts)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestRelayRetriesFailedEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := &MemoryStore{}
+	_, err := store.Enqueue(CreateInstance{BpmnProcessID: "shipping"})
+	require.NoError(t, err)
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	gateway.EXPECT().CreateWorkflowInstance(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "expected")).Times(2)
+	relay := &Relay{Store: store, Client: gatewayClient{gateway}, MaxAttempts: 2}
+
+	// when
+	for i := 0; i < 3; i++ {
+		dispatched, err := relay.DispatchPending(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 0, dispatched)
+	}
+
+	// then
+	require.Equal(t, 2, store.entries[0].Attempts)
+	require.Equal(t, codes.Unavailable, status.Code(store.entries[0].lastError))
+}
+
+func TestRelayTreatsCompletedJobAsDispatched(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := &MemoryStore{}
+	_, err := store.Enqueue(CompleteJob{JobKey: 123})
+	require.NoError(t, err)
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	request := &pb.CompleteJobRequest{JobKey: 123}
+	gateway.EXPECT().CompleteJob(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(nil, status.Error(codes.NotFound, "expected"))
+	relay := &Relay{Store: store, Client: gatewayClient{gateway}}
+
+	// when
+	dispatched, err := relay.DispatchPending(context.Background())
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 1, dispatched)
+}
+
+func TestRelayRunStopsWhenContextIsDone(t *testing.T) {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	relay := &Relay{Store: &MemoryStore{}}
+
+	// when
+	err := relay.Run(ctx)
+
+	// then
+	require.True(t, errors.Is(err, context.Canceled))
+}
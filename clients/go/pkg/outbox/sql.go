@@ -0,0 +1,132 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DefaultTable is used by the SQLStore if no table is set.
+const DefaultTable = "zeebe_outbox"
+
+// Placeholder returns the placeholder of the query parameter with the given index, starting at 1.
+type Placeholder func(index int) string
+
+// QuestionMark is the placeholder of e.g. MySQL and SQLite.
+func QuestionMark(int) string {
+	return "?"
+}
+
+// Dollar is the placeholder of PostgreSQL.
+func Dollar(index int) string {
+	return "$" + strconv.Itoa(index)
+}
+
+// Execer executes statements, e.g. a *sql.Tx of the application or a *sql.DB.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// SQLStore is a Store in a table of a SQL database. The table is created with the statement of CreateTable.
+type SQLStore struct {
+	DB *sql.DB
+	// Table, DefaultTable if empty
+	Table string
+	// Placeholder of the database, QuestionMark if nil
+	Placeholder Placeholder
+}
+
+// CreateTable returns a statement which creates the table of the store. The column types are supported by common
+// databases, but can be adapted, e.g. to use a JSON column for the payload.
+func (s *SQLStore) CreateTable() string {
+	return "CREATE TABLE " + s.table() + " (" +
+		"id VARCHAR(32) NOT NULL PRIMARY KEY, " +
+		"seq BIGINT NOT NULL, " +
+		"kind VARCHAR(32) NOT NULL, " +
+		"payload TEXT NOT NULL, " +
+		"attempts INTEGER NOT NULL DEFAULT 0, " +
+		"last_error TEXT, " +
+		"dispatched BOOLEAN NOT NULL DEFAULT FALSE)"
+}
+
+// Enqueue adds the command to the outbox with the given transaction of the application, so it is only dispatched if
+// the transaction is committed. It returns the id of the entry. Entries are dispatched in the order of the time at
+// which they were enqueued.
+func (s *SQLStore) Enqueue(ctx context.Context, tx Execer, command Command) (string, error) {
+	entry, err := NewEntry(command)
+	if err != nil {
+		return "", err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (id, seq, kind, payload) VALUES (%s, %s, %s, %s)", s.table(), s.param(1), s.param(2), s.param(3), s.param(4))
+	if _, err := tx.ExecContext(ctx, query, entry.ID, time.Now().UnixNano(), entry.Kind, string(entry.Payload)); err != nil {
+		return "", fmt.Errorf("failed to add %s command to outbox: %w", entry.Kind, err)
+	}
+
+	return entry.ID, nil
+}
+
+func (s *SQLStore) Pending(ctx context.Context, limit, maxAttempts int) ([]Entry, error) {
+	query := fmt.Sprintf("SELECT id, kind, payload, attempts FROM %s WHERE dispatched = %s AND attempts < %s ORDER BY seq LIMIT %d", s.table(), s.param(1), s.param(2), limit)
+	rows, err := s.DB.QueryContext(ctx, query, false, maxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		var payload string
+		if err := rows.Scan(&entry.ID, &entry.Kind, &payload, &entry.Attempts); err != nil {
+			return nil, err
+		}
+
+		entry.Payload = []byte(payload)
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (s *SQLStore) Dispatched(ctx context.Context, id string) error {
+	query := fmt.Sprintf("UPDATE %s SET dispatched = %s WHERE id = %s", s.table(), s.param(1), s.param(2))
+	_, err := s.DB.ExecContext(ctx, query, true, id)
+	return err
+}
+
+func (s *SQLStore) Failed(ctx context.Context, id string, cause error) error {
+	query := fmt.Sprintf("UPDATE %s SET attempts = attempts + 1, last_error = %s WHERE id = %s", s.table(), s.param(1), s.param(2))
+	_, err := s.DB.ExecContext(ctx, query, cause.Error(), id)
+	return err
+}
+
+func (s *SQLStore) table() string {
+	if s.Table != "" {
+		return s.Table
+	}
+	return DefaultTable
+}
+
+func (s *SQLStore) param(index int) string {
+	if s.Placeholder != nil {
+		return s.Placeholder(index)
+	}
+	return QuestionMark(index)
+}
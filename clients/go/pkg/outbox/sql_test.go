@@ -0,0 +1,51 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingExecer struct {
+	query string
+	args  []interface{}
+}
+
+func (e *recordingExecer) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	e.query = query
+	e.args = args
+	return nil, nil
+}
+
+func TestSQLStoreEnqueue(t *testing.T) {
+	// given
+	store := &SQLStore{Table: "outbox", Placeholder: Dollar}
+	tx := &recordingExecer{}
+
+	// when
+	id, err := store.Enqueue(context.Background(), tx, CompleteJob{JobKey: 123, Variables: map[string]string{"foo": "bar"}})
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO outbox (id, seq, kind, payload) VALUES ($1, $2, $3, $4)", tx.query)
+	require.Len(t, tx.args, 4)
+	require.Equal(t, id, tx.args[0])
+	require.Equal(t, KindCompleteJob, tx.args[2])
+	require.JSONEq(t, `{"jobKey":123,"variables":{"foo":"bar"}}`, tx.args[3].(string))
+}
@@ -0,0 +1,141 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payloadstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+)
+
+// NewCodec creates a VariableCodec which offloads the top-level variables whose JSON value is larger than threshold
+// bytes to the store, and resolves the references when variables are decoded. VariableCodec has no context, so the
+// store is called with context.Background(). Variables which are set as JSON string, e.g. with VariablesFromString,
+// are sent unchanged.
+func NewCodec(codec entities.VariableCodec, store Store, threshold int) entities.VariableCodec {
+	return offloadingCodec{codec: codec, store: store, threshold: threshold}
+}
+
+type offloadingCodec struct {
+	codec     entities.VariableCodec
+	store     Store
+	threshold int
+}
+
+type reference struct {
+	Ref string `json:"$ref"`
+}
+
+func (c offloadingCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := c.codec.Marshal(v)
+	if err != nil || len(data) <= c.threshold {
+		return data, err
+	}
+
+	var variables map[string]json.RawMessage
+	if json.Unmarshal(data, &variables) != nil {
+		// not a document of variables
+		return data, nil
+	}
+
+	offloaded := false
+	for name, value := range variables {
+		if len(value) <= c.threshold {
+			continue
+		}
+
+		ref, err := c.store.Put(context.Background(), value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to offload variable '%s': %w", name, err)
+		}
+		if variables[name], err = json.Marshal(reference{Ref: ref}); err != nil {
+			return nil, err
+		}
+		offloaded = true
+	}
+
+	if !offloaded {
+		return data, nil
+	}
+	return json.Marshal(variables)
+}
+
+func (c offloadingCodec) Unmarshal(data []byte, v interface{}) error {
+	data, err := c.resolveReferences(data)
+	if err != nil {
+		return err
+	}
+
+	return c.codec.Unmarshal(data, v)
+}
+
+// ResolveVariables replaces the references of the document by the offloaded variables, and resolves them further if
+// the wrapped codec is an entities.VariablesResolver.
+func (c offloadingCodec) ResolveVariables(data []byte) ([]byte, error) {
+	data, err := c.resolveReferences(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolver, ok := c.codec.(entities.VariablesResolver); ok {
+		return resolver.ResolveVariables(data)
+	}
+	return data, nil
+}
+
+func (c offloadingCodec) resolveReferences(data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte(`"`+RefKey+`"`)) {
+		return data, nil
+	}
+
+	var variables map[string]json.RawMessage
+	if json.Unmarshal(data, &variables) != nil {
+		return data, nil
+	}
+
+	for name, value := range variables {
+		ref, ok := referenceOf(value)
+		if !ok {
+			continue
+		}
+
+		payload, err := c.store.Get(context.Background(), ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve variable '%s' from reference '%s': %w", name, ref, err)
+		}
+		if !json.Valid(payload) {
+			return nil, fmt.Errorf("expected payload of variable '%s' to be JSON", name)
+		}
+		variables[name] = payload
+	}
+
+	return json.Marshal(variables)
+}
+
+func referenceOf(value json.RawMessage) (string, bool) {
+	var object map[string]json.RawMessage
+	if json.Unmarshal(value, &object) != nil || len(object) != 1 {
+		return "", false
+	}
+
+	var ref string
+	if json.Unmarshal(object[RefKey], &ref) != nil || ref == "" {
+		return "", false
+	}
+	return ref, true
+}
@@ -0,0 +1,135 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payloadstore
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type document struct {
+	ID      string
+	Content string
+}
+
+func newFileStore(t *testing.T) (*FileStore, func()) {
+	dir, err := ioutil.TempDir("", "payloadstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, func() { _ = os.RemoveAll(dir) }
+}
+
+func TestCodec_Marshal(t *testing.T) {
+	store, cleanup := newFileStore(t)
+	defer cleanup()
+	codec := NewCodec(entities.JSONCodec, store, 100)
+
+	data, err := codec.Marshal(map[string]interface{}{
+		"id":       "small",
+		"document": document{ID: "1", Content: strings.Repeat("a", 1000)},
+	})
+	if err != nil {
+		t.Fatalf("codec.Marshal() = %v", err)
+	}
+
+	var encoded struct {
+		ID       string
+		Document map[string]string
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		t.Fatal(err)
+	}
+	if encoded.ID != "small" {
+		t.Errorf("expected small variable to be unchanged, got %q", encoded.ID)
+	}
+	ref := encoded.Document[RefKey]
+	if len(encoded.Document) != 1 || ref == "" {
+		t.Fatalf("expected large variable to be replaced by a reference, got %v", encoded.Document)
+	}
+
+	payload, err := store.Get(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("store.Get() = %v", err)
+	}
+	var stored document
+	if err := json.Unmarshal(payload, &stored); err != nil || len(stored.Content) != 1000 {
+		t.Errorf("expected the store to contain the variable, got %s", payload)
+	}
+}
+
+func TestCodec_ResolveJobVariables(t *testing.T) {
+	store, cleanup := newFileStore(t)
+	defer cleanup()
+	codec := NewCodec(entities.NewCompressingCodec(entities.JSONCodec, 100), store, 200)
+	large := document{ID: "1", Content: strings.Repeat("a", 1000)}
+
+	data, err := codec.Marshal(map[string]interface{}{"document": large, "id": "small"})
+	if err != nil {
+		t.Fatalf("codec.Marshal() = %v", err)
+	}
+
+	job := entities.NewJob(&pb.ActivatedJob{Variables: string(data)}, codec)
+	var variables struct {
+		ID       string
+		Document document
+	}
+	if err := job.GetVariablesAs(&variables); err != nil {
+		t.Fatalf("job.GetVariablesAs() = %v", err)
+	}
+	if variables.ID != "small" || variables.Document != large {
+		t.Errorf("expected variables to be resolved, got %+v", variables)
+	}
+
+	var decoded map[string]interface{}
+	if err := job.GetVariablesAs(&decoded, entities.UseNumber()); err != nil {
+		t.Fatalf("job.GetVariablesAs() with decoder options = %v", err)
+	}
+	if decoded["id"] != "small" {
+		t.Errorf("expected variables to be resolved with decoder options, got %v", decoded)
+	}
+}
+
+func TestCodec_UnknownReference(t *testing.T) {
+	store, cleanup := newFileStore(t)
+	defer cleanup()
+	codec := NewCodec(entities.JSONCodec, store, 100)
+
+	var variables map[string]interface{}
+	err := codec.Unmarshal([]byte(`{"document":{"$ref":"`+strings.Repeat("0", 64)+`"}}`), &variables)
+	if err == nil || !strings.Contains(err.Error(), ErrNotFound.Error()) {
+		t.Errorf("expected unknown reference to fail, got %v", err)
+	}
+}
+
+func TestFileStore_InvalidReference(t *testing.T) {
+	store, cleanup := newFileStore(t)
+	defer cleanup()
+
+	if _, err := store.Get(context.Background(), "../secret"); err == nil {
+		t.Error("expected invalid reference to be rejected")
+	}
+}
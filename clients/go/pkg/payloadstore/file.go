@@ -0,0 +1,80 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package payloadstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileStore stores payloads as files in a directory, e.g. on a volume which is shared by all clients and workers.
+// Payloads are addressed by their SHA-256 digest, so storing the same payload twice is idempotent.
+type FileStore struct {
+	Dir string
+}
+
+// NewFileStore creates a FileStore and its directory if necessary.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	return &FileStore{Dir: dir}, nil
+}
+
+func (s *FileStore) Put(_ context.Context, payload []byte) (string, error) {
+	digest := sha256.Sum256(payload)
+	ref := hex.EncodeToString(digest[:])
+
+	path := filepath.Join(s.Dir, ref)
+	if _, err := os.Stat(path); err == nil {
+		return ref, nil
+	}
+
+	file, err := ioutil.TempFile(s.Dir, ref+".tmp")
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(payload); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+	return ref, nil
+}
+
+func (s *FileStore) Get(_ context.Context, ref string) ([]byte, error) {
+	if decoded, err := hex.DecodeString(ref); err != nil || len(decoded) != sha256.Size {
+		return nil, fmt.Errorf("invalid payload reference '%s'", ref)
+	}
+
+	payload, err := ioutil.ReadFile(filepath.Join(s.Dir, ref))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return payload, err
+}
@@ -0,0 +1,46 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package payloadstore offloads large variables to an external storage. Variables which are larger than a threshold
+// are uploaded to a Store when a command is sent and replaced by a reference variable of the form
+// {"$ref": "<reference>"}; activated jobs resolve the references again when their variables are decoded.
+//
+//	store, err := payloadstore.NewFileStore("/mnt/payloads")
+//	client, err := zbc.NewClient(&zbc.ClientConfig{
+//		PayloadStore:            store,
+//		PayloadOffloadThreshold: 64 * 1024,
+//	})
+//
+// The workflow only sees the reference, so offloaded variables must not be used in expressions or mappings.
+package payloadstore
+
+import (
+	"context"
+	"errors"
+)
+
+// RefKey is the only key of the object which replaces an offloaded variable.
+const RefKey = "$ref"
+
+// ErrNotFound is returned by a Store if no payload exists for a reference.
+var ErrNotFound = errors.New("payload not found")
+
+// Store stores the JSON values of offloaded variables. Adapters for object storages, e.g. S3 or GCS, implement it
+// with the SDK of the storage.
+type Store interface {
+	// Put stores the payload and returns the reference to get it again.
+	Put(ctx context.Context, payload []byte) (string, error)
+	// Get returns the payload of a reference, or ErrNotFound.
+	Get(ctx context.Context, ref string) ([]byte, error)
+}
@@ -0,0 +1,174 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pb
+
+import "encoding/json"
+
+// The responses of the gateway implement json.Marshaler with explicit field names, so their JSON representation, e.g.
+// the output of zbctl or of an HTTP facade, doesn't change with the generated code. The names are the JSON names of
+// the gateway protocol, empty fields are omitted and enums are represented by their names.
+
+type activateJobsResponseJSON struct {
+	Jobs []*ActivatedJob `json:"jobs,omitempty"`
+}
+
+func (x *ActivateJobsResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(activateJobsResponseJSON{Jobs: x.GetJobs()})
+}
+
+type activatedJobJSON struct {
+	Key                       int64  `json:"key,omitempty"`
+	Type                      string `json:"type,omitempty"`
+	WorkflowInstanceKey       int64  `json:"workflowInstanceKey,omitempty"`
+	BpmnProcessId             string `json:"bpmnProcessId,omitempty"`
+	WorkflowDefinitionVersion int32  `json:"workflowDefinitionVersion,omitempty"`
+	WorkflowKey               int64  `json:"workflowKey,omitempty"`
+	ElementId                 string `json:"elementId,omitempty"`
+	ElementInstanceKey        int64  `json:"elementInstanceKey,omitempty"`
+	CustomHeaders             string `json:"customHeaders,omitempty"`
+	Worker                    string `json:"worker,omitempty"`
+	Retries                   int32  `json:"retries,omitempty"`
+	Deadline                  int64  `json:"deadline,omitempty"`
+	Variables                 string `json:"variables,omitempty"`
+}
+
+func (x *ActivatedJob) MarshalJSON() ([]byte, error) {
+	return json.Marshal(activatedJobJSON{
+		Key:                       x.GetKey(),
+		Type:                      x.GetType(),
+		WorkflowInstanceKey:       x.GetWorkflowInstanceKey(),
+		BpmnProcessId:             x.GetBpmnProcessId(),
+		WorkflowDefinitionVersion: x.GetWorkflowDefinitionVersion(),
+		WorkflowKey:               x.GetWorkflowKey(),
+		ElementId:                 x.GetElementId(),
+		ElementInstanceKey:        x.GetElementInstanceKey(),
+		CustomHeaders:             x.GetCustomHeaders(),
+		Worker:                    x.GetWorker(),
+		Retries:                   x.GetRetries(),
+		Deadline:                  x.GetDeadline(),
+		Variables:                 x.GetVariables(),
+	})
+}
+
+type createWorkflowInstanceResponseJSON struct {
+	WorkflowKey         int64  `json:"workflowKey,omitempty"`
+	BpmnProcessId       string `json:"bpmnProcessId,omitempty"`
+	Version             int32  `json:"version,omitempty"`
+	WorkflowInstanceKey int64  `json:"workflowInstanceKey,omitempty"`
+	Variables           string `json:"variables,omitempty"`
+}
+
+func (x *CreateWorkflowInstanceResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(createWorkflowInstanceResponseJSON{
+		WorkflowKey:         x.GetWorkflowKey(),
+		BpmnProcessId:       x.GetBpmnProcessId(),
+		Version:             x.GetVersion(),
+		WorkflowInstanceKey: x.GetWorkflowInstanceKey(),
+	})
+}
+
+func (x *CreateWorkflowInstanceWithResultResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(createWorkflowInstanceResponseJSON{
+		WorkflowKey:         x.GetWorkflowKey(),
+		BpmnProcessId:       x.GetBpmnProcessId(),
+		Version:             x.GetVersion(),
+		WorkflowInstanceKey: x.GetWorkflowInstanceKey(),
+		Variables:           x.GetVariables(),
+	})
+}
+
+type deployWorkflowResponseJSON struct {
+	Key       int64               `json:"key,omitempty"`
+	Workflows []*WorkflowMetadata `json:"workflows,omitempty"`
+}
+
+func (x *DeployWorkflowResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(deployWorkflowResponseJSON{Key: x.GetKey(), Workflows: x.GetWorkflows()})
+}
+
+type workflowMetadataJSON struct {
+	BpmnProcessId string `json:"bpmnProcessId,omitempty"`
+	Version       int32  `json:"version,omitempty"`
+	WorkflowKey   int64  `json:"workflowKey,omitempty"`
+	ResourceName  string `json:"resourceName,omitempty"`
+}
+
+func (x *WorkflowMetadata) MarshalJSON() ([]byte, error) {
+	return json.Marshal(workflowMetadataJSON{
+		BpmnProcessId: x.GetBpmnProcessId(),
+		Version:       x.GetVersion(),
+		WorkflowKey:   x.GetWorkflowKey(),
+		ResourceName:  x.GetResourceName(),
+	})
+}
+
+type keyResponseJSON struct {
+	Key int64 `json:"key,omitempty"`
+}
+
+func (x *PublishMessageResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(keyResponseJSON{Key: x.GetKey()})
+}
+
+func (x *SetVariablesResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(keyResponseJSON{Key: x.GetKey()})
+}
+
+type topologyResponseJSON struct {
+	Brokers           []*BrokerInfo `json:"brokers,omitempty"`
+	ClusterSize       int32         `json:"clusterSize,omitempty"`
+	PartitionsCount   int32         `json:"partitionsCount,omitempty"`
+	ReplicationFactor int32         `json:"replicationFactor,omitempty"`
+	GatewayVersion    string        `json:"gatewayVersion,omitempty"`
+}
+
+func (x *TopologyResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(topologyResponseJSON{
+		Brokers:           x.GetBrokers(),
+		ClusterSize:       x.GetClusterSize(),
+		PartitionsCount:   x.GetPartitionsCount(),
+		ReplicationFactor: x.GetReplicationFactor(),
+		GatewayVersion:    x.GetGatewayVersion(),
+	})
+}
+
+// brokerInfoJSON always contains the node id, since zero is the id of the first broker
+type brokerInfoJSON struct {
+	NodeId     int32        `json:"nodeId"`
+	Host       string       `json:"host,omitempty"`
+	Port       int32        `json:"port,omitempty"`
+	Partitions []*Partition `json:"partitions,omitempty"`
+	Version    string       `json:"version,omitempty"`
+}
+
+func (x *BrokerInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(brokerInfoJSON{
+		NodeId:     x.GetNodeId(),
+		Host:       x.GetHost(),
+		Port:       x.GetPort(),
+		Partitions: x.GetPartitions(),
+		Version:    x.GetVersion(),
+	})
+}
+
+// partitionJSON always contains the role, since zero is the leader role
+type partitionJSON struct {
+	PartitionId int32  `json:"partitionId,omitempty"`
+	Role        string `json:"role"`
+}
+
+func (x *Partition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(partitionJSON{PartitionId: x.GetPartitionId(), Role: x.GetRole().String()})
+}
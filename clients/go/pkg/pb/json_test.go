@@ -0,0 +1,108 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivatedJobMarshalJSON(t *testing.T) {
+	job := &ActivatedJob{
+		Key:                       2251799813685326,
+		Type:                      "jobType",
+		WorkflowInstanceKey:       2251799813685321,
+		BpmnProcessId:             "jobProcess",
+		WorkflowDefinitionVersion: 1,
+		ElementId:                 "ServiceTask_0drxnet",
+		CustomHeaders:             "{}",
+		Retries:                   3,
+		Variables:                 `{"orderId":"a"}`,
+	}
+
+	data, err := json.Marshal(job)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"key": 2251799813685326,
+		"type": "jobType",
+		"workflowInstanceKey": 2251799813685321,
+		"bpmnProcessId": "jobProcess",
+		"workflowDefinitionVersion": 1,
+		"elementId": "ServiceTask_0drxnet",
+		"customHeaders": "{}",
+		"retries": 3,
+		"variables": "{\"orderId\":\"a\"}"
+	}`, string(data))
+}
+
+func TestTopologyResponseMarshalJSON(t *testing.T) {
+	topology := &TopologyResponse{
+		ClusterSize:       1,
+		PartitionsCount:   2,
+		ReplicationFactor: 1,
+		Brokers: []*BrokerInfo{{
+			Host: "0.0.0.0",
+			Port: 26501,
+			Partitions: []*Partition{
+				{PartitionId: 1, Role: Partition_LEADER},
+				{PartitionId: 2, Role: Partition_FOLLOWER},
+			},
+		}},
+	}
+
+	data, err := json.Marshal(topology)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"brokers": [{
+			"nodeId": 0,
+			"host": "0.0.0.0",
+			"port": 26501,
+			"partitions": [{"partitionId": 1, "role": "LEADER"}, {"partitionId": 2, "role": "FOLLOWER"}]
+		}],
+		"clusterSize": 1,
+		"partitionsCount": 2,
+		"replicationFactor": 1
+	}`, string(data))
+}
+
+func TestDeployWorkflowResponseMarshalJSON(t *testing.T) {
+	response := &DeployWorkflowResponse{
+		Key:       2251799813685250,
+		Workflows: []*WorkflowMetadata{{BpmnProcessId: "process", Version: 1, WorkflowKey: 2251799813685345, ResourceName: "model.bpmn"}},
+	}
+
+	data, err := json.Marshal(response)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"key": 2251799813685250,
+		"workflows": [{"bpmnProcessId": "process", "version": 1, "workflowKey": 2251799813685345, "resourceName": "model.bpmn"}]
+	}`, string(data))
+}
+
+func TestCreateWorkflowInstanceResponsesMarshalJSON(t *testing.T) {
+	data, err := json.Marshal(&CreateWorkflowInstanceResponse{WorkflowKey: 1, BpmnProcessId: "process", Version: 2, WorkflowInstanceKey: 3})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"workflowKey": 1, "bpmnProcessId": "process", "version": 2, "workflowInstanceKey": 3}`, string(data))
+
+	data, err = json.Marshal(&CreateWorkflowInstanceWithResultResponse{WorkflowKey: 1, WorkflowInstanceKey: 3, Variables: "{}"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"workflowKey": 1, "workflowInstanceKey": 3, "variables": "{}"}`, string(data))
+}
@@ -0,0 +1,275 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package publisher publishes messages while the gateway may be unavailable, e.g. on edge devices with flaky
+// connectivity: messages which can't be published are buffered, in memory or on disk, and replayed in order once the
+// gateway is available again. Each message is published with the rest of its time to live, and messages which expire
+// while they are buffered are passed to a callback instead.
+package publisher
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+)
+
+const (
+	// DefaultMaxBuffered is used by the Publisher if no maximum number of buffered messages is set.
+	DefaultMaxBuffered = 10000
+	// DefaultRetryInterval is used by the Publisher if no retry interval is set.
+	DefaultRetryInterval = time.Second
+	// DefaultRequestTimeout is used by the Publisher if no request timeout is set.
+	DefaultRequestTimeout = 10 * time.Second
+)
+
+// ErrBufferFull is returned by Publish if a message can't be published and the buffer is full.
+var ErrBufferFull = errors.New("message buffer is full")
+
+// Client publishes the messages, e.g. the client of the zbc package.
+type Client interface {
+	NewPublishMessageCommand() commands.PublishMessageCommandStep1
+}
+
+// Message is a message to publish, like zbc.Client.NewPublishMessageCommand. A message without id gets a random id,
+// so the broker ignores it if it is replayed after it was published.
+type Message struct {
+	Name           string        `json:"name"`
+	CorrelationKey string        `json:"correlationKey"`
+	MessageID      string        `json:"messageId,omitempty"`
+	TimeToLive     time.Duration `json:"timeToLive,omitempty"`
+	// Variables are encoded as JSON when the message is published or buffered
+	Variables interface{} `json:"variables,omitempty"`
+}
+
+// Options configure a Publisher. Zero values are replaced by the respective defaults.
+type Options struct {
+	// Dir, if set, is the directory in which messages are buffered, so they survive a restart of the process;
+	// messages are buffered in memory otherwise
+	Dir string
+	// MaxBuffered is the maximum number of buffered messages, DefaultMaxBuffered if zero
+	MaxBuffered int
+	// RetryInterval is the interval in which Run replays buffered messages, DefaultRetryInterval if zero
+	RetryInterval time.Duration
+	// RequestTimeout of every command, DefaultRequestTimeout if zero
+	RequestTimeout time.Duration
+	// OnExpired, if set, is called with the messages whose time to live passed before they could be published.
+	// Messages without time to live don't expire while they are buffered.
+	OnExpired func(Message)
+	// Logger, logging.Default if nil
+	Logger logging.Logger
+}
+
+// Publisher publishes messages and buffers those which can't be published because the gateway is unavailable, timed
+// out or applies backpressure. Run has to be running to replay buffered messages.
+type Publisher struct {
+	client  Client
+	options Options
+	queue   queue
+
+	// lock keeps messages in order: a message is only published directly if no message is buffered
+	lock sync.Mutex
+}
+
+// New creates a Publisher. If a directory is set, it is created if necessary and messages buffered in it before are
+// replayed by Run.
+func New(client Client, options Options) (*Publisher, error) {
+	if options.MaxBuffered <= 0 {
+		options.MaxBuffered = DefaultMaxBuffered
+	}
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = DefaultRetryInterval
+	}
+	if options.RequestTimeout <= 0 {
+		options.RequestTimeout = DefaultRequestTimeout
+	}
+	if options.Logger == nil {
+		options.Logger = logging.Default
+	}
+
+	var q queue = &memoryQueue{}
+	if options.Dir != "" {
+		fq, err := newFileQueue(options.Dir)
+		if err != nil {
+			return nil, err
+		}
+		q = fq
+	}
+
+	return &Publisher{client: client, options: options, queue: q}, nil
+}
+
+// Publish publishes the message, or buffers it if it can't be published now or other messages are buffered. Errors
+// other than unavailability of the gateway, e.g. invalid variables, are returned and the message is not buffered.
+func (p *Publisher) Publish(ctx context.Context, message Message) error {
+	if message.MessageID == "" {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return fmt.Errorf("failed to generate message id: %w", err)
+		}
+		message.MessageID = hex.EncodeToString(id)
+	}
+
+	variables, err := encodeVariables(message.Variables)
+	if err != nil {
+		return err
+	}
+	buffered := bufferedMessage{Message: message, EncodedVariables: variables, BufferedAt: time.Now()}
+	buffered.Message.Variables = nil
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.queue.len() == 0 {
+		err := p.publish(ctx, &buffered, message.TimeToLive)
+		if !isUnavailable(err) {
+			return err
+		}
+		p.options.Logger.Debug("Buffering message which could not be published", "name", message.Name, "error", err)
+	}
+
+	if p.queue.len() >= p.options.MaxBuffered {
+		return ErrBufferFull
+	}
+	return p.queue.push(buffered)
+}
+
+// Buffered returns the number of buffered messages.
+func (p *Publisher) Buffered() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.queue.len()
+}
+
+// Run replays buffered messages until the context is done, in which case it returns the error of the context.
+func (p *Publisher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.options.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.Replay(ctx); err != nil && ctx.Err() == nil {
+			p.options.Logger.Warn("Failed to replay buffered messages", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Replay publishes the buffered messages in order, until one can't be published, and returns how many were
+// published. Expired messages are removed and passed to OnExpired. Messages which are rejected by the gateway for
+// other reasons than unavailability are dropped, as replaying them would not succeed.
+func (p *Publisher) Replay(ctx context.Context) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	published := 0
+	for {
+		buffered, ok, err := p.queue.peek()
+		if err != nil || !ok {
+			return published, err
+		}
+
+		ttl := buffered.TimeToLive
+		if ttl > 0 {
+			ttl -= time.Since(buffered.BufferedAt)
+			if ttl <= 0 {
+				p.expire(buffered)
+				if err := p.queue.pop(); err != nil {
+					return published, err
+				}
+				continue
+			}
+		}
+
+		err = p.publish(ctx, &buffered, ttl)
+		if isUnavailable(err) {
+			return published, nil
+		}
+		if err != nil {
+			p.options.Logger.Warn("Dropping buffered message which was rejected", "name", buffered.Name, "messageId", buffered.MessageID, "error", err)
+		} else {
+			published++
+		}
+		if err := p.queue.pop(); err != nil {
+			return published, err
+		}
+	}
+}
+
+func (p *Publisher) publish(ctx context.Context, message *bufferedMessage, ttl time.Duration) error {
+	command := p.client.NewPublishMessageCommand().MessageName(message.Name).CorrelationKey(message.CorrelationKey).MessageId(message.MessageID)
+	if ttl > 0 {
+		command = command.TimeToLive(ttl)
+	}
+	if message.EncodedVariables != "" {
+		var err error
+		if command, err = command.VariablesFromString(message.EncodedVariables); err != nil {
+			return err
+		}
+	}
+
+	_, err := command.RequestTimeout(p.options.RequestTimeout).Send(ctx)
+	if status.Code(err) == codes.AlreadyExists {
+		return nil
+	}
+	return err
+}
+
+func (p *Publisher) expire(buffered bufferedMessage) {
+	p.options.Logger.Warn("Buffered message expired before it could be published", "name", buffered.Name, "messageId", buffered.MessageID)
+	if p.options.OnExpired == nil {
+		return
+	}
+
+	message := buffered.Message
+	if buffered.EncodedVariables != "" {
+		message.Variables = json.RawMessage(buffered.EncodedVariables)
+	}
+	p.options.OnExpired(message)
+}
+
+func encodeVariables(variables interface{}) (string, error) {
+	if variables == nil {
+		return "", nil
+	}
+
+	encoded, err := json.Marshal(variables)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode variables of message: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// isUnavailable returns whether the message could not be published because of the gateway, so it is buffered.
+func isUnavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
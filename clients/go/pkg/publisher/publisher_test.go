@@ -0,0 +1,193 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type gatewayClient struct {
+	gateway pb.GatewayClient
+}
+
+func (c gatewayClient) NewPublishMessageCommand() commands.PublishMessageCommandStep1 {
+	return commands.NewPublishMessageCommand(c.gateway, func(context.Context, error) bool { return false })
+}
+
+var errUnavailable = status.Error(codes.Unavailable, "connection refused")
+
+func TestPublishBuffersMessagesWhileGatewayIsUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	first := &pb.PublishMessageRequest{Name: "reading", CorrelationKey: "sensor-1", MessageId: "1", Variables: `{"celsius":21}`}
+	second := &pb.PublishMessageRequest{Name: "reading", CorrelationKey: "sensor-1", MessageId: "2"}
+	gomock.InOrder(
+		client.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: first}).Return(nil, errUnavailable),
+		client.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: first}).Return(&pb.PublishMessageResponse{}, nil),
+		client.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: second}).Return(&pb.PublishMessageResponse{}, nil),
+	)
+
+	publisher, err := New(gatewayClient{client}, Options{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// when
+	require.NoError(t, publisher.Publish(ctx, Message{Name: "reading", CorrelationKey: "sensor-1", MessageID: "1", Variables: map[string]int{"celsius": 21}}))
+	// buffered behind the first message, without trying to publish it
+	require.NoError(t, publisher.Publish(ctx, Message{Name: "reading", CorrelationKey: "sensor-1", MessageID: "2"}))
+	require.Equal(t, 2, publisher.Buffered())
+
+	published, err := publisher.Replay(ctx)
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 2, published)
+	require.Equal(t, 0, publisher.Buffered())
+}
+
+func TestReplayStopsWhileGatewayIsUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).Return(nil, errUnavailable).Times(2)
+
+	publisher, err := New(gatewayClient{client}, Options{})
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), Message{Name: "reading", CorrelationKey: "sensor-1"}))
+
+	published, err := publisher.Replay(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, 0, published)
+	require.Equal(t, 1, publisher.Buffered())
+}
+
+func TestReplayPublishesRestOfTimeToLive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var expired []Message
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).Return(nil, errUnavailable).Times(2)
+	client.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, request *pb.PublishMessageRequest, _ ...interface{}) (*pb.PublishMessageResponse, error) {
+		require.Equal(t, "long", request.MessageId)
+		require.True(t, request.TimeToLive > 0 && request.TimeToLive < time.Hour.Milliseconds(), "expected rest of time to live, got %d", request.TimeToLive)
+		return &pb.PublishMessageResponse{}, nil
+	})
+
+	publisher, err := New(gatewayClient{client}, Options{OnExpired: func(message Message) {
+		expired = append(expired, message)
+	}})
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, publisher.Publish(ctx, Message{Name: "reading", CorrelationKey: "sensor-1", MessageID: "short", TimeToLive: time.Millisecond, Variables: map[string]int{"celsius": 21}}))
+	require.NoError(t, publisher.Publish(ctx, Message{Name: "reading", CorrelationKey: "sensor-1", MessageID: "long", TimeToLive: time.Hour}))
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = publisher.Replay(ctx)
+	require.NoError(t, err)
+	_, err = publisher.Replay(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, expired, 1)
+	require.Equal(t, "short", expired[0].MessageID)
+	require.Equal(t, json.RawMessage(`{"celsius":21}`), expired[0].Variables)
+}
+
+func TestPublishReturnsRejection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.InvalidArgument, "invalid"))
+
+	publisher, err := New(gatewayClient{client}, Options{})
+	require.NoError(t, err)
+
+	err = publisher.Publish(context.Background(), Message{Name: "reading", CorrelationKey: "sensor-1"})
+
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Equal(t, 0, publisher.Buffered())
+}
+
+func TestPublishFailsIfBufferIsFull(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).Return(nil, errUnavailable)
+
+	publisher, err := New(gatewayClient{client}, Options{MaxBuffered: 1})
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), Message{Name: "reading", CorrelationKey: "sensor-1"}))
+
+	err = publisher.Publish(context.Background(), Message{Name: "reading", CorrelationKey: "sensor-2"})
+
+	require.Equal(t, ErrBufferFull, err)
+}
+
+func TestBufferedMessagesSurviveRestart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir, err := ioutil.TempDir("", "zeebe-publisher")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	first := &pb.PublishMessageRequest{Name: "reading", CorrelationKey: "sensor-1", MessageId: "1", Variables: `{"celsius":21}`}
+	second := &pb.PublishMessageRequest{Name: "reading", CorrelationKey: "sensor-2", MessageId: "2"}
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	gomock.InOrder(
+		client.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: first}).Return(nil, errUnavailable),
+		client.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: first}).Return(&pb.PublishMessageResponse{}, nil),
+		client.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: second}).Return(&pb.PublishMessageResponse{}, nil),
+	)
+
+	publisher, err := New(gatewayClient{client}, Options{Dir: dir})
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), Message{Name: "reading", CorrelationKey: "sensor-1", MessageID: "1", Variables: map[string]int{"celsius": 21}}))
+	require.NoError(t, publisher.Publish(context.Background(), Message{Name: "reading", CorrelationKey: "sensor-2", MessageID: "2"}))
+
+	// when
+	restarted, err := New(gatewayClient{client}, Options{Dir: dir})
+	require.NoError(t, err)
+	require.Equal(t, 2, restarted.Buffered())
+	published, err := restarted.Replay(context.Background())
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 2, published)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
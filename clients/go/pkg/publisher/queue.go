@@ -0,0 +1,157 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// bufferedMessage is a message with its variables encoded as JSON and the time it was buffered, from which the rest
+// of its time to live is computed.
+type bufferedMessage struct {
+	Message
+	EncodedVariables string    `json:"encodedVariables,omitempty"`
+	BufferedAt       time.Time `json:"bufferedAt"`
+}
+
+// queue buffers messages in the order in which they were pushed. It is accessed only with the lock of the publisher.
+type queue interface {
+	len() int
+	push(bufferedMessage) error
+	peek() (bufferedMessage, bool, error)
+	pop() error
+}
+
+type memoryQueue struct {
+	messages []bufferedMessage
+}
+
+func (q *memoryQueue) len() int {
+	return len(q.messages)
+}
+
+func (q *memoryQueue) push(message bufferedMessage) error {
+	q.messages = append(q.messages, message)
+	return nil
+}
+
+func (q *memoryQueue) peek() (bufferedMessage, bool, error) {
+	if len(q.messages) == 0 {
+		return bufferedMessage{}, false, nil
+	}
+	return q.messages[0], true, nil
+}
+
+func (q *memoryQueue) pop() error {
+	q.messages[0] = bufferedMessage{}
+	q.messages = q.messages[1:]
+	return nil
+}
+
+// fileQueue stores every message as a file in a directory, named by the time it was buffered and a sequence number,
+// so the files are read in order after a restart.
+type fileQueue struct {
+	dir   string
+	files []string
+	next  uint64
+}
+
+const queueFileSuffix = ".json"
+
+func newFileQueue(dir string) (*fileQueue, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &fileQueue{dir: dir}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), queueFileSuffix) {
+			q.files = append(q.files, entry.Name())
+		}
+	}
+	sort.Strings(q.files)
+	return q, nil
+}
+
+func (q *fileQueue) len() int {
+	return len(q.files)
+}
+
+func (q *fileQueue) push(message bufferedMessage) error {
+	content, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode buffered message: %w", err)
+	}
+
+	q.next++
+	name := fmt.Sprintf("%020d-%010d%s", message.BufferedAt.UnixNano(), q.next, queueFileSuffix)
+	file, err := ioutil.TempFile(q.dir, name+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), filepath.Join(q.dir, name)); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	q.files = append(q.files, name)
+	return nil
+}
+
+func (q *fileQueue) peek() (bufferedMessage, bool, error) {
+	if len(q.files) == 0 {
+		return bufferedMessage{}, false, nil
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(q.dir, q.files[0]))
+	if err != nil {
+		return bufferedMessage{}, false, err
+	}
+
+	var message bufferedMessage
+	if err := json.Unmarshal(content, &message); err != nil {
+		return bufferedMessage{}, false, fmt.Errorf("failed to decode buffered message %s: %w", q.files[0], err)
+	}
+	return message, true, nil
+}
+
+func (q *fileQueue) pop() error {
+	if err := os.Remove(filepath.Join(q.dir, q.files[0])); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	q.files = q.files[1:]
+	return nil
+}
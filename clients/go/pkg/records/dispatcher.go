@@ -0,0 +1,111 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package records
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Source yields exported records, one JSON document per call, e.g. the values of the messages of a Kafka topic. Next
+// should block until a record is available or the context is done.
+type Source interface {
+	Next(ctx context.Context) ([]byte, error)
+}
+
+// SourceFunc adapts a function to the Source interface.
+type SourceFunc func(ctx context.Context) ([]byte, error)
+
+// Next calls f(ctx).
+func (f SourceFunc) Next(ctx context.Context) ([]byte, error) {
+	return f(ctx)
+}
+
+// Dispatcher decodes records and calls the callback for their value type. Callbacks which are not set are skipped;
+// OnRecord is called for every record, before the typed callback.
+type Dispatcher struct {
+	OnRecord           func(record *Record) error
+	OnJob              func(record *Record, job *JobRecord) error
+	OnWorkflowInstance func(record *Record, workflowInstance *WorkflowInstanceRecord) error
+	OnIncident         func(record *Record, incident *IncidentRecord) error
+	OnVariable         func(record *Record, variable *VariableRecord) error
+}
+
+// Dispatch decodes the record and calls the matching callbacks. It returns the first error of decoding or the callbacks.
+func (d *Dispatcher) Dispatch(data []byte) error {
+	record, err := Decode(data)
+	if err != nil {
+		return err
+	}
+
+	if d.OnRecord != nil {
+		if err := d.OnRecord(record); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case record.ValueType == ValueTypeJob && d.OnJob != nil:
+		var job JobRecord
+		if err := decodeValue(record, &job); err != nil {
+			return err
+		}
+		return d.OnJob(record, &job)
+	case record.ValueType == ValueTypeWorkflowInstance && d.OnWorkflowInstance != nil:
+		var workflowInstance WorkflowInstanceRecord
+		if err := decodeValue(record, &workflowInstance); err != nil {
+			return err
+		}
+		return d.OnWorkflowInstance(record, &workflowInstance)
+	case record.ValueType == ValueTypeIncident && d.OnIncident != nil:
+		var incident IncidentRecord
+		if err := decodeValue(record, &incident); err != nil {
+			return err
+		}
+		return d.OnIncident(record, &incident)
+	case record.ValueType == ValueTypeVariable && d.OnVariable != nil:
+		var variable VariableRecord
+		if err := decodeValue(record, &variable); err != nil {
+			return err
+		}
+		return d.OnVariable(record, &variable)
+	}
+
+	return nil
+}
+
+// Consume dispatches the records of the source until the source or a callback returns an error, which is returned.
+// A source should return the context error once the context is done.
+func (d *Dispatcher) Consume(ctx context.Context, source Source) error {
+	for {
+		data, err := source.Next(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := d.Dispatch(data); err != nil {
+			return err
+		}
+	}
+}
+
+func decodeValue(record *Record, value interface{}) error {
+	if err := json.Unmarshal(record.Value, value); err != nil {
+		return fmt.Errorf("failed to decode value of %s record at position %d of partition %d: %w", record.ValueType, record.Position, record.PartitionID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package records
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	jobRecord = `{"partitionId":1,"recordType":"EVENT","intent":"CREATED","valueType":"JOB","rejectionType":"NULL_VAL",
+		"rejectionReason":"","key":2251799813685251,"position":4321,"sourceRecordPosition":231,"timestamp":2191,
+		"brokerVersion":"0.25.0","value":{"bpmnProcessId":"test-process","workflowKey":13,"workflowDefinitionVersion":12,
+		"workflowInstanceKey":1234,"elementId":"activity","elementInstanceKey":123,"worker":"myWorker","type":"myType",
+		"variables":{"foo":"bar"},"retries":12,"errorMessage":"failed message","errorCode":"error",
+		"customHeaders":{"workerVersion":"42"},"deadline":13}}`
+	incidentRecord = `{"partitionId":1,"recordType":"EVENT","intent":"CREATED","valueType":"INCIDENT","key":5,
+		"position":10,"value":{"errorType":"IO_MAPPING_ERROR","errorMessage":"error","bpmnProcessId":"process",
+		"workflowKey":134,"workflowInstanceKey":10,"elementId":"activity","elementInstanceKey":34,"jobKey":123,
+		"variableScopeKey":34}}`
+	deploymentRecord = `{"partitionId":1,"recordType":"EVENT","intent":"CREATED","valueType":"DEPLOYMENT","key":6,
+		"position":11,"value":{"resources":[],"deployedWorkflows":[]}}`
+)
+
+func TestDispatchTypedValues(t *testing.T) {
+	// given
+	var jobs []*JobRecord
+	var incidents []*IncidentRecord
+	var positions []int64
+	dispatcher := &Dispatcher{
+		OnRecord: func(record *Record) error {
+			positions = append(positions, record.Position)
+			return nil
+		},
+		OnJob: func(_ *Record, job *JobRecord) error {
+			jobs = append(jobs, job)
+			return nil
+		},
+		OnIncident: func(_ *Record, incident *IncidentRecord) error {
+			incidents = append(incidents, incident)
+			return nil
+		},
+	}
+
+	// when
+	for _, record := range []string{jobRecord, incidentRecord, deploymentRecord} {
+		if err := dispatcher.Dispatch([]byte(record)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// then
+	assert.Equal(t, []int64{4321, 10, 11}, positions)
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, "myType", jobs[0].Type)
+		assert.EqualValues(t, 1234, jobs[0].WorkflowInstanceKey)
+		assert.Equal(t, map[string]string{"workerVersion": "42"}, jobs[0].CustomHeaders)
+		assert.JSONEq(t, `{"foo":"bar"}`, string(jobs[0].Variables))
+	}
+	if assert.Len(t, incidents, 1) {
+		assert.Equal(t, "IO_MAPPING_ERROR", incidents[0].ErrorType)
+		assert.EqualValues(t, 123, incidents[0].JobKey)
+	}
+}
+
+func TestConsumeUntilSourceEnds(t *testing.T) {
+	// given
+	remaining := []string{jobRecord, incidentRecord}
+	source := SourceFunc(func(context.Context) ([]byte, error) {
+		if len(remaining) == 0 {
+			return nil, io.EOF
+		}
+		next := remaining[0]
+		remaining = remaining[1:]
+		return []byte(next), nil
+	})
+
+	var count int
+	dispatcher := &Dispatcher{OnRecord: func(*Record) error {
+		count++
+		return nil
+	}}
+
+	// when
+	err := dispatcher.Consume(context.Background(), source)
+
+	// then
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestConsumeStopsOnCallbackError(t *testing.T) {
+	// given
+	callbackErr := errors.New("callback failed")
+	source := SourceFunc(func(context.Context) ([]byte, error) {
+		return []byte(jobRecord), nil
+	})
+	dispatcher := &Dispatcher{OnJob: func(*Record, *JobRecord) error {
+		return callbackErr
+	}}
+
+	// when
+	err := dispatcher.Consume(context.Background(), source)
+
+	// then
+	assert.Equal(t, callbackErr, err)
+}
+
+func TestDispatchRejectsInvalidRecord(t *testing.T) {
+	// given
+	dispatcher := &Dispatcher{}
+
+	// when
+	err := dispatcher.Dispatch([]byte(`{"valueType":`))
+
+	// then
+	assert.Error(t, err)
+}
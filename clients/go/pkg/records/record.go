@@ -0,0 +1,112 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package records consumes the records exported by Zeebe brokers and delivers them as typed values to callbacks, e.g.
+// to build monitoring or audit tooling. Records are expected in the JSON format of the broker, as written by the
+// Elasticsearch exporter or JSON based Kafka exporters. The transport is abstracted by Source, so any consumer which
+// yields one record per message can be plugged in.
+package records
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Value types of the records which are decoded into typed values.
+const (
+	ValueTypeJob              = "JOB"
+	ValueTypeWorkflowInstance = "WORKFLOW_INSTANCE"
+	ValueTypeIncident         = "INCIDENT"
+	ValueTypeVariable         = "VARIABLE"
+)
+
+// Record is the metadata of an exported record. Its value is kept as raw JSON and decoded by the Dispatcher according
+// to the value type.
+type Record struct {
+	PartitionID          int32           `json:"partitionId"`
+	Position             int64           `json:"position"`
+	SourceRecordPosition int64           `json:"sourceRecordPosition"`
+	Key                  int64           `json:"key"`
+	Timestamp            int64           `json:"timestamp"`
+	RecordType           string          `json:"recordType"`
+	ValueType            string          `json:"valueType"`
+	Intent               string          `json:"intent"`
+	RejectionType        string          `json:"rejectionType"`
+	RejectionReason      string          `json:"rejectionReason"`
+	BrokerVersion        string          `json:"brokerVersion"`
+	Value                json.RawMessage `json:"value"`
+}
+
+// JobRecord is the value of a record with value type JOB.
+type JobRecord struct {
+	Type                      string            `json:"type"`
+	Worker                    string            `json:"worker"`
+	Retries                   int32             `json:"retries"`
+	Deadline                  int64             `json:"deadline"`
+	ErrorMessage              string            `json:"errorMessage"`
+	ErrorCode                 string            `json:"errorCode"`
+	CustomHeaders             map[string]string `json:"customHeaders"`
+	Variables                 json.RawMessage   `json:"variables"`
+	BpmnProcessID             string            `json:"bpmnProcessId"`
+	WorkflowKey               int64             `json:"workflowKey"`
+	WorkflowDefinitionVersion int32             `json:"workflowDefinitionVersion"`
+	WorkflowInstanceKey       int64             `json:"workflowInstanceKey"`
+	ElementID                 string            `json:"elementId"`
+	ElementInstanceKey        int64             `json:"elementInstanceKey"`
+}
+
+// WorkflowInstanceRecord is the value of a record with value type WORKFLOW_INSTANCE.
+type WorkflowInstanceRecord struct {
+	BpmnProcessID             string `json:"bpmnProcessId"`
+	Version                   int32  `json:"version"`
+	WorkflowKey               int64  `json:"workflowKey"`
+	WorkflowInstanceKey       int64  `json:"workflowInstanceKey"`
+	ElementID                 string `json:"elementId"`
+	FlowScopeKey              int64  `json:"flowScopeKey"`
+	BpmnElementType           string `json:"bpmnElementType"`
+	ParentWorkflowInstanceKey int64  `json:"parentWorkflowInstanceKey"`
+	ParentElementInstanceKey  int64  `json:"parentElementInstanceKey"`
+}
+
+// IncidentRecord is the value of a record with value type INCIDENT.
+type IncidentRecord struct {
+	ErrorType           string `json:"errorType"`
+	ErrorMessage        string `json:"errorMessage"`
+	BpmnProcessID       string `json:"bpmnProcessId"`
+	WorkflowKey         int64  `json:"workflowKey"`
+	WorkflowInstanceKey int64  `json:"workflowInstanceKey"`
+	ElementID           string `json:"elementId"`
+	ElementInstanceKey  int64  `json:"elementInstanceKey"`
+	JobKey              int64  `json:"jobKey"`
+	VariableScopeKey    int64  `json:"variableScopeKey"`
+}
+
+// VariableRecord is the value of a record with value type VARIABLE. The value is the JSON document of the variable.
+type VariableRecord struct {
+	Name                string `json:"name"`
+	Value               string `json:"value"`
+	ScopeKey            int64  `json:"scopeKey"`
+	WorkflowInstanceKey int64  `json:"workflowInstanceKey"`
+	WorkflowKey         int64  `json:"workflowKey"`
+}
+
+// Decode decodes a single exported record.
+func Decode(data []byte) (*Record, error) {
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode record: %w", err)
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,105 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package records
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+const (
+	bpmnElementTypeProcess = "PROCESS"
+
+	intentElementActivating = "ELEMENT_ACTIVATING"
+	intentElementCompleted  = "ELEMENT_COMPLETED"
+	intentElementTerminated = "ELEMENT_TERMINATED"
+)
+
+// InstanceTree tracks the workflow instances which were created by call activities from the exported workflow
+// instance records, e.g. to cancel a workflow instance with its child instances, see
+// commands.CancelInstanceTreeCommand. Instances are removed when they are completed or terminated.
+type InstanceTree struct {
+	lock     sync.Mutex
+	children map[int64]map[int64]struct{}
+	parents  map[int64]int64
+}
+
+// NewInstanceTree returns an empty tree.
+func NewInstanceTree() *InstanceTree {
+	return &InstanceTree{children: make(map[int64]map[int64]struct{}), parents: make(map[int64]int64)}
+}
+
+// Observe adds the child instances of the workflow instance records which the dispatcher decodes. The callbacks which
+// are set on the dispatcher are still called.
+func (t *InstanceTree) Observe(dispatcher *Dispatcher) {
+	onWorkflowInstance := dispatcher.OnWorkflowInstance
+	dispatcher.OnWorkflowInstance = func(record *Record, workflowInstance *WorkflowInstanceRecord) error {
+		t.Add(record, workflowInstance)
+		if onWorkflowInstance != nil {
+			return onWorkflowInstance(record, workflowInstance)
+		}
+		return nil
+	}
+}
+
+// Add updates the tree with the record, which is ignored unless it is an event of a workflow instance created by a
+// call activity.
+func (t *InstanceTree) Add(record *Record, workflowInstance *WorkflowInstanceRecord) {
+	if record.RecordType != "EVENT" || workflowInstance.BpmnElementType != bpmnElementTypeProcess || workflowInstance.ParentWorkflowInstanceKey <= 0 {
+		return
+	}
+
+	child, parent := workflowInstance.WorkflowInstanceKey, workflowInstance.ParentWorkflowInstanceKey
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switch record.Intent {
+	case intentElementActivating:
+		if t.children[parent] == nil {
+			t.children[parent] = make(map[int64]struct{})
+		}
+		t.children[parent][child] = struct{}{}
+		t.parents[child] = parent
+	case intentElementCompleted, intentElementTerminated:
+		delete(t.children[parent], child)
+		if len(t.children[parent]) == 0 {
+			delete(t.children, parent)
+		}
+		delete(t.parents, child)
+	}
+}
+
+// Parent returns the key of the workflow instance which created the instance, or false if it is not known.
+func (t *InstanceTree) Parent(workflowInstanceKey int64) (int64, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	parent, ok := t.parents[workflowInstanceKey]
+	return parent, ok
+}
+
+// ChildInstances returns the keys of the known child instances of the workflow instance in ascending order.
+func (t *InstanceTree) ChildInstances(_ context.Context, workflowInstanceKey int64) ([]int64, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	children := make([]int64, 0, len(t.children[workflowInstanceKey]))
+	for child := range t.children[workflowInstanceKey] {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i] < children[j] })
+	return children, nil
+}
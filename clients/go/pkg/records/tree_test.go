@@ -0,0 +1,70 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package records
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func workflowInstanceRecord(intent, elementType string, key, parentKey int64) []byte {
+	return []byte(fmt.Sprintf(`{"partitionId":1,"recordType":"EVENT","intent":"%s","valueType":"WORKFLOW_INSTANCE",
+		"key":%d,"position":1,"value":{"bpmnProcessId":"process","workflowInstanceKey":%d,"bpmnElementType":"%s",
+		"parentWorkflowInstanceKey":%d,"parentElementInstanceKey":-1}}`, intent, key, key, elementType, parentKey))
+}
+
+func TestInstanceTreeTracksChildInstances(t *testing.T) {
+	// given
+	var observed int
+	dispatcher := &Dispatcher{
+		OnWorkflowInstance: func(*Record, *WorkflowInstanceRecord) error {
+			observed++
+			return nil
+		},
+	}
+	tree := NewInstanceTree()
+	tree.Observe(dispatcher)
+
+	// when
+	for _, record := range [][]byte{
+		workflowInstanceRecord("ELEMENT_ACTIVATING", "PROCESS", 1, -1),
+		workflowInstanceRecord("ELEMENT_ACTIVATING", "PROCESS", 3, 1),
+		workflowInstanceRecord("ELEMENT_ACTIVATING", "PROCESS", 2, 1),
+		workflowInstanceRecord("ELEMENT_ACTIVATING", "CALL_ACTIVITY", 4, 1),
+		workflowInstanceRecord("ELEMENT_ACTIVATING", "PROCESS", 5, 2),
+		workflowInstanceRecord("ELEMENT_COMPLETED", "PROCESS", 3, 1),
+	} {
+		if err := dispatcher.Dispatch(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// then
+	assert.Equal(t, 6, observed)
+	children, err := tree.ChildInstances(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2}, children)
+	children, _ = tree.ChildInstances(context.Background(), 2)
+	assert.Equal(t, []int64{5}, children)
+
+	parent, ok := tree.Parent(5)
+	assert.True(t, ok)
+	assert.EqualValues(t, 2, parent)
+	_, ok = tree.Parent(3)
+	assert.False(t, ok)
+}
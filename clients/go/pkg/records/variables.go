@@ -0,0 +1,111 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package records
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+const (
+	intentVariableCreated = "CREATED"
+	intentVariableUpdated = "UPDATED"
+)
+
+// VariableStore tracks the current variables of the workflow instances from the exported variable records, e.g. to
+// export the variables of an instance, see commands.ExportVariablesCommand. The variables of an instance are removed
+// when it is completed or terminated.
+type VariableStore struct {
+	lock      sync.Mutex
+	instances map[int64]map[int64]map[string]json.RawMessage
+}
+
+// NewVariableStore returns an empty store.
+func NewVariableStore() *VariableStore {
+	return &VariableStore{instances: make(map[int64]map[int64]map[string]json.RawMessage)}
+}
+
+// Observe adds the variables which the dispatcher decodes and removes the variables of the workflow instances which
+// end. The callbacks which are set on the dispatcher are still called.
+func (s *VariableStore) Observe(dispatcher *Dispatcher) {
+	onVariable := dispatcher.OnVariable
+	dispatcher.OnVariable = func(record *Record, variable *VariableRecord) error {
+		s.Add(record, variable)
+		if onVariable != nil {
+			return onVariable(record, variable)
+		}
+		return nil
+	}
+
+	onWorkflowInstance := dispatcher.OnWorkflowInstance
+	dispatcher.OnWorkflowInstance = func(record *Record, workflowInstance *WorkflowInstanceRecord) error {
+		s.remove(record, workflowInstance)
+		if onWorkflowInstance != nil {
+			return onWorkflowInstance(record, workflowInstance)
+		}
+		return nil
+	}
+}
+
+// Add updates the store with the record, which is ignored unless it is an event of a created or updated variable.
+func (s *VariableStore) Add(record *Record, variable *VariableRecord) {
+	if record.RecordType != "EVENT" || (record.Intent != intentVariableCreated && record.Intent != intentVariableUpdated) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	scopes := s.instances[variable.WorkflowInstanceKey]
+	if scopes == nil {
+		scopes = make(map[int64]map[string]json.RawMessage)
+		s.instances[variable.WorkflowInstanceKey] = scopes
+	}
+	if scopes[variable.ScopeKey] == nil {
+		scopes[variable.ScopeKey] = make(map[string]json.RawMessage)
+	}
+	scopes[variable.ScopeKey][variable.Name] = json.RawMessage(variable.Value)
+}
+
+func (s *VariableStore) remove(record *Record, workflowInstance *WorkflowInstanceRecord) {
+	if record.RecordType != "EVENT" || workflowInstance.BpmnElementType != bpmnElementTypeProcess {
+		return
+	}
+	if record.Intent != intentElementCompleted && record.Intent != intentElementTerminated {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.instances, workflowInstance.WorkflowInstanceKey)
+}
+
+// Variables returns the variables of the workflow instance by the key of their scope, which is the workflow instance
+// or one of its element instances.
+func (s *VariableStore) Variables(_ context.Context, workflowInstanceKey int64) (map[int64]map[string]json.RawMessage, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	scopes := make(map[int64]map[string]json.RawMessage, len(s.instances[workflowInstanceKey]))
+	for scopeKey, variables := range s.instances[workflowInstanceKey] {
+		scopes[scopeKey] = make(map[string]json.RawMessage, len(variables))
+		for name, value := range variables {
+			scopes[scopeKey][name] = value
+		}
+	}
+	return scopes, nil
+}
@@ -0,0 +1,69 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package records
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func variableRecord(intent string, scopeKey, workflowInstanceKey int64, name, value string) []byte {
+	return []byte(fmt.Sprintf(`{"partitionId":1,"recordType":"EVENT","intent":"%s","valueType":"VARIABLE","key":9,
+		"position":1,"value":{"name":"%s","value":%q,"scopeKey":%d,"workflowInstanceKey":%d,"workflowKey":3}}`,
+		intent, name, value, scopeKey, workflowInstanceKey))
+}
+
+func TestVariableStoreTracksVariables(t *testing.T) {
+	// given
+	var observed int
+	dispatcher := &Dispatcher{
+		OnVariable: func(*Record, *VariableRecord) error {
+			observed++
+			return nil
+		},
+	}
+	store := NewVariableStore()
+	store.Observe(dispatcher)
+
+	// when
+	for _, record := range [][]byte{
+		variableRecord("CREATED", 1, 1, "orderId", `"DE-42"`),
+		variableRecord("CREATED", 1, 1, "total", "10"),
+		variableRecord("CREATED", 4, 1, "attempt", "1"),
+		variableRecord("UPDATED", 1, 1, "total", "12.5"),
+		variableRecord("CREATED", 2, 2, "orderId", `"DE-43"`),
+		workflowInstanceRecord("ELEMENT_COMPLETED", "PROCESS", 2, -1),
+	} {
+		if err := dispatcher.Dispatch(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// then
+	assert.Equal(t, 5, observed)
+	variables, err := store.Variables(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]map[string]json.RawMessage{
+		1: {"orderId": json.RawMessage(`"DE-42"`), "total": json.RawMessage("12.5")},
+		4: {"attempt": json.RawMessage("1")},
+	}, variables)
+
+	variables, _ = store.Variables(context.Background(), 2)
+	assert.Empty(t, variables)
+}
@@ -0,0 +1,328 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replay sends the commands which were recorded by a client with zbc.ClientConfig.CommandRecorder again, e.g.
+// against a local test cluster to reproduce an incident of production.
+//
+// The keys of the replayed commands are mapped: whenever a recorded command created something, like a workflow
+// instance or a deployed workflow, its recorded key is mapped to the key of the replayed command, and later commands
+// which refer to the recorded key are sent with the mapped key. Keys which are not created by recorded commands, like
+// the keys of activated jobs, can be mapped with MapKey; all other keys are sent as recorded.
+//
+//	recorded, err := zbc.ReadRecordedCommands(file)
+//	...
+//	results, err := replay.New(testClient, replay.Options{}).ReplayAll(ctx, recorded)
+package replay
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+)
+
+// Options configure a Replayer.
+type Options struct {
+	// Filter, if set, returns whether a recorded command is replayed; commands which are filtered are skipped
+	Filter func(command zbc.RecordedCommand) bool
+	// ContinueOnError replays the remaining commands after a command failed which succeeded when it was recorded
+	ContinueOnError bool
+}
+
+// Result is the outcome of a replayed command. Err is the error of the replayed command, which is expected if the
+// recorded command failed, too, see Diverged.
+type Result struct {
+	Command  zbc.RecordedCommand
+	Response proto.Message
+	Err      error
+	Skipped  bool
+}
+
+// Diverged returns whether the replayed command had another status code than the recorded command.
+func (r Result) Diverged() bool {
+	return !r.Skipped && status.Code(r.Err).String() != r.Command.Code
+}
+
+// Replayer sends recorded commands with a client and maps their keys. It is safe for concurrent use, but commands
+// are usually replayed in the order of the recording.
+type Replayer struct {
+	client  zbc.Client
+	options Options
+
+	lock sync.Mutex
+	keys map[int64]int64
+}
+
+// New creates a replayer which sends the commands with the client.
+func New(client zbc.Client, options Options) *Replayer {
+	return &Replayer{client: client, options: options, keys: make(map[int64]int64)}
+}
+
+// MapKey maps the recorded key to the key which replayed commands are sent with instead.
+func (r *Replayer) MapKey(recorded, replayed int64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.keys[recorded] = replayed
+}
+
+// Key returns the key which is mapped to the recorded key, or false if it is not mapped.
+func (r *Replayer) Key(recorded int64) (int64, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	replayed, ok := r.keys[recorded]
+	return replayed, ok
+}
+
+// ReplayAll replays the commands in order and returns their results. It stops at the first command which failed
+// while it succeeded when it was recorded, unless ContinueOnError is set, and returns an error in addition to the
+// results if any command diverged.
+func (r *Replayer) ReplayAll(ctx context.Context, recorded []zbc.RecordedCommand) ([]Result, error) {
+	results := make([]Result, 0, len(recorded))
+	diverged := 0
+	for _, command := range recorded {
+		result := r.Replay(ctx, command)
+		results = append(results, result)
+		if !result.Diverged() {
+			continue
+		}
+
+		diverged++
+		if result.Err != nil && command.Code == codes.OK.String() && !r.options.ContinueOnError {
+			return results, fmt.Errorf("failed to replay %s command recorded at %s: %w", command.Command, command.Time.Format(time.RFC3339Nano), result.Err)
+		}
+	}
+
+	if diverged > 0 {
+		return results, fmt.Errorf("expected replayed commands to have the outcomes of the recording, but %d of %d diverged", diverged, len(results))
+	}
+	return results, nil
+}
+
+// Replay sends the recorded command with mapped keys and maps the keys of its response.
+func (r *Replayer) Replay(ctx context.Context, command zbc.RecordedCommand) Result {
+	if r.options.Filter != nil && !r.options.Filter(command) {
+		return Result{Command: command, Skipped: true}
+	}
+
+	request, err := decodeRequest(command)
+	if err != nil {
+		return Result{Command: command, Err: err}
+	}
+	r.mapKeys(request.ProtoReflect())
+
+	response, err := r.send(ctx, request)
+	result := Result{Command: command, Err: err}
+	if err != nil {
+		return result
+	}
+	result.Response = response
+
+	if len(command.Response) > 0 {
+		recordedResponse := proto.Clone(response)
+		proto.Reset(recordedResponse)
+		if err := proto.Unmarshal(command.Response, recordedResponse); err == nil {
+			r.learnKeys(recordedResponse.ProtoReflect(), response.ProtoReflect())
+		}
+	}
+	return result
+}
+
+// requestTypes are the requests of the commands which are recorded, by command name.
+var requestTypes = map[string]func() proto.Message{
+	"CancelWorkflowInstance":           func() proto.Message { return &pb.CancelWorkflowInstanceRequest{} },
+	"CompleteJob":                      func() proto.Message { return &pb.CompleteJobRequest{} },
+	"CreateWorkflowInstance":           func() proto.Message { return &pb.CreateWorkflowInstanceRequest{} },
+	"CreateWorkflowInstanceWithResult": func() proto.Message { return &pb.CreateWorkflowInstanceWithResultRequest{} },
+	"DeployWorkflow":                   func() proto.Message { return &pb.DeployWorkflowRequest{} },
+	"FailJob":                          func() proto.Message { return &pb.FailJobRequest{} },
+	"ThrowError":                       func() proto.Message { return &pb.ThrowErrorRequest{} },
+	"PublishMessage":                   func() proto.Message { return &pb.PublishMessageRequest{} },
+	"ResolveIncident":                  func() proto.Message { return &pb.ResolveIncidentRequest{} },
+	"SetVariables":                     func() proto.Message { return &pb.SetVariablesRequest{} },
+	"UpdateJobRetries":                 func() proto.Message { return &pb.UpdateJobRetriesRequest{} },
+}
+
+func decodeRequest(command zbc.RecordedCommand) (proto.Message, error) {
+	newRequest, ok := requestTypes[command.Command]
+	if !ok {
+		return nil, fmt.Errorf("expected a command which can be replayed, but got %s", command.Command)
+	}
+
+	request := newRequest()
+	if err := proto.Unmarshal(command.Request, request); err != nil {
+		return nil, fmt.Errorf("failed to decode request of %s command: %w", command.Command, err)
+	}
+	return request, nil
+}
+
+// send sends the request with the command of the client, so the commands are sent with its configuration.
+func (r *Replayer) send(ctx context.Context, request proto.Message) (proto.Message, error) {
+	switch request := request.(type) {
+	case *pb.CancelWorkflowInstanceRequest:
+		return r.client.NewCancelInstanceCommand().WorkflowInstanceKey(request.WorkflowInstanceKey).Send(ctx)
+	case *pb.CompleteJobRequest:
+		command := r.client.NewCompleteJobCommand().JobKey(request.JobKey)
+		if request.Variables == "" {
+			return command.Send(ctx)
+		}
+		dispatch, err := command.VariablesFromString(request.Variables)
+		if err != nil {
+			return nil, err
+		}
+		return dispatch.Send(ctx)
+	case *pb.CreateWorkflowInstanceRequest:
+		command, err := r.createInstance(request)
+		if err != nil {
+			return nil, err
+		}
+		return command.Send(ctx)
+	case *pb.CreateWorkflowInstanceWithResultRequest:
+		command, err := r.createInstance(request.Request)
+		if err != nil {
+			return nil, err
+		}
+		withResult := command.WithResult().FetchVariables(request.FetchVariables...)
+		if request.RequestTimeout > 0 {
+			return withResult.RequestTimeout(time.Duration(request.RequestTimeout) * time.Millisecond).Send(ctx)
+		}
+		return withResult.Send(ctx)
+	case *pb.DeployWorkflowRequest:
+		command := r.client.NewDeployWorkflowCommand()
+		for _, workflow := range request.Workflows {
+			command = command.AddResource(workflow.Definition, workflow.Name, workflow.Type)
+		}
+		return command.Send(ctx)
+	case *pb.FailJobRequest:
+		return r.client.NewFailJobCommand().JobKey(request.JobKey).Retries(request.Retries).ErrorMessage(request.ErrorMessage).Send(ctx)
+	case *pb.ThrowErrorRequest:
+		return r.client.NewThrowErrorCommand().JobKey(request.JobKey).ErrorCode(request.ErrorCode).ErrorMessage(request.ErrorMessage).Send(ctx)
+	case *pb.PublishMessageRequest:
+		command := r.client.NewPublishMessageCommand().MessageName(request.Name).CorrelationKey(request.CorrelationKey).
+			MessageId(request.MessageId).TimeToLive(time.Duration(request.TimeToLive) * time.Millisecond)
+		if request.Variables != "" {
+			var err error
+			if command, err = command.VariablesFromString(request.Variables); err != nil {
+				return nil, err
+			}
+		}
+		return command.Send(ctx)
+	case *pb.ResolveIncidentRequest:
+		return r.client.NewResolveIncidentCommand().IncidentKey(request.IncidentKey).Send(ctx)
+	case *pb.SetVariablesRequest:
+		command, err := r.client.NewSetVariablesCommand().ElementInstanceKey(request.ElementInstanceKey).VariablesFromString(request.Variables)
+		if err != nil {
+			return nil, err
+		}
+		return command.Local(request.Local).Send(ctx)
+	case *pb.UpdateJobRetriesRequest:
+		return r.client.NewUpdateJobRetriesCommand().JobKey(request.JobKey).Retries(request.Retries).Send(ctx)
+	}
+	return nil, fmt.Errorf("expected a request which can be replayed, but got %T", request)
+}
+
+func (r *Replayer) createInstance(request *pb.CreateWorkflowInstanceRequest) (commands.CreateInstanceCommandStep3, error) {
+	if request == nil {
+		return nil, fmt.Errorf("expected a request to create a workflow instance, but got none")
+	}
+
+	var command commands.CreateInstanceCommandStep3
+	if request.WorkflowKey > 0 {
+		command = r.client.NewCreateInstanceCommand().WorkflowKey(request.WorkflowKey)
+	} else {
+		command = r.client.NewCreateInstanceCommand().BPMNProcessId(request.BpmnProcessId).Version(request.Version)
+	}
+
+	if request.Variables == "" {
+		return command, nil
+	}
+	return command.VariablesFromString(request.Variables)
+}
+
+// mapKeys replaces the keys of the message which are mapped.
+func (r *Replayer) mapKeys(message protoreflect.Message) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	type mappedKey struct {
+		message protoreflect.Message
+		field   protoreflect.FieldDescriptor
+		key     int64
+	}
+
+	// the keys are set after ranging over the message, as it must not be changed while it is ranged over
+	var mapped []mappedKey
+	rangeKeys(message, func(message protoreflect.Message, field protoreflect.FieldDescriptor, key int64) {
+		if replayed, ok := r.keys[key]; ok {
+			mapped = append(mapped, mappedKey{message: message, field: field, key: replayed})
+		}
+	})
+	for _, key := range mapped {
+		key.message.Set(key.field, protoreflect.ValueOfInt64(key.key))
+	}
+}
+
+// learnKeys maps the keys of the recorded response to the keys of the replayed response in the same fields.
+func (r *Replayer) learnKeys(recorded, replayed protoreflect.Message) {
+	recorded.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		switch {
+		case isKey(field):
+			if key := replayed.Get(field).Int(); value.Int() > 0 && key > 0 {
+				r.MapKey(value.Int(), key)
+			}
+		case field.Kind() == protoreflect.MessageKind && field.IsList():
+			recordedList, replayedList := value.List(), replayed.Get(field).List()
+			for i := 0; i < recordedList.Len() && i < replayedList.Len(); i++ {
+				r.learnKeys(recordedList.Get(i).Message(), replayedList.Get(i).Message())
+			}
+		case field.Kind() == protoreflect.MessageKind && !field.IsMap():
+			r.learnKeys(value.Message(), replayed.Get(field).Message())
+		}
+		return true
+	})
+}
+
+// rangeKeys calls the function for every key field of the message and the messages it contains, with the message
+// which has the field.
+func rangeKeys(message protoreflect.Message, f func(message protoreflect.Message, field protoreflect.FieldDescriptor, key int64)) {
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		switch {
+		case isKey(field):
+			f(message, field, value.Int())
+		case field.Kind() == protoreflect.MessageKind && field.IsList():
+			for i := 0; i < value.List().Len(); i++ {
+				rangeKeys(value.List().Get(i).Message(), f)
+			}
+		case field.Kind() == protoreflect.MessageKind && !field.IsMap():
+			rangeKeys(value.Message(), f)
+		}
+		return true
+	})
+}
+
+func isKey(field protoreflect.FieldDescriptor) bool {
+	name := field.JSONName()
+	return field.Kind() == protoreflect.Int64Kind && field.Cardinality() != protoreflect.Repeated && (name == "key" || strings.HasSuffix(name, "Key"))
+}
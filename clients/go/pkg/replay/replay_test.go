@@ -0,0 +1,179 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbtest/mockgateway"
+)
+
+func record(t *testing.T, commands func(ctx context.Context, client zbc.Client)) []zbc.RecordedCommand {
+	gateway, err := mockgateway.Start()
+	require.NoError(t, err)
+	defer gateway.Close()
+
+	var buffer bytes.Buffer
+	client, err := zbc.NewClient(&zbc.ClientConfig{
+		GatewayAddress:         gateway.Address(),
+		UsePlaintextConnection: true,
+		CommandRecorder:        &buffer,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+	commands(ctx, client)
+	require.NoError(t, client.Close())
+
+	recorded, err := zbc.ReadRecordedCommands(&buffer)
+	require.NoError(t, err)
+	return recorded
+}
+
+func TestReplayMapsKeys(t *testing.T) {
+	// given
+	recorded := record(t, func(ctx context.Context, client zbc.Client) {
+		create, err := client.NewCreateInstanceCommand().BPMNProcessId("order-process").LatestVersion().VariablesFromString(`{"orderId":"DE-42"}`)
+		require.NoError(t, err)
+		instance, err := create.Send(ctx)
+		require.NoError(t, err)
+
+		setVariables, err := client.NewSetVariablesCommand().ElementInstanceKey(instance.WorkflowInstanceKey).VariablesFromString(`{"paid":true}`)
+		require.NoError(t, err)
+		_, err = setVariables.Local(true).Send(ctx)
+		require.NoError(t, err)
+
+		_, err = client.NewCompleteJobCommand().JobKey(7).Send(ctx)
+		require.NoError(t, err)
+		_, err = client.NewCancelInstanceCommand().WorkflowInstanceKey(instance.WorkflowInstanceKey).Send(ctx)
+		require.NoError(t, err)
+	})
+
+	gateway, err := mockgateway.Start()
+	require.NoError(t, err)
+	defer gateway.Close()
+	gateway.Respond("CreateWorkflowInstance", mockgateway.Response{Message: &pb.CreateWorkflowInstanceResponse{BpmnProcessId: "order-process", WorkflowInstanceKey: 100}})
+
+	client, err := gateway.NewClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	replayer := New(client, Options{})
+	replayer.MapKey(7, 70)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	results, err := replayer.ReplayAll(ctx, recorded)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	requests := gateway.Requests()
+	require.Len(t, requests, 4)
+	require.Equal(t, `{"orderId":"DE-42"}`, requests[0].Message.(*pb.CreateWorkflowInstanceRequest).Variables)
+	setVariables := requests[1].Message.(*pb.SetVariablesRequest)
+	require.Equal(t, int64(100), setVariables.ElementInstanceKey)
+	require.Equal(t, `{"paid":true}`, setVariables.Variables)
+	require.True(t, setVariables.Local)
+	require.Equal(t, int64(70), requests[2].Message.(*pb.CompleteJobRequest).JobKey)
+	require.Equal(t, int64(100), requests[3].Message.(*pb.CancelWorkflowInstanceRequest).WorkflowInstanceKey)
+
+	var instance pb.CreateWorkflowInstanceResponse
+	require.NoError(t, proto.Unmarshal(recorded[0].Response, &instance))
+	key, ok := replayer.Key(instance.WorkflowInstanceKey)
+	require.True(t, ok)
+	require.Equal(t, int64(100), key)
+}
+
+func TestReplayStopsAtDivergedCommand(t *testing.T) {
+	// given
+	recorded := record(t, func(ctx context.Context, client zbc.Client) {
+		_, err := client.NewResolveIncidentCommand().IncidentKey(1).Send(ctx)
+		require.NoError(t, err)
+		_, err = client.NewUpdateJobRetriesCommand().JobKey(2).Retries(3).Send(ctx)
+		require.NoError(t, err)
+	})
+
+	gateway, err := mockgateway.Start()
+	require.NoError(t, err)
+	defer gateway.Close()
+	gateway.Respond("ResolveIncident", mockgateway.Response{Err: status.Error(codes.NotFound, "incident not found")})
+
+	client, err := gateway.NewClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	results, err := New(client, Options{}).ReplayAll(ctx, recorded)
+
+	// then
+	require.Error(t, err)
+	require.Len(t, results, 1)
+	require.True(t, results[0].Diverged())
+	require.Equal(t, codes.NotFound, status.Code(results[0].Err))
+	require.Len(t, gateway.Requests(), 1)
+}
+
+func TestReplaySkipsFilteredCommands(t *testing.T) {
+	// given
+	recorded := record(t, func(ctx context.Context, client zbc.Client) {
+		_, err := client.NewFailJobCommand().JobKey(1).Retries(0).ErrorMessage("boom").Send(ctx)
+		require.NoError(t, err)
+		_, err = client.NewThrowErrorCommand().JobKey(2).ErrorCode("payment-failed").ErrorMessage("declined").Send(ctx)
+		require.NoError(t, err)
+	})
+
+	gateway, err := mockgateway.Start()
+	require.NoError(t, err)
+	defer gateway.Close()
+
+	client, err := gateway.NewClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	replayer := New(client, Options{Filter: func(command zbc.RecordedCommand) bool {
+		return command.Command != "FailJob"
+	}})
+
+	// when
+	results, err := replayer.ReplayAll(ctx, recorded)
+
+	// then
+	require.NoError(t, err)
+	require.True(t, results[0].Skipped)
+	require.False(t, results[1].Skipped)
+	gateway.Recorder(t).AssertThrownError(2, "payment-failed").WithErrorMessage("declined")
+	require.Len(t, gateway.Requests(), 1)
+}
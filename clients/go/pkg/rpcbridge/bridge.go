@@ -0,0 +1,234 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rpcbridge gives request/response semantics on top of workflow instances, e.g. for API gateways: Call
+// publishes a message with a generated correlation key and waits until a job of the reply job type carries the same
+// key, whose variables are the reply.
+//
+// The workflow has to pass the correlation key variable to the reply task, e.g. by starting with a message start event
+// for the message and ending with a service task of the reply job type. The message of a call is published with the
+// rest of the timeout of the call as time to live, and the deadline of the call in the deadline variable.
+package rpcbridge
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
+)
+
+const (
+	DefaultReplyJobType           = "rpc-reply"
+	DefaultCorrelationKeyVariable = "rpcCorrelationKey"
+	DefaultDeadlineVariable       = "rpcDeadline"
+	DefaultTimeout                = 30 * time.Second
+	DefaultRequestTimeout         = 10 * time.Second
+)
+
+// ErrClosed is returned by Call if the bridge was closed before the reply was received.
+var ErrClosed = errors.New("rpc bridge is closed")
+
+// Client publishes the requests and works on the replies, e.g. the client of the zbc package.
+type Client interface {
+	NewPublishMessageCommand() commands.PublishMessageCommandStep1
+	NewJobWorker() worker.JobWorkerBuilderStep1
+}
+
+// Options configure a Bridge. Zero values are replaced by the respective defaults.
+type Options struct {
+	// ReplyJobType is the type of the jobs which carry the replies
+	ReplyJobType string
+	// CorrelationKeyVariable is the variable which contains the correlation key of the call, in the request and reply
+	CorrelationKeyVariable string
+	// DeadlineVariable is the variable which contains the deadline of the call in milliseconds since the epoch
+	DeadlineVariable string
+	// Timeout of calls with a context without deadline
+	Timeout time.Duration
+	// RequestTimeout of the commands of the bridge
+	RequestTimeout time.Duration
+	// Logger, logging.Default if nil
+	Logger logging.Logger
+}
+
+// Bridge publishes requests and dispatches the replies to the waiting calls. Replies for calls of other bridges with
+// the same reply job type, e.g. other replicas of the service, are released to them until the call's deadline passed.
+type Bridge struct {
+	client  Client
+	options Options
+	worker  worker.JobWorker
+
+	lock    sync.Mutex
+	pending map[string]chan map[string]interface{}
+	closed  chan struct{}
+	once    sync.Once
+}
+
+// New creates a bridge and opens its reply worker.
+func New(client Client, options Options) *Bridge {
+	if options.ReplyJobType == "" {
+		options.ReplyJobType = DefaultReplyJobType
+	}
+	if options.CorrelationKeyVariable == "" {
+		options.CorrelationKeyVariable = DefaultCorrelationKeyVariable
+	}
+	if options.DeadlineVariable == "" {
+		options.DeadlineVariable = DefaultDeadlineVariable
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultTimeout
+	}
+	if options.RequestTimeout <= 0 {
+		options.RequestTimeout = DefaultRequestTimeout
+	}
+	if options.Logger == nil {
+		options.Logger = logging.Default
+	}
+
+	bridge := &Bridge{
+		client:  client,
+		options: options,
+		pending: make(map[string]chan map[string]interface{}),
+		closed:  make(chan struct{}),
+	}
+	bridge.worker = client.NewJobWorker().JobType(options.ReplyJobType).Handler(bridge.handleReply).Logger(options.Logger).Open()
+	return bridge
+}
+
+// Call publishes the message with the variables and returns the variables of the reply. It fails with the error of
+// the context if no reply was received before it is done, or before the Timeout of the bridge if it has no deadline.
+func (b *Bridge) Call(ctx context.Context, messageName string, variables map[string]interface{}) (map[string]interface{}, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.options.Timeout)
+		defer cancel()
+	}
+	deadline, _ := ctx.Deadline()
+
+	key, err := newCorrelationKey()
+	if err != nil {
+		return nil, err
+	}
+
+	reply := make(chan map[string]interface{}, 1)
+	b.lock.Lock()
+	b.pending[key] = reply
+	b.lock.Unlock()
+	defer func() {
+		b.lock.Lock()
+		delete(b.pending, key)
+		b.lock.Unlock()
+	}()
+
+	request := make(map[string]interface{}, len(variables)+2)
+	for name, value := range variables {
+		request[name] = value
+	}
+	request[b.options.CorrelationKeyVariable] = key
+	request[b.options.DeadlineVariable] = deadline.UnixNano() / int64(time.Millisecond)
+
+	command, err := b.client.NewPublishMessageCommand().MessageName(messageName).CorrelationKey(key).TimeToLive(time.Until(deadline)).VariablesFromMap(request)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := command.RequestTimeout(b.options.RequestTimeout).Send(ctx); err != nil {
+		return nil, fmt.Errorf("failed to publish request message '%s': %w", messageName, err)
+	}
+
+	select {
+	case variables := <-reply:
+		return variables, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-b.closed:
+		return nil, ErrClosed
+	}
+}
+
+// Close closes the reply worker; pending calls fail with ErrClosed.
+func (b *Bridge) Close() {
+	b.once.Do(func() {
+		close(b.closed)
+		b.worker.Close()
+	})
+}
+
+func (b *Bridge) handleReply(client worker.JobClient, job entities.Job) {
+	variables, err := job.GetVariablesAsMap()
+	if err != nil {
+		b.options.Logger.Warn("Failed to decode variables of reply", "jobKey", job.Key, "error", err)
+		b.failJob(client, &job, job.Retries-1, "failed to decode variables of reply: "+err.Error())
+		return
+	}
+
+	key, _ := variables[b.options.CorrelationKeyVariable].(string)
+	b.lock.Lock()
+	reply, ok := b.pending[key]
+	b.lock.Unlock()
+
+	if ok || b.deadlinePassed(variables) {
+		if ok {
+			reply <- variables
+		} else {
+			b.options.Logger.Debug("Dropping reply after the deadline of its call", "jobKey", job.Key, "correlationKey", key)
+		}
+		b.completeJob(client, &job)
+		return
+	}
+
+	// the call may be waiting on another bridge with the same reply job type
+	b.failJob(client, &job, job.Retries, "no call is waiting for the reply on this bridge")
+}
+
+func (b *Bridge) deadlinePassed(variables map[string]interface{}) bool {
+	deadline, ok := variables[b.options.DeadlineVariable].(float64)
+	return !ok || time.Now().After(time.Unix(0, int64(deadline)*int64(time.Millisecond)))
+}
+
+func (b *Bridge) completeJob(client worker.JobClient, job *entities.Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.options.RequestTimeout)
+	defer cancel()
+
+	if _, err := client.NewCompleteJobCommand().JobKey(job.Key).Send(ctx); err != nil {
+		b.options.Logger.Warn("Failed to complete reply job", "jobKey", job.Key, "error", err)
+	}
+}
+
+func (b *Bridge) failJob(client worker.JobClient, job *entities.Job, retries int32, message string) {
+	if retries < 0 {
+		retries = 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.options.RequestTimeout)
+	defer cancel()
+
+	if _, err := client.NewFailJobCommand().JobKey(job.Key).Retries(retries).ErrorMessage(message).Send(ctx); err != nil {
+		b.options.Logger.Warn("Failed to fail reply job", "jobKey", job.Key, "error", err)
+	}
+}
+
+func newCorrelationKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate correlation key: %w", err)
+	}
+	return hex.EncodeToString(key), nil
+}
@@ -0,0 +1,155 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbtest/mockgateway"
+)
+
+func startBridge(t *testing.T) (*mockgateway.Gateway, *Bridge, func()) {
+	gateway, err := mockgateway.Start()
+	require.NoError(t, err)
+	client, err := gateway.NewClient()
+	require.NoError(t, err)
+
+	bridge := New(client, Options{})
+	return gateway, bridge, func() {
+		bridge.Close()
+		_ = client.Close()
+		gateway.Close()
+	}
+}
+
+func requestsOf(gateway *mockgateway.Gateway, method string) []mockgateway.Request {
+	var requests []mockgateway.Request
+	for _, request := range gateway.Requests() {
+		if request.Method == method {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// awaitRequest returns the variables of the published request message.
+func awaitRequest(t *testing.T, gateway *mockgateway.Gateway) (*pb.PublishMessageRequest, map[string]interface{}) {
+	require.Eventually(t, func() bool {
+		return len(requestsOf(gateway, "PublishMessage")) > 0
+	}, utils.DefaultTestTimeout, time.Millisecond)
+
+	request := requestsOf(gateway, "PublishMessage")[0].Message.(*pb.PublishMessageRequest)
+	var variables map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(request.Variables), &variables))
+	return request, variables
+}
+
+func TestCallReturnsReply(t *testing.T) {
+	gateway, bridge, closeBridge := startBridge(t)
+	defer closeBridge()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	type result struct {
+		variables map[string]interface{}
+		err       error
+	}
+	done := make(chan result, 1)
+	go func() {
+		variables, err := bridge.Call(ctx, "quote-requested", map[string]interface{}{"amount": 100})
+		done <- result{variables, err}
+	}()
+
+	request, variables := awaitRequest(t, gateway)
+	require.Equal(t, "quote-requested", request.Name)
+	require.Equal(t, request.CorrelationKey, variables[DefaultCorrelationKeyVariable])
+	require.EqualValues(t, 100, variables["amount"])
+	require.True(t, request.TimeToLive > 0)
+
+	gateway.AddJobs(&pb.ActivatedJob{
+		Key:       1,
+		Type:      DefaultReplyJobType,
+		Retries:   3,
+		Variables: fmt.Sprintf(`{"%s":"%s","price":42}`, DefaultCorrelationKeyVariable, request.CorrelationKey),
+	})
+
+	select {
+	case result := <-done:
+		require.NoError(t, result.err)
+		require.EqualValues(t, 42, result.variables["price"])
+	case <-ctx.Done():
+		t.Fatal("expected call to return the reply")
+	}
+	require.Eventually(t, func() bool {
+		return len(requestsOf(gateway, "CompleteJob")) == 1
+	}, utils.DefaultTestTimeout, time.Millisecond)
+}
+
+func TestCallTimesOutWithoutReply(t *testing.T) {
+	_, bridge, closeBridge := startBridge(t)
+	defer closeBridge()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := bridge.Call(ctx, "quote-requested", nil)
+
+	require.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestReleaseReplyOfOtherBridge(t *testing.T) {
+	gateway, _, closeBridge := startBridge(t)
+	defer closeBridge()
+
+	deadline := time.Now().Add(time.Minute).UnixNano() / int64(time.Millisecond)
+	gateway.AddJobs(&pb.ActivatedJob{
+		Key:       1,
+		Type:      DefaultReplyJobType,
+		Retries:   3,
+		Variables: fmt.Sprintf(`{"%s":"other","%s":%d}`, DefaultCorrelationKeyVariable, DefaultDeadlineVariable, deadline),
+	})
+
+	require.Eventually(t, func() bool {
+		return len(requestsOf(gateway, "FailJob")) == 1
+	}, utils.DefaultTestTimeout, time.Millisecond)
+	request := requestsOf(gateway, "FailJob")[0].Message.(*pb.FailJobRequest)
+	require.EqualValues(t, 3, request.Retries)
+}
+
+func TestDropReplyAfterDeadline(t *testing.T) {
+	gateway, _, closeBridge := startBridge(t)
+	defer closeBridge()
+
+	deadline := time.Now().Add(-time.Minute).UnixNano() / int64(time.Millisecond)
+	gateway.AddJobs(&pb.ActivatedJob{
+		Key:       1,
+		Type:      DefaultReplyJobType,
+		Retries:   3,
+		Variables: fmt.Sprintf(`{"%s":"other","%s":%d}`, DefaultCorrelationKeyVariable, DefaultDeadlineVariable, deadline),
+	})
+
+	require.Eventually(t, func() bool {
+		return len(requestsOf(gateway, "CompleteJob")) == 1
+	}, utils.DefaultTestTimeout, time.Millisecond)
+}
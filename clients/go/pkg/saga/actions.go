@@ -0,0 +1,88 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saga
+
+import (
+	"context"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+)
+
+// Client sends the commands of the actions of this package, e.g. the client of the zbc package.
+type Client interface {
+	NewCreateInstanceCommand() commands.CreateInstanceCommandStep1
+	NewPublishMessageCommand() commands.PublishMessageCommandStep1
+	NewCompleteJobCommand() commands.CompleteJobCommandStep1
+	NewThrowErrorCommand() commands.ThrowErrorCommandStep1
+}
+
+// CreateInstance creates a workflow instance of the latest version of the workflow and waits for its result, so the
+// step fails if the instance does not complete.
+func CreateInstance(client Client, bpmnProcessID string, variables interface{}) Action {
+	return func(ctx context.Context) error {
+		command := client.NewCreateInstanceCommand().BPMNProcessId(bpmnProcessID).LatestVersion()
+		if variables != nil {
+			var err error
+			if command, err = command.VariablesFromObject(variables); err != nil {
+				return err
+			}
+		}
+
+		_, err := command.WithResult().Send(ctx)
+		return err
+	}
+}
+
+// PublishMessage publishes a message, e.g. to cancel a waiting workflow instance. The message id makes retries of the
+// action idempotent while the message is buffered.
+func PublishMessage(client Client, name, correlationKey, messageID string, variables interface{}) Action {
+	return func(ctx context.Context) error {
+		command := client.NewPublishMessageCommand().MessageName(name).CorrelationKey(correlationKey).MessageId(messageID)
+		if variables != nil {
+			var err error
+			if command, err = command.VariablesFromObject(variables); err != nil {
+				return err
+			}
+		}
+
+		_, err := command.Send(ctx)
+		return err
+	}
+}
+
+// CompleteJob completes a job.
+func CompleteJob(client Client, jobKey int64, variables interface{}) Action {
+	return func(ctx context.Context) error {
+		step2 := client.NewCompleteJobCommand().JobKey(jobKey)
+		var command commands.DispatchCompleteJobCommand = step2
+		if variables != nil {
+			var err error
+			if command, err = step2.VariablesFromObject(variables); err != nil {
+				return err
+			}
+		}
+
+		_, err := command.Send(ctx)
+		return err
+	}
+}
+
+// ThrowError throws a BPMN error for a job, e.g. to let the workflow take a compensating path.
+func ThrowError(client Client, jobKey int64, errorCode, errorMessage string) Action {
+	return func(ctx context.Context) error {
+		_, err := client.NewThrowErrorCommand().JobKey(jobKey).ErrorCode(errorCode).ErrorMessage(errorMessage).Send(ctx)
+		return err
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package saga orchestrates a sequence of steps with compensations in code, for flows which are not modeled in BPMN.
+// Every step is an action, e.g. creating a workflow instance or completing a job, with an optional compensation, e.g.
+// publishing a message or throwing a BPMN error which cancels the work of the step. If a step fails, the compensations
+// of the completed steps are run in reverse order.
+//
+// The progress of a saga is saved in a Store after every step, so a saga which was interrupted, e.g. by a restart,
+// continues where it stopped when it is run again with the same id. Actions and compensations may therefore be run
+// more than once and should be idempotent.
+package saga
+
+import (
+	"context"
+	"fmt"
+)
+
+// Status of a saga.
+type Status string
+
+const (
+	StatusRunning      Status = "RUNNING"
+	StatusCompleted    Status = "COMPLETED"
+	StatusCompensating Status = "COMPENSATING"
+	StatusCompensated  Status = "COMPENSATED"
+)
+
+// Action is the work of a step, or its compensation.
+type Action func(ctx context.Context) error
+
+// Step of a saga. Steps without compensation are skipped when compensating.
+type Step struct {
+	Name       string
+	Action     Action
+	Compensate Action
+}
+
+// State is the progress of a saga, which is saved in the Store.
+type State struct {
+	Status Status `json:"status"`
+	// Completed is the number of steps which completed and were not compensated yet
+	Completed int `json:"completed"`
+	// FailedStep is the name of the step which failed and caused the compensation, if any
+	FailedStep string `json:"failedStep,omitempty"`
+}
+
+// Error is returned by Run if a step failed. The saga is compensated unless CompensationErr is set, in which case
+// running the saga again retries the compensation.
+type Error struct {
+	Step            string
+	Err             error
+	CompensationErr error
+}
+
+func (e *Error) Error() string {
+	if e.CompensationErr != nil {
+		return fmt.Sprintf("saga step '%s' failed: %v; compensation failed: %v", e.Step, e.Err, e.CompensationErr)
+	}
+	return fmt.Sprintf("saga step '%s' failed and was compensated: %v", e.Step, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Saga is a sequence of steps, identified by its id in the store.
+type Saga struct {
+	ID    string
+	Steps []Step
+	// Store of the state, MemoryStore of the saga if nil
+	Store Store
+}
+
+// Run runs the steps of the saga, continuing from the state saved in the store. It returns nil if all steps are
+// completed and an *Error if a step failed. A saga which was compensated already returns an *Error without running
+// any step again.
+func (s *Saga) Run(ctx context.Context) error {
+	store := s.Store
+	if store == nil {
+		store = &MemoryStore{}
+		s.Store = store
+	}
+
+	state, ok, err := store.Load(ctx, s.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load state of saga '%s': %w", s.ID, err)
+	}
+	if !ok {
+		state = State{Status: StatusRunning}
+	}
+
+	switch state.Status {
+	case StatusCompleted:
+		return nil
+	case StatusCompensated:
+		return &Error{Step: state.FailedStep, Err: fmt.Errorf("saga '%s' was compensated before", s.ID)}
+	case StatusCompensating:
+		return s.compensate(ctx, store, state, fmt.Errorf("saga '%s' was interrupted while compensating", s.ID))
+	}
+
+	for state.Completed < len(s.Steps) {
+		step := s.Steps[state.Completed]
+		if err := step.Action(ctx); err != nil {
+			state.Status = StatusCompensating
+			state.FailedStep = step.Name
+			if saveErr := store.Save(ctx, s.ID, state); saveErr != nil {
+				return &Error{Step: step.Name, Err: err, CompensationErr: saveErr}
+			}
+
+			return s.compensate(ctx, store, state, err)
+		}
+
+		state.Completed++
+		if state.Completed == len(s.Steps) {
+			state.Status = StatusCompleted
+		}
+		if err := store.Save(ctx, s.ID, state); err != nil {
+			return fmt.Errorf("failed to save state of saga '%s' after step '%s': %w", s.ID, step.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Saga) compensate(ctx context.Context, store Store, state State, cause error) error {
+	for state.Completed > 0 {
+		step := s.Steps[state.Completed-1]
+		if step.Compensate != nil {
+			if err := step.Compensate(ctx); err != nil {
+				return &Error{Step: state.FailedStep, Err: cause, CompensationErr: fmt.Errorf("step '%s': %w", step.Name, err)}
+			}
+		}
+
+		state.Completed--
+		if state.Completed == 0 {
+			state.Status = StatusCompensated
+		}
+		if err := store.Save(ctx, s.ID, state); err != nil {
+			return &Error{Step: state.FailedStep, Err: cause, CompensationErr: err}
+		}
+	}
+
+	if state.Status != StatusCompensated {
+		state.Status = StatusCompensated
+		if err := store.Save(ctx, s.ID, state); err != nil {
+			return &Error{Step: state.FailedStep, Err: cause, CompensationErr: err}
+		}
+	}
+
+	return &Error{Step: state.FailedStep, Err: cause}
+}
@@ -0,0 +1,172 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+var errStep = errors.New("step failed")
+
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) action(name string, err error) Action {
+	return func(context.Context) error {
+		r.calls = append(r.calls, name)
+		return err
+	}
+}
+
+func (r *recorder) step(name string, err error) Step {
+	return Step{Name: name, Action: r.action(name, err), Compensate: r.action("undo "+name, nil)}
+}
+
+func TestSagaRunsAllSteps(t *testing.T) {
+	// given
+	r := &recorder{}
+	saga := &Saga{ID: "order-1", Steps: []Step{r.step("reserve", nil), r.step("charge", nil)}}
+
+	// when
+	err := saga.Run(context.Background())
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, []string{"reserve", "charge"}, r.calls)
+	state, _, _ := saga.Store.Load(context.Background(), "order-1")
+	require.Equal(t, State{Status: StatusCompleted, Completed: 2}, state)
+}
+
+func TestSagaCompensatesCompletedStepsInReverseOrder(t *testing.T) {
+	// given
+	r := &recorder{}
+	saga := &Saga{ID: "order-1", Steps: []Step{r.step("reserve", nil), r.step("charge", nil), r.step("ship", errStep)}}
+
+	// when
+	err := saga.Run(context.Background())
+
+	// then
+	var sagaErr *Error
+	require.True(t, errors.As(err, &sagaErr))
+	require.Equal(t, "ship", sagaErr.Step)
+	require.True(t, errors.Is(err, errStep))
+	require.NoError(t, sagaErr.CompensationErr)
+	require.Equal(t, []string{"reserve", "charge", "ship", "undo charge", "undo reserve"}, r.calls)
+	state, _, _ := saga.Store.Load(context.Background(), "order-1")
+	require.Equal(t, State{Status: StatusCompensated, FailedStep: "ship"}, state)
+}
+
+func TestSagaRetriesFailedCompensation(t *testing.T) {
+	// given
+	r := &recorder{}
+	compensationErr := errors.New("compensation failed")
+	charge := r.step("charge", nil)
+	charge.Compensate = r.action("undo charge", compensationErr)
+	saga := &Saga{ID: "order-1", Steps: []Step{r.step("reserve", nil), charge, r.step("ship", errStep)}}
+
+	err := saga.Run(context.Background())
+	var sagaErr *Error
+	require.True(t, errors.As(err, &sagaErr))
+	require.True(t, errors.Is(sagaErr.CompensationErr, compensationErr))
+
+	// when
+	saga.Steps[1].Compensate = r.action("undo charge", nil)
+	err = saga.Run(context.Background())
+
+	// then
+	require.True(t, errors.As(err, &sagaErr))
+	require.NoError(t, sagaErr.CompensationErr)
+	require.Equal(t, []string{"reserve", "charge", "ship", "undo charge", "undo charge", "undo reserve"}, r.calls)
+}
+
+func TestSagaContinuesFromSavedState(t *testing.T) {
+	// given
+	r := &recorder{}
+	store := &MemoryStore{}
+	require.NoError(t, store.Save(context.Background(), "order-1", State{Status: StatusRunning, Completed: 1}))
+	saga := &Saga{ID: "order-1", Store: store, Steps: []Step{r.step("reserve", nil), r.step("charge", nil)}}
+
+	// when
+	err := saga.Run(context.Background())
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, []string{"charge"}, r.calls)
+}
+
+type gatewayClient struct {
+	gateway pb.GatewayClient
+}
+
+func (c gatewayClient) NewCreateInstanceCommand() commands.CreateInstanceCommandStep1 {
+	return commands.NewCreateInstanceCommand(c.gateway, noRetry)
+}
+
+func (c gatewayClient) NewPublishMessageCommand() commands.PublishMessageCommandStep1 {
+	return commands.NewPublishMessageCommand(c.gateway, noRetry)
+}
+
+func (c gatewayClient) NewCompleteJobCommand() commands.CompleteJobCommandStep1 {
+	return commands.NewCompleteJobCommand(c.gateway, noRetry)
+}
+
+func (c gatewayClient) NewThrowErrorCommand() commands.ThrowErrorCommandStep1 {
+	return commands.NewThrowErrorCommand(c.gateway, noRetry)
+}
+
+func noRetry(context.Context, error) bool {
+	return false
+}
+
+func TestSagaWithCommandActions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// given
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	client := gatewayClient{gateway}
+	createInstance := &pb.CreateWorkflowInstanceWithResultRequest{
+		Request:        &pb.CreateWorkflowInstanceRequest{BpmnProcessId: "charge-card", Version: -1},
+		RequestTimeout: -1,
+	}
+	cancelOrder := &pb.PublishMessageRequest{Name: "cancel-order", CorrelationKey: "order-1", MessageId: "cancel-order-1"}
+	gomock.InOrder(
+		gateway.EXPECT().CreateWorkflowInstanceWithResult(gomock.Any(), &utils.RPCTestMsg{Msg: createInstance}).Return(&pb.CreateWorkflowInstanceWithResultResponse{}, nil),
+		gateway.EXPECT().ThrowError(gomock.Any(), gomock.Any()).Return(nil, errStep),
+		gateway.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: cancelOrder}).Return(&pb.PublishMessageResponse{}, nil),
+	)
+
+	saga := &Saga{ID: "order-1", Steps: []Step{
+		{Name: "charge", Action: CreateInstance(client, "charge-card", nil), Compensate: PublishMessage(client, "cancel-order", "order-1", "cancel-order-1", nil)},
+		{Name: "reject", Action: ThrowError(client, 123, "REJECTED", "order rejected")},
+	}}
+
+	// when
+	err := saga.Run(context.Background())
+
+	// then
+	require.True(t, errors.Is(err, errStep))
+}
@@ -0,0 +1,52 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saga
+
+import (
+	"context"
+	"sync"
+)
+
+// Store saves the state of sagas, e.g. in the database of the application.
+type Store interface {
+	// Load returns the state of the saga, or false if it was not saved yet
+	Load(ctx context.Context, id string) (State, bool, error)
+	Save(ctx context.Context, id string, state State) error
+}
+
+// MemoryStore is a Store which keeps the states in memory, so sagas only continue within the same process.
+type MemoryStore struct {
+	lock   sync.Mutex
+	states map[string]State
+}
+
+func (s *MemoryStore) Load(_ context.Context, id string) (State, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	state, ok := s.states[id]
+	return state, ok, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, id string, state State) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.states == nil {
+		s.states = make(map[string]State)
+	}
+	s.states[id] = state
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scaling estimates the backlog of job types from the polls of job workers, so worker deployments can be
+// scaled on the load of Zeebe, e.g. with the metrics API scaler of KEDA.
+//
+// The gateway does not report how many jobs of a type are available, so the backlog is estimated: a poll which
+// activates as many jobs as were requested indicates that more jobs are waiting. The estimate is the number of jobs
+// which are active in the workers plus the requested jobs weighted by how many of the recent polls were saturated.
+package scaling
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// DefaultSmoothing is the weight of the latest poll in the saturation, if the Collector has no smoothing.
+const DefaultSmoothing = 0.3
+
+// Backlog is the estimated backlog of a job type.
+type Backlog struct {
+	JobType string `json:"jobType"`
+	// Active is the number of jobs which were activated but not handled yet
+	Active int `json:"active"`
+	// Saturation is the smoothed ratio of polls which activated as many jobs as requested, between 0 and 1
+	Saturation float64 `json:"saturation"`
+	// Backlog is the estimated number of jobs which are active or waiting to be activated
+	Backlog int `json:"backlog"`
+}
+
+// Collector collects the polls of job workers. It implements worker.JobWorkerMetrics and worker.JobPollMetrics, so it
+// is set as metrics of the workers whose backlog is reported:
+//
+//	collector := &scaling.Collector{}
+//	client.NewJobWorker().JobType("charge-card").Handler(handler).Metrics(collector).Open()
+//	http.Handle("/scaling", collector.Handler())
+type Collector struct {
+	// Smoothing is the weight of the latest poll in the saturation, DefaultSmoothing if zero
+	Smoothing float64
+
+	lock     sync.Mutex
+	jobTypes map[string]*jobTypeStats
+}
+
+type jobTypeStats struct {
+	active     int
+	requested  int
+	saturation float64
+	polled     bool
+}
+
+// SetJobsRemainingCount records the number of jobs of the type which are active in the workers.
+func (c *Collector) SetJobsRemainingCount(jobType string, count int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stats(jobType).active = count
+}
+
+// ObserveJobPoll records whether the poll was saturated.
+func (c *Collector) ObserveJobPoll(jobType string, requested, activated int) {
+	if requested <= 0 {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	saturated := 0.0
+	if activated >= requested {
+		saturated = 1
+	}
+
+	stats := c.stats(jobType)
+	if stats.polled {
+		smoothing := c.Smoothing
+		if smoothing <= 0 || smoothing > 1 {
+			smoothing = DefaultSmoothing
+		}
+		stats.saturation = smoothing*saturated + (1-smoothing)*stats.saturation
+	} else {
+		stats.saturation = saturated
+		stats.polled = true
+	}
+	stats.requested = requested
+}
+
+// Backlog returns the estimated backlog of the job type, or false if no worker reported it.
+func (c *Collector) Backlog(jobType string) (Backlog, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stats, ok := c.jobTypes[jobType]
+	if !ok {
+		return Backlog{}, false
+	}
+	return stats.backlog(jobType), true
+}
+
+// Backlogs returns the estimated backlog of all reported job types, ordered by job type.
+func (c *Collector) Backlogs() []Backlog {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	backlogs := make([]Backlog, 0, len(c.jobTypes))
+	for jobType, stats := range c.jobTypes {
+		backlogs = append(backlogs, stats.backlog(jobType))
+	}
+	sort.Slice(backlogs, func(i, j int) bool {
+		return backlogs[i].JobType < backlogs[j].JobType
+	})
+	return backlogs
+}
+
+// Handler serves the backlogs as JSON object keyed by job type, e.g. for the value location 'charge-card.backlog' of
+// the KEDA metrics API scaler. With the query parameter 'jobType' only the backlog of that job type is served, for
+// the value location 'backlog'; unknown job types are not found.
+func (c *Collector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{}
+		if jobType := r.URL.Query().Get("jobType"); jobType != "" {
+			backlog, ok := c.Backlog(jobType)
+			if !ok {
+				http.Error(w, "unknown job type", http.StatusNotFound)
+				return
+			}
+			body = backlog
+		} else {
+			backlogs := make(map[string]Backlog)
+			for _, backlog := range c.Backlogs() {
+				backlogs[backlog.JobType] = backlog
+			}
+			body = backlogs
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
+
+func (c *Collector) stats(jobType string) *jobTypeStats {
+	if c.jobTypes == nil {
+		c.jobTypes = make(map[string]*jobTypeStats)
+	}
+
+	stats, ok := c.jobTypes[jobType]
+	if !ok {
+		stats = &jobTypeStats{}
+		c.jobTypes[jobType] = stats
+	}
+	return stats
+}
+
+func (s *jobTypeStats) backlog(jobType string) Backlog {
+	return Backlog{
+		JobType:    jobType,
+		Active:     s.active,
+		Saturation: s.saturation,
+		Backlog:    s.active + int(math.Round(s.saturation*float64(s.requested))),
+	}
+}
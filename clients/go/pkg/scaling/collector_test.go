@@ -0,0 +1,106 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaling
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
+)
+
+var (
+	_ worker.JobWorkerMetrics = &Collector{}
+	_ worker.JobPollMetrics   = &Collector{}
+)
+
+func TestCollectorEstimatesBacklogOfSaturatedPolls(t *testing.T) {
+	// given
+	collector := &Collector{Smoothing: 0.5}
+	collector.SetJobsRemainingCount("foo", 4)
+
+	// when
+	collector.ObserveJobPoll("foo", 10, 10)
+	collector.ObserveJobPoll("foo", 10, 10)
+	collector.ObserveJobPoll("foo", 10, 2)
+
+	// then
+	backlog, ok := collector.Backlog("foo")
+	require.True(t, ok)
+	require.Equal(t, Backlog{JobType: "foo", Active: 4, Saturation: 0.5, Backlog: 9}, backlog)
+}
+
+func TestCollectorWithoutPolls(t *testing.T) {
+	// given
+	collector := &Collector{}
+	collector.SetJobsRemainingCount("foo", 3)
+
+	// when
+	backlog, ok := collector.Backlog("foo")
+	_, unknown := collector.Backlog("bar")
+
+	// then
+	require.True(t, ok)
+	require.False(t, unknown)
+	require.Equal(t, Backlog{JobType: "foo", Active: 3, Backlog: 3}, backlog)
+}
+
+func TestCollectorHandler(t *testing.T) {
+	// given
+	collector := &Collector{}
+	collector.ObserveJobPoll("foo", 5, 5)
+	collector.ObserveJobPoll("bar", 5, 0)
+	server := httptest.NewServer(collector.Handler())
+	defer server.Close()
+
+	// when
+	response, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	// then
+	var backlogs map[string]Backlog
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&backlogs))
+	require.Equal(t, map[string]Backlog{
+		"foo": {JobType: "foo", Saturation: 1, Backlog: 5},
+		"bar": {JobType: "bar", Backlog: 0},
+	}, backlogs)
+}
+
+func TestCollectorHandlerForJobType(t *testing.T) {
+	// given
+	collector := &Collector{}
+	collector.ObserveJobPoll("foo", 5, 5)
+	server := httptest.NewServer(collector.Handler())
+	defer server.Close()
+
+	// when
+	response, err := http.Get(server.URL + "?jobType=foo")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	unknown, err := http.Get(server.URL + "?jobType=bar")
+	require.NoError(t, err)
+	defer unknown.Body.Close()
+
+	// then
+	var backlog Backlog
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&backlog))
+	require.Equal(t, 5, backlog.Backlog)
+	require.Equal(t, http.StatusNotFound, unknown.StatusCode)
+}
@@ -0,0 +1,120 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secrets resolves secret placeholders like '{{secrets.API_KEY}}' in the custom headers and variables of jobs,
+// following the secret conventions of Camunda connectors, so credentials don't have to be stored in workflows. The
+// secrets are looked up by a Provider, e.g. from environment variables or HashiCorp Vault.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ErrNotFound is returned by providers which don't know the secret.
+var ErrNotFound = errors.New("secret not found")
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*secrets\.([A-Za-z0-9_.\-/]+)\s*\}\}`)
+
+// Provider looks up the values of secrets by their name.
+type Provider interface {
+	// Secret returns the value of the secret, or an error wrapping ErrNotFound if it doesn't exist
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// ProviderFunc adapts a function to the Provider interface, e.g. to look up secrets with the SDK of a cloud provider.
+type ProviderFunc func(ctx context.Context, name string) (string, error)
+
+// Secret calls f(ctx, name).
+func (f ProviderFunc) Secret(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// EnvProvider looks up secrets in environment variables, whose name is the prefix followed by the name of the secret.
+type EnvProvider struct {
+	Prefix string
+}
+
+func (p EnvProvider) Secret(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(p.Prefix + name)
+	if !ok {
+		return "", fmt.Errorf("expected environment variable %s%s: %w", p.Prefix, name, ErrNotFound)
+	}
+	return value, nil
+}
+
+type chain []Provider
+
+// Chain returns a provider which looks up secrets in the providers in order, until one of them knows the secret.
+func Chain(providers ...Provider) Provider {
+	return chain(providers)
+}
+
+func (c chain) Secret(ctx context.Context, name string) (string, error) {
+	for _, provider := range c {
+		value, err := provider.Secret(ctx, name)
+		if !errors.Is(err, ErrNotFound) {
+			return value, err
+		}
+	}
+	return "", fmt.Errorf("expected secret %s: %w", name, ErrNotFound)
+}
+
+// HasPlaceholders returns whether the text contains secret placeholders.
+func HasPlaceholders(text string) bool {
+	return strings.Contains(text, "secrets.") && placeholderPattern.MatchString(text)
+}
+
+// Resolve replaces the secret placeholders in the text with the values of the secrets. Every secret is looked up once.
+func Resolve(ctx context.Context, provider Provider, text string) (string, error) {
+	return resolve(ctx, provider, text, func(value string) string { return value })
+}
+
+// ResolveJSON replaces the secret placeholders in the strings of the JSON document with the values of the secrets,
+// which are escaped, so the document stays valid.
+func ResolveJSON(ctx context.Context, provider Provider, document string) (string, error) {
+	return resolve(ctx, provider, document, func(value string) string {
+		escaped, _ := json.Marshal(value)
+		return string(escaped[1 : len(escaped)-1])
+	})
+}
+
+func resolve(ctx context.Context, provider Provider, text string, escape func(string) string) (string, error) {
+	if !HasPlaceholders(text) {
+		return text, nil
+	}
+
+	values := make(map[string]string)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		name := match[1]
+		if _, ok := values[name]; ok {
+			continue
+		}
+
+		value, err := provider.Secret(ctx, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve secret %s: %w", name, err)
+		}
+		values[name] = escape(value)
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		return values[placeholderPattern.FindStringSubmatch(placeholder)[1]]
+	}), nil
+}
@@ -0,0 +1,124 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mapProvider(values map[string]string) Provider {
+	return ProviderFunc(func(_ context.Context, name string) (string, error) {
+		value, ok := values[name]
+		if !ok {
+			return "", ErrNotFound
+		}
+		return value, nil
+	})
+}
+
+func TestResolveReplacesPlaceholders(t *testing.T) {
+	provider := mapProvider(map[string]string{"API_KEY": "abc", "HOST": "example.com"})
+
+	text, err := Resolve(context.Background(), provider, "https://{{ secrets.HOST }}/?key={{secrets.API_KEY}}&again={{secrets.API_KEY}}")
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/?key=abc&again=abc", text)
+}
+
+func TestResolveJSONEscapesValues(t *testing.T) {
+	provider := mapProvider(map[string]string{"PASSWORD": `p"a\ss`})
+
+	document, err := ResolveJSON(context.Background(), provider, `{"password":"{{secrets.PASSWORD}}","plain":"secrets.PASSWORD"}`)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"password":"p\"a\\ss","plain":"secrets.PASSWORD"}`, document)
+}
+
+func TestResolveFailsForMissingSecret(t *testing.T) {
+	_, err := Resolve(context.Background(), mapProvider(nil), "{{secrets.MISSING}}")
+
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestEnvProvider(t *testing.T) {
+	require.NoError(t, os.Setenv("ZEEBE_SECRET_TOKEN", "xyz"))
+	defer os.Unsetenv("ZEEBE_SECRET_TOKEN")
+	provider := EnvProvider{Prefix: "ZEEBE_SECRET_"}
+
+	value, err := provider.Secret(context.Background(), "TOKEN")
+	require.NoError(t, err)
+	assert.Equal(t, "xyz", value)
+
+	_, err = provider.Secret(context.Background(), "MISSING")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestChainUsesFirstProviderWhichKnowsTheSecret(t *testing.T) {
+	unavailable := ProviderFunc(func(context.Context, string) (string, error) {
+		return "", errors.New("unavailable")
+	})
+	provider := Chain(mapProvider(map[string]string{"A": "first"}), mapProvider(map[string]string{"A": "second", "B": "second"}), unavailable)
+
+	value, err := provider.Secret(context.Background(), "A")
+	require.NoError(t, err)
+	assert.Equal(t, "first", value)
+
+	value, err = provider.Secret(context.Background(), "B")
+	require.NoError(t, err)
+	assert.Equal(t, "second", value)
+
+	_, err = provider.Secret(context.Background(), "C")
+	assert.EqualError(t, err, "unavailable")
+}
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/zeebe/connectors" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"API_KEY":"abc","PORT":8080}}}`))
+	}))
+	defer server.Close()
+	provider := &VaultProvider{Address: server.URL, Token: "token", Path: "zeebe/connectors"}
+
+	value, err := provider.Secret(context.Background(), "API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", value)
+
+	value, err = provider.Secret(context.Background(), "PORT")
+	require.NoError(t, err)
+	assert.Equal(t, "8080", value)
+
+	_, err = provider.Secret(context.Background(), "MISSING")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	provider.Token = "invalid"
+	_, err = provider.Secret(context.Background(), "API_KEY")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotFound))
+}
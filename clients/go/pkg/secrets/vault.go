@@ -0,0 +1,92 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const DefaultVaultMount = "secret"
+
+// VaultProvider looks up secrets in a secret of the key-value secrets engine, version 2, of HashiCorp Vault: the name
+// of a secret is a key of the data of the Vault secret at Path.
+type VaultProvider struct {
+	// Address of the Vault server, e.g. 'https://vault.example.com:8200'
+	Address string
+	// Token authenticates the requests
+	Token string
+	// Mount is the path at which the secrets engine is mounted, DefaultVaultMount if empty
+	Mount string
+	// Path of the Vault secret in the secrets engine, e.g. 'zeebe/connectors'
+	Path string
+	// Client sends the requests, http.DefaultClient if nil
+	Client *http.Client
+}
+
+type vaultSecretResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+func (p *VaultProvider) Secret(ctx context.Context, name string) (string, error) {
+	mount := p.Mount
+	if mount == "" {
+		mount = DefaultVaultMount
+	}
+	url := strings.TrimSuffix(p.Address, "/") + "/v1/" + strings.Trim(mount, "/") + "/data/" + strings.Trim(p.Path, "/")
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("X-Vault-Token", p.Token)
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Vault secret %s: %w", p.Path, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("expected Vault secret %s: %w", p.Path, ErrNotFound)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to read Vault secret %s: unexpected status %s", p.Path, response.Status)
+	}
+
+	var secret vaultSecretResponse
+	if err := json.NewDecoder(response.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("failed to decode Vault secret %s: %w", p.Path, err)
+	}
+
+	value, ok := secret.Data.Data[name]
+	if !ok {
+		return "", fmt.Errorf("expected key %s in Vault secret %s: %w", name, p.Path, ErrNotFound)
+	}
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
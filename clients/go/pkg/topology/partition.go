@@ -0,0 +1,89 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topology
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+)
+
+// startPartitionID is the id of the first partition of a cluster.
+const startPartitionID = 1
+
+// SubscriptionPartitionID returns the partition to which the brokers route messages and message subscriptions with the
+// given correlation key, in a cluster with the given number of partitions. It matches the hashing of the brokers, so
+// a message is only correlated to a workflow instance if both have the same correlation key, not merely equal values.
+// An error is returned if the number of partitions is not positive.
+func SubscriptionPartitionID(correlationKey string, partitionsCount int32) (int32, error) {
+	if partitionsCount <= 0 {
+		return 0, fmt.Errorf("expected cluster to have partitions, but got %d partitions", partitionsCount)
+	}
+
+	// equal to java.lang.String#hashCode of the UTF-8 bytes, as computed by the brokers
+	var hashCode int32
+	for _, b := range []byte(correlationKey) {
+		hashCode = 31*hashCode + int32(int8(b))
+	}
+
+	partition := hashCode % partitionsCount
+	if partition < 0 {
+		partition = -partition
+	}
+	return partition + startPartitionID, nil
+}
+
+// WhichPartition requests the topology of the cluster and returns the partition of messages with the given
+// correlation key, e.g. to debug messages which are not correlated.
+func WhichPartition(ctx context.Context, client Client, correlationKey string) (int32, error) {
+	topology, err := client.NewTopologyCommand().Send(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return SubscriptionPartitionID(correlationKey, topology.PartitionsCount)
+}
+
+// PublishMetrics is notified about the messages published by the client, per partition.
+type PublishMetrics interface {
+	IncrementMessagesPublished(partitionID int32, messageName string)
+	IncrementPublishFailures(partitionID int32, messageName string)
+}
+
+// PublishMetricsInterceptor returns a command interceptor for ClientConfig.Interceptors which reports every published
+// message to the metrics, with the partition of its correlation key in a cluster with the given number of partitions.
+// If the number of partitions is not positive, the partition is unknown and messages are not reported.
+func PublishMetricsInterceptor(partitionsCount int32, metrics PublishMetrics) zbc.CommandInterceptor {
+	return func(ctx context.Context, info zbc.CommandInfo, request, response interface{}, invoker zbc.CommandInvoker) error {
+		err := invoker(ctx, request, response)
+
+		if message, ok := request.(*pb.PublishMessageRequest); ok {
+			partitionID, partitionErr := SubscriptionPartitionID(message.CorrelationKey, partitionsCount)
+			if partitionErr != nil {
+				return err
+			}
+
+			if err != nil {
+				metrics.IncrementPublishFailures(partitionID, message.Name)
+			} else {
+				metrics.IncrementMessagesPublished(partitionID, message.Name)
+			}
+		}
+
+		return err
+	}
+}
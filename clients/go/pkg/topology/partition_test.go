@@ -0,0 +1,132 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topology
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+)
+
+func TestSubscriptionPartitionID(t *testing.T) {
+	// same expectations as the SubscriptionUtilTest of the brokers
+	expected := []struct {
+		correlationKey  string
+		partitionsCount int32
+		partitionID     int32
+	}{
+		{"a", 10, 7 + startPartitionID},
+		{"b", 3, 2 + startPartitionID},
+		{"c", 11, 0 + startPartitionID},
+		{"foobar", 100, 63 + startPartitionID},
+		{"", 3, startPartitionID},
+	}
+
+	for _, e := range expected {
+		partitionID, err := SubscriptionPartitionID(e.correlationKey, e.partitionsCount)
+		assert.NoError(t, err)
+		assert.Equal(t, e.partitionID, partitionID, "partition of '%s' in %d partitions", e.correlationKey, e.partitionsCount)
+	}
+}
+
+func TestSubscriptionPartitionIDWithoutPartitions(t *testing.T) {
+	for _, partitionsCount := range []int32{0, -1} {
+		_, err := SubscriptionPartitionID("foobar", partitionsCount)
+		assert.Error(t, err)
+	}
+}
+
+func TestWhichPartitionWithoutPartitions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// given
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(&pb.TopologyResponse{}, nil)
+
+	// when
+	_, err := WhichPartition(context.Background(), gatewayClient{gateway}, "foobar")
+
+	// then
+	assert.Error(t, err)
+}
+
+func TestWhichPartition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// given
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(&pb.TopologyResponse{PartitionsCount: 100}, nil)
+
+	// when
+	partitionID, err := WhichPartition(context.Background(), gatewayClient{gateway}, "foobar")
+
+	// then
+	assert.NoError(t, err)
+	assert.EqualValues(t, 64, partitionID)
+}
+
+type recordingPublishMetrics struct {
+	published map[int32]int
+	failed    map[int32]int
+}
+
+func (m *recordingPublishMetrics) IncrementMessagesPublished(partitionID int32, _ string) {
+	m.published[partitionID]++
+}
+
+func (m *recordingPublishMetrics) IncrementPublishFailures(partitionID int32, _ string) {
+	m.failed[partitionID]++
+}
+
+func TestPublishMetricsInterceptor(t *testing.T) {
+	// given
+	metrics := &recordingPublishMetrics{published: map[int32]int{}, failed: map[int32]int{}}
+	interceptor := PublishMetricsInterceptor(10, metrics)
+	failure := errors.New("failed")
+	succeed := func(context.Context, interface{}, interface{}) error { return nil }
+	fail := func(context.Context, interface{}, interface{}) error { return failure }
+
+	// when
+	_ = interceptor(context.Background(), zbc.CommandInfo{Name: "PublishMessage"}, &pb.PublishMessageRequest{CorrelationKey: "a"}, nil, succeed)
+	err := interceptor(context.Background(), zbc.CommandInfo{Name: "PublishMessage"}, &pb.PublishMessageRequest{CorrelationKey: "b"}, nil, fail)
+	_ = interceptor(context.Background(), zbc.CommandInfo{Name: "CompleteJob"}, &pb.CompleteJobRequest{}, nil, succeed)
+
+	// then
+	assert.Equal(t, failure, err)
+	assert.Equal(t, map[int32]int{8: 1}, metrics.published)
+	assert.Equal(t, map[int32]int{9: 1}, metrics.failed)
+}
+
+func TestPublishMetricsInterceptorWithoutPartitions(t *testing.T) {
+	// given
+	metrics := &recordingPublishMetrics{published: map[int32]int{}, failed: map[int32]int{}}
+	interceptor := PublishMetricsInterceptor(0, metrics)
+	succeed := func(context.Context, interface{}, interface{}) error { return nil }
+
+	// when
+	err := interceptor(context.Background(), zbc.CommandInfo{Name: "PublishMessage"}, &pb.PublishMessageRequest{CorrelationKey: "a"}, nil, succeed)
+
+	// then
+	assert.NoError(t, err)
+	assert.Empty(t, metrics.published)
+}
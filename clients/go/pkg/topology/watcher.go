@@ -0,0 +1,170 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package topology watches the topology of a Zeebe cluster and reports changes, e.g. to build health dashboards or
+// partition aware routing. It also computes the partitions to which messages are routed by their correlation key.
+package topology
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+// DefaultInterval is used by the Watcher if no interval is set.
+const DefaultInterval = 10 * time.Second
+
+// NoLeader is the leader of a partition for which no broker reports to be the leader.
+const NoLeader int32 = -1
+
+// Client sends topology requests, e.g. the client of the zbc package.
+type Client interface {
+	NewTopologyCommand() *commands.TopologyCommand
+}
+
+// LeaderChange is a partition whose leader changed. A partition whose Leader is NoLeader is unavailable until a new
+// leader is elected.
+type LeaderChange struct {
+	PartitionID    int32
+	PreviousLeader int32
+	Leader         int32
+}
+
+// Delta is the change between two topologies, with the current topology.
+type Delta struct {
+	Topology      *pb.TopologyResponse
+	BrokersJoined []*pb.BrokerInfo
+	BrokersLeft   []*pb.BrokerInfo
+	LeaderChanges []LeaderChange
+}
+
+func (d *Delta) empty() bool {
+	return len(d.BrokersJoined) == 0 && len(d.BrokersLeft) == 0 && len(d.LeaderChanges) == 0
+}
+
+// Watcher polls the topology of the cluster and calls OnChange whenever brokers joined or left or the leader of a
+// partition changed. The first topology is reported as a change from an empty cluster. OnError, if set, is called if
+// the topology could not be requested.
+type Watcher struct {
+	Client   Client
+	Interval time.Duration
+	OnChange func(delta Delta)
+	OnError  func(err error)
+}
+
+// Watch polls the topology until ctx is done and returns the context error.
+func (w *Watcher) Watch(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	previous := &pb.TopologyResponse{}
+	for {
+		current, err := w.requestTopology(ctx, interval)
+		if err != nil {
+			if w.OnError != nil && ctx.Err() == nil {
+				w.OnError(err)
+			}
+		} else {
+			delta := Diff(previous, current)
+			if !delta.empty() && w.OnChange != nil {
+				w.OnChange(delta)
+			}
+			previous = current
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *Watcher) requestTopology(ctx context.Context, timeout time.Duration) (*pb.TopologyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return w.Client.NewTopologyCommand().Send(ctx)
+}
+
+// Diff returns the change from the previous to the current topology.
+func Diff(previous, current *pb.TopologyResponse) Delta {
+	delta := Delta{Topology: current}
+
+	previousBrokers := brokersByID(previous)
+	currentBrokers := brokersByID(current)
+	for _, broker := range current.GetBrokers() {
+		if _, ok := previousBrokers[broker.NodeId]; !ok {
+			delta.BrokersJoined = append(delta.BrokersJoined, broker)
+		}
+	}
+	for _, broker := range previous.GetBrokers() {
+		if _, ok := currentBrokers[broker.NodeId]; !ok {
+			delta.BrokersLeft = append(delta.BrokersLeft, broker)
+		}
+	}
+
+	previousLeaders := leadersByPartition(previous)
+	currentLeaders := leadersByPartition(current)
+	for partitionID := int32(1); partitionID <= partitionsCount(previous, current); partitionID++ {
+		previousLeader, currentLeader := leaderOf(previousLeaders, partitionID), leaderOf(currentLeaders, partitionID)
+		if previousLeader != currentLeader {
+			delta.LeaderChanges = append(delta.LeaderChanges, LeaderChange{
+				PartitionID:    partitionID,
+				PreviousLeader: previousLeader,
+				Leader:         currentLeader,
+			})
+		}
+	}
+
+	return delta
+}
+
+func brokersByID(topology *pb.TopologyResponse) map[int32]*pb.BrokerInfo {
+	brokers := make(map[int32]*pb.BrokerInfo)
+	for _, broker := range topology.GetBrokers() {
+		brokers[broker.NodeId] = broker
+	}
+	return brokers
+}
+
+func leadersByPartition(topology *pb.TopologyResponse) map[int32]int32 {
+	leaders := make(map[int32]int32)
+	for _, broker := range topology.GetBrokers() {
+		for _, partition := range broker.GetPartitions() {
+			if partition.Role == pb.Partition_LEADER {
+				leaders[partition.PartitionId] = broker.NodeId
+			}
+		}
+	}
+	return leaders
+}
+
+func leaderOf(leaders map[int32]int32, partitionID int32) int32 {
+	if leader, ok := leaders[partitionID]; ok {
+		return leader
+	}
+	return NoLeader
+}
+
+func partitionsCount(previous, current *pb.TopologyResponse) int32 {
+	if previous.GetPartitionsCount() > current.GetPartitionsCount() {
+		return previous.GetPartitionsCount()
+	}
+	return current.GetPartitionsCount()
+}
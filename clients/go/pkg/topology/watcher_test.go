@@ -0,0 +1,119 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topology
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type gatewayClient struct {
+	gateway pb.GatewayClient
+}
+
+func (c gatewayClient) NewTopologyCommand() *commands.TopologyCommand {
+	return commands.NewTopologyCommand(c.gateway, func(context.Context, error) bool { return false })
+}
+
+func broker(nodeID int32, leaderOf ...int32) *pb.BrokerInfo {
+	info := &pb.BrokerInfo{NodeId: nodeID}
+	for _, partitionID := range leaderOf {
+		info.Partitions = append(info.Partitions, &pb.Partition{PartitionId: partitionID, Role: pb.Partition_LEADER})
+	}
+	return info
+}
+
+func TestDiffReportsBrokersAndLeaders(t *testing.T) {
+	// given
+	previous := &pb.TopologyResponse{PartitionsCount: 2, Brokers: []*pb.BrokerInfo{broker(0, 1), broker(1, 2)}}
+	current := &pb.TopologyResponse{PartitionsCount: 2, Brokers: []*pb.BrokerInfo{broker(0, 1, 2), broker(2)}}
+
+	// when
+	delta := Diff(previous, current)
+
+	// then
+	assert.Equal(t, current, delta.Topology)
+	assert.Equal(t, []*pb.BrokerInfo{current.Brokers[1]}, delta.BrokersJoined)
+	assert.Equal(t, []*pb.BrokerInfo{previous.Brokers[1]}, delta.BrokersLeft)
+	assert.Equal(t, []LeaderChange{{PartitionID: 2, PreviousLeader: 1, Leader: 0}}, delta.LeaderChanges)
+}
+
+func TestDiffReportsPartitionWithoutLeader(t *testing.T) {
+	// given
+	previous := &pb.TopologyResponse{PartitionsCount: 1, Brokers: []*pb.BrokerInfo{broker(0, 1)}}
+	current := &pb.TopologyResponse{PartitionsCount: 1, Brokers: []*pb.BrokerInfo{broker(0)}}
+
+	// when
+	delta := Diff(previous, current)
+
+	// then
+	assert.Empty(t, delta.BrokersJoined)
+	assert.Empty(t, delta.BrokersLeft)
+	assert.Equal(t, []LeaderChange{{PartitionID: 1, PreviousLeader: 0, Leader: NoLeader}}, delta.LeaderChanges)
+}
+
+func TestWatcherNotifiesOnlyChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// given
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	stable := &pb.TopologyResponse{PartitionsCount: 1, Brokers: []*pb.BrokerInfo{broker(0, 1)}}
+	changed := &pb.TopologyResponse{PartitionsCount: 1, Brokers: []*pb.BrokerInfo{broker(0), broker(1, 1)}}
+	failure := errors.New("unavailable")
+	gomock.InOrder(
+		gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(stable, nil),
+		gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(stable, nil),
+		gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(nil, failure),
+		gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(changed, nil),
+		gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(changed, nil).AnyTimes(),
+	)
+
+	deltas := make(chan Delta, 2)
+	errs := make(chan error, 1)
+	watcher := &Watcher{
+		Client:   gatewayClient{gateway},
+		Interval: time.Millisecond,
+		OnChange: func(delta Delta) { deltas <- delta },
+		OnError:  func(err error) { errs <- err },
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- watcher.Watch(ctx) }()
+
+	// when
+	initial := <-deltas
+	err := <-errs
+	change := <-deltas
+	cancel()
+
+	// then
+	assert.Equal(t, context.Canceled, <-done)
+	assert.Len(t, initial.BrokersJoined, 1)
+	assert.Equal(t, failure, err)
+	assert.Len(t, change.BrokersJoined, 1)
+	assert.Equal(t, []LeaderChange{{PartitionID: 1, PreviousLeader: 0, Leader: 1}}, change.LeaderChanges)
+}
@@ -0,0 +1,351 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+// ErrNoActivationSlot is returned by ActivationDispatcher.ActivateJobs, wrapped, if the context is done while the
+// request waits for a free activation slot. The gateway was not asked, so job workers neither count it as a failed
+// activation nor back off.
+var ErrNoActivationSlot = errors.New("no free activation slot")
+
+// ActivationDispatcher is a gateway client shared by the job workers of a client. It limits how many ActivateJobs
+// requests are in flight at the same time across all workers; further requests wait in order of arrival until a
+// request finished or their context is done. Waiting requests for the same job type, worker, job timeout and fetched
+// variables are coalesced into one request, whose jobs are split between them. Only all but one of the requests in
+// flight long poll, so the requests of other job types are sent without long polling instead of waiting for long polls
+// to time out; with a single slot, no request long polls. All other commands are passed through unchanged.
+type ActivationDispatcher struct {
+	pb.GatewayClient
+	slots         chan struct{}
+	longPollSlots chan struct{}
+
+	mutex   sync.Mutex
+	pending map[activationKey]*activationBatch
+}
+
+// NewActivationDispatcher creates a dispatcher which allows at most maxConcurrentActivations ActivateJobs requests to
+// be in flight at the same time.
+func NewActivationDispatcher(gateway pb.GatewayClient, maxConcurrentActivations int) *ActivationDispatcher {
+	longPollSlots := maxConcurrentActivations - 1
+	if longPollSlots < 0 {
+		longPollSlots = 0
+	}
+
+	return &ActivationDispatcher{
+		GatewayClient: gateway,
+		slots:         make(chan struct{}, maxConcurrentActivations),
+		longPollSlots: make(chan struct{}, longPollSlots),
+		pending:       map[activationKey]*activationBatch{},
+	}
+}
+
+// activationKey identifies the requests which can be coalesced, as they only differ in the number of jobs to activate.
+type activationKey struct {
+	jobType        string
+	worker         string
+	timeout        int64
+	fetchVariables string
+	longPoll       bool
+}
+
+func activationKeyOf(request *pb.ActivateJobsRequest) activationKey {
+	return activationKey{
+		jobType:        request.Type,
+		worker:         request.Worker,
+		timeout:        request.Timeout,
+		fetchVariables: strings.Join(request.FetchVariable, "\x00"),
+		longPoll:       request.RequestTimeout >= 0,
+	}
+}
+
+// activationBatch is a request which is sent for the coalesced requests of its members, once a slot is free.
+type activationBatch struct {
+	key       activationKey
+	ctx       context.Context
+	request   *pb.ActivateJobsRequest
+	opts      []grpc.CallOption
+	members   []*activationMember
+	taken     bool
+	abandoned chan struct{}
+
+	sent   chan struct{}
+	stream pb.Gateway_ActivateJobsClient
+	err    error
+}
+
+// activationMember is one of the coalesced requests of a batch, which receives up to remaining jobs of its responses.
+type activationMember struct {
+	ctx       context.Context
+	remaining int32
+	responses chan *pb.ActivateJobsResponse
+	err       error
+}
+
+// ActivateJobs waits for a free activation slot, coalesced with other waiting requests, and then sends the request.
+// The slot is released once the response of the gateway ended or the contexts of all coalesced requests are done.
+func (d *ActivationDispatcher) ActivateJobs(ctx context.Context, in *pb.ActivateJobsRequest, opts ...grpc.CallOption) (pb.Gateway_ActivateJobsClient, error) {
+	member := &activationMember{ctx: ctx, remaining: in.MaxJobsToActivate, responses: make(chan *pb.ActivateJobsResponse)}
+	batch := d.join(ctx, in, opts, member)
+
+	select {
+	case <-batch.sent:
+	case <-ctx.Done():
+		if d.leave(batch, member) {
+			return nil, fmt.Errorf("%w for jobs of type '%s': %v", ErrNoActivationSlot, in.Type, ctx.Err())
+		}
+		// the batch is sent already, so the stream reports that the context is done
+		<-batch.sent
+	}
+
+	if batch.err != nil {
+		return nil, batch.err
+	}
+	return &activationMemberStream{Gateway_ActivateJobsClient: batch.stream, member: member}, nil
+}
+
+// join adds the member to the waiting batch of the request, or creates one which is sent once a slot is free.
+func (d *ActivationDispatcher) join(ctx context.Context, in *pb.ActivateJobsRequest, opts []grpc.CallOption, member *activationMember) *activationBatch {
+	key := activationKeyOf(in)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if batch, ok := d.pending[key]; ok {
+		batch.members = append(batch.members, member)
+		batch.request.MaxJobsToActivate += member.remaining
+		return batch
+	}
+
+	batch := &activationBatch{
+		key:       key,
+		ctx:       ctx,
+		request:   cloneActivateJobsRequest(in),
+		opts:      opts,
+		members:   []*activationMember{member},
+		abandoned: make(chan struct{}),
+		sent:      make(chan struct{}),
+	}
+	d.pending[key] = batch
+	go d.send(batch)
+	return batch
+}
+
+// leave removes the member from its batch and returns true, unless the batch is sent already.
+func (d *ActivationDispatcher) leave(batch *activationBatch, member *activationMember) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if batch.taken {
+		return false
+	}
+
+	for i, m := range batch.members {
+		if m == member {
+			batch.members = append(batch.members[:i], batch.members[i+1:]...)
+			batch.request.MaxJobsToActivate -= member.remaining
+			break
+		}
+	}
+	if len(batch.members) == 0 {
+		delete(d.pending, batch.key)
+		batch.taken = true
+		close(batch.abandoned)
+	}
+	return true
+}
+
+func (d *ActivationDispatcher) send(batch *activationBatch) {
+	select {
+	case d.slots <- struct{}{}:
+	case <-batch.abandoned:
+		return
+	}
+
+	d.mutex.Lock()
+	if batch.taken {
+		d.mutex.Unlock()
+		<-d.slots
+		return
+	}
+	batch.taken = true
+	delete(d.pending, batch.key)
+	members := batch.members
+	d.mutex.Unlock()
+
+	longPoll := false
+	if batch.request.RequestTimeout >= 0 {
+		select {
+		case d.longPollSlots <- struct{}{}:
+			longPoll = true
+		default:
+			batch.request.RequestTimeout = -1
+		}
+	}
+	release := func() {
+		if longPoll {
+			<-d.longPollSlots
+		}
+		<-d.slots
+	}
+
+	ctx, cancel := membersContext(batch.ctx, members)
+	batch.stream, batch.err = d.GatewayClient.ActivateJobs(ctx, batch.request, batch.opts...)
+	if batch.err != nil {
+		cancel()
+		release()
+		close(batch.sent)
+		return
+	}
+	close(batch.sent)
+
+	distributeActivatedJobs(batch.stream, members)
+	cancel()
+	release()
+}
+
+// membersContext returns a context with the values of the given one, which is done when the contexts of all members
+// are done, with the latest deadline of them.
+func membersContext(ctx context.Context, members []*activationMember) (context.Context, context.CancelFunc) {
+	var deadline time.Time
+	for _, member := range members {
+		memberDeadline, ok := member.ctx.Deadline()
+		if !ok {
+			deadline = time.Time{}
+			break
+		}
+		if memberDeadline.After(deadline) {
+			deadline = memberDeadline
+		}
+	}
+
+	var cancel context.CancelFunc
+	ctx = detachedContext{ctx}
+	if deadline.IsZero() {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+	}
+
+	go func() {
+		for _, member := range members {
+			select {
+			case <-member.ctx.Done():
+			case <-ctx.Done():
+				return
+			}
+		}
+		cancel()
+	}()
+
+	return ctx, cancel
+}
+
+// distributeActivatedJobs passes the jobs of the responses to the members in order, up to their remaining number of
+// jobs. Jobs of members whose context is done are passed to the next member, so they are not lost while another member
+// is waiting; once the stream ended, its error is returned to all members.
+func distributeActivatedJobs(stream pb.Gateway_ActivateJobsClient, members []*activationMember) {
+	for {
+		response, err := stream.Recv()
+		if err != nil {
+			for _, member := range members {
+				member.err = err
+				close(member.responses)
+			}
+			return
+		}
+
+		jobs := response.Jobs
+		for i, member := range members {
+			if len(jobs) == 0 {
+				break
+			}
+
+			count := int(member.remaining)
+			if count > len(jobs) || i == len(members)-1 {
+				count = len(jobs)
+			}
+			if count == 0 {
+				continue
+			}
+
+			select {
+			case member.responses <- &pb.ActivateJobsResponse{Jobs: jobs[:count]}:
+				member.remaining -= int32(count)
+				jobs = jobs[count:]
+			case <-member.ctx.Done():
+				member.remaining = 0
+			}
+		}
+	}
+}
+
+func cloneActivateJobsRequest(request *pb.ActivateJobsRequest) *pb.ActivateJobsRequest {
+	return &pb.ActivateJobsRequest{
+		Type:              request.Type,
+		Worker:            request.Worker,
+		Timeout:           request.Timeout,
+		MaxJobsToActivate: request.MaxJobsToActivate,
+		FetchVariable:     request.FetchVariable,
+		RequestTimeout:    request.RequestTimeout,
+	}
+}
+
+// detachedContext keeps the values of a context, e.g. its metadata, without its deadline and cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// activationMemberStream receives the jobs of a member of a coalesced request.
+type activationMemberStream struct {
+	pb.Gateway_ActivateJobsClient
+	member *activationMember
+}
+
+func (s *activationMemberStream) Recv() (*pb.ActivateJobsResponse, error) {
+	select {
+	case response, ok := <-s.member.responses:
+		if !ok {
+			return nil, s.member.err
+		}
+		return response, nil
+	case <-s.member.ctx.Done():
+		return nil, status.FromContextError(s.member.ctx.Err()).Err()
+	}
+}
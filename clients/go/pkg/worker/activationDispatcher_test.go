@@ -0,0 +1,265 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"google.golang.org/grpc/status"
+)
+
+// blockingActivation returns a stream which ends once finish is closed or the context of the request is done.
+func blockingActivation(ctrl *gomock.Controller, ctx context.Context, finish <-chan struct{}) pb.Gateway_ActivateJobsClient {
+	stream := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+	stream.EXPECT().Recv().DoAndReturn(func() (*pb.ActivateJobsResponse, error) {
+		select {
+		case <-finish:
+			return nil, io.EOF
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	})
+	return stream
+}
+
+func endedActivation(ctrl *gomock.Controller, jobs ...*pb.ActivatedJob) pb.Gateway_ActivateJobsClient {
+	stream := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+	if len(jobs) > 0 {
+		stream.EXPECT().Recv().Return(&pb.ActivateJobsResponse{Jobs: jobs}, nil)
+	}
+	stream.EXPECT().Recv().Return(nil, io.EOF)
+	return stream
+}
+
+func TestActivationDispatcherLimitsConcurrentActivations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	finish := make(chan struct{})
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	gomock.InOrder(
+		client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ *pb.ActivateJobsRequest, _ ...interface{}) (pb.Gateway_ActivateJobsClient, error) {
+				return blockingActivation(ctrl, ctx, finish), nil
+			}),
+		client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(endedActivation(ctrl), nil),
+	)
+
+	dispatcher := NewActivationDispatcher(client, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	first, err := dispatcher.ActivateJobs(ctx, &pb.ActivateJobsRequest{Type: "foo"})
+	if err != nil {
+		t.Fatalf("Failed to activate jobs: %v", err)
+	}
+
+	// the second activation must wait until the first request ended
+	secondActivated := make(chan error, 1)
+	go func() {
+		second, err := dispatcher.ActivateJobs(ctx, &pb.ActivateJobsRequest{Type: "bar"})
+		if err == nil {
+			_, err = second.Recv()
+		}
+		secondActivated <- err
+	}()
+
+	select {
+	case <-secondActivated:
+		t.Fatal("Expected second activation to wait for a free slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(finish)
+	if _, err := first.Recv(); err != io.EOF {
+		t.Fatalf("Expected first stream to end, got %v", err)
+	}
+
+	select {
+	case err := <-secondActivated:
+		if err != io.EOF {
+			t.Errorf("Expected second stream to end, got %v", err)
+		}
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("Expected second activation to proceed after first request ended")
+	}
+}
+
+func TestActivationDispatcherTimesOutWaitingForSlot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	finish := make(chan struct{})
+	defer close(finish)
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ *pb.ActivateJobsRequest, _ ...interface{}) (pb.Gateway_ActivateJobsClient, error) {
+			return blockingActivation(ctrl, ctx, finish), nil
+		})
+
+	dispatcher := NewActivationDispatcher(client, 1)
+	_, err := dispatcher.ActivateJobs(context.Background(), &pb.ActivateJobsRequest{Type: "foo"})
+	if err != nil {
+		t.Fatalf("Failed to activate jobs: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = dispatcher.ActivateJobs(ctx, &pb.ActivateJobsRequest{Type: "bar"})
+	if !errors.Is(err, ErrNoActivationSlot) {
+		t.Errorf("Expected to time out waiting for slot, got %v", err)
+	}
+}
+
+func TestActivationDispatcherReleasesSlotWhenContextIsDone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	gomock.InOrder(
+		client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ *pb.ActivateJobsRequest, _ ...interface{}) (pb.Gateway_ActivateJobsClient, error) {
+				return blockingActivation(ctrl, ctx, nil), nil
+			}),
+		client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(endedActivation(ctrl), nil),
+	)
+
+	dispatcher := NewActivationDispatcher(client, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := dispatcher.ActivateJobs(ctx, &pb.ActivateJobsRequest{Type: "foo"}); err != nil {
+		t.Fatalf("Failed to activate jobs: %v", err)
+	}
+	cancel()
+
+	timeoutCtx, timeoutCancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer timeoutCancel()
+
+	second, err := dispatcher.ActivateJobs(timeoutCtx, &pb.ActivateJobsRequest{Type: "bar"})
+	if err != nil {
+		t.Fatalf("Expected slot to be released after context was canceled, got %v", err)
+	}
+	_, err = second.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestActivationDispatcherDoesNotLetLongPollsBlockOtherJobTypes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	finish := make(chan struct{})
+	defer close(finish)
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *pb.ActivateJobsRequest, _ ...interface{}) (pb.Gateway_ActivateJobsClient, error) {
+			if request.Type == "foo" {
+				assert.EqualValues(t, 0, request.RequestTimeout, "expected first activation to long poll")
+				return blockingActivation(ctrl, ctx, finish), nil
+			}
+			assert.EqualValues(t, -1, request.RequestTimeout, "expected activation of %s not to long poll", request.Type)
+			return endedActivation(ctrl), nil
+		}).Times(3)
+
+	dispatcher := NewActivationDispatcher(client, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	if _, err := dispatcher.ActivateJobs(ctx, &pb.ActivateJobsRequest{Type: "foo"}); err != nil {
+		t.Fatalf("Failed to activate jobs: %v", err)
+	}
+
+	// while foo long polls, the other job type is activated one after another
+	for _, jobType := range []string{"bar", "baz"} {
+		stream, err := dispatcher.ActivateJobs(ctx, &pb.ActivateJobsRequest{Type: jobType})
+		if err != nil {
+			t.Fatalf("Failed to activate jobs of type %s: %v", jobType, err)
+		}
+		if _, err := stream.Recv(); err != io.EOF {
+			t.Fatalf("Expected activation of %s to end, got %v", jobType, err)
+		}
+	}
+}
+
+func TestActivationDispatcherCoalescesWaitingActivations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	finish := make(chan struct{})
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	jobs := []*pb.ActivatedJob{{Key: 1}, {Key: 2}, {Key: 3}, {Key: 4}}
+	gomock.InOrder(
+		client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ *pb.ActivateJobsRequest, _ ...interface{}) (pb.Gateway_ActivateJobsClient, error) {
+				return blockingActivation(ctrl, ctx, finish), nil
+			}),
+		client.EXPECT().ActivateJobs(gomock.Any(), &rpcMsg{msg: &pb.ActivateJobsRequest{Type: "foo", MaxJobsToActivate: 5, RequestTimeout: -1}}).
+			Return(endedActivation(ctrl, jobs...), nil),
+	)
+
+	dispatcher := NewActivationDispatcher(client, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	if _, err := dispatcher.ActivateJobs(ctx, &pb.ActivateJobsRequest{Type: "block"}); err != nil {
+		t.Fatalf("Failed to activate jobs: %v", err)
+	}
+
+	activated := make(chan map[int32][]int64, 2)
+	for _, maxJobs := range []int32{2, 3} {
+		go func(maxJobs int32) {
+			stream, err := dispatcher.ActivateJobs(ctx, &pb.ActivateJobsRequest{Type: "foo", MaxJobsToActivate: maxJobs})
+			var keys []int64
+			for err == nil {
+				var response *pb.ActivateJobsResponse
+				if response, err = stream.Recv(); err == nil {
+					for _, job := range response.Jobs {
+						keys = append(keys, job.Key)
+					}
+				}
+			}
+			assert.Equal(t, io.EOF, err)
+			activated <- map[int32][]int64{maxJobs: keys}
+		}(maxJobs)
+	}
+
+	assert.Eventually(t, func() bool {
+		dispatcher.mutex.Lock()
+		defer dispatcher.mutex.Unlock()
+		batch, ok := dispatcher.pending[activationKeyOf(&pb.ActivateJobsRequest{Type: "foo"})]
+		return ok && len(batch.members) == 2
+	}, utils.DefaultTestTimeout, time.Millisecond)
+	close(finish)
+
+	// the jobs are split between the activations, up to their maximum number of jobs
+	var keys []int64
+	for i := 0; i < 2; i++ {
+		for maxJobs, activatedKeys := range <-activated {
+			assert.LessOrEqual(t, len(activatedKeys), int(maxJobs))
+			keys = append(keys, activatedKeys...)
+		}
+	}
+	assert.ElementsMatch(t, []int64{1, 2, 3, 4}, keys)
+}
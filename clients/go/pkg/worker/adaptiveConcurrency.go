@@ -0,0 +1,40 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+// adaptiveLimit adjusts the number of jobs a worker activates at the same time with additive increase and
+// multiplicative decrease: it is halved whenever the gateway signals backpressure and grows by one after every
+// activation without backpressure, up to the configured maximum.
+type adaptiveLimit struct {
+	limit int
+	max   int
+}
+
+func newAdaptiveLimit(max int) *adaptiveLimit {
+	return &adaptiveLimit{limit: max, max: max}
+}
+
+func (l *adaptiveLimit) onBackpressure() {
+	l.limit /= 2
+	if l.limit < 1 {
+		l.limit = 1
+	}
+}
+
+func (l *adaptiveLimit) onSuccess() {
+	if l.limit < l.max {
+		l.limit++
+	}
+}
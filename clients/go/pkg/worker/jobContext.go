@@ -0,0 +1,41 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+)
+
+// ContextJobHandler processes a job like a FallibleJobHandler, with a context which is done when the job deadline
+// passes, i.e. when the broker may have activated the job for another worker, or when the worker is closed. The
+// context carries the entities.JobInfo of the job, see entities.Job.Context. Returned errors are passed to the
+// FailureHandler of the worker.
+type ContextJobHandler func(ctx context.Context, client JobClient, job entities.Job) error
+
+// withJobContext adapts the handler, deriving the context of each job from the context of the worker.
+func withJobContext(workerCtx context.Context, handler ContextJobHandler) FallibleJobHandler {
+	return func(client JobClient, job entities.Job) error {
+		ctx, cancel := jobContext(workerCtx, &job)
+		defer cancel()
+
+		return handler(ctx, client, job)
+	}
+}
+
+func jobContext(workerCtx context.Context, job *entities.Job) (context.Context, context.CancelFunc) {
+	return entities.NewJobContext(workerCtx, job)
+}
@@ -0,0 +1,102 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func TestJobWorkerContextHandlerDeadline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	deadline := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+	client := activateOnce(ctrl, &pb.ActivatedJob{Key: 1, Deadline: deadline.UnixNano() / int64(time.Millisecond)})
+	deadlines := make(chan time.Time, 1)
+
+	worker := NewJobWorkerBuilder(client, nil).JobType("foo").ContextHandler(func(ctx context.Context, _ JobClient, _ entities.Job) error {
+		jobDeadline, _ := ctx.Deadline()
+		deadlines <- jobDeadline
+		return nil
+	}).Open()
+	defer worker.Close()
+
+	select {
+	case jobDeadline := <-deadlines:
+		assert.True(t, deadline.Equal(jobDeadline), "expected deadline %v, got %v", deadline, jobDeadline)
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected handler to be called")
+	}
+}
+
+func TestJobWorkerContextHandlerCanceledOnClose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	deadline := time.Now().Add(time.Minute).UnixNano() / int64(time.Millisecond)
+	client := activateOnce(ctrl, &pb.ActivatedJob{Key: 1, Deadline: deadline})
+	started := make(chan struct{}, 1)
+	handled := make(chan error, 1)
+
+	worker := NewJobWorkerBuilder(client, nil).JobType("foo").ContextHandler(func(ctx context.Context, _ JobClient, _ entities.Job) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		handled <- ctx.Err()
+		return nil
+	}).Open()
+
+	<-started
+	worker.Close()
+
+	select {
+	case err := <-handled:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected handler context to be canceled")
+	}
+}
+
+func TestJobWorkerContextHandlerFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := activateOnce(ctrl, &pb.ActivatedJob{Key: 1, Retries: 3})
+	request := &pb.FailJobRequest{JobKey: 1, Retries: 2, ErrorMessage: assert.AnError.Error()}
+	failed := make(chan struct{})
+	client.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: request}).DoAndReturn(
+		func(context.Context, *pb.FailJobRequest, ...interface{}) (*pb.FailJobResponse, error) {
+			close(failed)
+			return &pb.FailJobResponse{}, nil
+		})
+
+	worker := NewJobWorkerBuilder(client, gatewayJobClient{client}).JobType("foo").ContextHandler(func(context.Context, JobClient, entities.Job) error {
+		return assert.AnError
+	}).Open()
+	defer worker.Close()
+
+	select {
+	case <-failed:
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected job to be failed")
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/jsonschema"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+// DefaultDeduplicationCapacity is the number of processed jobs remembered by a MemoryDeduplicationStore by default.
+const DefaultDeduplicationCapacity = 10000
+
+// DeduplicationStore records which jobs were processed by the handler of a worker, so jobs which are activated again
+// with the same retries, e.g. because completing them failed and they timed out, are not processed twice. The store
+// implementation must be thread-safe; to deduplicate across workers it has to be shared by them, e.g. a database.
+type DeduplicationStore interface {
+	// Begin records that the handler is invoked for the job with the given retries, and returns whether the job was
+	// already processed successfully with these retries
+	Begin(ctx context.Context, jobKey int64, retries int32) (processed bool, err error)
+	// Succeed records that the job was processed successfully with the given retries
+	Succeed(ctx context.Context, jobKey int64, retries int32) error
+}
+
+type processedJob struct {
+	key     int64
+	retries int32
+}
+
+// MemoryDeduplicationStore is a DeduplicationStore which remembers the most recently processed jobs in memory, so it
+// only deduplicates jobs which are activated again by the same worker process.
+type MemoryDeduplicationStore struct {
+	mutex     sync.Mutex
+	processed map[processedJob]struct{}
+	order     []processedJob
+	next      int
+}
+
+// NewMemoryDeduplicationStore creates a MemoryDeduplicationStore which remembers up to capacity processed jobs, or
+// DefaultDeduplicationCapacity if capacity is not positive.
+func NewMemoryDeduplicationStore(capacity int) *MemoryDeduplicationStore {
+	if capacity <= 0 {
+		capacity = DefaultDeduplicationCapacity
+	}
+	return &MemoryDeduplicationStore{
+		processed: make(map[processedJob]struct{}, capacity),
+		order:     make([]processedJob, 0, capacity),
+	}
+}
+
+func (s *MemoryDeduplicationStore) Begin(_ context.Context, jobKey int64, retries int32) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, processed := s.processed[processedJob{key: jobKey, retries: retries}]
+	return processed, nil
+}
+
+func (s *MemoryDeduplicationStore) Succeed(_ context.Context, jobKey int64, retries int32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job := processedJob{key: jobKey, retries: retries}
+	if _, ok := s.processed[job]; ok {
+		return nil
+	}
+
+	if len(s.order) < cap(s.order) {
+		s.order = append(s.order, job)
+	} else {
+		delete(s.processed, s.order[s.next])
+		s.order[s.next] = job
+		s.next = (s.next + 1) % len(s.order)
+	}
+	s.processed[job] = struct{}{}
+	return nil
+}
+
+// deduplicateJobs wraps the handler, so it is not invoked for jobs which the store reports as processed with the same
+// retries. A job is processed successfully if the handler completed it, i.e. sent a command to complete it which
+// succeeded, and created none to fail it or to throw an error for it. Skipped jobs are not completed again, since
+// their completion was accepted by the gateway already; if errors of the store occur, the handler is invoked.
+func deduplicateJobs(store DeduplicationStore, requestTimeout time.Duration, logger logging.Logger, handler JobHandler) JobHandler {
+	return func(client JobClient, job entities.Job) {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		processed, err := store.Begin(ctx, job.Key, job.Retries)
+		cancel()
+		if err != nil {
+			logger.Warn("Failed to look up job in deduplication store, handling it", "jobKey", job.Key, "error", err)
+		}
+
+		if processed {
+			logger.Debug("Skipping job which was already processed", "jobKey", job.Key, "retries", job.Retries)
+			return
+		}
+
+		tracking := &trackingJobClient{JobClient: client}
+		handler(tracking.withOptionalCommands(client), job)
+
+		if !tracking.succeeded() {
+			return
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+		if err := store.Succeed(ctx, job.Key, job.Retries); err != nil {
+			logger.Warn("Failed to record processed job in deduplication store", "jobKey", job.Key, "error", err)
+		}
+	}
+}
+
+// trackingJobClient records which commands the handler created for its job.
+type trackingJobClient struct {
+	JobClient
+
+	mutex     sync.Mutex
+	completed bool
+	failed    bool
+}
+
+func (c *trackingJobClient) NewCompleteJobCommand() commands.CompleteJobCommandStep1 {
+	return &trackingCompleteJobStep1{step: c.JobClient.NewCompleteJobCommand(), client: c}
+}
+
+func (c *trackingJobClient) markCompleted() {
+	c.mutex.Lock()
+	c.completed = true
+	c.mutex.Unlock()
+}
+
+func (c *trackingJobClient) NewFailJobCommand() commands.FailJobCommandStep1 {
+	c.markFailed()
+	return c.JobClient.NewFailJobCommand()
+}
+
+func (c *trackingJobClient) markFailed() {
+	c.mutex.Lock()
+	c.failed = true
+	c.mutex.Unlock()
+}
+
+func (c *trackingJobClient) succeeded() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.completed && !c.failed
+}
+
+// withOptionalCommands returns the tracking client with the optional commands the given client can create, like
+// throwing errors and setting variables, e.g. for BPMN errors with variables.
+func (c *trackingJobClient) withOptionalCommands(client JobClient) JobClient {
+	thrower, throws := client.(errorThrower)
+	setter, sets := client.(variablesSetter)
+	switch {
+	case throws && sets:
+		return &trackingThrowingVariablesJobClient{trackingThrowingJobClient{trackingJobClient: c, thrower: thrower}, setter}
+	case throws:
+		return &trackingThrowingJobClient{trackingJobClient: c, thrower: thrower}
+	case sets:
+		return &trackingVariablesJobClient{trackingJobClient: c, setter: setter}
+	default:
+		return c
+	}
+}
+
+type trackingThrowingJobClient struct {
+	*trackingJobClient
+	thrower errorThrower
+}
+
+func (c *trackingThrowingJobClient) NewThrowErrorCommand() commands.ThrowErrorCommandStep1 {
+	c.markFailed()
+	return c.thrower.NewThrowErrorCommand()
+}
+
+type trackingVariablesJobClient struct {
+	*trackingJobClient
+	setter variablesSetter
+}
+
+func (c *trackingVariablesJobClient) NewSetVariablesCommand() commands.SetVariablesCommandStep1 {
+	return c.setter.NewSetVariablesCommand()
+}
+
+type trackingThrowingVariablesJobClient struct {
+	trackingThrowingJobClient
+	setter variablesSetter
+}
+
+func (c *trackingThrowingVariablesJobClient) NewSetVariablesCommand() commands.SetVariablesCommandStep1 {
+	return c.setter.NewSetVariablesCommand()
+}
+
+// trackingCompleteJobStep1 and the types below wrap the steps of the command to complete the job, so the job is only
+// recorded as completed once the command was sent successfully.
+type trackingCompleteJobStep1 struct {
+	step   commands.CompleteJobCommandStep1
+	client *trackingJobClient
+}
+
+func (s *trackingCompleteJobStep1) JobKey(jobKey int64) commands.CompleteJobCommandStep2 {
+	return &trackingCompleteJobStep2{CompleteJobCommandStep2: s.step.JobKey(jobKey), client: s.client}
+}
+
+type trackingCompleteJobStep2 struct {
+	commands.CompleteJobCommandStep2
+	client *trackingJobClient
+}
+
+func (s *trackingCompleteJobStep2) dispatch(dispatch commands.DispatchCompleteJobCommand, err error) (commands.DispatchCompleteJobCommand, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &trackingCompleteJobDispatch{DispatchCompleteJobCommand: dispatch, client: s.client}, nil
+}
+
+func (s *trackingCompleteJobStep2) VariablesFromString(variables string) (commands.DispatchCompleteJobCommand, error) {
+	return s.dispatch(s.CompleteJobCommandStep2.VariablesFromString(variables))
+}
+
+func (s *trackingCompleteJobStep2) VariablesFromStringer(variables fmt.Stringer) (commands.DispatchCompleteJobCommand, error) {
+	return s.dispatch(s.CompleteJobCommandStep2.VariablesFromStringer(variables))
+}
+
+func (s *trackingCompleteJobStep2) VariablesFromMap(variables map[string]interface{}, onlyKeys ...string) (commands.DispatchCompleteJobCommand, error) {
+	return s.dispatch(s.CompleteJobCommandStep2.VariablesFromMap(variables, onlyKeys...))
+}
+
+func (s *trackingCompleteJobStep2) VariablesFromObject(variables interface{}) (commands.DispatchCompleteJobCommand, error) {
+	return s.dispatch(s.CompleteJobCommandStep2.VariablesFromObject(variables))
+}
+
+func (s *trackingCompleteJobStep2) VariablesFromObjectIgnoreOmitempty(variables interface{}) (commands.DispatchCompleteJobCommand, error) {
+	return s.dispatch(s.CompleteJobCommandStep2.VariablesFromObjectIgnoreOmitempty(variables))
+}
+
+func (s *trackingCompleteJobStep2) ProcessVariables(variables map[string]interface{}) (commands.DispatchCompleteJobCommand, error) {
+	return s.dispatch(s.CompleteJobCommandStep2.ProcessVariables(variables))
+}
+
+func (s *trackingCompleteJobStep2) LocalVariables(elementInstanceKey int64, variables map[string]interface{}) (commands.CompleteJobCommandStep2, error) {
+	step, err := s.CompleteJobCommandStep2.LocalVariables(elementInstanceKey, variables)
+	if err != nil {
+		return nil, err
+	}
+	return &trackingCompleteJobStep2{CompleteJobCommandStep2: step, client: s.client}, nil
+}
+
+func (s *trackingCompleteJobStep2) RequestTimeout(timeout time.Duration) commands.DispatchCompleteJobCommand {
+	return &trackingCompleteJobDispatch{DispatchCompleteJobCommand: s.CompleteJobCommandStep2.RequestTimeout(timeout), client: s.client}
+}
+
+func (s *trackingCompleteJobStep2) OutputSchema(schema *jsonschema.Schema) commands.DispatchCompleteJobCommand {
+	return &trackingCompleteJobDispatch{DispatchCompleteJobCommand: s.CompleteJobCommandStep2.OutputSchema(schema), client: s.client}
+}
+
+func (s *trackingCompleteJobStep2) Send(ctx context.Context) (*pb.CompleteJobResponse, error) {
+	response, err := s.CompleteJobCommandStep2.Send(ctx)
+	if err == nil {
+		s.client.markCompleted()
+	}
+	return response, err
+}
+
+type trackingCompleteJobDispatch struct {
+	commands.DispatchCompleteJobCommand
+	client *trackingJobClient
+}
+
+func (d *trackingCompleteJobDispatch) RequestTimeout(timeout time.Duration) commands.DispatchCompleteJobCommand {
+	return &trackingCompleteJobDispatch{DispatchCompleteJobCommand: d.DispatchCompleteJobCommand.RequestTimeout(timeout), client: d.client}
+}
+
+func (d *trackingCompleteJobDispatch) OutputSchema(schema *jsonschema.Schema) commands.DispatchCompleteJobCommand {
+	return &trackingCompleteJobDispatch{DispatchCompleteJobCommand: d.DispatchCompleteJobCommand.OutputSchema(schema), client: d.client}
+}
+
+func (d *trackingCompleteJobDispatch) Send(ctx context.Context) (*pb.CompleteJobResponse, error) {
+	response, err := d.DispatchCompleteJobCommand.Send(ctx)
+	if err == nil {
+		d.client.markCompleted()
+	}
+	return response, err
+}
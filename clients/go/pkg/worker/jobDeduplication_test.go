@@ -0,0 +1,187 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func TestMemoryDeduplicationStoreEvictsOldestJob(t *testing.T) {
+	store := NewMemoryDeduplicationStore(2)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Succeed(ctx, 1, 3))
+	assert.NoError(t, store.Succeed(ctx, 2, 3))
+	assert.NoError(t, store.Succeed(ctx, 3, 3))
+
+	processed, _ := store.Begin(ctx, 1, 3)
+	assert.False(t, processed)
+	processed, _ = store.Begin(ctx, 2, 3)
+	assert.True(t, processed)
+	processed, _ = store.Begin(ctx, 3, 3)
+	assert.True(t, processed)
+	processed, _ = store.Begin(ctx, 3, 2)
+	assert.False(t, processed, "expected job with other retries not to be processed")
+}
+
+func TestDeduplicationSkipsProcessedJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	request := &pb.CompleteJobRequest{JobKey: 1, Variables: `{"paid":true}`}
+	client.EXPECT().CompleteJob(gomock.Any(), &rpcMsg{msg: request}).Return(&pb.CompleteJobResponse{}, nil)
+
+	invocations := 0
+	handler := deduplicateJobs(NewMemoryDeduplicationStore(0), utils.DefaultTestTimeout, logging.Default, func(client JobClient, job entities.Job) {
+		invocations++
+		command, _ := client.NewCompleteJobCommand().JobKey(job.Key).VariablesFromString(`{"paid":true}`)
+		_, _ = command.Send(context.Background())
+	})
+
+	handler(gatewayJobClient{client}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 1, Retries: 3}})
+	handler(gatewayJobClient{client}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 1, Retries: 3}})
+
+	assert.Equal(t, 1, invocations)
+}
+
+func TestDeduplicationHandlesJobAgainIfCompletionFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().CompleteJob(gomock.Any(), gomock.Any()).Return(nil, errors.New("connection lost"))
+	client.EXPECT().CompleteJob(gomock.Any(), gomock.Any()).Return(&pb.CompleteJobResponse{}, nil)
+
+	store := NewMemoryDeduplicationStore(0)
+	invocations := 0
+	handler := deduplicateJobs(store, utils.DefaultTestTimeout, logging.Default, func(client JobClient, job entities.Job) {
+		invocations++
+		_, _ = client.NewCompleteJobCommand().JobKey(job.Key).Send(context.Background())
+	})
+
+	handler(gatewayJobClient{client}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 1, Retries: 3}})
+	processed, _ := store.Begin(context.Background(), 1, 3)
+	assert.False(t, processed, "expected job whose completion failed not to be processed")
+
+	handler(gatewayJobClient{client}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 1, Retries: 3}})
+	processed, _ = store.Begin(context.Background(), 1, 3)
+	assert.True(t, processed)
+	assert.Equal(t, 2, invocations)
+}
+
+func TestDeduplicationDoesNotRecordUnsentCompletion(t *testing.T) {
+	store := NewMemoryDeduplicationStore(0)
+	handler := deduplicateJobs(store, utils.DefaultTestTimeout, logging.Default, func(client JobClient, job entities.Job) {
+		_, _ = client.NewCompleteJobCommand().JobKey(job.Key).VariablesFromString(`{"paid":true}`)
+	})
+
+	handler(gatewayJobClient{nil}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 1, Retries: 3}})
+
+	processed, _ := store.Begin(context.Background(), 1, 3)
+	assert.False(t, processed, "expected job which was not completed not to be processed")
+}
+
+func TestDeduplicationRetriesFailedJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().FailJob(gomock.Any(), gomock.Any()).Return(&pb.FailJobResponse{}, nil)
+	client.EXPECT().CompleteJob(gomock.Any(), gomock.Any()).Return(&pb.CompleteJobResponse{}, nil).Times(2)
+
+	store := NewMemoryDeduplicationStore(0)
+	invocations := 0
+	handler := deduplicateJobs(store, utils.DefaultTestTimeout, logging.Default, func(client JobClient, job entities.Job) {
+		invocations++
+		_, _ = client.NewCompleteJobCommand().JobKey(job.Key).Send(context.Background())
+		if job.Retries == 3 {
+			_, _ = client.NewFailJobCommand().JobKey(job.Key).Retries(2).Send(context.Background())
+		}
+	})
+
+	handler(gatewayJobClient{client}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 1, Retries: 3}})
+	handler(gatewayJobClient{client}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 1, Retries: 2}})
+
+	assert.Equal(t, 2, invocations)
+	processed, _ := store.Begin(context.Background(), 1, 3)
+	assert.False(t, processed, "expected failed job not to be processed")
+}
+
+func TestJobWorkerWithDeduplication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := activateOnce(ctrl, &pb.ActivatedJob{Key: 1, Retries: 3})
+	logs := make(logWriter, 10)
+
+	store := NewMemoryDeduplicationStore(0)
+	_ = store.Succeed(context.Background(), 1, 3)
+	worker := NewJobWorkerBuilder(client, gatewayJobClient{client}).JobType("foo").Handler(func(JobClient, entities.Job) {
+		t.Error("expected processed job not to be handled")
+	}).Deduplication(store).Logger(logging.NewStdLogger(log.New(logs, "", 0), logging.LevelDebug)).Open()
+	defer worker.Close()
+
+	timeout := time.After(utils.DefaultTestTimeout)
+	for {
+		select {
+		case line := <-logs:
+			if strings.HasPrefix(line, "DEBUG Skipping job which was already processed") {
+				return
+			}
+		case <-timeout:
+			t.Fatal("expected processed job to be skipped")
+		}
+	}
+}
+
+// logWriter passes every line logged to it on the channel, dropping lines if the channel is full
+type logWriter chan string
+
+func (w logWriter) Write(p []byte) (int, error) {
+	select {
+	case w <- string(p):
+	default:
+	}
+	return len(p), nil
+}
+
+func TestDeduplicationKeepsOptionalCommandsOfJobClient(t *testing.T) {
+	tracking := &trackingJobClient{}
+
+	_, throws := tracking.withOptionalCommands(gatewayJobClient{}).(errorThrower)
+	assert.True(t, throws)
+	_, sets := tracking.withOptionalCommands(variablesJobClient{gatewayJobClient{}}).(variablesSetter)
+	assert.True(t, sets)
+
+	client := tracking.withOptionalCommands(struct{ JobClient }{gatewayJobClient{}})
+	_, throws = client.(errorThrower)
+	_, sets = client.(variablesSetter)
+	assert.False(t, throws)
+	assert.False(t, sets)
+}
@@ -16,18 +16,29 @@ package worker
 
 import (
 	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"hash/fnv"
 	"sync"
+	"time"
 )
 
 type jobDispatcher struct {
 	jobQueue       chan entities.Job
 	workerFinished chan bool
 	closeSignal    chan struct{}
+	metrics        JobWorkerMetrics
+	activeJobs     *activeJobs
+	orderingKey    func(entities.Job) string
+	sizing         *activationSizing
 }
 
 func (dispatcher *jobDispatcher) run(client JobClient, handler JobHandler, concurrency int, closeWait *sync.WaitGroup) {
 	defer closeWait.Done()
 
+	if dispatcher.orderingKey != nil {
+		dispatcher.runSharded(client, handler, concurrency)
+		return
+	}
+
 	// prepare for shutdown
 	closeWorkers := make(chan struct{})
 	var workersClosed sync.WaitGroup
@@ -50,7 +61,8 @@ func (dispatcher *jobDispatcher) run(client JobClient, handler JobHandler, concu
 				workerQueue <- work
 				select {
 				case job := <-work:
-					handler(client, job)
+					dispatcher.handleJob(client, handler, &job)
+					dispatcher.activeJobs.done(job.Key)
 					dispatcher.workerFinished <- true
 				case <-closeWorkers:
 					break workerLoop
@@ -76,3 +88,74 @@ loop:
 		}
 	}
 }
+
+// runSharded dispatches the jobs to one worker per shard by their ordering key, so jobs with the same key are handled
+// one after another and in the order they were activated. Jobs with an empty key are distributed round robin.
+func (dispatcher *jobDispatcher) runSharded(client JobClient, handler JobHandler, concurrency int) {
+	closeWorkers := make(chan struct{})
+	var workersClosed sync.WaitGroup
+	workersClosed.Add(concurrency)
+
+	defer func() {
+		close(closeWorkers)
+		workersClosed.Wait()
+	}()
+
+	// every shard can buffer all active jobs, so a busy shard doesn't block the others
+	shards := make([]chan entities.Job, concurrency)
+	for i := range shards {
+		shards[i] = make(chan entities.Job, cap(dispatcher.jobQueue))
+		go func(work chan entities.Job) {
+			defer workersClosed.Done()
+
+			for {
+				select {
+				case job := <-work:
+					dispatcher.handleJob(client, handler, &job)
+					dispatcher.activeJobs.done(job.Key)
+					dispatcher.workerFinished <- true
+				case <-closeWorkers:
+					return
+				}
+			}
+		}(shards[i])
+	}
+
+	next := 0
+	for {
+		select {
+		case job := <-dispatcher.jobQueue:
+			shard := next
+			if key := dispatcher.orderingKey(job); key != "" {
+				hash := fnv.New32a()
+				_, _ = hash.Write([]byte(key))
+				shard = int(hash.Sum32() % uint32(concurrency))
+			} else {
+				next = (next + 1) % concurrency
+			}
+
+			select {
+			case shards[shard] <- job:
+			case <-dispatcher.closeSignal:
+				return
+			}
+		case <-dispatcher.closeSignal:
+			return
+		}
+	}
+}
+
+func (dispatcher *jobDispatcher) handleJob(client JobClient, handler JobHandler, job *entities.Job) {
+	if !dispatcher.activeJobs.start(job.Key) {
+		return
+	}
+
+	start := time.Now()
+	handler(client, *job)
+
+	duration := time.Since(start)
+	dispatcher.sizing.observe(duration)
+	if metrics, ok := dispatcher.metrics.(JobHandlerMetrics); ok {
+		metrics.ObserveJobHandlerDuration(job.Type, duration)
+	}
+}
@@ -166,6 +166,31 @@ func (suite *JobDispatcherSuite) TestShouldHandleMultipleJobsInParallel() {
 	close(suite.dispatcher.closeSignal)
 }
 
+func (suite *JobDispatcherSuite) TestShouldReportHandlerDuration() {
+	// given
+	metrics := &handlerMetricsStub{}
+	suite.dispatcher.metrics = metrics
+	handler := func(JobClient, entities.Job) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	go suite.dispatcher.run(&suite.client, handler, 1, &suite.waitGroup)
+
+	// when
+	suite.dispatcher.jobQueue <- entities.Job{ActivatedJob: pb.ActivatedJob{Type: "foo"}}
+
+	// then
+	select {
+	case <-suite.dispatcher.workerFinished:
+		suite.Equal("foo", metrics.jobType)
+		suite.GreaterOrEqual(int64(metrics.duration), int64(10*time.Millisecond))
+	case <-time.After(utils.DefaultTestTimeout):
+		suite.FailNow("Failed to wait for job handler invocation")
+	}
+
+	close(suite.dispatcher.closeSignal)
+}
+
 func (suite *JobDispatcherSuite) newSyncedJobHandler() func(JobClient, entities.Job) {
 	return func(JobClient, entities.Job) {
 		suite.awaitHandler <- true
@@ -198,3 +223,90 @@ func (stub *jobClientStub) NewCompleteJobCommand() commands.CompleteJobCommandSt
 func (jobClientStub) NewFailJobCommand() commands.FailJobCommandStep1 {
 	panic("implement me")
 }
+
+type handlerMetricsStub struct {
+	jobType  string
+	duration time.Duration
+}
+
+func (*handlerMetricsStub) SetJobsRemainingCount(string, int) {}
+
+func (m *handlerMetricsStub) ObserveJobHandlerDuration(jobType string, duration time.Duration) {
+	m.jobType = jobType
+	m.duration = duration
+}
+
+func TestShouldHandleJobsWithSameOrderingKeyInOrder(t *testing.T) {
+	// given
+	dispatcher := jobDispatcher{
+		jobQueue:       make(chan entities.Job, 4),
+		workerFinished: make(chan bool, 4),
+		closeSignal:    make(chan struct{}),
+		activeJobs:     newActiveJobs(),
+		orderingKey:    func(job entities.Job) string { return job.Type },
+	}
+
+	var mu sync.Mutex
+	var handled []int64
+	running := make(map[string]bool)
+	releaseFirst := make(chan struct{})
+	otherKeyHandled := make(chan struct{})
+	handler := func(_ JobClient, job entities.Job) {
+		mu.Lock()
+		if running[job.Type] {
+			t.Errorf("expected jobs of key %s to be handled one after another", job.Type)
+		}
+		running[job.Type] = true
+		mu.Unlock()
+
+		if job.Key == 1 {
+			<-releaseFirst
+		}
+		if job.Type == "b" {
+			close(otherKeyHandled)
+		}
+
+		mu.Lock()
+		running[job.Type] = false
+		handled = append(handled, job.Key)
+		mu.Unlock()
+	}
+
+	var closeWait sync.WaitGroup
+	closeWait.Add(1)
+	go dispatcher.run(&jobClientStub{}, handler, 4, &closeWait)
+
+	// when
+	for i, jobType := range []string{"a", "a", "a", "b"} {
+		job := entities.Job{ActivatedJob: pb.ActivatedJob{Key: int64(i + 1), Type: jobType}}
+		dispatcher.activeJobs.add(&job)
+		dispatcher.jobQueue <- job
+	}
+
+	// then
+	select {
+	case <-otherKeyHandled:
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected job of another key to be handled while the first key is blocked")
+	}
+	close(releaseFirst)
+	for i := 0; i < 4; i++ {
+		select {
+		case <-dispatcher.workerFinished:
+		case <-time.After(utils.DefaultTestTimeout):
+			t.Fatal("expected all jobs to be handled")
+		}
+	}
+	close(dispatcher.closeSignal)
+	closeWait.Wait()
+
+	var ordered []int64
+	for _, key := range handled {
+		if key != 4 {
+			ordered = append(ordered, key)
+		}
+	}
+	if len(ordered) != 3 || ordered[0] != 1 || ordered[1] != 2 || ordered[2] != 3 {
+		t.Errorf("expected jobs of the same key to be handled in order, got %v", handled)
+	}
+}
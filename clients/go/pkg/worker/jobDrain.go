@@ -0,0 +1,124 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+)
+
+const releasedOnDrainMessage = "job worker was drained before the job was handled"
+
+// activeJobs tracks the jobs which were activated by a worker but whose handler did not finish yet, with their retries,
+// and which of them were passed to their handler already.
+type activeJobs struct {
+	mu       sync.Mutex
+	retries  map[int64]int32
+	handling map[int64]struct{}
+	empty    chan struct{}
+}
+
+func newActiveJobs() *activeJobs {
+	return &activeJobs{retries: map[int64]int32{}, handling: map[int64]struct{}{}, empty: make(chan struct{}, 1)}
+}
+
+func (a *activeJobs) add(job *entities.Job) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.retries[job.Key] = job.Retries
+}
+
+// start records that the job is passed to its handler and returns whether it should be handled, which is not the case
+// if the job was released or is not active.
+func (a *activeJobs) start(key int64) bool {
+	if a == nil {
+		return true
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, active := a.retries[key]; !active {
+		return false
+	}
+	a.handling[key] = struct{}{}
+	return true
+}
+
+func (a *activeJobs) done(key int64) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.retries, key)
+	delete(a.handling, key)
+	if len(a.retries) == 0 {
+		select {
+		case a.empty <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (a *activeJobs) wait(ctx context.Context) error {
+	for {
+		a.mu.Lock()
+		count := len(a.retries)
+		a.mu.Unlock()
+		if count == 0 {
+			return nil
+		}
+
+		select {
+		case <-a.empty:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release fails the active jobs which were not passed to their handler yet with unchanged retries, so they can be
+// activated again by other workers. Jobs whose handler is still running are left to finish or time out, as failing them
+// would let another worker handle them at the same time. Released jobs are no longer active, so they are not passed to
+// their handler if the dispatcher picks them up afterwards.
+func (a *activeJobs) release(client JobClient, requestTimeout time.Duration, logger logging.Logger) {
+	a.mu.Lock()
+	retries := make(map[int64]int32, len(a.retries))
+	for key, jobRetries := range a.retries {
+		if _, handling := a.handling[key]; !handling {
+			retries[key] = jobRetries
+			delete(a.retries, key)
+		}
+	}
+	a.mu.Unlock()
+
+	for key, jobRetries := range retries {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		_, err := client.NewFailJobCommand().JobKey(key).Retries(jobRetries).ErrorMessage(releasedOnDrainMessage).Send(ctx)
+		cancel()
+		if err != nil {
+			logger.Warn("Failed to release job on drain", "jobKey", key, "error", err)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func activateOnce(ctrl *gomock.Controller, jobs ...*pb.ActivatedJob) *mock_pb.MockGatewayClient {
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	stream := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+	stream.EXPECT().Recv().Return(&pb.ActivateJobsResponse{Jobs: jobs}, nil).Times(1)
+	stream.EXPECT().Recv().Return(nil, io.EOF).AnyTimes()
+	client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(stream, nil).AnyTimes()
+
+	return client
+}
+
+func TestJobWorkerDrainWaitsForHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := activateOnce(ctrl, &pb.ActivatedJob{Key: 1}, &pb.ActivatedJob{Key: 2})
+	started := make(chan struct{}, 2)
+	handled := make(chan int64, 2)
+
+	worker := NewJobWorkerBuilder(client, nil).JobType("foo").Handler(func(_ JobClient, job entities.Job) {
+		started <- struct{}{}
+		time.Sleep(20 * time.Millisecond)
+		handled <- job.Key
+	}).Open()
+
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	err := worker.Drain(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, handled, 2)
+}
+
+func TestJobWorkerDrainReleasesQueuedJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := activateOnce(ctrl, &pb.ActivatedJob{Key: 1, Retries: 3}, &pb.ActivatedJob{Key: 2, Retries: 3})
+	request := &pb.FailJobRequest{JobKey: 2, Retries: 3, ErrorMessage: releasedOnDrainMessage}
+	client.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: request}).Return(&pb.FailJobResponse{}, nil)
+
+	started := make(chan int64, 2)
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	worker := NewJobWorkerBuilder(client, gatewayJobClient{client}).JobType("foo").Handler(func(_ JobClient, job entities.Job) {
+		started <- job.Key
+		<-unblock
+	}).Concurrency(1).ReleaseJobsOnDrain(true).Open()
+
+	assert.Equal(t, int64(1), <-started)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := worker.Drain(ctx)
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestJobWorkerDoesNotHandleReleasedJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var jobs []*pb.ActivatedJob
+	for key := int64(1); key <= 50; key++ {
+		jobs = append(jobs, &pb.ActivatedJob{Key: key, Type: "foo", Retries: 3})
+	}
+	client := activateOnce(ctrl, jobs...)
+
+	var mu sync.Mutex
+	released := map[int64]bool{}
+	handled := map[int64]bool{}
+	client.EXPECT().FailJob(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, request *pb.FailJobRequest, _ ...interface{}) (*pb.FailJobResponse, error) {
+			mu.Lock()
+			released[request.JobKey] = true
+			mu.Unlock()
+			return &pb.FailJobResponse{}, nil
+		}).AnyTimes()
+
+	started := make(chan struct{}, len(jobs))
+	worker := NewJobWorkerBuilder(client, gatewayJobClient{client}).JobType("foo").Handler(func(_ JobClient, job entities.Job) {
+		mu.Lock()
+		handled[job.Key] = true
+		mu.Unlock()
+		started <- struct{}{}
+		time.Sleep(time.Millisecond)
+	}).MaxJobsActive(len(jobs)).OrderingKey(func(job entities.Job) string {
+		return job.Type
+	}).ReleaseJobsOnDrain(true).Open()
+
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, worker.Drain(ctx))
+	worker.AwaitClose()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.NotEmpty(t, released)
+	for key := range released {
+		assert.False(t, handled[key], "expected released job %d not to be handled", key)
+	}
+}
+
+func TestJobWorkerCloseAfterDrain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := activateOnce(ctrl)
+	worker := NewJobWorkerBuilder(client, nil).JobType("foo").Handler(func(JobClient, entities.Job) {}).Open()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	assert.NoError(t, worker.Drain(ctx))
+	worker.Close()
+}
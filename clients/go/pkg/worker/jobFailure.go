@@ -0,0 +1,370 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+)
+
+// FallibleJobHandler processes a job like a JobHandler, but returns an error if it failed to process the job instead
+// of failing the job itself. The error is passed to the FailureHandler of the worker, which decides how the job is
+// failed. On success the handler is still responsible to complete the job.
+type FallibleJobHandler func(client JobClient, job entities.Job) error
+
+// FailureHandler decides how a job is failed after its handler returned the given error.
+type FailureHandler func(job entities.Job, err error) FailureDecision
+
+// DeadLetterHandler is called instead of failing a job for which no retries are left, e.g. to publish its payload to
+// a dead letter queue. It is responsible to complete, fail or otherwise resolve the job.
+type DeadLetterHandler func(client JobClient, job entities.Job, err error)
+
+// DefaultPanicRetryDecrement is the number of retries which are decremented when a job handler panics.
+const DefaultPanicRetryDecrement = 1
+
+// BPMNError can be returned by a FallibleJobHandler, also wrapped, to throw a BPMN error with the given code for the
+// job. It is thrown before the FailureHandler is asked, so it can be caught by an error event.
+type BPMNError struct {
+	Code    string
+	Message string
+	// Variables are set before the error is thrown, as the gateway does not support variables on thrown errors. They
+	// are set on the element instance of the job and propagated to the scopes which define them. Setting them
+	// requires a job client which can create SetVariables commands, like zbc.Client.
+	Variables interface{}
+}
+
+// NewBPMNError creates a BPMNError with the given code, message and optional variables, which may be nil.
+func NewBPMNError(code, message string, variables interface{}) *BPMNError {
+	return &BPMNError{Code: code, Message: message, Variables: variables}
+}
+
+func (e *BPMNError) Error() string {
+	return fmt.Sprintf("BPMN error '%s': %s", e.Code, e.Message)
+}
+
+// RetryableError can be returned by a FallibleJobHandler, also wrapped, to fail the job with decremented retries after
+// the given backoff, like RetryJob. It is handled before the FailureHandler is asked.
+type RetryableError struct {
+	Err     error
+	Backoff time.Duration
+}
+
+// NewRetryableError creates a RetryableError for the given cause and backoff.
+func NewRetryableError(err error, backoff time.Duration) *RetryableError {
+	return &RetryableError{Err: err, Backoff: backoff}
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+type failureAction int
+
+const (
+	failureActionRetry failureAction = iota
+	failureActionFail
+	failureActionThrowError
+)
+
+// FailureDecision describes how a failed job is handled. It is created by RetryJob, FailJobWithoutRetries or
+// ThrowBPMNError.
+type FailureDecision struct {
+	action       failureAction
+	backoff      time.Duration
+	errorCode    string
+	errorMessage string
+	variables    interface{}
+}
+
+// RetryJob fails the job with decremented retries after the given backoff. The backoff is waited for by the worker,
+// as the gateway does not support a retry backoff; unless the job times out first, it is not activated again before it
+// is failed.
+func RetryJob(backoff time.Duration) FailureDecision {
+	return FailureDecision{action: failureActionRetry, backoff: backoff}
+}
+
+// FailJobWithoutRetries fails the job with zero retries, which raises an incident unless a DeadLetterHandler is set.
+func FailJobWithoutRetries() FailureDecision {
+	return FailureDecision{action: failureActionFail}
+}
+
+// ThrowBPMNError throws a BPMN error with the given code for the job, which can be caught by an error event. If the
+// message is empty, the message of the handler error is used.
+func ThrowBPMNError(errorCode, errorMessage string) FailureDecision {
+	return FailureDecision{action: failureActionThrowError, errorCode: errorCode, errorMessage: errorMessage}
+}
+
+// ThrowJobError throws a BPMN error with the given code and message for the activated job, which can be caught by an
+// error event. If variables are given, they are set on the element instance of the job before the error is thrown, so
+// the error event can map them, e.g. diagnostic data of the handler. The job client has to be able to create
+// ThrowError commands, like zbc.Client.
+func ThrowJobError(ctx context.Context, client JobClient, job entities.Job, errorCode, errorMessage string, variables interface{}) error {
+	thrower, ok := client.(errorThrower)
+	if !ok {
+		return errors.New("job client cannot throw errors")
+	}
+
+	command := thrower.NewThrowErrorCommand().JobKey(job.Key).ErrorCode(errorCode).ErrorMessage(errorMessage)
+	if variables != nil {
+		var err error
+		if command, err = command.Variables(job.ElementInstanceKey, variables); err != nil {
+			return err
+		}
+	}
+
+	_, err := command.Send(ctx)
+	return err
+}
+
+// DefaultFailureHandler retries every failed job without a backoff.
+func DefaultFailureHandler(entities.Job, error) FailureDecision {
+	return RetryJob(0)
+}
+
+type jobFailureHandling struct {
+	decide         FailureHandler
+	deadLetter     DeadLetterHandler
+	requestTimeout time.Duration
+	logger         logging.Logger
+
+	panicRetryDecrement int32
+
+	mutex   sync.Mutex
+	pending map[int64]*pendingFailure
+	flushed bool
+}
+
+// pendingFailure is a job which is failed after its backoff.
+type pendingFailure struct {
+	timer *time.Timer
+	fail  func()
+}
+
+func (f *jobFailureHandling) wrap(handler FallibleJobHandler) JobHandler {
+	return func(client JobClient, job entities.Job) {
+		if err := handler(client, job); err != nil {
+			f.handleFailure(client, &job, err)
+		}
+	}
+}
+
+// recoverPanics fails the job if the handler panics, with the panic and its stack trace as error message, instead of
+// crashing the worker.
+func (f *jobFailureHandling) recoverPanics(handler JobHandler) JobHandler {
+	return func(client JobClient, job entities.Job) {
+		defer func() {
+			if r := recover(); r != nil {
+				f.handlePanic(client, &job, r, debug.Stack())
+			}
+		}()
+
+		handler(client, job)
+	}
+}
+
+func (f *jobFailureHandling) handlePanic(client JobClient, job *entities.Job, r interface{}, stack []byte) {
+	f.logger.Error("Recovered from panic in job handler", "jobKey", job.Key, "jobType", job.Type, "panic", r)
+
+	retries := job.Retries - f.panicRetryDecrement
+	if retries < 0 {
+		retries = 0
+	}
+
+	err := fmt.Errorf("job handler panicked: %v\n%s", r, stack)
+	if retries == 0 && f.deadLetter != nil {
+		f.deadLetter(client, *job, err)
+		return
+	}
+
+	f.failJob(client, job.Key, retries, err)
+}
+
+func (f *jobFailureHandling) handleFailure(client JobClient, job *entities.Job, err error) {
+	decision, ok := decisionOf(err)
+	if !ok {
+		decision = f.decide(*job, err)
+	}
+	if decision.action == failureActionThrowError {
+		f.throwError(client, job, decision, err)
+		return
+	}
+
+	retries := job.Retries - 1
+	if decision.action == failureActionFail || retries < 0 {
+		retries = 0
+	}
+
+	if retries == 0 && f.deadLetter != nil {
+		f.deadLetter(client, *job, err)
+		return
+	}
+
+	f.retryAfter(client, job, decision.backoff, retries, err)
+}
+
+// retryAfter fails the job after the backoff. The backoff is capped at the deadline of the job, less the request
+// timeout, so the job is failed before it times out and is activated again. Pending failures are sent by flush.
+func (f *jobFailureHandling) retryAfter(client JobClient, job *entities.Job, backoff time.Duration, retries int32, err error) {
+	if job.Deadline > 0 {
+		deadline := time.Unix(0, job.Deadline*int64(time.Millisecond))
+		if untilDeadline := time.Until(deadline) - f.requestTimeout; backoff > untilDeadline {
+			backoff = untilDeadline
+		}
+	}
+
+	jobKey := job.Key
+	fail := func() {
+		f.failJob(client, jobKey, retries, err)
+	}
+
+	f.mutex.Lock()
+	if backoff <= 0 || f.flushed {
+		f.mutex.Unlock()
+		fail()
+		return
+	}
+
+	if f.pending == nil {
+		f.pending = map[int64]*pendingFailure{}
+	}
+	f.pending[jobKey] = &pendingFailure{fail: fail, timer: time.AfterFunc(backoff, func() {
+		f.mutex.Lock()
+		pending, ok := f.pending[jobKey]
+		delete(f.pending, jobKey)
+		f.mutex.Unlock()
+
+		if ok {
+			pending.fail()
+		}
+	})}
+	f.mutex.Unlock()
+}
+
+// flush fails the jobs which wait for their backoff immediately, e.g. when the worker is closed, so they are not failed
+// after the worker was closed or lost if the process exits. Jobs which are retried afterwards are failed immediately.
+func (f *jobFailureHandling) flush() {
+	f.mutex.Lock()
+	pending := f.pending
+	f.pending = nil
+	f.flushed = true
+	f.mutex.Unlock()
+
+	for _, failure := range pending {
+		failure.timer.Stop()
+		failure.fail()
+	}
+}
+
+// decisionOf returns the decision for a BPMNError or RetryableError in the chain of the error.
+func decisionOf(err error) (FailureDecision, bool) {
+	var bpmnError *BPMNError
+	if errors.As(err, &bpmnError) {
+		decision := ThrowBPMNError(bpmnError.Code, bpmnError.Message)
+		decision.variables = bpmnError.Variables
+		return decision, true
+	}
+
+	var retryableError *RetryableError
+	if errors.As(err, &retryableError) {
+		return RetryJob(retryableError.Backoff), true
+	}
+
+	return FailureDecision{}, false
+}
+
+func (f *jobFailureHandling) failJob(client JobClient, jobKey int64, retries int32, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), f.requestTimeout)
+	defer cancel()
+
+	_, sendErr := client.NewFailJobCommand().JobKey(jobKey).Retries(retries).ErrorMessage(err.Error()).Send(ctx)
+	if sendErr != nil {
+		f.logger.Warn("Failed to fail job after handler error", "jobKey", jobKey, "error", sendErr)
+	}
+}
+
+func (f *jobFailureHandling) throwError(client JobClient, job *entities.Job, decision FailureDecision, err error) {
+	thrower, ok := client.(errorThrower)
+	if !ok {
+		f.logger.Warn("Failing job instead of throwing BPMN error, as the job client cannot throw errors", "jobKey", job.Key, "errorCode", decision.errorCode)
+		f.failJob(client, job.Key, decrementedRetries(job), err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.requestTimeout)
+	defer cancel()
+
+	message := decision.errorMessage
+	if message == "" {
+		message = err.Error()
+	}
+
+	if decision.variables != nil && !f.setVariables(ctx, client, job, decision.variables) {
+		return
+	}
+
+	_, sendErr := thrower.NewThrowErrorCommand().JobKey(job.Key).ErrorCode(decision.errorCode).ErrorMessage(message).Send(ctx)
+	if sendErr != nil {
+		f.logger.Warn("Failed to throw error after handler error", "jobKey", job.Key, "errorCode", decision.errorCode, "error", sendErr)
+	}
+}
+
+type errorThrower interface {
+	NewThrowErrorCommand() commands.ThrowErrorCommandStep1
+}
+
+type variablesSetter interface {
+	NewSetVariablesCommand() commands.SetVariablesCommandStep1
+}
+
+// setVariables sets the variables of a thrown error on the element instance of the job and returns whether the error
+// should still be thrown. If they cannot be set, the job is failed instead, so the error is not caught without them.
+func (f *jobFailureHandling) setVariables(ctx context.Context, client JobClient, job *entities.Job, variables interface{}) bool {
+	setter, ok := client.(variablesSetter)
+	if !ok {
+		f.logger.Warn("Ignoring variables of BPMN error, as the job client cannot set variables", "jobKey", job.Key)
+		return true
+	}
+
+	command, err := setter.NewSetVariablesCommand().ElementInstanceKey(job.ElementInstanceKey).VariablesFromObject(variables)
+	if err == nil {
+		_, err = command.Send(ctx)
+	}
+	if err != nil {
+		f.logger.Warn("Failed to set variables of BPMN error, failing job instead", "jobKey", job.Key, "error", err)
+		f.failJob(client, job.Key, decrementedRetries(job), fmt.Errorf("failed to set variables of BPMN error: %w", err))
+		return false
+	}
+
+	return true
+}
+
+func decrementedRetries(job *entities.Job) int32 {
+	if job.Retries > 0 {
+		return job.Retries - 1
+	}
+	return 0
+}
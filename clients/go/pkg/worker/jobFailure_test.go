@@ -0,0 +1,400 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+var errHandler = errors.New("handler failed")
+
+type gatewayJobClient struct {
+	gateway pb.GatewayClient
+}
+
+func (c gatewayJobClient) NewCompleteJobCommand() commands.CompleteJobCommandStep1 {
+	return commands.NewCompleteJobCommand(c.gateway, noRetry)
+}
+
+func (c gatewayJobClient) NewFailJobCommand() commands.FailJobCommandStep1 {
+	return commands.NewFailJobCommand(c.gateway, noRetry)
+}
+
+func (c gatewayJobClient) NewThrowErrorCommand() commands.ThrowErrorCommandStep1 {
+	return commands.NewThrowErrorCommand(c.gateway, noRetry)
+}
+
+func noRetry(context.Context, error) bool {
+	return false
+}
+
+func failingHandler(JobClient, entities.Job) error {
+	return errHandler
+}
+
+func newFailureHandling() *jobFailureHandling {
+	return &jobFailureHandling{decide: DefaultFailureHandler, requestTimeout: utils.DefaultTestTimeout, logger: logging.Default, panicRetryDecrement: DefaultPanicRetryDecrement}
+}
+
+func TestFailureHandlingRetriesJobByDefault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	request := &pb.FailJobRequest{JobKey: 123, Retries: 2, ErrorMessage: errHandler.Error()}
+	gateway.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: request}).Return(&pb.FailJobResponse{}, nil)
+
+	handler := newFailureHandling().wrap(failingHandler)
+	handler(gatewayJobClient{gateway}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, Retries: 3}})
+}
+
+func TestFailureHandlingRetriesJobAfterBackoff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failed := make(chan time.Time, 1)
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	request := &pb.FailJobRequest{JobKey: 123, Retries: 2, ErrorMessage: errHandler.Error()}
+	gateway.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: request}).DoAndReturn(
+		func(context.Context, *pb.FailJobRequest, ...interface{}) (*pb.FailJobResponse, error) {
+			failed <- time.Now()
+			return &pb.FailJobResponse{}, nil
+		})
+
+	failures := newFailureHandling()
+	failures.decide = func(entities.Job, error) FailureDecision {
+		return RetryJob(20 * time.Millisecond)
+	}
+
+	start := time.Now()
+	failures.wrap(failingHandler)(gatewayJobClient{gateway}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, Retries: 3}})
+
+	select {
+	case failedAt := <-failed:
+		assert.GreaterOrEqual(t, int64(failedAt.Sub(start)), int64(20*time.Millisecond))
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("Failed to wait for job to be failed")
+	}
+}
+
+func TestFailureHandlingFailsJobWithoutRetries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	request := &pb.FailJobRequest{JobKey: 123, Retries: 0, ErrorMessage: errHandler.Error()}
+	gateway.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: request}).Return(&pb.FailJobResponse{}, nil)
+
+	failures := newFailureHandling()
+	failures.decide = func(entities.Job, error) FailureDecision {
+		return FailJobWithoutRetries()
+	}
+
+	failures.wrap(failingHandler)(gatewayJobClient{gateway}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, Retries: 3}})
+}
+
+func TestFailureHandlingThrowsBPMNError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	request := &pb.ThrowErrorRequest{JobKey: 123, ErrorCode: "invalid-order", ErrorMessage: errHandler.Error()}
+	gateway.EXPECT().ThrowError(gomock.Any(), &rpcMsg{msg: request}).Return(&pb.ThrowErrorResponse{}, nil)
+
+	failures := newFailureHandling()
+	failures.decide = func(entities.Job, error) FailureDecision {
+		return ThrowBPMNError("invalid-order", "")
+	}
+
+	failures.wrap(failingHandler)(gatewayJobClient{gateway}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, Retries: 3}})
+}
+
+func TestFailureHandlingRoutesExhaustedJobToDeadLetterHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var deadLetterKey int64
+	var deadLetterErr error
+	failures := newFailureHandling()
+	failures.deadLetter = func(_ JobClient, job entities.Job, err error) {
+		deadLetterKey = job.Key
+		deadLetterErr = err
+	}
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	failures.wrap(failingHandler)(gatewayJobClient{gateway}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, Retries: 1}})
+
+	assert.EqualValues(t, 123, deadLetterKey)
+	assert.Equal(t, errHandler, deadLetterErr)
+}
+
+func TestFailureHandlingIgnoresSucceededJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := newFailureHandling().wrap(func(JobClient, entities.Job) error {
+		return nil
+	})
+
+	handler(gatewayJobClient{mock_pb.NewMockGatewayClient(ctrl)}, entities.Job{})
+}
+
+func TestJobWorkerBuilder_FallibleHandler(t *testing.T) {
+	builder := JobWorkerBuilder{}
+	builder.FallibleHandler(failingHandler).FailureHandler(func(entities.Job, error) FailureDecision {
+		return FailJobWithoutRetries()
+	})
+
+	assert.NotNil(t, builder.handler)
+	assert.Equal(t, failureActionFail, builder.failures.decide(entities.Job{}, errHandler).action)
+}
+
+func TestFailureHandlingThrowsWrappedBPMNError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	request := &pb.ThrowErrorRequest{JobKey: 123, ErrorCode: "NOT_FOUND", ErrorMessage: "no such order"}
+	gateway.EXPECT().ThrowError(gomock.Any(), &rpcMsg{msg: request}).Return(&pb.ThrowErrorResponse{}, nil)
+
+	failures := newFailureHandling()
+	failures.decide = func(entities.Job, error) FailureDecision {
+		t.Fatal("expected BPMN error to be thrown without asking the failure handler")
+		return FailJobWithoutRetries()
+	}
+	handler := failures.wrap(func(JobClient, entities.Job) error {
+		return fmt.Errorf("failed to load order: %w", &BPMNError{Code: "NOT_FOUND", Message: "no such order"})
+	})
+	handler(gatewayJobClient{gateway}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, Retries: 3}})
+}
+
+func TestFailureHandlingRecoversPanic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var request *pb.FailJobRequest
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	gateway.EXPECT().FailJob(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, r *pb.FailJobRequest, _ ...interface{}) (*pb.FailJobResponse, error) {
+			request = r
+			return &pb.FailJobResponse{}, nil
+		})
+
+	failures := newFailureHandling()
+	failures.panicRetryDecrement = 2
+	handler := failures.recoverPanics(func(JobClient, entities.Job) {
+		panic("unexpected")
+	})
+	handler(gatewayJobClient{gateway}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, Retries: 3}})
+
+	assert.Equal(t, int64(123), request.JobKey)
+	assert.Equal(t, int32(1), request.Retries)
+	assert.True(t, strings.HasPrefix(request.ErrorMessage, "job handler panicked: unexpected\n"))
+	assert.Contains(t, request.ErrorMessage, "TestFailureHandlingRecoversPanic")
+}
+
+func TestFailureHandlingPassesPanicToDeadLetterHandler(t *testing.T) {
+	var deadLetterErr error
+	failures := newFailureHandling()
+	failures.deadLetter = func(_ JobClient, _ entities.Job, err error) {
+		deadLetterErr = err
+	}
+	handler := failures.recoverPanics(func(JobClient, entities.Job) {
+		panic("unexpected")
+	})
+	handler(nil, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, Retries: 1}})
+
+	assert.Error(t, deadLetterErr)
+}
+
+type variablesJobClient struct {
+	gatewayJobClient
+}
+
+func (c variablesJobClient) NewSetVariablesCommand() commands.SetVariablesCommandStep1 {
+	return commands.NewSetVariablesCommand(c.gateway, noRetry)
+}
+
+func TestFailureHandlingSetsVariablesOfBPMNError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	setVariables := &pb.SetVariablesRequest{ElementInstanceKey: 456, Variables: `{"reason":"out of stock"}`}
+	throwError := &pb.ThrowErrorRequest{JobKey: 123, ErrorCode: "REJECTED", ErrorMessage: "order rejected"}
+	gomock.InOrder(
+		gateway.EXPECT().SetVariables(gomock.Any(), &rpcMsg{msg: setVariables}).Return(&pb.SetVariablesResponse{}, nil),
+		gateway.EXPECT().ThrowError(gomock.Any(), &rpcMsg{msg: throwError}).Return(&pb.ThrowErrorResponse{}, nil),
+	)
+
+	handler := newFailureHandling().wrap(func(JobClient, entities.Job) error {
+		return NewBPMNError("REJECTED", "order rejected", map[string]string{"reason": "out of stock"})
+	})
+	handler(variablesJobClient{gatewayJobClient{gateway}}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, ElementInstanceKey: 456, Retries: 3}})
+}
+
+func TestFailureHandlingRetriesRetryableErrorAfterBackoff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failed := make(chan struct{})
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	request := &pb.FailJobRequest{JobKey: 123, Retries: 2, ErrorMessage: errHandler.Error()}
+	gateway.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: request}).DoAndReturn(
+		func(context.Context, *pb.FailJobRequest, ...interface{}) (*pb.FailJobResponse, error) {
+			close(failed)
+			return &pb.FailJobResponse{}, nil
+		})
+
+	failures := newFailureHandling()
+	failures.decide = func(entities.Job, error) FailureDecision {
+		t.Fatal("expected retryable error to be retried without asking the failure handler")
+		return FailJobWithoutRetries()
+	}
+	handler := failures.wrap(func(JobClient, entities.Job) error {
+		return NewRetryableError(errHandler, 10*time.Millisecond)
+	})
+	start := time.Now()
+	handler(gatewayJobClient{gateway}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, Retries: 3}})
+
+	select {
+	case <-failed:
+		assert.True(t, time.Since(start) >= 10*time.Millisecond)
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected job to be failed")
+	}
+}
+
+func TestThrowJobErrorWithVariables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	setVariables := &pb.SetVariablesRequest{ElementInstanceKey: 456, Variables: `{"reason":"out of stock"}`}
+	throwError := &pb.ThrowErrorRequest{JobKey: 123, ErrorCode: "REJECTED", ErrorMessage: "order rejected"}
+	gomock.InOrder(
+		gateway.EXPECT().SetVariables(gomock.Any(), &rpcMsg{msg: setVariables}).Return(&pb.SetVariablesResponse{}, nil),
+		gateway.EXPECT().ThrowError(gomock.Any(), &rpcMsg{msg: throwError}).Return(&pb.ThrowErrorResponse{}, nil),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	job := entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, ElementInstanceKey: 456, Retries: 3}}
+	err := ThrowJobError(ctx, gatewayJobClient{gateway}, job, "REJECTED", "order rejected", map[string]string{"reason": "out of stock"})
+	assert.NoError(t, err)
+}
+
+func TestFailureHandlingCapsBackoffAtJobDeadline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failed := make(chan struct{})
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	request := &pb.FailJobRequest{JobKey: 123, Retries: 2, ErrorMessage: errHandler.Error()}
+	gateway.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: request}).DoAndReturn(
+		func(context.Context, *pb.FailJobRequest, ...interface{}) (*pb.FailJobResponse, error) {
+			close(failed)
+			return &pb.FailJobResponse{}, nil
+		})
+
+	failures := newFailureHandling()
+	failures.decide = func(entities.Job, error) FailureDecision {
+		return RetryJob(time.Hour)
+	}
+	deadline := time.Now().Add(failures.requestTimeout+20*time.Millisecond).UnixNano() / int64(time.Millisecond)
+	failures.wrap(failingHandler)(gatewayJobClient{gateway}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, Retries: 3, Deadline: deadline}})
+
+	select {
+	case <-failed:
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected job to be failed before its deadline")
+	}
+}
+
+func TestFailureHandlingFlushesJobsWaitingForBackoff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	gateway.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: &pb.FailJobRequest{JobKey: 1, Retries: 2, ErrorMessage: errHandler.Error()}}).Return(&pb.FailJobResponse{}, nil)
+	gateway.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: &pb.FailJobRequest{JobKey: 2, Retries: 2, ErrorMessage: errHandler.Error()}}).Return(&pb.FailJobResponse{}, nil)
+
+	failures := newFailureHandling()
+	failures.decide = func(entities.Job, error) FailureDecision {
+		return RetryJob(time.Hour)
+	}
+	handler := failures.wrap(failingHandler)
+
+	handler(gatewayJobClient{gateway}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 1, Retries: 3}})
+	failures.flush()
+	handler(gatewayJobClient{gateway}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 2, Retries: 3}})
+}
+
+func TestJobWorkerFailsJobsWaitingForBackoffOnClose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handled := make(chan struct{})
+	client := activateOnce(ctrl, &pb.ActivatedJob{Key: 1, Retries: 3})
+	client.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: &pb.FailJobRequest{JobKey: 1, Retries: 2, ErrorMessage: errHandler.Error()}}).Return(&pb.FailJobResponse{}, nil)
+
+	worker := NewJobWorkerBuilder(client, gatewayJobClient{client}).JobType("foo").FallibleHandler(func(JobClient, entities.Job) error {
+		close(handled)
+		return errHandler
+	}).FailureHandler(func(entities.Job, error) FailureDecision {
+		return RetryJob(time.Hour)
+	}).Open()
+
+	select {
+	case <-handled:
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected job to be handled")
+	}
+	worker.Close()
+}
+
+func TestFailureHandlingFailsJobIfClientCannotThrowErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	request := &pb.FailJobRequest{JobKey: 123, Retries: 2, ErrorMessage: "BPMN error 'REJECTED': order rejected"}
+	gateway.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: request}).Return(&pb.FailJobResponse{}, nil)
+
+	handler := newFailureHandling().wrap(func(JobClient, entities.Job) error {
+		return NewBPMNError("REJECTED", "order rejected", nil)
+	})
+	handler(struct{ JobClient }{gatewayJobClient{gateway}}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, Retries: 3}})
+}
+
+func TestThrowJobErrorRequiresErrorThrower(t *testing.T) {
+	err := ThrowJobError(context.Background(), struct{ JobClient }{gatewayJobClient{}}, entities.Job{}, "REJECTED", "order rejected", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+)
+
+const releasedByFilterMessage = "job was rejected by the filter of the job worker"
+
+// JobFilter decides whether a worker handles an activated job, e.g. by its custom headers.
+type JobFilter func(job entities.Job) bool
+
+// filterJobs wraps the handler, so it is only called for jobs accepted by the filter. Rejected jobs are failed with
+// unchanged retries if release is set, so they can be activated again by other workers, and are left to time out
+// otherwise.
+func filterJobs(filter JobFilter, release bool, requestTimeout time.Duration, logger logging.Logger, handler JobHandler) JobHandler {
+	return func(client JobClient, job entities.Job) {
+		if filter(job) {
+			handler(client, job)
+			return
+		}
+
+		if !release {
+			logger.Debug("Job rejected by filter, leaving it to time out", "jobKey", job.Key, "jobType", job.Type)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+		_, err := client.NewFailJobCommand().JobKey(job.Key).Retries(job.Retries).ErrorMessage(releasedByFilterMessage).Send(ctx)
+		if err != nil {
+			logger.Warn("Failed to release job rejected by filter", "jobKey", job.Key, "error", err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func regionFilter(job entities.Job) bool {
+	headers, _ := job.GetCustomHeadersAsMap()
+	return headers["region"] == "eu"
+}
+
+func TestJobWorkerReleasesFilteredJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := activateOnce(ctrl,
+		&pb.ActivatedJob{Key: 1, Retries: 3, CustomHeaders: `{"region":"us"}`},
+		&pb.ActivatedJob{Key: 2, Retries: 3, CustomHeaders: `{"region":"eu"}`},
+	)
+	released := make(chan struct{})
+	request := &pb.FailJobRequest{JobKey: 1, Retries: 3, ErrorMessage: releasedByFilterMessage}
+	client.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: request}).DoAndReturn(func(interface{}, interface{}, ...interface{}) (*pb.FailJobResponse, error) {
+		close(released)
+		return &pb.FailJobResponse{}, nil
+	})
+
+	handled := make(chan int64, 2)
+	worker := NewJobWorkerBuilder(client, gatewayJobClient{client}).JobType("foo").Handler(func(_ JobClient, job entities.Job) {
+		handled <- job.Key
+	}).JobFilter(regionFilter).Open()
+	defer worker.Close()
+
+	select {
+	case key := <-handled:
+		assert.EqualValues(t, 2, key)
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected accepted job to be handled")
+	}
+	select {
+	case <-released:
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected rejected job to be released")
+	}
+}
+
+func TestJobWorkerLeavesFilteredJobsToTimeOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := activateOnce(ctrl,
+		&pb.ActivatedJob{Key: 1, Retries: 3, CustomHeaders: `{"region":"us"}`},
+		&pb.ActivatedJob{Key: 2, Retries: 3, CustomHeaders: `{"region":"eu"}`},
+	)
+
+	handled := make(chan int64, 2)
+	worker := NewJobWorkerBuilder(client, gatewayJobClient{client}).JobType("foo").Handler(func(_ JobClient, job entities.Job) {
+		handled <- job.Key
+	}).JobFilter(regionFilter).ReleaseFilteredJobs(false).Concurrency(1).Open()
+
+	select {
+	case key := <-handled:
+		assert.EqualValues(t, 2, key)
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected accepted job to be handled")
+	}
+	worker.Close()
+}
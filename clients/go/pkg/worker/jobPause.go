@@ -0,0 +1,135 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"sync"
+)
+
+// pauseControl pauses and resumes the poller of a worker. While it is paused, the poller doesn't activate jobs and
+// the activation in progress is canceled; activated jobs are still handled. A nil control is never paused.
+type pauseControl struct {
+	jobType string
+	metrics JobWorkerMetrics
+
+	lock       sync.Mutex
+	paused     bool
+	resumed    chan struct{}
+	cancelPoll context.CancelFunc
+}
+
+func newPauseControl(jobType string, metrics JobWorkerMetrics) *pauseControl {
+	return &pauseControl{jobType: jobType, metrics: metrics}
+}
+
+func (p *pauseControl) pause() {
+	p.lock.Lock()
+	if p.paused {
+		p.lock.Unlock()
+		return
+	}
+	p.paused = true
+	p.resumed = make(chan struct{})
+	if p.cancelPoll != nil {
+		p.cancelPoll()
+	}
+	p.lock.Unlock()
+
+	p.observe(true)
+}
+
+func (p *pauseControl) resume() {
+	p.lock.Lock()
+	if !p.paused {
+		p.lock.Unlock()
+		return
+	}
+	p.paused = false
+	close(p.resumed)
+	p.lock.Unlock()
+
+	p.observe(false)
+}
+
+func (p *pauseControl) observe(paused bool) {
+	if metrics, ok := p.metrics.(JobPauseMetrics); ok {
+		metrics.SetJobWorkerPaused(p.jobType, paused)
+	}
+}
+
+func (p *pauseControl) isPaused() bool {
+	if p == nil {
+		return false
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.paused
+}
+
+// resumedSignal returns a channel which is closed when the poller is resumed, or nil if it is not paused.
+func (p *pauseControl) resumedSignal() <-chan struct{} {
+	if p == nil {
+		return nil
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.paused {
+		return nil
+	}
+	return p.resumed
+}
+
+// pollContext derives the context of an activation, which is canceled when the poller is paused. The second result is
+// false if the poller is paused already.
+func (p *pauseControl) pollContext(ctx context.Context) (context.Context, context.CancelFunc, bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	if p == nil {
+		return ctx, cancel, true
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.paused {
+		return ctx, cancel, false
+	}
+	p.cancelPoll = cancel
+	return ctx, cancel, true
+}
+
+// follow pauses the poller when true is received from the control channel and resumes it when false is received,
+// until the channel is closed or the worker is closed.
+func (p *pauseControl) follow(control <-chan bool, closed <-chan struct{}) {
+	for {
+		select {
+		case paused, ok := <-control:
+			if !ok {
+				return
+			}
+			if paused {
+				p.pause()
+			} else {
+				p.resume()
+			}
+		case <-closed:
+			return
+		}
+	}
+}
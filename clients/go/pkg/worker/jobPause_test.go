@@ -0,0 +1,109 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type pauseMetricsStub struct {
+	JobWorkerMetrics
+	lock   sync.Mutex
+	paused []bool
+}
+
+func (m *pauseMetricsStub) SetJobsRemainingCount(string, int) {}
+
+func (m *pauseMetricsStub) SetJobWorkerPaused(_ string, paused bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.paused = append(m.paused, paused)
+}
+
+func (m *pauseMetricsStub) observed() []bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]bool(nil), m.paused...)
+}
+
+func countActivations(ctrl *gomock.Controller, activations *int32) *mock_pb.MockGatewayClient {
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	stream := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+	stream.EXPECT().Recv().Return(nil, io.EOF).AnyTimes()
+	client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, *pb.ActivateJobsRequest, ...interface{}) (pb.Gateway_ActivateJobsClient, error) {
+		atomic.AddInt32(activations, 1)
+		return stream, nil
+	}).AnyTimes()
+
+	return client
+}
+
+func TestJobWorkerDoesNotActivateJobsWhilePaused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var activations int32
+	client := countActivations(ctrl, &activations)
+	metrics := &pauseMetricsStub{}
+
+	worker := NewJobWorkerBuilder(client, nil).JobType("foo").Handler(nil).PollInterval(5 * time.Millisecond).Metrics(metrics).Open()
+	defer worker.Close()
+
+	worker.Pause()
+	assert.True(t, worker.Paused())
+	// an activation which started before pausing may still call the gateway
+	time.Sleep(20 * time.Millisecond)
+	paused := atomic.LoadInt32(&activations)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, paused, atomic.LoadInt32(&activations))
+
+	worker.Resume()
+	assert.False(t, worker.Paused())
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&activations) > paused
+	}, utils.DefaultTestTimeout, time.Millisecond)
+	assert.Equal(t, []bool{true, false}, metrics.observed())
+}
+
+func TestJobWorkerFollowsPauseControl(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var activations int32
+	client := countActivations(ctrl, &activations)
+	control := make(chan bool)
+
+	worker := NewJobWorkerBuilder(client, nil).JobType("foo").Handler(nil).PollInterval(5 * time.Millisecond).PauseControl(control).Open()
+	defer worker.Close()
+
+	control <- true
+	assert.Eventually(t, worker.Paused, utils.DefaultTestTimeout, time.Millisecond)
+
+	control <- false
+	assert.Eventually(t, func() bool {
+		return !worker.Paused()
+	}, utils.DefaultTestTimeout, time.Millisecond)
+}
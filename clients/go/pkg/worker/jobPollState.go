@@ -0,0 +1,258 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+)
+
+const (
+	// DefaultMaxPollBackoff is the longest time a worker with a PollStateStore waits between failed activations.
+	DefaultMaxPollBackoff = 30 * time.Second
+	// DefaultPollStateMaxAge is the age after which a saved poll state is ignored when a worker is opened, as the
+	// gateway has likely recovered since.
+	DefaultPollStateMaxAge = 10 * time.Minute
+
+	// pollStateSaveInterval is the minimum time between saves of the state when only the last successful poll changed
+	pollStateSaveInterval = 10 * time.Second
+)
+
+// PollState is the state of the poller of a worker which survives restarts with a PollStateStore.
+type PollState struct {
+	// Backoff is the time the worker waits before it polls again after the last activations failed, or zero
+	Backoff time.Duration `json:"backoff"`
+	// LastSuccessfulPoll is the time of the last activation which didn't fail
+	LastSuccessfulPoll time.Time `json:"lastSuccessfulPoll"`
+	// ActivationLimit is the limit of AdaptiveConcurrency, or zero if it is not enabled
+	ActivationLimit int `json:"activationLimit,omitempty"`
+	// UpdatedAt is the time the state was saved
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// PollStateStore saves the poll states of workers by key, which consists of the job type and the name of the worker,
+// so restarted workers continue with the backoff and activation limit of their previous process instead of polling
+// at full speed, e.g. when all workers of a fleet are restarted while the gateway signals backpressure. The store
+// implementation must be thread-safe.
+type PollStateStore interface {
+	// Load returns the saved state of the key, or false if none was saved
+	Load(ctx context.Context, key string) (PollState, bool, error)
+	// Save saves the state of the key
+	Save(ctx context.Context, key string, state PollState) error
+}
+
+// FilePollStateStore is a PollStateStore which keeps the states of all workers of a process in a JSON file, e.g. on
+// a volume which survives restarts of the process. Processes must not share the file.
+type FilePollStateStore struct {
+	path string
+	lock sync.Mutex
+}
+
+// NewFilePollStateStore creates a store which keeps the states in the file at the path.
+func NewFilePollStateStore(path string) *FilePollStateStore {
+	return &FilePollStateStore{path: path}
+}
+
+func (s *FilePollStateStore) Load(_ context.Context, key string) (PollState, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	states, err := s.read()
+	if err != nil {
+		return PollState{}, false, err
+	}
+	state, ok := states[key]
+	return state, ok, nil
+}
+
+func (s *FilePollStateStore) Save(_ context.Context, key string, state PollState) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	states, err := s.read()
+	if err != nil {
+		return err
+	}
+	states[key] = state
+
+	content, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+
+	// replace the file atomically, so a crash while writing doesn't lose the states
+	temp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(content); err != nil {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), s.path)
+}
+
+func (s *FilePollStateStore) read() (map[string]PollState, error) {
+	states := make(map[string]PollState)
+	content, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// pollState backs off the poller of a worker exponentially after failed activations and saves its state to the store.
+// It is only used by the poller goroutine. A nil state never backs off.
+type pollState struct {
+	store          PollStateStore
+	key            string
+	maxBackoff     time.Duration
+	requestTimeout time.Duration
+	logger         logging.Logger
+
+	state   PollState
+	saved   PollState
+	retryAt time.Time
+}
+
+func newPollState(store PollStateStore, jobType, workerName string, logger logging.Logger) *pollState {
+	return &pollState{
+		store:          store,
+		key:            jobType + "/" + workerName,
+		maxBackoff:     DefaultMaxPollBackoff,
+		requestTimeout: DefaultRequestTimeout,
+		logger:         logger,
+	}
+}
+
+// restore loads the saved state, unless it is older than DefaultPollStateMaxAge, and applies its activation limit and
+// backoff. The first poll is delayed by a random part of the backoff, so restarted workers don't poll at once.
+func (s *pollState) restore(limit *adaptiveLimit) {
+	if s == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.requestTimeout)
+	defer cancel()
+
+	state, ok, err := s.store.Load(ctx, s.key)
+	if err != nil {
+		s.logger.Warn("Failed to load poll state of job worker", "key", s.key, "error", err)
+		return
+	}
+	if !ok || time.Since(state.UpdatedAt) > DefaultPollStateMaxAge {
+		return
+	}
+
+	s.state, s.saved = state, state
+	if limit != nil && state.ActivationLimit > 0 {
+		limit.limit = state.ActivationLimit
+		if limit.limit > limit.max {
+			limit.limit = limit.max
+		}
+	}
+	if state.Backoff > 0 {
+		s.retryAt = time.Now().Add(state.Backoff/2 + time.Duration(rand.Int63n(int64(state.Backoff/2)+1)))
+	}
+	s.logger.Info("Restored poll state of job worker", "key", s.key, "backoff", state.Backoff, "activationLimit", state.ActivationLimit)
+}
+
+func (s *pollState) onSuccess() {
+	if s == nil {
+		return
+	}
+
+	s.state.Backoff = 0
+	s.state.LastSuccessfulPoll = time.Now()
+	s.retryAt = time.Time{}
+}
+
+// onFailure backs off after a failed activation and returns the time until the poller polls again, which is the poll
+// interval without a poll state.
+func (s *pollState) onFailure(pollInterval time.Duration) time.Duration {
+	if s == nil {
+		return pollInterval
+	}
+
+	s.state.Backoff *= 2
+	if s.state.Backoff < pollInterval {
+		s.state.Backoff = pollInterval
+	}
+	if s.state.Backoff > s.maxBackoff {
+		s.state.Backoff = s.maxBackoff
+	}
+	s.retryAt = time.Now().Add(s.state.Backoff)
+	return s.state.Backoff
+}
+
+// ready returns whether the poller may activate jobs, i.e. it is not backing off.
+func (s *pollState) ready() bool {
+	return s == nil || !time.Now().Before(s.retryAt)
+}
+
+// pollDelay returns the time until the poller should poll again.
+func (s *pollState) pollDelay(pollInterval time.Duration) time.Duration {
+	if s == nil {
+		return pollInterval
+	}
+	if delay := time.Until(s.retryAt); delay > pollInterval {
+		return delay
+	}
+	return pollInterval
+}
+
+// save saves the state if the backoff or activation limit changed, or the last successful poll changed for a while.
+func (s *pollState) save(limit *adaptiveLimit) {
+	if s == nil {
+		return
+	}
+
+	if limit != nil {
+		s.state.ActivationLimit = limit.limit
+	}
+	if s.state.Backoff == s.saved.Backoff && s.state.ActivationLimit == s.saved.ActivationLimit &&
+		s.state.LastSuccessfulPoll.Sub(s.saved.LastSuccessfulPoll) < pollStateSaveInterval {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.requestTimeout)
+	defer cancel()
+
+	s.state.UpdatedAt = time.Now()
+	if err := s.store.Save(ctx, s.key, s.state); err != nil {
+		s.logger.Warn("Failed to save poll state of job worker", "key", s.key, "error", err)
+		return
+	}
+	s.saved = s.state
+}
@@ -0,0 +1,171 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type pollStateStoreStub struct {
+	lock   sync.Mutex
+	states map[string]PollState
+	saves  int
+}
+
+func (s *pollStateStoreStub) Load(_ context.Context, key string) (PollState, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	state, ok := s.states[key]
+	return state, ok, nil
+}
+
+func (s *pollStateStoreStub) Save(_ context.Context, key string, state PollState) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.states == nil {
+		s.states = make(map[string]PollState)
+	}
+	s.states[key] = state
+	s.saves++
+	return nil
+}
+
+func (s *pollStateStoreStub) state(key string) PollState {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.states[key]
+}
+
+func TestFilePollStateStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zeebe-poll-state")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store := NewFilePollStateStore(filepath.Join(dir, "state.json"))
+	_, ok, err := store.Load(context.Background(), "foo/worker")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	updatedAt := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Save(context.Background(), "foo/worker", PollState{Backoff: time.Second, ActivationLimit: 4, UpdatedAt: updatedAt}))
+	require.NoError(t, store.Save(context.Background(), "bar/worker", PollState{UpdatedAt: updatedAt}))
+
+	// a new store reads the states of the previous process
+	state, ok, err := NewFilePollStateStore(filepath.Join(dir, "state.json")).Load(context.Background(), "foo/worker")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, state.Backoff)
+	assert.Equal(t, 4, state.ActivationLimit)
+	assert.True(t, updatedAt.Equal(state.UpdatedAt))
+}
+
+func TestPollStateBacksOffExponentially(t *testing.T) {
+	store := &pollStateStoreStub{}
+	state := newPollState(store, "foo", "worker", logging.Default)
+	state.maxBackoff = 350 * time.Millisecond
+
+	var backoffs []time.Duration
+	for i := 0; i < 4; i++ {
+		state.onFailure(100 * time.Millisecond)
+		backoffs = append(backoffs, state.state.Backoff)
+	}
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 350 * time.Millisecond, 350 * time.Millisecond}, backoffs)
+	assert.False(t, state.ready())
+	assert.True(t, state.pollDelay(10*time.Millisecond) > 300*time.Millisecond)
+
+	state.onSuccess()
+	assert.True(t, state.ready())
+	assert.Equal(t, 10*time.Millisecond, state.pollDelay(10*time.Millisecond))
+}
+
+func TestPollStateIsSavedWhenChanged(t *testing.T) {
+	store := &pollStateStoreStub{}
+	state := newPollState(store, "foo", "worker", logging.Default)
+	limit := newAdaptiveLimit(8)
+
+	state.onSuccess()
+	state.save(limit)
+	state.onSuccess()
+	state.save(limit)
+	assert.Equal(t, 1, store.saves)
+
+	limit.onBackpressure()
+	state.onFailure(100 * time.Millisecond)
+	state.save(limit)
+	assert.Equal(t, 2, store.saves)
+	assert.Equal(t, 100*time.Millisecond, store.state("foo/worker").Backoff)
+	assert.Equal(t, 4, store.state("foo/worker").ActivationLimit)
+}
+
+func TestPollStateIsRestored(t *testing.T) {
+	store := &pollStateStoreStub{states: map[string]PollState{
+		"foo/worker":   {Backoff: time.Second, ActivationLimit: 64, UpdatedAt: time.Now()},
+		"stale/worker": {Backoff: time.Second, ActivationLimit: 2, UpdatedAt: time.Now().Add(-DefaultPollStateMaxAge - time.Minute)},
+	}}
+
+	state := newPollState(store, "foo", "worker", logging.Default)
+	limit := newAdaptiveLimit(8)
+	state.restore(limit)
+	assert.Equal(t, 8, limit.limit)
+	assert.False(t, state.ready())
+	delay := time.Until(state.retryAt)
+	assert.True(t, delay > 400*time.Millisecond && delay <= time.Second, "expected first poll to be delayed by half to all of the backoff, but got %s", delay)
+
+	stale := newPollState(store, "stale", "worker", logging.Default)
+	staleLimit := newAdaptiveLimit(8)
+	stale.restore(staleLimit)
+	assert.Equal(t, 8, staleLimit.limit)
+	assert.True(t, stale.ready())
+}
+
+func TestJobWorkerBacksOffAfterBackpressure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var activations int32
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, *pb.ActivateJobsRequest, ...interface{}) (pb.Gateway_ActivateJobsClient, error) {
+		atomic.AddInt32(&activations, 1)
+		return nil, status.Error(codes.ResourceExhausted, "backpressure")
+	}).AnyTimes()
+	store := &pollStateStoreStub{}
+
+	worker := NewJobWorkerBuilder(client, nil).JobType("foo").Handler(nil).Name("worker").PollInterval(20 * time.Millisecond).PollStatePersistence(store).Open()
+	defer worker.Close()
+
+	assert.Eventually(t, func() bool {
+		return store.state("foo/worker").Backoff >= 80*time.Millisecond
+	}, utils.DefaultTestTimeout, time.Millisecond)
+	// without backoff, the worker would have polled every 20ms
+	assert.True(t, atomic.LoadInt32(&activations) <= 4)
+}
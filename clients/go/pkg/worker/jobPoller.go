@@ -17,22 +17,24 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"io"
-	"log"
 	"sync"
 	"time"
 )
 
 type jobPoller struct {
-	client         pb.GatewayClient
-	request        pb.ActivateJobsRequest
-	requestTimeout time.Duration
-	maxJobsActive  int
-	pollInterval   time.Duration
+	client          pb.GatewayClient
+	request         pb.ActivateJobsRequest
+	requestTimeout  time.Duration
+	longPollTimeout time.Duration
+	maxJobsActive   int
+	pollInterval    time.Duration
 
 	jobQueue       chan entities.Job
 	workerFinished chan bool
@@ -40,13 +42,24 @@ type jobPoller struct {
 	remaining      int
 	threshold      int
 	metrics        JobWorkerMetrics
+	codec          entities.VariableCodec
+	activeJobs     *activeJobs
+	adaptiveLimit  *adaptiveLimit
+	starvation     *starvationDetection
+	sizing         *activationSizing
+	pause          *pauseControl
+	pollState      *pollState
+	logger         logging.Logger
 }
 
 func (poller *jobPoller) poll(closeWait *sync.WaitGroup) {
 	defer closeWait.Done()
 
-	// initial poll
-	poller.activateJobs()
+	// initial poll, unless the restored state backs off
+	poller.pollState.restore(poller.adaptiveLimit)
+	if poller.pollState.ready() {
+		poller.activateJobs()
+	}
 
 	for {
 		select {
@@ -55,7 +68,9 @@ func (poller *jobPoller) poll(closeWait *sync.WaitGroup) {
 			poller.remaining--
 			poller.setJobsRemainingCountMetric(poller.remaining)
 		// or the poll interval exceeded
-		case <-time.After(poller.pollInterval):
+		case <-time.After(poller.pollState.pollDelay(poller.pollInterval)):
+		// or the poller was resumed
+		case <-poller.pause.resumedSignal():
 		// or poller should stop
 		case <-poller.closeSignal:
 			poller.setJobsRemainingCountMetric(0)
@@ -69,40 +84,158 @@ func (poller *jobPoller) poll(closeWait *sync.WaitGroup) {
 }
 
 func (poller *jobPoller) shouldActivateJobs() bool {
-	return poller.remaining <= poller.threshold
+	return poller.remaining <= poller.threshold && poller.pollState.ready()
 }
 
 func (poller *jobPoller) activateJobs() {
-	ctx, cancel := context.WithTimeout(context.Background(), poller.requestTimeout)
+	maxJobsToActivate := poller.maxJobsActive - poller.remaining
+	if poller.adaptiveLimit != nil {
+		maxJobsToActivate = poller.adaptiveLimit.limit - poller.remaining
+	}
+	requestTimeout := poller.requestTimeout
+	longPollTimeout := poller.longPollTimeout
+	if poller.sizing != nil {
+		maxJobsToActivate = poller.sizing.maxJobsToActivate(maxJobsToActivate, poller.remaining)
+		if sizedTimeout := poller.sizing.longPollTimeout(poller.remaining); sizedTimeout > 0 {
+			longPollTimeout = sizedTimeout
+			requestTimeout = sizedTimeout + RequestTimeoutOffset
+		}
+	}
+	poller.request.RequestTimeout = longPollTimeout.Milliseconds()
+	if maxJobsToActivate <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
 	defer cancel()
+	ctx, cancelPoll, active := poller.pause.pollContext(ctx)
+	defer cancelPoll()
+	if !active {
+		return
+	}
 
-	poller.request.MaxJobsToActivate = int32(poller.maxJobsActive - poller.remaining)
+	poller.request.MaxJobsToActivate = int32(maxJobsToActivate)
 	stream, err := poller.client.ActivateJobs(ctx, &poller.request)
+	if err != nil && poller.pause.isPaused() {
+		return
+	}
+	if errors.Is(err, ErrNoActivationSlot) {
+		// the request waited for other activations of the client, it did not fail
+		poller.logger.Debug("Timed out waiting to request jobs", "jobType", poller.request.Type, "worker", poller.request.Worker, "error", err)
+		return
+	}
 	if err != nil {
-		log.Println("Failed to request jobs for worker", poller.request.Worker, err)
+		poller.logger.Warn("Failed to request jobs", "jobType", poller.request.Type, "worker", poller.request.Worker, "error", err)
+		poller.incrementActivationFailuresMetric()
+		poller.observeActivation(err)
 		return
 	}
 
+	activated := 0
+	defer func() {
+		poller.observeJobPollMetric(maxJobsToActivate, activated)
+	}()
+
 	for {
 		response, err := stream.Recv()
+		if err != nil && err != io.EOF && poller.pause.isPaused() {
+			// the activation was canceled by pausing the poller
+			break
+		}
 		if err != nil {
 			if err != io.EOF && status.Code(err) != codes.ResourceExhausted {
-				log.Println("Failed to activate jobs for worker", poller.request.Worker, err)
+				poller.logger.Warn("Failed to activate jobs", "jobType", poller.request.Type, "worker", poller.request.Worker, "error", err)
+				poller.incrementActivationFailuresMetric()
 			}
 
+			poller.observeActivation(err)
+			if err == io.EOF {
+				poller.observeSuccessfulPoll(maxJobsToActivate, activated)
+			}
 			break
 		}
 
+		poller.logger.Debug("Activated jobs", "jobType", poller.request.Type, "worker", poller.request.Worker, "count", len(response.Jobs))
+		activated += len(response.Jobs)
 		poller.remaining += len(response.Jobs)
 		poller.setJobsRemainingCountMetric(poller.remaining)
+		poller.incrementJobsActivatedMetric(len(response.Jobs))
 		for _, job := range response.Jobs {
-			poller.jobQueue <- entities.Job{ActivatedJob: *job}
+			activatedJob := entities.NewJob(job, poller.codec)
+			poller.activeJobs.add(&activatedJob)
+			poller.jobQueue <- activatedJob
 		}
 	}
 }
 
+// observeActivation adapts the activation limit and the backoff to the outcome of an activation, which is io.EOF if it
+// didn't fail.
+func (poller *jobPoller) observeActivation(err error) {
+	poller.adaptToActivationError(err)
+
+	if err == io.EOF {
+		poller.pollState.onSuccess()
+	} else {
+		backoff := poller.pollState.onFailure(poller.pollInterval)
+		poller.observePollBackoffMetric(backoff)
+	}
+	poller.pollState.save(poller.adaptiveLimit)
+}
+
+func (poller *jobPoller) adaptToActivationError(err error) {
+	if poller.adaptiveLimit == nil {
+		return
+	}
+
+	switch {
+	case status.Code(err) == codes.ResourceExhausted:
+		poller.adaptiveLimit.onBackpressure()
+		poller.logger.Info("Reduced job activation limit after backpressure", "jobType", poller.request.Type, "limit", poller.adaptiveLimit.limit)
+	case err == io.EOF:
+		poller.adaptiveLimit.onSuccess()
+	}
+}
+
+func (poller *jobPoller) observeSuccessfulPoll(requested, activated int) {
+	if metrics, ok := poller.metrics.(JobPollSaturationMetrics); ok {
+		if activated >= requested {
+			metrics.IncrementSaturatedPollsCount(poller.request.GetType())
+		} else {
+			metrics.IncrementTruncatedPollsCount(poller.request.GetType())
+		}
+	}
+
+	if poller.starvation != nil {
+		poller.starvation.observe(poller.request.GetType(), activated, time.Now())
+	}
+}
+
 func (poller *jobPoller) setJobsRemainingCountMetric(count int) {
 	if poller.metrics != nil {
 		poller.metrics.SetJobsRemainingCount(poller.request.GetType(), count)
 	}
 }
+
+func (poller *jobPoller) incrementJobsActivatedMetric(count int) {
+	if metrics, ok := poller.metrics.(JobActivationMetrics); ok {
+		metrics.IncrementJobsActivatedCount(poller.request.GetType(), count)
+	}
+}
+
+func (poller *jobPoller) observeJobPollMetric(requested, activated int) {
+	if metrics, ok := poller.metrics.(JobPollMetrics); ok {
+		metrics.ObserveJobPoll(poller.request.GetType(), requested, activated)
+	}
+}
+
+func (poller *jobPoller) observePollBackoffMetric(backoff time.Duration) {
+	if metrics, ok := poller.metrics.(JobPollBackoffMetrics); ok {
+		metrics.ObservePollBackoff(poller.request.GetType(), backoff)
+	}
+}
+
+func (poller *jobPoller) incrementActivationFailuresMetric() {
+	if metrics, ok := poller.metrics.(JobActivationMetrics); ok {
+		metrics.IncrementActivationFailuresCount(poller.request.GetType())
+	}
+}
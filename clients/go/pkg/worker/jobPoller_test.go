@@ -16,12 +16,17 @@
 package worker
 
 import (
+	"context"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
 	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
 	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"io"
 	"math"
 	"sync"
@@ -51,6 +56,7 @@ func (suite *JobPollerSuite) BeforeTest(suiteName, testName string) {
 		closeSignal:    make(chan struct{}),
 		remaining:      0,
 		threshold:      int(math.Round(float64(DefaultJobWorkerMaxJobActive) * DefaultJobWorkerPollThreshold)),
+		logger:         logging.Default,
 	}
 	suite.waitGroup.Add(1)
 }
@@ -228,6 +234,140 @@ func (suite *JobPollerSuite) TestShouldReportMetrics() {
 	suite.completeJob()
 }
 
+func (suite *JobPollerSuite) TestShouldReportActivationMetrics() {
+	// given
+	metrics := &activationMetricsStub{activated: make(map[string]int), failures: make(map[string]int)}
+	suite.poller.metrics = metrics
+	suite.poller.request.Type = "foo"
+	gomock.InOrder(
+		suite.client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(suite.singleJobStream(), nil),
+		suite.client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(nil, io.ErrUnexpectedEOF).AnyTimes(),
+	)
+
+	// when
+	go suite.poller.poll(&suite.waitGroup)
+	suite.consumeJob()
+
+	// then
+	suite.Eventually(func() bool {
+		return metrics.activatedCount("foo") == 1 && metrics.failuresCount("foo") > 0
+	}, utils.DefaultTestTimeout, time.Millisecond)
+}
+
+func (suite *JobPollerSuite) TestShouldReportPollMetrics() {
+	// given
+	metrics := &activationMetricsStub{activated: make(map[string]int), failures: make(map[string]int)}
+	suite.poller.metrics = metrics
+	suite.poller.maxJobsActive = 5
+	suite.poller.threshold = 5
+	gomock.InOrder(
+		suite.client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(suite.singleJobStream(), nil),
+		suite.client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(nil, io.ErrUnexpectedEOF).AnyTimes(),
+	)
+
+	// when
+	go suite.poller.poll(&suite.waitGroup)
+	suite.consumeJob()
+
+	// then
+	suite.Eventually(func() bool {
+		poll, ok := metrics.firstPoll()
+		return ok && poll == [2]int{5, 1}
+	}, utils.DefaultTestTimeout, time.Millisecond)
+}
+
+func (suite *JobPollerSuite) TestShouldReportPollSaturationMetrics() {
+	// given
+	metrics := &activationMetricsStub{activated: make(map[string]int), failures: make(map[string]int)}
+	suite.poller.metrics = metrics
+	suite.poller.request.Type = "foo"
+	suite.poller.pollInterval = 10 * time.Millisecond
+	suite.poller.maxJobsActive = 2
+	suite.poller.threshold = 2
+	gomock.InOrder(
+		suite.client.EXPECT().ActivateJobs(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.ActivateJobsRequest{
+			Type:              "foo",
+			MaxJobsToActivate: 2,
+		}}).Return(suite.singleJobStream(), nil),
+		suite.client.EXPECT().ActivateJobs(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.ActivateJobsRequest{
+			Type:              "foo",
+			MaxJobsToActivate: 1,
+		}}).Return(suite.singleJobStream(), nil),
+		suite.client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(nil, io.ErrUnexpectedEOF).AnyTimes(),
+	)
+
+	// when
+	go suite.poller.poll(&suite.waitGroup)
+	suite.consumeJob()
+	suite.consumeJob()
+
+	// then the first poll is truncated and the second is saturated
+	suite.Eventually(func() bool {
+		return metrics.pollsCount("foo") == [2]int{1, 1}
+	}, utils.DefaultTestTimeout, time.Millisecond)
+}
+
+func (suite *JobPollerSuite) TestShouldAdaptActivationsToBackpressure() {
+	// given
+	suite.poller.pollInterval = 10 * time.Millisecond
+	suite.poller.maxJobsActive = 10
+	suite.poller.threshold = 10
+	suite.poller.adaptiveLimit = newAdaptiveLimit(10)
+
+	activated := make(chan struct{})
+	gomock.InOrder(
+		suite.client.EXPECT().ActivateJobs(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.ActivateJobsRequest{
+			MaxJobsToActivate: 10,
+		}}).Return(nil, status.Error(codes.ResourceExhausted, "backpressure")),
+		suite.client.EXPECT().ActivateJobs(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.ActivateJobsRequest{
+			MaxJobsToActivate: 5,
+		}}).Return(suite.emptyStream(), nil),
+		suite.client.EXPECT().ActivateJobs(gomock.Any(), &utils.RPCTestMsg{Msg: &pb.ActivateJobsRequest{
+			MaxJobsToActivate: 6,
+		}}).DoAndReturn(func(context.Context, *pb.ActivateJobsRequest, ...interface{}) (pb.Gateway_ActivateJobsClient, error) {
+			close(activated)
+			return suite.blockingStream(), nil
+		}),
+	)
+
+	// when
+	go suite.poller.poll(&suite.waitGroup)
+
+	// then the limit is halved on backpressure and increased after a successful activation
+	select {
+	case <-activated:
+	case <-time.After(utils.DefaultTestTimeout):
+		suite.FailNow("Failed to wait for adapted activation")
+	}
+}
+
+func (suite *JobPollerSuite) TestShouldSetRequestTimeoutOfEveryPoll() {
+	// given
+	suite.poller.longPollTimeout = time.Second
+	suite.poller.maxJobsActive = 2
+	suite.poller.threshold = 2
+	requestTimeouts := make(chan int64, 2)
+	activate := func(_ context.Context, request *pb.ActivateJobsRequest, _ ...interface{}) (pb.Gateway_ActivateJobsClient, error) {
+		requestTimeouts <- request.RequestTimeout
+		// e.g. an interceptor for gateways without long polling
+		request.RequestTimeout = 0
+		return suite.singleJobStream(), nil
+	}
+	gomock.InOrder(
+		suite.client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).DoAndReturn(activate).Times(2),
+		suite.client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(nil, io.ErrUnexpectedEOF).AnyTimes(),
+	)
+
+	// when
+	go suite.poller.poll(&suite.waitGroup)
+	suite.consumeJob()
+	suite.consumeJob()
+
+	// then
+	suite.Equal(int64(1000), <-requestTimeouts)
+	suite.Equal(int64(1000), <-requestTimeouts)
+}
+
 func (suite *JobPollerSuite) singleJobStream() pb.Gateway_ActivateJobsClient {
 	stream := mock_pb.NewMockGateway_ActivateJobsClient(suite.ctrl)
 	gomock.InOrder(
@@ -242,6 +382,23 @@ func (suite *JobPollerSuite) singleJobStream() pb.Gateway_ActivateJobsClient {
 	return stream
 }
 
+func (suite *JobPollerSuite) emptyStream() pb.Gateway_ActivateJobsClient {
+	stream := mock_pb.NewMockGateway_ActivateJobsClient(suite.ctrl)
+	stream.EXPECT().Recv().Return(nil, io.EOF)
+
+	return stream
+}
+
+func (suite *JobPollerSuite) blockingStream() pb.Gateway_ActivateJobsClient {
+	stream := mock_pb.NewMockGateway_ActivateJobsClient(suite.ctrl)
+	stream.EXPECT().Recv().DoAndReturn(func() (*pb.ActivateJobsResponse, error) {
+		<-suite.poller.closeSignal
+		return nil, io.EOF
+	})
+
+	return stream
+}
+
 func (suite *JobPollerSuite) streamFailureAfterFirstJob() pb.Gateway_ActivateJobsClient {
 	stream := mock_pb.NewMockGateway_ActivateJobsClient(suite.ctrl)
 	gomock.InOrder(
@@ -263,3 +420,150 @@ func (suite *JobPollerSuite) completeJob() {
 	suite.consumeJob()
 	suite.poller.workerFinished <- true
 }
+
+type activationMetricsStub struct {
+	mutex     sync.Mutex
+	activated map[string]int
+	failures  map[string]int
+	polls     [][2]int
+	saturated map[string]int
+	truncated map[string]int
+	backoffs  map[string][]time.Duration
+}
+
+func (m *activationMetricsStub) ObserveJobPoll(_ string, requested, activated int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.polls = append(m.polls, [2]int{requested, activated})
+}
+
+func (m *activationMetricsStub) firstPoll() ([2]int, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if len(m.polls) == 0 {
+		return [2]int{}, false
+	}
+	return m.polls[0], true
+}
+
+func (m *activationMetricsStub) SetJobsRemainingCount(string, int) {}
+
+func (m *activationMetricsStub) IncrementJobsActivatedCount(jobType string, count int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.activated[jobType] += count
+}
+
+func (m *activationMetricsStub) IncrementActivationFailuresCount(jobType string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.failures[jobType]++
+}
+
+func (m *activationMetricsStub) activatedCount(jobType string) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.activated[jobType]
+}
+
+func (m *activationMetricsStub) failuresCount(jobType string) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.failures[jobType]
+}
+
+func (m *activationMetricsStub) IncrementSaturatedPollsCount(jobType string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.saturated == nil {
+		m.saturated = make(map[string]int)
+	}
+	m.saturated[jobType]++
+}
+
+func (m *activationMetricsStub) IncrementTruncatedPollsCount(jobType string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.truncated == nil {
+		m.truncated = make(map[string]int)
+	}
+	m.truncated[jobType]++
+}
+
+func (m *activationMetricsStub) ObservePollBackoff(jobType string, backoff time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.backoffs == nil {
+		m.backoffs = make(map[string][]time.Duration)
+	}
+	m.backoffs[jobType] = append(m.backoffs[jobType], backoff)
+}
+
+func (m *activationMetricsStub) pollBackoffs(jobType string) []time.Duration {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.backoffs[jobType]
+}
+
+// pollsCount returns the count of saturated and truncated polls
+func (m *activationMetricsStub) pollsCount(jobType string) [2]int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return [2]int{m.saturated[jobType], m.truncated[jobType]}
+}
+
+func TestJobPollerDoesNotBackOffWaitingForActivationSlot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w for jobs of type 'foo'", ErrNoActivationSlot))
+	metrics := &activationMetricsStub{activated: make(map[string]int), failures: make(map[string]int)}
+	poller := jobPoller{
+		client:         client,
+		request:        pb.ActivateJobsRequest{Type: "foo"},
+		requestTimeout: utils.DefaultTestTimeout,
+		maxJobsActive:  DefaultJobWorkerMaxJobActive,
+		pollInterval:   DefaultJobWorkerPollInterval,
+		metrics:        metrics,
+		pollState:      newPollState(&pollStateStoreStub{}, "foo", "worker", logging.Discard),
+		logger:         logging.Discard,
+	}
+
+	poller.activateJobs()
+
+	if failures := metrics.failuresCount("foo"); failures != 0 {
+		t.Errorf("Expected waiting for an activation slot not to count as failure, got %d failures", failures)
+	}
+	if !poller.pollState.ready() || len(metrics.pollBackoffs("foo")) > 0 {
+		t.Error("Expected poller not to back off after waiting for an activation slot")
+	}
+}
+
+func TestJobPollerReportsPollBackoff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(nil, io.ErrUnexpectedEOF).Times(2)
+	metrics := &activationMetricsStub{activated: make(map[string]int), failures: make(map[string]int)}
+	poller := jobPoller{
+		client:         client,
+		request:        pb.ActivateJobsRequest{Type: "foo"},
+		requestTimeout: utils.DefaultTestTimeout,
+		maxJobsActive:  DefaultJobWorkerMaxJobActive,
+		pollInterval:   10 * time.Millisecond,
+		metrics:        metrics,
+		pollState:      newPollState(&pollStateStoreStub{}, "foo", "worker", logging.Discard),
+		logger:         logging.Discard,
+	}
+
+	poller.activateJobs()
+	poller.activateJobs()
+
+	backoffs := metrics.pollBackoffs("foo")
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}
+	if len(backoffs) != len(expected) || backoffs[0] != expected[0] || backoffs[1] != expected[1] {
+		t.Errorf("Expected poll backoffs %v, got %v", expected, backoffs)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/jsonpath"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+)
+
+type variableProjection struct {
+	name string
+	path *jsonpath.Path
+}
+
+// projectVariables wraps the handler, so the projections are evaluated on the variables of each job and set on the
+// job before the handler is called. Projections of values which don't exist are not set. Jobs whose variables can't
+// be decoded are failed without retries, as activating them again would not fix the variables.
+func projectVariables(projections []variableProjection, requestTimeout time.Duration, logger logging.Logger, handler JobHandler) JobHandler {
+	return func(client JobClient, job entities.Job) {
+		var variables interface{}
+		err := job.GetVariablesAs(&variables, entities.UseNumber())
+		if err == nil {
+			for _, projection := range projections {
+				if value, ok := projection.path.Evaluate(variables); ok {
+					job.SetProjection(projection.name, value)
+				}
+			}
+
+			handler(client, job)
+			return
+		}
+
+		logger.Warn("Failed to decode job variables for projections", "jobKey", job.Key, "jobType", job.Type, "error", err)
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+		_, err = client.NewFailJobCommand().JobKey(job.Key).Retries(0).ErrorMessage("invalid variables: " + err.Error()).Send(ctx)
+		if err != nil {
+			logger.Warn("Failed to fail job with invalid variables", "jobKey", job.Key, "error", err)
+		}
+	}
+}
+
+// fetchProjectedVariables adds the top-level variables which the projections select from to the variables to fetch,
+// unless all variables are fetched anyway.
+func fetchProjectedVariables(fetchVariables []string, projections []variableProjection) []string {
+	if len(fetchVariables) == 0 {
+		return fetchVariables
+	}
+
+	fetched := make(map[string]bool, len(fetchVariables))
+	for _, name := range fetchVariables {
+		fetched[name] = true
+	}
+
+	for _, projection := range projections {
+		root := projection.path.Root()
+		if root == "" {
+			// the projection selects from the whole document, so all variables are needed
+			return nil
+		}
+		if !fetched[root] {
+			fetched[root] = true
+			fetchVariables = append(fetchVariables, root)
+		}
+	}
+	return fetchVariables
+}
@@ -0,0 +1,75 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/jsonpath"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func TestJobWorkerSetsVariableProjections(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := activateOnce(ctrl, &pb.ActivatedJob{Key: 1, Variables: `{"order":{"id":"DE-42","items":[{"sku":"a"},{"sku":"b"}]}}`})
+
+	type projections struct {
+		skus    []string
+		orderID string
+		missing bool
+	}
+	handled := make(chan projections, 1)
+	worker := NewJobWorkerBuilder(client, gatewayJobClient{client}).JobType("foo").Handler(func(_ JobClient, job entities.Job) {
+		var p projections
+		assert.NoError(t, job.GetProjectionAs("skus", &p.skus))
+		assert.NoError(t, job.GetProjectionAs("orderId", &p.orderID))
+		_, exists := job.GetProjection("customer")
+		p.missing = !exists
+		handled <- p
+	}).
+		VariableProjection("skus", "$.order.items[*].sku").
+		VariableProjection("orderId", "$.order.id").
+		VariableProjection("customer", "$.customer.name").
+		VariableProjection("invalid", "order.id").
+		Open()
+	defer worker.Close()
+
+	select {
+	case p := <-handled:
+		assert.Equal(t, []string{"a", "b"}, p.skus)
+		assert.Equal(t, "DE-42", p.orderID)
+		assert.True(t, p.missing)
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected job to be handled")
+	}
+}
+
+func TestFetchProjectedVariables(t *testing.T) {
+	projections := []variableProjection{
+		{name: "skus", path: jsonpath.MustCompile("$.order.items[*].sku")},
+		{name: "city", path: jsonpath.MustCompile("$.customer.address.city")},
+	}
+
+	assert.Equal(t, []string{"order", "customer"}, fetchProjectedVariables([]string{"order"}, projections))
+	assert.Empty(t, fetchProjectedVariables(nil, projections))
+	assert.Empty(t, fetchProjectedVariables([]string{"order"}, []variableProjection{{name: "all", path: jsonpath.MustCompile("$.*")}}))
+}
@@ -0,0 +1,45 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/jsonschema"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+)
+
+// validateInput wraps the handler, so it is only called for jobs whose variables match the schema. Jobs with invalid
+// variables are failed without retries and the violations as error message, as activating them again would not fix
+// the variables; the resulting incident can be resolved after the variables are corrected.
+func validateInput(schema *jsonschema.Schema, requestTimeout time.Duration, logger logging.Logger, handler JobHandler) JobHandler {
+	return func(client JobClient, job entities.Job) {
+		err := schema.ValidateJSON(job.Variables)
+		if err == nil {
+			handler(client, job)
+			return
+		}
+
+		logger.Warn("Job variables do not match the input schema", "jobKey", job.Key, "jobType", job.Type, "error", err)
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+		_, err = client.NewFailJobCommand().JobKey(job.Key).Retries(0).ErrorMessage("invalid input variables: " + err.Error()).Send(ctx)
+		if err != nil {
+			logger.Warn("Failed to fail job with invalid input variables", "jobKey", job.Key, "error", err)
+		}
+	}
+}
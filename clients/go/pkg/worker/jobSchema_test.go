@@ -0,0 +1,65 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/jsonschema"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func TestJobWorkerFailsJobsWithInvalidInput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := activateOnce(ctrl,
+		&pb.ActivatedJob{Key: 1, Retries: 3, Variables: `{"amount":"ten"}`},
+		&pb.ActivatedJob{Key: 2, Retries: 3, Variables: `{"amount":10}`},
+	)
+	failed := make(chan *pb.FailJobRequest, 1)
+	client.EXPECT().FailJob(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, request *pb.FailJobRequest, _ ...interface{}) (*pb.FailJobResponse, error) {
+		failed <- request
+		return &pb.FailJobResponse{}, nil
+	})
+
+	handled := make(chan int64, 2)
+	schema := jsonschema.MustCompile(`{"type":"object","required":["amount"],"properties":{"amount":{"type":"number"}}}`)
+	worker := NewJobWorkerBuilder(client, gatewayJobClient{client}).JobType("foo").Handler(func(_ JobClient, job entities.Job) {
+		handled <- job.Key
+	}).InputSchema(schema).Open()
+	defer worker.Close()
+
+	select {
+	case key := <-handled:
+		assert.EqualValues(t, 2, key)
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected valid job to be handled")
+	}
+	select {
+	case request := <-failed:
+		assert.EqualValues(t, 1, request.JobKey)
+		assert.EqualValues(t, 0, request.Retries)
+		assert.True(t, strings.Contains(request.ErrorMessage, "$.amount: expected number, got string"), request.ErrorMessage)
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected invalid job to be failed")
+	}
+}
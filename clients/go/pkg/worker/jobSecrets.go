@@ -0,0 +1,59 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/secrets"
+)
+
+// resolveSecrets wraps the handler, so the secret placeholders in the custom headers and variables of each job are
+// replaced with the values of the secrets before the handler is called. Jobs whose secrets don't exist are failed
+// without retries, while jobs whose secrets can't be looked up, e.g. because the provider is unavailable, are failed
+// with decremented retries.
+func resolveSecrets(provider secrets.Provider, requestTimeout time.Duration, logger logging.Logger, handler JobHandler) JobHandler {
+	return func(client JobClient, job entities.Job) {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+
+		customHeaders, err := secrets.ResolveJSON(ctx, provider, job.CustomHeaders)
+		if err == nil {
+			job.CustomHeaders = customHeaders
+			var variables string
+			variables, err = secrets.ResolveJSON(ctx, provider, job.Variables)
+			job.Variables = variables
+		}
+		if err == nil {
+			cancel()
+			handler(client, job)
+			return
+		}
+
+		retries := job.Retries - 1
+		if errors.Is(err, secrets.ErrNotFound) {
+			retries = 0
+		}
+		logger.Warn("Failed to resolve secrets of job", "jobKey", job.Key, "jobType", job.Type, "error", err)
+		_, err = client.NewFailJobCommand().JobKey(job.Key).Retries(retries).ErrorMessage(err.Error()).Send(ctx)
+		if err != nil {
+			logger.Warn("Failed to fail job with unresolved secrets", "jobKey", job.Key, "error", err)
+		}
+	}
+}
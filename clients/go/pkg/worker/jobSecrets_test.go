@@ -0,0 +1,82 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/secrets"
+)
+
+var testSecrets = secrets.ProviderFunc(func(_ context.Context, name string) (string, error) {
+	if name == "API_KEY" {
+		return "abc", nil
+	}
+	return "", secrets.ErrNotFound
+})
+
+func TestJobWorkerResolvesSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := activateOnce(ctrl, &pb.ActivatedJob{Key: 1, CustomHeaders: `{"authorization":"Bearer {{secrets.API_KEY}}"}`, Variables: `{"key":"{{secrets.API_KEY}}"}`})
+	handled := make(chan entities.Job, 1)
+
+	worker := NewJobWorkerBuilder(client, gatewayJobClient{client}).JobType("foo").Handler(func(_ JobClient, job entities.Job) {
+		handled <- job
+	}).SecretResolution(testSecrets).Open()
+	defer worker.Close()
+
+	select {
+	case job := <-handled:
+		assert.Equal(t, `{"authorization":"Bearer abc"}`, job.CustomHeaders)
+		assert.Equal(t, `{"key":"abc"}`, job.Variables)
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected job to be handled")
+	}
+}
+
+func TestJobWorkerFailsJobWithMissingSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := activateOnce(ctrl, &pb.ActivatedJob{Key: 1, Retries: 3, CustomHeaders: `{"token":"{{secrets.TOKEN}}"}`})
+	failed := make(chan *pb.FailJobRequest, 1)
+	client.EXPECT().FailJob(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, request *pb.FailJobRequest, _ ...interface{}) (*pb.FailJobResponse, error) {
+		failed <- request
+		return &pb.FailJobResponse{}, nil
+	})
+
+	worker := NewJobWorkerBuilder(client, gatewayJobClient{client}).JobType("foo").Handler(func(JobClient, entities.Job) {
+		t.Error("expected handler not to be called")
+	}).SecretResolution(testSecrets).Open()
+	defer worker.Close()
+
+	select {
+	case request := <-failed:
+		assert.EqualValues(t, 1, request.JobKey)
+		assert.EqualValues(t, 0, request.Retries)
+		assert.Contains(t, request.ErrorMessage, "TOKEN")
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected job to be failed")
+	}
+}
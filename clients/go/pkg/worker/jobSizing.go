@@ -0,0 +1,109 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// sizingWeight is the weight of the latest handler duration in the moving average
+	sizingWeight = 0.2
+	// sizingHeadroom is the fraction of the job timeout in which the activated jobs are expected to be handled, to
+	// leave room for variance of the handler duration
+	sizingHeadroom = 0.5
+	// minSizedRequestTimeout is the shortest long polling timeout of a sized activation
+	minSizedRequestTimeout = time.Second
+)
+
+// activationSizing sizes the activations of a worker by the moving average of the handler duration: it activates no
+// more jobs than the handlers can complete within the job timeout, after the jobs which are active already, and ends
+// the long polling when the active jobs are expected to be completed, so the freed handlers can be used again sooner.
+// Until the first handler returned, activations are not sized.
+type activationSizing struct {
+	concurrency    int
+	jobTimeout     time.Duration
+	requestTimeout time.Duration
+
+	lock            sync.Mutex
+	averageDuration time.Duration
+}
+
+func newActivationSizing(concurrency int, jobTimeout, requestTimeout time.Duration) *activationSizing {
+	return &activationSizing{concurrency: concurrency, jobTimeout: jobTimeout, requestTimeout: requestTimeout}
+}
+
+func (s *activationSizing) observe(duration time.Duration) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.averageDuration == 0 {
+		s.averageDuration = duration
+		return
+	}
+	s.averageDuration = time.Duration(sizingWeight*float64(duration) + (1-sizingWeight)*float64(s.averageDuration))
+}
+
+func (s *activationSizing) average() time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.averageDuration
+}
+
+// maxJobsToActivate limits the number of jobs to activate, which is at most free, while active jobs are not handled
+// yet. At least one job is activated if no job is active.
+func (s *activationSizing) maxJobsToActivate(free, active int) int {
+	average := s.average()
+	if average <= 0 || s.jobTimeout <= 0 {
+		return free
+	}
+
+	capacity := int(sizingHeadroom * float64(s.concurrency) * float64(s.jobTimeout) / float64(average))
+	limit := capacity - active
+	if limit > free {
+		limit = free
+	}
+	switch {
+	case active == 0 && limit < 1:
+		return 1
+	case limit < 0:
+		return 0
+	}
+	return limit
+}
+
+// longPollTimeout returns the time the gateway waits for jobs to activate, which is the time until the active jobs are
+// expected to be handled, within the configured request timeout. If no request timeout is configured, the default of
+// the gateway is kept and zero is returned.
+func (s *activationSizing) longPollTimeout(active int) time.Duration {
+	average := s.average()
+	if average <= 0 || active == 0 || s.requestTimeout <= 0 {
+		return s.requestTimeout
+	}
+
+	timeout := time.Duration(active) * average / time.Duration(s.concurrency)
+	if timeout < minSizedRequestTimeout {
+		timeout = minSizedRequestTimeout
+	}
+	if timeout > s.requestTimeout {
+		timeout = s.requestTimeout
+	}
+	return timeout
+}
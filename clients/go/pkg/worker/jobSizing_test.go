@@ -0,0 +1,115 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func TestActivationSizingIsUnsizedWithoutHandlerDuration(t *testing.T) {
+	sizing := newActivationSizing(4, time.Minute, 10*time.Second)
+
+	assert.Equal(t, 32, sizing.maxJobsToActivate(32, 0))
+	assert.Equal(t, 10*time.Second, sizing.longPollTimeout(8))
+}
+
+func TestActivationSizingLimitsJobsToJobTimeout(t *testing.T) {
+	sizing := newActivationSizing(4, time.Minute, 10*time.Second)
+	sizing.observe(5 * time.Second)
+
+	// 4 handlers complete 24 jobs in half of the job timeout
+	assert.Equal(t, 24, sizing.maxJobsToActivate(32, 0))
+	assert.Equal(t, 14, sizing.maxJobsToActivate(22, 10))
+	assert.Equal(t, 0, sizing.maxJobsToActivate(4, 28))
+}
+
+func TestActivationSizingActivatesOneJobIfHandlerIsSlowerThanJobTimeout(t *testing.T) {
+	sizing := newActivationSizing(1, time.Minute, 10*time.Second)
+	sizing.observe(2 * time.Minute)
+
+	assert.Equal(t, 1, sizing.maxJobsToActivate(32, 0))
+	assert.Equal(t, 0, sizing.maxJobsToActivate(31, 1))
+}
+
+func TestActivationSizingAveragesHandlerDuration(t *testing.T) {
+	sizing := newActivationSizing(1, time.Minute, 10*time.Second)
+	sizing.observe(time.Second)
+	sizing.observe(6 * time.Second)
+
+	assert.Equal(t, 2*time.Second, sizing.average())
+}
+
+func TestActivationSizingEndsLongPollingWhenActiveJobsAreHandled(t *testing.T) {
+	sizing := newActivationSizing(2, time.Minute, 10*time.Second)
+	sizing.observe(2 * time.Second)
+
+	assert.Equal(t, 10*time.Second, sizing.longPollTimeout(0))
+	assert.Equal(t, 4*time.Second, sizing.longPollTimeout(4))
+	assert.Equal(t, 10*time.Second, sizing.longPollTimeout(20))
+	assert.Equal(t, minSizedRequestTimeout, sizing.longPollTimeout(1))
+
+	sizing.observe(time.Millisecond)
+	assert.Equal(t, minSizedRequestTimeout, sizing.longPollTimeout(1))
+}
+
+func TestJobWorkerSizesActivationsByHandlerDuration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	maxJobsToActivate := make(chan int32, 100)
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	first := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+	first.EXPECT().Recv().Return(&pb.ActivateJobsResponse{Jobs: []*pb.ActivatedJob{{Key: 1}}}, nil)
+	first.EXPECT().Recv().Return(nil, io.EOF)
+	empty := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+	empty.EXPECT().Recv().Return(nil, io.EOF).AnyTimes()
+	client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, request *pb.ActivateJobsRequest, _ ...interface{}) (pb.Gateway_ActivateJobsClient, error) {
+		maxJobsToActivate <- request.MaxJobsToActivate
+		return first, nil
+	})
+	client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, request *pb.ActivateJobsRequest, _ ...interface{}) (pb.Gateway_ActivateJobsClient, error) {
+		maxJobsToActivate <- request.MaxJobsToActivate
+		return empty, nil
+	}).AnyTimes()
+
+	handled := make(chan struct{})
+	worker := NewJobWorkerBuilder(client, nil).JobType("foo").Handler(func(JobClient, entities.Job) {
+		time.Sleep(20 * time.Millisecond)
+		close(handled)
+	}).Concurrency(1).Timeout(100 * time.Millisecond).PollInterval(5 * time.Millisecond).ThroughputSizing().Open()
+	defer worker.Close()
+
+	assert.EqualValues(t, DefaultJobWorkerMaxJobActive, <-maxJobsToActivate)
+	select {
+	case <-handled:
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected job to be handled")
+	}
+
+	// 1 handler completes 2 jobs of 20ms in half of the job timeout
+	assert.Eventually(t, func() bool {
+		return <-maxJobsToActivate == 2
+	}, utils.DefaultTestTimeout, time.Millisecond)
+}
@@ -0,0 +1,69 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+)
+
+// PendingJobsFunc returns the number of pending jobs of the job type, e.g. from a backlog metric which is exported by
+// the brokers, or false if it is not known.
+type PendingJobsFunc func(jobType string) (int, bool)
+
+// StarvationHandler is called when a worker polled successfully, but activated no jobs for the starvation timeout,
+// while jobs of its job type are pending. This usually means that the worker is misconfigured, e.g. its job type does
+// not match the task definition of the workflow, or the jobs are activated by other workers.
+type StarvationHandler func(jobType string, idle time.Duration, pending int)
+
+type starvationDetection struct {
+	timeout   time.Duration
+	pending   PendingJobsFunc
+	handler   StarvationHandler
+	idleSince time.Time
+}
+
+func newStarvationDetection(timeout time.Duration, pending PendingJobsFunc, handler StarvationHandler, logger logging.Logger) *starvationDetection {
+	if handler == nil {
+		handler = func(jobType string, idle time.Duration, pending int) {
+			logger.Warn("Worker activated no jobs, although jobs are pending", "jobType", jobType, "idle", idle, "pending", pending)
+		}
+	}
+
+	return &starvationDetection{timeout: timeout, pending: pending, handler: handler, idleSince: time.Now()}
+}
+
+// observe records a successful poll and calls the handler if the worker is starved. The idle time is restarted after
+// the handler is called, so it is called at most once per timeout while the worker is starved.
+func (d *starvationDetection) observe(jobType string, activated int, now time.Time) {
+	if activated > 0 {
+		d.idleSince = now
+		return
+	}
+
+	idle := now.Sub(d.idleSince)
+	if idle < d.timeout {
+		return
+	}
+
+	pending, ok := d.pending(jobType)
+	if !ok || pending <= 0 {
+		return
+	}
+
+	d.handler(jobType, idle, pending)
+	d.idleSince = now
+}
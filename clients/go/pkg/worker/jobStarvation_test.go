@@ -0,0 +1,78 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+)
+
+type starvationRecorder struct {
+	idle    []time.Duration
+	pending []int
+}
+
+func (r *starvationRecorder) handle(_ string, idle time.Duration, pending int) {
+	r.idle = append(r.idle, idle)
+	r.pending = append(r.pending, pending)
+}
+
+func TestStarvationDetectionCallsHandlerAfterTimeout(t *testing.T) {
+	recorder := &starvationRecorder{}
+	detection := newStarvationDetection(time.Minute, func(string) (int, bool) { return 3, true }, recorder.handle, logging.Default)
+	start := detection.idleSince
+
+	detection.observe("foo", 0, start.Add(30*time.Second))
+	assert.Empty(t, recorder.idle)
+
+	detection.observe("foo", 0, start.Add(time.Minute))
+	assert.Equal(t, []time.Duration{time.Minute}, recorder.idle)
+	assert.Equal(t, []int{3}, recorder.pending)
+
+	// the idle time is restarted after the handler was called
+	detection.observe("foo", 0, start.Add(90*time.Second))
+	assert.Len(t, recorder.idle, 1)
+}
+
+func TestStarvationDetectionRestartsIdleTimeOnActivation(t *testing.T) {
+	recorder := &starvationRecorder{}
+	detection := newStarvationDetection(time.Minute, func(string) (int, bool) { return 3, true }, recorder.handle, logging.Default)
+	start := detection.idleSince
+
+	detection.observe("foo", 1, start.Add(50*time.Second))
+	detection.observe("foo", 0, start.Add(time.Minute))
+
+	assert.Empty(t, recorder.idle)
+}
+
+func TestStarvationDetectionIgnoresIdleWorkerWithoutPendingJobs(t *testing.T) {
+	recorder := &starvationRecorder{}
+	pending, known := 0, true
+	detection := newStarvationDetection(time.Minute, func(string) (int, bool) { return pending, known }, recorder.handle, logging.Default)
+	start := detection.idleSince
+
+	detection.observe("foo", 0, start.Add(time.Minute))
+	known = false
+	pending = 2
+	detection.observe("foo", 0, start.Add(2*time.Minute))
+	assert.Empty(t, recorder.idle)
+
+	known = true
+	detection.observe("foo", 0, start.Add(3*time.Minute))
+	assert.Equal(t, []time.Duration{3 * time.Minute}, recorder.idle)
+}
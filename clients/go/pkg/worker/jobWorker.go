@@ -16,9 +16,12 @@
 package worker
 
 import (
+	"context"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
 	"sync"
+	"time"
 )
 
 type JobClient interface {
@@ -29,22 +32,93 @@ type JobClient interface {
 type JobHandler func(client JobClient, job entities.Job)
 
 type JobWorker interface {
-	// Initiate graceful shutdown and awaits termination
+	// Initiate graceful shutdown and awaits termination. The contexts of running ContextJobHandlers are canceled, and
+	// failed jobs which wait for their retry backoff are failed immediately
 	Close()
 	// Await termination of worker
 	AwaitClose()
+	// Stop activating jobs and wait until the handlers of all activated jobs finished or the context is done, in which
+	// case the context error is returned. Jobs which were not passed to their handler yet are released if the worker was
+	// built with ReleaseJobsOnDrain, and failed jobs which wait for their retry backoff are failed immediately. The
+	// worker is closed afterwards, but Drain does not wait for handlers which are still running.
+	Drain(ctx context.Context) error
+	// Stop activating jobs until Resume is called, e.g. during an outage of a downstream system. The activation in
+	// progress is canceled, while the handlers of activated jobs keep running
+	Pause()
+	// Resume activating jobs after Pause
+	Resume()
+	// Paused returns whether the worker is paused
+	Paused() bool
 }
 
 type jobWorkerController struct {
 	closePoller     chan struct{}
 	closeDispatcher chan struct{}
+	pollerClosed    chan struct{}
 	closeWait       *sync.WaitGroup
+
+	stopPoller     *sync.Once
+	stopDispatcher *sync.Once
+	activeJobs     *activeJobs
+	jobClient      JobClient
+	releaseOnDrain bool
+	failures       *jobFailureHandling
+	requestTimeout time.Duration
+	logger         logging.Logger
+	cancelHandlers context.CancelFunc
+	pause          *pauseControl
 }
 
 func (controller jobWorkerController) Close() {
-	close(controller.closePoller)
-	close(controller.closeDispatcher)
+	controller.stopPolling()
+	controller.stopDispatching()
 	controller.AwaitClose()
+	controller.failures.flush()
+}
+
+func (controller jobWorkerController) Drain(ctx context.Context) error {
+	controller.stopPolling()
+
+	var err error
+	select {
+	case <-controller.pollerClosed:
+		err = controller.activeJobs.wait(ctx)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	controller.stopDispatching()
+	controller.failures.flush()
+	if err != nil && controller.releaseOnDrain {
+		controller.activeJobs.release(controller.jobClient, controller.requestTimeout, controller.logger)
+	}
+
+	return err
+}
+
+func (controller jobWorkerController) Pause() {
+	controller.pause.pause()
+}
+
+func (controller jobWorkerController) Resume() {
+	controller.pause.resume()
+}
+
+func (controller jobWorkerController) Paused() bool {
+	return controller.pause.isPaused()
+}
+
+func (controller jobWorkerController) stopPolling() {
+	controller.stopPoller.Do(func() {
+		close(controller.closePoller)
+	})
+}
+
+func (controller jobWorkerController) stopDispatching() {
+	controller.stopDispatcher.Do(func() {
+		close(controller.closeDispatcher)
+		controller.cancelHandlers()
+	})
 }
 
 func (controller jobWorkerController) AwaitClose() {
@@ -15,7 +15,61 @@
 
 package worker
 
+import "time"
+
 type JobWorkerMetrics interface {
 	// Set the remaining count of scheduled jobs for a specific job
 	SetJobsRemainingCount(jobType string, count int)
 }
+
+// JobActivationMetrics can additionally be implemented by a JobWorkerMetrics to observe the activation of jobs
+type JobActivationMetrics interface {
+	// Increment the count of jobs activated for a specific job type
+	IncrementJobsActivatedCount(jobType string, count int)
+	// Increment the count of failed activation requests for a specific job type
+	IncrementActivationFailuresCount(jobType string)
+}
+
+// JobPollMetrics can additionally be implemented by a JobWorkerMetrics to observe how many of the requested jobs
+// were activated by a poll, e.g. to estimate the backlog of a job type: polls which activate as many jobs as requested
+// indicate that more jobs are available
+type JobPollMetrics interface {
+	// Observe the number of jobs requested and activated by a poll for a specific job type
+	ObserveJobPoll(jobType string, requested, activated int)
+}
+
+// JobPollSaturationMetrics can additionally be implemented by a JobWorkerMetrics to count the successful polls which
+// activated exactly as many jobs as requested, which indicates a backlog of the job type, and the polls which were
+// truncated, as fewer jobs were available
+type JobPollSaturationMetrics interface {
+	// Increment the count of polls which activated as many jobs as requested for a specific job type
+	IncrementSaturatedPollsCount(jobType string)
+	// Increment the count of polls which activated fewer jobs than requested for a specific job type
+	IncrementTruncatedPollsCount(jobType string)
+}
+
+// JobPollBackoffMetrics can additionally be implemented by a JobWorkerMetrics to observe how long the poller waits
+// before it polls again after a failed activation
+type JobPollBackoffMetrics interface {
+	// Observe the backoff of the poller after a failed activation for a specific job type
+	ObservePollBackoff(jobType string, backoff time.Duration)
+}
+
+// JobPauseMetrics can additionally be implemented by a JobWorkerMetrics to observe when a worker is paused and resumed
+type JobPauseMetrics interface {
+	// Set whether the worker of a specific job type is paused
+	SetJobWorkerPaused(jobType string, paused bool)
+}
+
+// JobHandlerMetrics can additionally be implemented by a JobWorkerMetrics to observe the execution of job handlers
+type JobHandlerMetrics interface {
+	// Observe how long the handler took to process a job of a specific job type
+	ObserveJobHandlerDuration(jobType string, duration time.Duration)
+}
+
+// JobRouteMetrics observes the jobs dispatched by a Mux to its routes
+type JobRouteMetrics interface {
+	// Observe how long the handler of a route took to process a job of a specific job type. Jobs without a registered
+	// route are observed with the route they were routed by, and handled by the fallback handler of the Mux.
+	ObserveJobRouteDuration(jobType, route string, duration time.Duration)
+}
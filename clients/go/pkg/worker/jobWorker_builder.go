@@ -16,10 +16,14 @@
 package worker
 
 import (
+	"context"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/jsonpath"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/jsonschema"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
-	"log"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/secrets"
 	"math"
 	"sync"
 	"time"
@@ -40,12 +44,33 @@ type JobWorkerBuilder struct {
 	request        pb.ActivateJobsRequest
 	requestTimeout time.Duration
 
-	handler       JobHandler
-	maxJobsActive int
-	concurrency   int
-	pollInterval  time.Duration
-	pollThreshold float64
-	metrics       JobWorkerMetrics
+	handler        JobHandler
+	contextHandler ContextJobHandler
+	failures       *jobFailureHandling
+	maxJobsActive  int
+	concurrency    int
+	pollInterval   time.Duration
+	pollThreshold  float64
+	metrics        JobWorkerMetrics
+	codec          entities.VariableCodec
+	logger         logging.Logger
+
+	releaseOnDrain bool
+	adaptive       bool
+	sized          bool
+	orderingKey    func(entities.Job) string
+	filter         JobFilter
+	keepFiltered   bool
+	deduplication  DeduplicationStore
+	inputSchema    *jsonschema.Schema
+	projections    []variableProjection
+	pauseSignals   <-chan bool
+	secrets        secrets.Provider
+	pollStateStore PollStateStore
+
+	starvationTimeout time.Duration
+	pendingJobs       PendingJobsFunc
+	starvationHandler StarvationHandler
 }
 
 type JobWorkerBuilderStep1 interface {
@@ -57,6 +82,13 @@ type JobWorkerBuilderStep2 interface {
 	// Set the handler to process jobs. The worker should complete or fail the job. The handler implementation
 	// must be thread-safe.
 	Handler(JobHandler) JobWorkerBuilderStep3
+	// Set the handler to process jobs, which returns an error if it failed to process a job. The error is passed to
+	// the FailureHandler of the worker. The handler implementation must be thread-safe.
+	FallibleHandler(FallibleJobHandler) JobWorkerBuilderStep3
+	// Set the handler to process jobs, which gets a context that is done when the job deadline passes or the worker
+	// is closed. Returned errors are passed to the FailureHandler of the worker. The handler implementation must be
+	// thread-safe.
+	ContextHandler(ContextJobHandler) JobWorkerBuilderStep3
 }
 
 type JobWorkerBuilderStep3 interface {
@@ -75,10 +107,64 @@ type JobWorkerBuilderStep3 interface {
 	PollInterval(time.Duration) JobWorkerBuilderStep3
 	// Set the threshold of buffered activated jobs before polling for new jobs, i.e. threshold * MaxJobsActive(int)
 	PollThreshold(float64) JobWorkerBuilderStep3
-	// Set list of variable names which should be fetched on job activation
+	// Set list of variable names which should be fetched on job activation. By default all variables visible in the
+	// scope of the job are fetched, which can be large
 	FetchVariables(...string) JobWorkerBuilderStep3
 	// Set implementation for metrics reporting
 	Metrics(metrics JobWorkerMetrics) JobWorkerBuilderStep3
+	// Set the handler which decides how a job is failed after a FallibleJobHandler returned an error
+	FailureHandler(FailureHandler) JobWorkerBuilderStep3
+	// Set the handler which is called instead of failing a job for which no retries are left
+	DeadLetterHandler(DeadLetterHandler) JobWorkerBuilderStep3
+	// Set the number of retries which are decremented when the handler panics. The panic is recovered and the job is
+	// failed with the panic and its stack trace as error message
+	PanicRetryDecrement(int32) JobWorkerBuilderStep3
+	// Set whether jobs which were not passed to their handler when the context of Drain is done are failed with
+	// unchanged retries, so they can be activated again by other workers. Jobs whose handler is still running are left
+	// to finish or time out
+	ReleaseJobsOnDrain(bool) JobWorkerBuilderStep3
+	// Adapt the number of jobs activated at the same time to the backpressure of the gateway: it is halved whenever
+	// the gateway rejects an activation as resource exhausted and grows again gradually, up to MaxJobsActive(int)
+	AdaptiveConcurrency() JobWorkerBuilderStep3
+	// Size every activation by the average duration of the handler: no more jobs are activated than the handlers can
+	// complete within half of the job timeout, and the long polling ends when the active jobs are expected to be
+	// completed, within RequestTimeout(time.Duration). This keeps slow handlers from holding jobs which time out
+	ThroughputSizing() JobWorkerBuilderStep3
+	// Set the logger of the worker, instead of the logger of the client
+	Logger(logging.Logger) JobWorkerBuilderStep3
+	// Handle jobs with the same ordering key, e.g. the id of the same order, one after another in the order they were
+	// activated, while jobs with different keys are handled concurrently. Jobs with an empty key are not ordered
+	OrderingKey(func(entities.Job) string) JobWorkerBuilderStep3
+	// Set the filter which is evaluated before the handler. Jobs rejected by it are failed with unchanged retries, so
+	// other workers can activate them, e.g. to route jobs by region with custom headers
+	JobFilter(JobFilter) JobWorkerBuilderStep3
+	// Set whether jobs rejected by the JobFilter are released by failing them with unchanged retries, which is the
+	// default, or left to time out, so they are activated again only after the job timeout
+	ReleaseFilteredJobs(bool) JobWorkerBuilderStep3
+	// Set the store which records the jobs processed by the handler, so jobs which are activated again with the same
+	// retries, e.g. because completing them failed, are completed without invoking the handler again
+	Deduplication(DeduplicationStore) JobWorkerBuilderStep3
+	// Set the JSON Schema which the variables of activated jobs are validated against before the handler is invoked.
+	// Jobs with invalid variables are failed without retries, with the paths of the violations as error message
+	InputSchema(*jsonschema.Schema) JobWorkerBuilderStep3
+	// Call the handler when the worker polled successfully, but activated no jobs for the timeout, while the function
+	// reports pending jobs of its job type, e.g. from a backlog metric, to detect misconfigured job types. If the
+	// handler is nil, a warning is logged
+	StarvationDetection(timeout time.Duration, pending PendingJobsFunc, handler StarvationHandler) JobWorkerBuilderStep3
+	// Extract the values selected by the JSONPath, e.g. '$.order.items[*].sku', from the variables of each job before
+	// the handler is invoked, so the handler can get them with job.GetProjection(name). If FetchVariables(...string) is
+	// set, the variable the path selects from is fetched as well
+	VariableProjection(name string, path string) JobWorkerBuilderStep3
+	// Pause the worker whenever true is received from the channel and resume it whenever false is received, like
+	// JobWorker.Pause and JobWorker.Resume, until the channel or the worker is closed
+	PauseControl(<-chan bool) JobWorkerBuilderStep3
+	// Replace secret placeholders like '{{secrets.API_KEY}}' in the custom headers and variables of each job with the
+	// secrets of the provider before the handler is invoked. Jobs whose secrets don't exist are failed without retries
+	SecretResolution(secrets.Provider) JobWorkerBuilderStep3
+	// Save the poll backoff, the time of the last successful poll and the limit of AdaptiveConcurrency to the store,
+	// and restore them when a worker with the same job type and name is opened, e.g. after a restart. With a store,
+	// the worker backs off exponentially, up to DefaultMaxPollBackoff, while activations fail
+	PollStatePersistence(PollStateStore) JobWorkerBuilderStep3
 	// Open the job worker and start polling and handling jobs
 	Open() JobWorker
 }
@@ -93,6 +179,17 @@ func (builder *JobWorkerBuilder) Handler(handler JobHandler) JobWorkerBuilderSte
 	return builder
 }
 
+func (builder *JobWorkerBuilder) FallibleHandler(handler FallibleJobHandler) JobWorkerBuilderStep3 {
+	builder.handler = builder.failureHandling().wrap(handler)
+	return builder
+}
+
+func (builder *JobWorkerBuilder) ContextHandler(handler ContextJobHandler) JobWorkerBuilderStep3 {
+	builder.contextHandler = handler
+	builder.handler = nil
+	return builder
+}
+
 func (builder *JobWorkerBuilder) Name(name string) JobWorkerBuilderStep3 {
 	builder.request.Worker = name
 	return builder
@@ -113,7 +210,7 @@ func (builder *JobWorkerBuilder) MaxJobsActive(maxJobsActive int) JobWorkerBuild
 	if maxJobsActive > 0 {
 		builder.maxJobsActive = maxJobsActive
 	} else {
-		log.Println("Ignoring invalid maximum", maxJobsActive, "which should be greater then for job worker and using instead", builder.maxJobsActive)
+		builder.getLogger().Warn("Ignoring invalid maximum jobs active for job worker, which should be greater than zero", "maxJobsActive", maxJobsActive, "using", builder.maxJobsActive)
 	}
 	return builder
 }
@@ -122,7 +219,7 @@ func (builder *JobWorkerBuilder) Concurrency(concurrency int) JobWorkerBuilderSt
 	if concurrency > 0 {
 		builder.concurrency = concurrency
 	} else {
-		log.Println("Ignoring invalid concurrency", concurrency, "which should be greater then zero for job worker and using instead", builder.concurrency)
+		builder.getLogger().Warn("Ignoring invalid concurrency for job worker, which should be greater than zero", "concurrency", concurrency, "using", builder.concurrency)
 	}
 	return builder
 }
@@ -136,7 +233,7 @@ func (builder *JobWorkerBuilder) PollThreshold(pollThreshold float64) JobWorkerB
 	if pollThreshold > 0 {
 		builder.pollThreshold = pollThreshold
 	} else {
-		log.Println("Ignoring invalid poll threshold", pollThreshold, "which should be greater then zero for job worker and using instead", builder.concurrency)
+		builder.getLogger().Warn("Ignoring invalid poll threshold for job worker, which should be greater than zero", "pollThreshold", pollThreshold, "using", builder.pollThreshold)
 	}
 	return builder
 }
@@ -151,20 +248,164 @@ func (builder *JobWorkerBuilder) Metrics(metrics JobWorkerMetrics) JobWorkerBuil
 	return builder
 }
 
+func (builder *JobWorkerBuilder) FailureHandler(handler FailureHandler) JobWorkerBuilderStep3 {
+	if handler != nil {
+		builder.failureHandling().decide = handler
+	} else {
+		builder.getLogger().Warn("Ignoring nil failure handler for job worker and using the default failure handler")
+	}
+	return builder
+}
+
+func (builder *JobWorkerBuilder) DeadLetterHandler(handler DeadLetterHandler) JobWorkerBuilderStep3 {
+	builder.failureHandling().deadLetter = handler
+	return builder
+}
+
+func (builder *JobWorkerBuilder) PanicRetryDecrement(decrement int32) JobWorkerBuilderStep3 {
+	if decrement >= 0 {
+		builder.failureHandling().panicRetryDecrement = decrement
+	} else {
+		builder.getLogger().Warn("Ignoring invalid panic retry decrement for job worker, which should not be negative", "panicRetryDecrement", decrement, "using", builder.failureHandling().panicRetryDecrement)
+	}
+	return builder
+}
+
+func (builder *JobWorkerBuilder) ReleaseJobsOnDrain(release bool) JobWorkerBuilderStep3 {
+	builder.releaseOnDrain = release
+	return builder
+}
+
+func (builder *JobWorkerBuilder) AdaptiveConcurrency() JobWorkerBuilderStep3 {
+	builder.adaptive = true
+	return builder
+}
+
+func (builder *JobWorkerBuilder) ThroughputSizing() JobWorkerBuilderStep3 {
+	builder.sized = true
+	return builder
+}
+
+func (builder *JobWorkerBuilder) Logger(logger logging.Logger) JobWorkerBuilderStep3 {
+	if logger != nil {
+		builder.logger = logger
+	}
+	return builder
+}
+
+func (builder *JobWorkerBuilder) OrderingKey(orderingKey func(entities.Job) string) JobWorkerBuilderStep3 {
+	builder.orderingKey = orderingKey
+	return builder
+}
+
+func (builder *JobWorkerBuilder) JobFilter(filter JobFilter) JobWorkerBuilderStep3 {
+	builder.filter = filter
+	return builder
+}
+
+func (builder *JobWorkerBuilder) ReleaseFilteredJobs(release bool) JobWorkerBuilderStep3 {
+	builder.keepFiltered = !release
+	return builder
+}
+
+func (builder *JobWorkerBuilder) Deduplication(store DeduplicationStore) JobWorkerBuilderStep3 {
+	builder.deduplication = store
+	return builder
+}
+
+func (builder *JobWorkerBuilder) InputSchema(schema *jsonschema.Schema) JobWorkerBuilderStep3 {
+	builder.inputSchema = schema
+	return builder
+}
+
+func (builder *JobWorkerBuilder) StarvationDetection(timeout time.Duration, pending PendingJobsFunc, handler StarvationHandler) JobWorkerBuilderStep3 {
+	builder.starvationTimeout = timeout
+	builder.pendingJobs = pending
+	builder.starvationHandler = handler
+	return builder
+}
+
+func (builder *JobWorkerBuilder) VariableProjection(name string, path string) JobWorkerBuilderStep3 {
+	compiled, err := jsonpath.Compile(path)
+	if err != nil {
+		builder.getLogger().Warn("Ignoring invalid variable projection for job worker", "name", name, "error", err)
+		return builder
+	}
+
+	builder.projections = append(builder.projections, variableProjection{name: name, path: compiled})
+	return builder
+}
+
+func (builder *JobWorkerBuilder) PauseControl(control <-chan bool) JobWorkerBuilderStep3 {
+	builder.pauseSignals = control
+	return builder
+}
+
+func (builder *JobWorkerBuilder) SecretResolution(provider secrets.Provider) JobWorkerBuilderStep3 {
+	builder.secrets = provider
+	return builder
+}
+
+func (builder *JobWorkerBuilder) PollStatePersistence(store PollStateStore) JobWorkerBuilderStep3 {
+	builder.pollStateStore = store
+	return builder
+}
+
+func (builder *JobWorkerBuilder) failureHandling() *jobFailureHandling {
+	if builder.failures == nil {
+		builder.failures = &jobFailureHandling{decide: DefaultFailureHandler, requestTimeout: DefaultRequestTimeout, logger: builder.getLogger(), panicRetryDecrement: DefaultPanicRetryDecrement}
+	}
+	return builder.failures
+}
+
+func (builder *JobWorkerBuilder) getLogger() logging.Logger {
+	if builder.logger == nil {
+		return logging.Default
+	}
+	return builder.logger
+}
+
 func (builder *JobWorkerBuilder) Open() JobWorker {
 	jobQueue := make(chan entities.Job, builder.maxJobsActive)
 	workerFinished := make(chan bool, builder.maxJobsActive)
 	closePoller := make(chan struct{})
 	closeDispatcher := make(chan struct{})
+	pollerClosed := make(chan struct{})
+	activeJobs := newActiveJobs()
+	pause := newPauseControl(builder.request.Type, builder.metrics)
+	handlerCtx, cancelHandlers := context.WithCancel(context.Background())
+	handler := builder.handler
+	if builder.contextHandler != nil {
+		handler = builder.failureHandling().wrap(withJobContext(handlerCtx, builder.contextHandler))
+	}
+	logger := builder.getLogger()
+	builder.failureHandling().logger = logger
+	handler = builder.failureHandling().recoverPanics(handler)
+	if builder.deduplication != nil {
+		handler = deduplicateJobs(builder.deduplication, DefaultRequestTimeout, logger, handler)
+	}
+	if len(builder.projections) > 0 {
+		handler = projectVariables(builder.projections, DefaultRequestTimeout, logger, handler)
+	}
+	if builder.secrets != nil {
+		handler = resolveSecrets(builder.secrets, DefaultRequestTimeout, logger, handler)
+	}
+	if builder.inputSchema != nil {
+		handler = validateInput(builder.inputSchema, DefaultRequestTimeout, logger, handler)
+	}
+	if builder.filter != nil {
+		handler = filterJobs(builder.filter, !builder.keepFiltered, DefaultRequestTimeout, logger, handler)
+	}
 	var closeWait sync.WaitGroup
 	closeWait.Add(2)
 
 	poller := jobPoller{
-		client:         builder.gatewayClient,
-		maxJobsActive:  builder.maxJobsActive,
-		pollInterval:   builder.pollInterval,
-		request:        builder.request,
-		requestTimeout: builder.requestTimeout,
+		client:          builder.gatewayClient,
+		maxJobsActive:   builder.maxJobsActive,
+		pollInterval:    builder.pollInterval,
+		request:         builder.request,
+		requestTimeout:  builder.requestTimeout,
+		longPollTimeout: time.Duration(builder.request.RequestTimeout) * time.Millisecond,
 
 		jobQueue:       jobQueue,
 		workerFinished: workerFinished,
@@ -172,26 +413,85 @@ func (builder *JobWorkerBuilder) Open() JobWorker {
 		remaining:      0,
 		threshold:      int(math.Round(float64(builder.maxJobsActive) * builder.pollThreshold)),
 		metrics:        builder.metrics,
+		codec:          builder.codec,
+		activeJobs:     activeJobs,
+		pause:          pause,
+		logger:         logger,
+	}
+	if len(builder.projections) > 0 {
+		poller.request.FetchVariable = fetchProjectedVariables(builder.request.FetchVariable, builder.projections)
+	}
+	if builder.adaptive {
+		poller.adaptiveLimit = newAdaptiveLimit(builder.maxJobsActive)
+	}
+	var sizing *activationSizing
+	if builder.sized {
+		jobTimeout := time.Duration(builder.request.Timeout) * time.Millisecond
+		requestTimeout := time.Duration(builder.request.RequestTimeout) * time.Millisecond
+		sizing = newActivationSizing(builder.concurrency, jobTimeout, requestTimeout)
+		poller.sizing = sizing
+	}
+	if builder.pollStateStore != nil {
+		poller.pollState = newPollState(builder.pollStateStore, builder.request.Type, builder.request.Worker, logger)
+	}
+	if builder.pendingJobs != nil {
+		poller.starvation = newStarvationDetection(builder.starvationTimeout, builder.pendingJobs, builder.starvationHandler, logger)
 	}
 
 	dispatcher := jobDispatcher{
 		jobQueue:       jobQueue,
 		workerFinished: workerFinished,
 		closeSignal:    closeDispatcher,
+		metrics:        builder.metrics,
+		activeJobs:     activeJobs,
+		orderingKey:    builder.orderingKey,
+		sizing:         sizing,
 	}
 
-	go poller.poll(&closeWait)
-	go dispatcher.run(builder.jobClient, builder.handler, builder.concurrency, &closeWait)
+	go func() {
+		poller.poll(&closeWait)
+		close(pollerClosed)
+	}()
+	go dispatcher.run(builder.jobClient, handler, builder.concurrency, &closeWait)
+	if builder.pauseSignals != nil {
+		go pause.follow(builder.pauseSignals, closePoller)
+	}
 
 	return jobWorkerController{
 		closePoller:     closePoller,
 		closeDispatcher: closeDispatcher,
+		pollerClosed:    pollerClosed,
 		closeWait:       &closeWait,
+
+		stopPoller:     &sync.Once{},
+		stopDispatcher: &sync.Once{},
+		activeJobs:     activeJobs,
+		jobClient:      builder.jobClient,
+		releaseOnDrain: builder.releaseOnDrain,
+		failures:       builder.failureHandling(),
+		requestTimeout: DefaultRequestTimeout,
+		logger:         logger,
+		cancelHandlers: cancelHandlers,
+		pause:          pause,
 	}
 }
 
 func NewJobWorkerBuilder(gatewayClient pb.GatewayClient, jobClient JobClient) JobWorkerBuilderStep1 {
+	return NewJobWorkerBuilderWithCodec(gatewayClient, jobClient, entities.JSONCodec)
+}
+
+// NewJobWorkerBuilderWithCodec creates a builder of job workers which decode the variables of activated jobs with the
+// codec. If the job client has a method 'Logger() logging.Logger', like the client of the zbc package, the workers log
+// with its logger by default.
+func NewJobWorkerBuilderWithCodec(gatewayClient pb.GatewayClient, jobClient JobClient, codec entities.VariableCodec) JobWorkerBuilderStep1 {
+	logger := logging.Default
+	if provider, ok := jobClient.(loggerProvider); ok {
+		logger = provider.Logger()
+	}
+
 	return &JobWorkerBuilder{
+		logger:        logger,
+		codec:         codec,
 		gatewayClient: gatewayClient,
 		jobClient:     jobClient,
 		maxJobsActive: DefaultJobWorkerMaxJobActive,
@@ -206,3 +506,7 @@ func NewJobWorkerBuilder(gatewayClient pb.GatewayClient, jobClient JobClient) Jo
 		requestTimeout: DefaultRequestTimeout + RequestTimeoutOffset,
 	}
 }
+
+type loggerProvider interface {
+	Logger() logging.Logger
+}
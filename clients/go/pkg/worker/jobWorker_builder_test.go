@@ -20,6 +20,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
 	"testing"
 	"time"
 )
@@ -102,3 +103,49 @@ func TestJobWorkerBuilder_Metrics(t *testing.T) {
 
 	assert.Equal(t, workerMetrics, builder.metrics)
 }
+
+func TestJobWorkerBuilder_AdaptiveConcurrency(t *testing.T) {
+	builder := JobWorkerBuilder{}
+	builder.AdaptiveConcurrency()
+	assert.True(t, builder.adaptive)
+}
+
+type loggingJobClient struct {
+	JobClient
+	logger logging.Logger
+}
+
+func (c loggingJobClient) Logger() logging.Logger {
+	return c.logger
+}
+
+func TestJobWorkerBuilder_Logger(t *testing.T) {
+	builder := JobWorkerBuilder{}
+	builder.Logger(logging.Discard)
+	assert.Equal(t, logging.Discard, builder.logger)
+
+	// should ignore nil logger
+	builder.Logger(nil)
+	assert.Equal(t, logging.Discard, builder.logger)
+}
+
+func TestJobWorkerBuilder_LoggerOfJobClient(t *testing.T) {
+	builder := NewJobWorkerBuilder(nil, loggingJobClient{logger: logging.Discard}).(*JobWorkerBuilder)
+	assert.Equal(t, logging.Discard, builder.logger)
+}
+
+func TestJobWorkerBuilder_PanicRetryDecrement(t *testing.T) {
+	builder := JobWorkerBuilder{}
+	builder.PanicRetryDecrement(0)
+	assert.Equal(t, int32(0), builder.failures.panicRetryDecrement)
+
+	// should ignore invalid decrement
+	builder.PanicRetryDecrement(-1)
+	assert.Equal(t, int32(0), builder.failures.panicRetryDecrement)
+}
+
+func TestJobWorkerBuilder_OrderingKey(t *testing.T) {
+	builder := JobWorkerBuilder{}
+	builder.OrderingKey(func(job entities.Job) string { return job.Type })
+	assert.NotNil(t, builder.orderingKey)
+}
@@ -0,0 +1,121 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+)
+
+const unroutedJobMessage = "no handler is registered for the route of the job"
+
+type routeFunc func(job *entities.Job) (string, error)
+
+// Mux dispatches the jobs of a single job type to the handlers registered for the value of a custom header or
+// variable of the job, so one worker can replace many workers for near-identical job types. Jobs without a registered
+// route are passed to the fallback handler, which fails them with decremented retries by default.
+//
+// The routes must be registered before the HandleJob method is passed as handler to a job worker.
+type Mux struct {
+	route    routeFunc
+	routes   map[string]JobHandler
+	fallback JobHandler
+	metrics  JobRouteMetrics
+}
+
+// NewHeaderMux creates a Mux which routes jobs by the value of the given custom header.
+func NewHeaderMux(header string) *Mux {
+	return newMux(func(job *entities.Job) (string, error) {
+		headers, err := job.GetCustomHeadersAsMap()
+		if err != nil {
+			return "", err
+		}
+		return headers[header], nil
+	})
+}
+
+// NewVariableMux creates a Mux which routes jobs by the value of the given variable, formatted with fmt.Sprint if it
+// is not a string. If the worker fetches only some variables, the variable has to be fetched too.
+func NewVariableMux(variable string) *Mux {
+	return newMux(func(job *entities.Job) (string, error) {
+		variables, err := job.GetVariablesAsMap()
+		if err != nil {
+			return "", err
+		}
+		switch value := variables[variable].(type) {
+		case nil:
+			return "", nil
+		case string:
+			return value, nil
+		default:
+			return fmt.Sprint(value), nil
+		}
+	})
+}
+
+func newMux(route routeFunc) *Mux {
+	return &Mux{route: route, routes: make(map[string]JobHandler), fallback: failUnroutedJob}
+}
+
+// Handle registers the handler for jobs routed by the given value, replacing a previously registered handler.
+func (m *Mux) Handle(route string, handler JobHandler) *Mux {
+	m.routes[route] = handler
+	return m
+}
+
+// Fallback sets the handler for jobs without a registered route, including jobs which could not be routed because
+// their custom headers or variables could not be decoded.
+func (m *Mux) Fallback(handler JobHandler) *Mux {
+	m.fallback = handler
+	return m
+}
+
+// Metrics sets the metrics which observe the jobs handled per route.
+func (m *Mux) Metrics(metrics JobRouteMetrics) *Mux {
+	m.metrics = metrics
+	return m
+}
+
+// HandleJob dispatches the job to the handler of its route, and can be passed as JobHandler to a job worker.
+func (m *Mux) HandleJob(client JobClient, job entities.Job) {
+	route, err := m.route(&job)
+	handler, ok := m.routes[route]
+	if err != nil || !ok {
+		handler = m.fallback
+	}
+
+	if m.metrics == nil {
+		handler(client, job)
+		return
+	}
+
+	start := time.Now()
+	handler(client, job)
+	m.metrics.ObserveJobRouteDuration(job.Type, route, time.Since(start))
+}
+
+func failUnroutedJob(client JobClient, job entities.Job) {
+	retries := job.Retries - 1
+	if retries < 0 {
+		retries = 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultRequestTimeout)
+	defer cancel()
+	_, _ = client.NewFailJobCommand().JobKey(job.Key).Retries(retries).ErrorMessage(unroutedJobMessage).Send(ctx)
+}
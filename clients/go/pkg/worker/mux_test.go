@@ -0,0 +1,89 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type routeMetrics struct {
+	sync.Mutex
+	routes []string
+}
+
+func (m *routeMetrics) ObserveJobRouteDuration(_, route string, _ time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.routes = append(m.routes, route)
+}
+
+func TestHeaderMuxDispatchesByCustomHeader(t *testing.T) {
+	var handled []string
+	metrics := &routeMetrics{}
+	mux := NewHeaderMux("action").
+		Handle("create", func(JobClient, entities.Job) { handled = append(handled, "create") }).
+		Handle("delete", func(JobClient, entities.Job) { handled = append(handled, "delete") }).
+		Metrics(metrics)
+
+	mux.HandleJob(nil, entities.Job{ActivatedJob: pb.ActivatedJob{Type: "crud", CustomHeaders: `{"action":"delete"}`}})
+	mux.HandleJob(nil, entities.Job{ActivatedJob: pb.ActivatedJob{Type: "crud", CustomHeaders: `{"action":"create"}`}})
+
+	assert.Equal(t, []string{"delete", "create"}, handled)
+	assert.Equal(t, []string{"delete", "create"}, metrics.routes)
+}
+
+func TestVariableMuxDispatchesByVariable(t *testing.T) {
+	var handled []string
+	mux := NewVariableMux("version").
+		Handle("2", func(JobClient, entities.Job) { handled = append(handled, "v2") }).
+		Handle("beta", func(JobClient, entities.Job) { handled = append(handled, "beta") })
+
+	mux.HandleJob(nil, entities.Job{ActivatedJob: pb.ActivatedJob{Variables: `{"version":2}`}})
+	mux.HandleJob(nil, entities.Job{ActivatedJob: pb.ActivatedJob{Variables: `{"version":"beta"}`}})
+
+	assert.Equal(t, []string{"v2", "beta"}, handled)
+}
+
+func TestMuxPassesUnroutedJobsToFallback(t *testing.T) {
+	var fallback []int64
+	mux := NewHeaderMux("action").
+		Handle("create", func(JobClient, entities.Job) { t.Error("expected job not to be routed") }).
+		Fallback(func(_ JobClient, job entities.Job) { fallback = append(fallback, job.Key) })
+
+	mux.HandleJob(nil, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 1, CustomHeaders: `{"action":"update"}`}})
+	mux.HandleJob(nil, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 2}})
+	mux.HandleJob(nil, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 3, CustomHeaders: `not json`}})
+
+	assert.Equal(t, []int64{1, 2, 3}, fallback)
+}
+
+func TestMuxFailsUnroutedJobsByDefault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	request := &pb.FailJobRequest{JobKey: 1, Retries: 2, ErrorMessage: unroutedJobMessage}
+	client.EXPECT().FailJob(gomock.Any(), &rpcMsg{msg: request}).Return(&pb.FailJobResponse{}, nil)
+
+	NewHeaderMux("action").HandleJob(gatewayJobClient{client}, entities.Job{ActivatedJob: pb.ActivatedJob{Key: 1, Retries: 3}})
+}
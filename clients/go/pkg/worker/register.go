@@ -0,0 +1,210 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RegisterTag is the struct tag used by Register to configure a job worker.
+const RegisterTag = "zeebe"
+
+// WorkerClient creates job workers, e.g. a zbc.Client.
+type WorkerClient interface {
+	NewJobWorker() JobWorkerBuilderStep1
+}
+
+type workerSpec struct {
+	field          string
+	handler        JobHandler
+	jobType        string
+	name           string
+	timeout        time.Duration
+	requestTimeout time.Duration
+	maxJobsActive  int
+	concurrency    int
+	pollInterval   time.Duration
+	pollThreshold  float64
+	fetchVariables []string
+}
+
+// Register opens a job worker for every field of the given struct, or pointer to struct, which is tagged with
+// 'zeebe'. The field must be a JobHandler, or a function with the same signature, and hold the handler of the worker.
+// The tag starts with the job type, optionally followed by comma separated options:
+//
+//	type Handlers struct {
+//		ChargeCard worker.JobHandler `zeebe:"charge-card,maxJobsActive=10,timeout=30s,fetchVariables=orderId;amount"`
+//		ShipOrder  worker.JobHandler `zeebe:"ship-order,concurrency=2"`
+//	}
+//
+// The supported options are name, timeout, requestTimeout, maxJobsActive, concurrency, pollInterval, pollThreshold
+// and fetchVariables (separated by ';'). Durations use the format of time.ParseDuration; durations and numbers must be
+// positive. Fields without the tag are ignored.
+//
+// If any field is invalid, no worker is opened and an error is returned.
+func Register(client WorkerClient, handlers interface{}) ([]JobWorker, error) {
+	specs, err := parseWorkerSpecs(handlers)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := make([]JobWorker, 0, len(specs))
+	for _, spec := range specs {
+		workers = append(workers, spec.open(client))
+	}
+
+	return workers, nil
+}
+
+func parseWorkerSpecs(handlers interface{}) ([]workerSpec, error) {
+	value := reflect.ValueOf(handlers)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected handlers to be a struct or pointer to struct, but got %T", handlers)
+	}
+
+	handlerType := reflect.TypeOf(JobHandler(nil))
+	var specs []workerSpec
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag, ok := field.Tag.Lookup(RegisterTag)
+		if !ok {
+			continue
+		}
+
+		if !field.Type.ConvertibleTo(handlerType) {
+			return nil, fmt.Errorf("expected field %s to be a worker.JobHandler, but got %s", field.Name, field.Type)
+		}
+		if field.PkgPath != "" {
+			return nil, fmt.Errorf("expected field %s to be exported", field.Name)
+		}
+
+		fieldValue := value.Field(i)
+		if fieldValue.IsNil() {
+			return nil, fmt.Errorf("expected field %s to have a handler, but it is nil", field.Name)
+		}
+
+		spec, err := parseWorkerTag(field.Name, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		spec.handler = fieldValue.Convert(handlerType).Interface().(JobHandler)
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
+func parseWorkerTag(field, tag string) (workerSpec, error) {
+	parts := strings.Split(tag, ",")
+	spec := workerSpec{field: field, jobType: strings.TrimSpace(parts[0])}
+	if spec.jobType == "" {
+		return spec, fmt.Errorf("expected tag of field %s to start with a job type, but got %q", field, tag)
+	}
+
+	for _, option := range parts[1:] {
+		keyValue := strings.SplitN(option, "=", 2)
+		if len(keyValue) != 2 {
+			return spec, fmt.Errorf("expected option %q of field %s to have the format key=value", option, field)
+		}
+
+		key, value := strings.TrimSpace(keyValue[0]), strings.TrimSpace(keyValue[1])
+		var err error
+		switch key {
+		case "name":
+			spec.name = value
+		case "timeout":
+			spec.timeout, err = parsePositiveDuration(value)
+		case "requestTimeout":
+			spec.requestTimeout, err = parsePositiveDuration(value)
+		case "maxJobsActive":
+			spec.maxJobsActive, err = parsePositiveInt(value)
+		case "concurrency":
+			spec.concurrency, err = parsePositiveInt(value)
+		case "pollInterval":
+			spec.pollInterval, err = parsePositiveDuration(value)
+		case "pollThreshold":
+			spec.pollThreshold, err = strconv.ParseFloat(value, 64)
+			if err == nil && spec.pollThreshold <= 0 {
+				err = fmt.Errorf("expected a positive number, but got %v", spec.pollThreshold)
+			}
+		case "fetchVariables":
+			spec.fetchVariables = strings.Split(value, ";")
+		default:
+			err = fmt.Errorf("unknown option")
+		}
+
+		if err != nil {
+			return spec, fmt.Errorf("invalid option %q of field %s: %w", option, field, err)
+		}
+	}
+
+	return spec, nil
+}
+
+func parsePositiveDuration(value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err == nil && duration <= 0 {
+		err = fmt.Errorf("expected a positive duration, but got %v", duration)
+	}
+	return duration, err
+}
+
+func parsePositiveInt(value string) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err == nil && number <= 0 {
+		err = fmt.Errorf("expected a positive number, but got %d", number)
+	}
+	return number, err
+}
+
+func (spec workerSpec) open(client WorkerClient) JobWorker {
+	builder := client.NewJobWorker().JobType(spec.jobType).Handler(spec.handler)
+
+	if spec.name != "" {
+		builder = builder.Name(spec.name)
+	}
+	if spec.timeout > 0 {
+		builder = builder.Timeout(spec.timeout)
+	}
+	if spec.requestTimeout > 0 {
+		builder = builder.RequestTimeout(spec.requestTimeout)
+	}
+	if spec.maxJobsActive > 0 {
+		builder = builder.MaxJobsActive(spec.maxJobsActive)
+	}
+	if spec.concurrency > 0 {
+		builder = builder.Concurrency(spec.concurrency)
+	}
+	if spec.pollInterval > 0 {
+		builder = builder.PollInterval(spec.pollInterval)
+	}
+	if spec.pollThreshold > 0 {
+		builder = builder.PollThreshold(spec.pollThreshold)
+	}
+	if len(spec.fetchVariables) > 0 {
+		builder = builder.FetchVariables(spec.fetchVariables...)
+	}
+
+	return builder.Open()
+}
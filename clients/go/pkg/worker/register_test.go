@@ -0,0 +1,129 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebe-io/zeebe/clients/go/internal/mock_pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type registeredHandlers struct {
+	Charge  JobHandler                    `zeebe:"charge-card,maxJobsActive=10,timeout=30s,fetchVariables=orderId;amount"`
+	Ship    func(JobClient, entities.Job) `zeebe:"ship-order,name=shipper"`
+	Ignored JobHandler
+}
+
+type workerClientStub struct {
+	gateway pb.GatewayClient
+}
+
+func (c workerClientStub) NewJobWorker() JobWorkerBuilderStep1 {
+	return NewJobWorkerBuilder(c.gateway, nil)
+}
+
+func TestRegisterOpensWorkerPerTaggedField(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	stream := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+	stream.EXPECT().Recv().Return(nil, io.EOF).AnyTimes()
+
+	charged := &rpcMsg{msg: &pb.ActivateJobsRequest{
+		Type:              "charge-card",
+		Worker:            commands.DefaultJobWorkerName,
+		MaxJobsToActivate: 10,
+		Timeout:           (30 * time.Second).Milliseconds(),
+		RequestTimeout:    DefaultRequestTimeout.Milliseconds(),
+		FetchVariable:     []string{"orderId", "amount"},
+	}}
+	shipped := &rpcMsg{msg: &pb.ActivateJobsRequest{
+		Type:              "ship-order",
+		Worker:            "shipper",
+		MaxJobsToActivate: DefaultJobWorkerMaxJobActive,
+		Timeout:           commands.DefaultJobTimeoutInMs,
+		RequestTimeout:    DefaultRequestTimeout.Milliseconds(),
+	}}
+	client.EXPECT().ActivateJobs(gomock.Any(), charged).Return(stream, nil).MinTimes(1)
+	client.EXPECT().ActivateJobs(gomock.Any(), shipped).Return(stream, nil).MinTimes(1)
+
+	handler := func(JobClient, entities.Job) {}
+	workers, err := Register(workerClientStub{gateway: client}, &registeredHandlers{Charge: handler, Ship: handler, Ignored: handler})
+	if err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+
+	assert.Len(t, workers, 2)
+	time.Sleep(10 * time.Millisecond)
+	for _, worker := range workers {
+		worker.Close()
+	}
+}
+
+func TestRegisterRejectsInvalidHandlers(t *testing.T) {
+	handler := func(JobClient, entities.Job) {}
+
+	cases := map[string]interface{}{
+		"not a struct": handler,
+		"nil handler":  &registeredHandlers{Charge: handler},
+		"wrong type": &struct {
+			Handler func() `zeebe:"foo"`
+		}{Handler: func() {}},
+		"missing job type": &struct {
+			Handler JobHandler `zeebe:",timeout=1s"`
+		}{Handler: handler},
+		"unknown option": &struct {
+			Handler JobHandler `zeebe:"foo,retries=3"`
+		}{Handler: handler},
+		"invalid duration": &struct {
+			Handler JobHandler `zeebe:"foo,timeout=soon"`
+		}{Handler: handler},
+		"negative max jobs active": &struct {
+			Handler JobHandler `zeebe:"foo,maxJobsActive=-1"`
+		}{Handler: handler},
+		"zero timeout": &struct {
+			Handler JobHandler `zeebe:"foo,timeout=0s"`
+		}{Handler: handler},
+		"zero poll threshold": &struct {
+			Handler JobHandler `zeebe:"foo,pollThreshold=0"`
+		}{Handler: handler},
+	}
+
+	for name, handlers := range cases {
+		t.Run(name, func(t *testing.T) {
+			workers, err := Register(workerClientStub{}, handlers)
+			assert.Error(t, err)
+			assert.Empty(t, workers)
+		})
+	}
+}
+
+func TestRegisterNamesFieldOfNonPositiveOption(t *testing.T) {
+	handlers := &struct {
+		Charge JobHandler `zeebe:"charge-card,maxJobsActive=-1"`
+	}{Charge: func(JobClient, entities.Job) {}}
+
+	_, err := Register(workerClientStub{}, handlers)
+
+	assert.EqualError(t, err, `invalid option "maxJobsActive=-1" of field Charge: expected a positive number, but got -1`)
+}
@@ -0,0 +1,335 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package workerhost runs a set of job workers in production: it exposes their readiness and health over HTTP,
+// restarts workers whose pollers stalled, pauses and resumes them on demand and drains all workers when the process is asked to terminate.
+//
+//	host := workerhost.New(workerhost.Options{})
+//	host.Add("payments", func(monitor *workerhost.Monitor) worker.JobWorker {
+//		return client.NewJobWorker().JobType("payment").FallibleHandler(monitor.Fallible(handlePayment)).Metrics(monitor).Open()
+//	})
+//	go http.ListenAndServe(":8080", host.Handler())
+//	err := host.Run(context.Background())
+package workerhost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
+)
+
+const (
+	DefaultCheckInterval       = 10 * time.Second
+	DefaultStallTimeout        = 5 * time.Minute
+	DefaultMaxHandlerErrorRate = 0.5
+	DefaultMinHandledJobs      = 10
+	DefaultDrainTimeout        = 30 * time.Second
+)
+
+const (
+	StatusOK       = "ok"
+	StatusStarting = "starting"
+	StatusFailing  = "failing"
+	StatusStopped  = "stopped"
+	StatusPaused   = "paused"
+)
+
+// Options configure a Host. Zero values are replaced by the respective defaults.
+type Options struct {
+	// CheckInterval is the interval in which the workers are checked, and the window of the handler error rate
+	CheckInterval time.Duration
+	// StallTimeout is the time after which a worker which neither polled nor handled a job is restarted. It has to
+	// exceed the poll interval and the duration of the handlers of the workers
+	StallTimeout time.Duration
+	// MaxHandlerErrorRate is the fraction of jobs in a check interval whose handler returned an error, above which the
+	// worker is failing
+	MaxHandlerErrorRate float64
+	// MinHandledJobs is the number of jobs which have to be handled in a check interval to compute the error rate
+	MinHandledJobs int
+	// DrainTimeout is the time for which Run waits for the handlers of activated jobs when it is stopped
+	DrainTimeout time.Duration
+	// Logger logs restarts of workers, instead of the default logger
+	Logger logging.Logger
+}
+
+func (o Options) withDefaults() Options {
+	if o.CheckInterval <= 0 {
+		o.CheckInterval = DefaultCheckInterval
+	}
+	if o.StallTimeout <= 0 {
+		o.StallTimeout = DefaultStallTimeout
+	}
+	if o.MaxHandlerErrorRate <= 0 {
+		o.MaxHandlerErrorRate = DefaultMaxHandlerErrorRate
+	}
+	if o.MinHandledJobs <= 0 {
+		o.MinHandledJobs = DefaultMinHandledJobs
+	}
+	if o.DrainTimeout <= 0 {
+		o.DrainTimeout = DefaultDrainTimeout
+	}
+	if o.Logger == nil {
+		o.Logger = logging.Default
+	}
+	return o
+}
+
+// WorkerFactory opens a worker, which reports to the monitor by passing it to Metrics of the worker builder.
+type WorkerFactory func(monitor *Monitor) worker.JobWorker
+
+type hostedWorker struct {
+	name     string
+	open     WorkerFactory
+	monitor  *Monitor
+	worker   worker.JobWorker
+	status   string
+	restarts int
+	paused   bool
+}
+
+// Host owns a set of workers, which are opened by Run.
+type Host struct {
+	options Options
+
+	mutex    sync.Mutex
+	workers  []*hostedWorker
+	running  bool
+	draining bool
+}
+
+func New(options Options) *Host {
+	return &Host{options: options.withDefaults()}
+}
+
+// Add adds a worker which is opened by the factory when Run is called, or right away if the host is running, and again
+// whenever it is restarted.
+func (h *Host) Add(name string, open WorkerFactory) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	w := &hostedWorker{name: name, open: open, status: StatusStopped}
+	h.workers = append(h.workers, w)
+	if h.running && !h.draining {
+		h.openWorker(w)
+	}
+}
+
+// Run opens the workers and checks them until the context is done or the process receives SIGTERM or SIGINT. The
+// workers are drained then, and the error of draining them, if any, is returned.
+func (h *Host) Run(ctx context.Context) error {
+	h.start()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(signals)
+
+	ticker := time.NewTicker(h.options.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.check(time.Now())
+		case <-signals:
+			return h.drain()
+		case <-ctx.Done():
+			return h.drain()
+		}
+	}
+}
+
+func (h *Host) start() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.running = true
+	for _, w := range h.workers {
+		h.openWorker(w)
+	}
+}
+
+func (h *Host) openWorker(w *hostedWorker) {
+	w.monitor = newMonitor(time.Now())
+	w.worker = w.open(w.monitor)
+	w.status = StatusStarting
+	if w.paused {
+		w.worker.Pause()
+		w.monitor.setPaused(true)
+		w.status = StatusPaused
+	}
+}
+
+// Pause pauses the worker with the given name, see worker.JobWorker.Pause. It stays paused when it is restarted, and
+// is reported as paused instead of restarted while it doesn't poll.
+func (h *Host) Pause(name string) error {
+	return h.setPaused(name, true)
+}
+
+// Resume resumes the worker with the given name after Pause.
+func (h *Host) Resume(name string) error {
+	return h.setPaused(name, false)
+}
+
+func (h *Host) setPaused(name string, paused bool) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, w := range h.workers {
+		if w.name != name {
+			continue
+		}
+
+		w.paused = paused
+		if w.worker == nil {
+			return nil
+		}
+		if paused {
+			w.worker.Pause()
+			w.status = StatusPaused
+		} else {
+			w.worker.Resume()
+			w.status = StatusStarting
+		}
+		w.monitor.setPaused(paused)
+		return nil
+	}
+	return fmt.Errorf("expected to find worker %s, but it is not hosted", name)
+}
+
+func (h *Host) check(now time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.running || h.draining {
+		return
+	}
+
+	for _, w := range h.workers {
+		polled, lastActivity, handled, failed := w.monitor.window()
+		if w.monitor.isPaused() {
+			w.status = StatusPaused
+			continue
+		}
+		if now.Sub(lastActivity) > h.options.StallTimeout {
+			w.restarts++
+			h.options.Logger.Warn("Restarting worker which neither polled nor handled jobs", "worker", w.name, "since", lastActivity, "restarts", w.restarts)
+			// closing waits for the stalled poller, so the new worker is opened without waiting for it
+			go w.worker.Close()
+			h.openWorker(w)
+			continue
+		}
+
+		switch {
+		case handled >= h.options.MinHandledJobs && float64(failed)/float64(handled) > h.options.MaxHandlerErrorRate:
+			w.status = StatusFailing
+		case polled:
+			w.status = StatusOK
+		default:
+			w.status = StatusStarting
+		}
+	}
+}
+
+func (h *Host) drain() error {
+	h.mutex.Lock()
+	h.draining = true
+	workers := make([]worker.JobWorker, 0, len(h.workers))
+	for _, w := range h.workers {
+		w.status = StatusStopped
+		if w.worker != nil {
+			workers = append(workers, w.worker)
+		}
+	}
+	h.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.options.DrainTimeout)
+	defer cancel()
+
+	errs := make(chan error, len(workers))
+	for _, w := range workers {
+		go func(w worker.JobWorker) {
+			errs <- w.Drain(ctx)
+		}(w)
+	}
+
+	var err error
+	for range workers {
+		if drainErr := <-errs; drainErr != nil && err == nil {
+			err = drainErr
+		}
+	}
+
+	h.mutex.Lock()
+	h.running = false
+	h.mutex.Unlock()
+	return err
+}
+
+// Handler returns an HTTP handler which serves HealthHandler at /healthz and ReadyHandler at /readyz.
+func (h *Host) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", h.HealthHandler())
+	mux.Handle("/readyz", h.ReadyHandler())
+	return mux
+}
+
+// HealthHandler responds with 200 while no worker is failing, i.e. the error rate of its handler is at most
+// MaxHandlerErrorRate, and with 503 otherwise. Stalled workers are restarted instead of reported as unhealthy.
+func (h *Host) HealthHandler() http.Handler {
+	return h.statusHandler(func(status string) bool {
+		return status != StatusFailing
+	})
+}
+
+// ReadyHandler responds with 200 while the host is running and every worker polled jobs successfully or is paused,
+// and with 503 otherwise, e.g. while it is not connected to the gateway yet or drains the workers.
+func (h *Host) ReadyHandler() http.Handler {
+	return h.statusHandler(func(status string) bool {
+		return status == StatusOK || status == StatusFailing || status == StatusPaused
+	})
+}
+
+type statusResponse struct {
+	Status  string            `json:"status"`
+	Workers map[string]string `json:"workers"`
+}
+
+func (h *Host) statusHandler(ok func(status string) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		h.mutex.Lock()
+		response := statusResponse{Status: StatusOK, Workers: make(map[string]string, len(h.workers))}
+		for _, hosted := range h.workers {
+			response.Workers[hosted.name] = hosted.status
+			if !ok(hosted.status) {
+				response.Status = hosted.status
+			}
+		}
+		h.mutex.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if response.Status != StatusOK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	})
+}
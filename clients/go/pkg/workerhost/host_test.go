@@ -0,0 +1,198 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workerhost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
+)
+
+type fakeWorker struct {
+	closed  int32
+	drained int32
+	paused  int32
+}
+
+func (w *fakeWorker) Close() {
+	atomic.AddInt32(&w.closed, 1)
+}
+
+func (w *fakeWorker) AwaitClose() {}
+
+func (w *fakeWorker) Drain(context.Context) error {
+	atomic.AddInt32(&w.drained, 1)
+	return nil
+}
+
+func (w *fakeWorker) Pause() {
+	atomic.StoreInt32(&w.paused, 1)
+}
+
+func (w *fakeWorker) Resume() {
+	atomic.StoreInt32(&w.paused, 0)
+}
+
+func (w *fakeWorker) Paused() bool {
+	return atomic.LoadInt32(&w.paused) == 1
+}
+
+func statusCode(t *testing.T, handler http.Handler, path string) int {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder.Code
+}
+
+func TestHostIsReadyAfterSuccessfulPoll(t *testing.T) {
+	host := New(Options{})
+	var monitor *Monitor
+	host.Add("payments", func(m *Monitor) worker.JobWorker {
+		monitor = m
+		return &fakeWorker{}
+	})
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode(t, host.Handler(), "/readyz"))
+
+	host.start()
+	host.check(time.Now())
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode(t, host.Handler(), "/readyz"))
+
+	monitor.ObserveJobPoll("payment", 32, 0)
+	host.check(time.Now())
+	assert.Equal(t, http.StatusOK, statusCode(t, host.Handler(), "/readyz"))
+	assert.Equal(t, http.StatusOK, statusCode(t, host.Handler(), "/healthz"))
+}
+
+func TestHostIsUnhealthyIfHandlersFail(t *testing.T) {
+	host := New(Options{MinHandledJobs: 4})
+	var monitor *Monitor
+	host.Add("payments", func(m *Monitor) worker.JobWorker {
+		monitor = m
+		return &fakeWorker{}
+	})
+	host.start()
+
+	handler := monitor.Fallible(func(worker.JobClient, entities.Job) error {
+		return errors.New("payment declined")
+	})
+	for i := 0; i < 4; i++ {
+		_ = handler(nil, entities.Job{})
+		monitor.ObserveJobHandlerDuration("payment", time.Millisecond)
+	}
+	monitor.ObserveJobPoll("payment", 32, 4)
+	host.check(time.Now())
+
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode(t, host.Handler(), "/healthz"))
+	assert.Equal(t, http.StatusOK, statusCode(t, host.Handler(), "/readyz"))
+
+	// the error rate is computed per check interval
+	monitor.ObserveJobPoll("payment", 32, 0)
+	host.check(time.Now())
+	assert.Equal(t, http.StatusOK, statusCode(t, host.Handler(), "/healthz"))
+}
+
+func TestHostRestartsStalledWorker(t *testing.T) {
+	host := New(Options{StallTimeout: time.Minute})
+	var opened []*fakeWorker
+	host.Add("payments", func(*Monitor) worker.JobWorker {
+		w := &fakeWorker{}
+		opened = append(opened, w)
+		return w
+	})
+	host.start()
+
+	host.check(time.Now().Add(30 * time.Second))
+	require.Len(t, opened, 1)
+
+	host.check(time.Now().Add(2 * time.Minute))
+	require.Len(t, opened, 2)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&opened[0].closed) == 1
+	}, utils.DefaultTestTimeout, time.Millisecond)
+}
+
+func TestHostDoesNotRestartPausedWorker(t *testing.T) {
+	host := New(Options{StallTimeout: time.Minute})
+	var opened []*fakeWorker
+	host.Add("payments", func(*Monitor) worker.JobWorker {
+		w := &fakeWorker{}
+		opened = append(opened, w)
+		return w
+	})
+	host.start()
+
+	require.NoError(t, host.Pause("payments"))
+	host.check(time.Now().Add(2 * time.Minute))
+
+	require.Len(t, opened, 1)
+	assert.True(t, opened[0].Paused())
+	assert.Equal(t, http.StatusOK, statusCode(t, host.Handler(), "/readyz"))
+	assert.Equal(t, http.StatusOK, statusCode(t, host.Handler(), "/healthz"))
+
+	require.NoError(t, host.Resume("payments"))
+	assert.False(t, opened[0].Paused())
+	host.check(time.Now().Add(30 * time.Second))
+	require.Len(t, opened, 1)
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode(t, host.Handler(), "/readyz"))
+}
+
+func TestHostKeepsWorkerPausedWhenItIsRestarted(t *testing.T) {
+	host := New(Options{StallTimeout: time.Minute})
+	var opened []*fakeWorker
+	host.Add("payments", func(*Monitor) worker.JobWorker {
+		w := &fakeWorker{}
+		opened = append(opened, w)
+		return w
+	})
+	require.NoError(t, host.Pause("payments"))
+	host.start()
+
+	require.Len(t, opened, 1)
+	assert.True(t, opened[0].Paused())
+	assert.Error(t, host.Pause("shipping"))
+}
+
+func TestRunDrainsWorkersWhenContextIsDone(t *testing.T) {
+	host := New(Options{})
+	w := &fakeWorker{}
+	host.Add("payments", func(*Monitor) worker.JobWorker {
+		return w
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- host.Run(ctx)
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected host to stop")
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&w.drained))
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode(t, host.Handler(), "/readyz"))
+}
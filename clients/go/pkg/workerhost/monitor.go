@@ -0,0 +1,106 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workerhost
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
+)
+
+// Monitor observes a hosted worker to decide whether it is ready and healthy. It implements the metrics interfaces of
+// the worker package and has to be passed to the builder of the worker with Metrics; handler errors are only counted
+// for handlers which are wrapped with Fallible.
+type Monitor struct {
+	mutex        sync.Mutex
+	lastActivity time.Time
+	polled       bool
+	handled      int
+	failed       int
+	paused       bool
+}
+
+func newMonitor(now time.Time) *Monitor {
+	return &Monitor{lastActivity: now}
+}
+
+// Fallible wraps the handler, so its errors are counted for the handler error rate of the worker.
+func (m *Monitor) Fallible(handler worker.FallibleJobHandler) worker.FallibleJobHandler {
+	return func(client worker.JobClient, job entities.Job) error {
+		err := handler(client, job)
+		if err != nil {
+			m.mutex.Lock()
+			m.failed++
+			m.mutex.Unlock()
+		}
+		return err
+	}
+}
+
+func (m *Monitor) SetJobsRemainingCount(string, int) {}
+
+func (m *Monitor) IncrementJobsActivatedCount(string, int) {}
+
+func (m *Monitor) IncrementActivationFailuresCount(string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.lastActivity = time.Now()
+}
+
+func (m *Monitor) ObserveJobPoll(string, int, int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.lastActivity = time.Now()
+	m.polled = true
+}
+
+func (m *Monitor) ObserveJobHandlerDuration(string, time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.lastActivity = time.Now()
+	m.handled++
+}
+
+func (m *Monitor) SetJobWorkerPaused(_ string, paused bool) {
+	m.setPaused(paused)
+}
+
+// setPaused records whether the worker is paused; the time without activity starts over whenever it changes.
+func (m *Monitor) setPaused(paused bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.paused != paused {
+		m.lastActivity = time.Now()
+	}
+	m.paused = paused
+}
+
+func (m *Monitor) isPaused() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.paused
+}
+
+// window returns the state of the worker since the previous window, and starts the next one.
+func (m *Monitor) window() (polled bool, lastActivity time.Time, handled, failed int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	handled, failed = m.handled, m.failed
+	m.handled, m.failed = 0, 0
+	return m.polled, m.lastActivity, handled, failed
+}
@@ -0,0 +1,107 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultPollInterval is used by AwaitCompletion if no poll interval is given.
+const DefaultPollInterval = time.Second
+
+// ErrInstanceTerminated is returned by AwaitCompletion if the workflow
+// instance was terminated, e.g. canceled, instead of completed.
+var ErrInstanceTerminated = errors.New("workflow instance was terminated")
+
+// InstanceStatus is the lifecycle status of a workflow instance.
+type InstanceStatus int
+
+const (
+	// InstanceActive means the workflow instance has not ended yet.
+	InstanceActive InstanceStatus = iota
+	// InstanceCompleted means the workflow instance reached an end event.
+	InstanceCompleted
+	// InstanceTerminated means the workflow instance was canceled.
+	InstanceTerminated
+)
+
+// InstanceState is the state of a workflow instance as seen by an
+// InstanceLookup.
+type InstanceState struct {
+	WorkflowInstanceKey int64
+	Status              InstanceStatus
+	// Variables is the JSON document of the instance's variables; it is only
+	// expected to be complete once the instance ended.
+	Variables string
+}
+
+// InstanceLookup returns the current state of a workflow instance. The gateway
+// has no query API, so implementations typically read the records exported by
+// the brokers, e.g. from Elasticsearch. Implementations should return a nil
+// state if the instance is not known (yet), for example because the exporter
+// lags behind.
+type InstanceLookup interface {
+	Lookup(ctx context.Context, workflowInstanceKey int64) (*InstanceState, error)
+}
+
+// InstanceLookupFunc adapts a function to the InstanceLookup interface.
+type InstanceLookupFunc func(ctx context.Context, workflowInstanceKey int64) (*InstanceState, error)
+
+// Lookup calls f(ctx, workflowInstanceKey).
+func (f InstanceLookupFunc) Lookup(ctx context.Context, workflowInstanceKey int64) (*InstanceState, error) {
+	return f(ctx, workflowInstanceKey)
+}
+
+// AwaitCompletion polls the lookup every pollInterval until the workflow
+// instance completed and returns its final state. Unlike creating an instance
+// with result, it is not bound to the gateway's request timeout; the only
+// deadline is the one of ctx. If pollInterval is not positive,
+// DefaultPollInterval is used.
+//
+// It returns ErrInstanceTerminated if the instance was terminated, the error
+// of the lookup if it fails, or the context error if ctx is done first.
+func AwaitCompletion(ctx context.Context, lookup InstanceLookup, workflowInstanceKey int64, pollInterval time.Duration) (*InstanceState, error) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		state, err := lookup.Lookup(ctx, workflowInstanceKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up workflow instance %d: %w", workflowInstanceKey, err)
+		}
+
+		if state != nil {
+			switch state.Status {
+			case InstanceCompleted:
+				return state, nil
+			case InstanceTerminated:
+				return state, fmt.Errorf("workflow instance %d: %w", workflowInstanceKey, ErrInstanceTerminated)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+)
+
+func TestAwaitCompletion(t *testing.T) {
+	polls := 0
+	lookup := InstanceLookupFunc(func(_ context.Context, key int64) (*InstanceState, error) {
+		polls++
+		switch polls {
+		case 1:
+			return nil, nil
+		case 2:
+			return &InstanceState{WorkflowInstanceKey: key, Status: InstanceActive}, nil
+		default:
+			return &InstanceState{WorkflowInstanceKey: key, Status: InstanceCompleted, Variables: `{"foo":"bar"}`}, nil
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	state, err := AwaitCompletion(ctx, lookup, 123, time.Millisecond)
+	if err != nil {
+		t.Fatalf("AwaitCompletion() = %v", err)
+	}
+
+	if state.Variables != `{"foo":"bar"}` || polls != 3 {
+		t.Errorf("AwaitCompletion() = %v after %d polls, expected completed state after 3 polls", state, polls)
+	}
+}
+
+func TestAwaitCompletionOfTerminatedInstance(t *testing.T) {
+	lookup := InstanceLookupFunc(func(_ context.Context, key int64) (*InstanceState, error) {
+		return &InstanceState{WorkflowInstanceKey: key, Status: InstanceTerminated}, nil
+	})
+
+	_, err := AwaitCompletion(context.Background(), lookup, 123, time.Millisecond)
+	if !errors.Is(err, ErrInstanceTerminated) {
+		t.Errorf("AwaitCompletion() = %v, expected %v", err, ErrInstanceTerminated)
+	}
+}
+
+func TestAwaitCompletionFailsWithLookup(t *testing.T) {
+	lookupErr := errors.New("index not found")
+	lookup := InstanceLookupFunc(func(context.Context, int64) (*InstanceState, error) {
+		return nil, lookupErr
+	})
+
+	_, err := AwaitCompletion(context.Background(), lookup, 123, time.Millisecond)
+	if !errors.Is(err, lookupErr) {
+		t.Errorf("AwaitCompletion() = %v, expected %v", err, lookupErr)
+	}
+}
+
+func TestAwaitCompletionRespectsDeadline(t *testing.T) {
+	lookup := InstanceLookupFunc(func(_ context.Context, key int64) (*InstanceState, error) {
+		return &InstanceState{WorkflowInstanceKey: key, Status: InstanceActive}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := AwaitCompletion(ctx, lookup, 123, time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("AwaitCompletion() = %v, expected %v", err, context.DeadlineExceeded)
+	}
+}
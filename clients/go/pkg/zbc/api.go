@@ -16,8 +16,11 @@
 package zbc
 
 import (
+	"context"
+
 	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
+	"google.golang.org/grpc/connectivity"
 )
 
 type Client interface {
@@ -25,11 +28,17 @@ type Client interface {
 	NewDeployWorkflowCommand() *commands.DeployCommand
 
 	NewCreateInstanceCommand() commands.CreateInstanceCommandStep1
+	NewCreateInstanceBatchCommand() *commands.CreateInstanceBatchCommand
 	NewCancelInstanceCommand() commands.CancelInstanceStep1
+	NewCancelInstanceTreeCommand() *commands.CancelInstanceTreeCommand
 	NewSetVariablesCommand() commands.SetVariablesCommandStep1
+	NewExportVariablesCommand() *commands.ExportVariablesCommand
+	NewImportVariablesCommand() *commands.ImportVariablesCommand
 	NewResolveIncidentCommand() commands.ResolveIncidentCommandStep1
+	NewResolveIncidentBatchCommand() *commands.ResolveIncidentBatchCommand
 
 	NewPublishMessageCommand() commands.PublishMessageCommandStep1
+	NewPublishMessageBatchCommand() *commands.PublishMessageBatchCommand
 
 	NewActivateJobsCommand() commands.ActivateJobsCommandStep1
 	NewCompleteJobCommand() commands.CompleteJobCommandStep1
@@ -39,5 +48,14 @@ type Client interface {
 
 	NewJobWorker() worker.JobWorkerBuilderStep1
 
+	// ConnectionState returns the current state of the connection to the gateway, which is idle until the first
+	// command is sent
+	ConnectionState() connectivity.State
+	// GatewayVersion returns the version of the gateway, which is queried once
+	GatewayVersion(ctx context.Context) (string, error)
+	// Supports returns whether the gateway supports the feature, based on its version
+	Supports(ctx context.Context, feature Feature) (bool, error)
+	// Metadata returns the cache of the workflows which were observed in the responses of the client's commands
+	Metadata() *MetadataCache
 	Close() error
 }
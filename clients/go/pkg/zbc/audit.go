@@ -0,0 +1,141 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DefaultAuditMaxVariablesLength is the length to which the variables of audit records are truncated by default
+const DefaultAuditMaxVariablesLength = 256
+
+// AuditRecord describes a unary command which was sent by the client.
+type AuditRecord struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	// Keys are the keys of the request and the response, by the name of their field, e.g. 'jobKey'
+	Keys map[string]int64 `json:"keys,omitempty"`
+	// BpmnProcessID is the BPMN process id of the request, if any
+	BpmnProcessID string `json:"bpmnProcessId,omitempty"`
+	// DurationMs is how long the command took, including all retries, in milliseconds
+	DurationMs float64 `json:"durationMs"`
+	// Code is the status code of the outcome, e.g. 'OK' or 'NotFound', and Error the message of a failed command
+	Code  string `json:"code"`
+	Error string `json:"error,omitempty"`
+	// Variables are the variables of the request, truncated to the configured length
+	Variables          string `json:"variables,omitempty"`
+	VariablesTruncated bool   `json:"variablesTruncated,omitempty"`
+}
+
+// AuditSink receives an audit record for every unary command sent by the client, after the command finished. It is
+// called concurrently, by the goroutine which sent the command.
+type AuditSink interface {
+	Audit(record AuditRecord)
+}
+
+// AuditSinkFunc is an AuditSink which calls the function.
+type AuditSinkFunc func(record AuditRecord)
+
+func (f AuditSinkFunc) Audit(record AuditRecord) {
+	f(record)
+}
+
+// NewJSONLinesAuditSink creates an AuditSink which writes every record as a line of JSON to the writer, e.g. a file
+// which is shipped to a compliance pipeline. Records which can't be written are dropped.
+func NewJSONLinesAuditSink(writer io.Writer) AuditSink {
+	return &jsonLinesAuditSink{encoder: json.NewEncoder(writer)}
+}
+
+type jsonLinesAuditSink struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+}
+
+func (s *jsonLinesAuditSink) Audit(record AuditRecord) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_ = s.encoder.Encode(record)
+}
+
+func auditInterceptor(sink AuditSink, maxVariablesLength int) CommandInterceptor {
+	if maxVariablesLength == 0 {
+		maxVariablesLength = DefaultAuditMaxVariablesLength
+	}
+
+	return func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+		start := time.Now()
+		err := invoker(ctx, request, response)
+
+		record := AuditRecord{
+			Time:       start,
+			Command:    info.Name,
+			DurationMs: float64(time.Since(start)) / float64(time.Millisecond),
+			Code:       status.Code(err).String(),
+		}
+		if err != nil {
+			record.Error = status.Convert(err).Message()
+		}
+		auditMessage(&record, request, maxVariablesLength)
+		if err == nil {
+			auditMessage(&record, response, maxVariablesLength)
+		}
+
+		sink.Audit(record)
+		return err
+	}
+}
+
+// auditMessage adds the keys, BPMN process id and variables of the message to the record.
+func auditMessage(record *AuditRecord, message interface{}, maxVariablesLength int) {
+	protoMessage, ok := message.(proto.Message)
+	if !ok {
+		return
+	}
+
+	protoMessage.ProtoReflect().Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		name := field.JSONName()
+		switch {
+		case field.Kind() == protoreflect.Int64Kind && field.Cardinality() != protoreflect.Repeated && (name == "key" || strings.HasSuffix(name, "Key")):
+			if record.Keys == nil {
+				record.Keys = make(map[string]int64)
+			}
+			record.Keys[name] = value.Int()
+		case field.Kind() == protoreflect.StringKind && name == "bpmnProcessId" && record.BpmnProcessID == "":
+			record.BpmnProcessID = value.String()
+		case field.Kind() == protoreflect.StringKind && name == "variables" && record.Variables == "" && maxVariablesLength > 0:
+			record.Variables = value.String()
+			if len(record.Variables) > maxVariablesLength {
+				end := maxVariablesLength
+				for end > 0 && !utf8.RuneStart(record.Variables[end]) {
+					end--
+				}
+				record.Variables = record.Variables[:end]
+				record.VariablesTruncated = true
+			}
+		}
+		return true
+	})
+}
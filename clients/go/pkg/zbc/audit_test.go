@@ -0,0 +1,94 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func TestAuditSinkRecordsCommands(t *testing.T) {
+	// given
+	lis, server := createServerWithInterceptor(func(_ context.Context, _ interface{}, info *grpc.UnaryServerInfo, _ grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod == "/gateway_protocol.Gateway/CreateWorkflowInstance" {
+			return &pb.CreateWorkflowInstanceResponse{WorkflowKey: 1, BpmnProcessId: "order-process", WorkflowInstanceKey: 2}, nil
+		}
+		return nil, status.Error(codes.NotFound, "job not found")
+	})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	var records []AuditRecord
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:          lis.Addr().String(),
+		UsePlaintextConnection:  true,
+		AuditSink:               AuditSinkFunc(func(record AuditRecord) { records = append(records, record) }),
+		AuditMaxVariablesLength: 10,
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	create, err := client.NewCreateInstanceCommand().BPMNProcessId("order-process").LatestVersion().VariablesFromString(`{"orderId":"DE-42"}`)
+	require.NoError(t, err)
+	_, err = create.Send(ctx)
+	require.NoError(t, err)
+	_, err = client.NewCompleteJobCommand().JobKey(123).Send(ctx)
+	require.Error(t, err)
+
+	// then
+	require.Len(t, records, 2)
+
+	require.Equal(t, "CreateWorkflowInstance", records[0].Command)
+	require.Equal(t, "OK", records[0].Code)
+	require.Equal(t, "order-process", records[0].BpmnProcessID)
+	require.Equal(t, map[string]int64{"workflowKey": 1, "workflowInstanceKey": 2}, records[0].Keys)
+	require.Equal(t, `{"orderId"`, records[0].Variables)
+	require.True(t, records[0].VariablesTruncated)
+
+	require.Equal(t, "CompleteJob", records[1].Command)
+	require.Equal(t, "NotFound", records[1].Code)
+	require.Equal(t, "job not found", records[1].Error)
+	require.Equal(t, map[string]int64{"jobKey": 123}, records[1].Keys)
+}
+
+func TestJSONLinesAuditSink(t *testing.T) {
+	var buffer bytes.Buffer
+	sink := NewJSONLinesAuditSink(&buffer)
+
+	sink.Audit(AuditRecord{Command: "CompleteJob", Keys: map[string]int64{"jobKey": 1}, Code: "OK"})
+	sink.Audit(AuditRecord{Command: "FailJob", Code: "NotFound", Error: "job not found"})
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Len(t, lines, 2)
+	var record AuditRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	require.Equal(t, "FailJob", record.Command)
+	require.Equal(t, "job not found", record.Error)
+}
@@ -0,0 +1,348 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const DefaultCircuitBreakerFailureThreshold = 5
+const DefaultCircuitBreakerOpenDuration = 10 * time.Second
+const DefaultCircuitBreakerHalfOpenProbes = 1
+
+// DefaultCircuitBreakerFailureCodes are the status codes which indicate that the gateway is failing, as opposed to
+// the command being rejected, e.g. because the job does not exist.
+var DefaultCircuitBreakerFailureCodes = []codes.Code{
+	codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded, codes.Internal,
+}
+
+// ErrCircuitOpen is returned by commands which are not sent because the circuit breaker of the command is open. Use
+// this value to do error comparison.
+const ErrCircuitOpen = Error("circuit breaker is open, the command was not sent to the gateway")
+
+// CircuitState is the state of the circuit breaker of a command.
+type CircuitState int
+
+const (
+	// CircuitClosed sends all commands to the gateway
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails all commands with ErrCircuitOpen, without sending them to the gateway
+	CircuitOpen
+	// CircuitHalfOpen sends a limited number of probe commands to the gateway, to decide whether it recovered
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerMetrics is implemented by CommandMetrics which also observe the state of the circuit breakers.
+type CircuitBreakerMetrics interface {
+	// Observe that the circuit breaker of the command changed to the state
+	SetCircuitState(command string, state CircuitState)
+}
+
+// CircuitBreakerPolicy configures when the circuit breaker of a command opens and how it recovers. Every command has
+// its own circuit breaker. Zero values are replaced by the respective defaults.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failed commands after which the circuit opens
+	FailureThreshold int
+	// OpenDuration is how long the circuit stays open before probe commands are sent again
+	OpenDuration time.Duration
+	// HalfOpenProbes is the number of probe commands which are sent while the circuit is half-open. The circuit closes
+	// once all of them succeeded, and opens again as soon as one of them failed.
+	HalfOpenProbes int
+	// FailureCodes are the status codes which count as failure
+	FailureCodes []codes.Code
+	// OnStateChange, if set, is called whenever the circuit breaker of a command changes its state
+	OnStateChange func(command string, from, to CircuitState)
+}
+
+func (p CircuitBreakerPolicy) withDefaults() CircuitBreakerPolicy {
+	if p.FailureThreshold <= 0 {
+		p.FailureThreshold = DefaultCircuitBreakerFailureThreshold
+	}
+	if p.OpenDuration <= 0 {
+		p.OpenDuration = DefaultCircuitBreakerOpenDuration
+	}
+	if p.HalfOpenProbes <= 0 {
+		p.HalfOpenProbes = DefaultCircuitBreakerHalfOpenProbes
+	}
+	if len(p.FailureCodes) == 0 {
+		p.FailureCodes = DefaultCircuitBreakerFailureCodes
+	}
+
+	return p
+}
+
+func (p CircuitBreakerPolicy) isFailure(err error) bool {
+	code := status.Code(err)
+	for _, failureCode := range p.FailureCodes {
+		if code == failureCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+type circuitBreaker struct {
+	command string
+	policy  CircuitBreakerPolicy
+	metrics CircuitBreakerMetrics
+	now     func() time.Time
+
+	lock     sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probes   int
+	probed   int
+}
+
+func newCircuitBreaker(command string, policy CircuitBreakerPolicy, metrics CircuitBreakerMetrics) *circuitBreaker {
+	return &circuitBreaker{command: command, policy: policy, metrics: metrics, now: time.Now}
+}
+
+// allow returns whether the command may be sent, and whether it is a probe of the half-open circuit. Every allowed
+// command must be followed by a call to done.
+func (b *circuitBreaker) allow() (bool, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == CircuitOpen {
+		if b.now().Sub(b.openedAt) < b.policy.OpenDuration {
+			return false, false
+		}
+		b.transition(CircuitHalfOpen)
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.probes+b.probed >= b.policy.HalfOpenProbes {
+			return false, false
+		}
+		b.probes++
+		return true, true
+	}
+
+	return true, false
+}
+
+// done records the outcome of an allowed command.
+func (b *circuitBreaker) done(probe bool, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if probe {
+		b.probes--
+	}
+
+	switch {
+	case b.policy.isFailure(err):
+		b.failures++
+		if (probe && b.state == CircuitHalfOpen) || (b.state == CircuitClosed && b.failures >= b.policy.FailureThreshold) {
+			b.open()
+		}
+	case err != nil && (errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled):
+		// the command was canceled by the caller, which says nothing about the gateway
+	default:
+		b.failures = 0
+		if probe && b.state == CircuitHalfOpen {
+			b.probed++
+			if b.probed >= b.policy.HalfOpenProbes {
+				b.transition(CircuitClosed)
+			}
+		}
+	}
+}
+
+func (b *circuitBreaker) open() {
+	b.openedAt = b.now()
+	b.transition(CircuitOpen)
+}
+
+func (b *circuitBreaker) transition(state CircuitState) {
+	from := b.state
+	b.state = state
+	b.failures = 0
+	b.probes = 0
+	b.probed = 0
+
+	if b.metrics != nil {
+		b.metrics.SetCircuitState(b.command, state)
+	}
+	if b.policy.OnStateChange != nil {
+		b.policy.OnStateChange(b.command, from, state)
+	}
+}
+
+type circuitBreakers struct {
+	policies      map[string]CircuitBreakerPolicy
+	defaultPolicy *CircuitBreakerPolicy
+	metrics       CircuitBreakerMetrics
+
+	lock     sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+func newCircuitBreakers(config *ClientConfig) *circuitBreakers {
+	breakers := &circuitBreakers{
+		policies: make(map[string]CircuitBreakerPolicy, len(config.CommandCircuitBreakers)),
+		breakers: make(map[string]*circuitBreaker),
+	}
+
+	for command, policy := range config.CommandCircuitBreakers {
+		if policy != nil {
+			breakers.policies[command] = policy.withDefaults()
+		}
+	}
+	if config.CircuitBreaker != nil {
+		policy := config.CircuitBreaker.withDefaults()
+		breakers.defaultPolicy = &policy
+	}
+	if metrics, ok := config.CommandMetrics.(CircuitBreakerMetrics); ok {
+		breakers.metrics = metrics
+	}
+
+	return breakers
+}
+
+// of returns the circuit breaker of the command, or nil if the command has none.
+func (c *circuitBreakers) of(command string) *circuitBreaker {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if breaker, ok := c.breakers[command]; ok {
+		return breaker
+	}
+
+	policy, ok := c.policies[command]
+	if !ok {
+		if c.defaultPolicy == nil {
+			return nil
+		}
+		policy = *c.defaultPolicy
+	}
+
+	breaker := newCircuitBreaker(command, policy, c.metrics)
+	c.breakers[command] = breaker
+	return breaker
+}
+
+func validateCircuitBreakerPolicy(policy *CircuitBreakerPolicy) error {
+	if policy != nil && (policy.FailureThreshold < 0 || policy.OpenDuration < 0 || policy.HalfOpenProbes < 0) {
+		return errors.New("circuit breaker must have a non-negative failure threshold, open duration and number of probes")
+	}
+
+	return nil
+}
+
+func configureCircuitBreakers(config *ClientConfig) error {
+	if err := validateCircuitBreakerPolicy(config.CircuitBreaker); err != nil {
+		return err
+	}
+
+	for _, policy := range config.CommandCircuitBreakers {
+		if err := validateCircuitBreakerPolicy(policy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hasCircuitBreaker(config *ClientConfig) bool {
+	return config.CircuitBreaker != nil || len(config.CommandCircuitBreakers) > 0
+}
+
+func circuitBreakerInterceptor(breakers *circuitBreakers) CommandInterceptor {
+	return func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+		breaker := breakers.of(info.Name)
+		if breaker == nil {
+			return invoker(ctx, request, response)
+		}
+
+		allowed, probe := breaker.allow()
+		if !allowed {
+			return ErrCircuitOpen
+		}
+
+		err := invoker(ctx, request, response)
+		breaker.done(probe, err)
+		return err
+	}
+}
+
+func circuitBreakerStreamInterceptor(breakers *circuitBreakers) StreamCommandInterceptor {
+	return func(ctx context.Context, info CommandInfo, streamer CommandStreamer) (grpc.ClientStream, error) {
+		breaker := breakers.of(info.Name)
+		if breaker == nil {
+			return streamer(ctx)
+		}
+
+		allowed, probe := breaker.allow()
+		if !allowed {
+			return nil, ErrCircuitOpen
+		}
+
+		stream, err := streamer(ctx)
+		if err != nil {
+			breaker.done(probe, err)
+			return nil, err
+		}
+
+		return &circuitBreakerStream{ClientStream: stream, breaker: breaker, probe: probe}, nil
+	}
+}
+
+// circuitBreakerStream records the outcome of a streaming command once the stream ended.
+type circuitBreakerStream struct {
+	grpc.ClientStream
+	breaker *circuitBreaker
+	probe   bool
+	once    sync.Once
+}
+
+func (s *circuitBreakerStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.once.Do(func() {
+			if err == io.EOF {
+				s.breaker.done(s.probe, nil)
+			} else {
+				s.breaker.done(s.probe, err)
+			}
+		})
+	}
+
+	return err
+}
@@ -0,0 +1,189 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+)
+
+type circuitStateMetrics struct {
+	states []CircuitState
+}
+
+func (m *circuitStateMetrics) ObserveCommandLatency(string, codes.Code, time.Duration) {}
+
+func (m *circuitStateMetrics) SetCircuitState(_ string, state CircuitState) {
+	m.states = append(m.states, state)
+}
+
+func TestCircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	breaker := newCircuitBreaker("Topology", CircuitBreakerPolicy{FailureThreshold: 2}.withDefaults(), nil)
+	unavailable := status.Error(codes.Unavailable, "gateway unavailable")
+
+	for _, err := range []error{unavailable, nil, unavailable, status.Error(codes.NotFound, "not found"), unavailable} {
+		allowed, _ := breaker.allow()
+		require.True(t, allowed)
+		breaker.done(false, err)
+	}
+	require.Equal(t, CircuitClosed, breaker.state)
+
+	allowed, _ := breaker.allow()
+	require.True(t, allowed)
+	breaker.done(false, unavailable)
+
+	allowed, _ = breaker.allow()
+	require.False(t, allowed)
+	require.Equal(t, CircuitOpen, breaker.state)
+}
+
+func TestCircuitBreakerClosesAfterSuccessfulProbes(t *testing.T) {
+	now := time.Now()
+	metrics := &circuitStateMetrics{}
+	var transitions []CircuitState
+	policy := CircuitBreakerPolicy{
+		FailureThreshold: 1,
+		OpenDuration:     time.Second,
+		HalfOpenProbes:   2,
+		OnStateChange: func(command string, from, to CircuitState) {
+			require.Equal(t, "Topology", command)
+			transitions = append(transitions, to)
+		},
+	}
+	breaker := newCircuitBreaker("Topology", policy.withDefaults(), metrics)
+	breaker.now = func() time.Time { return now }
+
+	_, _ = breaker.allow()
+	breaker.done(false, status.Error(codes.Unavailable, "gateway unavailable"))
+
+	// the circuit stays open until the open duration elapsed
+	now = now.Add(500 * time.Millisecond)
+	allowed, _ := breaker.allow()
+	require.False(t, allowed)
+
+	// only the configured number of probes is sent while half-open
+	now = now.Add(time.Second)
+	allowed, firstProbe := breaker.allow()
+	require.True(t, allowed)
+	require.True(t, firstProbe)
+	allowed, secondProbe := breaker.allow()
+	require.True(t, allowed)
+	require.True(t, secondProbe)
+	allowed, _ = breaker.allow()
+	require.False(t, allowed)
+
+	breaker.done(true, nil)
+	require.Equal(t, CircuitHalfOpen, breaker.state)
+	breaker.done(true, nil)
+
+	require.Equal(t, CircuitClosed, breaker.state)
+	require.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}, transitions)
+	require.Equal(t, transitions, metrics.states)
+}
+
+func TestCircuitBreakerReopensAfterFailedProbe(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker("Topology", CircuitBreakerPolicy{FailureThreshold: 1}.withDefaults(), nil)
+	breaker.now = func() time.Time { return now }
+
+	_, _ = breaker.allow()
+	breaker.done(false, status.Error(codes.Unavailable, "gateway unavailable"))
+
+	now = now.Add(DefaultCircuitBreakerOpenDuration)
+	_, probe := breaker.allow()
+	breaker.done(probe, status.Error(codes.DeadlineExceeded, "timeout"))
+	require.Equal(t, CircuitOpen, breaker.state)
+
+	now = now.Add(DefaultCircuitBreakerOpenDuration / 2)
+	allowed, _ := breaker.allow()
+	require.False(t, allowed)
+}
+
+func TestCircuitBreakerIgnoresCanceledProbe(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker("Topology", CircuitBreakerPolicy{FailureThreshold: 1}.withDefaults(), nil)
+	breaker.now = func() time.Time { return now }
+
+	_, _ = breaker.allow()
+	breaker.done(false, status.Error(codes.Unavailable, "gateway unavailable"))
+
+	now = now.Add(DefaultCircuitBreakerOpenDuration)
+	_, probe := breaker.allow()
+	breaker.done(probe, status.Error(codes.Canceled, "canceled"))
+
+	allowed, probe := breaker.allow()
+	require.True(t, allowed)
+	require.True(t, probe)
+	require.Equal(t, CircuitHalfOpen, breaker.state)
+}
+
+func TestCircuitBreakerShortCircuitsCommands(t *testing.T) {
+	// given
+	var calls int32
+	lis, server := createServerWithInterceptor(func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, status.Error(codes.Unavailable, "gateway unavailable")
+	})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		CircuitBreaker:         &CircuitBreakerPolicy{FailureThreshold: 3, OpenDuration: time.Minute},
+		CommandCircuitBreakers: map[string]*CircuitBreakerPolicy{"CancelWorkflowInstance": {FailureThreshold: 1}},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	for i := 0; i < 3; i++ {
+		_, err = client.NewTopologyCommand().Send(ctx)
+		require.EqualValues(t, codes.Unavailable, status.Code(err))
+	}
+	_, err = client.NewTopologyCommand().Send(ctx)
+
+	// then
+	require.Equal(t, ErrCircuitOpen, err)
+	require.EqualValues(t, 3, atomic.LoadInt32(&calls))
+
+	_, err = client.NewCancelInstanceCommand().WorkflowInstanceKey(123).Send(ctx)
+	require.EqualValues(t, codes.Unavailable, status.Code(err))
+	_, err = client.NewCancelInstanceCommand().WorkflowInstanceKey(123).Send(ctx)
+	require.Equal(t, ErrCircuitOpen, err)
+	require.EqualValues(t, 4, atomic.LoadInt32(&calls))
+}
+
+func TestInvalidCircuitBreakerFailsClient(t *testing.T) {
+	_, err := NewClient(&ClientConfig{
+		GatewayAddress:         "localhost:26500",
+		UsePlaintextConnection: true,
+		CircuitBreaker:         &CircuitBreakerPolicy{FailureThreshold: -1},
+	})
+
+	require.Error(t, err)
+}
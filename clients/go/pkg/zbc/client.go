@@ -15,11 +15,15 @@
 package zbc
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"github.com/zeebe-io/zeebe/clients/go/internal/embedded"
-	"log"
+	"io"
+	"io/ioutil"
+	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -29,8 +33,12 @@ import (
 	"google.golang.org/grpc/keepalive"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 
 	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/payloadstore"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
 )
@@ -41,24 +49,178 @@ const InsecureEnvVar = "ZEEBE_INSECURE_CONNECTION"
 const CaCertificatePath = "ZEEBE_CA_CERTIFICATE_PATH"
 const KeepAliveEnvVar = "ZEEBE_KEEP_ALIVE"
 const GatewayAddressEnvVar = "ZEEBE_ADDRESS"
+const ClientCertificatePathEnvVar = "ZEEBE_CLIENT_CERTIFICATE_PATH"
+const ClientKeyPathEnvVar = "ZEEBE_CLIENT_KEY_PATH"
+const KeepAliveTimeoutEnvVar = "ZEEBE_KEEP_ALIVE_TIMEOUT"
+
+// UnixSocketAddressPrefix is the prefix of gateway addresses which point to a unix domain socket, e.g.
+// 'unix:///var/run/zeebe/gateway.sock'
+const UnixSocketAddressPrefix = "unix://"
 
 type ClientImpl struct {
 	gateway             pb.GatewayClient
+	activationGateway   pb.GatewayClient
 	connection          *grpc.ClientConn
+	pool                *connectionPool
 	credentialsProvider CredentialsProvider
+	codec               entities.VariableCodec
+	logger              logging.Logger
+	capabilities        *gatewayCapabilities
+	metadata            *MetadataCache
 }
 
 type ClientConfig struct {
+	// GatewayAddress is either 'host:port' or the path of a unix domain socket prefixed with 'unix://'
 	GatewayAddress         string
 	UsePlaintextConnection bool
 	CaCertificatePath      string
 	CredentialsProvider    CredentialsProvider
 
+	// GatewayAddresses, if set, are the 'host:port' addresses of multiple gateways. Commands are balanced round robin
+	// across the gateways which are connected, and gateways which can't be reached are skipped until they are
+	// reconnected. GatewayAddress defaults to the first address, e.g. for the OAuth audience.
+	GatewayAddresses []string
+
+	// ClientCertificatePath and ClientKeyPath point to a PEM encoded certificate and key which the client presents to
+	// the gateway, for gateways which require mutual TLS
+	ClientCertificatePath string
+	ClientKeyPath         string
+	// RootCAs, if set, are the certificate authorities used to verify the gateway certificate instead of the system
+	// pool. The certificate at CaCertificatePath is added to this pool.
+	RootCAs *x509.CertPool
+	// TLSConfig, if set, is the base configuration of the transport security, e.g. to plug in certificates which are
+	// rotated by a SPIFFE agent. The options above are applied to a copy of it.
+	TLSConfig *tls.Config
+
 	// KeepAlive can be used configure how often keep alive messages should be sent to the gateway. These will be sent
 	// whether or not there are active requests. Negative values will result in error and zero will result in the default
 	// of 45 seconds being used
 	KeepAlive time.Duration
-
+	// KeepAliveTimeout is how long the client waits for the response to a keep alive message before it closes the
+	// connection; zero keeps the gRPC default of 20 seconds. KeepAlivePermitWithoutStream sends keep alive messages
+	// even if there are no active requests.
+	KeepAliveTimeout             time.Duration
+	KeepAlivePermitWithoutStream bool
+
+	// LoadBalancingPolicy, if set, is the gRPC load balancing policy, e.g. "round_robin". Unless GatewayAddresses are
+	// set, the gateway address is then resolved with DNS, so calls are balanced across all addresses of the host, e.g.
+	// the pods of a headless Kubernetes service.
+	LoadBalancingPolicy string
+	// DNSResolutionInterval, if set, resolves the gateway address with DNS again in this interval, to pick up new
+	// addresses of the host while all connections are healthy. gRPC resolves at most every 30 seconds.
+	DNSResolutionInterval time.Duration
+
+	// GrpcCompression, if set, compresses every request with the registered gRPC compressor of that name, e.g.
+	// "gzip". Compressed responses are decompressed regardless of it. GrpcCompressionLevel sets the level of the gzip
+	// compressor, which applies to all gRPC connections of the process; zero keeps the default level.
+	GrpcCompression      string
+	GrpcCompressionLevel int
+
+	// Interceptors are applied, in order, to every unary command sent to the gateway
+	Interceptors []CommandInterceptor
+	// StreamInterceptors are applied, in order, to every streaming command sent to the gateway, e.g. ActivateJobs
+	StreamInterceptors []StreamCommandInterceptor
+	// CommandMetrics, if set, observes the latency and outcome of every unary command
+	CommandMetrics CommandMetrics
+
+	// DefaultCommandTimeout, if set, is the timeout of unary commands which are sent with a context without deadline
+	// and without a RequestTimeout of the command. Streaming commands like ActivateJobs are not affected, and commands
+	// which wait for a result, like CreateInstanceWithResult, only pass it to the gateway if set on the command.
+	DefaultCommandTimeout time.Duration
+
+	// RateLimiter, if set, limits the rate of all commands sent to the gateway, e.g. NewSaaSRateLimiter(). Every
+	// attempt of a retried command waits for the limiter.
+	RateLimiter RateLimiter
+	// CommandRateLimiters overrides the RateLimiter for specific commands, keyed by command name, e.g.
+	// 'CreateWorkflowInstance'
+	CommandRateLimiters map[string]RateLimiter
+
+	// RetryPolicy, if set, retries unary commands which failed with a transient error, e.g. because of backpressure.
+	// Commands which are not idempotent, like creating a workflow instance, are only retried if they have an entry in
+	// CommandRetryPolicies.
+	RetryPolicy *RetryPolicy
+	// CommandRetryPolicies overrides the RetryPolicy for specific commands, keyed by command name, e.g. 'CompleteJob'
+	CommandRetryPolicies map[string]*RetryPolicy
+
+	// CircuitBreaker, if set, fails commands with ErrCircuitOpen without sending them once they consistently failed,
+	// e.g. because the gateway is unavailable, so the gateway can recover without a pile up of requests. Every command
+	// has its own circuit breaker; its state is observed by the CommandMetrics if they implement CircuitBreakerMetrics.
+	CircuitBreaker *CircuitBreakerPolicy
+	// CommandCircuitBreakers overrides the CircuitBreaker for specific commands, keyed by command name, e.g.
+	// 'ActivateJobs'
+	CommandCircuitBreakers map[string]*CircuitBreakerPolicy
+
+	// Hedging, if set, sends read-only commands like Topology again if the gateway didn't respond within the delay of
+	// the policy, and uses the first successful response. Hedged attempts are counted by the CommandMetrics if they
+	// implement HedgingMetrics.
+	Hedging *HedgingPolicy
+
+	// MaxConcurrentActivations limits how many ActivateJobs requests the job workers and activate jobs commands of
+	// this client may have in flight at the same time. Further requests wait until one finished, coalesced per job
+	// type, and only all but one of the requests in flight long poll, see worker.ActivationDispatcher. Zero means no
+	// limit.
+	MaxConcurrentActivations int
+
+	// VariableCodec, if set, serializes and deserializes the variables of all commands and activated jobs instead of
+	// encoding/json. The gateway expects JSON documents, so the codec must still produce JSON. Use
+	// entities.NewMarshalingCodec to control how types like time.Time are serialized.
+	VariableCodec entities.VariableCodec
+	// VariableCompressionThreshold, if set, compresses top-level variables whose JSON value is larger than the
+	// threshold in bytes, see entities.NewCompressingCodec
+	VariableCompressionThreshold int
+	// PayloadStore, if set with a PayloadOffloadThreshold, stores the top-level variables whose JSON value is larger
+	// than the threshold in bytes and sends references instead, see payloadstore.NewCodec
+	PayloadStore            payloadstore.Store
+	PayloadOffloadThreshold int
+
+	// Logger, if set, is used by the client, its job workers and the default OAuth credentials provider instead of
+	// logging warnings and errors with the log package
+	Logger logging.Logger
+
+	// Dialer, if set, opens the connections to the gateway instead of dialing the gateway address over TCP, e.g. to
+	// connect to an in-process gateway in tests
+	Dialer func(ctx context.Context, address string) (net.Conn, error)
+
+	// ConnectionStateListener, if set, is called with the state of the connection to the gateway whenever it
+	// changes. The connection is idle until the first command is sent.
+	ConnectionStateListener ConnectionStateListener
+
+	// ConnectionPoolSize, if greater than one, is the number of connections to the gateway across which commands are
+	// distributed round robin, to avoid the throughput limit of a single HTTP/2 connection at very high command rates.
+	// The connection state and its listener only reflect the first connection.
+	ConnectionPoolSize int
+
+	// AuditSink, if set, receives a record of every unary command, e.g. NewJSONLinesAuditSink(file). The variables of
+	// the records are truncated to AuditMaxVariablesLength bytes, DefaultAuditMaxVariablesLength if zero; negative
+	// values omit them.
+	AuditSink               AuditSink
+	AuditMaxVariablesLength int
+
+	// CommandRecorder, if set, receives every command which changes the state of the broker as a line of JSON, e.g. a
+	// file, so the commands can be replayed against a test cluster with the replay package. The variables are recorded
+	// as they were sent, unless CommandRecorderSanitizer is set, e.g. to RedactVariables().
+	CommandRecorder          io.Writer
+	CommandRecorderSanitizer VariablesSanitizer
+
+	// MetadataCacheSize is the number of workflows whose BPMN process id and version are kept by the cache of
+	// Metadata(), DefaultMetadataCacheSize if zero
+	MetadataCacheSize int
+
+	// CheckGatewayFeatures, if set, fails commands which need a feature the gateway version doesn't support, like
+	// ThrowError, with ErrUnsupportedByGateway before they are sent. The version is queried once with a topology
+	// request; if that fails, the commands are sent anyway.
+	CheckGatewayFeatures bool
+
+	// GatewayCompatibility, if set, adapts commands to gateways of older versions, e.g. while the gateways are
+	// upgraded one by one: fields of features the gateway version doesn't support are removed from the requests and
+	// a warning is logged with Logger once per feature, instead of failing the commands.
+	GatewayCompatibility bool
+
+	// RequestSigner, if set, signs every attempt of every command, e.g. with NewHMACRequestSigner or
+	// NewJWTRequestSigner, for gateways behind a proxy which verifies the signatures of the requests.
+	RequestSigner RequestSigner
+
+	// DialOpts are passed to gRPC when dialing the gateway, together with the options derived from this configuration
 	DialOpts []grpc.DialOption
 }
 
@@ -80,23 +242,39 @@ func (c *ClientImpl) NewDeployWorkflowCommand() *commands.DeployCommand {
 }
 
 func (c *ClientImpl) NewPublishMessageCommand() commands.PublishMessageCommandStep1 {
-	return commands.NewPublishMessageCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewPublishMessageCommandWithCodec(c.gateway, c.credentialsProvider.ShouldRetryRequest, c.codec)
+}
+
+func (c *ClientImpl) NewPublishMessageBatchCommand() *commands.PublishMessageBatchCommand {
+	return commands.NewPublishMessageBatchCommandWithCodec(c.gateway, c.credentialsProvider.ShouldRetryRequest, c.codec)
 }
 
 func (c *ClientImpl) NewResolveIncidentCommand() commands.ResolveIncidentCommandStep1 {
 	return commands.NewResolveIncidentCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
 }
 
+func (c *ClientImpl) NewResolveIncidentBatchCommand() *commands.ResolveIncidentBatchCommand {
+	return commands.NewResolveIncidentBatchCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+}
+
 func (c *ClientImpl) NewCreateInstanceCommand() commands.CreateInstanceCommandStep1 {
-	return commands.NewCreateInstanceCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewCreateInstanceCommandWithCodec(c.gateway, c.credentialsProvider.ShouldRetryRequest, c.codec)
+}
+
+func (c *ClientImpl) NewCreateInstanceBatchCommand() *commands.CreateInstanceBatchCommand {
+	return commands.NewCreateInstanceBatchCommandWithCodec(c.gateway, c.credentialsProvider.ShouldRetryRequest, c.codec)
 }
 
 func (c *ClientImpl) NewCancelInstanceCommand() commands.CancelInstanceStep1 {
 	return commands.NewCancelInstanceCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
 }
 
+func (c *ClientImpl) NewCancelInstanceTreeCommand() *commands.CancelInstanceTreeCommand {
+	return commands.NewCancelInstanceTreeCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+}
+
 func (c *ClientImpl) NewCompleteJobCommand() commands.CompleteJobCommandStep1 {
-	return commands.NewCompleteJobCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewCompleteJobCommandWithCodec(c.gateway, c.credentialsProvider.ShouldRetryRequest, c.codec)
 }
 
 func (c *ClientImpl) NewFailJobCommand() commands.FailJobCommandStep1 {
@@ -108,23 +286,58 @@ func (c *ClientImpl) NewUpdateJobRetriesCommand() commands.UpdateJobRetriesComma
 }
 
 func (c *ClientImpl) NewSetVariablesCommand() commands.SetVariablesCommandStep1 {
-	return commands.NewSetVariablesCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewSetVariablesCommandWithCodec(c.gateway, c.credentialsProvider.ShouldRetryRequest, c.codec)
+}
+
+func (c *ClientImpl) NewExportVariablesCommand() *commands.ExportVariablesCommand {
+	return commands.NewExportVariablesCommand()
+}
+
+func (c *ClientImpl) NewImportVariablesCommand() *commands.ImportVariablesCommand {
+	return commands.NewImportVariablesCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
 }
 
 func (c *ClientImpl) NewActivateJobsCommand() commands.ActivateJobsCommandStep1 {
-	return commands.NewActivateJobsCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewActivateJobsCommandWithCodec(c.activationGateway, c.credentialsProvider.ShouldRetryRequest, c.codec)
 }
 
 func (c *ClientImpl) NewThrowErrorCommand() commands.ThrowErrorCommandStep1 {
-	return commands.NewThrowErrorCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewThrowErrorCommandWithCodec(c.gateway, c.credentialsProvider.ShouldRetryRequest, c.codec)
 }
 
 func (c *ClientImpl) NewJobWorker() worker.JobWorkerBuilderStep1 {
-	return worker.NewJobWorkerBuilder(c.gateway, c)
+	return worker.NewJobWorkerBuilderWithCodec(c.activationGateway, c, c.codec)
+}
+
+// Logger returns the logger of the client, which is used by its job workers by default.
+func (c *ClientImpl) Logger() logging.Logger {
+	return c.logger
+}
+
+// ConnectionState returns the current state of the connection to the gateway.
+func (c *ClientImpl) ConnectionState() connectivity.State {
+	return c.connection.GetState()
+}
+
+// GatewayVersion returns the version of the gateway, which is queried with a topology request the first time. It is
+// empty if the gateway doesn't report its version.
+func (c *ClientImpl) GatewayVersion(ctx context.Context) (string, error) {
+	return c.capabilities.gatewayVersion(ctx)
+}
+
+// Supports returns whether the gateway supports the feature. Gateways which don't report their version are assumed to
+// support all features of this protocol.
+func (c *ClientImpl) Supports(ctx context.Context, feature Feature) (bool, error) {
+	return c.capabilities.supports(ctx, feature)
+}
+
+// Metadata returns the cache of the workflows which were observed in the responses of the client's commands.
+func (c *ClientImpl) Metadata() *MetadataCache {
+	return c.metadata
 }
 
 func (c *ClientImpl) Close() error {
-	return c.connection.Close()
+	return c.pool.Close()
 }
 
 func NewClient(config *ClientConfig) (Client, error) {
@@ -133,6 +346,16 @@ func NewClient(config *ClientConfig) (Client, error) {
 		return nil, err
 	}
 
+	if config.Logger == nil {
+		config.Logger = logging.Default
+	}
+
+	target := configureGatewayAddresses(config)
+	target, err = configureLoadBalancing(config, target)
+	if err != nil {
+		return nil, err
+	}
+
 	err = configureConnectionSecurity(config)
 	if err != nil {
 		return nil, err
@@ -148,17 +371,80 @@ func NewClient(config *ClientConfig) (Client, error) {
 		return nil, err
 	}
 
+	err = configureGrpcCompression(config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = configureRetryPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = configureCircuitBreakers(config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = configureHedging(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.MetadataCacheSize < 0 {
+		return nil, errors.New("metadata cache size must not be negative")
+	}
+
+	if config.MaxConcurrentActivations < 0 {
+		return nil, errors.New("max concurrent activations must not be negative")
+	}
+
+	if config.VariableCodec == nil {
+		config.VariableCodec = entities.JSONCodec
+	}
+	if config.VariableCompressionThreshold > 0 {
+		config.VariableCodec = entities.NewCompressingCodec(config.VariableCodec, config.VariableCompressionThreshold)
+	}
+	if config.PayloadStore != nil && config.PayloadOffloadThreshold > 0 {
+		config.VariableCodec = payloadstore.NewCodec(config.VariableCodec, config.PayloadStore, config.PayloadOffloadThreshold)
+	}
+
+	capabilities := &gatewayCapabilities{}
+	metadata := NewMetadataCache(config.MetadataCacheSize)
+	configureInterceptors(config, capabilities, metadata)
+	configureDialer(config)
+
 	config.DialOpts = append(config.DialOpts, grpc.WithUserAgent("zeebe-client-go/"+getVersion()))
 
-	conn, err := grpc.Dial(config.GatewayAddress, config.DialOpts...)
+	pool, err := dialConnectionPool(config, target)
 	if err != nil {
 		return nil, err
 	}
+	conn := pool.conns[0]
+
+	if config.ConnectionStateListener != nil {
+		go watchConnectionState(conn, config.ConnectionStateListener)
+	}
+
+	gateway := pb.NewGatewayClient(pool)
+	capabilities.topology = func(ctx context.Context) (*pb.TopologyResponse, error) {
+		return gateway.Topology(ctx, &pb.TopologyRequest{})
+	}
+	activationGateway := gateway
+	if config.MaxConcurrentActivations > 0 {
+		activationGateway = worker.NewActivationDispatcher(gateway, config.MaxConcurrentActivations)
+	}
 
 	return &ClientImpl{
-		gateway:             pb.NewGatewayClient(conn),
+		gateway:             gateway,
+		activationGateway:   activationGateway,
 		connection:          conn,
+		pool:                pool,
 		credentialsProvider: config.CredentialsProvider,
+		codec:               config.VariableCodec,
+		logger:              config.Logger,
+		capabilities:        capabilities,
+		metadata:            metadata,
 	}, nil
 }
 
@@ -184,6 +470,23 @@ func applyClientEnvOverrides(config *ClientConfig) error {
 		config.KeepAlive = time.Duration(keepAlive) * time.Millisecond
 	}
 
+	if val := env.get(KeepAliveTimeoutEnvVar); val != "" {
+		timeout, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return fmt.Errorf("keep alive timeout must be expressed as positive number of milliseconds: %w", err)
+		}
+
+		config.KeepAliveTimeout = time.Duration(timeout) * time.Millisecond
+	}
+
+	if path := env.get(ClientCertificatePathEnvVar); path != "" {
+		config.ClientCertificatePath = path
+	}
+
+	if path := env.get(ClientKeyPathEnvVar); path != "" {
+		config.ClientKeyPath = path
+	}
+
 	return nil
 }
 
@@ -196,7 +499,7 @@ func configureCredentialsProvider(config *ClientConfig) error {
 
 	if config.CredentialsProvider != nil {
 		if config.UsePlaintextConnection {
-			log.Println("Warning: The configured security level does not guarantee that the credentials will be confidential. If this unintentional, please enable transport security.")
+			config.Logger.Warn("The configured security level does not guarantee that the credentials will be confidential. If this unintentional, please enable transport security.")
 		}
 
 		callCredentials := &callCredentials{credentialsProvider: config.CredentialsProvider}
@@ -213,13 +516,7 @@ func shouldUseDefaultCredentialsProvider() bool {
 }
 
 func setDefaultCredentialsProvider(config *ClientConfig) error {
-	var audience string
-	index := strings.LastIndex(config.GatewayAddress, ":")
-	if index > 0 {
-		audience = config.GatewayAddress[0:index]
-	}
-
-	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{Audience: audience})
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{Audience: defaultAudience(config.GatewayAddress), Logger: config.Logger})
 	if err != nil {
 		return err
 	}
@@ -228,22 +525,23 @@ func setDefaultCredentialsProvider(config *ClientConfig) error {
 	return nil
 }
 
+// defaultAudience is the host of the gateway address, which is the audience of access tokens for most gateways.
+func defaultAudience(address string) string {
+	index := strings.LastIndex(address, ":")
+	if index > 0 && !strings.HasPrefix(address, UnixSocketAddressPrefix) {
+		return address[0:index]
+	}
+	return ""
+}
+
 func configureConnectionSecurity(config *ClientConfig) error {
 	if !config.UsePlaintextConnection {
-		var creds credentials.TransportCredentials
-
-		if config.CaCertificatePath == "" {
-			creds = credentials.NewTLS(&tls.Config{})
-		} else if _, err := os.Stat(config.CaCertificatePath); os.IsNotExist(err) {
-			return fmt.Errorf("expected to find CA certificate but no such file at '%s': %w", config.CaCertificatePath, ErrFileNotFound)
-		} else {
-			creds, err = credentials.NewClientTLSFromFile(config.CaCertificatePath, "")
-			if err != nil {
-				return err
-			}
+		tlsConfig, err := createTLSConfig(config)
+		if err != nil {
+			return err
 		}
 
-		config.DialOpts = append(config.DialOpts, grpc.WithTransportCredentials(creds))
+		config.DialOpts = append(config.DialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	} else {
 		config.DialOpts = append(config.DialOpts, grpc.WithInsecure())
 	}
@@ -251,6 +549,60 @@ func configureConnectionSecurity(config *ClientConfig) error {
 	return nil
 }
 
+func createTLSConfig(config *ClientConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if config.TLSConfig != nil {
+		tlsConfig = config.TLSConfig.Clone()
+	}
+
+	if config.RootCAs != nil {
+		tlsConfig.RootCAs = config.RootCAs
+	}
+
+	if config.CaCertificatePath != "" {
+		if _, err := os.Stat(config.CaCertificatePath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("expected to find CA certificate but no such file at '%s': %w", config.CaCertificatePath, ErrFileNotFound)
+		}
+
+		certificate, err := ioutil.ReadFile(config.CaCertificatePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if tlsConfig.RootCAs == nil {
+			tlsConfig.RootCAs = x509.NewCertPool()
+		}
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(certificate) {
+			return nil, fmt.Errorf("expected to find PEM encoded CA certificate at '%s'", config.CaCertificatePath)
+		}
+	}
+
+	if config.ClientCertificatePath != "" || config.ClientKeyPath != "" {
+		certificate, err := tls.LoadX509KeyPair(config.ClientCertificatePath, config.ClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = append(tlsConfig.Certificates, certificate)
+	}
+
+	return tlsConfig, nil
+}
+
+func configureDialer(config *ClientConfig) {
+	if config.Dialer != nil {
+		config.DialOpts = append(config.DialOpts, grpc.WithContextDialer(config.Dialer))
+	} else if strings.HasPrefix(config.GatewayAddress, UnixSocketAddressPrefix) {
+		path := strings.TrimPrefix(config.GatewayAddress, UnixSocketAddressPrefix)
+		dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		}
+
+		config.DialOpts = append(config.DialOpts, grpc.WithContextDialer(dialer), grpc.WithAuthority("localhost"))
+	}
+}
+
 func configureKeepAlive(config *ClientConfig) error {
 	keepAlive := DefaultKeepAlive
 
@@ -259,7 +611,14 @@ func configureKeepAlive(config *ClientConfig) error {
 	} else if config.KeepAlive != time.Duration(0) {
 		keepAlive = config.KeepAlive
 	}
-	config.DialOpts = append(config.DialOpts, grpc.WithKeepaliveParams(keepalive.ClientParameters{Time: keepAlive}))
+	if config.KeepAliveTimeout < time.Duration(0) {
+		return errors.New("keep alive timeout must be a positive duration")
+	}
+	config.DialOpts = append(config.DialOpts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                keepAlive,
+		Timeout:             config.KeepAliveTimeout,
+		PermitWithoutStream: config.KeepAlivePermitWithoutStream,
+	}))
 
 	return nil
 }
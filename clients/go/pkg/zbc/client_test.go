@@ -16,20 +16,28 @@ package zbc
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/suite"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
 	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
 )
 
@@ -206,6 +214,213 @@ func (s *clientTestSuite) TestClientWithDefaultRootCa() {
 	}
 }
 
+func (s *clientTestSuite) TestClientWithRootCAs() {
+	// given
+	lis, grpcServer := createSecureServer()
+
+	go grpcServer.Serve(lis)
+	defer func() {
+		grpcServer.Stop()
+		_ = lis.Close()
+	}()
+
+	certificate, err := ioutil.ReadFile("testdata/chain.cert.pem")
+	s.NoError(err)
+	rootCAs := x509.NewCertPool()
+	s.True(rootCAs.AppendCertsFromPEM(certificate))
+
+	parts := strings.Split(lis.Addr().String(), ":")
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress: fmt.Sprintf("0.0.0.0:%s", parts[len(parts)-1]),
+		RootCAs:        rootCAs,
+	})
+
+	s.NoError(err)
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Error(err)
+	if grpcStatus, ok := status.FromError(err); ok {
+		s.EqualValues(codes.Unimplemented, grpcStatus.Code())
+	}
+}
+
+func (s *clientTestSuite) TestClientWithClientCertificate() {
+	// given
+	lis, grpcServer := createMutualTLSServer()
+
+	go grpcServer.Serve(lis)
+	defer func() {
+		grpcServer.Stop()
+		_ = lis.Close()
+	}()
+
+	parts := strings.Split(lis.Addr().String(), ":")
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:        fmt.Sprintf("0.0.0.0:%s", parts[len(parts)-1]),
+		CaCertificatePath:     "testdata/chain.cert.pem",
+		ClientCertificatePath: "testdata/chain.cert.pem",
+		ClientKeyPath:         "testdata/private.key.pem",
+	})
+
+	s.NoError(err)
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Error(err)
+	if grpcStatus, ok := status.FromError(err); ok {
+		s.EqualValues(codes.Unimplemented, grpcStatus.Code())
+	}
+}
+
+func (s *clientTestSuite) TestClientWithoutClientCertificate() {
+	// given
+	lis, grpcServer := createMutualTLSServer()
+
+	go grpcServer.Serve(lis)
+	defer func() {
+		grpcServer.Stop()
+		_ = lis.Close()
+	}()
+
+	parts := strings.Split(lis.Addr().String(), ":")
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:    fmt.Sprintf("0.0.0.0:%s", parts[len(parts)-1]),
+		CaCertificatePath: "testdata/chain.cert.pem",
+	})
+
+	s.NoError(err)
+
+	// when
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = client.NewTopologyCommand().Send(ctx)
+
+	// then
+	s.Error(err)
+	s.NotEqual(codes.Unimplemented, status.Code(err))
+}
+
+func (s *clientTestSuite) TestClientWithInvalidClientCertificate() {
+	// when
+	_, err := NewClient(&ClientConfig{
+		GatewayAddress:        "0.0.0.0:26500",
+		ClientCertificatePath: "testdata/chain.cert.pem",
+		ClientKeyPath:         "non.existing",
+	})
+
+	// then
+	s.Error(err)
+}
+
+func (s *clientTestSuite) TestClientWithUnixSocketAddress() {
+	// given
+	dir, err := ioutil.TempDir("", "zeebe")
+	s.NoError(err)
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "gateway.sock")
+	lis, err := net.Listen("unix", socket)
+	s.NoError(err)
+	grpcServer := grpc.NewServer()
+	pb.RegisterGatewayServer(grpcServer, &pb.UnimplementedGatewayServer{})
+
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         UnixSocketAddressPrefix + socket,
+		UsePlaintextConnection: true,
+	})
+	s.NoError(err)
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Error(err)
+	s.EqualValues(codes.Unimplemented, status.Code(err))
+}
+
+func (s *clientTestSuite) TestClientWithDialer() {
+	// given
+	lis, grpcServer := createServer()
+
+	go grpcServer.Serve(lis)
+	defer func() {
+		grpcServer.Stop()
+		_ = lis.Close()
+	}()
+
+	var lock sync.Mutex
+	var dialedAddress string
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         "in-process",
+		UsePlaintextConnection: true,
+		Dialer: func(ctx context.Context, address string) (net.Conn, error) {
+			lock.Lock()
+			dialedAddress = address
+			lock.Unlock()
+			var d net.Dialer
+			return d.DialContext(ctx, "tcp", lis.Addr().String())
+		},
+	})
+	s.NoError(err)
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Error(err)
+	s.EqualValues(codes.Unimplemented, status.Code(err))
+	lock.Lock()
+	defer lock.Unlock()
+	s.Equal("in-process", dialedAddress)
+}
+
+func (s *clientTestSuite) TestClientWithConnectionStateListener() {
+	// given
+	lis, grpcServer := createServer()
+
+	go grpcServer.Serve(lis)
+	defer func() {
+		grpcServer.Stop()
+		_ = lis.Close()
+	}()
+
+	states := make(chan connectivity.State, 10)
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		ConnectionStateListener: func(state connectivity.State) {
+			states <- state
+		},
+	})
+	s.NoError(err)
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+	s.EqualValues(codes.Unimplemented, status.Code(err))
+	s.Equal(connectivity.Ready, client.ConnectionState())
+	s.NoError(client.Close())
+
+	// then
+	var reported []connectivity.State
+	for len(reported) == 0 || reported[len(reported)-1] != connectivity.Shutdown {
+		select {
+		case state := <-states:
+			reported = append(reported, state)
+		case <-time.After(utils.DefaultTestTimeout):
+			s.FailNow("expected connection to be shut down", "reported states: %v", reported)
+		}
+	}
+	s.Contains(reported, connectivity.Ready)
+}
+
 func (s *clientTestSuite) TestClientWithPathToNonExistingFile() {
 	// given
 	lis, grpcServer := createSecureServer()
@@ -317,6 +532,21 @@ func (s *clientTestSuite) TestRejectNegativeDuration() {
 	s.Error(err)
 }
 
+func (s *clientTestSuite) TestRejectNegativeKeepAliveTimeout() {
+	// given
+	config := &ClientConfig{
+		GatewayAddress:         fmt.Sprintf("0.0.0.0:0"),
+		UsePlaintextConnection: true,
+		KeepAliveTimeout:       -5 * time.Second,
+	}
+
+	// when
+	_, err := NewClient(config)
+
+	// then
+	s.Error(err)
+}
+
 func (s *clientTestSuite) TestRejectNegativeDurationAsEnvVar() {
 	// given
 	env.set(KeepAliveEnvVar, "-100")
@@ -406,6 +636,20 @@ func createSecureServer() (net.Listener, *grpc.Server) {
 	return createServer(grpc.Creds(creds))
 }
 
+func createMutualTLSServer() (net.Listener, *grpc.Server) {
+	certificate, _ := tls.LoadX509KeyPair("testdata/chain.cert.pem", "testdata/private.key.pem")
+	pem, _ := ioutil.ReadFile("testdata/chain.cert.pem")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(pem)
+
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	return createServer(grpc.Creds(creds))
+}
+
 func createServer(opts ...grpc.ServerOption) (net.Listener, *grpc.Server) {
 	lis, _ := net.Listen("tcp", "0.0.0.0:0")
 	grpcServer := grpc.NewServer(opts...)
@@ -0,0 +1,39 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CommandMetrics is used to observe the commands sent by the client, e.g. to export them to a metrics backend.
+type CommandMetrics interface {
+	// Observe how long a command took until the gateway responded, and the status code of the response
+	ObserveCommandLatency(command string, code codes.Code, duration time.Duration)
+}
+
+func commandMetricsInterceptor(metrics CommandMetrics) CommandInterceptor {
+	return func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+		start := time.Now()
+		err := invoker(ctx, request, response)
+		metrics.ObserveCommandLatency(info.Name, status.Code(err), time.Since(start))
+
+		return err
+	}
+}
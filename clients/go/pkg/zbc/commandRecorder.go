@@ -0,0 +1,159 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// RedactedValue replaces the values of variables which are redacted by RedactVariables.
+const RedactedValue = "<redacted>"
+
+// RecordedCommand is a unary command which was sent by the client with a CommandRecorder, e.g. to replay it against a
+// test cluster with the replay package. The request and response are kept in the binary protobuf encoding, so they
+// are replayed exactly.
+type RecordedCommand struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	// Code is the status code of the outcome, e.g. 'OK' or 'NotFound'
+	Code     string `json:"code"`
+	Request  []byte `json:"request"`
+	Response []byte `json:"response,omitempty"`
+}
+
+// VariablesSanitizer returns the variables of a recorded command, e.g. with personal data removed. It is called with
+// the name of the command and the JSON document of the variables of its request or response.
+type VariablesSanitizer func(command, variables string) string
+
+// RedactVariables returns a VariablesSanitizer which replaces the values of the top-level variables with the names
+// by RedactedValue, or of all variables if no names are given. Variables which are no JSON object are replaced by an
+// empty object.
+func RedactVariables(names ...string) VariablesSanitizer {
+	redacted := make(map[string]bool, len(names))
+	for _, name := range names {
+		redacted[name] = true
+	}
+
+	return func(_, variables string) string {
+		var document map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(variables), &document); err != nil {
+			return "{}"
+		}
+
+		value, _ := json.Marshal(RedactedValue)
+		for name := range document {
+			if len(redacted) == 0 || redacted[name] {
+				document[name] = value
+			}
+		}
+		sanitized, _ := json.Marshal(document)
+		return string(sanitized)
+	}
+}
+
+// ReadRecordedCommands reads the commands which were written by a CommandRecorder, one JSON document per line.
+func ReadRecordedCommands(reader io.Reader) ([]RecordedCommand, error) {
+	var recorded []RecordedCommand
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var command RecordedCommand
+		if err := json.Unmarshal(scanner.Bytes(), &command); err != nil {
+			return nil, fmt.Errorf("failed to read recorded command in line %d: %w", line, err)
+		}
+		recorded = append(recorded, command)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recorded commands: %w", err)
+	}
+	return recorded, nil
+}
+
+type commandRecorder struct {
+	lock     sync.Mutex
+	encoder  *json.Encoder
+	sanitize VariablesSanitizer
+}
+
+func newCommandRecorder(writer io.Writer, sanitize VariablesSanitizer) *commandRecorder {
+	return &commandRecorder{encoder: json.NewEncoder(writer), sanitize: sanitize}
+}
+
+// interceptor records every command which changes the state of the broker after it finished, i.e. all commands but
+// the read-only ones like Topology.
+func (r *commandRecorder) interceptor(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+	start := time.Now()
+	err := invoker(ctx, request, response)
+	if readOnlyCommands[info.Name] {
+		return err
+	}
+
+	requestMessage, ok := request.(proto.Message)
+	if !ok {
+		return err
+	}
+
+	recorded := RecordedCommand{Time: start, Command: info.Name, Code: status.Code(err).String()}
+	recorded.Request, _ = proto.Marshal(r.sanitized(info.Name, requestMessage))
+	if responseMessage, ok := response.(proto.Message); ok && err == nil {
+		recorded.Response, _ = proto.Marshal(r.sanitized(info.Name, responseMessage))
+	}
+
+	r.lock.Lock()
+	_ = r.encoder.Encode(recorded)
+	r.lock.Unlock()
+	return err
+}
+
+// sanitized returns a copy of the message whose variables are sanitized, or the message if there is no sanitizer.
+func (r *commandRecorder) sanitized(command string, message proto.Message) proto.Message {
+	if r.sanitize == nil {
+		return message
+	}
+
+	sanitized := proto.Clone(message)
+	r.sanitizeMessage(command, sanitized.ProtoReflect())
+	return sanitized
+}
+
+func (r *commandRecorder) sanitizeMessage(command string, message protoreflect.Message) {
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		switch {
+		case field.Kind() == protoreflect.StringKind && field.Cardinality() != protoreflect.Repeated && field.JSONName() == "variables":
+			message.Set(field, protoreflect.ValueOfString(r.sanitize(command, value.String())))
+		case field.Kind() == protoreflect.MessageKind && field.IsList():
+			for i := 0; i < value.List().Len(); i++ {
+				r.sanitizeMessage(command, value.List().Get(i).Message())
+			}
+		case field.Kind() == protoreflect.MessageKind && !field.IsMap():
+			r.sanitizeMessage(command, value.Message())
+		}
+		return true
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func TestCommandRecorderRecordsCommands(t *testing.T) {
+	// given
+	lis, server := createServerWithInterceptor(func(_ context.Context, _ interface{}, info *grpc.UnaryServerInfo, _ grpc.UnaryHandler) (interface{}, error) {
+		switch info.FullMethod {
+		case "/gateway_protocol.Gateway/CreateWorkflowInstance":
+			return &pb.CreateWorkflowInstanceResponse{WorkflowKey: 1, BpmnProcessId: "order-process", WorkflowInstanceKey: 2}, nil
+		case "/gateway_protocol.Gateway/Topology":
+			return &pb.TopologyResponse{}, nil
+		}
+		return nil, status.Error(codes.NotFound, "job not found")
+	})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	var buffer bytes.Buffer
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:           lis.Addr().String(),
+		UsePlaintextConnection:   true,
+		CommandRecorder:          &buffer,
+		CommandRecorderSanitizer: RedactVariables("email"),
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	create, err := client.NewCreateInstanceCommand().BPMNProcessId("order-process").LatestVersion().VariablesFromString(`{"orderId":"DE-42","email":"jane@example.com"}`)
+	require.NoError(t, err)
+	_, err = create.Send(ctx)
+	require.NoError(t, err)
+	_, err = client.NewTopologyCommand().Send(ctx)
+	require.NoError(t, err)
+	_, err = client.NewCompleteJobCommand().JobKey(123).Send(ctx)
+	require.Error(t, err)
+
+	// then
+	recorded, err := ReadRecordedCommands(&buffer)
+	require.NoError(t, err)
+	require.Len(t, recorded, 2)
+
+	require.Equal(t, "CreateWorkflowInstance", recorded[0].Command)
+	require.Equal(t, "OK", recorded[0].Code)
+	var request pb.CreateWorkflowInstanceRequest
+	require.NoError(t, proto.Unmarshal(recorded[0].Request, &request))
+	require.Equal(t, "order-process", request.BpmnProcessId)
+	require.JSONEq(t, `{"orderId":"DE-42","email":"<redacted>"}`, request.Variables)
+	var response pb.CreateWorkflowInstanceResponse
+	require.NoError(t, proto.Unmarshal(recorded[0].Response, &response))
+	require.EqualValues(t, 2, response.WorkflowInstanceKey)
+
+	require.Equal(t, "CompleteJob", recorded[1].Command)
+	require.Equal(t, "NotFound", recorded[1].Code)
+	require.Empty(t, recorded[1].Response)
+}
+
+func TestRedactAllVariables(t *testing.T) {
+	sanitize := RedactVariables()
+
+	var variables map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(sanitize("CompleteJob", `{"a":1,"b":{"c":2}}`)), &variables))
+	require.Equal(t, map[string]interface{}{"a": RedactedValue, "b": RedactedValue}, variables)
+	require.Equal(t, "{}", sanitize("CompleteJob", "not json"))
+}
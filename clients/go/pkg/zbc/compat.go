@@ -0,0 +1,153 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+// compatRule adapts the requests of a command to gateways which don't support a feature.
+type compatRule struct {
+	command string
+	feature Feature
+	// consequence describes how the command behaves without the feature
+	consequence string
+	// adapt removes the fields of the feature from the request and returns whether any were set
+	adapt func(request proto.Message) bool
+}
+
+// compatRules are the adaptations of commands to older gateways, which ignore unknown fields or reject them.
+var compatRules = []compatRule{
+	{
+		command:     "ActivateJobs",
+		feature:     FeatureLongPolling,
+		consequence: "the request timeout is not sent, so jobs are polled without long polling",
+		adapt: func(request proto.Message) bool {
+			activate, ok := request.(*pb.ActivateJobsRequest)
+			if !ok || activate.RequestTimeout == 0 {
+				return false
+			}
+			activate.RequestTimeout = 0
+			return true
+		},
+	},
+}
+
+// gatewayCompatibility adapts commands to the version of the gateway, e.g. while the gateways are upgraded one by one
+// and some still run the previous version. Instead of failing, it logs a warning once per feature and gateway version.
+type gatewayCompatibility struct {
+	capabilities *gatewayCapabilities
+	logger       logging.Logger
+
+	lock   sync.Mutex
+	warned map[string]bool
+}
+
+func newGatewayCompatibility(capabilities *gatewayCapabilities, logger logging.Logger) *gatewayCompatibility {
+	return &gatewayCompatibility{capabilities: capabilities, logger: logger, warned: make(map[string]bool)}
+}
+
+// adapt returns the request to send to the gateway, which is a copy of the request if any fields were removed. If
+// the gateway version can't be queried, the request is sent as is.
+func (c *gatewayCompatibility) adapt(ctx context.Context, command string, request interface{}) interface{} {
+	message, ok := request.(proto.Message)
+	if !ok || !needsCompatibility(command) {
+		return request
+	}
+
+	version, err := c.capabilities.gatewayVersion(ctx)
+	if err != nil {
+		return request
+	}
+
+	adapted := message
+	for _, rule := range compatRules {
+		if rule.command != command || SupportsFeature(version, rule.feature) {
+			continue
+		}
+
+		if adapted == message {
+			adapted = proto.Clone(message)
+		}
+		if rule.adapt(adapted) {
+			c.warn(version, rule.feature, fmt.Sprintf("Gateway version %s doesn't support %s of %s; %s", version, rule.feature, command, rule.consequence))
+		}
+	}
+
+	if feature, ok := commandFeatures[command]; ok && !SupportsFeature(version, feature) {
+		c.warn(version, feature, fmt.Sprintf("Gateway version %s doesn't support %s; the command is sent anyway and may be rejected", version, command))
+	}
+	return adapted
+}
+
+// needsCompatibility returns whether the command depends on the gateway version, which excludes the topology requests
+// which query the version.
+func needsCompatibility(command string) bool {
+	if _, ok := commandFeatures[command]; ok {
+		return true
+	}
+	for _, rule := range compatRules {
+		if rule.command == command {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *gatewayCompatibility) warn(version string, feature Feature, msg string) {
+	key := version + "/" + string(feature)
+
+	c.lock.Lock()
+	warned := c.warned[key]
+	c.warned[key] = true
+	c.lock.Unlock()
+
+	if !warned {
+		c.logger.Warn(msg, "gatewayVersion", version, "feature", feature)
+	}
+}
+
+func (c *gatewayCompatibility) interceptor(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+	return invoker(ctx, c.adapt(ctx, info.Name, request), response)
+}
+
+func (c *gatewayCompatibility) streamInterceptor(ctx context.Context, info CommandInfo, streamer CommandStreamer) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &compatClientStream{ClientStream: stream, ctx: ctx, command: info.Name, compat: c}, nil
+}
+
+// compatClientStream adapts the request of a streaming command, which is sent with SendMsg after the stream is opened.
+type compatClientStream struct {
+	grpc.ClientStream
+	ctx     context.Context
+	command string
+	compat  *gatewayCompatibility
+}
+
+func (s *compatClientStream) SendMsg(m interface{}) error {
+	return s.ClientStream.SendMsg(s.compat.adapt(s.ctx, s.command, m))
+}
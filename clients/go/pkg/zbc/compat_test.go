@@ -0,0 +1,146 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type compatGateway struct {
+	pb.UnimplementedGatewayServer
+	version string
+
+	lock     sync.Mutex
+	activate []*pb.ActivateJobsRequest
+}
+
+func (g *compatGateway) Topology(context.Context, *pb.TopologyRequest) (*pb.TopologyResponse, error) {
+	return &pb.TopologyResponse{GatewayVersion: g.version}, nil
+}
+
+func (g *compatGateway) ActivateJobs(request *pb.ActivateJobsRequest, _ pb.Gateway_ActivateJobsServer) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.activate = append(g.activate, request)
+	return nil
+}
+
+func (g *compatGateway) activateRequests() []*pb.ActivateJobsRequest {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return append([]*pb.ActivateJobsRequest(nil), g.activate...)
+}
+
+func (g *compatGateway) ThrowError(context.Context, *pb.ThrowErrorRequest) (*pb.ThrowErrorResponse, error) {
+	return &pb.ThrowErrorResponse{}, nil
+}
+
+func startCompatGateway(t *testing.T, version string, out *bytes.Buffer) (*compatGateway, Client, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	gateway := &compatGateway{version: version}
+	server := grpc.NewServer()
+	pb.RegisterGatewayServer(server, gateway)
+	go server.Serve(listener)
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         listener.Addr().String(),
+		UsePlaintextConnection: true,
+		GatewayCompatibility:   true,
+		Logger:                 logging.NewStdLogger(log.New(out, "", 0), logging.LevelWarn),
+	})
+	require.NoError(t, err)
+
+	return gateway, client, func() {
+		_ = client.Close()
+		server.Stop()
+	}
+}
+
+func activateWithLongPolling(t *testing.T, client Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	_, err := client.NewActivateJobsCommand().JobType("payment").MaxJobsToActivate(1).RequestTimeout(time.Second).Send(ctx)
+	require.NoError(t, err)
+}
+
+func TestCompatibilityRemovesUnsupportedFields(t *testing.T) {
+	// given
+	var out bytes.Buffer
+	gateway, client, stop := startCompatGateway(t, "0.21.1", &out)
+	defer stop()
+
+	// when
+	activateWithLongPolling(t, client)
+	activateWithLongPolling(t, client)
+
+	// then
+	require.Len(t, gateway.activateRequests(), 2)
+	for _, request := range gateway.activateRequests() {
+		require.Equal(t, "payment", request.Type)
+		require.Zero(t, request.RequestTimeout)
+	}
+	require.Equal(t, 1, strings.Count(out.String(), "doesn't support LongPolling of ActivateJobs"), out.String())
+}
+
+func TestCompatibilityKeepsSupportedFields(t *testing.T) {
+	// given
+	var out bytes.Buffer
+	gateway, client, stop := startCompatGateway(t, "0.22.0", &out)
+	defer stop()
+
+	// when
+	activateWithLongPolling(t, client)
+
+	// then
+	require.Len(t, gateway.activateRequests(), 1)
+	require.NotZero(t, gateway.activateRequests()[0].RequestTimeout)
+	require.Empty(t, out.String())
+}
+
+func TestCompatibilityWarnsAboutUnsupportedCommand(t *testing.T) {
+	// given
+	var out bytes.Buffer
+	_, client, stop := startCompatGateway(t, "0.21.1", &out)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	_, err := client.NewThrowErrorCommand().JobKey(1).ErrorCode("payment-failed").Send(ctx)
+
+	// then
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "Gateway version 0.21.1 doesn't support ThrowError")
+}
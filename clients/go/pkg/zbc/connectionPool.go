@@ -0,0 +1,78 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// connectionPool distributes the calls of the client round robin across several connections to the gateway, since
+// the concurrent streams of a single HTTP/2 connection limit the throughput at high command rates.
+type connectionPool struct {
+	conns []*grpc.ClientConn
+	next  uint32
+}
+
+func dialConnectionPool(config *ClientConfig, target string) (*connectionPool, error) {
+	if config.ConnectionPoolSize < 0 {
+		return nil, errors.New("connection pool size must not be negative")
+	}
+
+	size := config.ConnectionPoolSize
+	if size == 0 {
+		size = 1
+	}
+
+	pool := &connectionPool{conns: make([]*grpc.ClientConn, 0, size)}
+	for i := 0; i < size; i++ {
+		conn, err := grpc.Dial(target, config.DialOpts...)
+		if err != nil {
+			_ = pool.Close()
+			return nil, err
+		}
+		pool.conns = append(pool.conns, conn)
+	}
+
+	return pool, nil
+}
+
+func (p *connectionPool) pick() *grpc.ClientConn {
+	if len(p.conns) == 1 {
+		return p.conns[0]
+	}
+	return p.conns[atomic.AddUint32(&p.next, 1)%uint32(len(p.conns))]
+}
+
+func (p *connectionPool) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	return p.pick().Invoke(ctx, method, args, reply, opts...)
+}
+
+func (p *connectionPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.pick().NewStream(ctx, desc, method, opts...)
+}
+
+func (p *connectionPool) Close() error {
+	var err error
+	for _, conn := range p.conns {
+		if closeErr := conn.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
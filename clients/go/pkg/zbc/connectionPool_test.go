@@ -0,0 +1,89 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type connectionPoolTestSuite struct {
+	*envSuite
+}
+
+func TestConnectionPoolSuite(t *testing.T) {
+	suite.Run(t, &connectionPoolTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *connectionPoolTestSuite) TestDistributeCommandsAcrossConnections() {
+	// given
+	var mutex sync.Mutex
+	callsByPeer := make(map[string]int)
+	lis, server := createServerWithInterceptor(func(ctx context.Context, _ interface{}, _ *grpc.UnaryServerInfo, _ grpc.UnaryHandler) (interface{}, error) {
+		if p, ok := peer.FromContext(ctx); ok {
+			mutex.Lock()
+			callsByPeer[p.Addr.String()]++
+			mutex.Unlock()
+		}
+		return &pb.TopologyResponse{}, nil
+	})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		ConnectionPoolSize:     2,
+	})
+	s.Require().NoError(err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	for i := 0; i < 4; i++ {
+		_, err = client.NewTopologyCommand().Send(ctx)
+		s.Require().NoError(err)
+	}
+
+	// then
+	mutex.Lock()
+	defer mutex.Unlock()
+	s.Len(callsByPeer, 2)
+	for _, calls := range callsByPeer {
+		s.Equal(2, calls)
+	}
+}
+
+func (s *connectionPoolTestSuite) TestRejectNegativeConnectionPoolSize() {
+	// when
+	_, err := NewClient(&ClientConfig{
+		GatewayAddress:         "localhost:26500",
+		UsePlaintextConnection: true,
+		ConnectionPoolSize:     -1,
+	})
+
+	// then
+	s.Error(err)
+}
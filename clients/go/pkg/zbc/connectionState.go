@@ -0,0 +1,40 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// ConnectionStateListener is called with the state of the connection to the gateway whenever it changes, e.g. to
+// flip a readiness probe. It is called from a single goroutine, for the last time with connectivity.Shutdown after the
+// client is closed.
+type ConnectionStateListener func(state connectivity.State)
+
+func watchConnectionState(conn *grpc.ClientConn, listener ConnectionStateListener) {
+	state := conn.GetState()
+	for {
+		listener(state)
+		if state == connectivity.Shutdown {
+			return
+		}
+
+		conn.WaitForStateChange(context.Background(), state)
+		state = conn.GetState()
+	}
+}
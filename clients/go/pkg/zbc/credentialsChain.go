@@ -0,0 +1,161 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const ErrNoCredentialsProvider = Error("expected at least one credentials provider in the chain")
+
+// CredentialsProviderChain is a CredentialsProvider which tries its providers in order. The first provider which applies
+// its credentials successfully is used for all following calls. If the gateway rejects the credentials of that provider
+// and the provider cannot refresh them, the chain falls back to the next provider.
+type CredentialsProviderChain struct {
+	providers []CredentialsProvider
+
+	lock   sync.Mutex
+	active int
+}
+
+// NewCredentialsProviderChain creates a chain of the given providers, e.g. a static token for local development
+// followed by an OAuthCredentialsProvider for SaaS.
+func NewCredentialsProviderChain(providers ...CredentialsProvider) (*CredentialsProviderChain, error) {
+	if len(providers) == 0 {
+		return nil, ErrNoCredentialsProvider
+	}
+
+	return &CredentialsProviderChain{providers: providers}, nil
+}
+
+// ApplyCredentials applies the credentials of the first provider, starting with the active one, which succeeds.
+func (c *CredentialsProviderChain) ApplyCredentials(ctx context.Context, headers map[string]string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var failures []string
+	for i := c.active; i < len(c.providers); i++ {
+		if err := c.providers[i].ApplyCredentials(ctx, headers); err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+
+		c.active = i
+		return nil
+	}
+
+	c.active = 0
+	return status.Errorf(codes.Canceled, "failed to apply credentials of any provider: %s", strings.Join(failures, "; "))
+}
+
+// ShouldRetryRequest asks the active provider whether to retry. If it does not want to retry an UNAUTHENTICATED error,
+// the chain moves on to the next provider and retries with its credentials.
+func (c *CredentialsProviderChain) ShouldRetryRequest(ctx context.Context, err error) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.providers[c.active].ShouldRetryRequest(ctx, err) {
+		return true
+	}
+
+	if status.Code(err) == codes.Unauthenticated && c.active+1 < len(c.providers) {
+		c.active++
+		return true
+	}
+
+	return false
+}
+
+// StaticCredentialsProvider is a CredentialsProvider which sets a fixed bearer token as 'Authorization' header.
+type StaticCredentialsProvider struct {
+	Token string
+}
+
+// ApplyCredentials sets the token as 'Authorization' header, or fails if the token is empty.
+func (p *StaticCredentialsProvider) ApplyCredentials(_ context.Context, headers map[string]string) error {
+	if p.Token == "" {
+		return fmt.Errorf("expected to find non-empty static token")
+	}
+
+	headers["Authorization"] = "Bearer " + p.Token
+	return nil
+}
+
+// ShouldRetryRequest always returns false, as the token cannot be refreshed.
+func (*StaticCredentialsProvider) ShouldRetryRequest(_ context.Context, _ error) bool {
+	return false
+}
+
+// FileCredentialsProvider is a CredentialsProvider which reads a bearer token from a file, e.g. a token mounted by a
+// secret manager. The file is read for every call, so tokens rotated on disk are picked up.
+type FileCredentialsProvider struct {
+	Path string
+
+	lock  sync.Mutex
+	token string
+}
+
+// ApplyCredentials reads the token from the file and sets it as 'Authorization' header.
+func (p *FileCredentialsProvider) ApplyCredentials(_ context.Context, headers map[string]string) error {
+	token, err := p.readToken()
+	if err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	p.token = token
+	p.lock.Unlock()
+
+	headers["Authorization"] = "Bearer " + token
+	return nil
+}
+
+// ShouldRetryRequest checks if the error is UNAUTHENTICATED and, if so, returns true if the token in the file changed
+// since it was last applied.
+func (p *FileCredentialsProvider) ShouldRetryRequest(_ context.Context, err error) bool {
+	if status.Code(err) != codes.Unauthenticated {
+		return false
+	}
+
+	token, err := p.readToken()
+	if err != nil {
+		return false
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return token != p.token
+}
+
+func (p *FileCredentialsProvider) readToken() (string, error) {
+	contents, err := ioutil.ReadFile(p.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return "", fmt.Errorf("expected to find non-empty token in file '%s'", p.Path)
+	}
+
+	return token, nil
+}
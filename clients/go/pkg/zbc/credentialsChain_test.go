@@ -0,0 +1,107 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCredentialsProviderChainFallsBackOnApplyFailure(t *testing.T) {
+	// given
+	chain, err := NewCredentialsProviderChain(
+		&FileCredentialsProvider{Path: "does-not-exist"},
+		&StaticCredentialsProvider{Token: accessToken},
+	)
+	require.NoError(t, err)
+	headers := map[string]string{}
+
+	// when
+	err = chain.ApplyCredentials(context.Background(), headers)
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, "Bearer "+accessToken, headers["Authorization"])
+}
+
+func TestCredentialsProviderChainFallsBackOnUnauthenticated(t *testing.T) {
+	// given
+	chain, err := NewCredentialsProviderChain(
+		&StaticCredentialsProvider{Token: "rejectedToken"},
+		&StaticCredentialsProvider{Token: accessToken},
+	)
+	require.NoError(t, err)
+	require.NoError(t, chain.ApplyCredentials(context.Background(), map[string]string{}))
+
+	// when
+	retry := chain.ShouldRetryRequest(context.Background(), status.Error(codes.Unauthenticated, "expected"))
+
+	// then
+	require.True(t, retry)
+	headers := map[string]string{}
+	require.NoError(t, chain.ApplyCredentials(context.Background(), headers))
+	require.Equal(t, "Bearer "+accessToken, headers["Authorization"])
+	require.False(t, chain.ShouldRetryRequest(context.Background(), status.Error(codes.Unauthenticated, "expected")))
+}
+
+func TestCredentialsProviderChainFailsIfAllProvidersFail(t *testing.T) {
+	// given
+	chain, err := NewCredentialsProviderChain(&StaticCredentialsProvider{}, &FileCredentialsProvider{Path: "does-not-exist"})
+	require.NoError(t, err)
+
+	// when
+	err = chain.ApplyCredentials(context.Background(), map[string]string{})
+
+	// then
+	require.Error(t, err)
+	require.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestCredentialsProviderChainWithoutProviders(t *testing.T) {
+	// when
+	_, err := NewCredentialsProviderChain()
+
+	// then
+	require.Equal(t, ErrNoCredentialsProvider, err)
+}
+
+func TestFileCredentialsProviderRetriesWithRotatedToken(t *testing.T) {
+	// given
+	file, err := ioutil.TempFile("", "token")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	require.NoError(t, ioutil.WriteFile(file.Name(), []byte("oldToken\n"), 0600))
+
+	provider := &FileCredentialsProvider{Path: file.Name()}
+	headers := map[string]string{}
+	require.NoError(t, provider.ApplyCredentials(context.Background(), headers))
+	require.Equal(t, "Bearer oldToken", headers["Authorization"])
+	unauthenticated := status.Error(codes.Unauthenticated, "expected")
+	require.False(t, provider.ShouldRetryRequest(context.Background(), unauthenticated))
+
+	// when
+	require.NoError(t, ioutil.WriteFile(file.Name(), []byte(accessToken), 0600))
+
+	// then
+	require.True(t, provider.ShouldRetryRequest(context.Background(), unauthenticated))
+	require.NoError(t, provider.ApplyCredentials(context.Background(), headers))
+	require.Equal(t, "Bearer "+accessToken, headers["Authorization"])
+}
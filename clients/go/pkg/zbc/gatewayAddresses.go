@@ -0,0 +1,71 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer/roundrobin"
+	"google.golang.org/grpc/resolver"
+)
+
+const gatewayAddressesScheme = "zeebe-gateways"
+
+// configureGatewayAddresses returns the target to dial. If multiple gateway addresses are configured, the target is
+// resolved to all of them and calls are balanced round robin across the gateways which are connected, unless another
+// LoadBalancingPolicy is configured; gateways which can't be reached are skipped until they are reconnected.
+func configureGatewayAddresses(config *ClientConfig) string {
+	if len(config.GatewayAddresses) == 0 {
+		return config.GatewayAddress
+	}
+	if config.GatewayAddress == "" {
+		config.GatewayAddress = config.GatewayAddresses[0]
+	}
+
+	addresses := make([]resolver.Address, len(config.GatewayAddresses))
+	for i, address := range config.GatewayAddresses {
+		addresses[i] = resolver.Address{Addr: address}
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			addresses[i].ServerName = host
+		}
+	}
+
+	if config.LoadBalancingPolicy == "" {
+		config.LoadBalancingPolicy = roundrobin.Name
+	}
+	config.DialOpts = append(config.DialOpts, grpc.WithResolvers(staticResolverBuilder{addresses: addresses}))
+	return gatewayAddressesScheme + ":///gateways"
+}
+
+// staticResolverBuilder resolves every target to a fixed list of addresses
+type staticResolverBuilder struct {
+	addresses []resolver.Address
+}
+
+func (b staticResolverBuilder) Build(_ resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	cc.UpdateState(resolver.State{Addresses: b.addresses})
+	return staticResolver{}, nil
+}
+
+func (b staticResolverBuilder) Scheme() string {
+	return gatewayAddressesScheme
+}
+
+type staticResolver struct{}
+
+func (staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (staticResolver) Close() {}
@@ -0,0 +1,124 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type gatewayAddressesTestSuite struct {
+	*envSuite
+}
+
+func TestGatewayAddressesSuite(t *testing.T) {
+	suite.Run(t, &gatewayAddressesTestSuite{envSuite: new(envSuite)})
+}
+
+func createCountingServer(counter *int32) (string, *grpc.Server) {
+	lis, server := createServerWithInterceptor(func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error) {
+		atomic.AddInt32(counter, 1)
+		return &pb.TopologyResponse{}, nil
+	})
+	go server.Serve(lis)
+	return lis.Addr().String(), server
+}
+
+func (s *gatewayAddressesTestSuite) TestBalanceAcrossGateways() {
+	// given
+	var first, second int32
+	firstAddress, firstServer := createCountingServer(&first)
+	defer firstServer.Stop()
+	secondAddress, secondServer := createCountingServer(&second)
+	defer secondServer.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddresses:       []string{firstAddress, secondAddress},
+		UsePlaintextConnection: true,
+	})
+	s.Require().NoError(err)
+	defer client.Close()
+
+	// when
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// then
+	s.Eventually(func() bool {
+		if _, err := client.NewTopologyCommand().Send(ctx); err != nil {
+			return false
+		}
+		return atomic.LoadInt32(&first) > 0 && atomic.LoadInt32(&second) > 0
+	}, utils.DefaultTestTimeout, time.Millisecond)
+}
+
+func (s *gatewayAddressesTestSuite) TestFailOverToConnectedGateway() {
+	// given
+	var first, second int32
+	firstAddress, firstServer := createCountingServer(&first)
+	secondAddress, secondServer := createCountingServer(&second)
+	defer secondServer.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddresses:       []string{firstAddress, secondAddress},
+		UsePlaintextConnection: true,
+	})
+	s.Require().NoError(err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+	_, err = client.NewTopologyCommand().Send(ctx)
+	s.Require().NoError(err)
+
+	// when
+	firstServer.Stop()
+
+	// then
+	s.Eventually(func() bool {
+		_, err := client.NewTopologyCommand().Send(ctx)
+		return err == nil
+	}, utils.DefaultTestTimeout, 10*time.Millisecond)
+
+	before := atomic.LoadInt32(&second)
+	for i := 0; i < 5; i++ {
+		_, err := client.NewTopologyCommand().Send(ctx)
+		s.Require().NoError(err)
+	}
+	s.EqualValues(before+5, atomic.LoadInt32(&second))
+}
+
+func (s *gatewayAddressesTestSuite) TestDefaultGatewayAddress() {
+	// given
+	config := &ClientConfig{
+		GatewayAddresses:       []string{"first:26500", "second:26500"},
+		UsePlaintextConnection: true,
+	}
+
+	// when
+	_, err := NewClient(config)
+
+	// then
+	s.NoError(err)
+	s.Equal("first:26500", config.GatewayAddress)
+}
@@ -0,0 +1,175 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+// ErrUnsupportedByGateway is returned, wrapped, by commands which need a feature the gateway doesn't support, before
+// they are sent. Use errors.Is to compare errors with it.
+const ErrUnsupportedByGateway = Error("unsupported by gateway")
+
+// Feature is a capability of the gateway which depends on its version.
+type Feature string
+
+const (
+	// FeatureThrowError is the ThrowError command, which throws BPMN errors from job workers
+	FeatureThrowError Feature = "ThrowError"
+	// FeatureCreateInstanceWithResult is the command which creates a workflow instance and awaits its result
+	FeatureCreateInstanceWithResult Feature = "CreateWorkflowInstanceWithResult"
+	// FeatureLongPolling is the request timeout of ActivateJobs, for which the gateway waits until jobs are available
+	FeatureLongPolling Feature = "LongPolling"
+	// FeatureTenants is the assignment of resources to tenants, which no gateway of this protocol supports
+	FeatureTenants Feature = "Tenants"
+	// FeatureJobStreaming is the streaming of jobs to workers, which no gateway of this protocol supports
+	FeatureJobStreaming Feature = "JobStreaming"
+)
+
+// unavailableVersion is the minimum version of features which aren't supported by any gateway of this protocol
+const unavailableVersion = ""
+
+// featureVersions are the gateway versions which introduced the features
+var featureVersions = map[Feature]string{
+	FeatureThrowError:               "0.22.0",
+	FeatureCreateInstanceWithResult: "0.22.0",
+	FeatureLongPolling:              "0.22.0",
+	FeatureTenants:                  unavailableVersion,
+	FeatureJobStreaming:             unavailableVersion,
+}
+
+// commandFeatures are the features which commands need, keyed by command name
+var commandFeatures = map[string]Feature{
+	"ThrowError":                       FeatureThrowError,
+	"CreateWorkflowInstanceWithResult": FeatureCreateInstanceWithResult,
+}
+
+// SupportsFeature returns whether a gateway of the version supports the feature. Versions which can't be parsed, e.g.
+// of development builds or of gateways which don't report their version, are assumed to support all features of this
+// protocol.
+func SupportsFeature(gatewayVersion string, feature Feature) bool {
+	required, ok := featureVersions[feature]
+	if !ok || required == unavailableVersion {
+		return false
+	}
+
+	version, ok := parseVersion(gatewayVersion)
+	if !ok {
+		return true
+	}
+	minimum, _ := parseVersion(required)
+	for i := range version {
+		if version[i] != minimum[i] {
+			return version[i] > minimum[i]
+		}
+	}
+	return true
+}
+
+// parseVersion returns the major, minor and patch version, ignoring pre-release suffixes like '-alpha2', so
+// pre-releases support the features of their release.
+func parseVersion(version string) ([3]int, bool) {
+	var parsed [3]int
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) != len(parsed) {
+		return parsed, false
+	}
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return parsed, false
+		}
+		parsed[i] = number
+	}
+	return parsed, true
+}
+
+// gatewayCapabilities caches the version of the gateway, which is queried with a topology request.
+type gatewayCapabilities struct {
+	topology func(ctx context.Context) (*pb.TopologyResponse, error)
+
+	lock    sync.Mutex
+	version string
+	known   bool
+}
+
+func (g *gatewayCapabilities) gatewayVersion(ctx context.Context) (string, error) {
+	g.lock.Lock()
+	if g.known {
+		defer g.lock.Unlock()
+		return g.version, nil
+	}
+	g.lock.Unlock()
+
+	response, err := g.topology(ctx)
+	if status.Code(err) == codes.Unimplemented {
+		// the gateway will never report its version
+		response, err = &pb.TopologyResponse{}, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	g.observe(response)
+	return response.GatewayVersion, nil
+}
+
+func (g *gatewayCapabilities) observe(response *pb.TopologyResponse) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.version = response.GatewayVersion
+	g.known = true
+}
+
+func (g *gatewayCapabilities) supports(ctx context.Context, feature Feature) (bool, error) {
+	version, err := g.gatewayVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return SupportsFeature(version, feature), nil
+}
+
+// interceptor observes the gateway version in topology responses and, if check is set, fails the commands which need
+// a feature which the gateway doesn't support. If the version can't be queried, the commands are sent anyway.
+func (g *gatewayCapabilities) interceptor(check bool) CommandInterceptor {
+	return func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+		if feature, ok := commandFeatures[info.Name]; ok && check {
+			if version, err := g.gatewayVersion(ctx); err == nil && !SupportsFeature(version, feature) {
+				return fmt.Errorf("%w: %s needs %s, which gateway version %s doesn't support", ErrUnsupportedByGateway, info.Name, feature, version)
+			}
+		}
+
+		err := invoker(ctx, request, response)
+		if topology, ok := response.(*pb.TopologyResponse); ok && err == nil {
+			g.observe(topology)
+		}
+		return err
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func TestSupportsFeature(t *testing.T) {
+	tests := []struct {
+		version string
+		feature Feature
+		want    bool
+	}{
+		{"0.21.1", FeatureThrowError, false},
+		{"0.22.0", FeatureThrowError, true},
+		{"0.22.0-alpha1", FeatureThrowError, true},
+		{"0.25.0-alpha2", FeatureCreateInstanceWithResult, true},
+		{"1.0.0", FeatureLongPolling, true},
+		{"development", FeatureThrowError, true},
+		{"", FeatureThrowError, true},
+		{"0.25.0", FeatureTenants, false},
+		{"", FeatureJobStreaming, false},
+		{"0.25.0", Feature("Unknown"), false},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, SupportsFeature(test.version, test.feature), "%s on %q", test.feature, test.version)
+	}
+}
+
+func startTopologyServer(version string, topologyCalls *int32) (Client, func()) {
+	lis, server := createServerWithInterceptor(func(_ context.Context, _ interface{}, info *grpc.UnaryServerInfo, _ grpc.UnaryHandler) (interface{}, error) {
+		switch info.FullMethod {
+		case "/gateway_protocol.Gateway/Topology":
+			atomic.AddInt32(topologyCalls, 1)
+			return &pb.TopologyResponse{GatewayVersion: version}, nil
+		case "/gateway_protocol.Gateway/ThrowError":
+			return &pb.ThrowErrorResponse{}, nil
+		default:
+			return nil, status.Error(codes.Unimplemented, info.FullMethod)
+		}
+	})
+	go server.Serve(lis)
+
+	client, _ := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		CheckGatewayFeatures:   true,
+	})
+	return client, func() {
+		_ = client.Close()
+		server.Stop()
+	}
+}
+
+func TestGatewayVersionIsQueriedOnce(t *testing.T) {
+	// given
+	var topologyCalls int32
+	client, stop := startTopologyServer("0.25.0", &topologyCalls)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	version, err := client.GatewayVersion(ctx)
+	require.NoError(t, err)
+	supported, err := client.Supports(ctx, FeatureThrowError)
+	require.NoError(t, err)
+	_, err = client.NewThrowErrorCommand().JobKey(1).ErrorCode("error").Send(ctx)
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, "0.25.0", version)
+	require.True(t, supported)
+	require.EqualValues(t, 1, atomic.LoadInt32(&topologyCalls))
+}
+
+func TestCommandUnsupportedByGatewayIsNotSent(t *testing.T) {
+	// given
+	var topologyCalls int32
+	client, stop := startTopologyServer("0.21.0", &topologyCalls)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	_, err := client.NewThrowErrorCommand().JobKey(1).ErrorCode("error").Send(ctx)
+
+	// then
+	require.True(t, errors.Is(err, ErrUnsupportedByGateway), "expected unsupported error, got %v", err)
+	supported, err := client.Supports(ctx, FeatureThrowError)
+	require.NoError(t, err)
+	require.False(t, supported)
+}
+
+func TestGatewayWithoutTopologySupportsFeatures(t *testing.T) {
+	// given
+	lis, server := createServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	client, err := NewClient(&ClientConfig{GatewayAddress: lis.Addr().String(), UsePlaintextConnection: true, CheckGatewayFeatures: true})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	_, err = client.NewThrowErrorCommand().JobKey(1).ErrorCode("error").Send(ctx)
+
+	// then
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+	version, err := client.GatewayVersion(ctx)
+	require.NoError(t, err)
+	require.Empty(t, version)
+}
@@ -0,0 +1,41 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+func configureGrpcCompression(config *ClientConfig) error {
+	if config.GrpcCompressionLevel != 0 {
+		if err := gzip.SetLevel(config.GrpcCompressionLevel); err != nil {
+			return err
+		}
+	}
+
+	if config.GrpcCompression == "" {
+		return nil
+	}
+	if encoding.GetCompressor(config.GrpcCompression) == nil {
+		return fmt.Errorf("grpc compressor '%s' is not registered", config.GrpcCompression)
+	}
+
+	config.DialOpts = append(config.DialOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor(config.GrpcCompression)))
+	return nil
+}
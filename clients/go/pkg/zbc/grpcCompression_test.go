@@ -0,0 +1,103 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+)
+
+// compressionRecorder records the compression of the requests received by the server
+type compressionRecorder struct {
+	compression chan string
+}
+
+func (r *compressionRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if header, ok := s.(*stats.InHeader); ok {
+		r.compression <- header.Compression
+	}
+}
+
+func (r *compressionRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+type grpcCompressionTestSuite struct {
+	*envSuite
+}
+
+func TestGrpcCompressionSuite(t *testing.T) {
+	suite.Run(t, &grpcCompressionTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *grpcCompressionTestSuite) TestCompressRequests() {
+	// given
+	recorder := &compressionRecorder{compression: make(chan string, 1)}
+	lis, server := createServer(grpc.StatsHandler(recorder))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		GrpcCompression:        "gzip",
+	})
+	s.Require().NoError(err)
+
+	// when
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+	_, _ = client.NewTopologyCommand().Send(ctx)
+
+	// then
+	s.Equal("gzip", <-recorder.compression)
+}
+
+func (s *grpcCompressionTestSuite) TestRejectUnknownCompressor() {
+	// when
+	_, err := NewClient(&ClientConfig{
+		GatewayAddress:         "0.0.0.0:0",
+		UsePlaintextConnection: true,
+		GrpcCompression:        "unknown",
+	})
+
+	// then
+	s.Error(err)
+}
+
+func (s *grpcCompressionTestSuite) TestRejectInvalidCompressionLevel() {
+	// when
+	_, err := NewClient(&ClientConfig{
+		GatewayAddress:         "0.0.0.0:0",
+		UsePlaintextConnection: true,
+		GrpcCompression:        "gzip",
+		GrpcCompressionLevel:   42,
+	})
+
+	// then
+	s.Error(err)
+}
@@ -0,0 +1,172 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const DefaultHedgeDelay = 100 * time.Millisecond
+const DefaultHedgeMaxAttempts = 2
+const DefaultHedgeBudget = 0.1
+
+// hedgeBudgetBurst is the number of hedged attempts which can be sent in a row before the budget is exhausted
+const hedgeBudgetBurst = 10
+
+// readOnlyCommands can be hedged, since sending them more than once has no effect on the broker. Topology is the only
+// read-only command of the gateway protocol.
+var readOnlyCommands = map[string]bool{
+	"Topology": true,
+}
+
+// HedgingPolicy configures how read-only commands are hedged: if the gateway didn't respond within the delay, the
+// command is sent again and the first successful response is used, which cuts the tail latency, e.g. while a gateway
+// is unavailable during a leader change. With GatewayAddresses, the attempts are balanced across the gateways. The
+// error of a failed attempt is returned once no other attempt is in flight; it is retried by the RetryPolicy. Zero
+// values are replaced by the respective defaults.
+type HedgingPolicy struct {
+	// Delay is the time to wait for a response before the next attempt is sent
+	Delay time.Duration
+	// MaxAttempts is the maximum number of attempts which are in flight for a command, including the first one
+	MaxAttempts int
+	// Budget is the fraction, between 0 and 1, of commands for which a hedged attempt may be sent, which caps the
+	// duplicate traffic, e.g. 0.1 adds at most one attempt per ten commands after a burst of ten
+	Budget float64
+}
+
+// HedgingMetrics is implemented by CommandMetrics which also count the hedged attempts.
+type HedgingMetrics interface {
+	// Increment the number of hedged attempts of the command
+	IncrementHedgedAttemptsCount(command string)
+}
+
+func (p HedgingPolicy) withDefaults() HedgingPolicy {
+	if p.Delay <= 0 {
+		p.Delay = DefaultHedgeDelay
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultHedgeMaxAttempts
+	}
+	if p.Budget <= 0 {
+		p.Budget = DefaultHedgeBudget
+	}
+	return p
+}
+
+func configureHedging(config *ClientConfig) error {
+	policy := config.Hedging
+	if policy != nil && (policy.Delay < 0 || policy.MaxAttempts < 0 || policy.Budget < 0 || policy.Budget > 1) {
+		return errors.New("hedging policy must have a non-negative delay and number of attempts and a budget between 0 and 1")
+	}
+
+	return nil
+}
+
+// hedgeBudget grants hedged attempts: every command adds the budget fraction of a token, up to the burst, and every
+// hedged attempt takes a whole token.
+type hedgeBudget struct {
+	fraction float64
+
+	lock   sync.Mutex
+	tokens float64
+}
+
+func (b *hedgeBudget) deposit() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.tokens += b.fraction
+	if b.tokens > hedgeBudgetBurst {
+		b.tokens = hedgeBudgetBurst
+	}
+}
+
+func (b *hedgeBudget) withdraw() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+type hedgedResponse struct {
+	response proto.Message
+	err      error
+}
+
+func hedgingInterceptor(policy HedgingPolicy, metrics CommandMetrics) CommandInterceptor {
+	budget := &hedgeBudget{fraction: policy.Budget, tokens: hedgeBudgetBurst}
+	hedgingMetrics, _ := metrics.(HedgingMetrics)
+
+	return func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+		message, ok := response.(proto.Message)
+		if !ok || !readOnlyCommands[info.Name] {
+			return invoker(ctx, request, response)
+		}
+		budget.deposit()
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// every attempt gets its own response, so the attempts which lose the race don't write to the response
+		responses := make(chan hedgedResponse, policy.MaxAttempts)
+		send := func() {
+			attemptResponse := message.ProtoReflect().New().Interface()
+			go func() {
+				err := invoker(ctx, request, attemptResponse)
+				responses <- hedgedResponse{response: attemptResponse, err: err}
+			}()
+		}
+
+		send()
+		attempts, pending := 1, 1
+		delay := time.NewTimer(policy.Delay)
+		defer delay.Stop()
+
+		for {
+			select {
+			case result := <-responses:
+				pending--
+				if result.err == nil {
+					proto.Merge(message, result.response)
+					return nil
+				}
+				if pending == 0 {
+					return result.err
+				}
+			case <-delay.C:
+				if attempts >= policy.MaxAttempts || !budget.withdraw() {
+					continue
+				}
+
+				send()
+				attempts++
+				pending++
+				if hedgingMetrics != nil {
+					hedgingMetrics.IncrementHedgedAttemptsCount(info.Name)
+				}
+				delay.Reset(policy.Delay)
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type hedgingMetrics struct {
+	hedged int32
+}
+
+func (m *hedgingMetrics) ObserveCommandLatency(string, codes.Code, time.Duration) {}
+
+func (m *hedgingMetrics) IncrementHedgedAttemptsCount(string) {
+	atomic.AddInt32(&m.hedged, 1)
+}
+
+// slowFirstAttempt blocks the first attempt until it is canceled and responds to the others with a broker
+func slowFirstAttempt(attempts *int32) CommandInvoker {
+	return func(ctx context.Context, _, response interface{}) error {
+		if atomic.AddInt32(attempts, 1) == 1 {
+			<-ctx.Done()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		response.(*pb.TopologyResponse).Brokers = []*pb.BrokerInfo{{NodeId: 1}}
+		return nil
+	}
+}
+
+func TestHedgingUsesFirstSuccessfulResponse(t *testing.T) {
+	metrics := &hedgingMetrics{}
+	interceptor := hedgingInterceptor(HedgingPolicy{Delay: time.Millisecond}.withDefaults(), metrics)
+	var attempts int32
+	response := &pb.TopologyResponse{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+	err := interceptor(ctx, CommandInfo{Name: "Topology"}, &pb.TopologyRequest{}, response, slowFirstAttempt(&attempts))
+
+	require.NoError(t, err)
+	require.Len(t, response.Brokers, 1)
+	require.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+	require.EqualValues(t, 1, atomic.LoadInt32(&metrics.hedged))
+}
+
+func TestHedgingIsLimitedByBudget(t *testing.T) {
+	interceptor := hedgingInterceptor(HedgingPolicy{Delay: time.Millisecond, Budget: 0.01}.withDefaults(), nil)
+	var attempts int32
+
+	for i := 0; i < hedgeBudgetBurst+5; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		atomic.StoreInt32(&attempts, 0)
+		err := interceptor(ctx, CommandInfo{Name: "Topology"}, &pb.TopologyRequest{}, &pb.TopologyResponse{}, slowFirstAttempt(&attempts))
+		cancel()
+
+		if i < hedgeBudgetBurst {
+			require.NoError(t, err)
+			require.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+		} else {
+			require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+			require.EqualValues(t, 1, atomic.LoadInt32(&attempts))
+		}
+	}
+}
+
+func TestHedgingReturnsErrorIfAllAttemptsFailed(t *testing.T) {
+	interceptor := hedgingInterceptor(HedgingPolicy{Delay: time.Millisecond, MaxAttempts: 3}.withDefaults(), nil)
+	var attempts int32
+	invoker := func(context.Context, interface{}, interface{}) error {
+		atomic.AddInt32(&attempts, 1)
+		return status.Error(codes.Unavailable, "gateway unavailable")
+	}
+
+	err := interceptor(context.Background(), CommandInfo{Name: "Topology"}, &pb.TopologyRequest{}, &pb.TopologyResponse{}, invoker)
+
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.EqualValues(t, 1, atomic.LoadInt32(&attempts))
+}
+
+func TestHedgingIgnoresCommandsWhichAreNotReadOnly(t *testing.T) {
+	interceptor := hedgingInterceptor(HedgingPolicy{Delay: time.Millisecond}.withDefaults(), nil)
+	var attempts int32
+	invoker := func(context.Context, interface{}, interface{}) error {
+		atomic.AddInt32(&attempts, 1)
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}
+
+	err := interceptor(context.Background(), CommandInfo{Name: "CompleteJob"}, &pb.CompleteJobRequest{}, &pb.CompleteJobResponse{}, invoker)
+
+	require.NoError(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(&attempts))
+}
+
+func TestClientHedgesTopology(t *testing.T) {
+	var calls int32
+	lis, server := createServerWithInterceptor(func(ctx context.Context, _ interface{}, _ *grpc.UnaryServerInfo, _ grpc.UnaryHandler) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &pb.TopologyResponse{ClusterSize: 3}, nil
+	})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		Hedging:                &HedgingPolicy{Delay: 10 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+	response, err := client.NewTopologyCommand().Send(ctx)
+
+	require.NoError(t, err)
+	require.EqualValues(t, 3, response.ClusterSize)
+	require.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestClientRejectsInvalidHedgingPolicy(t *testing.T) {
+	_, err := NewClient(&ClientConfig{
+		GatewayAddress:         "localhost:26500",
+		UsePlaintextConnection: true,
+		Hedging:                &HedgingPolicy{Budget: 2},
+	})
+
+	require.Error(t, err)
+}
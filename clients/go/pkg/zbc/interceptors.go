@@ -0,0 +1,155 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+)
+
+// CommandInfo describes a command which is about to be sent to the gateway.
+type CommandInfo struct {
+	// Name of the command as defined by the gateway protocol, e.g. 'CompleteJob'
+	Name string
+	// Full gRPC method of the command, e.g. '/gateway_protocol.Gateway/CompleteJob'
+	Method string
+	// Attempt is the number of times the command has been sent, starting at 1
+	Attempt int
+}
+
+// CommandInvoker sends the command to the gateway, or passes it to the next interceptor in the chain.
+type CommandInvoker func(ctx context.Context, request, response interface{}) error
+
+// CommandInterceptor intercepts every unary command sent by the client. The interceptor is responsible for calling
+// the invoker to actually send the command; not calling it short-circuits the chain.
+type CommandInterceptor func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error
+
+// CommandStreamer opens the stream of a streaming command, or passes it to the next interceptor in the chain.
+type CommandStreamer func(ctx context.Context) (grpc.ClientStream, error)
+
+// StreamCommandInterceptor intercepts every streaming command sent by the client, e.g. ActivateJobs. The interceptor
+// may wrap the returned stream to observe the received messages.
+type StreamCommandInterceptor func(ctx context.Context, info CommandInfo, streamer CommandStreamer) (grpc.ClientStream, error)
+
+func configureInterceptors(config *ClientConfig, capabilities *gatewayCapabilities, metadata *MetadataCache) {
+	var interceptors []CommandInterceptor
+	if config.AuditSink != nil {
+		interceptors = append(interceptors, auditInterceptor(config.AuditSink, config.AuditMaxVariablesLength))
+	}
+	if config.CommandRecorder != nil {
+		interceptors = append(interceptors, newCommandRecorder(config.CommandRecorder, config.CommandRecorderSanitizer).interceptor)
+	}
+	interceptors = append(interceptors, capabilities.interceptor(config.CheckGatewayFeatures))
+	var streamInterceptors []StreamCommandInterceptor
+	if config.GatewayCompatibility {
+		compat := newGatewayCompatibility(capabilities, config.Logger)
+		interceptors = append(interceptors, compat.interceptor)
+		streamInterceptors = append(streamInterceptors, compat.streamInterceptor)
+	}
+	interceptors = append(interceptors, jobMetadataInterceptor, metadata.interceptor)
+	streamInterceptors = append(streamInterceptors, jobMetadataStreamInterceptor, metadata.streamInterceptor)
+	if config.DefaultCommandTimeout > 0 {
+		interceptors = append(interceptors, defaultTimeoutInterceptor(config.DefaultCommandTimeout))
+	}
+	if hasRetryPolicy(config) {
+		interceptors = append(interceptors, retryInterceptor(config))
+	}
+	if config.Hedging != nil {
+		interceptors = append(interceptors, hedgingInterceptor(config.Hedging.withDefaults(), config.CommandMetrics))
+	}
+	if hasCircuitBreaker(config) {
+		breakers := newCircuitBreakers(config)
+		interceptors = append(interceptors, circuitBreakerInterceptor(breakers))
+		streamInterceptors = append(streamInterceptors, circuitBreakerStreamInterceptor(breakers))
+	}
+	if config.CommandMetrics != nil {
+		interceptors = append(interceptors, commandMetricsInterceptor(config.CommandMetrics))
+	}
+	if hasRateLimiter(config) {
+		interceptors = append(interceptors, rateLimitInterceptor(config))
+		streamInterceptors = append(streamInterceptors, rateLimitStreamInterceptor(config))
+	}
+	interceptors = append(interceptors, config.Interceptors...)
+	streamInterceptors = append(streamInterceptors, config.StreamInterceptors...)
+	if config.RequestSigner != nil {
+		interceptors = append(interceptors, requestSigningInterceptor(config.RequestSigner))
+		streamInterceptors = append(streamInterceptors, requestSigningStreamInterceptor(config.RequestSigner))
+	}
+
+	if len(interceptors) > 0 {
+		unaryInterceptors := make([]grpc.UnaryClientInterceptor, len(interceptors))
+		for i, interceptor := range interceptors {
+			unaryInterceptors[i] = unaryClientInterceptor(interceptor)
+		}
+
+		config.DialOpts = append(config.DialOpts, grpc.WithChainUnaryInterceptor(unaryInterceptors...))
+	}
+
+	if len(streamInterceptors) > 0 {
+		streamClientInterceptors := make([]grpc.StreamClientInterceptor, len(streamInterceptors))
+		for i, interceptor := range streamInterceptors {
+			streamClientInterceptors[i] = streamClientInterceptor(interceptor)
+		}
+
+		config.DialOpts = append(config.DialOpts, grpc.WithChainStreamInterceptor(streamClientInterceptors...))
+	}
+}
+
+// defaultTimeoutInterceptor applies the timeout to commands sent with a context without deadline. It runs before the
+// retry, hedging and circuit breaker interceptors, so the timeout covers all attempts of a command.
+func defaultTimeoutInterceptor(timeout time.Duration) CommandInterceptor {
+	return func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		return invoker(ctx, request, response)
+	}
+}
+
+func unaryClientInterceptor(interceptor CommandInterceptor) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		next := func(ctx context.Context, request, response interface{}) error {
+			return invoker(ctx, method, request, response, cc, opts...)
+		}
+
+		return interceptor(ctx, newCommandInfo(ctx, method), req, reply, next)
+	}
+}
+
+func streamClientInterceptor(interceptor StreamCommandInterceptor) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		next := func(ctx context.Context) (grpc.ClientStream, error) {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		return interceptor(ctx, newCommandInfo(ctx, method), next)
+	}
+}
+
+func newCommandInfo(ctx context.Context, method string) CommandInfo {
+	return CommandInfo{
+		Name:    method[strings.LastIndex(method, "/")+1:],
+		Method:  method,
+		Attempt: commands.Attempt(ctx),
+	}
+}
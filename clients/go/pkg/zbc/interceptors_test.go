@@ -0,0 +1,258 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type interceptorsTestSuite struct {
+	*envSuite
+}
+
+func TestInterceptorsSuite(t *testing.T) {
+	suite.Run(t, &interceptorsTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *interceptorsTestSuite) TestInterceptorsAreChainedInOrder() {
+	// given
+	lis, server := createServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	var calls []string
+	var infos []CommandInfo
+	record := func(name string) CommandInterceptor {
+		return func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+			calls = append(calls, name)
+			infos = append(infos, info)
+			return invoker(ctx, request, response)
+		}
+	}
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		Interceptors:           []CommandInterceptor{record("first"), record("second")},
+	})
+	s.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	_, err = client.NewTopologyCommand().Send(ctx)
+
+	// then
+	s.EqualValues(codes.Unimplemented, status.Code(err))
+	s.Equal([]string{"first", "second"}, calls)
+	for _, info := range infos {
+		s.Equal("Topology", info.Name)
+		s.Equal("/gateway_protocol.Gateway/Topology", info.Method)
+		s.Equal(1, info.Attempt)
+	}
+}
+
+func (s *interceptorsTestSuite) TestInterceptorCanShortCircuitCommand() {
+	// given
+	lis, server := createServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	expectedErr := errors.New("rejected by interceptor")
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		Interceptors: []CommandInterceptor{
+			func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+				s.IsType(&pb.CompleteJobRequest{}, request)
+				return expectedErr
+			},
+		},
+	})
+	s.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	_, err = client.NewCompleteJobCommand().JobKey(123).Send(ctx)
+
+	// then
+	s.Error(err)
+	s.Contains(err.Error(), expectedErr.Error())
+}
+
+func (s *interceptorsTestSuite) TestInterceptorSeesRetryAttempt() {
+	// given
+	lis, server := createServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	var attempts []int
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		CredentialsProvider:    &retryOnceCredentialsProvider{},
+		Interceptors: []CommandInterceptor{
+			func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+				attempts = append(attempts, info.Attempt)
+				return invoker(ctx, request, response)
+			},
+		},
+	})
+	s.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	_, _ = client.NewTopologyCommand().Send(ctx)
+
+	// then
+	s.Equal([]int{1, 2}, attempts)
+}
+
+func (s *interceptorsTestSuite) TestDefaultCommandTimeout() {
+	// given
+	lis, server := createServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	var deadlines []time.Time
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		DefaultCommandTimeout:  time.Minute,
+		Interceptors: []CommandInterceptor{
+			func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+				deadline, _ := ctx.Deadline()
+				deadlines = append(deadlines, deadline)
+				return invoker(ctx, request, response)
+			},
+		},
+	})
+	s.NoError(err)
+
+	commandDeadline := time.Now().Add(utils.DefaultTestTimeout)
+	ctx, cancel := context.WithDeadline(context.Background(), commandDeadline)
+	defer cancel()
+
+	// when
+	start := time.Now()
+	_, _ = client.NewTopologyCommand().Send(context.Background())
+	_, _ = client.NewTopologyCommand().Send(ctx)
+
+	// then
+	s.Len(deadlines, 2)
+	s.False(deadlines[0].Before(start.Add(time.Minute)), "expected default timeout to be applied")
+	s.True(deadlines[1].Equal(commandDeadline), "expected deadline of the context to be kept")
+}
+
+func (s *interceptorsTestSuite) TestStreamInterceptor() {
+	// given
+	lis, server := createServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	var infos []CommandInfo
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		StreamInterceptors: []StreamCommandInterceptor{
+			func(ctx context.Context, info CommandInfo, streamer CommandStreamer) (grpc.ClientStream, error) {
+				infos = append(infos, info)
+				return streamer(ctx)
+			},
+		},
+	})
+	s.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	_, _ = client.NewActivateJobsCommand().JobType("foo").MaxJobsToActivate(1).Send(ctx)
+
+	// then
+	s.Len(infos, 1)
+	s.Equal("ActivateJobs", infos[0].Name)
+}
+
+func (s *interceptorsTestSuite) TestCommandMetrics() {
+	// given
+	lis, server := createServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	metrics := &commandMetricsStub{}
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		CommandMetrics:         metrics,
+	})
+	s.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	_, _ = client.NewTopologyCommand().Send(ctx)
+
+	// then
+	s.Equal("Topology", metrics.command)
+	s.Equal(codes.Unimplemented, metrics.code)
+	s.Greater(int64(metrics.duration), int64(0))
+}
+
+type commandMetricsStub struct {
+	command  string
+	code     codes.Code
+	duration time.Duration
+}
+
+func (m *commandMetricsStub) ObserveCommandLatency(command string, code codes.Code, duration time.Duration) {
+	m.command = command
+	m.code = code
+	m.duration = duration
+}
+
+type retryOnceCredentialsProvider struct {
+	retried bool
+}
+
+func (p *retryOnceCredentialsProvider) ApplyCredentials(context.Context, map[string]string) error {
+	return nil
+}
+
+func (p *retryOnceCredentialsProvider) ShouldRetryRequest(context.Context, error) bool {
+	if p.retried {
+		return false
+	}
+
+	p.retried = true
+	return true
+}
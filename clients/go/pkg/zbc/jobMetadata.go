@@ -0,0 +1,54 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+)
+
+const (
+	// JobKeyMetadataKey is the gRPC metadata key of the job key, which is sent with commands whose context carries a
+	// job, see entities.Job.Context
+	JobKeyMetadataKey = "zeebe-job-key"
+	// WorkflowInstanceKeyMetadataKey is the gRPC metadata key of the workflow instance key of the job
+	WorkflowInstanceKeyMetadataKey = "zeebe-workflow-instance-key"
+)
+
+// withJobMetadata appends the keys of the job whose context this is, if any, to the outgoing metadata.
+func withJobMetadata(ctx context.Context) context.Context {
+	info, ok := entities.JobInfoFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx,
+		JobKeyMetadataKey, strconv.FormatInt(info.Key, 10),
+		WorkflowInstanceKeyMetadataKey, strconv.FormatInt(info.WorkflowInstanceKey, 10),
+	)
+}
+
+func jobMetadataInterceptor(ctx context.Context, _ CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+	return invoker(withJobMetadata(ctx), request, response)
+}
+
+func jobMetadataStreamInterceptor(ctx context.Context, _ CommandInfo, streamer CommandStreamer) (grpc.ClientStream, error) {
+	return streamer(withJobMetadata(ctx))
+}
@@ -0,0 +1,66 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func TestCommandSentWithJobContextCarriesJobMetadata(t *testing.T) {
+	// given
+	received := make(chan metadata.MD, 2)
+	lis, server := createServerWithInterceptor(func(ctx context.Context, _ interface{}, _ *grpc.UnaryServerInfo, _ grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		received <- md
+		return &pb.SetVariablesResponse{}, nil
+	})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	client, err := NewClient(&ClientConfig{GatewayAddress: lis.Addr().String(), UsePlaintextConnection: true})
+	require.NoError(t, err)
+	defer client.Close()
+
+	job := entities.Job{ActivatedJob: pb.ActivatedJob{Key: 123, WorkflowInstanceKey: 456}}
+	ctx, cancel := entities.NewJobContext(context.Background(), &job)
+	defer cancel()
+	ctx, cancelTimeout := context.WithTimeout(ctx, utils.DefaultTestTimeout)
+	defer cancelTimeout()
+
+	// when
+	command, err := client.NewSetVariablesCommand().ElementInstanceKey(789).VariablesFromString("{}")
+	require.NoError(t, err)
+	_, err = command.Send(ctx)
+	require.NoError(t, err)
+	_, err = command.Send(context.Background())
+	require.NoError(t, err)
+
+	// then
+	md := <-received
+	require.Equal(t, []string{"123"}, md.Get(JobKeyMetadataKey))
+	require.Equal(t, []string{"456"}, md.Get(WorkflowInstanceKeyMetadataKey))
+
+	md = <-received
+	require.Empty(t, md.Get(JobKeyMetadataKey))
+}
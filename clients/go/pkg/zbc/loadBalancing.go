@@ -0,0 +1,103 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/resolver"
+)
+
+const dnsScheme = "dns"
+
+// configureLoadBalancing applies the load balancing policy and returns the target to dial, which is resolved with DNS
+// if needed.
+func configureLoadBalancing(config *ClientConfig, target string) (string, error) {
+	if config.DNSResolutionInterval < time.Duration(0) {
+		return "", errors.New("dns resolution interval must be a positive duration")
+	}
+
+	if config.LoadBalancingPolicy != "" {
+		if balancer.Get(config.LoadBalancingPolicy) == nil {
+			return "", fmt.Errorf("load balancing policy '%s' is not registered", config.LoadBalancingPolicy)
+		}
+		serviceConfig := fmt.Sprintf(`{"loadBalancingPolicy":%q}`, config.LoadBalancingPolicy)
+		config.DialOpts = append(config.DialOpts, grpc.WithDefaultServiceConfig(serviceConfig))
+	}
+
+	if len(config.GatewayAddresses) > 0 || strings.HasPrefix(target, UnixSocketAddressPrefix) {
+		return target, nil
+	}
+	if config.LoadBalancingPolicy == "" && config.DNSResolutionInterval == 0 {
+		return target, nil
+	}
+
+	if !strings.HasPrefix(target, dnsScheme+":") {
+		target = dnsScheme + ":///" + target
+	}
+	if config.DNSResolutionInterval > 0 {
+		config.DialOpts = append(config.DialOpts, grpc.WithResolvers(periodicResolverBuilder{
+			Builder:  resolver.Get(dnsScheme),
+			interval: config.DNSResolutionInterval,
+		}))
+	}
+	return target, nil
+}
+
+// periodicResolverBuilder builds resolvers which resolve the target again in an interval
+type periodicResolverBuilder struct {
+	resolver.Builder
+	interval time.Duration
+}
+
+func (b periodicResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	r, err := b.Builder.Build(target, cc, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	periodic := &periodicResolver{Resolver: r, done: make(chan struct{})}
+	go periodic.resolvePeriodically(b.interval)
+	return periodic, nil
+}
+
+type periodicResolver struct {
+	resolver.Resolver
+	done chan struct{}
+}
+
+func (r *periodicResolver) resolvePeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.ResolveNow(resolver.ResolveNowOptions{})
+		case <-r.done:
+			return
+		}
+	}
+}
+
+func (r *periodicResolver) Close() {
+	close(r.done)
+	r.Resolver.Close()
+}
@@ -0,0 +1,127 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+)
+
+type loadBalancingTestSuite struct {
+	*envSuite
+}
+
+func TestLoadBalancingSuite(t *testing.T) {
+	suite.Run(t, &loadBalancingTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *loadBalancingTestSuite) TestResolveGatewayAddressWithDNS() {
+	// given
+	var counter int32
+	address, server := createCountingServer(&counter)
+	defer server.Stop()
+	_, port, _ := net.SplitHostPort(address)
+
+	config := &ClientConfig{
+		GatewayAddress:         fmt.Sprintf("localhost:%s", port),
+		UsePlaintextConnection: true,
+		LoadBalancingPolicy:    "round_robin",
+		DNSResolutionInterval:  time.Second,
+	}
+	client, err := NewClient(config)
+	s.Require().NoError(err)
+	defer client.Close()
+
+	// when
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+	_, err = client.NewTopologyCommand().Send(ctx)
+
+	// then
+	s.NoError(err)
+	s.EqualValues(1, atomic.LoadInt32(&counter))
+}
+
+func (s *loadBalancingTestSuite) TestRejectUnknownLoadBalancingPolicy() {
+	// when
+	_, err := NewClient(&ClientConfig{
+		GatewayAddress:         "0.0.0.0:0",
+		UsePlaintextConnection: true,
+		LoadBalancingPolicy:    "unknown",
+	})
+
+	// then
+	s.Error(err)
+}
+
+func (s *loadBalancingTestSuite) TestRejectNegativeDNSResolutionInterval() {
+	// when
+	_, err := NewClient(&ClientConfig{
+		GatewayAddress:         "0.0.0.0:0",
+		UsePlaintextConnection: true,
+		DNSResolutionInterval:  -time.Second,
+	})
+
+	// then
+	s.Error(err)
+}
+
+type countingResolver struct {
+	resolutions int32
+	closed      int32
+}
+
+func (r *countingResolver) Build(resolver.Target, resolver.ClientConn, resolver.BuildOptions) (resolver.Resolver, error) {
+	return r, nil
+}
+
+func (r *countingResolver) Scheme() string {
+	return "counting"
+}
+
+func (r *countingResolver) ResolveNow(resolver.ResolveNowOptions) {
+	atomic.AddInt32(&r.resolutions, 1)
+}
+
+func (r *countingResolver) Close() {
+	atomic.AddInt32(&r.closed, 1)
+}
+
+func (s *loadBalancingTestSuite) TestResolvePeriodically() {
+	// given
+	counting := &countingResolver{}
+	builder := periodicResolverBuilder{Builder: counting, interval: time.Millisecond}
+
+	// when
+	r, err := builder.Build(resolver.Target{}, nil, resolver.BuildOptions{})
+	s.Require().NoError(err)
+
+	// then
+	s.Eventually(func() bool {
+		return atomic.LoadInt32(&counting.resolutions) >= 3
+	}, utils.DefaultTestTimeout, time.Millisecond)
+
+	r.Close()
+	s.EqualValues(1, atomic.LoadInt32(&counting.closed))
+}
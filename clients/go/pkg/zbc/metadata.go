@@ -0,0 +1,224 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/records"
+)
+
+const DefaultMetadataCacheSize = 1000
+
+// WorkflowInfo is the metadata of a deployed workflow.
+type WorkflowInfo struct {
+	BpmnProcessID string
+	Version       int32
+}
+
+type cachedWorkflow struct {
+	key  int64
+	info WorkflowInfo
+}
+
+// MetadataCache maps the keys of workflows to their BPMN process id and version, e.g. to log and export workflow keys
+// in a readable form without querying them. It is populated from the responses of the client's commands, like deployed
+// workflows, created workflow instances and activated jobs, and from exported records with ObserveRecords, and keeps
+// the most recently used workflows. The workflow of a key never changes, while the latest version of a BPMN process id
+// changes with every deployment of it.
+type MetadataCache struct {
+	size int
+
+	lock      sync.Mutex
+	workflows map[int64]*list.Element
+	recent    *list.List
+	latest    map[string]int64
+}
+
+// NewMetadataCache returns a cache which keeps at most size workflows, DefaultMetadataCacheSize if it is not positive.
+func NewMetadataCache(size int) *MetadataCache {
+	if size <= 0 {
+		size = DefaultMetadataCacheSize
+	}
+
+	return &MetadataCache{size: size, workflows: make(map[int64]*list.Element), recent: list.New(), latest: make(map[string]int64)}
+}
+
+// Workflow returns the metadata of the workflow with the key, or false if it is not cached.
+func (c *MetadataCache) Workflow(workflowKey int64) (WorkflowInfo, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, ok := c.workflows[workflowKey]
+	if !ok {
+		return WorkflowInfo{}, false
+	}
+	c.recent.MoveToFront(element)
+	return element.Value.(*cachedWorkflow).info, true
+}
+
+// LatestWorkflow returns the key and metadata of the latest version of the BPMN process id which was observed, or false
+// if no version of it is cached.
+func (c *MetadataCache) LatestWorkflow(bpmnProcessID string) (int64, WorkflowInfo, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key, ok := c.latest[bpmnProcessID]
+	if !ok {
+		return 0, WorkflowInfo{}, false
+	}
+	return key, c.workflows[key].Value.(*cachedWorkflow).info, true
+}
+
+// PutWorkflow adds the metadata of a workflow, e.g. which was queried from another source.
+func (c *MetadataCache) PutWorkflow(workflowKey int64, info WorkflowInfo) {
+	if workflowKey <= 0 || info.BpmnProcessID == "" {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.workflows[workflowKey]; ok {
+		element.Value.(*cachedWorkflow).info = info
+		c.recent.MoveToFront(element)
+	} else {
+		c.workflows[workflowKey] = c.recent.PushFront(&cachedWorkflow{key: workflowKey, info: info})
+	}
+
+	if latest, ok := c.latest[info.BpmnProcessID]; !ok || c.workflows[latest].Value.(*cachedWorkflow).info.Version < info.Version {
+		c.latest[info.BpmnProcessID] = workflowKey
+	}
+
+	for c.recent.Len() > c.size {
+		c.remove(c.recent.Back().Value.(*cachedWorkflow).key)
+	}
+}
+
+// Invalidate removes the workflow with the key, e.g. if it was cached from an untrusted source.
+func (c *MetadataCache) Invalidate(workflowKey int64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.remove(workflowKey)
+}
+
+// Clear removes all workflows.
+func (c *MetadataCache) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.workflows = make(map[int64]*list.Element)
+	c.recent.Init()
+	c.latest = make(map[string]int64)
+}
+
+func (c *MetadataCache) remove(workflowKey int64) {
+	element, ok := c.workflows[workflowKey]
+	if !ok {
+		return
+	}
+
+	workflow := c.recent.Remove(element).(*cachedWorkflow)
+	delete(c.workflows, workflowKey)
+	if c.latest[workflow.info.BpmnProcessID] != workflowKey {
+		return
+	}
+
+	// the latest version is unknown now, unless an older version of it is cached
+	delete(c.latest, workflow.info.BpmnProcessID)
+	for key, element := range c.workflows {
+		info := element.Value.(*cachedWorkflow).info
+		if info.BpmnProcessID != workflow.info.BpmnProcessID {
+			continue
+		}
+		if latest, ok := c.latest[info.BpmnProcessID]; !ok || c.workflows[latest].Value.(*cachedWorkflow).info.Version < info.Version {
+			c.latest[info.BpmnProcessID] = key
+		}
+	}
+}
+
+// ObserveRecords adds the workflows of the job and workflow instance records which the dispatcher decodes. The
+// callbacks which are set on the dispatcher are still called.
+func (c *MetadataCache) ObserveRecords(dispatcher *records.Dispatcher) {
+	onJob := dispatcher.OnJob
+	dispatcher.OnJob = func(record *records.Record, job *records.JobRecord) error {
+		c.PutWorkflow(job.WorkflowKey, WorkflowInfo{BpmnProcessID: job.BpmnProcessID, Version: job.WorkflowDefinitionVersion})
+		if onJob != nil {
+			return onJob(record, job)
+		}
+		return nil
+	}
+
+	onWorkflowInstance := dispatcher.OnWorkflowInstance
+	dispatcher.OnWorkflowInstance = func(record *records.Record, workflowInstance *records.WorkflowInstanceRecord) error {
+		c.PutWorkflow(workflowInstance.WorkflowKey, WorkflowInfo{BpmnProcessID: workflowInstance.BpmnProcessID, Version: workflowInstance.Version})
+		if onWorkflowInstance != nil {
+			return onWorkflowInstance(record, workflowInstance)
+		}
+		return nil
+	}
+}
+
+func (c *MetadataCache) observeResponse(response interface{}) {
+	switch response := response.(type) {
+	case *pb.DeployWorkflowResponse:
+		for _, workflow := range response.GetWorkflows() {
+			c.PutWorkflow(workflow.GetWorkflowKey(), WorkflowInfo{BpmnProcessID: workflow.GetBpmnProcessId(), Version: workflow.GetVersion()})
+		}
+	case *pb.CreateWorkflowInstanceResponse:
+		c.PutWorkflow(response.GetWorkflowKey(), WorkflowInfo{BpmnProcessID: response.GetBpmnProcessId(), Version: response.GetVersion()})
+	case *pb.CreateWorkflowInstanceWithResultResponse:
+		c.PutWorkflow(response.GetWorkflowKey(), WorkflowInfo{BpmnProcessID: response.GetBpmnProcessId(), Version: response.GetVersion()})
+	case *pb.ActivateJobsResponse:
+		for _, job := range response.GetJobs() {
+			c.PutWorkflow(job.GetWorkflowKey(), WorkflowInfo{BpmnProcessID: job.GetBpmnProcessId(), Version: job.GetWorkflowDefinitionVersion()})
+		}
+	}
+}
+
+func (c *MetadataCache) interceptor(ctx context.Context, _ CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+	err := invoker(ctx, request, response)
+	if err == nil {
+		c.observeResponse(response)
+	}
+	return err
+}
+
+func (c *MetadataCache) streamInterceptor(ctx context.Context, _ CommandInfo, streamer CommandStreamer) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &metadataStream{ClientStream: stream, cache: c}, nil
+}
+
+type metadataStream struct {
+	grpc.ClientStream
+	cache *MetadataCache
+}
+
+func (s *metadataStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.cache.observeResponse(m)
+	}
+	return err
+}
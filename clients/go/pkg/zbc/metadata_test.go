@@ -0,0 +1,110 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/records"
+)
+
+func TestMetadataCacheEvictsLeastRecentlyUsedWorkflow(t *testing.T) {
+	cache := NewMetadataCache(2)
+	cache.PutWorkflow(1, WorkflowInfo{BpmnProcessID: "order", Version: 1})
+	cache.PutWorkflow(2, WorkflowInfo{BpmnProcessID: "payment", Version: 1})
+
+	_, ok := cache.Workflow(1)
+	require.True(t, ok)
+	cache.PutWorkflow(3, WorkflowInfo{BpmnProcessID: "shipping", Version: 1})
+
+	_, ok = cache.Workflow(2)
+	require.False(t, ok)
+	info, ok := cache.Workflow(1)
+	require.True(t, ok)
+	require.Equal(t, WorkflowInfo{BpmnProcessID: "order", Version: 1}, info)
+}
+
+func TestMetadataCacheTracksLatestVersion(t *testing.T) {
+	cache := NewMetadataCache(0)
+	cache.PutWorkflow(2, WorkflowInfo{BpmnProcessID: "order", Version: 2})
+	cache.PutWorkflow(1, WorkflowInfo{BpmnProcessID: "order", Version: 1})
+	cache.PutWorkflow(0, WorkflowInfo{BpmnProcessID: "order", Version: 3})
+
+	key, info, ok := cache.LatestWorkflow("order")
+	require.True(t, ok)
+	require.EqualValues(t, 2, key)
+	require.EqualValues(t, 2, info.Version)
+
+	cache.PutWorkflow(3, WorkflowInfo{BpmnProcessID: "order", Version: 3})
+	key, _, _ = cache.LatestWorkflow("order")
+	require.EqualValues(t, 3, key)
+
+	cache.Invalidate(3)
+	key, _, ok = cache.LatestWorkflow("order")
+	require.True(t, ok)
+	require.EqualValues(t, 2, key)
+
+	cache.Clear()
+	_, _, ok = cache.LatestWorkflow("order")
+	require.False(t, ok)
+}
+
+func TestMetadataCacheObservesRecords(t *testing.T) {
+	cache := NewMetadataCache(0)
+	var jobs int
+	dispatcher := &records.Dispatcher{OnJob: func(*records.Record, *records.JobRecord) error {
+		jobs++
+		return nil
+	}}
+	cache.ObserveRecords(dispatcher)
+
+	err := dispatcher.Dispatch([]byte(`{"valueType":"JOB","value":{"bpmnProcessId":"order","workflowKey":5,"workflowDefinitionVersion":2}}`))
+	require.NoError(t, err)
+	err = dispatcher.Dispatch([]byte(`{"valueType":"WORKFLOW_INSTANCE","value":{"bpmnProcessId":"payment","workflowKey":6,"version":1}}`))
+	require.NoError(t, err)
+
+	require.Equal(t, 1, jobs)
+	info, _ := cache.Workflow(5)
+	require.Equal(t, WorkflowInfo{BpmnProcessID: "order", Version: 2}, info)
+	info, _ = cache.Workflow(6)
+	require.Equal(t, WorkflowInfo{BpmnProcessID: "payment", Version: 1}, info)
+}
+
+func TestClientCachesDeployedWorkflows(t *testing.T) {
+	lis, server := createServerWithInterceptor(func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error) {
+		return &pb.DeployWorkflowResponse{Key: 1, Workflows: []*pb.WorkflowMetadata{{BpmnProcessId: "order", Version: 4, WorkflowKey: 7}}}, nil
+	})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	client, err := NewClient(&ClientConfig{GatewayAddress: lis.Addr().String(), UsePlaintextConnection: true})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+	_, err = client.NewDeployWorkflowCommand().AddResource([]byte("<definitions/>"), "order.bpmn", pb.WorkflowRequestObject_BPMN).Send(ctx)
+	require.NoError(t, err)
+
+	info, ok := client.Metadata().Workflow(7)
+	require.True(t, ok)
+	require.Equal(t, WorkflowInfo{BpmnProcessID: "order", Version: 4}, info)
+}
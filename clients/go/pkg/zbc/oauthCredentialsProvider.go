@@ -19,13 +19,14 @@ import (
 	"fmt"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/logging"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"log"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -45,6 +46,9 @@ const OAuthDefaultAuthzURL = "https://login.cloud.camunda.io/oauth/token/"
 // OAuthDefaultRequestTimeout is the default timeout for OAuth requests
 const OAuthDefaultRequestTimeout = 10 * time.Second
 
+// OAuthDefaultRefreshBefore is the default duration before the expiry of an access token at which it is refreshed
+const OAuthDefaultRefreshBefore = 30 * time.Second
+
 // OAuthCredentialsProvider is a built-in CredentialsProvider that contains credentials obtained from an OAuth
 // authorization server, including a token prefix and an access token. Using these values it sets the 'Authorization'
 // header of each gRPC call.
@@ -53,8 +57,20 @@ type OAuthCredentialsProvider struct {
 	TokenConfig *clientcredentials.Config
 	Cache       OAuthCredentialsCache
 
-	token   *oauth2.Token
-	timeout time.Duration
+	token         *oauth2.Token
+	timeout       time.Duration
+	refreshBefore time.Duration
+
+	lock       sync.Mutex
+	refreshing *tokenRefresh
+	logger     logging.Logger
+}
+
+// tokenRefresh is a token request in flight, which is shared by all goroutines that need new credentials meanwhile.
+type tokenRefresh struct {
+	done    chan struct{}
+	updated bool
+	err     error
 }
 
 // OAuthProviderConfig configures an OAuthCredentialsProvider, containing the required data to request an access token
@@ -74,6 +90,11 @@ type OAuthProviderConfig struct {
 	Cache OAuthCredentialsCache
 	// Timeout is the maximum duration of an OAuth request. The default value is 10 seconds
 	Timeout time.Duration
+	// RefreshBefore is the duration before the expiry of the access token at which it is refreshed, instead of waiting
+	// for an UNAUTHENTICATED response. The default value is 30 seconds
+	RefreshBefore time.Duration
+	// Logger is used to log token refreshes and failures. The default logs warnings with the log package
+	Logger logging.Logger
 }
 
 // ApplyCredentials takes a map of headers as input and adds an access token prefixed by a token type to the 'Authorization'
@@ -94,7 +115,7 @@ func (p *OAuthCredentialsProvider) ShouldRetryRequest(ctx context.Context, err e
 	if status.Code(err) == codes.Unauthenticated {
 		updated, err := p.updateCredentials(ctx)
 		if err != nil {
-			log.Printf("Expected to refresh token after UNAUTHENTICATED response but: %s", err.Error())
+			p.getLogger().Warn("Expected to refresh token after UNAUTHENTICATED response", "audience", p.Audience, "error", err)
 			return false
 		}
 
@@ -129,31 +150,73 @@ func NewOAuthCredentialsProvider(config *OAuthProviderConfig) (*OAuthCredentials
 			TokenURL:       config.AuthorizationServerURL,
 			AuthStyle:      oauth2.AuthStyleInParams,
 		},
-		Audience: config.Audience,
-		Cache:    config.Cache,
-		timeout:  config.Timeout,
+		Audience:      config.Audience,
+		Cache:         config.Cache,
+		timeout:       config.Timeout,
+		refreshBefore: config.RefreshBefore,
+		logger:        config.Logger,
 	}
 
 	return &provider, nil
 }
 
 func (p *OAuthCredentialsProvider) getCredentials(ctx context.Context) (*oauth2.Token, error) {
+	p.lock.Lock()
 	if p.token == nil {
-		credentials := p.getCachedToken()
+		p.token = p.getCachedToken()
+	}
+	token := p.token
+	p.lock.Unlock()
 
-		if credentials != nil {
-			p.token = credentials
-			return credentials, nil
-		}
+	if token != nil && !p.expiresSoon(token) {
+		return token, nil
+	}
 
-		if _, err := p.updateCredentials(ctx); err != nil {
-			return nil, err
+	if _, err := p.updateCredentials(ctx); err != nil {
+		if token != nil && token.Valid() {
+			p.getLogger().Warn("Failed to refresh access token before its expiry, using the current one", "audience", p.Audience, "error", err)
+			return token, nil
 		}
+		return nil, err
 	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
 	return p.token, nil
 }
 
+func (p *OAuthCredentialsProvider) expiresSoon(token *oauth2.Token) bool {
+	return !token.Expiry.IsZero() && time.Until(token.Expiry) <= p.refreshBefore
+}
+
+// updateCredentials requests a new access token. Concurrent calls share a single request to the authorization server.
 func (p *OAuthCredentialsProvider) updateCredentials(ctx context.Context) (bool, error) {
+	p.lock.Lock()
+	if refresh := p.refreshing; refresh != nil {
+		p.lock.Unlock()
+		select {
+		case <-refresh.done:
+			return refresh.updated, refresh.err
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+
+	refresh := &tokenRefresh{done: make(chan struct{})}
+	p.refreshing = refresh
+	p.lock.Unlock()
+
+	refresh.updated, refresh.err = p.requestToken(ctx)
+
+	p.lock.Lock()
+	p.refreshing = nil
+	p.lock.Unlock()
+	close(refresh.done)
+
+	return refresh.updated, refresh.err
+}
+
+func (p *OAuthCredentialsProvider) requestToken(ctx context.Context) (bool, error) {
 	ctx, cancel := context.WithTimeout(ctx, p.timeout)
 	defer cancel()
 
@@ -163,7 +226,12 @@ func (p *OAuthCredentialsProvider) updateCredentials(ctx context.Context) (bool,
 	token, err := p.TokenConfig.Token(ctx)
 	if err != nil {
 		return false, fmt.Errorf("failed to obtain access token: %w", err)
-	} else if p.token == nil || !p.token.Valid() || p.token.AccessToken != token.AccessToken {
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.token == nil || !p.token.Valid() || p.token.AccessToken != token.AccessToken {
+		p.getLogger().Debug("Refreshed OAuth access token", "audience", p.Audience, "expiry", token.Expiry)
 		p.token = token
 		p.updateCache(token)
 		return true, nil
@@ -185,14 +253,14 @@ func (p *OAuthCredentialsProvider) updateCache(credentials *oauth2.Token) {
 	audience := p.Audience
 	err := p.Cache.Update(audience, credentials)
 	if err != nil {
-		log.Printf("Failed to persist credentials for %s to cache: %s", audience, err)
+		p.getLogger().Warn("Failed to persist credentials to cache", "audience", audience, "error", err)
 	}
 }
 
 func (p *OAuthCredentialsProvider) getCachedToken() *oauth2.Token {
 	err := p.Cache.Refresh()
 	if err != nil {
-		log.Printf("Failed to refresh the OAuth credentials cache, %s", err.Error())
+		p.getLogger().Warn("Failed to refresh the OAuth credentials cache", "error", err)
 		return nil
 	}
 	return p.Cache.Get(p.Audience)
@@ -222,7 +290,18 @@ func applyCredentialEnvOverrides(config *OAuthProviderConfig) error {
 	return nil
 }
 
+func (p *OAuthCredentialsProvider) getLogger() logging.Logger {
+	if p.logger == nil {
+		return logging.Default
+	}
+	return p.logger
+}
+
 func applyCredentialDefaults(config *OAuthProviderConfig) {
+	if config.Logger == nil {
+		config.Logger = logging.Default
+	}
+
 	if config.AuthorizationServerURL == "" {
 		config.AuthorizationServerURL = OAuthDefaultAuthzURL
 	}
@@ -230,7 +309,7 @@ func applyCredentialDefaults(config *OAuthProviderConfig) {
 	if config.Cache == nil {
 		cache, err := NewOAuthYamlCredentialsCache("")
 		if err != nil {
-			log.Printf("Failed to create OAuth YAML token cache with default path: %s", err.Error())
+			config.Logger.Warn("Failed to create OAuth YAML token cache with default path", "error", err)
 		} else {
 			config.Cache = cache
 		}
@@ -239,4 +318,8 @@ func applyCredentialDefaults(config *OAuthProviderConfig) {
 	if config.Timeout <= time.Duration(0) {
 		config.Timeout = OAuthDefaultRequestTimeout
 	}
+
+	if config.RefreshBefore <= time.Duration(0) {
+		config.RefreshBefore = OAuthDefaultRefreshBefore
+	}
 }
@@ -31,6 +31,8 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -463,6 +465,79 @@ func (s *oauthCredsProviderTestSuite) TestOAuthCredentialsProviderUsesCachedCred
 	s.False(authServerCalled)
 }
 
+func (s *oauthCredsProviderTestSuite) TestOAuthCredentialsProviderRefreshesBeforeExpiry() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	cache, err := NewOAuthYamlCredentialsCache(DefaultOauthYamlCachePath)
+	s.NoError(err)
+	err = cache.Update(audience, &oauth2.Token{
+		AccessToken: "expiringToken",
+		Expiry:      time.Now().Add(time.Second * 5),
+		TokenType:   "Bearer",
+	})
+	s.NoError(err)
+
+	authzServer := mockAuthorizationServer(s.T(), &mutableToken{value: accessToken})
+	defer authzServer.Close()
+
+	credsProvider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL,
+		RefreshBefore:          time.Minute,
+	})
+	s.NoError(err)
+
+	// when
+	headers := make(map[string]string)
+	err = credsProvider.ApplyCredentials(context.Background(), headers)
+
+	// then
+	s.NoError(err)
+	s.Equal("Bearer "+accessToken, headers["Authorization"])
+}
+
+func (s *oauthCredsProviderTestSuite) TestOAuthCredentialsProviderDeduplicatesConcurrentRequests() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	var requests int32
+	authzServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(50 * time.Millisecond)
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{"access_token": "` + accessToken + `", "expires_in": 3600, "token_type": "bearer"}`))
+	}))
+	defer authzServer.Close()
+
+	credsProvider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL,
+	})
+	s.NoError(err)
+
+	// when
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- credsProvider.ApplyCredentials(context.Background(), make(map[string]string))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// then
+	for err := range errs {
+		s.NoError(err)
+	}
+	s.EqualValues(1, atomic.LoadInt32(&requests))
+}
+
 func (s *oauthCredsProviderTestSuite) TestOAuthTimeout() {
 	// given
 	truncateDefaultOAuthYamlCacheFile()
@@ -0,0 +1,149 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v2"
+)
+
+// ProfileEnvVar selects the profile of the configuration file which is used by NewClientFromEnv.
+const ProfileEnvVar = "ZEEBE_PROFILE"
+
+// ConfigFileEnvVar overrides the path of the configuration file which contains the profiles.
+const ConfigFileEnvVar = "ZEEBE_CONFIG_FILE"
+
+var DefaultConfigFilePath = getDefaultConfigFilePath()
+
+// Profile is a named client configuration of a configuration file, e.g. for a cluster. The file contains the
+// profiles by name:
+//
+//	profiles:
+//	  dev:
+//	    address: localhost:26500
+//	    insecure: true
+//	  prod:
+//	    address: zeebe.example.com:443
+//	    clientId: my-client
+//	    clientSecret: my-secret
+//	    authorizationServerUrl: https://login.example.com/oauth/token
+type Profile struct {
+	Address               string        `yaml:"address"`
+	Insecure              bool          `yaml:"insecure"`
+	CaCertificatePath     string        `yaml:"caCertificatePath"`
+	ClientCertificatePath string        `yaml:"clientCertificatePath"`
+	ClientKeyPath         string        `yaml:"clientKeyPath"`
+	KeepAlive             time.Duration `yaml:"keepAlive"`
+	KeepAliveTimeout      time.Duration `yaml:"keepAliveTimeout"`
+
+	// ClientID and ClientSecret, if set, are used to request access tokens from the authorization server with OAuth.
+	// The audience defaults to the host of the address.
+	ClientID               string `yaml:"clientId"`
+	ClientSecret           string `yaml:"clientSecret"`
+	Audience               string `yaml:"audience"`
+	AuthorizationServerURL string `yaml:"authorizationServerUrl"`
+}
+
+type profilesFile struct {
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// LoadProfile reads the profile with the given name from the configuration file at the path, or at
+// DefaultConfigFilePath if the path is empty.
+func LoadProfile(path, name string) (*Profile, error) {
+	if path == "" {
+		path = DefaultConfigFilePath
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file %s: %w", path, err)
+	}
+
+	var file profilesFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+	}
+
+	profile, ok := file.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile '%s' not found in configuration file %s", name, path)
+	}
+	return &profile, nil
+}
+
+// NewClientFromEnv creates a client which is configured by environment variables, like NewClient with an empty
+// configuration. If ZEEBE_PROFILE is set, the profile of that name is read from the configuration file first, at
+// ZEEBE_CONFIG_FILE or DefaultConfigFilePath, and the environment variables override its values.
+func NewClientFromEnv() (Client, error) {
+	config := &ClientConfig{}
+
+	if name := env.get(ProfileEnvVar); name != "" {
+		profile, err := LoadProfile(env.get(ConfigFileEnvVar), name)
+		if err != nil {
+			return nil, err
+		}
+		if err := profile.apply(config); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewClient(config)
+}
+
+func (p *Profile) apply(config *ClientConfig) error {
+	config.GatewayAddress = p.Address
+	config.UsePlaintextConnection = p.Insecure
+	config.CaCertificatePath = p.CaCertificatePath
+	config.ClientCertificatePath = p.ClientCertificatePath
+	config.ClientKeyPath = p.ClientKeyPath
+	config.KeepAlive = p.KeepAlive
+	config.KeepAliveTimeout = p.KeepAliveTimeout
+
+	if p.ClientID == "" && p.ClientSecret == "" {
+		return nil
+	}
+
+	audience := p.Audience
+	if audience == "" {
+		audience = defaultAudience(p.Address)
+	}
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               p.ClientID,
+		ClientSecret:           p.ClientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: p.AuthorizationServerURL,
+	})
+	if err != nil {
+		return err
+	}
+
+	config.CredentialsProvider = provider
+	return nil
+}
+
+func getDefaultConfigFilePath() string {
+	home, err := homedir.Dir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".config", "zeebe", "config.yaml")
+}
@@ -0,0 +1,153 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type profileTestSuite struct {
+	*envSuite
+	dir string
+}
+
+func TestProfileSuite(t *testing.T) {
+	suite.Run(t, &profileTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *profileTestSuite) SetupTest() {
+	s.envSuite.SetupTest()
+
+	dir, err := ioutil.TempDir("", "zeebe-profiles")
+	s.Require().NoError(err)
+	s.dir = dir
+}
+
+func (s *profileTestSuite) TearDownTest() {
+	s.envSuite.TearDownTest()
+	_ = os.RemoveAll(s.dir)
+}
+
+func (s *profileTestSuite) writeConfigFile(content string) string {
+	path := filepath.Join(s.dir, "config.yaml")
+	s.Require().NoError(ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func (s *profileTestSuite) TestLoadProfile() {
+	// given
+	path := s.writeConfigFile(`
+profiles:
+  dev:
+    address: localhost:26500
+    insecure: true
+    keepAlive: 30s
+  prod:
+    address: zeebe.example.com:443
+    clientId: my-client
+`)
+
+	// when
+	profile, err := LoadProfile(path, "dev")
+
+	// then
+	s.Require().NoError(err)
+	s.Equal(&Profile{Address: "localhost:26500", Insecure: true, KeepAlive: 30 * time.Second}, profile)
+}
+
+func (s *profileTestSuite) TestLoadUnknownProfile() {
+	// given
+	path := s.writeConfigFile("profiles:\n  dev:\n    address: localhost:26500\n")
+
+	// when
+	_, err := LoadProfile(path, "prod")
+
+	// then
+	s.Error(err)
+}
+
+func (s *profileTestSuite) TestNewClientFromEnvWithProfile() {
+	// given
+	lis, server := createServerWithInterceptor(func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error) {
+		return &pb.TopologyResponse{}, nil
+	})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	path := s.writeConfigFile("profiles:\n  broken:\n    address: localhost:1\n  local:\n    address: '" + lis.Addr().String() + "'\n    insecure: true\n")
+	env.set(ConfigFileEnvVar, path)
+	env.set(ProfileEnvVar, "local")
+
+	// when
+	client, err := NewClientFromEnv()
+	s.Require().NoError(err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+	_, err = client.NewTopologyCommand().Send(ctx)
+
+	// then
+	s.NoError(err)
+}
+
+func (s *profileTestSuite) TestEnvOverridesProfile() {
+	// given
+	lis, server := createServerWithInterceptor(func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error) {
+		return &pb.TopologyResponse{}, nil
+	})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	path := s.writeConfigFile("profiles:\n  local:\n    address: localhost:1\n    insecure: true\n")
+	env.set(ConfigFileEnvVar, path)
+	env.set(ProfileEnvVar, "local")
+	env.set(GatewayAddressEnvVar, lis.Addr().String())
+
+	// when
+	client, err := NewClientFromEnv()
+	s.Require().NoError(err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+	_, err = client.NewTopologyCommand().Send(ctx)
+
+	// then
+	s.NoError(err)
+}
+
+func (s *profileTestSuite) TestNewClientFromEnvWithMissingConfigFile() {
+	// given
+	env.set(ConfigFileEnvVar, filepath.Join(s.dir, "missing.yaml"))
+	env.set(ProfileEnvVar, "local")
+
+	// when
+	_, err := NewClientFromEnv()
+
+	// then
+	s.Error(err)
+}
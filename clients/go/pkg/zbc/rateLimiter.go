@@ -0,0 +1,141 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	// SaaSCommandsPerSecond is the rate of commands allowed by NewSaaSRateLimiter
+	SaaSCommandsPerSecond = 50
+	// SaaSCommandBurst is the number of commands which NewSaaSRateLimiter allows to be sent at once
+	SaaSCommandBurst = 100
+)
+
+// RateLimiter limits the rate of commands sent to the gateway. Wait blocks until the command may be sent, or returns
+// an error if the context is done first. It is implemented by *rate.Limiter of golang.org/x/time/rate.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// NewTokenBucketRateLimiter creates a RateLimiter which allows commandsPerSecond commands on average and bursts of up
+// to burst commands.
+func NewTokenBucketRateLimiter(commandsPerSecond float64, burst int) RateLimiter {
+	return &tokenBucket{
+		interval: time.Duration(float64(time.Second) / commandsPerSecond),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// NewSaaSRateLimiter creates a RateLimiter with conservative defaults for shared clusters, e.g. for batch jobs which
+// create many workflow instances.
+func NewSaaSRateLimiter() RateLimiter {
+	return NewTokenBucketRateLimiter(SaaSCommandsPerSecond, SaaSCommandBurst)
+}
+
+type tokenBucket struct {
+	interval time.Duration
+	burst    float64
+
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token and returns how long to wait until it is available.
+func (b *tokenBucket) reserve() time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+float64(now.Sub(b.last))/float64(b.interval))
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens * float64(b.interval))
+}
+
+// cancel returns a token which was reserved but not used.
+func (b *tokenBucket) cancel() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+func rateLimiterOf(config *ClientConfig, command string) RateLimiter {
+	if limiter, ok := config.CommandRateLimiters[command]; ok {
+		return limiter
+	}
+
+	return config.RateLimiter
+}
+
+func hasRateLimiter(config *ClientConfig) bool {
+	return config.RateLimiter != nil || len(config.CommandRateLimiters) > 0
+}
+
+func rateLimitInterceptor(config *ClientConfig) CommandInterceptor {
+	return func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+		if limiter := rateLimiterOf(config, info.Name); limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		return invoker(ctx, request, response)
+	}
+}
+
+func rateLimitStreamInterceptor(config *ClientConfig) StreamCommandInterceptor {
+	return func(ctx context.Context, info CommandInfo, streamer CommandStreamer) (grpc.ClientStream, error) {
+		if limiter := rateLimiterOf(config, info.Name); limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		return streamer(ctx)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+)
+
+type countingRateLimiter struct {
+	waits int
+	err   error
+}
+
+func (l *countingRateLimiter) Wait(context.Context) error {
+	l.waits++
+	return l.err
+}
+
+type rateLimiterTestSuite struct {
+	*envSuite
+}
+
+func TestRateLimiterSuite(t *testing.T) {
+	suite.Run(t, &rateLimiterTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *rateLimiterTestSuite) TestCommandRateLimiterOverridesRateLimiter() {
+	// given
+	lis, server := createServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	limiter := &countingRateLimiter{}
+	topologyLimiter := &countingRateLimiter{}
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		RateLimiter:            limiter,
+		CommandRateLimiters:    map[string]RateLimiter{"Topology": topologyLimiter},
+	})
+	s.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	_, err = client.NewTopologyCommand().Send(ctx)
+	s.EqualValues(codes.Unimplemented, status.Code(err))
+	_, err = client.NewCancelInstanceCommand().WorkflowInstanceKey(123).Send(ctx)
+	s.EqualValues(codes.Unimplemented, status.Code(err))
+
+	// then
+	s.Equal(1, topologyLimiter.waits)
+	s.Equal(1, limiter.waits)
+}
+
+func (s *rateLimiterTestSuite) TestRateLimiterErrorFailsCommand() {
+	// given
+	lis, server := createServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		RateLimiter:            &countingRateLimiter{err: context.DeadlineExceeded},
+	})
+	s.NoError(err)
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Equal(context.DeadlineExceeded, err)
+}
+
+func TestTokenBucketRateLimiterAllowsBurst(t *testing.T) {
+	// given
+	limiter := NewTokenBucketRateLimiter(1, 2)
+	require.NoError(t, limiter.Wait(context.Background()))
+	require.NoError(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// when
+	err := limiter.Wait(ctx)
+
+	// then
+	require.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestTokenBucketRateLimiterWaitsForToken(t *testing.T) {
+	// given
+	limiter := NewTokenBucketRateLimiter(50, 1)
+	require.NoError(t, limiter.Wait(context.Background()))
+	start := time.Now()
+
+	// when
+	err := limiter.Wait(context.Background())
+
+	// then
+	require.NoError(t, err)
+	require.True(t, time.Since(start) >= 15*time.Millisecond, "expected to wait for the next token")
+}
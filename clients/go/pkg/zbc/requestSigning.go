@@ -0,0 +1,261 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// Metadata keys of the signatures which are attached to the commands by the signers of this package.
+const (
+	// ContentDigestHeader is the SHA-256 digest of the request, base64 encoded with the prefix 'sha-256='
+	ContentDigestHeader = "x-zeebe-content-digest"
+	// SignatureKeyIDHeader is the id of the key which signed the request with NewHMACRequestSigner
+	SignatureKeyIDHeader = "x-zeebe-signature-key-id"
+	// SignatureTimestampHeader is the time in Unix seconds at which the request was signed with NewHMACRequestSigner
+	SignatureTimestampHeader = "x-zeebe-signature-timestamp"
+	// SignatureHeader is the base64 encoded HMAC-SHA256 of the signing string, see HMACSigningString
+	SignatureHeader = "x-zeebe-signature"
+	// SignatureJWTHeader is the JSON web token which signs the request with NewJWTRequestSigner
+	SignatureJWTHeader = "x-zeebe-request-jwt"
+)
+
+// DefaultRequestJWTTimeToLive is how long the tokens of NewJWTRequestSigner are valid, if no time to live is given.
+const DefaultRequestJWTTimeToLive = time.Minute
+
+// SignedRequest is a command which is about to be sent to the gateway, see RequestSigner.
+type SignedRequest struct {
+	// Method is the full gRPC method of the command, e.g. '/gateway_protocol.Gateway/CompleteJob'
+	Method string
+	// Digest is the SHA-256 digest of the deterministic protobuf encoding of the request. Streaming commands, like
+	// ActivateJobs, send their request after the metadata, so they are signed with the digest of an empty request.
+	Digest []byte
+	// Time is the time of the attempt which is signed
+	Time time.Time
+}
+
+// RequestSigner signs every attempt of every command, e.g. for a proxy in front of the gateway which verifies the
+// signatures. It returns the metadata which is attached to the command.
+type RequestSigner interface {
+	SignRequest(ctx context.Context, request SignedRequest) (map[string]string, error)
+}
+
+// RequestSignerFunc adapts a function to the RequestSigner interface.
+type RequestSignerFunc func(ctx context.Context, request SignedRequest) (map[string]string, error)
+
+func (f RequestSignerFunc) SignRequest(ctx context.Context, request SignedRequest) (map[string]string, error) {
+	return f(ctx, request)
+}
+
+// ContentDigest returns the value of the ContentDigestHeader of the digest.
+func ContentDigest(digest []byte) string {
+	return "sha-256=" + base64.StdEncoding.EncodeToString(digest)
+}
+
+// HMACSigningString returns the string which is signed by NewHMACRequestSigner: the method, the timestamp and the
+// content digest, separated by newlines.
+func HMACSigningString(method, timestamp, contentDigest string) string {
+	return method + "\n" + timestamp + "\n" + contentDigest
+}
+
+type hmacRequestSigner struct {
+	keyID string
+	key   []byte
+}
+
+// NewHMACRequestSigner returns a signer which signs the requests with the shared key using HMAC-SHA256. It attaches
+// the key id, timestamp, content digest and signature as SignatureKeyIDHeader, SignatureTimestampHeader,
+// ContentDigestHeader and SignatureHeader.
+func NewHMACRequestSigner(keyID string, key []byte) RequestSigner {
+	return &hmacRequestSigner{keyID: keyID, key: key}
+}
+
+func (s *hmacRequestSigner) SignRequest(_ context.Context, request SignedRequest) (map[string]string, error) {
+	timestamp := strconv.FormatInt(request.Time.Unix(), 10)
+	digest := ContentDigest(request.Digest)
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(HMACSigningString(request.Method, timestamp, digest)))
+
+	return map[string]string{
+		SignatureKeyIDHeader:     s.keyID,
+		SignatureTimestampHeader: timestamp,
+		ContentDigestHeader:      digest,
+		SignatureHeader:          base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+	}, nil
+}
+
+// RequestClaims are the claims of the tokens of NewJWTRequestSigner.
+type RequestClaims struct {
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	ID        string `json:"jti"`
+	Method    string `json:"method"`
+	// Digest is the value of the ContentDigestHeader
+	Digest string `json:"digest"`
+}
+
+type jwtRequestSigner struct {
+	keyID      string
+	signer     crypto.Signer
+	algorithm  string
+	timeToLive time.Duration
+}
+
+// NewJWTRequestSigner returns a signer which attaches a JSON web token with the RequestClaims of every request as
+// SignatureJWTHeader, and the content digest as ContentDigestHeader. The token is signed by the signer, which may be
+// a local key or a key of a KMS: RSA keys sign with RS256, ECDSA P-256 keys with ES256 and Ed25519 keys with EdDSA.
+// The key id is the 'kid' of the token header.
+func NewJWTRequestSigner(keyID string, signer crypto.Signer, timeToLive time.Duration) (RequestSigner, error) {
+	var algorithm string
+	switch key := signer.Public().(type) {
+	case *rsa.PublicKey:
+		algorithm = "RS256"
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("expected ECDSA key on curve P-256, but got %s", key.Curve.Params().Name)
+		}
+		algorithm = "ES256"
+	case ed25519.PublicKey:
+		algorithm = "EdDSA"
+	default:
+		return nil, fmt.Errorf("expected RSA, ECDSA or Ed25519 key to sign requests, but got %T", key)
+	}
+
+	if timeToLive <= 0 {
+		timeToLive = DefaultRequestJWTTimeToLive
+	}
+	return &jwtRequestSigner{keyID: keyID, signer: signer, algorithm: algorithm, timeToLive: timeToLive}, nil
+}
+
+func (s *jwtRequestSigner) SignRequest(_ context.Context, request SignedRequest) (map[string]string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	digest := ContentDigest(request.Digest)
+	header, err := json.Marshal(map[string]string{"alg": s.algorithm, "typ": "JWT", "kid": s.keyID})
+	if err != nil {
+		return nil, err
+	}
+	claims, err := json.Marshal(RequestClaims{
+		IssuedAt:  request.Time.Unix(),
+		ExpiresAt: request.Time.Add(s.timeToLive).Unix(),
+		ID:        base64.RawURLEncoding.EncodeToString(id),
+		Method:    request.Method,
+		Digest:    digest,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	signature, err := s.sign([]byte(signingInput))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	return map[string]string{
+		ContentDigestHeader: digest,
+		SignatureJWTHeader:  signingInput + "." + base64.RawURLEncoding.EncodeToString(signature),
+	}, nil
+}
+
+func (s *jwtRequestSigner) sign(signingInput []byte) ([]byte, error) {
+	if s.algorithm == "EdDSA" {
+		return s.signer.Sign(rand.Reader, signingInput, crypto.Hash(0))
+	}
+
+	hash := sha256.Sum256(signingInput)
+	signature, err := s.signer.Sign(rand.Reader, hash[:], crypto.SHA256)
+	if err != nil || s.algorithm != "ES256" {
+		return signature, err
+	}
+
+	// ECDSA signers return ASN.1 signatures, but tokens have the fixed size encoding of r and s
+	var parsed struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(signature, &parsed); err != nil {
+		return nil, err
+	}
+	encoded := make([]byte, 64)
+	rBytes, sBytes := parsed.R.Bytes(), parsed.S.Bytes()
+	copy(encoded[32-len(rBytes):32], rBytes)
+	copy(encoded[64-len(sBytes):], sBytes)
+	return encoded, nil
+}
+
+// requestSigningInterceptor signs every attempt of the commands. It is the last interceptor, so the signature covers
+// the request as it is sent.
+func requestSigningInterceptor(signer RequestSigner) CommandInterceptor {
+	return func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+		var payload []byte
+		if message, ok := request.(proto.Message); ok {
+			var err error
+			if payload, err = (proto.MarshalOptions{Deterministic: true}).Marshal(message); err != nil {
+				return fmt.Errorf("failed to sign %s command: %w", info.Name, err)
+			}
+		}
+
+		ctx, err := signRequest(ctx, signer, info.Method, payload)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, request, response)
+	}
+}
+
+func requestSigningStreamInterceptor(signer RequestSigner) StreamCommandInterceptor {
+	return func(ctx context.Context, info CommandInfo, streamer CommandStreamer) (grpc.ClientStream, error) {
+		ctx, err := signRequest(ctx, signer, info.Method, nil)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx)
+	}
+}
+
+func signRequest(ctx context.Context, signer RequestSigner, method string, payload []byte) (context.Context, error) {
+	digest := sha256.Sum256(payload)
+	headers, err := signer.SignRequest(ctx, SignedRequest{Method: method, Digest: digest[:], Time: time.Now()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign request of %s: %w", method, err)
+	}
+
+	pairs := make([]string, 0, 2*len(headers))
+	for key, value := range headers {
+		pairs = append(pairs, key, value)
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...), nil
+}
@@ -0,0 +1,142 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func startSigningServer(t *testing.T, signer RequestSigner) (Client, <-chan metadata.MD, func()) {
+	received := make(chan metadata.MD, 1)
+	lis, server := createServerWithInterceptor(func(ctx context.Context, request interface{}, _ *grpc.UnaryServerInfo, _ grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		payload, _ := proto.Marshal(request.(proto.Message))
+		digest := sha256.Sum256(payload)
+		md.Set("test-digest", ContentDigest(digest[:]))
+		received <- md
+		return &pb.PublishMessageResponse{}, nil
+	})
+	go server.Serve(lis)
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		RequestSigner:          signer,
+	})
+	require.NoError(t, err)
+
+	return client, received, func() {
+		_ = client.Close()
+		server.Stop()
+	}
+}
+
+func publishSignedMessage(t *testing.T, client Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	command, err := client.NewPublishMessageCommand().MessageName("payment-received").CorrelationKey("DE-42").VariablesFromString(`{"total":12.5}`)
+	require.NoError(t, err)
+	_, err = command.Send(ctx)
+	require.NoError(t, err)
+}
+
+func TestHMACRequestSigning(t *testing.T) {
+	// given
+	key := []byte("shared-secret")
+	client, received, stop := startSigningServer(t, NewHMACRequestSigner("proxy-key", key))
+	defer stop()
+
+	// when
+	publishSignedMessage(t, client)
+
+	// then
+	md := <-received
+	require.Equal(t, []string{"proxy-key"}, md.Get(SignatureKeyIDHeader))
+	require.Equal(t, md.Get("test-digest"), md.Get(ContentDigestHeader))
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(HMACSigningString("/gateway_protocol.Gateway/PublishMessage", md.Get(SignatureTimestampHeader)[0], md.Get(ContentDigestHeader)[0])))
+	require.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), md.Get(SignatureHeader)[0])
+}
+
+func TestJWTRequestSigning(t *testing.T) {
+	// given
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := NewJWTRequestSigner("kms-key", key, 0)
+	require.NoError(t, err)
+
+	client, received, stop := startSigningServer(t, signer)
+	defer stop()
+
+	// when
+	publishSignedMessage(t, client)
+
+	// then
+	md := <-received
+	parts := strings.Split(md.Get(SignatureJWTHeader)[0], ".")
+	require.Len(t, parts, 3)
+
+	var header map[string]string
+	decodeSegment(t, parts[0], &header)
+	require.Equal(t, map[string]string{"alg": "ES256", "typ": "JWT", "kid": "kms-key"}, header)
+
+	var claims RequestClaims
+	decodeSegment(t, parts[1], &claims)
+	require.Equal(t, "/gateway_protocol.Gateway/PublishMessage", claims.Method)
+	require.Equal(t, md.Get("test-digest")[0], claims.Digest)
+	require.Equal(t, int64(DefaultRequestJWTTimeToLive.Seconds()), claims.ExpiresAt-claims.IssuedAt)
+	require.NotEmpty(t, claims.ID)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	require.Len(t, signature, 64)
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	require.True(t, ecdsa.Verify(&key.PublicKey, hash[:], r, s))
+}
+
+func TestJWTRequestSignerRejectsUnsupportedKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	_, err = NewJWTRequestSigner("kms-key", key, 0)
+	require.Error(t, err)
+}
+
+func decodeSegment(t *testing.T, segment string, value interface{}) {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, value))
+}
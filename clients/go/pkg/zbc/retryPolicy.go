@@ -0,0 +1,173 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/commands"
+)
+
+const DefaultRetryMaxAttempts = 5
+const DefaultRetryInitialBackoff = 100 * time.Millisecond
+const DefaultRetryMaxBackoff = 5 * time.Second
+const DefaultRetryBackoffMultiplier = 2.0
+const DefaultRetryJitter = 0.2
+
+// DefaultRetryableCodes are the status codes of transient errors, i.e. the gateway or broker rejected the command
+// because of backpressure or because it was temporarily unavailable.
+var DefaultRetryableCodes = []codes.Code{codes.ResourceExhausted, codes.Unavailable}
+
+// nonIdempotentCommands are not retried unless a per-command retry policy is configured for them, since retrying them
+// may apply the command more than once, e.g. create two workflow instances.
+var nonIdempotentCommands = map[string]bool{
+	"CreateWorkflowInstance":           true,
+	"CreateWorkflowInstanceWithResult": true,
+}
+
+// RetryPolicy configures how commands which failed with a transient error are retried. Zero values are replaced by
+// the respective defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a command is sent, including the first attempt. Use 1 to disable
+	// retries.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the time to wait between two attempts
+	MaxBackoff time.Duration
+	// BackoffMultiplier is the factor by which the backoff grows after each attempt
+	BackoffMultiplier float64
+	// Jitter is the fraction, between 0 and 1, by which each backoff is randomly increased or decreased
+	Jitter float64
+	// RetryableCodes are the status codes after which a command is retried
+	RetryableCodes []codes.Code
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultRetryInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if p.BackoffMultiplier < 1 {
+		p.BackoffMultiplier = DefaultRetryBackoffMultiplier
+	}
+	if p.Jitter <= 0 || p.Jitter > 1 {
+		p.Jitter = DefaultRetryJitter
+	}
+	if len(p.RetryableCodes) == 0 {
+		p.RetryableCodes = DefaultRetryableCodes
+	}
+
+	return p
+}
+
+func (p RetryPolicy) isRetryable(err error) bool {
+	code := status.Code(err)
+	for _, retryableCode := range p.RetryableCodes {
+		if code == retryableCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff returns the time to wait after the given attempt failed
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.BackoffMultiplier, float64(attempt-1))
+	backoff = math.Min(backoff, float64(p.MaxBackoff))
+	// #nosec 404
+	backoff *= 1 + p.Jitter*(2*rand.Float64()-1)
+
+	return time.Duration(backoff)
+}
+
+func validateRetryPolicy(policy *RetryPolicy) error {
+	if policy != nil && (policy.MaxAttempts < 0 || policy.Jitter < 0 || policy.Jitter > 1) {
+		return errors.New("retry policy must have a non-negative number of attempts and a jitter between 0 and 1")
+	}
+
+	return nil
+}
+
+func configureRetryPolicy(config *ClientConfig) error {
+	if err := validateRetryPolicy(config.RetryPolicy); err != nil {
+		return err
+	}
+
+	for _, policy := range config.CommandRetryPolicies {
+		if err := validateRetryPolicy(policy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func retryInterceptor(config *ClientConfig) CommandInterceptor {
+	policies := make(map[string]RetryPolicy, len(config.CommandRetryPolicies))
+	for command, policy := range config.CommandRetryPolicies {
+		if policy != nil {
+			policies[command] = policy.withDefaults()
+		}
+	}
+
+	var defaultPolicy *RetryPolicy
+	if config.RetryPolicy != nil {
+		policy := config.RetryPolicy.withDefaults()
+		defaultPolicy = &policy
+	}
+
+	return func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+		policy, ok := policies[info.Name]
+		if !ok {
+			if defaultPolicy == nil || nonIdempotentCommands[info.Name] {
+				return invoker(ctx, request, response)
+			}
+			policy = *defaultPolicy
+		}
+
+		attemptCtx := ctx
+		for attempt := 1; ; attempt++ {
+			err := invoker(attemptCtx, request, response)
+			if err == nil || attempt >= policy.MaxAttempts || !policy.isRetryable(err) {
+				return err
+			}
+
+			select {
+			case <-time.After(policy.backoff(attempt)):
+			case <-ctx.Done():
+				return err
+			}
+			attemptCtx = commands.WithNextAttempt(attemptCtx)
+		}
+	}
+}
+
+func hasRetryPolicy(config *ClientConfig) bool {
+	return config.RetryPolicy != nil || len(config.CommandRetryPolicies) > 0
+}
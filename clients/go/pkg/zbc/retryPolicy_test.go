@@ -0,0 +1,203 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+type retryPolicyTestSuite struct {
+	*envSuite
+	stopServer func()
+	cancelCtx  context.CancelFunc
+}
+
+func (s *retryPolicyTestSuite) TearDownTest() {
+	if s.stopServer != nil {
+		s.stopServer()
+		s.stopServer = nil
+	}
+	if s.cancelCtx != nil {
+		s.cancelCtx()
+		s.cancelCtx = nil
+	}
+	s.envSuite.TearDownTest()
+}
+
+func TestRetryPolicySuite(t *testing.T) {
+	suite.Run(t, &retryPolicyTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *retryPolicyTestSuite) TestRetryUntilSuccess() {
+	// given
+	calls, client := s.clientFailingTimes(2, codes.ResourceExhausted, &ClientConfig{
+		RetryPolicy: &RetryPolicy{InitialBackoff: time.Millisecond},
+	})
+
+	// when
+	_, err := client.NewTopologyCommand().Send(s.ctx())
+
+	// then
+	s.NoError(err)
+	s.EqualValues(3, atomic.LoadInt32(calls))
+}
+
+func (s *retryPolicyTestSuite) TestRetriesAreSentWithNextAttempt() {
+	// given
+	var attempts []int
+	_, client := s.clientFailingTimes(2, codes.Unavailable, &ClientConfig{
+		RetryPolicy: &RetryPolicy{InitialBackoff: time.Millisecond},
+		Interceptors: []CommandInterceptor{
+			func(ctx context.Context, info CommandInfo, request, response interface{}, invoker CommandInvoker) error {
+				attempts = append(attempts, info.Attempt)
+				return invoker(ctx, request, response)
+			},
+		},
+	})
+
+	// when
+	_, err := client.NewTopologyCommand().Send(s.ctx())
+
+	// then
+	s.NoError(err)
+	s.Equal([]int{1, 2, 3}, attempts)
+}
+
+func (s *retryPolicyTestSuite) TestStopAfterMaxAttempts() {
+	// given
+	calls, client := s.clientFailingTimes(5, codes.Unavailable, &ClientConfig{
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+
+	// when
+	_, err := client.NewTopologyCommand().Send(s.ctx())
+
+	// then
+	s.EqualValues(codes.Unavailable, status.Code(err))
+	s.EqualValues(3, atomic.LoadInt32(calls))
+}
+
+func (s *retryPolicyTestSuite) TestDoNotRetryNonRetryableCode() {
+	// given
+	calls, client := s.clientFailingTimes(1, codes.InvalidArgument, &ClientConfig{
+		RetryPolicy: &RetryPolicy{InitialBackoff: time.Millisecond},
+	})
+
+	// when
+	_, err := client.NewTopologyCommand().Send(s.ctx())
+
+	// then
+	s.EqualValues(codes.InvalidArgument, status.Code(err))
+	s.EqualValues(1, atomic.LoadInt32(calls))
+}
+
+func (s *retryPolicyTestSuite) TestDoNotRetryNonIdempotentCommandByDefault() {
+	// given
+	calls, client := s.clientFailingTimes(1, codes.ResourceExhausted, &ClientConfig{
+		RetryPolicy: &RetryPolicy{InitialBackoff: time.Millisecond},
+	})
+
+	// when
+	_, err := client.NewCreateInstanceCommand().WorkflowKey(123).Send(s.ctx())
+
+	// then
+	s.EqualValues(codes.ResourceExhausted, status.Code(err))
+	s.EqualValues(1, atomic.LoadInt32(calls))
+}
+
+func (s *retryPolicyTestSuite) TestPerCommandOverride() {
+	// given
+	calls, client := s.clientFailingTimes(1, codes.ResourceExhausted, &ClientConfig{
+		RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+		CommandRetryPolicies: map[string]*RetryPolicy{
+			"CreateWorkflowInstance": {InitialBackoff: time.Millisecond},
+		},
+	})
+
+	// when
+	_, err := client.NewCreateInstanceCommand().WorkflowKey(123).Send(s.ctx())
+
+	// then
+	s.NoError(err)
+	s.EqualValues(2, atomic.LoadInt32(calls))
+}
+
+func (s *retryPolicyTestSuite) TestRejectInvalidJitter() {
+	// when
+	_, err := NewClient(&ClientConfig{
+		GatewayAddress:         "localhost:26500",
+		UsePlaintextConnection: true,
+		RetryPolicy:            &RetryPolicy{Jitter: 2},
+	})
+
+	// then
+	s.Error(err)
+}
+
+func (s *retryPolicyTestSuite) TestBackoffIsCappedAndJittered() {
+	// given
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: 0.5}.withDefaults()
+
+	for attempt := 1; attempt < 10; attempt++ {
+		// when
+		backoff := policy.backoff(attempt)
+
+		// then
+		s.LessOrEqual(int64(backoff), int64(1500*time.Millisecond))
+		s.GreaterOrEqual(int64(backoff), int64(50*time.Millisecond))
+	}
+}
+
+func (s *retryPolicyTestSuite) clientFailingTimes(failures int32, code codes.Code, config *ClientConfig) (*int32, Client) {
+	var calls int32
+	lis, server := createServerWithInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, _ grpc.UnaryHandler) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			return nil, status.Error(code, "transient failure")
+		}
+
+		switch req.(type) {
+		case *pb.CreateWorkflowInstanceRequest:
+			return &pb.CreateWorkflowInstanceResponse{}, nil
+		default:
+			return &pb.TopologyResponse{}, nil
+		}
+	})
+	go server.Serve(lis)
+	s.stopServer = server.Stop
+
+	config.GatewayAddress = lis.Addr().String()
+	config.UsePlaintextConnection = true
+	client, err := NewClient(config)
+	s.NoError(err)
+
+	return &calls, client
+}
+
+func (s *retryPolicyTestSuite) ctx() context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	s.cancelCtx = cancel
+	return ctx
+}
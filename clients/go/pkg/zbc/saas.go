@@ -0,0 +1,80 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SaaSDomain is the domain of the gateways of SaaS clusters, which are addressed as '<cluster id>.<region>.<domain>'.
+const SaaSDomain = "zeebe.camunda.io"
+
+// SaaSPort is the port of the gateways of SaaS clusters.
+const SaaSPort = 443
+
+// NewSaaSClient creates a client for the SaaS cluster with the given id in the region, e.g. 'bru-2', which requests
+// access tokens with the client credentials. See NewSaaSClientConfig.
+func NewSaaSClient(clusterID, region, clientID, clientSecret string) (Client, error) {
+	config, err := NewSaaSClientConfig(clusterID, region, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(config)
+}
+
+// NewSaaSClientConfig derives the configuration of a client for a SaaS cluster: the gateway address and the audience
+// of the access tokens are the host of the cluster, tokens are requested from OAuthDefaultAuthzURL and the connection
+// uses TLS with the system certificate pool. The returned configuration can be adapted before it is passed to
+// NewClient; the environment variables of the client and the OAuth credentials still override it.
+func NewSaaSClientConfig(clusterID, region, clientID, clientSecret string) (*ClientConfig, error) {
+	host, err := saasHost(clusterID, region)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               host,
+		AuthorizationServerURL: OAuthDefaultAuthzURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClientConfig{
+		GatewayAddress:      fmt.Sprintf("%s:%d", host, SaaSPort),
+		CredentialsProvider: provider,
+	}, nil
+}
+
+func saasHost(clusterID, region string) (string, error) {
+	clusterID = strings.TrimSpace(clusterID)
+	region = strings.TrimSpace(region)
+
+	if clusterID == "" || region == "" {
+		return "", fmt.Errorf("expected to find non-empty cluster id and region")
+	}
+	if strings.ContainsAny(clusterID, ".:/") {
+		return "", fmt.Errorf("expected cluster id '%s' to be the id of the cluster, not its address", clusterID)
+	}
+	if strings.ContainsAny(region, ".:/") {
+		return "", fmt.Errorf("expected region '%s' to be the id of the region, e.g. 'bru-2'", region)
+	}
+
+	return fmt.Sprintf("%s.%s.%s", clusterID, region, SaaSDomain), nil
+}
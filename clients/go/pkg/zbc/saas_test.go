@@ -0,0 +1,85 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type saasTestSuite struct {
+	*envSuite
+}
+
+func TestSaaSSuite(t *testing.T) {
+	suite.Run(t, &saasTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *saasTestSuite) SetupTest() {
+	s.envSuite.SetupTest()
+
+	file, err := ioutil.TempFile("", ".saasCache")
+	s.Require().NoError(err)
+	_ = file.Close()
+	env.set(OAuthCachePathEnvVar, file.Name())
+}
+
+func (s *saasTestSuite) TearDownTest() {
+	_ = os.Remove(env.get(OAuthCachePathEnvVar))
+	s.envSuite.TearDownTest()
+}
+
+func (s *saasTestSuite) TestDeriveConfigFromCluster() {
+	// when
+	config, err := NewSaaSClientConfig(" 1234-abcd ", "bru-2", "client", "secret")
+
+	// then
+	s.Require().NoError(err)
+	s.Equal("1234-abcd.bru-2.zeebe.camunda.io:443", config.GatewayAddress)
+	s.False(config.UsePlaintextConnection)
+
+	provider, ok := config.CredentialsProvider.(*OAuthCredentialsProvider)
+	s.Require().True(ok)
+	s.Equal("1234-abcd.bru-2.zeebe.camunda.io", provider.Audience)
+	s.Equal(OAuthDefaultAuthzURL, provider.TokenConfig.TokenURL)
+	s.Equal("client", provider.TokenConfig.ClientID)
+}
+
+func (s *saasTestSuite) TestRejectAddressAsClusterID() {
+	// when
+	_, err := NewSaaSClientConfig("1234-abcd.bru-2.zeebe.camunda.io:443", "bru-2", "client", "secret")
+
+	// then
+	s.Error(err)
+}
+
+func (s *saasTestSuite) TestRejectMissingRegion() {
+	// when
+	_, err := NewSaaSClientConfig("1234-abcd", "", "client", "secret")
+
+	// then
+	s.Error(err)
+}
+
+func (s *saasTestSuite) TestRejectMissingCredentials() {
+	// when
+	_, err := NewSaaSClientConfig("1234-abcd", "bru-2", "", "")
+
+	// then
+	s.Error(err)
+}
@@ -0,0 +1,148 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package zbcerrors maps the errors returned by commands of the client to typed errors, so callers can decide how to
+// handle them with errors.Is and errors.As instead of matching the messages of status errors:
+//
+//	_, err := client.NewCompleteJobCommand().JobKey(key).Send(ctx)
+//	if errors.Is(zbcerrors.FromError(err), zbcerrors.ErrJobNotFound) {
+//		// the job was completed, canceled or timed out before
+//	}
+//
+// Commands rejected by the broker additionally describe the rejection with a CommandRejection.
+package zbcerrors
+
+import (
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNotFound is matched by errors of commands which refer to an entity which does not exist, e.g. a job or
+	// workflow instance which was already completed
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidArgument is matched by errors of commands which were rejected as invalid, by the gateway or broker
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrBackpressure is matched by errors of commands which were rejected because the broker is overloaded; the
+	// command can be retried after a backoff
+	ErrBackpressure = errors.New("backpressure")
+	// ErrProcessNotFound is matched by errors of commands which refer to a workflow which is not deployed, and also
+	// matches ErrNotFound
+	ErrProcessNotFound = errors.New("workflow not found")
+	// ErrJobNotFound is matched by errors of commands which refer to a job which does not exist, e.g. because it was
+	// already completed or its workflow instance was canceled, and also matches ErrNotFound
+	ErrJobNotFound = errors.New("job not found")
+)
+
+// Error is a status error returned by the gateway, which matches the sentinel errors of its kind with errors.Is. It
+// keeps the status of the error, so status.Code and status.FromError work on it as on the original error.
+type Error struct {
+	Code    codes.Code
+	Message string
+	// Rejection describes the rejection if the command was rejected by the broker, and is nil otherwise
+	Rejection *CommandRejection
+
+	err   error
+	kinds []error
+}
+
+// FromError maps a status error returned by a command to an Error. Errors which are no status errors, e.g. context
+// errors, and nil are returned unchanged.
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*Error); ok {
+		return err
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	rejection := parseRejection(s.Message())
+	return &Error{Code: s.Code(), Message: s.Message(), Rejection: rejection, err: err, kinds: kindsOf(s.Code(), s.Message(), rejection)}
+}
+
+// FromCommandError maps a status error like FromError, and sets the value type of the rejection from the name of the
+// command as defined by the gateway protocol, e.g. 'CompleteJob'.
+func FromCommandError(command string, err error) error {
+	mapped := FromError(err)
+	typed, ok := mapped.(*Error)
+	if !ok || typed.Rejection == nil {
+		return mapped
+	}
+
+	rejection := *typed.Rejection
+	rejection.ValueType = commandValueTypes[command]
+	withValueType := *typed
+	withValueType.Rejection = &rejection
+	return &withValueType
+}
+
+// kindsOf classifies an error by its code and, for not found errors, by the reason of the rejection, which names the
+// missing entity, e.g. "Expected to complete job with key '1', but no such job was found".
+func kindsOf(code codes.Code, message string, rejection *CommandRejection) []error {
+	if rejection != nil {
+		message = rejection.Reason
+	}
+
+	switch code {
+	case codes.NotFound:
+		message = strings.ToLower(message)
+		switch {
+		case strings.Contains(message, "workflow instance"):
+			return []error{ErrNotFound}
+		case strings.Contains(message, "job"):
+			return []error{ErrNotFound, ErrJobNotFound}
+		case strings.Contains(message, "workflow"):
+			return []error{ErrNotFound, ErrProcessNotFound}
+		}
+		return []error{ErrNotFound}
+	case codes.InvalidArgument:
+		return []error{ErrInvalidArgument}
+	case codes.ResourceExhausted:
+		return []error{ErrBackpressure}
+	}
+	return nil
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Is reports whether the error is of the kind of the given sentinel error.
+func (e *Error) Is(target error) bool {
+	for _, kind := range e.kinds {
+		if kind == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the original status error.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus returns the status of the original error.
+func (e *Error) GRPCStatus() *status.Status {
+	s, _ := status.FromError(e.err)
+	return s
+}
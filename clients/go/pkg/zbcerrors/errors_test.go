@@ -0,0 +1,122 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbcerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFromErrorMatchesSentinels(t *testing.T) {
+	tests := []struct {
+		err  error
+		is   []error
+		isnt []error
+	}{
+		{
+			err:  status.Error(codes.NotFound, "Command 'COMPLETE' rejected with code 'NOT_FOUND': Expected to complete job with key '1', but no such job was found"),
+			is:   []error{ErrNotFound, ErrJobNotFound},
+			isnt: []error{ErrProcessNotFound, ErrInvalidArgument},
+		},
+		{
+			err:  status.Error(codes.NotFound, "Command 'CREATE' rejected with code 'NOT_FOUND': Expected to find workflow definition with process ID 'order', but none found"),
+			is:   []error{ErrNotFound, ErrProcessNotFound},
+			isnt: []error{ErrJobNotFound},
+		},
+		{
+			err:  status.Error(codes.NotFound, "Command 'CANCEL' rejected with code 'NOT_FOUND': Expected to cancel a workflow instance with key '1', but no such workflow instance was found"),
+			is:   []error{ErrNotFound},
+			isnt: []error{ErrJobNotFound, ErrProcessNotFound},
+		},
+		{
+			err:  status.Error(codes.NotFound, "Expected to find workflow with key '1' to be deployed but not found"),
+			is:   []error{ErrNotFound, ErrProcessNotFound},
+			isnt: []error{ErrJobNotFound},
+		},
+		{
+			err:  status.Error(codes.InvalidArgument, "Expected to activate jobs with max jobs to activate greater than zero"),
+			is:   []error{ErrInvalidArgument},
+			isnt: []error{ErrNotFound},
+		},
+		{
+			err:  status.Error(codes.ResourceExhausted, "Expected to handle request, but the broker is overloaded"),
+			is:   []error{ErrBackpressure},
+			isnt: []error{ErrInvalidArgument},
+		},
+		{
+			err:  status.Error(codes.Unavailable, "connection refused"),
+			isnt: []error{ErrNotFound, ErrInvalidArgument, ErrBackpressure},
+		},
+	}
+
+	for _, test := range tests {
+		err := fmt.Errorf("wrapped: %w", FromError(test.err))
+		for _, sentinel := range test.is {
+			assert.True(t, errors.Is(err, sentinel), "expected '%v' to be %v", test.err, sentinel)
+		}
+		for _, sentinel := range test.isnt {
+			assert.False(t, errors.Is(err, sentinel), "expected '%v' not to be %v", test.err, sentinel)
+		}
+	}
+}
+
+func TestFromErrorKeepsStatus(t *testing.T) {
+	original := status.Error(codes.NotFound, "no such job")
+
+	err := FromError(original)
+
+	var typed *Error
+	assert.True(t, errors.As(err, &typed))
+	assert.Equal(t, codes.NotFound, typed.Code)
+	assert.Equal(t, "no such job", typed.Message)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, original.Error(), err.Error())
+	assert.True(t, errors.Is(err, original))
+	assert.Equal(t, err, FromError(err))
+}
+
+func TestFromErrorReturnsOtherErrorsUnchanged(t *testing.T) {
+	assert.Nil(t, FromError(nil))
+	assert.Equal(t, context.DeadlineExceeded, FromError(context.DeadlineExceeded))
+}
+
+func TestFromCommandErrorParsesRejection(t *testing.T) {
+	err := status.Error(codes.FailedPrecondition, "Command 'COMPLETE' rejected with code 'INVALID_STATE': Expected to complete job with key '1', but it is in state 'FAILED'")
+
+	var typed *Error
+	assert.True(t, errors.As(FromCommandError("CompleteJob", err), &typed))
+	assert.Equal(t, &CommandRejection{
+		Type:      RejectionInvalidState,
+		Intent:    "COMPLETE",
+		ValueType: "JOB",
+		Reason:    "Expected to complete job with key '1', but it is in state 'FAILED'",
+	}, typed.Rejection)
+
+	assert.True(t, errors.As(FromError(err), &typed))
+	assert.Empty(t, typed.Rejection.ValueType)
+}
+
+func TestFromErrorWithoutRejection(t *testing.T) {
+	var typed *Error
+	assert.True(t, errors.As(FromCommandError("CompleteJob", status.Error(codes.Unavailable, "connection refused")), &typed))
+	assert.Nil(t, typed.Rejection)
+	assert.Nil(t, FromCommandError("CompleteJob", nil))
+}
@@ -0,0 +1,68 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbcerrors
+
+import "regexp"
+
+// RejectionType is the reason why the broker rejected a command.
+type RejectionType string
+
+const (
+	RejectionInvalidArgument RejectionType = "INVALID_ARGUMENT"
+	RejectionNotFound        RejectionType = "NOT_FOUND"
+	RejectionAlreadyExists   RejectionType = "ALREADY_EXISTS"
+	RejectionInvalidState    RejectionType = "INVALID_STATE"
+	RejectionProcessingError RejectionType = "PROCESSING_ERROR"
+)
+
+// CommandRejection describes a command which was rejected by the broker. The gateway does not send error details, but
+// formats the rejection into the status message, from which it is parsed.
+type CommandRejection struct {
+	Type RejectionType
+	// Intent of the rejected command, e.g. 'COMPLETE'
+	Intent string
+	// ValueType of the rejected command, e.g. 'JOB'. It is not part of the status message, so it is only known if the
+	// error was mapped by FromCommandError
+	ValueType string
+	// Reason of the rejection, e.g. "Expected to complete job with key '1', but no such job was found"
+	Reason string
+}
+
+var rejectionPattern = regexp.MustCompile(`(?s)^Command '([^']*)' rejected with code '([^']*)': (.*)$`)
+
+// commandValueTypes are the value types of the records written for the commands of the gateway protocol.
+var commandValueTypes = map[string]string{
+	"ActivateJobs":                     "JOB_BATCH",
+	"CancelWorkflowInstance":           "WORKFLOW_INSTANCE",
+	"CompleteJob":                      "JOB",
+	"CreateWorkflowInstance":           "WORKFLOW_INSTANCE_CREATION",
+	"CreateWorkflowInstanceWithResult": "WORKFLOW_INSTANCE_CREATION",
+	"DeployWorkflow":                   "DEPLOYMENT",
+	"FailJob":                          "JOB",
+	"ThrowError":                       "JOB",
+	"PublishMessage":                   "MESSAGE",
+	"ResolveIncident":                  "INCIDENT",
+	"SetVariables":                     "VARIABLE_DOCUMENT",
+	"UpdateJobRetries":                 "JOB",
+}
+
+func parseRejection(message string) *CommandRejection {
+	match := rejectionPattern.FindStringSubmatch(message)
+	if match == nil {
+		return nil
+	}
+
+	return &CommandRejection{Intent: match[1], Type: RejectionType(match[2]), Reason: match[3]}
+}
@@ -0,0 +1,118 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbtest
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/workflow"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+)
+
+const (
+	// DefaultAssertTimeout is how long the assertions wait for the expected state.
+	DefaultAssertTimeout = 10 * time.Second
+	// DefaultAssertPollInterval is the period between two checks of the assertions.
+	DefaultAssertPollInterval = 100 * time.Millisecond
+
+	releasedJobMessage = "released by zbtest"
+)
+
+// TestingT is the part of testing.TB used by the assertions.
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
+// AssertJobActivated activates jobs of the given type until one of the workflow instance is activated, and returns it.
+// Jobs of other workflow instances are released again by failing them with unchanged retries. It fails the test if no
+// such job is activated within DefaultAssertTimeout.
+func AssertJobActivated(t TestingT, client zbc.Client, jobType string, workflowInstanceKey int64) *entities.Job {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultAssertTimeout)
+	defer cancel()
+
+	for {
+		jobs, err := client.NewActivateJobsCommand().JobType(jobType).MaxJobsToActivate(32).Send(ctx)
+		if err != nil && ctx.Err() == nil {
+			t.Fatalf("expected job of type '%s' to be activated for workflow instance %d, but activation failed: %v", jobType, workflowInstanceKey, err)
+			return nil
+		}
+
+		var activated *entities.Job
+		for i := range jobs {
+			job := &jobs[i]
+			if activated == nil && job.WorkflowInstanceKey == workflowInstanceKey {
+				activated = job
+				continue
+			}
+
+			_, _ = client.NewFailJobCommand().JobKey(job.Key).Retries(job.Retries).ErrorMessage(releasedJobMessage).Send(ctx)
+		}
+
+		if activated != nil {
+			return activated
+		}
+
+		select {
+		case <-time.After(DefaultAssertPollInterval):
+		case <-ctx.Done():
+			t.Fatalf("expected job of type '%s' to be activated for workflow instance %d within %s, but none was", jobType, workflowInstanceKey, DefaultAssertTimeout)
+			return nil
+		}
+	}
+}
+
+// AwaitJob activates the next job of the given type and returns it, e.g. to assert that a workflow instance reached a
+// service task with the expected variables without opening a job worker. It polls until a job is activated or the
+// context is done, in which case the error of the context is returned. The job stays activated until it is completed,
+// failed or times out.
+func AwaitJob(ctx context.Context, client zbc.Client, jobType string) (*entities.Job, error) {
+	for {
+		jobs, err := client.NewActivateJobsCommand().JobType(jobType).MaxJobsToActivate(1).Send(ctx)
+		if err != nil && ctx.Err() == nil {
+			return nil, err
+		}
+		if len(jobs) > 0 {
+			return &jobs[0], nil
+		}
+
+		select {
+		case <-time.After(DefaultAssertPollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// AssertWorkflowInstanceCompleted waits until the lookup reports the workflow instance as completed, and returns its
+// final state. It fails the test if the instance was terminated, or did not complete within DefaultAssertTimeout.
+func AssertWorkflowInstanceCompleted(t TestingT, lookup workflow.InstanceLookup, workflowInstanceKey int64) *workflow.InstanceState {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultAssertTimeout)
+	defer cancel()
+
+	state, err := workflow.AwaitCompletion(ctx, lookup, workflowInstanceKey, DefaultAssertPollInterval)
+	if err != nil {
+		t.Fatalf("expected workflow instance %d to be completed, but: %v", workflowInstanceKey, err)
+		return nil
+	}
+
+	return state
+}
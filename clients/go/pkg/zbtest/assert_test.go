@@ -0,0 +1,171 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbtest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/workflow"
+)
+
+type recordingT struct {
+	failure string
+}
+
+func (*recordingT) Helper() {}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.failure = fmt.Sprintf(format, args...)
+}
+
+type jobsGateway struct {
+	pb.UnimplementedGatewayServer
+
+	mu     sync.Mutex
+	jobs   []*pb.ActivatedJob
+	failed []int64
+}
+
+func (g *jobsGateway) ActivateJobs(request *pb.ActivateJobsRequest, stream pb.Gateway_ActivateJobsServer) error {
+	g.mu.Lock()
+	jobs := g.jobs
+	g.jobs = nil
+	g.mu.Unlock()
+
+	return stream.Send(&pb.ActivateJobsResponse{Jobs: jobs})
+}
+
+func (g *jobsGateway) FailJob(_ context.Context, request *pb.FailJobRequest) (*pb.FailJobResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.failed = append(g.failed, request.JobKey)
+	return &pb.FailJobResponse{}, nil
+}
+
+func startEngine(t *testing.T, gateway pb.GatewayServer) (*Engine, func()) {
+	listener, err := net.Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer()
+	pb.RegisterGatewayServer(server, gateway)
+	go server.Serve(listener)
+
+	engine, err := ConnectEngine(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return engine, func() {
+		_ = engine.Close()
+		server.Stop()
+	}
+}
+
+func TestAssertJobActivated(t *testing.T) {
+	// given
+	gateway := &jobsGateway{jobs: []*pb.ActivatedJob{
+		{Key: 1, Type: "foo", WorkflowInstanceKey: 10, Retries: 3},
+		{Key: 2, Type: "foo", WorkflowInstanceKey: 20, Retries: 3},
+	}}
+	engine, stop := startEngine(t, gateway)
+	defer stop()
+
+	// when
+	job := AssertJobActivated(t, engine.Client, "foo", 20)
+
+	// then
+	assert.EqualValues(t, 2, job.Key)
+	assert.Equal(t, []int64{1}, gateway.failed)
+}
+
+func TestAwaitJob(t *testing.T) {
+	// given
+	gateway := &jobsGateway{}
+	engine, stop := startEngine(t, gateway)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultAssertTimeout)
+	defer cancel()
+	go func() {
+		time.Sleep(2 * DefaultAssertPollInterval)
+		gateway.mu.Lock()
+		gateway.jobs = []*pb.ActivatedJob{{Key: 1, Type: "foo", Variables: `{"orderId":"o-1"}`}}
+		gateway.mu.Unlock()
+	}()
+
+	// when
+	job, err := AwaitJob(ctx, engine.Client, "foo")
+
+	// then
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, job.Key)
+	assert.Equal(t, `{"orderId":"o-1"}`, job.Variables)
+}
+
+func TestAwaitJobReturnsErrorOfContext(t *testing.T) {
+	// given
+	engine, stop := startEngine(t, &jobsGateway{})
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*DefaultAssertPollInterval)
+	defer cancel()
+
+	// when
+	job, err := AwaitJob(ctx, engine.Client, "foo")
+
+	// then
+	assert.Nil(t, job)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestAssertWorkflowInstanceCompleted(t *testing.T) {
+	// given
+	lookup := workflow.InstanceLookupFunc(func(_ context.Context, key int64) (*workflow.InstanceState, error) {
+		return &workflow.InstanceState{WorkflowInstanceKey: key, Status: workflow.InstanceCompleted}, nil
+	})
+
+	// when
+	state := AssertWorkflowInstanceCompleted(t, lookup, 123)
+
+	// then
+	assert.EqualValues(t, 123, state.WorkflowInstanceKey)
+}
+
+func TestAssertWorkflowInstanceCompletedFailsForTerminatedInstance(t *testing.T) {
+	// given
+	recorder := &recordingT{}
+	lookup := workflow.InstanceLookupFunc(func(_ context.Context, key int64) (*workflow.InstanceState, error) {
+		return &workflow.InstanceState{WorkflowInstanceKey: key, Status: workflow.InstanceTerminated}, nil
+	})
+
+	// when
+	state := AssertWorkflowInstanceCompleted(recorder, lookup, 123)
+
+	// then
+	assert.Nil(t, state)
+	assert.Contains(t, recorder.failure, "workflow instance 123")
+}
@@ -0,0 +1,143 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/containersuite"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/records"
+)
+
+const (
+	// DefaultBrokerImage is the repository of the Zeebe images started by StartBroker.
+	DefaultBrokerImage = "camunda/zeebe"
+	// DefaultRecordLimit is the default number of exported records kept by brokers started with ExportRecords.
+	DefaultRecordLimit = 1024
+
+	debugExporterPort = "8000"
+)
+
+// BrokerOptions configure the broker started by StartBroker.
+type BrokerOptions struct {
+	// Version is the tag of the Zeebe image, e.g. "0.25.0"; Image is used instead if set
+	Version string
+	// Image is the full name of the docker image, e.g. "camunda/zeebe:current-test"
+	Image string
+	// Env are additional environment variables of the broker, e.g. to configure exporters
+	Env map[string]string
+	// ExportRecords enables the debug HTTP exporter of the broker, which keeps the last RecordLimit records, so they
+	// can be read with Engine.Records
+	ExportRecords bool
+	RecordLimit   int
+}
+
+// StartBroker starts a single node broker with an embedded gateway in a docker container, pulling the image if
+// necessary, and waits until the topology has a leader for every partition. The engine should be closed at the end of
+// the test to remove the container.
+func StartBroker(ctx context.Context, options BrokerOptions) (*Engine, error) {
+	image := options.Image
+	if image == "" {
+		if options.Version == "" {
+			return nil, fmt.Errorf("expected either version or image of the broker")
+		}
+		image = DefaultBrokerImage + ":" + options.Version
+	}
+
+	env := make(map[string]string, len(options.Env)+2)
+	for name, value := range options.Env {
+		env[name] = value
+	}
+	var exposedPorts []string
+	if options.ExportRecords {
+		limit := options.RecordLimit
+		if limit <= 0 {
+			limit = DefaultRecordLimit
+		}
+		env["ZEEBE_BROKER_EXPORTERS_DEBUGHTTP_CLASSNAME"] = "io.zeebe.broker.exporter.debug.DebugHttpExporter"
+		env["ZEEBE_BROKER_EXPORTERS_DEBUGHTTP_ARGS_LIMIT"] = strconv.Itoa(limit)
+		exposedPorts = append(exposedPorts, debugExporterPort)
+	}
+
+	container, address, err := containersuite.StartContainerWithEnv(ctx, image, DefaultEngineWaitTime, env, exposedPorts...)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := ConnectEngine(address)
+	if err != nil {
+		_ = container.Terminate(ctx)
+		return nil, err
+	}
+	engine.container = container
+
+	if options.ExportRecords {
+		host, err := container.Host(ctx)
+		if err != nil {
+			_ = engine.Close()
+			return nil, err
+		}
+		port, err := container.MappedPort(ctx, debugExporterPort)
+		if err != nil {
+			_ = engine.Close()
+			return nil, err
+		}
+		engine.recordsURL = fmt.Sprintf("http://%s:%d/records.json", host, port.Int())
+	}
+
+	return engine, nil
+}
+
+// Records returns a snapshot of the records exported by the broker, oldest first. It requires a broker started with
+// ExportRecords; only the last RecordLimit records are kept.
+func (e *Engine) Records(ctx context.Context) ([]*records.Record, error) {
+	if e.recordsURL == "" {
+		return nil, fmt.Errorf("expected broker to be started with ExportRecords")
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, e.recordsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read exported records: %s", response.Status)
+	}
+
+	var documents []json.RawMessage
+	if err := json.NewDecoder(response.Body).Decode(&documents); err != nil {
+		return nil, fmt.Errorf("failed to read exported records: %w", err)
+	}
+
+	// the exporter returns the newest record first
+	exported := make([]*records.Record, len(documents))
+	for i, document := range documents {
+		record, err := records.Decode(document)
+		if err != nil {
+			return nil, err
+		}
+		exported[len(documents)-1-i] = record
+	}
+	return exported, nil
+}
@@ -0,0 +1,51 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbtest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineRecords(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/records.json", request.URL.Path)
+		_, _ = writer.Write([]byte(`[{"position":2,"valueType":"JOB","intent":"CREATED"},{"position":1,"valueType":"DEPLOYMENT","intent":"CREATE"}]`))
+	}))
+	defer server.Close()
+
+	engine := &Engine{recordsURL: server.URL + "/records.json"}
+	exported, err := engine.Records(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, exported, 2)
+	assert.EqualValues(t, 1, exported[0].Position)
+	assert.Equal(t, "JOB", exported[1].ValueType)
+}
+
+func TestEngineRecordsWithoutExporter(t *testing.T) {
+	_, err := (&Engine{}).Records(context.Background())
+	assert.Error(t, err)
+}
+
+func TestStartBrokerWithoutImage(t *testing.T) {
+	_, err := StartBroker(context.Background(), BrokerOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,84 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package zbtest runs workflows against a Zeebe broker in tests. It starts a broker in a docker container, or connects
+// to a running one, and provides assertions which wait for the expected state instead of sleeping.
+package zbtest
+
+import (
+	"context"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/containersuite"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+)
+
+// DefaultEngineWaitTime is the period between checks whether a started engine is ready.
+const DefaultEngineWaitTime = time.Second
+
+// Engine is a Zeebe broker used by tests, together with a client connected to its gateway.
+type Engine struct {
+	// GatewayAddress is the contact point of the gateway in the format 'host:port'
+	GatewayAddress string
+	// Client is connected to the gateway with a plaintext connection
+	Client zbc.Client
+
+	container  testcontainers.Container
+	recordsURL string
+}
+
+// StartEngine starts a docker container of the given Zeebe image and waits until its gateway is ready. The engine
+// should be closed at the end of the test to remove the container.
+func StartEngine(ctx context.Context, image string) (*Engine, error) {
+	container, address, err := containersuite.StartContainer(ctx, image, DefaultEngineWaitTime)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := ConnectEngine(address)
+	if err != nil {
+		_ = container.Terminate(ctx)
+		return nil, err
+	}
+
+	engine.container = container
+	return engine, nil
+}
+
+// ConnectEngine connects to an already running engine with a plaintext connection, e.g. one started by the build.
+func ConnectEngine(gatewayAddress string) (*Engine, error) {
+	client, err := zbc.NewClient(&zbc.ClientConfig{
+		GatewayAddress:         gatewayAddress,
+		UsePlaintextConnection: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Engine{GatewayAddress: gatewayAddress, Client: client}, nil
+}
+
+// Close closes the client and removes the container of the engine, if it was started by StartEngine.
+func (e *Engine) Close() error {
+	err := e.Client.Close()
+	if e.container != nil {
+		if terminateErr := e.container.Terminate(context.Background()); terminateErr != nil {
+			return terminateErr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,237 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package faultinject injects faults into the commands of a client on a deterministic schedule, so the resilience of
+// workers and commands, e.g. backoff, retries and draining, can be tested in CI without a flaky network.
+//
+//	injector := faultinject.New(
+//		faultinject.ResourceExhausted("ActivateJobs", 0, 3),
+//		faultinject.TruncateActivations(1, 3, 1),
+//		faultinject.DropStreams(4, 1),
+//	)
+//	config := &zbc.ClientConfig{GatewayAddress: gateway.Address(), UsePlaintextConnection: true}
+//	injector.Configure(config)
+//	client, err := zbc.NewClient(config)
+//
+// Faults are scheduled by the index of the call of a command, starting at 0, so the first three ActivateJobs calls
+// above are rejected as resource exhausted, the fourth activates at most one job and the fifth stream is dropped.
+package faultinject
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+)
+
+const activateJobs = "ActivateJobs"
+
+// Fault is injected into the calls of a command which are scheduled by From and Count.
+type Fault struct {
+	// Command is the name of the command, e.g. 'CompleteJob', or empty for all commands
+	Command string
+	// From is the index of the first call of the command which the fault is injected into, starting at 0
+	From int
+	// Count is the number of consecutive calls which the fault is injected into; zero means all calls from From on
+	Count int
+
+	// Latency delays the call before it is sent
+	Latency time.Duration
+	// Code, if not codes.OK, fails the call with this status code without sending it, e.g. codes.ResourceExhausted
+	Code codes.Code
+	// DropStream fails a streaming call with codes.Unavailable after DropAfter responses were received, as if the
+	// connection was lost
+	DropStream bool
+	DropAfter  int
+	// TruncateJobs, if set, truncates the jobs of every ActivateJobs response to MaxJobs jobs. The truncated jobs stay
+	// activated, as if the response was lost, so they are only activated again after their timeout
+	TruncateJobs bool
+	MaxJobs      int
+}
+
+// Latency delays the calls of the command, from the call with the index from on, for count calls.
+func Latency(command string, latency time.Duration, from, count int) Fault {
+	return Fault{Command: command, From: from, Count: count, Latency: latency}
+}
+
+// ResourceExhausted rejects the calls of the command as resource exhausted, like a gateway under backpressure.
+func ResourceExhausted(command string, from, count int) Fault {
+	return Fault{Command: command, From: from, Count: count, Code: codes.ResourceExhausted}
+}
+
+// Unavailable fails the calls of the command as unavailable, like an unreachable gateway.
+func Unavailable(command string, from, count int) Fault {
+	return Fault{Command: command, From: from, Count: count, Code: codes.Unavailable}
+}
+
+// DropStreams drops ActivateJobs streams before their first response is received.
+func DropStreams(from, count int) Fault {
+	return Fault{Command: activateJobs, From: from, Count: count, DropStream: true}
+}
+
+// TruncateActivations truncates the responses of ActivateJobs calls to at most maxJobs jobs.
+func TruncateActivations(maxJobs, from, count int) Fault {
+	return Fault{Command: activateJobs, From: from, Count: count, TruncateJobs: true, MaxJobs: maxJobs}
+}
+
+func (f Fault) appliesTo(command string, call int) bool {
+	if f.Command != "" && f.Command != command {
+		return false
+	}
+	return call >= f.From && (f.Count <= 0 || call < f.From+f.Count)
+}
+
+// Injector injects the faults into the commands of the clients it is configured on. It counts the calls of each
+// command, so it should be used by a single client to keep the schedule deterministic.
+type Injector struct {
+	faults []Fault
+
+	lock  sync.Mutex
+	calls map[string]int
+}
+
+// New creates an injector which injects the faults, in order, into the calls they are scheduled for.
+func New(faults ...Fault) *Injector {
+	return &Injector{faults: faults, calls: make(map[string]int)}
+}
+
+// Configure adds the interceptors of the injector to the configuration of a client. They are applied to every attempt
+// of a retried command.
+func (i *Injector) Configure(config *zbc.ClientConfig) {
+	config.Interceptors = append(config.Interceptors, i.Interceptor)
+	config.StreamInterceptors = append(config.StreamInterceptors, i.StreamInterceptor)
+}
+
+// Calls returns how many calls of the command were intercepted so far.
+func (i *Injector) Calls(command string) int {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	return i.calls[command]
+}
+
+// next counts the call of the command and returns the faults scheduled for it.
+func (i *Injector) next(command string) []Fault {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	call := i.calls[command]
+	i.calls[command] = call + 1
+
+	var faults []Fault
+	for _, fault := range i.faults {
+		if fault.appliesTo(command, call) {
+			faults = append(faults, fault)
+		}
+	}
+	return faults
+}
+
+// Interceptor injects the faults into unary commands.
+func (i *Injector) Interceptor(ctx context.Context, info zbc.CommandInfo, request, response interface{}, invoker zbc.CommandInvoker) error {
+	if err := inject(ctx, info.Name, i.next(info.Name)); err != nil {
+		return err
+	}
+
+	return invoker(ctx, request, response)
+}
+
+// StreamInterceptor injects the faults into streaming commands, e.g. ActivateJobs.
+func (i *Injector) StreamInterceptor(ctx context.Context, info zbc.CommandInfo, streamer zbc.CommandStreamer) (grpc.ClientStream, error) {
+	faults := i.next(info.Name)
+	if err := inject(ctx, info.Name, faults); err != nil {
+		return nil, err
+	}
+
+	stream := &faultyStream{dropAfter: -1, maxJobs: -1}
+	for _, fault := range faults {
+		if fault.DropStream && (stream.dropAfter < 0 || fault.DropAfter < stream.dropAfter) {
+			stream.dropAfter = fault.DropAfter
+		}
+		if fault.TruncateJobs && (stream.maxJobs < 0 || fault.MaxJobs < stream.maxJobs) {
+			stream.maxJobs = fault.MaxJobs
+		}
+	}
+	if stream.dropAfter < 0 && stream.maxJobs < 0 {
+		return streamer(ctx)
+	}
+
+	ctx, stream.cancel = context.WithCancel(ctx)
+	clientStream, err := streamer(ctx)
+	if err != nil {
+		stream.cancel()
+		return nil, err
+	}
+	stream.ClientStream = clientStream
+	return stream, nil
+}
+
+// inject delays the call by the latency of the faults and returns the error of the first fault with a status code.
+func inject(ctx context.Context, command string, faults []Fault) error {
+	var latency time.Duration
+	for _, fault := range faults {
+		latency += fault.Latency
+	}
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	for _, fault := range faults {
+		if fault.Code != codes.OK {
+			return status.Errorf(fault.Code, "fault injected into %s", command)
+		}
+	}
+	return nil
+}
+
+type faultyStream struct {
+	grpc.ClientStream
+	cancel context.CancelFunc
+
+	dropAfter int
+	maxJobs   int
+	received  int
+}
+
+func (s *faultyStream) RecvMsg(m interface{}) error {
+	if s.dropAfter >= 0 && s.received >= s.dropAfter {
+		s.cancel()
+		return status.Error(codes.Unavailable, "stream dropped by fault injection")
+	}
+
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.cancel()
+		return err
+	}
+	s.received++
+
+	if response, ok := m.(*pb.ActivateJobsResponse); ok && s.maxJobs >= 0 && len(response.Jobs) > s.maxJobs {
+		response.Jobs = response.Jobs[:s.maxJobs]
+	}
+	return nil
+}
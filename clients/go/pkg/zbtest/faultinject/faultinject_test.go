@@ -0,0 +1,112 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faultinject
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbtest/mockgateway"
+)
+
+func startClient(t *testing.T, injector *Injector) (*mockgateway.Gateway, zbc.Client, func()) {
+	gateway, err := mockgateway.Start()
+	require.NoError(t, err)
+
+	config := &zbc.ClientConfig{GatewayAddress: gateway.Address(), UsePlaintextConnection: true}
+	injector.Configure(config)
+	client, err := zbc.NewClient(config)
+	require.NoError(t, err)
+
+	return gateway, client, func() {
+		_ = client.Close()
+		gateway.Close()
+	}
+}
+
+func TestResourceExhaustedBurst(t *testing.T) {
+	injector := New(ResourceExhausted("Topology", 1, 2))
+	gateway, client, closeClient := startClient(t, injector)
+	defer closeClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	var codesOfCalls []codes.Code
+	for i := 0; i < 4; i++ {
+		_, err := client.NewTopologyCommand().Send(ctx)
+		codesOfCalls = append(codesOfCalls, status.Code(err))
+	}
+
+	require.Equal(t, []codes.Code{codes.OK, codes.ResourceExhausted, codes.ResourceExhausted, codes.OK}, codesOfCalls)
+	require.Equal(t, 4, injector.Calls("Topology"))
+	require.Len(t, gateway.Requests(), 2)
+}
+
+func TestLatency(t *testing.T) {
+	injector := New(Latency("", 50*time.Millisecond, 0, 1))
+	_, client, closeClient := startClient(t, injector)
+	defer closeClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.NewTopologyCommand().Send(ctx)
+
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestTruncateActivations(t *testing.T) {
+	injector := New(TruncateActivations(1, 0, 1))
+	gateway, client, closeClient := startClient(t, injector)
+	defer closeClient()
+
+	gateway.AddJobs(&pb.ActivatedJob{Type: "payment"}, &pb.ActivatedJob{Type: "payment"}, &pb.ActivatedJob{Type: "payment"})
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	truncated, err := client.NewActivateJobsCommand().JobType("payment").MaxJobsToActivate(2).Send(ctx)
+	require.NoError(t, err)
+	remaining, err := client.NewActivateJobsCommand().JobType("payment").MaxJobsToActivate(2).Send(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, truncated, 1)
+	require.Len(t, remaining, 1)
+}
+
+func TestDropStreams(t *testing.T) {
+	injector := New(DropStreams(0, 1))
+	gateway, client, closeClient := startClient(t, injector)
+	defer closeClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	_, err := client.NewActivateJobsCommand().JobType("payment").MaxJobsToActivate(1).Send(ctx)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	gateway.AddJobs(&pb.ActivatedJob{Type: "payment"})
+	jobs, err := client.NewActivateJobsCommand().JobType("payment").MaxJobsToActivate(1).Send(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+}
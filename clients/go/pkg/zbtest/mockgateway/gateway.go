@@ -0,0 +1,178 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mockgateway implements the gateway service in memory, so workers and commands can be unit tested without
+// a broker. Calls are recorded and answered with scripted responses, or with a default response of the call.
+//
+//	gateway, err := mockgateway.Start()
+//	defer gateway.Close()
+//	gateway.AddJobs(&pb.ActivatedJob{Type: "payment", Variables: `{"amount":42}`})
+//	gateway.Respond("CompleteJob", mockgateway.Response{Err: status.Error(codes.NotFound, "job not found")})
+//	client, err := gateway.NewClient()
+package mockgateway
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+)
+
+// Response is the scripted outcome of a single call. After Delay, the call fails with Err if set, or returns
+// Message, which must be the response type of the call. If neither is set, the default response is returned.
+type Response struct {
+	Message proto.Message
+	Err     error
+	Delay   time.Duration
+}
+
+// Request is a call received by the gateway, e.g. Method "CompleteJob" with a *pb.CompleteJobRequest.
+type Request struct {
+	Method  string
+	Message proto.Message
+}
+
+// Gateway is an in-memory gateway listening on a local port.
+//
+// By default, ActivateJobs activates the jobs added with AddJobs, calls which create something return a new key and
+// all other calls return an empty response.
+type Gateway struct {
+	pb.UnimplementedGatewayServer
+
+	server   *grpc.Server
+	listener net.Listener
+
+	mu        sync.Mutex
+	responses map[string][]Response
+	jobs      map[string][]*pb.ActivatedJob
+	requests  []Request
+	nextKey   int64
+}
+
+// Start starts a gateway on a random local port.
+func Start() (*Gateway, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Gateway{
+		server:    grpc.NewServer(),
+		listener:  listener,
+		responses: make(map[string][]Response),
+		jobs:      make(map[string][]*pb.ActivatedJob),
+		nextKey:   1,
+	}
+	pb.RegisterGatewayServer(g.server, g)
+	go func() {
+		_ = g.server.Serve(listener)
+	}()
+	return g, nil
+}
+
+// Address is the gateway address in the format 'host:port'.
+func (g *Gateway) Address() string {
+	return g.listener.Addr().String()
+}
+
+// NewClient creates a client with a plaintext connection to the gateway.
+func (g *Gateway) NewClient() (zbc.Client, error) {
+	return zbc.NewClient(&zbc.ClientConfig{
+		GatewayAddress:         g.Address(),
+		UsePlaintextConnection: true,
+	})
+}
+
+// Close stops the gateway; calls in progress are cancelled.
+func (g *Gateway) Close() {
+	g.server.Stop()
+}
+
+// Respond appends scripted responses for the calls of the method, e.g. "CompleteJob". Each response answers one call,
+// in order; once they are used up, the default response is returned again.
+func (g *Gateway) Respond(method string, responses ...Response) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.responses[method] = append(g.responses[method], responses...)
+}
+
+// AddJobs adds jobs which are activated by ActivateJobs calls for their type. Jobs without a key get a new key.
+func (g *Gateway) AddJobs(jobs ...*pb.ActivatedJob) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, job := range jobs {
+		if job.Key == 0 {
+			job.Key = g.newKey()
+		}
+		g.jobs[job.Type] = append(g.jobs[job.Type], job)
+	}
+}
+
+// Requests returns the calls received so far, in order.
+func (g *Gateway) Requests() []Request {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]Request(nil), g.requests...)
+}
+
+func (g *Gateway) newKey() int64 {
+	key := g.nextKey
+	g.nextKey++
+	return key
+}
+
+// handle records the call and returns its response, which is the scripted one or else created by defaultResponse
+func (g *Gateway) handle(ctx context.Context, method string, request proto.Message, defaultResponse func() proto.Message) (proto.Message, error) {
+	g.mu.Lock()
+	g.requests = append(g.requests, Request{Method: method, Message: request})
+
+	var response Response
+	if scripted := g.responses[method]; len(scripted) > 0 {
+		response = scripted[0]
+		g.responses[method] = scripted[1:]
+	}
+	if response.Err == nil && response.Message == nil {
+		response.Message = defaultResponse()
+	}
+	g.mu.Unlock()
+
+	if response.Delay > 0 {
+		timer := time.NewTimer(response.Delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	return response.Message, response.Err
+}
+
+func unexpectedResponse(method string, response proto.Message) error {
+	return status.Error(codes.Internal, fmt.Sprintf("scripted response of %s has unexpected type %T", method, response))
+}
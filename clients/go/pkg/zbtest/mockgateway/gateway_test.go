@@ -0,0 +1,140 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mockgateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbc"
+)
+
+func startGateway(t *testing.T) (*Gateway, zbc.Client, func()) {
+	gateway, err := Start()
+	require.NoError(t, err)
+
+	client, err := gateway.NewClient()
+	require.NoError(t, err)
+
+	return gateway, client, func() {
+		_ = client.Close()
+		gateway.Close()
+	}
+}
+
+func TestActivateAndCompleteJobs(t *testing.T) {
+	gateway, client, closeGateway := startGateway(t)
+	defer closeGateway()
+
+	gateway.AddJobs(&pb.ActivatedJob{Type: "payment", Variables: `{"amount":42}`}, &pb.ActivatedJob{Type: "other"})
+
+	completed := make(chan int64, 1)
+	jobWorker := client.NewJobWorker().JobType("payment").Handler(func(client worker.JobClient, job entities.Job) {
+		if _, err := client.NewCompleteJobCommand().JobKey(job.Key).Send(context.Background()); err != nil {
+			t.Errorf("failed to complete job: %v", err)
+		}
+		completed <- job.Key
+	}).PollInterval(10 * time.Millisecond).Open()
+	defer jobWorker.Close()
+
+	select {
+	case key := <-completed:
+		require.EqualValues(t, 1, key)
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("expected job to be activated")
+	}
+
+	var completeRequests []*pb.CompleteJobRequest
+	for _, request := range gateway.Requests() {
+		if request.Method == "CompleteJob" {
+			completeRequests = append(completeRequests, request.Message.(*pb.CompleteJobRequest))
+		}
+	}
+	require.Len(t, completeRequests, 1)
+	require.EqualValues(t, 1, completeRequests[0].JobKey)
+}
+
+func TestScriptedResponses(t *testing.T) {
+	gateway, client, closeGateway := startGateway(t)
+	defer closeGateway()
+
+	gateway.Respond("PublishMessage",
+		Response{Err: status.Error(codes.AlreadyExists, "message already published")},
+		Response{Message: &pb.PublishMessageResponse{Key: 42}},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	_, err := client.NewPublishMessageCommand().MessageName("foo").CorrelationKey("bar").Send(ctx)
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	response, err := client.NewPublishMessageCommand().MessageName("foo").CorrelationKey("bar").Send(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 42, response.Key)
+
+	// the scripted responses are used up
+	response, err = client.NewPublishMessageCommand().MessageName("foo").CorrelationKey("bar").Send(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, int64(42), response.Key)
+}
+
+func TestScriptedDelay(t *testing.T) {
+	gateway, client, closeGateway := startGateway(t)
+	defer closeGateway()
+
+	gateway.Respond("Topology", Response{Delay: time.Minute})
+
+	_, err := client.NewTopologyCommand().RequestTimeout(10 * time.Millisecond).Send(context.Background())
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestScriptedResponseOfWrongType(t *testing.T) {
+	gateway, client, closeGateway := startGateway(t)
+	defer closeGateway()
+
+	gateway.Respond("Topology", Response{Message: &pb.CompleteJobResponse{}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	_, err := client.NewTopologyCommand().Send(ctx)
+	require.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestCreateInstanceReturnsNewKeys(t *testing.T) {
+	_, client, closeGateway := startGateway(t)
+	defer closeGateway()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	first, err := client.NewCreateInstanceCommand().BPMNProcessId("order").LatestVersion().Send(ctx)
+	require.NoError(t, err)
+	second, err := client.NewCreateInstanceCommand().BPMNProcessId("order").LatestVersion().Send(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, "order", first.BpmnProcessId)
+	require.NotEqual(t, first.WorkflowInstanceKey, second.WorkflowInstanceKey)
+}
@@ -0,0 +1,318 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mockgateway
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/zbtest"
+)
+
+// Recorder asserts on the commands received by the gateway. The assertions wait up to zbtest.DefaultAssertTimeout
+// for the expected command, since workers handle jobs asynchronously, and fail the test if it isn't received.
+//
+//	recorder := gateway.Recorder(t)
+//	recorder.AssertCompletedJob(jobKey).WithVariables(map[string]interface{}{"paid": true})
+//	recorder.AssertPublishedMessage("payment-received", "order-1")
+type Recorder struct {
+	t       zbtest.TestingT
+	gateway *Gateway
+}
+
+// Recorder creates a Recorder which fails the test t.
+func (g *Gateway) Recorder(t zbtest.TestingT) *Recorder {
+	return &Recorder{t: t, gateway: g}
+}
+
+// await waits until the gateway received a matching request of the method and returns it, or nil if it timed out
+func (r *Recorder) await(method string, matches func(proto.Message) bool) proto.Message {
+	deadline := time.Now().Add(zbtest.DefaultAssertTimeout)
+	for {
+		for _, request := range r.gateway.Requests() {
+			if request.Method == method && matches(request.Message) {
+				return request.Message
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil
+		}
+		time.Sleep(zbtest.DefaultAssertPollInterval)
+	}
+}
+
+// CompletedJobAssertion asserts on the CompleteJob command of a job.
+type CompletedJobAssertion struct {
+	t       zbtest.TestingT
+	request *pb.CompleteJobRequest
+}
+
+// AssertCompletedJob asserts that the job was completed.
+func (r *Recorder) AssertCompletedJob(jobKey int64) *CompletedJobAssertion {
+	r.t.Helper()
+
+	request := r.await("CompleteJob", func(message proto.Message) bool {
+		return message.(*pb.CompleteJobRequest).JobKey == jobKey
+	})
+	if request == nil {
+		r.t.Fatalf("expected job %d to be completed, but it wasn't", jobKey)
+		return &CompletedJobAssertion{t: r.t}
+	}
+	return &CompletedJobAssertion{t: r.t, request: request.(*pb.CompleteJobRequest)}
+}
+
+// WithVariables asserts that the job was completed with variables equal to the JSON document of expected.
+func (a *CompletedJobAssertion) WithVariables(expected interface{}) *CompletedJobAssertion {
+	a.t.Helper()
+
+	if a.request != nil {
+		assertVariables(a.t, "job", a.request.Variables, expected)
+	}
+	return a
+}
+
+// FailedJobAssertion asserts on the FailJob command of a job.
+type FailedJobAssertion struct {
+	t       zbtest.TestingT
+	request *pb.FailJobRequest
+}
+
+// AssertFailedJob asserts that the job was failed.
+func (r *Recorder) AssertFailedJob(jobKey int64) *FailedJobAssertion {
+	r.t.Helper()
+
+	request := r.await("FailJob", func(message proto.Message) bool {
+		return message.(*pb.FailJobRequest).JobKey == jobKey
+	})
+	if request == nil {
+		r.t.Fatalf("expected job %d to be failed, but it wasn't", jobKey)
+		return &FailedJobAssertion{t: r.t}
+	}
+	return &FailedJobAssertion{t: r.t, request: request.(*pb.FailJobRequest)}
+}
+
+// WithRetries asserts the remaining retries the job was failed with.
+func (a *FailedJobAssertion) WithRetries(retries int32) *FailedJobAssertion {
+	a.t.Helper()
+
+	if a.request != nil && a.request.Retries != retries {
+		a.t.Fatalf("expected job %d to be failed with %d retries, but got %d", a.request.JobKey, retries, a.request.Retries)
+	}
+	return a
+}
+
+// WithErrorMessage asserts the error message the job was failed with.
+func (a *FailedJobAssertion) WithErrorMessage(errorMessage string) *FailedJobAssertion {
+	a.t.Helper()
+
+	if a.request != nil && a.request.ErrorMessage != errorMessage {
+		a.t.Fatalf("expected job %d to be failed with error message '%s', but got '%s'", a.request.JobKey, errorMessage, a.request.ErrorMessage)
+	}
+	return a
+}
+
+// ThrownErrorAssertion asserts on the ThrowError command of a job.
+type ThrownErrorAssertion struct {
+	t       zbtest.TestingT
+	request *pb.ThrowErrorRequest
+}
+
+// AssertThrownError asserts that an error with the code was thrown for the job.
+func (r *Recorder) AssertThrownError(jobKey int64, errorCode string) *ThrownErrorAssertion {
+	r.t.Helper()
+
+	request := r.await("ThrowError", func(message proto.Message) bool {
+		request := message.(*pb.ThrowErrorRequest)
+		return request.JobKey == jobKey && request.ErrorCode == errorCode
+	})
+	if request == nil {
+		r.t.Fatalf("expected error '%s' to be thrown for job %d, but it wasn't", errorCode, jobKey)
+		return &ThrownErrorAssertion{t: r.t}
+	}
+	return &ThrownErrorAssertion{t: r.t, request: request.(*pb.ThrowErrorRequest)}
+}
+
+// WithErrorMessage asserts the message of the thrown error.
+func (a *ThrownErrorAssertion) WithErrorMessage(errorMessage string) *ThrownErrorAssertion {
+	a.t.Helper()
+
+	if a.request != nil && a.request.ErrorMessage != errorMessage {
+		a.t.Fatalf("expected error '%s' to be thrown with message '%s', but got '%s'", a.request.ErrorCode, errorMessage, a.request.ErrorMessage)
+	}
+	return a
+}
+
+// PublishedMessageAssertion asserts on a PublishMessage command.
+type PublishedMessageAssertion struct {
+	t       zbtest.TestingT
+	request *pb.PublishMessageRequest
+}
+
+// AssertPublishedMessage asserts that a message with the name and correlation key was published.
+func (r *Recorder) AssertPublishedMessage(name, correlationKey string) *PublishedMessageAssertion {
+	r.t.Helper()
+
+	request := r.await("PublishMessage", func(message proto.Message) bool {
+		request := message.(*pb.PublishMessageRequest)
+		return request.Name == name && request.CorrelationKey == correlationKey
+	})
+	if request == nil {
+		r.t.Fatalf("expected message '%s' with correlation key '%s' to be published, but it wasn't", name, correlationKey)
+		return &PublishedMessageAssertion{t: r.t}
+	}
+	return &PublishedMessageAssertion{t: r.t, request: request.(*pb.PublishMessageRequest)}
+}
+
+// WithMessageID asserts the id of the message.
+func (a *PublishedMessageAssertion) WithMessageID(messageID string) *PublishedMessageAssertion {
+	a.t.Helper()
+
+	if a.request != nil && a.request.MessageId != messageID {
+		a.t.Fatalf("expected message '%s' to be published with id '%s', but got '%s'", a.request.Name, messageID, a.request.MessageId)
+	}
+	return a
+}
+
+// WithTimeToLive asserts the time to live of the message.
+func (a *PublishedMessageAssertion) WithTimeToLive(timeToLive time.Duration) *PublishedMessageAssertion {
+	a.t.Helper()
+
+	if a.request != nil && a.request.TimeToLive != int64(timeToLive/time.Millisecond) {
+		a.t.Fatalf("expected message '%s' to be published with time to live %s, but got %dms", a.request.Name, timeToLive, a.request.TimeToLive)
+	}
+	return a
+}
+
+// WithVariables asserts that the message was published with variables equal to the JSON document of expected.
+func (a *PublishedMessageAssertion) WithVariables(expected interface{}) *PublishedMessageAssertion {
+	a.t.Helper()
+
+	if a.request != nil {
+		assertVariables(a.t, "message", a.request.Variables, expected)
+	}
+	return a
+}
+
+// CreatedInstanceAssertion asserts on a CreateWorkflowInstance command.
+type CreatedInstanceAssertion struct {
+	t       zbtest.TestingT
+	request *pb.CreateWorkflowInstanceRequest
+}
+
+// AssertCreatedInstance asserts that an instance of the workflow was created, with or without awaiting its result.
+func (r *Recorder) AssertCreatedInstance(bpmnProcessID string) *CreatedInstanceAssertion {
+	r.t.Helper()
+
+	deadline := time.Now().Add(zbtest.DefaultAssertTimeout)
+	for {
+		for _, request := range r.gateway.Requests() {
+			var create *pb.CreateWorkflowInstanceRequest
+			switch message := request.Message.(type) {
+			case *pb.CreateWorkflowInstanceRequest:
+				create = message
+			case *pb.CreateWorkflowInstanceWithResultRequest:
+				create = message.Request
+			}
+			if create != nil && create.BpmnProcessId == bpmnProcessID {
+				return &CreatedInstanceAssertion{t: r.t, request: create}
+			}
+		}
+
+		if time.Now().After(deadline) {
+			r.t.Fatalf("expected an instance of workflow '%s' to be created, but it wasn't", bpmnProcessID)
+			return &CreatedInstanceAssertion{t: r.t}
+		}
+		time.Sleep(zbtest.DefaultAssertPollInterval)
+	}
+}
+
+// WithVariables asserts that the instance was created with variables equal to the JSON document of expected.
+func (a *CreatedInstanceAssertion) WithVariables(expected interface{}) *CreatedInstanceAssertion {
+	a.t.Helper()
+
+	if a.request != nil {
+		assertVariables(a.t, "workflow instance", a.request.Variables, expected)
+	}
+	return a
+}
+
+// SetVariablesAssertion asserts on a SetVariables command.
+type SetVariablesAssertion struct {
+	t       zbtest.TestingT
+	request *pb.SetVariablesRequest
+}
+
+// AssertSetVariables asserts that variables were set on the element instance.
+func (r *Recorder) AssertSetVariables(elementInstanceKey int64) *SetVariablesAssertion {
+	r.t.Helper()
+
+	request := r.await("SetVariables", func(message proto.Message) bool {
+		return message.(*pb.SetVariablesRequest).ElementInstanceKey == elementInstanceKey
+	})
+	if request == nil {
+		r.t.Fatalf("expected variables to be set on element instance %d, but they weren't", elementInstanceKey)
+		return &SetVariablesAssertion{t: r.t}
+	}
+	return &SetVariablesAssertion{t: r.t, request: request.(*pb.SetVariablesRequest)}
+}
+
+// WithVariables asserts that the variables are equal to the JSON document of expected.
+func (a *SetVariablesAssertion) WithVariables(expected interface{}) *SetVariablesAssertion {
+	a.t.Helper()
+
+	if a.request != nil {
+		assertVariables(a.t, "element instance", a.request.Variables, expected)
+	}
+	return a
+}
+
+// WithLocal asserts whether the variables were set locally.
+func (a *SetVariablesAssertion) WithLocal(local bool) *SetVariablesAssertion {
+	a.t.Helper()
+
+	if a.request != nil && a.request.Local != local {
+		a.t.Fatalf("expected variables of element instance %d to be set with local %t, but got %t", a.request.ElementInstanceKey, local, a.request.Local)
+	}
+	return a
+}
+
+func assertVariables(t zbtest.TestingT, owner, actual string, expected interface{}) {
+	t.Helper()
+
+	expectedJSON, err := json.Marshal(expected)
+	if err != nil {
+		t.Fatalf("failed to serialize expected variables: %v", err)
+		return
+	}
+
+	var expectedValue, actualValue interface{}
+	_ = json.Unmarshal(expectedJSON, &expectedValue)
+	if actual == "" {
+		actual = "{}"
+	}
+	if err := json.Unmarshal([]byte(actual), &actualValue); err != nil {
+		t.Fatalf("expected variables of %s to be JSON, but got '%s'", owner, actual)
+		return
+	}
+
+	if !reflect.DeepEqual(expectedValue, actualValue) {
+		t.Fatalf("expected variables of %s to be %s, but got %s", owner, expectedJSON, actual)
+	}
+}
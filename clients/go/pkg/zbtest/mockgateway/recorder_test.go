@@ -0,0 +1,110 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mockgateway
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeebe-io/zeebe/clients/go/internal/utils"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/entities"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+	"github.com/zeebe-io/zeebe/clients/go/pkg/worker"
+)
+
+type recordingT struct {
+	failures []string
+}
+
+func (*recordingT) Helper() {}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func TestRecorderAssertsWorkerCommands(t *testing.T) {
+	gateway, client, closeGateway := startGateway(t)
+	defer closeGateway()
+
+	gateway.AddJobs(
+		&pb.ActivatedJob{Key: 1, Type: "payment", Variables: `{"amount":42}`},
+		&pb.ActivatedJob{Key: 2, Type: "payment", Variables: `{"amount":-1}`, Retries: 3},
+	)
+
+	jobWorker := client.NewJobWorker().JobType("payment").Handler(func(client worker.JobClient, job entities.Job) {
+		ctx := context.Background()
+		variables, _ := job.GetVariablesAsMap()
+		if variables["amount"].(float64) < 0 {
+			_, _ = client.NewFailJobCommand().JobKey(job.Key).Retries(job.Retries - 1).ErrorMessage("invalid amount").Send(ctx)
+			return
+		}
+
+		command, _ := client.NewCompleteJobCommand().JobKey(job.Key).VariablesFromMap(map[string]interface{}{"paid": true})
+		_, _ = command.Send(ctx)
+	}).PollInterval(10 * time.Millisecond).Open()
+	defer jobWorker.Close()
+
+	recorder := gateway.Recorder(t)
+	recorder.AssertCompletedJob(1).WithVariables(map[string]bool{"paid": true})
+	recorder.AssertFailedJob(2).WithRetries(2).WithErrorMessage("invalid amount")
+}
+
+func TestRecorderAssertsClientCommands(t *testing.T) {
+	gateway, client, closeGateway := startGateway(t)
+	defer closeGateway()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	publish, err := client.NewPublishMessageCommand().MessageName("payment-received").CorrelationKey("order-1").MessageId("1").TimeToLive(time.Minute).VariablesFromString(`{"amount":42}`)
+	require.NoError(t, err)
+	_, err = publish.Send(ctx)
+	require.NoError(t, err)
+
+	create, err := client.NewCreateInstanceCommand().BPMNProcessId("order").LatestVersion().VariablesFromString(`{"orderId":"order-1"}`)
+	require.NoError(t, err)
+	_, err = create.Send(ctx)
+	require.NoError(t, err)
+
+	recorder := gateway.Recorder(t)
+	recorder.AssertPublishedMessage("payment-received", "order-1").
+		WithMessageID("1").
+		WithTimeToLive(time.Minute).
+		WithVariables(map[string]int{"amount": 42})
+	recorder.AssertCreatedInstance("order").WithVariables(map[string]string{"orderId": "order-1"})
+}
+
+func TestRecorderReportsMismatch(t *testing.T) {
+	gateway, client, closeGateway := startGateway(t)
+	defer closeGateway()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	command, err := client.NewSetVariablesCommand().ElementInstanceKey(5).VariablesFromString(`{"foo":"bar"}`)
+	require.NoError(t, err)
+	_, err = command.Local(true).Send(ctx)
+	require.NoError(t, err)
+
+	recordingT := &recordingT{}
+	gateway.Recorder(recordingT).AssertSetVariables(5).WithVariables(map[string]string{"foo": "baz"}).WithLocal(false)
+
+	require.Len(t, recordingT.failures, 2)
+	require.Contains(t, recordingT.failures[0], `{"foo":"baz"}`)
+}
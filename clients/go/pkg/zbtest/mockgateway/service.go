@@ -0,0 +1,250 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mockgateway
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/zeebe-io/zeebe/clients/go/pkg/pb"
+)
+
+func (g *Gateway) ActivateJobs(request *pb.ActivateJobsRequest, stream pb.Gateway_ActivateJobsServer) error {
+	response, err := g.handle(stream.Context(), "ActivateJobs", request, func() proto.Message {
+		return &pb.ActivateJobsResponse{Jobs: g.activate(request)}
+	})
+	if err != nil {
+		return err
+	}
+
+	typed, ok := response.(*pb.ActivateJobsResponse)
+	if !ok {
+		return unexpectedResponse("ActivateJobs", response)
+	}
+	if len(typed.Jobs) == 0 {
+		return nil
+	}
+	return stream.Send(typed)
+}
+
+// activate removes up to MaxJobsToActivate jobs of the requested type; it is called with the lock held
+func (g *Gateway) activate(request *pb.ActivateJobsRequest) []*pb.ActivatedJob {
+	jobs := g.jobs[request.Type]
+	count := int(request.MaxJobsToActivate)
+	if count <= 0 || count > len(jobs) {
+		count = len(jobs)
+	}
+	g.jobs[request.Type] = jobs[count:]
+
+	activated := jobs[:count]
+	for _, job := range activated {
+		if job.Worker == "" {
+			job.Worker = request.Worker
+		}
+	}
+	return activated
+}
+
+func (g *Gateway) CancelWorkflowInstance(ctx context.Context, request *pb.CancelWorkflowInstanceRequest) (*pb.CancelWorkflowInstanceResponse, error) {
+	response, err := g.handle(ctx, "CancelWorkflowInstance", request, func() proto.Message {
+		return &pb.CancelWorkflowInstanceResponse{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	typed, ok := response.(*pb.CancelWorkflowInstanceResponse)
+	if !ok {
+		return nil, unexpectedResponse("CancelWorkflowInstance", response)
+	}
+	return typed, nil
+}
+
+func (g *Gateway) CompleteJob(ctx context.Context, request *pb.CompleteJobRequest) (*pb.CompleteJobResponse, error) {
+	response, err := g.handle(ctx, "CompleteJob", request, func() proto.Message {
+		return &pb.CompleteJobResponse{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	typed, ok := response.(*pb.CompleteJobResponse)
+	if !ok {
+		return nil, unexpectedResponse("CompleteJob", response)
+	}
+	return typed, nil
+}
+
+func (g *Gateway) CreateWorkflowInstance(ctx context.Context, request *pb.CreateWorkflowInstanceRequest) (*pb.CreateWorkflowInstanceResponse, error) {
+	response, err := g.handle(ctx, "CreateWorkflowInstance", request, func() proto.Message {
+		return &pb.CreateWorkflowInstanceResponse{
+			WorkflowKey:         request.WorkflowKey,
+			BpmnProcessId:       request.BpmnProcessId,
+			Version:             request.Version,
+			WorkflowInstanceKey: g.newKey(),
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	typed, ok := response.(*pb.CreateWorkflowInstanceResponse)
+	if !ok {
+		return nil, unexpectedResponse("CreateWorkflowInstance", response)
+	}
+	return typed, nil
+}
+
+func (g *Gateway) CreateWorkflowInstanceWithResult(ctx context.Context, request *pb.CreateWorkflowInstanceWithResultRequest) (*pb.CreateWorkflowInstanceWithResultResponse, error) {
+	response, err := g.handle(ctx, "CreateWorkflowInstanceWithResult", request, func() proto.Message {
+		return &pb.CreateWorkflowInstanceWithResultResponse{
+			WorkflowKey:         request.Request.GetWorkflowKey(),
+			BpmnProcessId:       request.Request.GetBpmnProcessId(),
+			Version:             request.Request.GetVersion(),
+			WorkflowInstanceKey: g.newKey(),
+			Variables:           request.Request.GetVariables(),
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	typed, ok := response.(*pb.CreateWorkflowInstanceWithResultResponse)
+	if !ok {
+		return nil, unexpectedResponse("CreateWorkflowInstanceWithResult", response)
+	}
+	return typed, nil
+}
+
+func (g *Gateway) DeployWorkflow(ctx context.Context, request *pb.DeployWorkflowRequest) (*pb.DeployWorkflowResponse, error) {
+	response, err := g.handle(ctx, "DeployWorkflow", request, func() proto.Message {
+		return &pb.DeployWorkflowResponse{Key: g.newKey()}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	typed, ok := response.(*pb.DeployWorkflowResponse)
+	if !ok {
+		return nil, unexpectedResponse("DeployWorkflow", response)
+	}
+	return typed, nil
+}
+
+func (g *Gateway) FailJob(ctx context.Context, request *pb.FailJobRequest) (*pb.FailJobResponse, error) {
+	response, err := g.handle(ctx, "FailJob", request, func() proto.Message {
+		return &pb.FailJobResponse{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	typed, ok := response.(*pb.FailJobResponse)
+	if !ok {
+		return nil, unexpectedResponse("FailJob", response)
+	}
+	return typed, nil
+}
+
+func (g *Gateway) ThrowError(ctx context.Context, request *pb.ThrowErrorRequest) (*pb.ThrowErrorResponse, error) {
+	response, err := g.handle(ctx, "ThrowError", request, func() proto.Message {
+		return &pb.ThrowErrorResponse{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	typed, ok := response.(*pb.ThrowErrorResponse)
+	if !ok {
+		return nil, unexpectedResponse("ThrowError", response)
+	}
+	return typed, nil
+}
+
+func (g *Gateway) PublishMessage(ctx context.Context, request *pb.PublishMessageRequest) (*pb.PublishMessageResponse, error) {
+	response, err := g.handle(ctx, "PublishMessage", request, func() proto.Message {
+		return &pb.PublishMessageResponse{Key: g.newKey()}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	typed, ok := response.(*pb.PublishMessageResponse)
+	if !ok {
+		return nil, unexpectedResponse("PublishMessage", response)
+	}
+	return typed, nil
+}
+
+func (g *Gateway) ResolveIncident(ctx context.Context, request *pb.ResolveIncidentRequest) (*pb.ResolveIncidentResponse, error) {
+	response, err := g.handle(ctx, "ResolveIncident", request, func() proto.Message {
+		return &pb.ResolveIncidentResponse{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	typed, ok := response.(*pb.ResolveIncidentResponse)
+	if !ok {
+		return nil, unexpectedResponse("ResolveIncident", response)
+	}
+	return typed, nil
+}
+
+func (g *Gateway) SetVariables(ctx context.Context, request *pb.SetVariablesRequest) (*pb.SetVariablesResponse, error) {
+	response, err := g.handle(ctx, "SetVariables", request, func() proto.Message {
+		return &pb.SetVariablesResponse{Key: g.newKey()}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	typed, ok := response.(*pb.SetVariablesResponse)
+	if !ok {
+		return nil, unexpectedResponse("SetVariables", response)
+	}
+	return typed, nil
+}
+
+func (g *Gateway) Topology(ctx context.Context, request *pb.TopologyRequest) (*pb.TopologyResponse, error) {
+	response, err := g.handle(ctx, "Topology", request, func() proto.Message {
+		return &pb.TopologyResponse{ClusterSize: 1, PartitionsCount: 1, ReplicationFactor: 1}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	typed, ok := response.(*pb.TopologyResponse)
+	if !ok {
+		return nil, unexpectedResponse("Topology", response)
+	}
+	return typed, nil
+}
+
+func (g *Gateway) UpdateJobRetries(ctx context.Context, request *pb.UpdateJobRetriesRequest) (*pb.UpdateJobRetriesResponse, error) {
+	response, err := g.handle(ctx, "UpdateJobRetries", request, func() proto.Message {
+		return &pb.UpdateJobRetriesResponse{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	typed, ok := response.(*pb.UpdateJobRetriesResponse)
+	if !ok {
+		return nil, unexpectedResponse("UpdateJobRetries", response)
+	}
+	return typed, nil
+}